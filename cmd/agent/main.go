@@ -14,6 +14,8 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/aws"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/azurespeech"
 	llmProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/llm"
 	sttProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/stt"
 	ttsProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/tts"
@@ -37,6 +39,25 @@ func main() {
 	deepgramKey := os.Getenv("DEEPGRAM_API_KEY")
 	assemblyKey := os.Getenv("ASSEMBLYAI_API_KEY")
 	lokutorKey := os.Getenv("LOKUTOR_API_KEY")
+	elevenLabsKey := os.Getenv("ELEVENLABS_API_KEY")
+	azureKey := os.Getenv("AZURE_SPEECH_KEY")
+	azureRegion := os.Getenv("AZURE_SPEECH_REGION")
+	piperVoicesDir := os.Getenv("PIPER_VOICES_DIR")
+	azure := azurespeech.New(azurespeech.Config{Key: azureKey, Region: azureRegion, SampleRate: SampleRate})
+	// AWS credentials and region come from the usual chain: AWS_REGION,
+	// AWS_PROFILE, an instance or task role, and so on. They are only
+	// loaded when an AWS provider is chosen.
+	var amazonProviders *aws.Providers
+	amazon := func() *aws.Providers {
+		if amazonProviders == nil {
+			p, err := aws.New(aws.Config{SampleRate: SampleRate})
+			if err != nil {
+				log.Fatal(err)
+			}
+			amazonProviders = p
+		}
+		return amazonProviders
+	}
 
 	sttProviderName := os.Getenv("STT_PROVIDER")
 	if sttProviderName == "" {
@@ -46,15 +67,31 @@ func main() {
 	if llmProviderName == "" {
 		llmProviderName = "groq"
 	}
+	ttsProviderName := os.Getenv("TTS_PROVIDER")
+	if ttsProviderName == "" {
+		ttsProviderName = "lokutor"
+	}
 
 	lang := orchestrator.Language(os.Getenv("AGENT_LANGUAGE"))
 	if lang == "" {
 		lang = orchestrator.LanguageEs
 	}
 
-	if lokutorKey == "" {
+	if ttsProviderName == "lokutor" && lokutorKey == "" {
 		log.Fatal("Error: LOKUTOR_API_KEY must be set.")
 	}
+	if ttsProviderName == "openai" && openaiKey == "" {
+		log.Fatal("Error: OPENAI_API_KEY must be set for openai TTS")
+	}
+	if ttsProviderName == "elevenlabs" && elevenLabsKey == "" {
+		log.Fatal("Error: ELEVENLABS_API_KEY must be set for elevenlabs TTS")
+	}
+	if (ttsProviderName == "azure" || sttProviderName == "azure") && (azureKey == "" || azureRegion == "") {
+		log.Fatal("Error: AZURE_SPEECH_KEY and AZURE_SPEECH_REGION must be set for azure")
+	}
+	if ttsProviderName == "piper" && piperVoicesDir == "" {
+		log.Fatal("Error: PIPER_VOICES_DIR must be set for piper TTS")
+	}
 
 	var stt orchestrator.STTProvider
 	switch sttProviderName {
@@ -73,6 +110,10 @@ func main() {
 			log.Fatal("Error: ASSEMBLYAI_API_KEY must be set for assemblyai STT")
 		}
 		stt = sttProvider.NewAssemblyAISTT(assemblyKey)
+	case "azure":
+		stt = azure.STT
+	case "aws":
+		stt = amazon().STT
 	case "groq":
 		fallthrough
 	default:
@@ -127,13 +168,36 @@ func main() {
 		config.FirstSpeaker = orchestrator.FirstSpeakerUser
 	}
 
-	fmt.Printf("Configured: STT=%s | LLM=%s | TTS=Lokutor\n", sttProviderName, llmProviderName)
+	var tts orchestrator.TTSProvider
+	switch ttsProviderName {
+	case "openai":
+		openaiTTS := ttsProvider.NewOpenAITTS(openaiKey, "")
+		openaiTTS.SetSampleRate(SampleRate)
+		tts = openaiTTS
+	case "elevenlabs":
+		elevenLabsTTS := ttsProvider.NewElevenLabsTTS(elevenLabsKey, "")
+		elevenLabsTTS.SetSampleRate(SampleRate)
+		tts = elevenLabsTTS
+	case "azure":
+		tts = azure.TTS
+	case "aws":
+		tts = amazon().TTS
+	case "piper":
+		piperTTS, err := ttsProvider.NewPiperTTS(piperVoicesDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		piperTTS.SetSampleRate(SampleRate)
+		tts = piperTTS
+	default:
+		tts = ttsProvider.NewLokutorTTS(lokutorKey)
+	}
+
+	fmt.Printf("Configured: STT=%s | LLM=%s | TTS=%s\n", sttProviderName, llmProviderName, ttsProviderName)
 	fmt.Printf("VAD Threshold: %.3f | Sample Rate: %dHz | Language: %s\n", config.BargeInVADThreshold, SampleRate, lang)
 	fmt.Println("Voice Agent Started! Listening to microphone...")
 	fmt.Println("Press Ctrl+C to exit")
 
-	tts := ttsProvider.NewLokutorTTS(lokutorKey)
-
 	// Advanced VAD with ZCR and Peak tracking for better noise rejection.
 	vad := orchestrator.NewImprovedRMSVAD(config.BargeInVADThreshold, 200*time.Millisecond, SampleRate)
 	// Wait for ~80ms of continuous energy before muting (8 * 10ms frame size).
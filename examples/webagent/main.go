@@ -0,0 +1,79 @@
+// Command webagent is a voice agent in the browser: it serves a page that
+// streams the microphone to a ManagedStream over a WebSocket and plays the
+// bot's replies. It doubles as a reference for serving the orchestrator over
+// the web and as a smoke test of the whole stack.
+//
+//	GROQ_API_KEY=... LOKUTOR_API_KEY=... go run ./examples/webagent
+//
+// then open http://localhost:8080. Browsers only allow the microphone on
+// localhost or over HTTPS.
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	llmProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/llm"
+	sttProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/stt"
+	ttsProvider "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/tts"
+)
+
+// SampleRate is the rate of audio both ways; Lokutor speaks at 44.1kHz.
+const SampleRate = 44100
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Note: No .env file found, using system environment variables")
+	}
+
+	groqKey := os.Getenv("GROQ_API_KEY")
+	lokutorKey := os.Getenv("LOKUTOR_API_KEY")
+	if groqKey == "" || lokutorKey == "" {
+		log.Fatal("Error: GROQ_API_KEY and LOKUTOR_API_KEY must be set.")
+	}
+	addr := os.Getenv("ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	lang := orchestrator.Language(os.Getenv("AGENT_LANGUAGE"))
+	if lang == "" {
+		lang = orchestrator.LanguageEn
+	}
+
+	stt := sttProvider.NewGroqSTT(groqKey, "whisper-large-v3-turbo")
+	stt.SetSampleRate(SampleRate)
+	llm := llmProvider.NewGroqLLM(groqKey, "meta-llama/llama-4-scout-17b-16e-instruct")
+	tts := ttsProvider.NewLokutorTTS(lokutorKey)
+
+	config := orchestrator.DefaultConfig()
+	config.Language = lang
+	config.SampleRate = SampleRate
+	vad := orchestrator.NewImprovedRMSVAD(config.BargeInVADThreshold, 200*time.Millisecond, SampleRate)
+	orch := orchestrator.New(stt, llm, tts, vad, config, nil)
+
+	prompt := "You are a helpful and concise voice assistant talking to someone in their browser. " +
+		"Use short sentences suitable for speech."
+	srv := &http.Server{Addr: addr, Handler: newServer(orch, prompt)}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		orch.Close()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("Voice agent listening on %s", addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
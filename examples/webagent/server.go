@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"log"
+	"net/http"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// forwarded are the events the page shows; audio goes as binary messages.
+var forwarded = map[orchestrator.EventType]bool{
+	orchestrator.UserSpeaking:         true,
+	orchestrator.TranscriptPartial:    true,
+	orchestrator.TranscriptFinal:      true,
+	orchestrator.BotThinking:          true,
+	orchestrator.BotResponse:          true,
+	orchestrator.Interrupted:          true,
+	orchestrator.ConversationComplete: true,
+	orchestrator.ErrorEvent:           true,
+}
+
+// ready is the first message on a socket: the page captures and plays audio
+// at SampleRate, and receives it in chunks of FrameMs.
+type ready struct {
+	Type       string `json:"type"`
+	SessionID  string `json:"session_id"`
+	SampleRate int    `json:"sample_rate"`
+	FrameMs    int64  `json:"frame_ms"`
+}
+
+// newServer serves the page, health probes and a voice session per
+// WebSocket at /ws. The socket carries 16-bit mono PCM both ways as binary
+// messages, and the stream's events to the page as JSON. A client may ask
+// for its audio chunk size with ?framing=, e.g. "40ms" or "http".
+func newServer(orch *orchestrator.Orchestrator, prompt string) http.Handler {
+	static, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServerFS(static))
+	health := orch.HealthHandler()
+	for _, path := range []string{"/healthz", "/livez", "/readyz"} {
+		mux.Handle(path, health)
+	}
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		serveSession(w, r, orch, prompt)
+	})
+	return mux
+}
+
+func serveSession(w http.ResponseWriter, r *http.Request, orch *orchestrator.Orchestrator, prompt string) {
+	framing := orchestrator.AudioFraming{Transport: orchestrator.TransportWebSocket}
+	if asked := r.URL.Query().Get("framing"); asked != "" {
+		f, err := orchestrator.ParseAudioFraming(asked)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		framing = f
+	}
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	session := orch.NewSessionWithDefaults("web")
+	if prompt != "" {
+		orch.SetSystemPrompt(session, prompt)
+	}
+	rate := orch.GetConfig().SampleRate
+	if err := wsjson.Write(ctx, conn, ready{Type: "ready", SessionID: session.ID, SampleRate: rate, FrameMs: framing.FrameDuration().Milliseconds()}); err != nil {
+		return
+	}
+	stream := orch.NewManagedStream(ctx, session)
+	stream.SetAudioFraming(framing)
+	defer stream.Close()
+	log.Printf("session %s connected from %s", session.ID, r.RemoteAddr)
+
+	go func() {
+		// The stream closing, e.g. after a goodbye, ends the session.
+		defer cancel()
+		for ev := range stream.Events() {
+			var err error
+			switch {
+			case ev.Type == orchestrator.AudioChunk:
+				chunk, _ := ev.Data.([]byte)
+				err = conn.Write(ctx, websocket.MessageBinary, chunk)
+			case forwarded[ev.Type]:
+				err = wsjson.Write(ctx, conn, ev)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			break
+		}
+		if typ == websocket.MessageBinary {
+			stream.Write(data)
+		}
+	}
+	log.Printf("session %s closed", session.ID)
+	conn.Close(websocket.StatusNormalClosure, "")
+}
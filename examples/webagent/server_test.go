@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type fakeSTT struct{}
+
+func (fakeSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (orchestrator.TranscriptionResult, error) {
+	return orchestrator.TranscriptionResult{Text: "hello"}, nil
+}
+func (fakeSTT) Name() string { return "fake" }
+
+type fakeLLM struct{}
+
+func (fakeLLM) Complete(ctx context.Context, messages []orchestrator.Message, tools []orchestrator.Tool) (string, error) {
+	return "Hi there.", nil
+}
+func (fakeLLM) Name() string { return "fake" }
+
+type fakeTTS struct{}
+
+func (fakeTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return make([]byte, 882), nil
+}
+func (t fakeTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	audio, _ := t.Synthesize(ctx, text, voice, lang)
+	return onChunk(audio)
+}
+func (fakeTTS) Abort() error { return nil }
+func (fakeTTS) Name() string { return "fake" }
+
+func TestServer(t *testing.T) {
+	config := orchestrator.DefaultConfig()
+	config.Greeting = "Welcome to the demo."
+	orch := orchestrator.New(fakeSTT{}, fakeLLM{}, fakeTTS{}, nil, config, nil)
+	server := httptest.NewServer(newServer(orch, "Be brief."))
+	defer server.Close()
+
+	for path, want := range map[string]string{"/": "app.js", "/capture.js": "registerProcessor", "/healthz": ""} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), want) {
+			t.Errorf("GET %s = %d %q", path, resp.StatusCode, body)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws?framing=10ms", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+	if err := conn.Write(ctx, websocket.MessageBinary, make([]byte, 1764)); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []string
+	var audio int
+	for audio == 0 || len(events) < 2 {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("after %v and %d bytes of audio: %v", events, audio, err)
+		}
+		if typ == websocket.MessageBinary {
+			audio += len(data)
+			continue
+		}
+		var ev struct {
+			Type       string `json:"type"`
+			SampleRate int    `json:"sample_rate"`
+			FrameMs    int    `json:"frame_ms"`
+			Data       any    `json:"data"`
+		}
+		json.Unmarshal(data, &ev)
+		switch ev.Type {
+		case "ready":
+			if ev.SampleRate != 44100 || ev.FrameMs != 10 {
+				t.Errorf("ready at %d Hz in %dms frames", ev.SampleRate, ev.FrameMs)
+			}
+		case "BOT_RESPONSE":
+			if ev.Data != "Welcome to the demo." {
+				t.Errorf("greeting = %v", ev.Data)
+			}
+		}
+		events = append(events, ev.Type)
+	}
+	if events[0] != "ready" || audio != 882 {
+		t.Errorf("got %v and %d bytes of audio", events, audio)
+	}
+}
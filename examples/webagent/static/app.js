@@ -0,0 +1,140 @@
+// Streams the microphone to /ws and plays what comes back. Binary messages
+// are 16-bit mono PCM; text messages are the stream's events.
+const button = document.getElementById("talk");
+const status = document.getElementById("status");
+const log = document.getElementById("log");
+
+let socket, context, mic, rate;
+let playhead = 0;
+let playing = [];
+let carry = null;
+let partial = null;
+
+button.onclick = () => (socket ? stop() : start());
+
+async function start() {
+  // Created in the click so the browser lets it play.
+  context = new AudioContext();
+  try {
+    mic = await navigator.mediaDevices.getUserMedia({
+      audio: { echoCancellation: true, noiseSuppression: true, channelCount: 1 },
+    });
+  } catch (err) {
+    line("error", "Microphone: " + err.message);
+    return;
+  }
+  const scheme = location.protocol === "https:" ? "wss" : "ws";
+  socket = new WebSocket(`${scheme}://${location.host}/ws`);
+  socket.binaryType = "arraybuffer";
+  socket.onmessage = (msg) =>
+    typeof msg.data === "string" ? onEvent(JSON.parse(msg.data)) : play(msg.data);
+  socket.onclose = () => stop();
+  button.textContent = "Hang up";
+  button.classList.add("live");
+  status.textContent = "Connecting…";
+}
+
+function stop() {
+  if (socket) socket.close();
+  if (mic) mic.getTracks().forEach((t) => t.stop());
+  if (context) context.close();
+  socket = context = mic = null;
+  playing = [];
+  playhead = 0;
+  button.textContent = "Start talking";
+  button.classList.remove("live");
+  status.textContent = "";
+}
+
+async function capture() {
+  await context.audioWorklet.addModule("capture.js");
+  const node = new AudioWorkletNode(context, "capture", { processorOptions: { rate } });
+  node.port.onmessage = (e) => {
+    if (socket && socket.readyState === WebSocket.OPEN) socket.send(e.data);
+  };
+  context.createMediaStreamSource(mic).connect(node);
+  status.textContent = "Listening";
+}
+
+function onEvent(ev) {
+  switch (ev.type) {
+    case "ready":
+      rate = ev.sample_rate;
+      capture();
+      break;
+    case "USER_SPEAKING":
+      status.textContent = "Hearing you…";
+      break;
+    case "TRANSCRIPT_PARTIAL":
+      if (!partial) partial = line("partial", "");
+      partial.textContent = ev.data;
+      break;
+    case "TRANSCRIPT_FINAL":
+      if (partial) partial.remove();
+      partial = null;
+      line("user", ev.data, "You");
+      break;
+    case "BOT_THINKING":
+      status.textContent = "Thinking…";
+      break;
+    case "BOT_RESPONSE":
+      line("bot", ev.data, "Agent");
+      status.textContent = "Listening";
+      break;
+    case "INTERRUPTED":
+      flush();
+      break;
+    case "ERROR":
+      line("error", ev.data);
+      break;
+  }
+}
+
+function play(data) {
+  if (!context || !rate) return;
+  let bytes = new Uint8Array(data);
+  if (carry) {
+    bytes = new Uint8Array([...carry, ...bytes]);
+    carry = null;
+  }
+  if (bytes.length % 2) {
+    carry = bytes.slice(-1);
+    bytes = bytes.slice(0, -1);
+  }
+  const pcm = new Int16Array(bytes.buffer, bytes.byteOffset, bytes.length / 2);
+  if (!pcm.length) return;
+  const buffer = context.createBuffer(1, pcm.length, rate);
+  const samples = buffer.getChannelData(0);
+  for (let i = 0; i < pcm.length; i++) samples[i] = pcm[i] / 32768;
+
+  const source = context.createBufferSource();
+  source.buffer = buffer;
+  source.connect(context.destination);
+  playhead = Math.max(playhead, context.currentTime + 0.05);
+  source.start(playhead);
+  playhead += buffer.duration;
+  playing.push(source);
+  source.onended = () => (playing = playing.filter((s) => s !== source));
+}
+
+// flush drops the bot's queued audio when the user talks over it.
+function flush() {
+  playing.forEach((s) => s.stop());
+  playing = [];
+  playhead = 0;
+  carry = null;
+}
+
+function line(kind, text, who) {
+  const p = document.createElement("p");
+  p.className = kind;
+  if (who) {
+    const b = document.createElement("b");
+    b.textContent = who + ": ";
+    p.append(b);
+  }
+  p.append(text);
+  log.append(p);
+  p.scrollIntoView();
+  return p;
+}
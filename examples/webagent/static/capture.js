@@ -0,0 +1,28 @@
+// Resamples the microphone to the server's rate and posts it as 16-bit PCM,
+// about 20ms at a time.
+class Capture extends AudioWorkletProcessor {
+  constructor(options) {
+    super();
+    this.step = sampleRate / options.processorOptions.rate;
+    this.pos = 0;
+    this.out = new Int16Array(Math.round(options.processorOptions.rate / 50));
+    this.n = 0;
+  }
+
+  process(inputs) {
+    const input = inputs[0][0];
+    if (!input) return true;
+    for (; this.pos < input.length; this.pos += this.step) {
+      const s = Math.max(-1, Math.min(1, input[Math.floor(this.pos)]));
+      this.out[this.n++] = s < 0 ? s * 0x8000 : s * 0x7fff;
+      if (this.n === this.out.length) {
+        this.port.postMessage(this.out.slice().buffer);
+        this.n = 0;
+      }
+    }
+    this.pos -= input.length;
+    return true;
+  }
+}
+
+registerProcessor("capture", Capture);
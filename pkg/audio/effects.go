@@ -0,0 +1,292 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Effect processes audio in place, as samples in [-1, 1]. Effects keep
+// state from one call to the next, so an instance serves one stream.
+type Effect interface {
+	Process(samples []float64)
+}
+
+// EffectFactory makes an Effect for audio at sampleRate. A Chain holds
+// factories rather than effects so every stream starts with fresh state.
+type EffectFactory func(sampleRate int) Effect
+
+// BandType is the shape of an EQ band.
+type BandType int
+
+const (
+	BandPeak BandType = iota
+	BandLowShelf
+	BandHighShelf
+	BandLowPass  // GainDB is ignored
+	BandHighPass // GainDB is ignored
+)
+
+// EQBand is one band of an equaliser. Q defaults to 0.707.
+type EQBand struct {
+	Type   BandType
+	Freq   float64
+	GainDB float64
+	Q      float64
+}
+
+type eq []biquad
+
+func (e eq) Process(x []float64) {
+	for i := range e {
+		for j, v := range x {
+			x[j] = e[i].process(v)
+		}
+	}
+}
+
+// EQ shapes the spectrum with biquad bands applied in order. Bands above
+// the Nyquist frequency of the stream are left out.
+func EQ(bands ...EQBand) EffectFactory {
+	return func(sampleRate int) Effect {
+		fs := float64(sampleRate)
+		var e eq
+		for _, b := range bands {
+			if b.Freq <= 0 || b.Freq >= fs/2 {
+				continue
+			}
+			q := b.Q
+			if q <= 0 {
+				q = 0.707
+			}
+			switch b.Type {
+			case BandLowShelf:
+				e = append(e, newLowShelf(fs, b.Freq, b.GainDB, q))
+			case BandHighShelf:
+				e = append(e, newHighShelf(fs, b.Freq, b.GainDB, q))
+			case BandLowPass:
+				e = append(e, newLowPass(fs, b.Freq, q))
+			case BandHighPass:
+				e = append(e, newHighPass(fs, b.Freq, q))
+			default:
+				e = append(e, newPeaking(fs, b.Freq, b.GainDB, q))
+			}
+		}
+		return e
+	}
+}
+
+type gain float64
+
+func (g gain) Process(x []float64) {
+	for i := range x {
+		x[i] *= float64(g)
+	}
+}
+
+// Gain scales the audio by db decibels.
+func Gain(db float64) EffectFactory {
+	return func(int) Effect { return gain(math.Pow(10, db/20)) }
+}
+
+type deEsser struct {
+	detector  biquad
+	env       float64
+	attack    float64
+	release   float64
+	threshold float64 // dBFS
+	maxCut    float64 // dB
+}
+
+// DeEsser turns down sibilance: while the band above freq (around 5-7kHz
+// for most voices) is louder than thresholdDB, the voice is turned down by
+// up to 12dB, at a 4:1 ratio, for as long as the "s" lasts. Audio without
+// loud sibilance passes untouched.
+func DeEsser(freq, thresholdDB float64) EffectFactory {
+	return func(sampleRate int) Effect {
+		fs := float64(sampleRate)
+		freq := math.Min(freq, fs*0.45)
+		return &deEsser{
+			detector:  newHighPass(fs, freq, 0.707),
+			attack:    math.Exp(-1 / (0.001 * fs)),
+			release:   math.Exp(-1 / (0.05 * fs)),
+			threshold: thresholdDB,
+			maxCut:    12,
+		}
+	}
+}
+
+func (d *deEsser) Process(x []float64) {
+	for i, v := range x {
+		high := d.detector.process(v)
+		level := math.Abs(high)
+		coef := d.release
+		if level > d.env {
+			coef = d.attack
+		}
+		d.env = coef*d.env + (1-coef)*level
+		g := 1.0
+		if d.env > 0 {
+			if over := 20*math.Log10(d.env) - d.threshold; over > 0 {
+				g = math.Pow(10, -math.Min(over*0.75, d.maxCut)/20)
+			}
+		}
+		x[i] = v * g
+	}
+}
+
+// comb and allpass are the delay lines of a Schroeder reverb.
+type comb struct {
+	buf      []float64
+	i        int
+	feedback float64
+	damp     float64
+	last     float64
+}
+
+func (c *comb) process(x float64) float64 {
+	y := c.buf[c.i]
+	c.last = y*(1-c.damp) + c.last*c.damp
+	c.buf[c.i] = x + c.last*c.feedback
+	c.i = (c.i + 1) % len(c.buf)
+	return y
+}
+
+type allpass struct {
+	buf []float64
+	i   int
+}
+
+func (a *allpass) process(x float64) float64 {
+	b := a.buf[a.i]
+	y := b - 0.5*x
+	a.buf[a.i] = x + 0.5*b
+	a.i = (a.i + 1) % len(a.buf)
+	return y
+}
+
+type reverb struct {
+	combs     []*comb
+	allpasses []*allpass
+	mix       float64
+}
+
+// Reverb adds room sound: room from 0 (a small, dry room) to 1 (a hall),
+// mix the share of reverberated signal, e.g. 0.15. Its tail is cut off
+// where the stream ends.
+func Reverb(room, mix float64) EffectFactory {
+	return func(sampleRate int) Effect {
+		fs := float64(sampleRate)
+		room = math.Max(0, math.Min(1, room))
+		r := &reverb{mix: math.Max(0, math.Min(1, mix))}
+		for _, ms := range []float64{29.7, 37.1, 41.1, 43.7} {
+			r.combs = append(r.combs, &comb{
+				buf:      make([]float64, max(1, int(ms*fs/1000))),
+				feedback: 0.7 + 0.28*room,
+				damp:     0.2,
+			})
+		}
+		for _, ms := range []float64{5, 1.7} {
+			r.allpasses = append(r.allpasses, &allpass{buf: make([]float64, max(1, int(ms*fs/1000)))})
+		}
+		return r
+	}
+}
+
+func (r *reverb) Process(x []float64) {
+	for i, v := range x {
+		var wet float64
+		for _, c := range r.combs {
+			wet += c.process(v)
+		}
+		wet /= float64(len(r.combs))
+		for _, a := range r.allpasses {
+			wet = a.process(wet)
+		}
+		x[i] = v*(1-r.mix) + wet*r.mix
+	}
+}
+
+// Chain runs 16-bit little-endian mono PCM through effects in order,
+// clipping the result to full scale. Audio can be written in chunks of any
+// size, odd ones included.
+type Chain struct {
+	effects []Effect
+	odd     []byte
+}
+
+// NewChain returns a chain of fresh effects for audio at sampleRate.
+func NewChain(sampleRate int, effects ...EffectFactory) *Chain {
+	c := &Chain{}
+	for _, f := range effects {
+		c.effects = append(c.effects, f(sampleRate))
+	}
+	return c
+}
+
+// Process returns the processed audio of pcm. A trailing odd byte is kept
+// for the next call.
+func (c *Chain) Process(pcm []byte) []byte {
+	if len(c.odd) > 0 {
+		pcm = append(c.odd, pcm...)
+		c.odd = nil
+	}
+	if len(pcm)%2 == 1 {
+		c.odd = []byte{pcm[len(pcm)-1]}
+		pcm = pcm[:len(pcm)-1]
+	}
+	x := make([]float64, len(pcm)/2)
+	for i := range x {
+		x[i] = float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) / 32768
+	}
+	for _, e := range c.effects {
+		e.Process(x)
+	}
+	out := make([]byte, len(pcm))
+	for i, v := range x {
+		v = math.Max(-32768, math.Min(32767, math.Round(v*32768)))
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(int16(v)))
+	}
+	return out
+}
+
+func newLowShelf(fs, fc, gainDB, q float64) biquad {
+	a := math.Pow(10, gainDB/40)
+	w0 := 2 * math.Pi * fc / fs
+	cos, alpha := math.Cos(w0), math.Sin(w0)/(2*q)
+	sq := 2 * math.Sqrt(a) * alpha
+	a0 := (a + 1) + (a-1)*cos + sq
+	return biquad{
+		b0: a * ((a + 1) - (a-1)*cos + sq) / a0,
+		b1: 2 * a * ((a - 1) - (a+1)*cos) / a0,
+		b2: a * ((a + 1) - (a-1)*cos - sq) / a0,
+		a1: -2 * ((a - 1) + (a+1)*cos) / a0,
+		a2: ((a + 1) + (a-1)*cos - sq) / a0,
+	}
+}
+
+func newPeaking(fs, fc, gainDB, q float64) biquad {
+	a := math.Pow(10, gainDB/40)
+	w0 := 2 * math.Pi * fc / fs
+	cos, alpha := math.Cos(w0), math.Sin(w0)/(2*q)
+	a0 := 1 + alpha/a
+	return biquad{
+		b0: (1 + alpha*a) / a0,
+		b1: -2 * cos / a0,
+		b2: (1 - alpha*a) / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha/a) / a0,
+	}
+}
+
+func newLowPass(fs, fc, q float64) biquad {
+	w0 := 2 * math.Pi * fc / fs
+	cos, alpha := math.Cos(w0), math.Sin(w0)/(2*q)
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 - cos) / 2 / a0,
+		b1: (1 - cos) / a0,
+		b2: (1 - cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
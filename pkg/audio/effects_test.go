@@ -0,0 +1,62 @@
+package audio
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestChain_EQAndGain(t *testing.T) {
+	low := tone(100, 0.2, 16000, 1)
+	high := tone(3000, 0.2, 16000, 1)
+	eq := EQ(EQBand{Type: BandLowShelf, Freq: 300, GainDB: 6}, EQBand{Type: BandPeak, Freq: 20000, GainDB: 6})
+	if got := Loudness(NewChain(16000, eq).Process(low), 16000) - Loudness(low, 16000); math.Abs(got-6) > 1 {
+		t.Errorf("low shelf raised a 100Hz tone by %.1f dB, want 6", got)
+	}
+	if got := Loudness(NewChain(16000, eq).Process(high), 16000) - Loudness(high, 16000); math.Abs(got) > 1 {
+		t.Errorf("low shelf changed a 3kHz tone by %.1f dB", got)
+	}
+	if peak := peakOf(NewChain(16000, Gain(-6)).Process(high)); math.Abs(peak-0.1) > 0.005 {
+		t.Errorf("-6dB gain left peak %.3f", peak)
+	}
+	if peak := peakOf(NewChain(16000, Gain(20)).Process(high)); peak > 1 {
+		t.Errorf("gain should clip at full scale, peak %.3f", peak)
+	}
+}
+
+func TestChain_Chunked(t *testing.T) {
+	pcm := tone(440, 0.3, 16000, 0.5)
+	effects := []EffectFactory{EQ(EQBand{Freq: 1000, GainDB: -3, Q: 2}), DeEsser(5000, -30), Reverb(0.5, 0.2)}
+	whole := NewChain(16000, effects...).Process(pcm)
+	c := NewChain(16000, effects...)
+	var chunked []byte
+	for i := 0; i < len(pcm); i += 777 {
+		chunked = append(chunked, c.Process(pcm[i:min(i+777, len(pcm))])...)
+	}
+	if !bytes.Equal(whole, chunked) {
+		t.Error("processing in odd-sized chunks should match processing the whole clip")
+	}
+}
+
+func TestDeEsser(t *testing.T) {
+	voice := tone(200, 0.3, 44100, 1)
+	hiss := tone(7000, 0.3, 44100, 1)
+	d := DeEsser(5000, -30)
+	if got := Loudness(NewChain(44100, d).Process(hiss), 44100) - Loudness(hiss, 44100); got > -6 {
+		t.Errorf("loud sibilance cut by only %.1f dB", -got)
+	}
+	if got := Loudness(NewChain(44100, d).Process(voice), 44100) - Loudness(voice, 44100); math.Abs(got) > 0.5 {
+		t.Errorf("a 200Hz tone changed by %.1f dB", got)
+	}
+}
+
+func TestReverb_Tail(t *testing.T) {
+	pcm := append(tone(440, 0.5, 16000, 0.1), make([]byte, 16000)...)
+	out := NewChain(16000, Reverb(0.8, 0.3)).Process(pcm)
+	if peakOf(out[len(pcm)-8000:]) == 0 {
+		t.Error("reverb should ring on after the sound stops")
+	}
+	if dry := NewChain(16000, Reverb(0.8, 0)).Process(pcm); !bytes.Equal(dry, pcm) {
+		t.Error("a mix of 0 should leave the audio as it was")
+	}
+}
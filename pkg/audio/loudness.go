@@ -0,0 +1,232 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Loudness returns the integrated loudness of 16-bit little-endian mono PCM
+// in LUFS, measured as in ITU-R BS.1770: K-weighted, in 400ms blocks every
+// 100ms, with the -70 LUFS absolute gate and the -10 LU relative gate. Audio
+// too short or too quiet to measure returns -Inf.
+func Loudness(pcm []byte, sampleRate int) float64 {
+	m := NewLoudnessMeter(sampleRate)
+	m.keep = true
+	m.Write(pcm)
+	if len(m.blocks) == 0 {
+		return math.Inf(-1)
+	}
+	var sum float64
+	for _, ms := range m.blocks {
+		sum += ms
+	}
+	gate := lufs(sum/float64(len(m.blocks))) - 10
+	sum = 0
+	n := 0
+	for _, ms := range m.blocks {
+		if lufs(ms) > gate {
+			sum += ms
+			n++
+		}
+	}
+	if n == 0 {
+		return math.Inf(-1)
+	}
+	return lufs(sum / float64(n))
+}
+
+func lufs(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+// LoudnessMeter measures the loudness of audio fed to it in chunks. It
+// applies only the absolute gate, so its running value is cheap to read after
+// every chunk and, over speech, close to the fully gated measurement.
+type LoudnessMeter struct {
+	shelf, highpass biquad
+
+	sub     int       // Samples per 100ms sub-block
+	subSum  float64   // Sum of squares in the current sub-block
+	subN    int       // Samples in the current sub-block
+	recent  []float64 // Last four sub-block sums
+	blockMS float64   // Sum of gated block mean squares
+	blockN  int
+	odd     []byte
+
+	keep   bool // Loudness needs every block for the relative gate
+	blocks []float64
+}
+
+// NewLoudnessMeter returns a meter for audio at sampleRate.
+func NewLoudnessMeter(sampleRate int) *LoudnessMeter {
+	if sampleRate <= 0 {
+		sampleRate = 44100
+	}
+	return &LoudnessMeter{
+		shelf:    newHighShelf(float64(sampleRate), 1500, 4, 1/math.Sqrt2),
+		highpass: newHighPass(float64(sampleRate), 38, 0.5),
+		sub:      max(sampleRate/10, 1),
+	}
+}
+
+// Write measures the next chunk.
+func (m *LoudnessMeter) Write(pcm []byte) {
+	if len(m.odd) > 0 {
+		pcm = append(m.odd, pcm...)
+		m.odd = nil
+	}
+	if len(pcm)%2 == 1 {
+		m.odd = []byte{pcm[len(pcm)-1]}
+		pcm = pcm[:len(pcm)-1]
+	}
+	for i := 0; i < len(pcm); i += 2 {
+		x := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) / 32768
+		y := m.highpass.process(m.shelf.process(x))
+		m.subSum += y * y
+		m.subN++
+		if m.subN < m.sub {
+			continue
+		}
+		m.recent = append(m.recent, m.subSum)
+		m.subSum, m.subN = 0, 0
+		if len(m.recent) < 4 {
+			continue
+		}
+		if len(m.recent) > 4 {
+			m.recent = m.recent[1:]
+		}
+		ms := (m.recent[0] + m.recent[1] + m.recent[2] + m.recent[3]) / float64(4*m.sub)
+		if lufs(ms) <= -70 {
+			continue
+		}
+		m.blockMS += ms
+		m.blockN++
+		if m.keep {
+			m.blocks = append(m.blocks, ms)
+		}
+	}
+}
+
+// Loudness returns the loudness measured so far in LUFS, or -Inf before the
+// first 400ms of sound.
+func (m *LoudnessMeter) Loudness() float64 {
+	if m.blockN == 0 {
+		return math.Inf(-1)
+	}
+	return lufs(m.blockMS / float64(m.blockN))
+}
+
+// Normalizer scales audio towards a target loudness without letting samples
+// pass a peak ceiling. Gain changes are ramped across each chunk so a new
+// measurement never causes a click.
+type Normalizer struct {
+	target  float64
+	ceiling float64 // Linear, full scale 1
+	gain    float64
+}
+
+// NewNormalizer returns a Normalizer aiming at targetLUFS with peaks held
+// below peakDBFS.
+func NewNormalizer(targetLUFS, peakDBFS float64) *Normalizer {
+	return &Normalizer{target: targetLUFS, ceiling: math.Pow(10, peakDBFS/20), gain: 1}
+}
+
+// maxGainDB bounds how much quiet audio is boosted, so near-silence and
+// breaths are not pulled up to speech level.
+const maxGainDB = 20
+
+func (n *Normalizer) gainFor(measured float64) float64 {
+	if math.IsInf(measured, -1) || math.IsNaN(measured) {
+		return n.gain
+	}
+	db := math.Max(-maxGainDB, math.Min(maxGainDB, n.target-measured))
+	return math.Pow(10, db/20)
+}
+
+// Prime sets the starting gain from a loudness measured earlier, e.g. for
+// the same voice in a previous response, so the first chunk is already at
+// the right level.
+func (n *Normalizer) Prime(measured float64) {
+	n.gain = n.gainFor(measured)
+}
+
+// Apply scales one chunk given the loudness measured for its source so far.
+func (n *Normalizer) Apply(pcm []byte, measured float64) []byte {
+	samples := len(pcm) / 2
+	if samples == 0 {
+		return pcm
+	}
+	want := n.gainFor(measured)
+	var peak float64
+	for i := 0; i < samples; i++ {
+		peak = math.Max(peak, math.Abs(float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))/32768))
+	}
+	if peak > 0 {
+		want = math.Min(want, n.ceiling/peak)
+	}
+
+	out := make([]byte, len(pcm))
+	copy(out, pcm)
+	limit := n.ceiling * 32767
+	for i := 0; i < samples; i++ {
+		g := n.gain + (want-n.gain)*float64(i+1)/float64(samples)
+		v := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) * g
+		v = math.Max(-limit, math.Min(limit, math.Round(v)))
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(int16(v)))
+	}
+	n.gain = want
+	return out
+}
+
+// NormalizeLoudness scales a complete clip to targetLUFS with peaks held
+// below peakDBFS.
+func NormalizeLoudness(pcm []byte, sampleRate int, targetLUFS, peakDBFS float64) []byte {
+	measured := Loudness(pcm, sampleRate)
+	n := NewNormalizer(targetLUFS, peakDBFS)
+	n.Prime(measured)
+	return n.Apply(pcm, measured)
+}
+
+// biquad is a direct form I second-order filter with a0 normalised to 1.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+func newHighShelf(fs, fc, gainDB, q float64) biquad {
+	a := math.Pow(10, gainDB/40)
+	w0 := 2 * math.Pi * fc / fs
+	cos, alpha := math.Cos(w0), math.Sin(w0)/(2*q)
+	sq := 2 * math.Sqrt(a) * alpha
+	a0 := (a + 1) - (a-1)*cos + sq
+	return biquad{
+		b0: a * ((a + 1) + (a-1)*cos + sq) / a0,
+		b1: -2 * a * ((a - 1) + (a+1)*cos) / a0,
+		b2: a * ((a + 1) + (a-1)*cos - sq) / a0,
+		a1: 2 * ((a - 1) - (a+1)*cos) / a0,
+		a2: ((a + 1) - (a-1)*cos - sq) / a0,
+	}
+}
+
+func newHighPass(fs, fc, q float64) biquad {
+	w0 := 2 * math.Pi * fc / fs
+	cos, alpha := math.Cos(w0), math.Sin(w0)/(2*q)
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
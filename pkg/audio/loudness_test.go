@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func tone(freq, amplitude float64, sampleRate int, d float64) []byte {
+	n := int(float64(sampleRate) * d)
+	pcm := make([]byte, 2*n)
+	for i := 0; i < n; i++ {
+		v := int16(amplitude * 32767 * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(v))
+	}
+	return pcm
+}
+
+func peakOf(pcm []byte) float64 {
+	var peak float64
+	for i := 0; i+1 < len(pcm); i += 2 {
+		peak = math.Max(peak, math.Abs(float64(int16(binary.LittleEndian.Uint16(pcm[i:])))/32768))
+	}
+	return peak
+}
+
+func TestLoudness_ReferenceTone(t *testing.T) {
+	// A 1kHz sine at -20 dBFS measures about -23 LUFS.
+	got := Loudness(tone(1000, 0.1, 48000, 2), 48000)
+	if math.Abs(got-(-23)) > 0.5 {
+		t.Errorf("got %.2f LUFS, want about -23", got)
+	}
+	if !math.IsInf(Loudness(make([]byte, 96000), 48000), -1) {
+		t.Error("silence must measure -Inf")
+	}
+}
+
+func TestLoudnessMeter_MatchesWholeClip(t *testing.T) {
+	pcm := tone(440, 0.3, 16000, 2)
+	m := NewLoudnessMeter(16000)
+	for i := 0; i < len(pcm); i += 1001 {
+		m.Write(pcm[i:min(i+1001, len(pcm))])
+	}
+	if diff := math.Abs(m.Loudness() - Loudness(pcm, 16000)); diff > 0.1 {
+		t.Errorf("chunked measurement off by %.2f LU", diff)
+	}
+}
+
+func TestNormalizeLoudness(t *testing.T) {
+	quiet := tone(1000, 0.05, 16000, 2)
+	out := NormalizeLoudness(quiet, 16000, -16, -1)
+	if got := Loudness(out, 16000); math.Abs(got-(-16)) > 0.5 {
+		t.Errorf("got %.2f LUFS, want -16", got)
+	}
+
+	loud := tone(1000, 0.5, 16000, 2)
+	out = NormalizeLoudness(loud, 16000, -3, -6)
+	if peak := peakOf(out); peak > math.Pow(10, -6.0/20)+0.001 {
+		t.Errorf("peak %.3f above the -6 dBFS ceiling", peak)
+	}
+}
@@ -0,0 +1,40 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+)
+
+// NoiseSource generates comfort noise: soft, slightly low-passed noise like
+// the hiss of an open phone line, as 16-bit little-endian mono PCM.
+type NoiseSource struct {
+	rng   *rand.Rand
+	gain  float64
+	state float64
+}
+
+// noiseSmoothing is the pole of the low-pass that takes the edge off white
+// noise.
+const noiseSmoothing = 0.6
+
+// NewNoiseSource returns a source whose output has an RMS level of
+// levelDBFS.
+func NewNoiseSource(levelDBFS float64) *NoiseSource {
+	// A one-pole low-pass scales the variance of unit white noise by
+	// (1-a)/(1+a); undo that so the level is what was asked for.
+	rms := math.Pow(10, levelDBFS/20) * 32768
+	spread := math.Sqrt((1 - noiseSmoothing) / (1 + noiseSmoothing))
+	return &NoiseSource{rng: rand.New(rand.NewSource(rand.Int63())), gain: rms / spread}
+}
+
+// Read returns the next samples samples of noise.
+func (n *NoiseSource) Read(samples int) []byte {
+	pcm := make([]byte, 2*max(samples, 0))
+	for i := 0; i < samples; i++ {
+		n.state = noiseSmoothing*n.state + (1-noiseSmoothing)*n.rng.NormFloat64()
+		v := math.Max(-32768, math.Min(32767, math.Round(n.state*n.gain)))
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(v)))
+	}
+	return pcm
+}
@@ -0,0 +1,27 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestNoiseSource_Level(t *testing.T) {
+	n := NewNoiseSource(-50)
+	var pcm []byte
+	for i := 0; i < 50; i++ {
+		pcm = append(pcm, n.Read(160)...)
+	}
+	var sum float64
+	for i := 0; i < len(pcm); i += 2 {
+		v := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) / 32768
+		sum += v * v
+	}
+	got := 10 * math.Log10(sum/float64(len(pcm)/2))
+	if math.Abs(got-(-50)) > 1 {
+		t.Errorf("got %.2f dBFS, want about -50", got)
+	}
+	if len(n.Read(0)) != 0 {
+		t.Error("reading no samples must return nothing")
+	}
+}
@@ -0,0 +1,66 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Resampler converts 16-bit little-endian mono PCM from one sample rate to
+// another by linear interpolation, which is plenty for speech going to a
+// speaker. Audio can be fed in chunks of any size as it arrives.
+type Resampler struct {
+	from, to int
+	pos      float64 // Input position of the next output sample; -1 is prev
+	prev     float64 // Last sample of the previous chunk
+	odd      []byte
+}
+
+// NewResampler returns a Resampler from rate from to rate to. Equal or
+// unset rates pass audio through untouched.
+func NewResampler(from, to int) *Resampler {
+	return &Resampler{from: from, to: to}
+}
+
+// Write converts a chunk and returns the output ready so far.
+func (r *Resampler) Write(pcm []byte) []byte {
+	if r.from == r.to || r.from <= 0 || r.to <= 0 {
+		return pcm
+	}
+	if len(r.odd) > 0 {
+		pcm = append(r.odd, pcm...)
+		r.odd = nil
+	}
+	if len(pcm)%2 == 1 {
+		r.odd = []byte{pcm[len(pcm)-1]}
+		pcm = pcm[:len(pcm)-1]
+	}
+	n := len(pcm) / 2
+	if n == 0 {
+		return nil
+	}
+	sample := func(i int) float64 {
+		if i < 0 {
+			return r.prev
+		}
+		return float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+	}
+	step := float64(r.from) / float64(r.to)
+	out := make([]byte, 0, int(float64(n)/step+2)*2)
+	for ; r.pos <= float64(n-1); r.pos += step {
+		i := int(math.Floor(r.pos))
+		frac := r.pos - float64(i)
+		v := sample(i)
+		if frac > 0 {
+			v += (sample(i+1) - v) * frac
+		}
+		out = binary.LittleEndian.AppendUint16(out, uint16(int16(math.Round(v))))
+	}
+	r.pos -= float64(n)
+	r.prev = sample(n - 1)
+	return out
+}
+
+// Resample converts a complete clip; see Resampler.
+func Resample(pcm []byte, from, to int) []byte {
+	return NewResampler(from, to).Write(pcm)
+}
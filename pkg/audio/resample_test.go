@@ -0,0 +1,35 @@
+package audio
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestResampler_ChunkedMatchesWhole(t *testing.T) {
+	in := sine(440, 24000, 0.1)
+	whole := Resample(in, 24000, 44100)
+	if want := len(in) / 2 * 44100 / 24000; math.Abs(float64(len(whole)/2-want)) > 2 {
+		t.Errorf("got %d samples, want about %d", len(whole)/2, want)
+	}
+
+	r := NewResampler(24000, 44100)
+	var chunked []byte
+	for i := 0; i < len(in); i += 333 { // Odd sizes split samples
+		chunked = append(chunked, r.Write(in[i:min(i+333, len(in))])...)
+	}
+	if !bytes.Equal(chunked, whole) {
+		t.Errorf("chunked output (%d bytes) differs from whole (%d bytes)", len(chunked), len(whole))
+	}
+}
+
+func TestResampler_KeepsPitch(t *testing.T) {
+	out := Resample(sine(440, 24000, 0.5), 24000, 16000)
+	// 440Hz crosses zero about 440 times in half a second.
+	if n := zeroCrossings(out); n < 430 || n > 450 {
+		t.Errorf("got %d zero crossings, want about 440", n)
+	}
+	if got := Resample([]byte{1, 2, 3, 4}, 16000, 16000); !bytes.Equal(got, []byte{1, 2, 3, 4}) {
+		t.Error("equal rates should pass audio through")
+	}
+}
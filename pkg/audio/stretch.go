@@ -0,0 +1,179 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Stretcher changes the tempo of 16-bit little-endian mono PCM without
+// changing its pitch, using WSOLA (waveform-similarity overlap-add): 20ms
+// windows are taken from the input at rate times the output hop, each nudged
+// to where it best continues the previous one, and overlap-added. rate 1.25
+// plays 25% faster; 0.8 plays slower.
+//
+// Audio can be fed in chunks of any size as it arrives; Write returns the
+// output that can no longer change and Flush the rest.
+type Stretcher struct {
+	sampleRate int
+	rate       float64
+	frame      int // Window length in samples
+	hop        int // Output hop, half a window
+	tol        int // How far a window may move to line up with the previous one
+	window     []float64
+
+	in     []float64 // Input from absolute sample inBase on
+	inBase int
+	total  int // Input samples received
+	odd    []byte
+
+	out     []float64 // Output from absolute sample outBase on
+	norm    []float64
+	outBase int
+
+	k    int // Next window
+	prev int // Input start of the previous window
+}
+
+// NewStretcher returns a Stretcher for audio at sampleRate. A rate of 1 or
+// less than or equal to 0 passes audio through untouched.
+func NewStretcher(sampleRate int, rate float64) *Stretcher {
+	frame := sampleRate / 50 &^ 1
+	if frame < 16 {
+		frame = 16
+	}
+	window := make([]float64, frame)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(frame))
+	}
+	return &Stretcher{sampleRate: sampleRate, rate: rate, frame: frame, hop: frame / 2, tol: frame / 4, window: window}
+}
+
+func (s *Stretcher) passthrough() bool {
+	return s.rate <= 0 || s.rate == 1
+}
+
+// Write adds input and returns the output that is ready.
+func (s *Stretcher) Write(pcm []byte) []byte {
+	if s.passthrough() {
+		return pcm
+	}
+	if len(s.odd) > 0 {
+		pcm = append(s.odd, pcm...)
+		s.odd = nil
+	}
+	if len(pcm)%2 == 1 {
+		s.odd = []byte{pcm[len(pcm)-1]}
+		pcm = pcm[:len(pcm)-1]
+	}
+	for i := 0; i < len(pcm); i += 2 {
+		s.in = append(s.in, float64(int16(binary.LittleEndian.Uint16(pcm[i:]))))
+	}
+	s.total += len(pcm) / 2
+	s.process(false)
+	return s.emit(s.k * s.hop)
+}
+
+// Flush returns the remaining output and resets the Stretcher for a new
+// stream.
+func (s *Stretcher) Flush() []byte {
+	if s.passthrough() {
+		return nil
+	}
+	s.process(true)
+	end := int(math.Round(float64(s.total) / s.rate))
+	out := s.emit(end)
+	*s = *NewStretcher(s.sampleRate, s.rate)
+	return out
+}
+
+// TimeStretch stretches a complete clip; see Stretcher.
+func TimeStretch(pcm []byte, sampleRate int, rate float64) []byte {
+	s := NewStretcher(sampleRate, rate)
+	out := s.Write(pcm)
+	return append(out, s.Flush()...)
+}
+
+func (s *Stretcher) sample(i int) float64 {
+	i -= s.inBase
+	if i < 0 || i >= len(s.in) {
+		return 0
+	}
+	return s.in[i]
+}
+
+func (s *Stretcher) process(final bool) {
+	overlap := s.frame - s.hop
+	for {
+		nominal := int(math.Round(float64(s.k) * float64(s.hop) * s.rate))
+		if final && nominal >= s.total {
+			return
+		}
+		start := 0
+		if s.k == 0 {
+			if !final && s.total < s.frame {
+				return
+			}
+		} else {
+			target := s.prev + s.hop
+			lo, hi := max(nominal-s.tol, 0), nominal+s.tol
+			if !final && s.total < max(hi+s.frame, target+overlap) {
+				return
+			}
+			best := math.Inf(-1)
+			for c := lo; c <= hi; c++ {
+				var corr float64
+				for i := 0; i < overlap; i++ {
+					corr += s.sample(c+i) * s.sample(target+i)
+				}
+				if corr > best {
+					best, start = corr, c
+				}
+			}
+		}
+
+		pos := s.k*s.hop - s.outBase
+		if need := pos + s.frame; need > len(s.out) {
+			s.out = append(s.out, make([]float64, need-len(s.out))...)
+			s.norm = append(s.norm, make([]float64, need-len(s.norm))...)
+		}
+		for i, w := range s.window {
+			s.out[pos+i] += s.sample(start+i) * w
+			s.norm[pos+i] += w
+		}
+		s.prev = start
+		s.k++
+
+		// Input before both the next search window and the continuation of
+		// this one is never read again.
+		next := int(math.Round(float64(s.k)*float64(s.hop)*s.rate)) - s.tol
+		if drop := min(next, s.prev+s.hop) - s.inBase; drop > 0 && drop <= len(s.in) {
+			s.in = s.in[drop:]
+			s.inBase += drop
+		}
+	}
+}
+
+// emit converts output up to absolute sample end to PCM and drops it.
+func (s *Stretcher) emit(end int) []byte {
+	n := end - s.outBase
+	if n <= 0 {
+		return nil
+	}
+	if n > len(s.out) {
+		s.out = append(s.out, make([]float64, n-len(s.out))...)
+		s.norm = append(s.norm, make([]float64, n-len(s.norm))...)
+	}
+	pcm := make([]byte, 2*n)
+	for i := 0; i < n; i++ {
+		v := s.out[i]
+		if s.norm[i] > 1e-3 {
+			v /= s.norm[i]
+		}
+		v = math.Max(-32768, math.Min(32767, math.Round(v)))
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(v)))
+	}
+	s.out = s.out[n:]
+	s.norm = s.norm[n:]
+	s.outBase = end
+	return pcm
+}
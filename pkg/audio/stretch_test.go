@@ -0,0 +1,71 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func sine(freq float64, sampleRate int, d float64) []byte {
+	n := int(float64(sampleRate) * d)
+	pcm := make([]byte, 2*n)
+	for i := 0; i < n; i++ {
+		v := int16(8000 * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(v))
+	}
+	return pcm
+}
+
+func zeroCrossings(pcm []byte) int {
+	n := 0
+	prev := int16(0)
+	for i := 0; i+1 < len(pcm); i += 2 {
+		v := int16(binary.LittleEndian.Uint16(pcm[i:]))
+		if (prev < 0) != (v < 0) {
+			n++
+		}
+		prev = v
+	}
+	return n
+}
+
+func TestTimeStretch_KeepsPitch(t *testing.T) {
+	const rate = 16000
+	in := sine(440, rate, 1)
+	for _, speed := range []float64{0.8, 1.25} {
+		out := TimeStretch(in, rate, speed)
+		wantLen := int(math.Round(float64(len(in)/2)/speed)) * 2
+		if len(out) != wantLen {
+			t.Errorf("speed %v: got %d bytes, want %d", speed, len(out), wantLen)
+		}
+		// Crossings per second stay at ~880 if the pitch is unchanged.
+		perSecond := float64(zeroCrossings(out)) / (float64(len(out)/2) / rate)
+		if math.Abs(perSecond-880) > 40 {
+			t.Errorf("speed %v: pitch moved, %v zero crossings per second", speed, perSecond)
+		}
+	}
+}
+
+func TestStretcher_ChunkedMatchesWhole(t *testing.T) {
+	const rate = 16000
+	in := sine(300, rate, 0.5)
+	whole := TimeStretch(in, rate, 1.2)
+
+	s := NewStretcher(rate, 1.2)
+	var chunked []byte
+	for i := 0; i < len(in); i += 333 { // Odd sizes split samples
+		chunked = append(chunked, s.Write(in[i:min(i+333, len(in))])...)
+	}
+	chunked = append(chunked, s.Flush()...)
+	if !bytes.Equal(whole, chunked) {
+		t.Errorf("chunked output differs: %d vs %d bytes", len(chunked), len(whole))
+	}
+}
+
+func TestStretcher_Passthrough(t *testing.T) {
+	in := []byte{1, 2, 3, 4}
+	if out := TimeStretch(in, 16000, 1); !bytes.Equal(out, in) {
+		t.Errorf("rate 1 must not change audio, got %v", out)
+	}
+}
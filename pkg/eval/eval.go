@@ -0,0 +1,158 @@
+// Package eval plays scripted conversations against an orchestrator and
+// grades the replies, so prompt or provider changes can be gated in CI the
+// way code changes are gated by tests.
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Script is one conversation: user turns played in order against a fresh
+// session. Scripts are usually kept as JSON fixtures; see LoadScripts.
+type Script struct {
+	Name         string `json:"name"`
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	Turns        []Turn `json:"turns"`
+}
+
+// Turn is one user turn and what its reply must satisfy. Exactly one of
+// Text, Audio or AudioFile is the input.
+type Turn struct {
+	Text      string `json:"text,omitempty"`
+	Audio     []byte `json:"-"`                    // PCM in the orchestrator's input format
+	AudioFile string `json:"audio_file,omitempty"` // Relative to the script file when loaded
+
+	Expect       []string `json:"expect,omitempty"`         // Patterns the reply must match
+	Forbid       []string `json:"forbid,omitempty"`         // Patterns it must not match
+	MaxLatencyMS int      `json:"max_latency_ms,omitempty"` // Whole turn
+	Judge        string   `json:"judge,omitempty"`          // Rubric for Runner.Judge
+
+	Graders []Grader `json:"-"` // In addition to the above
+}
+
+// LoadScripts reads a JSON file holding a Script or a list of them. Audio
+// files are resolved relative to the file.
+func LoadScripts(path string) ([]Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var scripts []Script
+	if err := json.Unmarshal(data, &scripts); err != nil {
+		var one Script
+		if err := json.Unmarshal(data, &one); err != nil {
+			return nil, fmt.Errorf("eval: %s: %w", path, err)
+		}
+		scripts = []Script{one}
+	}
+	dir := filepath.Dir(path)
+	for i := range scripts {
+		for j := range scripts[i].Turns {
+			if f := scripts[i].Turns[j].AudioFile; f != "" && !filepath.IsAbs(f) {
+				scripts[i].Turns[j].AudioFile = filepath.Join(dir, f)
+			}
+		}
+	}
+	return scripts, nil
+}
+
+// Runner plays scripts against Orchestrator.
+type Runner struct {
+	Orchestrator *orchestrator.Orchestrator
+	Graders      []Grader                 // Applied to every turn
+	Judge        orchestrator.LLMProvider // Grades turns with a Judge rubric; nil fails them
+	TextOnly     bool                     // Skip TTS for text turns; latency then covers the LLM only
+}
+
+// Run plays every script and grades each turn. A turn that fails does not
+// stop its script, so one report shows every problem.
+func (r *Runner) Run(ctx context.Context, scripts ...Script) *Report {
+	report := &Report{StartedAt: time.Now()}
+	for _, s := range scripts {
+		report.Scripts = append(report.Scripts, r.runScript(ctx, s))
+	}
+	report.Duration = time.Since(report.StartedAt)
+	return report
+}
+
+func (r *Runner) runScript(ctx context.Context, s Script) ScriptResult {
+	o := r.Orchestrator
+	session := o.NewSessionWithDefaults("eval-" + s.Name)
+	if s.SystemPrompt != "" {
+		o.SetSystemPrompt(session, s.SystemPrompt)
+	}
+	result := ScriptResult{Name: s.Name}
+	for i, turn := range s.Turns {
+		tr := r.play(ctx, session, turn)
+		tr.Index = i
+		tr.Context = session.GetContextCopy()
+		for _, g := range r.graders(turn) {
+			tr.Scores = append(tr.Scores, g.Grade(ctx, tr))
+		}
+		result.Turns = append(result.Turns, tr)
+	}
+	return result
+}
+
+func (r *Runner) play(ctx context.Context, session *orchestrator.ConversationSession, turn Turn) TurnResult {
+	tr := TurnResult{Input: turn.Text}
+	audio := turn.Audio
+	if turn.AudioFile != "" {
+		var err error
+		if audio, err = os.ReadFile(turn.AudioFile); err != nil {
+			tr.Error = err.Error()
+			return tr
+		}
+		tr.Input = filepath.Base(turn.AudioFile)
+	}
+
+	start := time.Now()
+	onAudio := func([]byte) error {
+		if tr.FirstAudio == 0 {
+			tr.FirstAudio = time.Since(start)
+		}
+		return nil
+	}
+	var err error
+	switch {
+	case audio != nil:
+		tr.Transcript, _, err = r.Orchestrator.ProcessAudio(ctx, session, audio, true, onAudio)
+		tr.Response = session.LastAssistant
+	case r.TextOnly:
+		tr.Response, err = r.Orchestrator.Chat(ctx, session, turn.Text)
+	default:
+		tr.Response, err = r.Orchestrator.ProcessTextStream(ctx, session, turn.Text, onAudio)
+	}
+	tr.Latency = time.Since(start)
+	if err != nil {
+		tr.Error = err.Error()
+	}
+	return tr
+}
+
+// graders builds a turn's graders from its expectations, then adds its own
+// and the runner's.
+func (r *Runner) graders(turn Turn) []Grader {
+	var gs []Grader
+	for _, p := range turn.Expect {
+		gs = append(gs, Regex(p))
+	}
+	for _, p := range turn.Forbid {
+		gs = append(gs, NotRegex(p))
+	}
+	if turn.MaxLatencyMS > 0 {
+		gs = append(gs, MaxLatency(time.Duration(turn.MaxLatencyMS)*time.Millisecond))
+	}
+	if turn.Judge != "" {
+		gs = append(gs, &LLMJudge{LLM: r.Judge, Rubric: turn.Judge})
+	}
+	gs = append(gs, turn.Graders...)
+	return append(gs, r.Graders...)
+}
@@ -0,0 +1,124 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+type fixedSTT struct{ text string }
+
+func (s fixedSTT) Transcribe(ctx context.Context, audio []byte, lang orchestrator.Language) (orchestrator.TranscriptionResult, error) {
+	return orchestrator.TranscriptionResult{Text: s.text}, nil
+}
+
+func (s fixedSTT) Name() string { return "fixed" }
+
+// shopLLM answers questions about opening hours and refuses everything else.
+type shopLLM struct{}
+
+func (shopLLM) Complete(ctx context.Context, messages []orchestrator.Message, tools []orchestrator.Tool) (string, error) {
+	if strings.Contains(messages[len(messages)-1].Content, "open") {
+		return "We open at 9am.", nil
+	}
+	return "Sorry, I can only help with opening hours.", nil
+}
+
+func (shopLLM) Name() string { return "shop" }
+
+type silentTTS struct{}
+
+func (silentTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	return []byte{0, 0}, nil
+}
+
+func (silentTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	return onChunk([]byte{0, 0})
+}
+
+func (silentTTS) Abort() error { return nil }
+
+func (silentTTS) Name() string { return "silent" }
+
+type judgeLLM struct{ reply string }
+
+func (j judgeLLM) Complete(ctx context.Context, messages []orchestrator.Message, tools []orchestrator.Tool) (string, error) {
+	return j.reply, nil
+}
+
+func (judgeLLM) Name() string { return "judge" }
+
+func newRunner() *Runner {
+	o := orchestrator.NewWithVAD(fixedSTT{text: "when do you open"}, shopLLM{}, silentTTS{}, nil, orchestrator.DefaultConfig())
+	return &Runner{Orchestrator: o, Judge: judgeLLM{reply: `{"score": 5, "reason": "Polite and on topic."}`}}
+}
+
+func TestRunner_GradesTurns(t *testing.T) {
+	r := newRunner()
+	report := r.Run(context.Background(), Script{
+		Name: "opening hours",
+		Turns: []Turn{
+			{Text: "When do you open?", Expect: []string{`9\s*am`}, MaxLatencyMS: 1000, Judge: "Answers politely"},
+			{Audio: []byte{1, 2, 3, 4}, Expect: []string{"9am"}, Graders: []Grader{MaxFirstAudio(time.Second)}},
+			{Text: "Can I get a refund?", Expect: []string{"refund"}, Forbid: []string{"sorry"}},
+		},
+	})
+
+	if report.Passed() {
+		t.Fatal("expected the refund turn to fail")
+	}
+	turns := report.Scripts[0].Turns
+	if !turns[0].Passed() || len(turns[0].Scores) != 3 || turns[0].Scores[2].Value != 5 {
+		t.Errorf("turn 1: %+v", turns[0])
+	}
+	if !turns[1].Passed() || turns[1].Transcript != "when do you open" || turns[1].FirstAudio == 0 {
+		t.Errorf("turn 2: %+v", turns[1])
+	}
+	if turns[2].Passed() || turns[2].Scores[0].Passed || turns[2].Scores[1].Passed {
+		t.Errorf("turn 3: %+v", turns[2])
+	}
+	if passed, total := report.Counts(); passed != 2 || total != 3 {
+		t.Errorf("counts %d/%d", passed, total)
+	}
+
+	var out bytes.Buffer
+	report.WriteText(&out)
+	if !strings.Contains(out.String(), "FAIL opening hours") || !strings.Contains(out.String(), "failed not regex sorry") {
+		t.Errorf("unexpected text report:\n%s", out.String())
+	}
+}
+
+func TestRunner_JudgeFailsLowRatings(t *testing.T) {
+	r := newRunner()
+	r.Judge = judgeLLM{reply: "```json\n{\"score\": 2, \"reason\": \"Curt.\"}\n```"}
+	r.TextOnly = true
+	report := r.Run(context.Background(), Script{Name: "tone", Turns: []Turn{{Text: "When do you open?", Judge: "Warm tone"}}})
+	if s := report.Scripts[0].Turns[0].Scores[0]; s.Passed || s.Value != 2 || s.Detail != "Curt." {
+		t.Errorf("got %+v", s)
+	}
+}
+
+func TestLoadScripts(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "hello.pcm"), []byte{1, 2}, 0o644)
+	path := filepath.Join(dir, "suite.json")
+	os.WriteFile(path, []byte(`{"name": "audio", "turns": [{"audio_file": "hello.pcm", "expect": ["9am"]}]}`), 0o644)
+
+	scripts, err := LoadScripts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) != 1 || scripts[0].Turns[0].AudioFile != filepath.Join(dir, "hello.pcm") {
+		t.Fatalf("got %+v", scripts)
+	}
+	report := newRunner().Run(context.Background(), scripts...)
+	if !report.Passed() || report.Scripts[0].Turns[0].Input != "hello.pcm" {
+		t.Errorf("got %+v", report.Scripts[0].Turns[0])
+	}
+}
@@ -0,0 +1,154 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Score is one grader's verdict on a turn.
+type Score struct {
+	Grader string  `json:"grader"`
+	Passed bool    `json:"passed"`
+	Value  float64 `json:"value,omitempty"` // Grader-specific, e.g. a judge's 1-5 rating
+	Detail string  `json:"detail,omitempty"`
+}
+
+// Grader scores the result of one turn.
+type Grader interface {
+	Grade(ctx context.Context, r TurnResult) Score
+}
+
+// GraderFunc adapts a function to Grader.
+type GraderFunc func(ctx context.Context, r TurnResult) Score
+
+func (f GraderFunc) Grade(ctx context.Context, r TurnResult) Score { return f(ctx, r) }
+
+// Regex passes when the reply matches pattern. Patterns are case-insensitive
+// unless they set flags of their own.
+func Regex(pattern string) Grader {
+	return regexGrader{pattern: pattern, want: true}
+}
+
+// NotRegex passes when the reply does not match pattern.
+func NotRegex(pattern string) Grader {
+	return regexGrader{pattern: pattern, want: false}
+}
+
+type regexGrader struct {
+	pattern string
+	want    bool
+}
+
+func (g regexGrader) Grade(ctx context.Context, r TurnResult) Score {
+	name := "regex " + g.pattern
+	if !g.want {
+		name = "not " + name
+	}
+	pattern := g.pattern
+	if !strings.HasPrefix(pattern, "(?") {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Score{Grader: name, Detail: err.Error()}
+	}
+	if r.Error != "" {
+		return Score{Grader: name, Detail: "turn failed: " + r.Error}
+	}
+	matched := re.MatchString(r.Response)
+	s := Score{Grader: name, Passed: matched == g.want}
+	if !s.Passed {
+		s.Detail = fmt.Sprintf("reply %q", r.Response)
+	}
+	return s
+}
+
+// MaxLatency passes when the whole turn took at most d.
+func MaxLatency(d time.Duration) Grader {
+	return GraderFunc(func(ctx context.Context, r TurnResult) Score {
+		return Score{
+			Grader: "latency <= " + d.String(),
+			Passed: r.Error == "" && r.Latency <= d,
+			Value:  float64(r.Latency.Milliseconds()),
+			Detail: r.Latency.String(),
+		}
+	})
+}
+
+// MaxFirstAudio passes when the reply's first audio arrived within d.
+func MaxFirstAudio(d time.Duration) Grader {
+	return GraderFunc(func(ctx context.Context, r TurnResult) Score {
+		return Score{
+			Grader: "first audio <= " + d.String(),
+			Passed: r.Error == "" && r.FirstAudio > 0 && r.FirstAudio <= d,
+			Value:  float64(r.FirstAudio.Milliseconds()),
+			Detail: r.FirstAudio.String(),
+		}
+	})
+}
+
+// LLMJudge has an LLM rate the reply against a rubric from 1 to 5, seeing
+// the conversation up to it.
+type LLMJudge struct {
+	LLM      orchestrator.LLMProvider
+	Rubric   string
+	MinScore int // Lowest passing rating; defaults to 4
+}
+
+const judgePrompt = `You grade the last reply of an assistant in a conversation.
+Rubric: %s
+Rate how well the last reply meets the rubric from 1 (not at all) to 5 (fully).
+Reply with only a JSON object: {"score": <1-5>, "reason": "<one sentence>"}.`
+
+func (j *LLMJudge) Grade(ctx context.Context, r TurnResult) Score {
+	s := Score{Grader: "judge: " + j.Rubric}
+	if j.LLM == nil {
+		s.Detail = "no judge LLM configured"
+		return s
+	}
+	if r.Error != "" {
+		s.Detail = "turn failed: " + r.Error
+		return s
+	}
+
+	var transcript strings.Builder
+	for _, m := range r.Context {
+		if (m.Role == orchestrator.RoleUser || m.Role == orchestrator.RoleAssistant) && m.Content != "" {
+			fmt.Fprintf(&transcript, "%s: %s\n", m.Role, m.Content)
+		}
+	}
+	reply, err := j.LLM.Complete(ctx, []orchestrator.Message{
+		{Role: orchestrator.RoleSystem, Content: fmt.Sprintf(judgePrompt, j.Rubric)},
+		{Role: orchestrator.RoleUser, Content: transcript.String()},
+	}, nil)
+	if err != nil {
+		s.Detail = "judge failed: " + err.Error()
+		return s
+	}
+
+	var verdict struct {
+		Score  float64 `json:"score"`
+		Reason string  `json:"reason"`
+	}
+	if start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}"); start >= 0 && end > start {
+		reply = reply[start : end+1]
+	}
+	if err := json.Unmarshal([]byte(reply), &verdict); err != nil {
+		s.Detail = fmt.Sprintf("unreadable verdict %q", reply)
+		return s
+	}
+	min := j.MinScore
+	if min <= 0 {
+		min = 4
+	}
+	s.Value = verdict.Score
+	s.Passed = verdict.Score >= float64(min)
+	s.Detail = verdict.Reason
+	return s
+}
@@ -0,0 +1,130 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// TurnResult is what one scripted turn produced, and how it was graded.
+type TurnResult struct {
+	Index      int                    `json:"index"`
+	Input      string                 `json:"input"` // The text, or the audio file's name
+	Transcript string                 `json:"transcript,omitempty"`
+	Response   string                 `json:"response"`
+	Latency    time.Duration          `json:"latency"`
+	FirstAudio time.Duration          `json:"first_audio,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Scores     []Score                `json:"scores"`
+	Context    []orchestrator.Message `json:"-"` // The session after the turn
+}
+
+// Passed reports whether every grader passed the turn. A turn that failed
+// passes only if nothing graded it.
+func (t TurnResult) Passed() bool {
+	if t.Error != "" && len(t.Scores) == 0 {
+		return false
+	}
+	for _, s := range t.Scores {
+		if !s.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// ScriptResult holds the turns of one script, or of one simulated call.
+type ScriptResult struct {
+	Name    string       `json:"name"`
+	Turns   []TurnResult `json:"turns"`
+	Outcome string       `json:"outcome,omitempty"` // How a simulated call ended
+	Error   string       `json:"error,omitempty"`   // Why a simulated call stopped early
+}
+
+// Passed reports whether every turn passed and, for a simulated call,
+// whether the caller met their goal.
+func (s ScriptResult) Passed() bool {
+	if s.Error != "" || s.Outcome != "" && s.Outcome != OutcomeGoalMet {
+		return false
+	}
+	for _, t := range s.Turns {
+		if !t.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Report is the outcome of a Runner.Run.
+type Report struct {
+	StartedAt time.Time      `json:"started_at"`
+	Duration  time.Duration  `json:"duration"`
+	Scripts   []ScriptResult `json:"scripts"`
+}
+
+// Passed reports whether every turn of every script passed; gate on it.
+func (r *Report) Passed() bool {
+	for _, s := range r.Scripts {
+		if !s.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Counts returns how many turns passed out of how many were played.
+func (r *Report) Counts() (passed, total int) {
+	for _, s := range r.Scripts {
+		for _, t := range s.Turns {
+			total++
+			if t.Passed() {
+				passed++
+			}
+		}
+	}
+	return passed, total
+}
+
+// WriteJSON writes the report as indented JSON, e.g. as a CI artifact.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes a readable summary listing every failed check.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, s := range r.Scripts {
+		status := "PASS"
+		if !s.Passed() {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s %s\n", status, s.Name)
+		if s.Outcome != "" && s.Outcome != OutcomeGoalMet {
+			fmt.Fprintf(w, "  call ended: %s\n", s.Outcome)
+		}
+		if s.Error != "" {
+			fmt.Fprintf(w, "  error: %s\n", s.Error)
+		}
+		for _, t := range s.Turns {
+			if t.Passed() {
+				continue
+			}
+			fmt.Fprintf(w, "  turn %d %q -> %q (%v)\n", t.Index+1, t.Input, t.Response, t.Latency.Round(time.Millisecond))
+			if t.Error != "" {
+				fmt.Fprintf(w, "    error: %s\n", t.Error)
+			}
+			for _, sc := range t.Scores {
+				if !sc.Passed {
+					fmt.Fprintf(w, "    failed %s: %s\n", sc.Grader, sc.Detail)
+				}
+			}
+		}
+	}
+	passed, total := r.Counts()
+	_, err := fmt.Fprintf(w, "%d/%d turns passed in %v\n", passed, total, r.Duration.Round(time.Millisecond))
+	return err
+}
@@ -0,0 +1,165 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Persona is a synthetic caller: who they are, what they want from the
+// call, and how they sound.
+type Persona struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`       // E.g. "an impatient retiree who mishears numbers"
+	Goal        string                `json:"goal"`              // What the call must achieve, e.g. "move Friday's delivery to Monday"
+	Opening     string                `json:"opening,omitempty"` // First line; "" lets the caller LLM open
+	MaxTurns    int                   `json:"max_turns,omitempty"`
+	Voice       orchestrator.Voice    `json:"voice,omitempty"`
+	Language    orchestrator.Language `json:"language,omitempty"`
+}
+
+// Markers the caller LLM ends the call with.
+const (
+	goalMetMarker = "[GOAL MET]"
+	hangUpMarker  = "[HANG UP]"
+)
+
+// Call outcomes, as reported in ScriptResult.Outcome.
+const (
+	OutcomeGoalMet  = "goal_met"
+	OutcomeHungUp   = "hung_up"   // The caller gave up on the goal
+	OutcomeMaxTurns = "max_turns" // The call ran out of turns first
+	OutcomeError    = "error"     // The caller LLM or its TTS failed
+)
+
+const defaultMaxTurns = 10
+
+// Simulator puts synthetic callers through the orchestrator end to end: an
+// LLM plays each Persona, speaking to the agent until the goal is met, it
+// gives up, or MaxTurns runs out. With a Speaker the caller's lines are
+// synthesized and go through STT like a real call; without one they are
+// sent as text. Each call is reported as a ScriptResult named after the
+// persona, whose turns are graded like scripted ones. Run several callers
+// at once with Concurrency for load testing.
+type Simulator struct {
+	Orchestrator *orchestrator.Orchestrator
+	Caller       orchestrator.LLMProvider // Speaks for the personas
+	Speaker      orchestrator.TTSProvider // Voices the caller; nil sends text
+	SystemPrompt string                   // The agent's
+	Graders      []Grader                 // Applied to every agent reply, e.g. an LLMJudge
+	Concurrency  int                      // Calls in flight; 0 or 1 runs them one by one
+}
+
+// Run places one call per persona and reports them in the order given.
+func (s *Simulator) Run(ctx context.Context, personas ...Persona) *Report {
+	report := &Report{StartedAt: time.Now(), Scripts: make([]ScriptResult, len(personas))}
+	sem := make(chan struct{}, max(s.Concurrency, 1))
+	var wg sync.WaitGroup
+	for i, p := range personas {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			report.Scripts[i] = s.call(ctx, p)
+		}()
+	}
+	wg.Wait()
+	report.Duration = time.Since(report.StartedAt)
+	return report
+}
+
+func (s *Simulator) call(ctx context.Context, p Persona) ScriptResult {
+	o := s.Orchestrator
+	session := o.NewSessionWithDefaults("sim-" + p.Name)
+	if s.SystemPrompt != "" {
+		o.SetSystemPrompt(session, s.SystemPrompt)
+	}
+	runner := &Runner{Orchestrator: o, Graders: s.Graders}
+	result := ScriptResult{Name: p.Name, Outcome: OutcomeMaxTurns}
+
+	// The caller LLM sees the call from its side: its own lines are the
+	// assistant's, the agent's are the user's.
+	caller := []orchestrator.Message{{Role: orchestrator.RoleSystem, Content: personaPrompt(p)}}
+	maxTurns := p.MaxTurns
+	if maxTurns <= 0 {
+		maxTurns = defaultMaxTurns
+	}
+	for i := 0; i < maxTurns; i++ {
+		line := p.Opening
+		if i > 0 || line == "" {
+			var err error
+			if line, err = s.Caller.Complete(ctx, caller, nil); err != nil {
+				result.Outcome = OutcomeError
+				result.Error = fmt.Sprintf("caller: %v", err)
+				return result
+			}
+		}
+		line, outcome := endOfCall(line)
+		if line == "" {
+			if outcome == "" {
+				outcome = OutcomeHungUp // Said nothing
+			}
+			result.Outcome = outcome
+			return result
+		}
+
+		turn := Turn{Text: line}
+		if s.Speaker != nil {
+			audio, err := s.Speaker.Synthesize(ctx, line, p.Voice, p.Language)
+			if err != nil {
+				result.Outcome = OutcomeError
+				result.Error = fmt.Sprintf("caller voice: %v", err)
+				return result
+			}
+			turn.Audio = audio
+		}
+		tr := runner.play(ctx, session, turn)
+		tr.Index = i
+		tr.Input = line
+		tr.Context = session.GetContextCopy()
+		for _, g := range runner.graders(Turn{}) {
+			tr.Scores = append(tr.Scores, g.Grade(ctx, tr))
+		}
+		result.Turns = append(result.Turns, tr)
+
+		if outcome != "" {
+			result.Outcome = outcome
+			return result
+		}
+		caller = append(caller,
+			orchestrator.Message{Role: orchestrator.RoleAssistant, Content: line},
+			orchestrator.Message{Role: orchestrator.RoleUser, Content: tr.Response})
+	}
+	return result
+}
+
+func personaPrompt(p Persona) string {
+	var b strings.Builder
+	b.WriteString("You are role-playing a caller phoning a voice assistant, to test it. Stay in character throughout.\n")
+	if p.Description != "" {
+		fmt.Fprintf(&b, "Who you are: %s\n", p.Description)
+	}
+	fmt.Fprintf(&b, "Your goal for this call: %s\n", p.Goal)
+	b.WriteString("Reply with exactly what you say next, as spoken words only: no stage directions, no quotes.\n")
+	fmt.Fprintf(&b, "Once your goal has been achieved, say goodbye and end with %s. If you give up on it, end with %s.", goalMetMarker, hangUpMarker)
+	return b.String()
+}
+
+// endOfCall strips an end-of-call marker from a caller line and returns the
+// outcome it stands for, or "" if the call goes on.
+func endOfCall(line string) (string, string) {
+	outcome := ""
+	switch {
+	case strings.Contains(line, hangUpMarker):
+		outcome = OutcomeHungUp
+	case strings.Contains(line, goalMetMarker):
+		outcome = OutcomeGoalMet
+	}
+	line = strings.ReplaceAll(line, hangUpMarker, "")
+	line = strings.ReplaceAll(line, goalMetMarker, "")
+	return strings.TrimSpace(line), outcome
+}
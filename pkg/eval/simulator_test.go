@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// scriptedCaller says its lines in order, keeping what it was shown.
+type scriptedCaller struct {
+	mu    sync.Mutex
+	lines []string
+	seen  [][]orchestrator.Message
+}
+
+func (c *scriptedCaller) Complete(ctx context.Context, messages []orchestrator.Message, tools []orchestrator.Tool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen = append(c.seen, messages)
+	if len(c.lines) == 0 {
+		return "", nil
+	}
+	line := c.lines[0]
+	c.lines = c.lines[1:]
+	return line, nil
+}
+
+func (*scriptedCaller) Name() string { return "caller" }
+
+func TestSimulator_CallerMeetsGoal(t *testing.T) {
+	caller := &scriptedCaller{lines: []string{"When do you open tomorrow?", "Great, thanks. Bye! [GOAL MET]"}}
+	sim := &Simulator{Orchestrator: newRunner().Orchestrator, Caller: caller, Graders: []Grader{Regex("open|help")}}
+
+	report := sim.Run(context.Background(), Persona{Name: "early-bird", Description: "a busy parent", Goal: "find out the opening time"})
+	call := report.Scripts[0]
+	if call.Outcome != OutcomeGoalMet || len(call.Turns) != 2 || !report.Passed() {
+		t.Fatalf("got outcome %q with %d turns: %+v", call.Outcome, len(call.Turns), call)
+	}
+	if call.Turns[0].Response != "We open at 9am." || call.Turns[1].Input != "Great, thanks. Bye!" {
+		t.Errorf("unexpected turns %+v", call.Turns)
+	}
+
+	prompt := caller.seen[0][0].Content
+	if !strings.Contains(prompt, "a busy parent") || !strings.Contains(prompt, "find out the opening time") {
+		t.Errorf("persona missing from caller prompt: %q", prompt)
+	}
+	second := caller.seen[1]
+	if last := second[len(second)-1]; last.Role != orchestrator.RoleUser || last.Content != "We open at 9am." {
+		t.Errorf("caller should hear the agent as the user, got %+v", last)
+	}
+}
+
+func TestSimulator_SpokenCallerGoesThroughSTT(t *testing.T) {
+	caller := &scriptedCaller{lines: []string{"Hi there [HANG UP]"}}
+	sim := &Simulator{Orchestrator: newRunner().Orchestrator, Caller: caller, Speaker: silentTTS{}}
+
+	call := sim.Run(context.Background(), Persona{Name: "mumbler", Goal: "ask anything"}).Scripts[0]
+	if call.Outcome != OutcomeHungUp || call.Passed() {
+		t.Fatalf("a caller who hung up must fail the call, got %+v", call)
+	}
+	if tr := call.Turns[0]; tr.Input != "Hi there" || tr.Transcript != "when do you open" {
+		t.Errorf("expected the synthesized line to be transcribed, got %+v", tr)
+	}
+}
+
+func TestSimulator_ConcurrentCallsStopAtMaxTurns(t *testing.T) {
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, "Are you open?")
+	}
+	sim := &Simulator{Orchestrator: newRunner().Orchestrator, Caller: &scriptedCaller{lines: lines}, Concurrency: 3}
+
+	var personas []Persona
+	for _, name := range []string{"a", "b", "c"} {
+		personas = append(personas, Persona{Name: name, Goal: "keep asking", Opening: "Hello?", MaxTurns: 3})
+	}
+	report := sim.Run(context.Background(), personas...)
+	for i, call := range report.Scripts {
+		if call.Name != personas[i].Name || call.Outcome != OutcomeMaxTurns || len(call.Turns) != 3 {
+			t.Errorf("call %d: got %q %q with %d turns", i, call.Name, call.Outcome, len(call.Turns))
+		}
+		if call.Turns[0].Input != "Hello?" {
+			t.Errorf("call %d should open with the persona's line, got %q", i, call.Turns[0].Input)
+		}
+	}
+	if report.Passed() {
+		t.Error("calls that never met their goal must fail the report")
+	}
+}
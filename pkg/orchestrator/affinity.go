@@ -0,0 +1,170 @@
+package orchestrator
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultRingReplicas is the number of virtual nodes per instance. More
+// replicas spread sessions more evenly at the cost of a larger ring.
+const DefaultRingReplicas = 128
+
+// RebalanceEvent is delivered to OnRebalance listeners whenever the set of
+// instances changes. Moved tells a listener whether a session it holds in
+// memory now belongs to another instance and should be handed off.
+type RebalanceEvent struct {
+	Added   []string
+	Removed []string
+	Nodes   []string
+
+	before *ringState
+	after  *ringState
+}
+
+// Moved reports the previous and new owner of sessionID and whether they differ.
+func (e RebalanceEvent) Moved(sessionID string) (from, to string, moved bool) {
+	from, to = e.before.locate(sessionID), e.after.locate(sessionID)
+	return from, to, from != to
+}
+
+type ringState struct {
+	hashes []uint64
+	owners map[uint64]string
+	nodes  []string
+}
+
+func (r *ringState) locate(key string) string {
+	if r == nil || len(r.hashes) == 0 {
+		return ""
+	}
+	h := ringHash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
+
+// HashRing maps session IDs to orchestrator instances with consistent
+// hashing, so adding or removing an instance only moves the sessions that
+// hashed to it. Use it for sticky in-memory sessions; deployments with a
+// shared SessionStore don't need affinity.
+type HashRing struct {
+	mu        sync.RWMutex
+	replicas  int
+	nodes     map[string]bool
+	state     *ringState
+	listeners []func(RebalanceEvent)
+}
+
+func NewHashRing(replicas int, nodes ...string) *HashRing {
+	if replicas <= 0 {
+		replicas = DefaultRingReplicas
+	}
+	r := &HashRing{replicas: replicas, nodes: make(map[string]bool)}
+	for _, n := range nodes {
+		r.nodes[n] = true
+	}
+	r.state = r.build()
+	return r
+}
+
+// OnRebalance registers a listener called synchronously after every membership change.
+func (r *HashRing) OnRebalance(fn func(RebalanceEvent)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+func (r *HashRing) Add(nodes ...string) {
+	r.update(nodes, nil)
+}
+
+func (r *HashRing) Remove(nodes ...string) {
+	r.update(nil, nodes)
+}
+
+// Locate returns the instance that owns sessionID, or "" if the ring is empty.
+func (r *HashRing) Locate(sessionID string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.state.locate(sessionID)
+}
+
+func (r *HashRing) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.state.nodes...)
+}
+
+func (r *HashRing) update(add, remove []string) {
+	r.mu.Lock()
+	var added, removed []string
+	for _, n := range add {
+		if !r.nodes[n] {
+			r.nodes[n] = true
+			added = append(added, n)
+		}
+	}
+	for _, n := range remove {
+		if r.nodes[n] {
+			delete(r.nodes, n)
+			removed = append(removed, n)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	before := r.state
+	r.state = r.build()
+	event := RebalanceEvent{
+		Added:   added,
+		Removed: removed,
+		Nodes:   append([]string(nil), r.state.nodes...),
+		before:  before,
+		after:   r.state,
+	}
+	listeners := append([]func(RebalanceEvent){}, r.listeners...)
+	r.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+func (r *HashRing) build() *ringState {
+	s := &ringState{owners: make(map[uint64]string, len(r.nodes)*r.replicas)}
+	for n := range r.nodes {
+		s.nodes = append(s.nodes, n)
+	}
+	sort.Strings(s.nodes)
+	for _, n := range s.nodes {
+		for i := 0; i < r.replicas; i++ {
+			h := ringHash(n + "#" + strconv.Itoa(i))
+			if _, taken := s.owners[h]; taken {
+				continue
+			}
+			s.owners[h] = n
+			s.hashes = append(s.hashes, h)
+		}
+	}
+	sort.Slice(s.hashes, func(i, j int) bool { return s.hashes[i] < s.hashes[j] })
+	return s
+}
+
+// ringHash is FNV-1a followed by a splitmix64 finaliser; FNV alone clusters
+// badly for keys that differ only in a trailing counter.
+func ringHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
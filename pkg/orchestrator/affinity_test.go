@@ -0,0 +1,74 @@
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHashRing_Distribution(t *testing.T) {
+	ring := NewHashRing(0, "a", "b", "c")
+	counts := map[string]int{}
+	for i := 0; i < 3000; i++ {
+		counts[ring.Locate(fmt.Sprintf("session-%d", i))]++
+	}
+	for _, n := range []string{"a", "b", "c"} {
+		if counts[n] < 700 || counts[n] > 1300 {
+			t.Errorf("node %s got %d of 3000 sessions, expected roughly a third", n, counts[n])
+		}
+	}
+	if ring.Locate("session-1") != ring.Locate("session-1") {
+		t.Error("Locate must be deterministic")
+	}
+}
+
+func TestHashRing_RebalanceMovesOnlyAffectedSessions(t *testing.T) {
+	ring := NewHashRing(64, "a", "b", "c")
+	owners := map[string]string{}
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("s%d", i)
+		owners[id] = ring.Locate(id)
+	}
+
+	var events []RebalanceEvent
+	ring.OnRebalance(func(e RebalanceEvent) { events = append(events, e) })
+
+	ring.Add("d")
+	ring.Add("d")
+	if len(events) != 1 {
+		t.Fatalf("expected exactly one rebalance event, got %d", len(events))
+	}
+	if len(events[0].Added) != 1 || events[0].Added[0] != "d" || len(events[0].Nodes) != 4 {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+
+	movedCount := 0
+	for id, prev := range owners {
+		from, to, moved := events[0].Moved(id)
+		if from != prev {
+			t.Fatalf("event reports wrong previous owner for %s: %s vs %s", id, from, prev)
+		}
+		if moved {
+			movedCount++
+			if to != "d" {
+				t.Errorf("session %s moved to %s, expected only moves onto the new node", id, to)
+			}
+		}
+	}
+	if movedCount == 0 || movedCount > 400 {
+		t.Errorf("expected about a quarter of sessions to move, got %d", movedCount)
+	}
+
+	ring.Remove("d")
+	for id, prev := range owners {
+		if ring.Locate(id) != prev {
+			t.Errorf("session %s did not return to %s after removing d", id, prev)
+		}
+	}
+}
+
+func TestHashRing_Empty(t *testing.T) {
+	ring := NewHashRing(8)
+	if got := ring.Locate("x"); got != "" {
+		t.Errorf("expected empty owner, got %q", got)
+	}
+}
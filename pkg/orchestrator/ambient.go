@@ -0,0 +1,147 @@
+package orchestrator
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// AmbientConfig configures always-on listening. Speech is transcribed into
+// a rolling window but only becomes a conversation turn when it contains a
+// wake word or hot phrase.
+type AmbientConfig struct {
+	WakeWords  []string      // e.g. "hey lokutor"
+	HotPhrases []string      // Phrases that promote without being addressed, e.g. "call an ambulance"
+	Window     time.Duration // How far back the transcript window reaches; default 60s
+	MaxEntries int           // Cap on remembered utterances; default 50
+	// FollowUp keeps the stream awake after a promoted turn so the user can
+	// carry on without repeating the wake word. 0 requires it every time.
+	FollowUp time.Duration
+}
+
+// AmbientEntry is one utterance heard in ambient mode.
+type AmbientEntry struct {
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+}
+
+// WakeEventData describes a promotion out of ambient mode.
+type WakeEventData struct {
+	Phrase  string         `json:"phrase"`
+	Context []AmbientEntry `json:"context,omitempty"` // Utterances heard before the trigger
+}
+
+type ambientState struct {
+	mu         sync.Mutex
+	cfg        *AmbientConfig
+	entries    []AmbientEntry
+	awakeUntil time.Time
+}
+
+// EnableAmbient switches the stream to ambient listening.
+func (ms *ManagedStream) EnableAmbient(cfg AmbientConfig) {
+	if cfg.Window <= 0 {
+		cfg.Window = 60 * time.Second
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 50
+	}
+	ms.ambient.mu.Lock()
+	defer ms.ambient.mu.Unlock()
+	ms.ambient.cfg = &cfg
+	ms.ambient.entries = nil
+	ms.ambient.awakeUntil = time.Time{}
+}
+
+// DisableAmbient returns the stream to treating every utterance as a turn.
+func (ms *ManagedStream) DisableAmbient() {
+	ms.ambient.mu.Lock()
+	defer ms.ambient.mu.Unlock()
+	ms.ambient.cfg = nil
+	ms.ambient.entries = nil
+}
+
+// AmbientTranscript returns the utterances currently in the window.
+func (ms *ManagedStream) AmbientTranscript() []AmbientEntry {
+	ms.ambient.mu.Lock()
+	defer ms.ambient.mu.Unlock()
+	return append([]AmbientEntry(nil), ms.ambient.entries...)
+}
+
+// ambientCapture files a final transcript in ambient mode. It reports true
+// when the utterance was only overheard and must not become a turn. On a
+// wake word or hot phrase the preceding window is added to the session as
+// context and the utterance goes on to become a normal turn.
+func (ms *ManagedStream) ambientCapture(transcript string) bool {
+	ms.ambient.mu.Lock()
+	cfg := ms.ambient.cfg
+	if cfg == nil {
+		ms.ambient.mu.Unlock()
+		return false
+	}
+	now := time.Now()
+
+	if now.Before(ms.ambient.awakeUntil) {
+		ms.ambient.awakeUntil = now.Add(cfg.FollowUp)
+		ms.ambient.mu.Unlock()
+		return false
+	}
+
+	phrase := matchPhrase(transcript, cfg.WakeWords)
+	if phrase == "" {
+		phrase = matchPhrase(transcript, cfg.HotPhrases)
+	}
+	if phrase == "" {
+		ms.ambient.entries = append(ms.ambient.entries, AmbientEntry{Text: transcript, At: now})
+		ms.pruneAmbientLocked(now)
+		ms.ambient.mu.Unlock()
+		ms.emit(AmbientTranscript, transcript)
+		return true
+	}
+
+	ms.pruneAmbientLocked(now)
+	heard := ms.ambient.entries
+	ms.ambient.entries = nil
+	if cfg.FollowUp > 0 {
+		ms.ambient.awakeUntil = now.Add(cfg.FollowUp)
+	}
+	ms.ambient.mu.Unlock()
+
+	if len(heard) > 0 {
+		lines := make([]string, len(heard))
+		for i, e := range heard {
+			lines[i] = e.Text
+		}
+		ms.session.AddMessage("system", "Overheard before the user addressed you:\n"+strings.Join(lines, "\n"))
+	}
+	ms.emit(WakeWordDetected, WakeEventData{Phrase: phrase, Context: heard})
+	return false
+}
+
+func (ms *ManagedStream) pruneAmbientLocked(now time.Time) {
+	cfg := ms.ambient.cfg
+	entries := ms.ambient.entries
+	for len(entries) > 0 && (now.Sub(entries[0].At) > cfg.Window || len(entries) > cfg.MaxEntries) {
+		entries = entries[1:]
+	}
+	ms.ambient.entries = entries
+}
+
+// matchPhrase returns the first phrase found in text as a run of whole
+// words, ignoring case and punctuation.
+func matchPhrase(text string, phrases []string) string {
+	norm := " " + normalizeWords(text) + " "
+	for _, p := range phrases {
+		if np := normalizeWords(p); np != "" && strings.Contains(norm, " "+np+" ") {
+			return p
+		}
+	}
+	return ""
+}
+
+func normalizeWords(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	}), " ")
+}
@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedSTT returns its transcripts in order, one per call.
+type scriptedSTT struct {
+	texts []string
+}
+
+func (s *scriptedSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	if len(s.texts) == 0 {
+		return TranscriptionResult{}, nil
+	}
+	text := s.texts[0]
+	s.texts = s.texts[1:]
+	return TranscriptionResult{Text: text}, nil
+}
+
+func (s *scriptedSTT) Name() string { return "scripted" }
+
+func drainTypes(ms *ManagedStream) []EventType {
+	var types []EventType
+	for {
+		select {
+		case ev := <-ms.Events():
+			types = append(types, ev.Type)
+		case <-time.After(50 * time.Millisecond):
+			return types
+		}
+	}
+}
+
+func hasEvent(types []EventType, want EventType) bool {
+	for _, t := range types {
+		if t == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestManagedStream_AmbientPromotesOnWakeWord(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	stt := &scriptedSTT{texts: []string{
+		"we're out of milk",
+		"and eggs too",
+		"Hey Kitchen, add those to my list.",
+		"thanks",
+	}}
+	orch := New(stt, &MockLLMProvider{completeResult: "Added milk and eggs."}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, cfg, &NoOpLogger{})
+	session := NewConversationSession("home")
+	ms := orch.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+	ms.EnableAmbient(AmbientConfig{WakeWords: []string{"hey kitchen"}, FollowUp: time.Minute})
+
+	audio := make([]byte, 3200)
+	ms.runBatchPipeline(audio)
+	ms.runBatchPipeline(audio)
+	if types := drainTypes(ms); hasEvent(types, BotThinking) || !hasEvent(types, AmbientTranscript) {
+		t.Fatalf("overheard speech should not start a turn, got %v", types)
+	}
+	if got := ms.AmbientTranscript(); len(got) != 2 || got[1].Text != "and eggs too" {
+		t.Fatalf("unexpected window: %+v", got)
+	}
+
+	ms.runBatchPipeline(audio)
+	types := drainTypes(ms)
+	if !hasEvent(types, WakeWordDetected) || !hasEvent(types, BotResponse) {
+		t.Fatalf("wake word should promote to a turn, got %v", types)
+	}
+
+	msgs := session.GetContextCopy()
+	if len(msgs) < 3 || msgs[0].Role != "system" || !strings.Contains(msgs[0].Content, "out of milk") || msgs[1].Role != "user" {
+		t.Errorf("expected preceding context before the user turn, got %+v", msgs)
+	}
+	if len(ms.AmbientTranscript()) != 0 {
+		t.Error("window should be consumed by the promotion")
+	}
+
+	// Within the follow-up window no wake word is needed.
+	ms.runBatchPipeline(audio)
+	if types := drainTypes(ms); !hasEvent(types, TranscriptFinal) {
+		t.Errorf("follow-up should be a normal turn, got %v", types)
+	}
+}
+
+func TestAmbientWindowAndMatching(t *testing.T) {
+	ms := &ManagedStream{}
+	ms.EnableAmbient(AmbientConfig{MaxEntries: 2, Window: time.Minute})
+	now := time.Now()
+	ms.ambient.entries = []AmbientEntry{{Text: "old", At: now.Add(-2 * time.Minute)}, {Text: "a", At: now}, {Text: "b", At: now}, {Text: "c", At: now}}
+	ms.pruneAmbientLocked(now)
+	if got := ms.AmbientTranscript(); len(got) != 2 || got[0].Text != "b" {
+		t.Errorf("expected the two newest entries, got %+v", got)
+	}
+
+	if matchPhrase("Okay, HEY kitchen!", []string{"hey kitchen"}) != "hey kitchen" {
+		t.Error("expected case- and punctuation-insensitive match")
+	}
+	if matchPhrase("they kitchenette", []string{"hey kitchen"}) != "" {
+		t.Error("phrases must match whole words")
+	}
+}
@@ -0,0 +1,260 @@
+package orchestrator
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// AMDClassifier is an optional second opinion for AMDDetector, e.g. an STT
+// call looking for "leave a message" or a trained model. It sees the audio
+// heard so far when the heuristics cannot decide.
+type AMDClassifier interface {
+	Classify(ctx context.Context, audio []byte) (AnsweredBy, error)
+}
+
+// AMDConfig tunes AMDDetector. Durations are measured in audio time.
+type AMDConfig struct {
+	SampleRate      int           // 16-bit mono PCM; telephony is usually 8000
+	SpeechThreshold float64       // Frame RMS above which the far end is talking
+	InitialSilence  time.Duration // Nobody speaks at all: give up as unknown
+	MaxHumanGreet   time.Duration // Continuous speech longer than this is a recording
+	SilenceAfter    time.Duration // Silence that ends a short (human) greeting
+	MinBeep         time.Duration // Pure tone at least this long is a voicemail beep
+	Classifier      AMDClassifier
+}
+
+func DefaultAMDConfig() AMDConfig {
+	return AMDConfig{
+		SampleRate:      8000,
+		SpeechThreshold: 0.02,
+		InitialSilence:  3500 * time.Millisecond,
+		MaxHumanGreet:   2500 * time.Millisecond,
+		SilenceAfter:    800 * time.Millisecond,
+		MinBeep:         150 * time.Millisecond,
+	}
+}
+
+// Beeps sit in this band; speech rarely stays this tonal for long.
+const (
+	amdBeepMinHz  = 300.0
+	amdBeepMaxHz  = 2500.0
+	amdBeepPurity = 0.8
+	amdFrame      = 20 * time.Millisecond
+)
+
+// AMDDetector classifies who answered an outbound call from greeting length
+// and voicemail beeps, deferring to a Classifier when those are
+// inconclusive. It implements AnsweringMachineDetector and BeepWaiter.
+type AMDDetector struct {
+	cfg AMDConfig
+}
+
+func NewAMDDetector(cfg AMDConfig) *AMDDetector {
+	def := DefaultAMDConfig()
+	if cfg.SampleRate <= 0 {
+		cfg.SampleRate = def.SampleRate
+	}
+	if cfg.SpeechThreshold <= 0 {
+		cfg.SpeechThreshold = def.SpeechThreshold
+	}
+	if cfg.InitialSilence <= 0 {
+		cfg.InitialSilence = def.InitialSilence
+	}
+	if cfg.MaxHumanGreet <= 0 {
+		cfg.MaxHumanGreet = def.MaxHumanGreet
+	}
+	if cfg.SilenceAfter <= 0 {
+		cfg.SilenceAfter = def.SilenceAfter
+	}
+	if cfg.MinBeep <= 0 {
+		cfg.MinBeep = def.MinBeep
+	}
+	return &AMDDetector{cfg: cfg}
+}
+
+// amdFrames slices incoming chunks into fixed 20ms frames.
+type amdFrames struct {
+	size    int
+	pending []byte
+}
+
+func (f *amdFrames) push(chunk []byte, fn func(frame []byte) bool) bool {
+	f.pending = append(f.pending, chunk...)
+	for len(f.pending) >= f.size {
+		frame := f.pending[:f.size]
+		f.pending = f.pending[f.size:]
+		if fn(frame) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *AMDDetector) frameBytes() int {
+	return int(float64(d.cfg.SampleRate)*amdFrame.Seconds()) * 2
+}
+
+func (d *AMDDetector) frames(dur time.Duration) int {
+	return int(dur / amdFrame)
+}
+
+func (d *AMDDetector) Detect(ctx context.Context, audio <-chan []byte) (AnsweredBy, error) {
+	var (
+		frames    = amdFrames{size: d.frameBytes()}
+		heard     []byte
+		seen      int
+		speech    int // Frames of the current greeting
+		silence   int // Frames of silence since the greeting paused
+		tone      int
+		decision  AnsweredBy
+		greetings bool
+	)
+
+	step := func(frame []byte) bool {
+		seen++
+		if d.isTone(frame) {
+			tone++
+			if tone >= d.frames(d.cfg.MinBeep) {
+				decision = AnsweredMachine
+				return true
+			}
+		} else {
+			tone = 0
+		}
+
+		if chunkRMS(frame) >= d.cfg.SpeechThreshold {
+			greetings = true
+			speech += silence + 1 // Short pauses belong to the same greeting
+			silence = 0
+			if speech >= d.frames(d.cfg.MaxHumanGreet) {
+				decision = AnsweredMachine
+				return true
+			}
+			return false
+		}
+
+		if !greetings {
+			if seen >= d.frames(d.cfg.InitialSilence) {
+				decision = AnsweredUnknown
+				return true
+			}
+			return false
+		}
+		silence++
+		if silence >= d.frames(d.cfg.SilenceAfter) {
+			decision = AnsweredHuman
+			return true
+		}
+		return false
+	}
+
+	for {
+		select {
+		case chunk, ok := <-audio:
+			if !ok {
+				return d.classify(ctx, heard, AnsweredUnknown)
+			}
+			heard = append(heard, chunk...)
+			if frames.push(chunk, step) {
+				if decision == AnsweredUnknown {
+					return d.classify(ctx, heard, decision)
+				}
+				return decision, nil
+			}
+		case <-ctx.Done():
+			return d.classify(context.WithoutCancel(ctx), heard, AnsweredUnknown)
+		}
+	}
+}
+
+func (d *AMDDetector) classify(ctx context.Context, audio []byte, fallback AnsweredBy) (AnsweredBy, error) {
+	if d.cfg.Classifier == nil || len(audio) == 0 {
+		return fallback, nil
+	}
+	by, err := d.cfg.Classifier.Classify(ctx, audio)
+	if err != nil || by == "" {
+		return fallback, err
+	}
+	return by, nil
+}
+
+// BeepWaiter is implemented by detectors that can tell when a voicemail
+// system starts recording.
+type BeepWaiter interface {
+	WaitForBeep(ctx context.Context, audio <-chan []byte) error
+}
+
+// WaitForBeep returns once a voicemail beep has been heard, or ErrNoBeep if
+// the audio ends first.
+func (d *AMDDetector) WaitForBeep(ctx context.Context, audio <-chan []byte) error {
+	frames := amdFrames{size: d.frameBytes()}
+	tone := 0
+	step := func(frame []byte) bool {
+		if d.isTone(frame) {
+			tone++
+		} else {
+			tone = 0
+		}
+		return tone >= d.frames(d.cfg.MinBeep)
+	}
+	for {
+		select {
+		case chunk, ok := <-audio:
+			if !ok {
+				return ErrNoBeep
+			}
+			if frames.push(chunk, step) {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// isTone reports whether a frame is dominated by a single frequency in the
+// beep band. The frequency is estimated from zero crossings and confirmed
+// with a Goertzel filter.
+func (d *AMDDetector) isTone(frame []byte) bool {
+	samples := bytesToSamples(frame)
+	n := len(samples)
+	if n < 2 {
+		return false
+	}
+	energy := calculateEnergy(samples)
+	if math.Sqrt(energy/float64(n)) < d.cfg.SpeechThreshold {
+		return false
+	}
+	// Half a period lies between neighbouring crossings; measuring from the
+	// first to the last crossing avoids the edge error of a short frame.
+	crossings, first, last := 0, 0, 0
+	for i := 1; i < n; i++ {
+		if (samples[i-1] < 0) != (samples[i] < 0) {
+			if crossings == 0 {
+				first = i
+			}
+			last = i
+			crossings++
+		}
+	}
+	if crossings < 3 {
+		return false
+	}
+	freq := float64(crossings-1) * float64(d.cfg.SampleRate) / (2 * float64(last-first))
+	if freq < amdBeepMinHz || freq > amdBeepMaxHz {
+		return false
+	}
+	purity := goertzelPower(samples, freq, d.cfg.SampleRate) / (energy * float64(n) / 2)
+	return purity >= amdBeepPurity
+}
+
+func goertzelPower(samples []float64, freq float64, sampleRate int) float64 {
+	coeff := 2 * math.Cos(2*math.Pi*freq/float64(sampleRate))
+	var s1, s2 float64
+	for _, x := range samples {
+		s0 := x + coeff*s1 - s2
+		s2, s1 = s1, s0
+	}
+	return s1*s1 + s2*s2 - coeff*s1*s2
+}
@@ -0,0 +1,125 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func amdNoise(r *rand.Rand, ms int, amp float64) []byte {
+	out := make([]byte, 8000*ms/1000*2)
+	for i := 0; i < len(out); i += 2 {
+		v := int16((r.Float64()*2 - 1) * amp * 32767)
+		binary.LittleEndian.PutUint16(out[i:], uint16(v))
+	}
+	return out
+}
+
+func amdSilence(ms int) []byte { return make([]byte, 8000*ms/1000*2) }
+
+func feed(chunks ...[]byte) <-chan []byte {
+	ch := make(chan []byte, 1024)
+	for _, c := range chunks {
+		for len(c) > 0 {
+			n := min(320, len(c))
+			ch <- c[:n]
+			c = c[n:]
+		}
+	}
+	close(ch)
+	return ch
+}
+
+type fixedClassifier AnsweredBy
+
+func (f fixedClassifier) Classify(ctx context.Context, audio []byte) (AnsweredBy, error) {
+	return AnsweredBy(f), nil
+}
+
+func TestAMDDetector(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	beep := generateSine(1000, 300, 8000, 0.4)
+
+	tests := []struct {
+		name       string
+		classifier AMDClassifier
+		audio      <-chan []byte
+		want       AnsweredBy
+	}{
+		{"short hello", nil, (feed(amdNoise(r, 600, 0.3), amdSilence(1000))), AnsweredHuman},
+		{"long greeting", nil, (feed(amdNoise(r, 3000, 0.3))), AnsweredMachine},
+		{"beep", nil, (feed(amdSilence(200), beep)), AnsweredMachine},
+		{"dead air", nil, (feed(amdSilence(4000))), AnsweredUnknown},
+		{"classifier breaks the tie", fixedClassifier(AnsweredHuman), (feed(amdSilence(4000))), AnsweredHuman},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewAMDDetector(AMDConfig{Classifier: tt.classifier})
+			got, err := d.Detect(context.Background(), tt.audio)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAMDDetector_WaitForBeep(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	d := NewAMDDetector(DefaultAMDConfig())
+	audio := feed(amdNoise(r, 500, 0.3), generateSine(850, 250, 8000, 0.4))
+	if err := d.WaitForBeep(context.Background(), audio); err != nil {
+		t.Fatalf("expected the beep to be heard, got %v", err)
+	}
+	if err := d.WaitForBeep(context.Background(), feed(amdNoise(r, 1000, 0.3))); !errors.Is(err, ErrNoBeep) {
+		t.Error("speech alone should not count as a beep")
+	}
+}
+
+func TestOutbound_VoicemailAfterBeep(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	orch := newOutboundOrch()
+	session := NewConversationSession("lead-4")
+
+	call := orch.Dial(context.Background(), session, OutboundOptions{
+		Detector: NewAMDDetector(DefaultAMDConfig()),
+		OnMachine: func(c *OutboundCall) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := c.WaitForBeep(ctx); err != nil {
+				t.Errorf("beep not heard: %v", err)
+				return
+			}
+			c.Say(ctx, "This is Acme calling about your order.")
+		},
+	})
+	defer call.Hangup()
+
+	call.Answered()
+	for c := range feed(amdNoise(r, 3000, 0.3), generateSine(1000, 300, 8000, 0.4)) {
+		call.Write(c)
+	}
+	waitDone(t, call)
+
+	if call.AnsweredBy() != AnsweredMachine {
+		t.Fatalf("expected machine, got %s", call.AnsweredBy())
+	}
+	sawBeep := false
+	timeout := time.After(time.Second)
+	for !sawBeep {
+		select {
+		case ev := <-call.Stream().Events():
+			sawBeep = ev.Type == VoicemailBeep
+		case <-timeout:
+			t.Fatal("no VoicemailBeep event")
+		}
+	}
+	if msgs := session.GetContextCopy(); len(msgs) != 1 {
+		t.Errorf("expected the voicemail to be left, got %+v", msgs)
+	}
+}
@@ -0,0 +1,181 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+)
+
+// SessionMetrics summarises a conversation for dashboards. Talk and silence
+// figures are measured over the span from the first to the last recorded turn.
+type SessionMetrics struct {
+	Duration           time.Duration `json:"duration"`
+	UserTalkTime       time.Duration `json:"user_talk_time"`
+	BotTalkTime        time.Duration `json:"bot_talk_time"`
+	TalkRatio          float64       `json:"talk_ratio"` // User share of total talk time, 0-1
+	UserTurns          int           `json:"user_turns"`
+	BotTurns           int           `json:"bot_turns"`
+	Interruptions      int           `json:"interruptions"`
+	AvgResponseLatency time.Duration `json:"avg_response_latency"`
+	UserWordsPerMinute float64       `json:"user_wpm"`
+	BotWordsPerMinute  float64       `json:"bot_wpm"`
+	SilencePercentage  float64       `json:"silence_pct"`
+	SentimentScores    []float64     `json:"sentiment_scores,omitempty"` // One per user turn, -1..1
+	SentimentTrend     float64       `json:"sentiment_trend"`            // Slope per turn; >0 improving
+}
+
+type sessionAnalytics struct {
+	first, last    time.Time
+	userTalk       time.Duration
+	botTalk        time.Duration
+	userWords      int
+	botWords       int
+	userTurns      int
+	botTurns       int
+	interruptions  int
+	latencyTotal   time.Duration
+	latencySamples int
+	sentiment      []float64
+}
+
+func (a *sessionAnalytics) span(start, end time.Time) {
+	if a.first.IsZero() || start.Before(a.first) {
+		a.first = start
+	}
+	if end.After(a.last) {
+		a.last = end
+	}
+}
+
+// RecordUserTurn feeds a finished user utterance into the session analytics.
+func (s *ConversationSession) RecordUserTurn(start, end time.Time, transcript string) {
+	if end.Before(start) {
+		end = start
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := &s.analytics
+	a.span(start, end)
+	a.userTalk += end.Sub(start)
+	a.userWords += countWords(transcript)
+	a.userTurns++
+	a.sentiment = append(a.sentiment, SentimentScore(transcript))
+}
+
+// RecordBotTurn feeds a finished bot utterance into the session analytics.
+func (s *ConversationSession) RecordBotTurn(start, end time.Time, text string) {
+	if end.Before(start) {
+		end = start
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if end.After(s.botSpeechEnd) {
+		s.botSpeechEnd = end
+	}
+	a := &s.analytics
+	a.span(start, end)
+	a.botTalk += end.Sub(start)
+	a.botWords += countWords(text)
+	a.botTurns++
+}
+
+func (s *ConversationSession) RecordInterruption() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.analytics.interruptions++
+}
+
+// RecordResponseLatency records the time from end of user speech to the first bot audio.
+func (s *ConversationSession) RecordResponseLatency(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.analytics.latencyTotal += d
+	s.analytics.latencySamples++
+}
+
+// Analytics computes the current metrics for the session.
+func (s *ConversationSession) Analytics() SessionMetrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a := s.analytics
+
+	m := SessionMetrics{
+		UserTalkTime:    a.userTalk,
+		BotTalkTime:     a.botTalk,
+		UserTurns:       a.userTurns,
+		BotTurns:        a.botTurns,
+		Interruptions:   a.interruptions,
+		SentimentScores: append([]float64(nil), a.sentiment...),
+		SentimentTrend:  slope(a.sentiment),
+	}
+	if !a.first.IsZero() {
+		m.Duration = a.last.Sub(a.first)
+	}
+	if talk := a.userTalk + a.botTalk; talk > 0 {
+		m.TalkRatio = float64(a.userTalk) / float64(talk)
+		if m.Duration > 0 {
+			// Overlapping speech (barge-in) can make talk exceed the span
+			m.SilencePercentage = 100 * max(0, 1-float64(talk)/float64(m.Duration))
+		}
+	}
+	if a.latencySamples > 0 {
+		m.AvgResponseLatency = a.latencyTotal / time.Duration(a.latencySamples)
+	}
+	if a.userTalk > 0 {
+		m.UserWordsPerMinute = float64(a.userWords) / a.userTalk.Minutes()
+	}
+	if a.botTalk > 0 {
+		m.BotWordsPerMinute = float64(a.botWords) / a.botTalk.Minutes()
+	}
+	return m
+}
+
+var (
+	positiveWords = map[string]bool{
+		"good": true, "great": true, "thanks": true, "thank": true, "perfect": true, "excellent": true,
+		"love": true, "awesome": true, "nice": true, "happy": true, "helpful": true, "yes": true,
+		"gracias": true, "bien": true, "genial": true, "perfecto": true, "excelente": true,
+	}
+	negativeWords = map[string]bool{
+		"bad": true, "terrible": true, "awful": true, "hate": true, "angry": true, "wrong": true,
+		"useless": true, "annoying": true, "frustrated": true, "problem": true, "no": true, "not": true,
+		"mal": true, "horrible": true, "problema": true, "nunca": true,
+	}
+)
+
+// SentimentScore is a cheap lexicon-based polarity estimate in [-1, 1]. It is
+// meant for trends across a call, not for judging a single utterance.
+func SentimentScore(text string) float64 {
+	pos, neg := 0, 0
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		w = strings.Trim(w, ".,!?¡¿;:\"'")
+		if positiveWords[w] {
+			pos++
+		} else if negativeWords[w] {
+			neg++
+		}
+	}
+	if pos+neg == 0 {
+		return 0
+	}
+	return float64(pos-neg) / float64(pos+neg)
+}
+
+// slope is the least-squares slope of ys against their index.
+func slope(ys []float64) float64 {
+	n := float64(len(ys))
+	if n < 2 {
+		return 0
+	}
+	var sx, sy, sxy, sxx float64
+	for i, y := range ys {
+		x := float64(i)
+		sx += x
+		sy += y
+		sxy += x * y
+		sxx += x * x
+	}
+	return (n*sxy - sx*sy) / (n*sxx - sx*sx)
+}
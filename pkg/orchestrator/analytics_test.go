@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSessionAnalytics(t *testing.T) {
+	s := NewConversationSession("analytics")
+	t0 := time.Now()
+
+	// 0-6s user (10 words), 7-10s bot, 12-15s user, 16-20s bot
+	s.RecordUserTurn(t0, t0.Add(6*time.Second), "this is terrible I have a problem with my order")
+	s.RecordResponseLatency(time.Second)
+	s.RecordBotTurn(t0.Add(7*time.Second), t0.Add(10*time.Second), "sorry about that let me check")
+	s.RecordUserTurn(t0.Add(12*time.Second), t0.Add(15*time.Second), "great thanks that is perfect")
+	s.RecordResponseLatency(3 * time.Second)
+	s.RecordInterruption()
+	s.RecordBotTurn(t0.Add(16*time.Second), t0.Add(20*time.Second), "you're welcome")
+
+	m := s.Analytics()
+	if m.Duration != 20*time.Second {
+		t.Errorf("expected 20s span, got %v", m.Duration)
+	}
+	if m.UserTalkTime != 9*time.Second || m.BotTalkTime != 7*time.Second {
+		t.Errorf("unexpected talk times: user=%v bot=%v", m.UserTalkTime, m.BotTalkTime)
+	}
+	if math.Abs(m.TalkRatio-9.0/16.0) > 1e-9 {
+		t.Errorf("expected talk ratio 0.5625, got %v", m.TalkRatio)
+	}
+	if math.Abs(m.SilencePercentage-20) > 1e-9 {
+		t.Errorf("expected 20%% silence, got %v", m.SilencePercentage)
+	}
+	if m.AvgResponseLatency != 2*time.Second {
+		t.Errorf("expected 2s average latency, got %v", m.AvgResponseLatency)
+	}
+	if m.Interruptions != 1 || m.UserTurns != 2 || m.BotTurns != 2 {
+		t.Errorf("unexpected counts: %+v", m)
+	}
+	// 15 user words over 9 seconds
+	if math.Abs(m.UserWordsPerMinute-100) > 1e-9 {
+		t.Errorf("expected 100 wpm, got %v", m.UserWordsPerMinute)
+	}
+	if len(m.SentimentScores) != 2 || m.SentimentScores[0] >= 0 || m.SentimentScores[1] <= 0 {
+		t.Errorf("unexpected sentiment scores: %v", m.SentimentScores)
+	}
+	if m.SentimentTrend <= 0 {
+		t.Errorf("expected improving sentiment trend, got %v", m.SentimentTrend)
+	}
+}
+
+func TestSessionAnalytics_Empty(t *testing.T) {
+	m := NewConversationSession("empty").Analytics()
+	if m.Duration != 0 || m.TalkRatio != 0 || m.SilencePercentage != 0 || m.SentimentTrend != 0 {
+		t.Errorf("expected zero metrics, got %+v", m)
+	}
+}
+
+func TestManagedStream_BotTalkTimeIsPlaybackTime(t *testing.T) {
+	// One second of audio at the default 44.1kHz, synthesized instantly.
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 88200)}
+	o := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "Your order shipped."}, tts, nil, DefaultConfig(), &NoOpLogger{})
+	session := NewConversationSession("u")
+	ms := o.NewManagedStream(context.Background(), session)
+	go ms.runLLMAndTTS(context.Background(), "where is my order")
+
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type != AnalyticsUpdate {
+				continue
+			}
+			m := ev.Data.(SessionMetrics)
+			if m.BotTalkTime < 900*time.Millisecond || m.BotTalkTime > 1100*time.Millisecond {
+				t.Errorf("bot talk time = %v, want the 1s the audio plays for", m.BotTalkTime)
+			}
+			// Closing while the reply is still playing is not the user
+			// interrupting.
+			ms.Close()
+			if n := session.Analytics().Interruptions; n != 0 {
+				t.Errorf("Close counted %d interruptions", n)
+			}
+			return
+		case <-timeout:
+			t.Fatal("no analytics update")
+		}
+	}
+}
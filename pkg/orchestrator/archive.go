@@ -0,0 +1,286 @@
+package orchestrator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+// BlobStore is object storage archives are uploaded to, such as an S3 or
+// GCS bucket.
+type BlobStore interface {
+	PutBlob(ctx context.Context, key string, data []byte, meta BlobMeta) error
+}
+
+// BlobMeta describes an uploaded object.
+type BlobMeta struct {
+	ContentType string
+	// Labels are attached to the object for lifecycle rules and search:
+	// tags on S3, custom metadata on GCS.
+	Labels map[string]string
+}
+
+// PurgeableBlobStore is a BlobStore that can list and delete objects. With
+// one as Config.Archive.Store, PurgeUser deletes the user's archives.
+type PurgeableBlobStore interface {
+	BlobStore
+	// ListBlobs returns the keys of every object whose key starts with
+	// prefix.
+	ListBlobs(ctx context.Context, prefix string) ([]string, error)
+	DeleteBlob(ctx context.Context, key string) error
+}
+
+// BlobStoreFunc adapts a function to BlobStore.
+type BlobStoreFunc func(ctx context.Context, key string, data []byte, meta BlobMeta) error
+
+func (f BlobStoreFunc) PutBlob(ctx context.Context, key string, data []byte, meta BlobMeta) error {
+	return f(ctx, key, data, meta)
+}
+
+// ArchiveConfig has each ManagedStream's session bundled and uploaded to
+// Store when the stream closes. A bundle is a .tar.gz of:
+//
+//	metadata.json   IDs, providers, labels and when the session ended
+//	transcript.json the conversation's messages
+//	transcript.txt  the same, one "role: text" line each
+//	analytics.json  the session's SessionMetrics
+//	input.wav       the caller's audio, with Audio set
+//	output.wav      the bot's audio, with Audio set
+type ArchiveConfig struct {
+	Store    BlobStore
+	Prefix   string            // Prepended to keys, which are <prefix>users/<user>/YYYY/MM/DD/<session>.tar.gz, or <prefix>YYYY/MM/DD/<session>.tar.gz without a user
+	Labels   map[string]string // On every archive, e.g. {"retention": "90d"}; a session's tenant is added as "tenant"
+	Audio    bool              // Keep the stream's input and output audio for the archive
+	MaxAudio time.Duration     // Audio kept per direction; defaults to an hour
+	Timeout  time.Duration     // For bundling and uploading one archive; defaults to 60s
+}
+
+// ArchiveAudio is a session's audio, as 16-bit mono PCM.
+type ArchiveAudio struct {
+	Input      []byte
+	InputRate  int
+	Output     []byte
+	OutputRate int
+}
+
+// ArchiveMetadata is the metadata.json of an archive.
+type ArchiveMetadata struct {
+	SessionID string            `json:"session_id"`
+	UserID    string            `json:"user_id,omitempty"`
+	TenantID  string            `json:"tenant_id,omitempty"`
+	Language  Language          `json:"language,omitempty"`
+	Voice     Voice             `json:"voice,omitempty"`
+	Providers map[string]string `json:"providers"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Usage     TokenUsage        `json:"usage"`
+	EndedAt   time.Time         `json:"ended_at"`
+}
+
+// ArchiveSession bundles a session, with audio if it is non-nil, uploads it
+// to Config.Archive.Store and returns its key. Streams archive their
+// sessions on their own when they close; call this for sessions served
+// some other way.
+func (o *Orchestrator) ArchiveSession(ctx context.Context, session *ConversationSession, audio *ArchiveAudio) (string, error) {
+	cfg := o.GetConfig().Archive
+	if cfg.Store == nil {
+		return "", fmt.Errorf("archive: no store configured")
+	}
+	meta := ArchiveMetadata{
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		TenantID:  session.GetTenantID(),
+		Language:  session.GetCurrentLanguage(),
+		Voice:     session.GetCurrentVoice(),
+		Providers: o.GetProviders(),
+		Labels:    make(map[string]string, len(cfg.Labels)+1),
+		Usage:     session.Usage(),
+		EndedAt:   time.Now().UTC(),
+	}
+	for k, v := range cfg.Labels {
+		meta.Labels[k] = v
+	}
+	if meta.TenantID != "" {
+		meta.Labels["tenant"] = meta.TenantID
+	}
+
+	data, err := bundleSession(meta, session.GetContextCopy(), session.Analytics(), audio)
+	if err != nil {
+		return "", fmt.Errorf("archive: %w", err)
+	}
+	key := archiveUserPrefix(cfg.Prefix, meta.UserID) + meta.EndedAt.Format("2006/01/02") + "/" + session.ID + ".tar.gz"
+	if err := cfg.Store.PutBlob(ctx, key, data, BlobMeta{ContentType: "application/gzip", Labels: meta.Labels}); err != nil {
+		return "", fmt.Errorf("archive %s: %w", key, err)
+	}
+	return key, nil
+}
+
+// archiveUserPrefix is the prefix of every key archived for userID. User
+// IDs are escaped, so one user's prefix never contains another's.
+func archiveUserPrefix(prefix, userID string) string {
+	if userID == "" {
+		return prefix
+	}
+	return prefix + "users/" + url.PathEscape(userID) + "/"
+}
+
+// archivePurger deletes a user's archives from a store that can list them.
+// Archives uploaded under a different Prefix are not found.
+type archivePurger struct {
+	store  PurgeableBlobStore
+	prefix string
+}
+
+func (p archivePurger) PurgeUser(ctx context.Context, userID string) (int, error) {
+	keys, err := p.store.ListBlobs(ctx, archiveUserPrefix(p.prefix, userID))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, key := range keys {
+		if err := p.store.DeleteBlob(ctx, key); err != nil {
+			return removed, fmt.Errorf("delete %s: %w", key, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// archiveSession archives a session whose stream has closed.
+func (o *Orchestrator) archiveSession(session *ConversationSession, audio *ArchiveAudio) {
+	cfg := o.GetConfig().Archive
+	if cfg.Store == nil {
+		return
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if _, err := o.ArchiveSession(ctx, session, audio); err != nil {
+		o.logger.Warn("session archive failed", "sessionID", session.ID, "error", err)
+	}
+}
+
+func bundleSession(meta ArchiveMetadata, messages []Message, metrics SessionMetrics, pcm *ArchiveAudio) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: meta.EndedAt}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	addJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		return add(name, data)
+	}
+
+	var transcript strings.Builder
+	for _, m := range messages {
+		fmt.Fprintf(&transcript, "%s: %s\n", m.Role, m.Content)
+	}
+	if err := addJSON("metadata.json", meta); err != nil {
+		return nil, err
+	}
+	if err := addJSON("transcript.json", messages); err != nil {
+		return nil, err
+	}
+	if err := add("transcript.txt", []byte(transcript.String())); err != nil {
+		return nil, err
+	}
+	if err := addJSON("analytics.json", metrics); err != nil {
+		return nil, err
+	}
+	if pcm != nil {
+		if len(pcm.Input) > 0 {
+			if err := add("input.wav", audio.NewWavBuffer(pcm.Input, pcm.InputRate)); err != nil {
+				return nil, err
+			}
+		}
+		if len(pcm.Output) > 0 {
+			if err := add("output.wav", audio.NewWavBuffer(pcm.Output, pcm.OutputRate)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// archiveRecorder keeps a stream's audio for its archive, up to a limit per
+// direction.
+type archiveRecorder struct {
+	mu            sync.Mutex
+	input, output []byte
+	outRate       int // Bot audio comes at Config.SampleRate
+	limit         time.Duration
+}
+
+func newArchiveRecorder(cfg ArchiveConfig, outRate int) *archiveRecorder {
+	if cfg.Store == nil || !cfg.Audio {
+		return nil
+	}
+	limit := cfg.MaxAudio
+	if limit <= 0 {
+		limit = time.Hour
+	}
+	return &archiveRecorder{outRate: outRate, limit: limit}
+}
+
+func (r *archiveRecorder) keep(buf, chunk []byte, rate int) []byte {
+	limit := int(r.limit.Seconds()*float64(rate)) * 2
+	if room := limit - len(buf); len(chunk) > room {
+		chunk = chunk[:max(0, room)]
+	}
+	return append(buf, chunk...)
+}
+
+// writeInput keeps audio from the caller, at rate.
+func (r *archiveRecorder) writeInput(chunk []byte, rate int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.input = r.keep(r.input, chunk, rate)
+	r.mu.Unlock()
+}
+
+// writeOutput keeps audio sent to the caller.
+func (r *archiveRecorder) writeOutput(chunk []byte) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.output = r.keep(r.output, chunk, r.outRate)
+	r.mu.Unlock()
+}
+
+// audio returns what was kept, or nil when audio is not archived.
+func (r *archiveRecorder) audio(inRate int) *ArchiveAudio {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &ArchiveAudio{Input: r.input, InputRate: inRate, Output: r.output, OutputRate: r.outRate}
+}
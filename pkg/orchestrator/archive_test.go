@@ -0,0 +1,169 @@
+package orchestrator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type archived struct {
+	key   string
+	meta  BlobMeta
+	files map[string][]byte
+}
+
+// archiveSink collects uploaded archives, unpacked.
+func archiveSink(t *testing.T) (BlobStore, chan archived) {
+	got := make(chan archived, 1)
+	return BlobStoreFunc(func(ctx context.Context, key string, data []byte, meta BlobMeta) error {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Error(err)
+			return err
+		}
+		files := map[string][]byte{}
+		tr := tar.NewReader(zr)
+		for {
+			h, err := tr.Next()
+			if err != nil {
+				break
+			}
+			files[h.Name], _ = io.ReadAll(tr)
+		}
+		got <- archived{key, meta, files}
+		return nil
+	}), got
+}
+
+func TestArchiveSession(t *testing.T) {
+	store, got := archiveSink(t)
+	cfg := DefaultConfig()
+	cfg.Archive = ArchiveConfig{Store: store, Prefix: "calls/", Labels: map[string]string{"retention": "90d"}}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg)
+	session := NewConversationSession("caller")
+	session.ID = "s1"
+	session.TenantID = "acme"
+	session.AddMessage(RoleUser, "Where is my order?")
+	session.AddMessage(RoleAssistant, "It ships tomorrow.")
+
+	key, err := o.ArchiveSession(context.Background(), session, &ArchiveAudio{Input: make([]byte, 320), InputRate: 16000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := <-got
+	if a.key != key || !strings.HasPrefix(key, "calls/users/caller/"+time.Now().UTC().Format("2006/01/02")) || !strings.HasSuffix(key, "/s1.tar.gz") {
+		t.Errorf("key = %q", key)
+	}
+	if a.meta.ContentType != "application/gzip" || a.meta.Labels["retention"] != "90d" || a.meta.Labels["tenant"] != "acme" {
+		t.Errorf("blob meta = %+v", a.meta)
+	}
+
+	var meta ArchiveMetadata
+	json.Unmarshal(a.files["metadata.json"], &meta)
+	if meta.SessionID != "s1" || meta.TenantID != "acme" || meta.Providers["llm"] == "" {
+		t.Errorf("metadata = %+v", meta)
+	}
+	if string(a.files["transcript.txt"]) != "user: Where is my order?\nassistant: It ships tomorrow.\n" {
+		t.Errorf("transcript.txt = %q", a.files["transcript.txt"])
+	}
+	if a.files["analytics.json"] == nil || a.files["transcript.json"] == nil {
+		t.Errorf("files = %v", a.files)
+	}
+	if len(a.files["input.wav"]) != 44+320 || a.files["output.wav"] != nil {
+		t.Errorf("got %d bytes of input.wav and %d of output.wav", len(a.files["input.wav"]), len(a.files["output.wav"]))
+	}
+}
+
+func TestManagedStream_CloseArchivesAudio(t *testing.T) {
+	store, got := archiveSink(t)
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Archive = ArchiveConfig{Store: store, Audio: true, MaxAudio: 10 * time.Millisecond}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("caller"))
+
+	ms.bufferInput(make([]byte, 400), false)
+	ms.bufferInput(make([]byte, 800), false)
+	ms.mu.Lock()
+	ms.sendAudioLocked(make([]byte, 200), 0)
+	ms.mu.Unlock()
+	ms.Close()
+
+	select {
+	case a := <-got:
+		// MaxAudio keeps 10ms of input, 441 samples at 44.1kHz.
+		if len(a.files["input.wav"]) != 44+882 || len(a.files["output.wav"]) != 44+200 {
+			t.Errorf("got %d bytes of input.wav and %d of output.wav", len(a.files["input.wav"]), len(a.files["output.wav"]))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no archive uploaded after the stream closed")
+	}
+}
+
+// memBlobs is an in-memory PurgeableBlobStore.
+type memBlobs struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (m *memBlobs) PutBlob(ctx context.Context, key string, data []byte, meta BlobMeta) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blobs[key] = data
+	return nil
+}
+
+func (m *memBlobs) ListBlobs(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for key := range m.blobs {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memBlobs) DeleteBlob(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.blobs, key)
+	return nil
+}
+
+func TestPurgeUser_DeletesArchives(t *testing.T) {
+	store := &memBlobs{blobs: map[string][]byte{}}
+	cfg := DefaultConfig()
+	cfg.Archive = ArchiveConfig{Store: store, Prefix: "calls/"}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg)
+	ctx := context.Background()
+	for _, user := range []string{"alice", "alice", "alice/2", ""} {
+		session := NewConversationSession(user)
+		session.ID = "s" + strconv.Itoa(len(store.blobs))
+		if _, err := o.ArchiveSession(ctx, session, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := o.PurgeUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Removed["archives"] != 2 || len(store.blobs) != 2 {
+		t.Errorf("removed %d archives, %d left: %v", report.Removed["archives"], len(store.blobs), store.blobs)
+	}
+	for key := range store.blobs {
+		if strings.HasPrefix(key, "calls/users/alice/") {
+			t.Errorf("%s survived the purge", key)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AssistConfig configures passive agent assist: a live call between people
+// is transcribed and analysed, and what the analysis finds is sent as
+// events. Nothing is ever synthesized or spoken.
+type AssistConfig struct {
+	// Prompt tells the LLM about the call and what help is wanted, e.g.
+	// "You assist a bank's support agents. Suggest next steps."
+	Prompt string
+	// Lookup answers a knowledge query the LLM asks for, e.g. from a help
+	// center search. nil doesn't offer lookups to the LLM.
+	Lookup     func(ctx context.Context, query string) (string, error)
+	Compliance []ComplianceRule
+	Window     int // Utterances given to each analysis; default 20
+	Language   Language
+}
+
+// ComplianceRule is a script requirement for one speaker, or all when
+// Speaker is empty. Phrases are matched as whole words, ignoring case.
+type ComplianceRule struct {
+	Name     string
+	Speaker  string
+	Forbid   []string      // Raise an alert whenever one of these is said
+	Require  []string      // Raise an alert unless one of these is said...
+	Within   time.Duration // ...this long into the call; 0 means by Close
+	Describe string        // A rule the LLM judges from the transcript, e.g. "never promise a refund"
+}
+
+// AssistUtterance is one speaker's utterance on an assisted call.
+type AssistUtterance struct {
+	Speaker string    `json:"speaker"`
+	Text    string    `json:"text"`
+	At      time.Time `json:"at"`
+}
+
+// Suggestion is advice for the human on the call.
+type Suggestion struct {
+	Text string `json:"text"`
+}
+
+// KnowledgeResult is the answer to a knowledge query the LLM asked for.
+type KnowledgeResult struct {
+	Query  string `json:"query"`
+	Answer string `json:"answer"`
+}
+
+// ComplianceViolation reports a broken ComplianceRule.
+type ComplianceViolation struct {
+	Rule    string `json:"rule"`
+	Speaker string `json:"speaker,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+const assistPrompt = `You listen in on a live call and help the human taking it. You never speak on the call.
+%s
+After each new utterance, reply with only a JSON object with these fields:
+"suggestions": short pieces of advice for what to say or do next, or [] when you have nothing new,
+"lookups": %s,
+"violations": a list of {"rule": name, "reason": why} for rules below the call has just broken, or [].
+Rules:
+%s`
+
+// Assist transcribes and analyses a live human-to-human call, one speaker
+// per audio leg, with the orchestrator's VAD, STT and LLM. Read what it
+// finds from Events.
+type Assist struct {
+	o       *Orchestrator
+	ctx     context.Context
+	cancel  context.CancelFunc
+	cfg     AssistConfig
+	session *ConversationSession
+
+	mu         sync.Mutex
+	legs       map[string]*assistLeg
+	utterances []AssistUtterance
+	analysing  bool
+	pending    bool
+	seen       map[string]bool // Suggestions, lookups and alerts already sent
+	closing    bool            // No new audio; transcriptions under way are still analysed
+	closed     bool
+	events     chan OrchestratorEvent
+	wg         sync.WaitGroup
+	timers     []*time.Timer
+}
+
+type assistLeg struct {
+	vad      VADProvider
+	preRoll  []byte
+	speech   []byte
+	speaking bool
+}
+
+// NewAssist starts assisting the call id. Close ends it.
+func (o *Orchestrator) NewAssist(ctx context.Context, id string, cfg AssistConfig) *Assist {
+	if cfg.Window <= 0 {
+		cfg.Window = 20
+	}
+	if cfg.Language == "" {
+		cfg.Language = o.GetConfig().Language
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	a := &Assist{
+		o:       o,
+		ctx:     ctx,
+		cancel:  cancel,
+		cfg:     cfg,
+		session: o.NewSessionWithDefaults(id),
+		legs:    make(map[string]*assistLeg),
+		seen:    make(map[string]bool),
+		events:  make(chan OrchestratorEvent, 256),
+	}
+	for _, r := range cfg.Compliance {
+		if len(r.Require) > 0 && r.Within > 0 {
+			a.timers = append(a.timers, time.AfterFunc(r.Within, func() { a.checkRequired(r) }))
+		}
+	}
+	return a
+}
+
+// Session returns the session the analysis runs in, for its usage.
+func (a *Assist) Session() *ConversationSession {
+	return a.session
+}
+
+// Events returns what the assist finds: AssistTranscript, SuggestionReady,
+// KnowledgeFound and ComplianceAlert events. A slow reader drops events;
+// the channel is closed by Close.
+func (a *Assist) Events() <-chan OrchestratorEvent {
+	return a.events
+}
+
+// Transcript returns the call's utterances so far.
+func (a *Assist) Transcript() []AssistUtterance {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.utterances)
+}
+
+// Write passes audio of speaker's leg of the call, 16-bit mono PCM at the
+// orchestrator's sample rate. Each utterance is transcribed when the VAD
+// hears it end.
+func (a *Assist) Write(speaker string, pcm []byte) error {
+	a.mu.Lock()
+	if a.closing {
+		a.mu.Unlock()
+		return fmt.Errorf("assist is closed")
+	}
+	leg, ok := a.legs[speaker]
+	if !ok {
+		leg = &assistLeg{vad: a.newVAD()}
+		a.legs[speaker] = leg
+	}
+	a.mu.Unlock()
+
+	// Legs are written one chunk at a time, each by its own caller.
+	ev, err := leg.vad.Process(pcm)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ev != nil && ev.Type == VADSpeechStart:
+		leg.speaking = true
+		leg.speech = append(leg.preRoll, pcm...)
+		leg.preRoll = nil
+	case ev != nil && ev.Type == VADSpeechEnd && leg.speaking:
+		leg.speaking = false
+		audio := append(leg.speech, pcm...)
+		leg.speech = nil
+		a.goTranscribe(speaker, audio)
+	case leg.speaking:
+		leg.speech = append(leg.speech, pcm...)
+	default:
+		leg.preRoll = append(leg.preRoll, pcm...)
+		if limit := int(durationToBytes(300*time.Millisecond, a.o.GetConfig().SampleRate)); len(leg.preRoll) > limit {
+			leg.preRoll = leg.preRoll[len(leg.preRoll)-limit:]
+		}
+	}
+	return nil
+}
+
+func (a *Assist) newVAD() VADProvider {
+	if a.o.vad != nil {
+		return a.o.vad.Clone()
+	}
+	return NewRMSVAD(0.02, 500*time.Millisecond)
+}
+
+func (a *Assist) goTranscribe(speaker string, audio []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closing {
+		return
+	}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		res, err := a.o.Transcribe(a.ctx, audio, a.cfg.Language)
+		if err != nil {
+			if a.ctx.Err() == nil {
+				a.o.logger.Warn("assist transcription failed", "sessionID", a.session.ID, "speaker", speaker, "error", err)
+			}
+			return
+		}
+		a.addTranscript(speaker, res.Text, false)
+	}()
+}
+
+// AddTranscript adds an utterance transcribed elsewhere, e.g. by a
+// streaming STT connection of the caller's own, as if it had been heard.
+// It is ignored once Close has been called.
+func (a *Assist) AddTranscript(speaker, text string) {
+	a.addTranscript(speaker, text, true)
+}
+
+// addTranscript adds an utterance. Transcriptions started before Close are
+// still added while it waits for them; external ones are refused, as Close
+// may already be waiting and their analysis would not be waited for.
+func (a *Assist) addTranscript(speaker, text string, external bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	u := AssistUtterance{Speaker: speaker, Text: text, At: time.Now()}
+	a.mu.Lock()
+	if a.closed || (external && a.closing) {
+		a.mu.Unlock()
+		return
+	}
+	a.utterances = append(a.utterances, u)
+	a.emitLocked(AssistTranscript, u)
+	for _, r := range a.cfg.Compliance {
+		if r.Speaker != "" && r.Speaker != speaker {
+			continue
+		}
+		if phrase := matchPhrase(text, r.Forbid); phrase != "" {
+			a.emitLocked(ComplianceAlert, ComplianceViolation{Rule: r.Name, Speaker: speaker, Reason: fmt.Sprintf("said %q", phrase)})
+		}
+		if matchPhrase(text, r.Require) != "" {
+			a.seen["require:"+r.Name] = true
+		}
+	}
+	a.analyseLocked()
+	a.mu.Unlock()
+}
+
+// checkRequired alerts if none of r's required phrases has been said.
+func (a *Assist) checkRequired(r ComplianceRule) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.seen["require:"+r.Name] {
+		return
+	}
+	a.seen["require:"+r.Name] = true
+	reason := fmt.Sprintf("none of %q said", r.Require)
+	if r.Within > 0 {
+		reason += fmt.Sprintf(" within %v", r.Within)
+	}
+	a.emitLocked(ComplianceAlert, ComplianceViolation{Rule: r.Name, Speaker: r.Speaker, Reason: reason})
+}
+
+// analyseLocked runs an analysis of the latest utterances, or has the
+// running one followed by another.
+func (a *Assist) analyseLocked() {
+	if a.analysing {
+		a.pending = true
+		return
+	}
+	a.analysing = true
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		for {
+			a.mu.Lock()
+			window := a.utterances[max(0, len(a.utterances)-a.cfg.Window):]
+			window = slices.Clone(window)
+			a.pending = false
+			a.mu.Unlock()
+
+			a.analyse(window)
+
+			a.mu.Lock()
+			if !a.pending {
+				a.analysing = false
+				a.mu.Unlock()
+				return
+			}
+			a.mu.Unlock()
+		}
+	}()
+}
+
+func (a *Assist) prompt() string {
+	lookups := `[] (no knowledge base is available)`
+	if a.cfg.Lookup != nil {
+		lookups = `search queries for facts the human needs and may not know, or []`
+	}
+	var rules strings.Builder
+	for _, r := range a.cfg.Compliance {
+		if r.Describe != "" {
+			fmt.Fprintf(&rules, "- %s: %s\n", r.Name, r.Describe)
+		}
+	}
+	if rules.Len() == 0 {
+		rules.WriteString("(none)\n")
+	}
+	return fmt.Sprintf(assistPrompt, a.cfg.Prompt, lookups, rules.String())
+}
+
+// analyse has the LLM look at window and sends what it finds.
+func (a *Assist) analyse(window []AssistUtterance) {
+	var transcript strings.Builder
+	for _, u := range window {
+		fmt.Fprintf(&transcript, "%s: %s\n", u.Speaker, u.Text)
+	}
+	a.session.ClearContext()
+	a.session.AddMessage(RoleSystem, a.prompt())
+	a.session.AddMessage(RoleUser, transcript.String())
+	reply, err := a.o.GenerateResponse(a.ctx, a.session)
+	if err != nil {
+		if a.ctx.Err() == nil {
+			a.o.logger.Warn("assist analysis failed", "sessionID", a.session.ID, "error", err)
+		}
+		return
+	}
+
+	var parsed struct {
+		Suggestions []string `json:"suggestions"`
+		Lookups     []string `json:"lookups"`
+		Violations  []struct {
+			Rule   string `json:"rule"`
+			Reason string `json:"reason"`
+		} `json:"violations"`
+	}
+	if start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}"); start >= 0 && end > start {
+		reply = reply[start : end+1]
+	}
+	if err := json.Unmarshal([]byte(reply), &parsed); err != nil {
+		a.o.logger.Warn("assist analysis unreadable", "sessionID", a.session.ID, "error", err)
+		return
+	}
+
+	a.mu.Lock()
+	for _, s := range parsed.Suggestions {
+		if s = strings.TrimSpace(s); s != "" && a.firstTimeLocked("suggestion:"+s) {
+			a.emitLocked(SuggestionReady, Suggestion{Text: s})
+		}
+	}
+	for _, v := range parsed.Violations {
+		if v.Rule != "" && a.firstTimeLocked("violation:"+v.Rule) {
+			a.emitLocked(ComplianceAlert, ComplianceViolation{Rule: v.Rule, Reason: v.Reason})
+		}
+	}
+	var queries []string
+	if a.cfg.Lookup != nil {
+		for _, q := range parsed.Lookups {
+			if q = strings.TrimSpace(q); q != "" && a.firstTimeLocked("lookup:"+q) {
+				queries = append(queries, q)
+			}
+		}
+	}
+	a.mu.Unlock()
+
+	for _, q := range queries {
+		answer, err := a.cfg.Lookup(a.ctx, q)
+		if err != nil {
+			a.o.logger.Warn("assist lookup failed", "sessionID", a.session.ID, "query", q, "error", err)
+			continue
+		}
+		a.mu.Lock()
+		a.emitLocked(KnowledgeFound, KnowledgeResult{Query: q, Answer: answer})
+		a.mu.Unlock()
+	}
+}
+
+func (a *Assist) firstTimeLocked(key string) bool {
+	if a.seen[key] {
+		return false
+	}
+	a.seen[key] = true
+	return true
+}
+
+func (a *Assist) emitLocked(t EventType, data any) {
+	if a.closed {
+		return
+	}
+	select {
+	case a.events <- OrchestratorEvent{Type: t, SessionID: a.session.ID, Data: data}:
+	default:
+	}
+}
+
+// Close ends the assist once the transcriptions and analysis under way
+// have finished and their events are sent. Rules whose required phrases
+// were never said are reported last, so an utterance still being
+// transcribed counts.
+func (a *Assist) Close() {
+	a.mu.Lock()
+	if a.closing {
+		a.mu.Unlock()
+		return
+	}
+	a.closing = true
+	for _, t := range a.timers {
+		t.Stop()
+	}
+	a.mu.Unlock()
+	a.wg.Wait()
+	for _, r := range a.cfg.Compliance {
+		if len(r.Require) > 0 {
+			a.checkRequired(r)
+		}
+	}
+	a.cancel()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.closed = true
+	close(a.events)
+}
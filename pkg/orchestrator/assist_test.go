@@ -0,0 +1,128 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingTTS fails the test if anything is synthesized.
+type recordingTTS struct {
+	MockTTSProvider
+	t *testing.T
+}
+
+func (r *recordingTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	r.t.Errorf("assist synthesized %q", text)
+	return nil, errors.New("no synthesis")
+}
+
+func (r *recordingTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	r.t.Errorf("assist synthesized %q", text)
+	return errors.New("no synthesis")
+}
+
+func assistEvents(a *Assist) map[EventType][]any {
+	got := make(map[EventType][]any)
+	for ev := range a.Events() {
+		got[ev.Type] = append(got[ev.Type], ev.Data)
+	}
+	return got
+}
+
+func TestAssist_TranscribesLegsWithoutSpeaking(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleRate = 16000
+	llm := &MockLLMProvider{completeResult: `{"suggestions": ["Offer the refund form"], "lookups": [], "violations": []}`}
+	o := New(&MockSTTProvider{transcribeResult: "I was charged twice"}, llm, &recordingTTS{t: t}, NewRMSVAD(0.02, 0), cfg, nil)
+
+	a := o.NewAssist(context.Background(), "call-1", AssistConfig{Prompt: "Help support agents."})
+	for range 10 {
+		a.Write("customer", busFrame(3000))
+	}
+	a.Write("customer", busFrame(0))
+	a.Write("agent", busFrame(0))
+	a.Close()
+
+	got := assistEvents(a)
+	tr := got[AssistTranscript]
+	if len(tr) != 1 || tr[0].(AssistUtterance).Speaker != "customer" || tr[0].(AssistUtterance).Text != "I was charged twice" {
+		t.Fatalf("transcripts = %+v", tr)
+	}
+	if s := got[SuggestionReady]; len(s) != 1 || s[0].(Suggestion).Text != "Offer the refund form" {
+		t.Errorf("suggestions = %+v", s)
+	}
+	if err := a.Write("customer", busFrame(3000)); err == nil {
+		t.Error("Write after Close should fail")
+	}
+}
+
+func TestAssist_ComplianceAndLookups(t *testing.T) {
+	llm := &MockLLMProvider{completeResult: "Sure: ```json\n" + `{"suggestions": [], "lookups": ["refund policy"], "violations": [{"rule": "no_promises", "reason": "promised a refund"}]}` + "\n```"}
+	o := New(&MockSTTProvider{}, llm, &MockTTSProvider{}, nil, DefaultConfig(), nil)
+
+	var queries []string
+	a := o.NewAssist(context.Background(), "call-2", AssistConfig{
+		Lookup: func(ctx context.Context, q string) (string, error) {
+			queries = append(queries, q)
+			return "Refunds within 30 days.", nil
+		},
+		Compliance: []ComplianceRule{
+			{Name: "recording_notice", Speaker: "agent", Require: []string{"this call is recorded"}},
+			{Name: "disclosure", Speaker: "agent", Require: []string{"terms and conditions"}},
+			{Name: "no_card_numbers", Speaker: "agent", Forbid: []string{"read me your card number"}},
+			{Name: "no_promises", Describe: "the agent never promises a refund"},
+		},
+	})
+	a.AddTranscript("agent", "Hi, this call is recorded. Please read me your card number.")
+	a.AddTranscript("customer", "Read me your card number? No.")
+	a.AddTranscript("agent", "I'll get you a refund.")
+	a.Close()
+
+	got := assistEvents(a)
+	var rules []string
+	for _, v := range got[ComplianceAlert] {
+		rules = append(rules, v.(ComplianceViolation).Rule)
+	}
+	// The customer saying the forbidden phrase is not the agent saying it.
+	joined := strings.Join(rules, ",")
+	if strings.Count(joined, "no_card_numbers") != 1 || strings.Count(joined, "no_promises") != 1 ||
+		strings.Count(joined, "disclosure") != 1 || strings.Contains(joined, "recording_notice") {
+		t.Errorf("alerts = %v", rules)
+	}
+	// The same lookup is only run once however often the LLM asks.
+	if len(queries) != 1 || len(got[KnowledgeFound]) != 1 || got[KnowledgeFound][0].(KnowledgeResult).Answer != "Refunds within 30 days." {
+		t.Errorf("queries %v, knowledge %+v", queries, got[KnowledgeFound])
+	}
+	if len(a.Transcript()) != 3 {
+		t.Errorf("transcript = %+v", a.Transcript())
+	}
+}
+
+func TestAssist_CloseWaitsForRequiredPhrase(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleRate = 16000
+	stt := &delayedSTT{name: "slow", delay: 50 * time.Millisecond, res: TranscriptionResult{Text: "Hi, this call is recorded."}}
+	llm := &MockLLMProvider{completeResult: `{"suggestions": [], "lookups": [], "violations": []}`}
+	o := New(stt, llm, &MockTTSProvider{}, NewRMSVAD(0.02, 0), cfg, nil)
+
+	a := o.NewAssist(context.Background(), "call-3", AssistConfig{
+		Compliance: []ComplianceRule{{Name: "recording_notice", Speaker: "agent", Require: []string{"call is recorded"}}},
+	})
+	for range 10 {
+		a.Write("agent", busFrame(3000))
+	}
+	a.Write("agent", busFrame(0))
+	a.Close()
+	a.AddTranscript("agent", "too late")
+
+	got := assistEvents(a)
+	if len(got[AssistTranscript]) != 1 {
+		t.Errorf("transcripts = %+v", got[AssistTranscript])
+	}
+	if len(got[ComplianceAlert]) != 0 {
+		t.Errorf("the phrase was said before Close, got alerts %+v", got[ComplianceAlert])
+	}
+}
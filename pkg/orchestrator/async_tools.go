@@ -0,0 +1,120 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AsyncToolOptions configures a tool that may take longer than a caller is
+// willing to sit in silence.
+type AsyncToolOptions struct {
+	// Acknowledgment is spoken as soon as the tool starts, e.g. "Let me
+	// pull that up for you." Empty leaves it to the LLM.
+	Acknowledgment string
+	// Timeout bounds the tool run; 0 means it lives as long as the stream.
+	Timeout time.Duration
+}
+
+// ToolResultEventData carries the outcome of an asynchronous tool.
+type ToolResultEventData struct {
+	Name   string `json:"name"`
+	CallID string `json:"call_id"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// What the LLM sees as the immediate tool result, so it can keep the
+// conversation going while the real work happens.
+const (
+	asyncToolPending      = "This is running in the background. Tell the user you are on it; the result will follow in a later message."
+	asyncToolAcknowledged = "This is running in the background and the user has been told. Carry on with the conversation; the result will follow in a later message."
+)
+
+// RegisterAsyncTool registers a tool that runs in the background of a
+// ManagedStream. The LLM gets a placeholder result straight away, and once
+// the handler returns its result is added to the conversation and the bot
+// brings it up in a new turn of its own.
+func (o *Orchestrator) RegisterAsyncTool(name string, handler ContextToolHandler, opts AsyncToolOptions) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.toolHandlers[name] = handler
+	if o.asyncTools == nil {
+		o.asyncTools = make(map[string]AsyncToolOptions)
+	}
+	o.asyncTools[name] = opts
+}
+
+func (o *Orchestrator) asyncTool(name string) (AsyncToolOptions, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	opts, ok := o.asyncTools[name]
+	return opts, ok
+}
+
+// startAsyncTool launches tc in the background if it names an async tool the
+// session may use. It returns the placeholder result for the LLM and the
+// acknowledgment, if any, for the caller to speak as part of the current
+// reply.
+func (ms *ManagedStream) startAsyncTool(tc ToolCallEventData) (placeholder, ack string, ok bool) {
+	opts, ok := ms.orch.asyncTool(tc.Name)
+	if !ok || !ms.session.ToolAllowed(tc.Name) {
+		return "", "", false
+	}
+
+	ms.mu.Lock()
+	key := ms.turnKey
+	ms.mu.Unlock()
+
+	ctx := WithIdempotencyKey(ms.ctx, key)
+	cancel := context.CancelFunc(func() {})
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	}
+
+	placeholder = asyncToolPending
+	if opts.Acknowledgment != "" {
+		placeholder = asyncToolAcknowledged
+	}
+
+	go func() {
+		defer cancel()
+		result, _, err := ms.orch.callTool(ctx, ms.session, tc, func(entry ToolAuditEntry) {
+			ms.emit(ToolAudit, entry)
+		})
+		ms.deliverAsyncResult(tc, result, err)
+	}()
+	return placeholder, opts.Acknowledgment, true
+}
+
+func (ms *ManagedStream) deliverAsyncResult(tc ToolCallEventData, result string, err error) {
+	if ms.ctx.Err() != nil {
+		return
+	}
+	data := ToolResultEventData{Name: tc.Name, CallID: tc.CallID, Result: result}
+	note := fmt.Sprintf("The background task %s (call %s) finished with result: %s", tc.Name, tc.CallID, result)
+	if err != nil {
+		data.Error = err.Error()
+		note = fmt.Sprintf("The background task %s (call %s) failed: %v", tc.Name, tc.CallID, err)
+	}
+	ms.emit(ToolResult, data)
+
+	if ms.waitForGap(ms.ctx) != nil {
+		return
+	}
+
+	ms.session.AddMessageRaw(Message{Role: "system", Content: note})
+	ms.runLLMAndTTS(ms.ctx, "")
+}
+
+// claimGap marks the stream as thinking, in the same critical section that
+// sees the bot and the user are both quiet, and reports whether it did.
+func (ms *ManagedStream) claimGap() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.isThinking || ms.isSpeaking || (ms.vad != nil && ms.vad.IsSpeaking()) {
+		return false
+	}
+	ms.isThinking = true
+	return true
+}
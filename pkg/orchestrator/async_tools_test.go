@@ -0,0 +1,101 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManagedStream_AsyncTool(t *testing.T) {
+	llm := &MockStreamingLLM{responses: []struct {
+		content   string
+		toolCalls []ToolCallEventData
+	}{
+		{toolCalls: []ToolCallEventData{{Name: "build_report", CallID: "r1"}}},
+		{content: "Anything else while I wait?"},
+		{content: "Your report is ready: 42 sales."},
+	}}
+	tts := &sentenceTTS{spoken: make(chan struct{})}
+	orch := New(&MockSTTProvider{}, llm, tts, nil, DefaultConfig(), &NoOpLogger{})
+
+	release := make(chan struct{})
+	orch.RegisterAsyncTool("build_report", func(ctx context.Context, args string) (string, error) {
+		select {
+		case <-release:
+			return "42 sales", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}, AsyncToolOptions{Acknowledgment: "Give me a moment to build that."})
+
+	session := NewConversationSession("analyst")
+	ms := orch.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+	go ms.runLLMAndTTS(context.Background(), "build my sales report")
+
+	var responses []string
+	waitFor := func(match func(OrchestratorEvent) bool) {
+		t.Helper()
+		timeout := time.After(3 * time.Second)
+		for {
+			select {
+			case ev := <-ms.Events():
+				if ev.Type == BotResponse {
+					responses = append(responses, ev.Data.(string))
+				}
+				if match(ev) {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("timed out; responses so far: %v", responses)
+			}
+		}
+	}
+
+	waitFor(func(ev OrchestratorEvent) bool {
+		return ev.Type == BotResponse && ev.Data == "Anything else while I wait?"
+	})
+
+	// The acknowledgment is the first thing the turn says, not a second
+	// voice on top of it.
+	tts.mu.Lock()
+	if len(tts.texts) == 0 || tts.texts[0] != "Give me a moment to build that." {
+		t.Errorf("expected the acknowledgment to be spoken first, got %q", tts.texts)
+	}
+	tts.mu.Unlock()
+
+	var placeholder string
+	for _, m := range session.GetContextCopy() {
+		if m.Role == "tool" && m.ToolCallID == "r1" {
+			placeholder = m.Content
+		}
+	}
+	if !strings.Contains(placeholder, "background") {
+		t.Errorf("expected a placeholder tool result, got %q", placeholder)
+	}
+
+	close(release)
+	waitFor(func(ev OrchestratorEvent) bool {
+		if ev.Type != ToolResult {
+			return false
+		}
+		if data := ev.Data.(ToolResultEventData); data.Result != "42 sales" || data.CallID != "r1" {
+			t.Errorf("unexpected tool result event: %+v", data)
+		}
+		return true
+	})
+	waitFor(func(ev OrchestratorEvent) bool {
+		return ev.Type == BotResponse && ev.Data == "Your report is ready: 42 sales."
+	})
+
+	found := false
+	for _, m := range session.GetContextCopy() {
+		if m.Role == "system" && strings.Contains(m.Content, "42 sales") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("async result should be added to the conversation")
+	}
+}
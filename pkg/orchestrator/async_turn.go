@@ -0,0 +1,92 @@
+package orchestrator
+
+import "context"
+
+// TurnUpdateType says what a TurnUpdate carries.
+type TurnUpdateType string
+
+const (
+	TurnTranscript TurnUpdateType = "transcript" // Text is what the user said
+	TurnText       TurnUpdateType = "text"       // Text is the next piece of the reply
+	TurnAudio      TurnUpdateType = "audio"      // Audio is the next chunk of the spoken reply
+	TurnDone       TurnUpdateType = "done"       // The last update; Err is set if the turn failed
+)
+
+// TurnUpdate is one step of a turn run by ProcessAudioAsync.
+type TurnUpdate struct {
+	Type  TurnUpdateType
+	Text  string
+	Audio []byte
+	Err   error
+}
+
+// ProcessAudioAsync runs a ProcessAudio turn in the background and reports
+// it as it goes: the transcript, then the reply text and audio as they are
+// produced, then TurnDone. Reply text comes in pieces with a streaming LLM
+// and whole otherwise. The channel is closed after TurnDone.
+//
+// Updates are not dropped, so a reader that stops reading stalls the turn;
+// cancel ctx to abandon it.
+func (o *Orchestrator) ProcessAudioAsync(ctx context.Context, session *ConversationSession, audioData []byte) <-chan TurnUpdate {
+	updates := make(chan TurnUpdate, 16)
+	send := func(u TurnUpdate) error {
+		select {
+		case updates <- u:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	progress := &turnProgress{
+		transcript: func(text string) { send(TurnUpdate{Type: TurnTranscript, Text: text}) },
+		text:       func(text string) { send(TurnUpdate{Type: TurnText, Text: text}) },
+	}
+
+	go func() {
+		defer close(updates)
+		_, _, err := o.ProcessAudio(withTurnProgress(ctx, progress), session, audioData, true, func(chunk []byte) error {
+			return send(TurnUpdate{Type: TurnAudio, Audio: chunk})
+		})
+		// After a cancel TurnDone is sent only if there is room, since the
+		// reader may be gone.
+		done := TurnUpdate{Type: TurnDone, Err: err}
+		if send(done) != nil {
+			select {
+			case updates <- done:
+			default:
+			}
+		}
+	}()
+	return updates
+}
+
+// turnProgress receives a turn's intermediate results.
+type turnProgress struct {
+	transcript func(text string)
+	text       func(text string)
+}
+
+type turnProgressKey struct{}
+
+func withTurnProgress(ctx context.Context, p *turnProgress) context.Context {
+	return context.WithValue(ctx, turnProgressKey{}, p)
+}
+
+// turnProgressFrom returns the progress listener on ctx; its methods do
+// nothing when there is none.
+func turnProgressFrom(ctx context.Context) *turnProgress {
+	p, _ := ctx.Value(turnProgressKey{}).(*turnProgress)
+	return p
+}
+
+func (p *turnProgress) heard(text string) {
+	if p != nil {
+		p.transcript(text)
+	}
+}
+
+func (p *turnProgress) replied(text string) {
+	if p != nil && text != "" {
+		p.text(text)
+	}
+}
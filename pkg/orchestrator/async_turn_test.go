@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func collectUpdates(updates <-chan TurnUpdate) []TurnUpdate {
+	var all []TurnUpdate
+	for u := range updates {
+		all = append(all, u)
+	}
+	return all
+}
+
+func updateTypes(updates []TurnUpdate) string {
+	types := make([]string, len(updates))
+	for i, u := range updates {
+		types[i] = string(u.Type)
+	}
+	return strings.Join(types, " ")
+}
+
+func TestProcessAudioAsync_Batch(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "what time is it"}, &MockLLMProvider{completeResult: "It is noon."}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, DefaultConfig())
+	updates := collectUpdates(o.ProcessAudioAsync(context.Background(), NewConversationSession("u"), []byte{1}))
+
+	if got := updateTypes(updates); got != "transcript text audio done" {
+		t.Fatalf("got updates %s", got)
+	}
+	if updates[0].Text != "what time is it" || updates[1].Text != "It is noon." || len(updates[2].Audio) != 2 || updates[3].Err != nil {
+		t.Errorf("unexpected updates: %+v", updates)
+	}
+}
+
+func TestProcessAudioAsync_StreamsReplyText(t *testing.T) {
+	llm := &MockStreamingLLM{responses: []struct {
+		content   string
+		toolCalls []ToolCallEventData
+	}{{content: "Sure. It is noon."}}}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "what time is it"}, llm, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig())
+	updates := collectUpdates(o.ProcessAudioAsync(context.Background(), NewConversationSession("u"), []byte{1}))
+
+	var text strings.Builder
+	audio := 0
+	for _, u := range updates {
+		switch u.Type {
+		case TurnText:
+			text.WriteString(u.Text)
+		case TurnAudio:
+			audio++
+		}
+	}
+	if updates[0].Type != TurnTranscript || updates[len(updates)-1].Type != TurnDone {
+		t.Fatalf("expected transcript first and done last, got %s", updateTypes(updates))
+	}
+	if text.String() != "Sure. It is noon." || audio == 0 {
+		t.Errorf("expected the reply text and its audio, got %q and %d chunks", text.String(), audio)
+	}
+}
+
+func TestProcessAudioAsync_Error(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{transcribeErr: errors.New("stt down")}, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig())
+	updates := collectUpdates(o.ProcessAudioAsync(context.Background(), NewConversationSession("u"), []byte{1}))
+	if len(updates) != 1 || updates[0].Type != TurnDone || updates[0].Err == nil {
+		t.Errorf("expected a single failed TurnDone, got %+v", updates)
+	}
+}
@@ -0,0 +1,132 @@
+package orchestrator
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProviderCall is one request a turn made to a vendor, with the IDs the
+// vendor gave it, so a failed turn can be found in the vendor's own logs
+// and dashboards.
+type ProviderCall struct {
+	Stage     Stage             `json:"stage,omitempty"` // Empty for calls made outside a pipeline stage
+	Provider  string            `json:"provider"`
+	RequestID string            `json:"request_id,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"` // Every correlation header the response carried
+	Status    int               `json:"status,omitempty"`
+	At        time.Time         `json:"at"`
+}
+
+// requestIDHeaders are the response headers vendors put request or trace
+// IDs in. The first one a response has is taken as its RequestID.
+var requestIDHeaders = []string{
+	"X-Request-Id",     // OpenAI, Groq
+	"Request-Id",       // Anthropic, ElevenLabs
+	"Apim-Request-Id",  // Azure
+	"Dg-Request-Id",    // Deepgram
+	"X-Amzn-Requestid", // AWS
+	"X-Amz-Request-Id",
+	"X-Goog-Request-Id",
+	"Cf-Ray",
+	"X-Cloud-Trace-Context",
+	"X-Amzn-Trace-Id",
+}
+
+// AuditTrail collects the provider calls of a turn. Turns keep one of their
+// own; a caller that wants them for a ProcessAudio turn passes a context
+// from WithAuditTrail.
+type AuditTrail struct {
+	mu    sync.Mutex
+	calls []ProviderCall
+}
+
+type auditKey struct{}
+type auditStageKey struct{}
+
+// WithAuditTrail returns a context whose provider calls are added to the
+// returned trail. A context that already has a trail keeps it.
+func WithAuditTrail(ctx context.Context) (context.Context, *AuditTrail) {
+	if t, ok := ctx.Value(auditKey{}).(*AuditTrail); ok {
+		return ctx, t
+	}
+	t := &AuditTrail{}
+	return context.WithValue(ctx, auditKey{}, t), t
+}
+
+// Calls returns the calls recorded so far, oldest first.
+func (t *AuditTrail) Calls() []ProviderCall {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]ProviderCall(nil), t.calls...)
+}
+
+// RequestIDs lists the calls as provider=id pairs for logs, e.g.
+// "llm:openai=req_123".
+func (t *AuditTrail) RequestIDs() []string {
+	var ids []string
+	for _, c := range t.Calls() {
+		id := c.RequestID
+		if id == "" {
+			id = "?"
+		}
+		if c.Stage != "" {
+			ids = append(ids, string(c.Stage)+":"+c.Provider+"="+id)
+		} else {
+			ids = append(ids, c.Provider+"="+id)
+		}
+	}
+	return ids
+}
+
+// ReportResponse records the vendor's IDs for a response to a provider's
+// request in the turn's audit trail. Providers call it for every response,
+// failed ones included, and for the handshake of a WebSocket. Calls
+// outside a turn, and nil responses, are ignored.
+func ReportResponse(ctx context.Context, provider string, resp *http.Response) {
+	t, ok := ctx.Value(auditKey{}).(*AuditTrail)
+	if !ok || resp == nil {
+		return
+	}
+	call := ProviderCall{Provider: provider, Status: resp.StatusCode, At: time.Now()}
+	call.Stage, _ = ctx.Value(auditStageKey{}).(Stage)
+	for _, h := range requestIDHeaders {
+		v := strings.TrimSpace(resp.Header.Get(h))
+		if v == "" {
+			continue
+		}
+		if call.RequestID == "" {
+			call.RequestID = v
+		}
+		if call.Headers == nil {
+			call.Headers = make(map[string]string)
+		}
+		call.Headers[strings.ToLower(h)] = v
+	}
+	t.mu.Lock()
+	t.calls = append(t.calls, call)
+	t.mu.Unlock()
+}
+
+// withAuditStage tags the provider calls made under ctx with stage.
+func withAuditStage(ctx context.Context, stage Stage) context.Context {
+	if _, ok := ctx.Value(auditKey{}).(*AuditTrail); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, auditStageKey{}, stage)
+}
+
+// logFailure logs a stage of the stream's turn that failed, with the
+// provider requests the turn made, for looking up on the vendors' side.
+func (ms *ManagedStream) logFailure(ctx context.Context, stage Stage, err error) {
+	if ms.orch == nil {
+		return
+	}
+	t, _ := ctx.Value(auditKey{}).(*AuditTrail)
+	ms.orch.logger.Warn("stream turn failed", "sessionID", ms.session.ID, "stage", stage, "error", err, "requests", t.RequestIDs())
+}
@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// vendorLLM reports a response carrying a vendor request ID, then fails or
+// answers.
+type vendorLLM struct{ err error }
+
+func (v vendorLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	resp.Header.Set("X-Request-Id", "req_123")
+	resp.Header.Set("Cf-Ray", "8a1b-AMS")
+	if v.err != nil {
+		resp.StatusCode = http.StatusInternalServerError
+	}
+	ReportResponse(ctx, "vendor", resp)
+	if v.err != nil {
+		return "", v.err
+	}
+	return "Booked.", nil
+}
+
+func (v vendorLLM) Name() string { return "vendor" }
+
+func TestAuditTrail_RecordsProviderRequests(t *testing.T) {
+	recorder := NewInMemoryTurnRecorder()
+	o := New(&MockSTTProvider{transcribeResult: "book a table"}, vendorLLM{err: errors.New("overloaded")}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), nil)
+	o.SetTurnRecorder(recorder)
+
+	ctx, trail := WithAuditTrail(WithIdempotencyKey(context.Background(), "turn-7"))
+	if _, _, err := o.ProcessAudio(ctx, NewConversationSession("u1"), []byte{1, 2}, false, nil); err == nil {
+		t.Fatal("expected the LLM failure")
+	}
+
+	rec, err := recorder.LoadTurn(context.Background(), "turn-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Calls) != 1 {
+		t.Fatalf("recorded calls = %+v, want the LLM request", rec.Calls)
+	}
+	c := rec.Calls[0]
+	want := map[string]string{"x-request-id": "req_123", "cf-ray": "8a1b-AMS"}
+	if c.Stage != StageLLM || c.Provider != "vendor" || c.RequestID != "req_123" || c.Status != http.StatusInternalServerError || !reflect.DeepEqual(c.Headers, want) {
+		t.Errorf("unexpected call: %+v", c)
+	}
+	if ids := trail.RequestIDs(); !reflect.DeepEqual(ids, []string{"llm:vendor=req_123"}) {
+		t.Errorf("caller's trail = %v", ids)
+	}
+}
+
+func TestReportResponse_OutsideTurnIgnored(t *testing.T) {
+	ReportResponse(context.Background(), "vendor", &http.Response{Header: http.Header{}})
+	ctx, trail := WithAuditTrail(context.Background())
+	ReportResponse(ctx, "vendor", nil)
+	if calls := trail.Calls(); len(calls) != 0 {
+		t.Errorf("got %+v, want no calls", calls)
+	}
+}
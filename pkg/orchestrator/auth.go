@@ -0,0 +1,279 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Identity is who is on the other end of a connection, as established by an
+// Authenticator. It travels in the request context, is attached to the
+// sessions the connection opens, and is what per-caller quotas count.
+type Identity struct {
+	Subject  string         // Stable ID of the caller: a JWT "sub", or a fingerprint of the API key
+	TenantID string         // Tenant the caller belongs to; "" for single-tenant deployments
+	Scopes   []string       // What the caller may do; "*" grants everything
+	Claims   map[string]any // The verified token's claims; nil for API keys
+}
+
+// HasScope reports whether the identity was granted scope.
+func (id Identity) HasScope(scope string) bool {
+	return slices.Contains(id.Scopes, scope) || slices.Contains(id.Scopes, "*")
+}
+
+// Authenticator establishes the identity behind an HTTP request, including
+// the upgrade request of a WebSocket. It returns ErrUnauthenticated, possibly
+// wrapped, when the request carries no valid credentials.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Identity, error)
+}
+
+// AuthenticatorFunc adapts a function to Authenticator.
+type AuthenticatorFunc func(r *http.Request) (Identity, error)
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (Identity, error) { return f(r) }
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFrom returns the identity RequireAuth put in ctx.
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// RequireAuth guards a handler: requests auth cannot identify get 401, and
+// identities lacking any of scopes get 403. The handler finds the identity
+// with IdentityFrom.
+func RequireAuth(auth Authenticator, scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := auth.Authenticate(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="orchestrator"`)
+				http.Error(w, "unauthenticated", http.StatusUnauthorized)
+				return
+			}
+			for _, s := range scopes {
+				if !id.HasScope(s) {
+					http.Error(w, fmt.Sprintf("missing scope %q", s), http.StatusForbidden)
+					return
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), id)))
+		})
+	}
+}
+
+// credential returns the token a request presents: a bearer token, an
+// X-API-Key header, or, for browser WebSockets that cannot set headers, an
+// access_token query parameter.
+func credential(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("access_token")
+}
+
+// AnyOf tries each authenticator in turn, e.g. JWTs for end users and API
+// keys for backends, and returns the first identity established.
+func AnyOf(auths ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (Identity, error) {
+		for _, a := range auths {
+			if id, err := a.Authenticate(r); err == nil {
+				return id, nil
+			}
+		}
+		return Identity{}, ErrUnauthenticated
+	})
+}
+
+// APIKeyAuth authenticates clients by the API keys registered with their
+// tenants, granting them the tenant's Scopes.
+type APIKeyAuth struct {
+	Registry *TenantRegistry
+}
+
+func (a *APIKeyAuth) Authenticate(r *http.Request) (Identity, error) {
+	key := credential(r)
+	if key == "" {
+		return Identity{}, ErrUnauthenticated
+	}
+	t, err := a.Registry.Authenticate(key)
+	if err != nil {
+		return Identity{}, fmt.Errorf("api key: %w", ErrUnauthenticated)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return Identity{Subject: "key:" + hex.EncodeToString(sum[:6]), TenantID: t.ID, Scopes: t.Scopes}, nil
+}
+
+// JWTAuth validates bearer JWTs signed with HS256 by Secret or with RS256 by
+// one of Keys, looked up by the token's "kid" ("" for a key used without
+// one). Tokens must carry an expiry and a subject, which identifies the
+// caller to session ownership and per-identity quotas; not-before is
+// enforced when present, and Issuer and Audience when set.
+// The tenant is read from TenantClaim and scopes from "scope", the OAuth
+// space-separated string, or a "scp" or "scopes" list.
+type JWTAuth struct {
+	Secret      []byte                    // HS256 key; nil rejects HS256 tokens
+	Keys        map[string]*rsa.PublicKey // RS256 keys by key ID
+	Issuer      string
+	Audience    string
+	TenantClaim string        // Defaults to "tenant_id"
+	Leeway      time.Duration // Clock skew allowed on exp and nbf
+}
+
+func (a *JWTAuth) Authenticate(r *http.Request) (Identity, error) {
+	token := credential(r)
+	if token == "" {
+		return Identity{}, ErrUnauthenticated
+	}
+	claims, err := a.verify(token, time.Now())
+	if err != nil {
+		return Identity{}, fmt.Errorf("jwt: %v: %w", err, ErrUnauthenticated)
+	}
+	tenantClaim := a.TenantClaim
+	if tenantClaim == "" {
+		tenantClaim = "tenant_id"
+	}
+	id := Identity{Claims: claims}
+	id.Subject, _ = claims["sub"].(string)
+	id.TenantID, _ = claims[tenantClaim].(string)
+	if s, ok := claims["scope"].(string); ok {
+		id.Scopes = strings.Fields(s)
+	}
+	for _, name := range []string{"scp", "scopes"} {
+		if list, ok := claims[name].([]any); ok {
+			for _, v := range list {
+				if s, ok := v.(string); ok {
+					id.Scopes = append(id.Scopes, s)
+				}
+			}
+		}
+	}
+	return id, nil
+}
+
+func (a *JWTAuth) verify(token string, now time.Time) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("header: %v", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("signature: %v", err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case "HS256":
+		if a.Secret == nil {
+			return nil, errors.New("HS256 not accepted")
+		}
+		mac := hmac.New(sha256.New, a.Secret)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return nil, errors.New("bad signature")
+		}
+	case "RS256":
+		key := a.Keys[header.Kid]
+		if key == nil {
+			return nil, fmt.Errorf("unknown key %q", header.Kid)
+		}
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) != nil {
+			return nil, errors.New("bad signature")
+		}
+	default:
+		return nil, fmt.Errorf("algorithm %q not accepted", header.Alg)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("claims: %v", err)
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("token has no expiry")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(a.Leeway)) {
+		return nil, errors.New("token expired")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("token has no subject")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0).Add(-a.Leeway)) {
+		return nil, errors.New("token not yet valid")
+	}
+	if a.Issuer != "" && claims["iss"] != a.Issuer {
+		return nil, errors.New("wrong issuer")
+	}
+	if a.Audience != "" && !hasAudience(claims["aud"], a.Audience) {
+		return nil, errors.New("wrong audience")
+	}
+	return claims, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func hasAudience(aud any, want string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == want
+	case []any:
+		for _, v := range aud {
+			if v == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetIdentity records who opened the session.
+func (s *ConversationSession) SetIdentity(id Identity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Identity = &id
+}
+
+// GetIdentity returns who opened the session, if it was opened through an
+// Authenticator.
+func (s *ConversationSession) GetIdentity() (Identity, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.Identity == nil {
+		return Identity{}, false
+	}
+	return *s.Identity, true
+}
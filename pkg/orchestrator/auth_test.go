@@ -0,0 +1,205 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func signJWT(t *testing.T, header, claims map[string]any, sign func([]byte) []byte) string {
+	t.Helper()
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func hs256(secret []byte) func([]byte) []byte {
+	return func(b []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(b)
+		return mac.Sum(nil)
+	}
+}
+
+func bearer(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestJWTAuth(t *testing.T) {
+	secret := []byte("s3cret")
+	auth := &JWTAuth{Secret: secret, Issuer: "https://id.example.com", Audience: "voice"}
+	hs := map[string]any{"alg": "HS256", "typ": "JWT"}
+	valid := map[string]any{
+		"sub": "user-42", "tenant_id": "acme", "scope": "stream:write calls:read",
+		"iss": "https://id.example.com", "aud": []string{"voice", "web"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	id, err := auth.Authenticate(bearer(signJWT(t, hs, valid, hs256(secret))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Subject != "user-42" || id.TenantID != "acme" || !id.HasScope("stream:write") || id.HasScope("admin") {
+		t.Errorf("unexpected identity %+v", id)
+	}
+
+	with := func(k string, v any) map[string]any {
+		c := map[string]any{}
+		for key, val := range valid {
+			c[key] = val
+		}
+		c[k] = v
+		return c
+	}
+	without := func(k string) map[string]any {
+		c := with(k, nil)
+		delete(c, k)
+		return c
+	}
+	rejected := map[string]string{
+		"wrong secret": signJWT(t, hs, valid, hs256([]byte("guess"))),
+		"expired":      signJWT(t, hs, with("exp", time.Now().Add(-time.Minute).Unix()), hs256(secret)),
+		"not yet":      signJWT(t, hs, with("nbf", time.Now().Add(time.Minute).Unix()), hs256(secret)),
+		"issuer":       signJWT(t, hs, with("iss", "https://evil.example.com"), hs256(secret)),
+		"audience":     signJWT(t, hs, with("aud", "billing"), hs256(secret)),
+		"no expiry":    signJWT(t, hs, without("exp"), hs256(secret)),
+		"no subject":   signJWT(t, hs, without("sub"), hs256(secret)),
+		"empty sub":    signJWT(t, hs, with("sub", ""), hs256(secret)),
+		"alg none":     signJWT(t, map[string]any{"alg": "none"}, valid, func([]byte) []byte { return nil }),
+		"malformed":    "not.a-token",
+	}
+	for name, token := range rejected {
+		if _, err := auth.Authenticate(bearer(token)); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("%s: expected ErrUnauthenticated, got %v", name, err)
+		}
+	}
+}
+
+func TestJWTAuth_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := &JWTAuth{Keys: map[string]*rsa.PublicKey{"k1": &key.PublicKey}, TenantClaim: "org"}
+	sign := func(b []byte) []byte {
+		digest := sha256.Sum256(b)
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		return sig
+	}
+	claims := map[string]any{"sub": "svc", "org": "globex", "scp": []string{"stream:write"}, "exp": time.Now().Add(time.Hour).Unix()}
+
+	id, err := auth.Authenticate(bearer(signJWT(t, map[string]any{"alg": "RS256", "kid": "k1"}, claims, sign)))
+	if err != nil || id.TenantID != "globex" || !id.HasScope("stream:write") {
+		t.Errorf("got %+v, %v", id, err)
+	}
+	if _, err := auth.Authenticate(bearer(signJWT(t, map[string]any{"alg": "RS256", "kid": "k2"}, claims, sign))); err == nil {
+		t.Error("a token signed with an unknown key should be rejected")
+	}
+	if _, err := auth.Authenticate(bearer(signJWT(t, map[string]any{"alg": "HS256"}, claims, hs256(nil)))); err == nil {
+		t.Error("HS256 should be rejected without a secret")
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	reg := NewTenantRegistry()
+	reg.Register(Tenant{ID: "acme", Orchestrator: newTenantOrch("hi"), APIKeys: []string{"acme-key"}, Scopes: []string{"stream:write"}})
+	reg.Register(Tenant{ID: "globex", Orchestrator: newTenantOrch("hi"), APIKeys: []string{"globex-key"}})
+
+	var got Identity
+	handler := RequireAuth(&APIKeyAuth{Registry: reg}, "stream:write")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = IdentityFrom(r.Context())
+	}))
+
+	cases := []struct {
+		name string
+		req  func() *http.Request
+		code int
+	}{
+		{"no credentials", func() *http.Request { return httptest.NewRequest(http.MethodGet, "/stream", nil) }, http.StatusUnauthorized},
+		{"unknown key", func() *http.Request { return bearer("nope") }, http.StatusUnauthorized},
+		{"missing scope", func() *http.Request { return bearer("globex-key") }, http.StatusForbidden},
+		{"header key", func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/stream", nil)
+			r.Header.Set("X-API-Key", "acme-key")
+			return r
+		}, http.StatusOK},
+		{"query token", func() *http.Request { return httptest.NewRequest(http.MethodGet, "/stream?access_token=acme-key", nil) }, http.StatusOK},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, tc.req())
+		if rec.Code != tc.code {
+			t.Errorf("%s: got %d, want %d", tc.name, rec.Code, tc.code)
+		}
+	}
+	if got.TenantID != "acme" || got.Subject == "" || got.Subject == "acme-key" {
+		t.Errorf("handler got identity %+v; the subject must identify the key without revealing it", got)
+	}
+}
+
+func TestAnyOf(t *testing.T) {
+	reg := NewTenantRegistry()
+	reg.Register(Tenant{ID: "acme", Orchestrator: newTenantOrch("hi"), APIKeys: []string{"acme-key"}})
+	auth := AnyOf(&JWTAuth{Secret: []byte("x")}, &APIKeyAuth{Registry: reg})
+	if id, err := auth.Authenticate(bearer("acme-key")); err != nil || id.TenantID != "acme" {
+		t.Errorf("an API key should fall through to APIKeyAuth, got %+v, %v", id, err)
+	}
+	if _, err := auth.Authenticate(bearer("neither")); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestTenantRegistry_IdentityQuotas(t *testing.T) {
+	reg := NewTenantRegistry()
+	reg.Register(Tenant{ID: "acme", Orchestrator: newTenantOrch("hi"), Quota: TenantQuota{MaxTurnsPerMinutePerIdentity: 1}})
+	reg.Register(Tenant{ID: "globex", Orchestrator: newTenantOrch("hi")})
+
+	alice := Identity{Subject: "alice", TenantID: "acme"}
+	session, err := reg.NewSessionFor(alice, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := session.GetIdentity(); !ok || id.Subject != "alice" || session.UserID != "alice" || session.GetTenantID() != "acme" {
+		t.Errorf("identity not attached: %+v, user %q", id, session.UserID)
+	}
+
+	ctx := context.Background()
+	if _, _, err := reg.ProcessAudio(ctx, session, []byte{1, 2}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := reg.ProcessAudio(ctx, session, []byte{1, 2}, false, nil); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected a second turn from alice to exceed the per-identity quota, got %v", err)
+	}
+	bob, _ := reg.NewSessionFor(Identity{Subject: "bob", TenantID: "acme"}, "")
+	if _, _, err := reg.ProcessAudio(ctx, bob, []byte{1, 2}, false, nil); err != nil {
+		t.Errorf("bob's turns count against a separate quota, got %v", err)
+	}
+
+	other, _ := reg.NewSession("globex", "carol")
+	if err := reg.Authorize(alice, other); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for another tenant's session, got %v", err)
+	}
+	if err := reg.Authorize(alice, session); err != nil {
+		t.Error(err)
+	}
+	if err := reg.Authorize(Identity{Subject: "bob", TenantID: "acme"}, session); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for another caller's session, got %v", err)
+	}
+	anon, _ := reg.NewSession("acme", "guest")
+	if err := reg.Authorize(alice, anon); err != nil {
+		t.Errorf("a session without an identity is open to its tenant, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// BackchannelClassifier decides whether a short utterance heard over the
+// bot is a backchannel ("mm-hmm", "right") that shows the user is listening,
+// rather than an attempt to take the turn.
+type BackchannelClassifier interface {
+	IsBackchannel(ctx context.Context, transcript string, lang Language) (bool, error)
+}
+
+// BackchannelClassifierFunc adapts a function to BackchannelClassifier.
+type BackchannelClassifierFunc func(ctx context.Context, transcript string, lang Language) (bool, error)
+
+func (f BackchannelClassifierFunc) IsBackchannel(ctx context.Context, transcript string, lang Language) (bool, error) {
+	return f(ctx, transcript, lang)
+}
+
+// BackchannelFilter keeps the bot talking through backchannels. Speech that
+// starts while the bot is talking is held back instead of interrupting;
+// once it has gone on for MaxDuration it interrupts as usual, while shorter
+// speech is transcribed when it ends and put to Classifier. A backchannel
+// is dropped and reported as a Backchannel event; anything else interrupts
+// the bot and is answered, as is speech STT fails on.
+type BackchannelFilter struct {
+	Classifier  BackchannelClassifier // nil uses BackchannelPhrases with DefaultBackchannels
+	MaxDuration time.Duration         // Longer speech always interrupts; defaults to 1s
+	Timeout     time.Duration         // Limit on the quick transcription and classification; defaults to 2s
+}
+
+// DefaultBackchannels returns common English, Spanish, French and German
+// acknowledgments.
+func DefaultBackchannels() []string {
+	return []string{
+		"mm", "mhm", "mm hmm", "uh huh", "hmm", "yeah", "yes", "yep", "yup", "ok", "okay",
+		"right", "sure", "i see", "got it", "alright", "all right", "oh", "ah", "wow", "cool",
+		"sí", "vale", "claro", "ajá", "ya", "oui", "d'accord", "ouais", "ja", "genau", "okay",
+	}
+}
+
+// BackchannelPhrases classifies an utterance as a backchannel when it is
+// made up entirely of the listed phrases, as in "yeah, yeah, right". An
+// empty transcript, such as a cough, counts as one too.
+type BackchannelPhrases struct {
+	Phrases []string // nil uses DefaultBackchannels
+}
+
+func (b BackchannelPhrases) IsBackchannel(_ context.Context, transcript string, _ Language) (bool, error) {
+	phrases := b.Phrases
+	if phrases == nil {
+		phrases = DefaultBackchannels()
+	}
+	words := strings.Fields(normalizeWords(transcript))
+next:
+	for len(words) > 0 {
+		for _, p := range phrases {
+			pw := strings.Fields(normalizeWords(p))
+			if len(pw) > 0 && len(pw) <= len(words) && strings.Join(words[:len(pw)], " ") == strings.Join(pw, " ") {
+				words = words[len(pw):]
+				continue next
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+func (f *BackchannelFilter) maxDuration() time.Duration {
+	if f.MaxDuration <= 0 {
+		return time.Second
+	}
+	return f.MaxDuration
+}
+
+// holdsForBackchannelLocked reports whether speech under way may still turn
+// out to be a backchannel, and so must not interrupt the bot yet.
+func (ms *ManagedStream) holdsForBackchannelLocked(cfg Config) bool {
+	if cfg.Backchannel == nil || !(ms.isSpeaking || time.Now().Before(ms.playbackEnd)) {
+		return false
+	}
+	return !ms.bargeInHeld || time.Since(ms.bargeInSince) < cfg.Backchannel.maxDuration()
+}
+
+// checkBackchannel takes the speech that was held back over the bot when it
+// ends before MaxDuration, and has it classified in the background.
+func (ms *ManagedStream) checkBackchannel() {
+	if ms.orch == nil {
+		return
+	}
+	f := ms.orch.GetConfig().Backchannel
+	if f == nil {
+		return
+	}
+	ms.mu.Lock()
+	if !ms.bargeInHeld || ms.bargeInDropped || time.Since(ms.bargeInSince) >= f.maxDuration() ||
+		ms.interruptionPolicyLocked(ms.orch.GetConfig()) == InterruptIgnore {
+		ms.mu.Unlock()
+		return
+	}
+	from := ms.audioBuf.Len() - int(ms.tl.inputBytes-ms.speechFrom)
+	audio := append([]byte(nil), ms.audioBuf.Bytes()[max(from, 0):]...)
+	ms.mu.Unlock()
+	go ms.classifyBackchannel(f, audio)
+}
+
+func (ms *ManagedStream) classifyBackchannel(f *BackchannelFilter, audio []byte) {
+	timeout := f.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ms.ctx, timeout)
+	defer cancel()
+	lang := ms.session.GetCurrentLanguage()
+
+	var classifier BackchannelClassifier = BackchannelPhrases{}
+	if f.Classifier != nil {
+		classifier = f.Classifier
+	}
+	res, err := ms.orch.Transcribe(ctx, audio, lang)
+	backchannel := false
+	if err == nil {
+		text := strings.TrimSpace(res.Text)
+		backchannel = ms.isEchoTranscript(text, 0)
+		if !backchannel {
+			backchannel, err = classifier.IsBackchannel(ctx, text, lang)
+		}
+		if err == nil && backchannel {
+			ms.emit(Backchannel, text)
+			return
+		}
+	}
+	if ms.ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		ms.orch.logger.Warn("backchannel check failed; interrupting", "sessionID", ms.session.ID, "error", err)
+	}
+	// Substantive: interrupt and answer it, from the held audio, as the
+	// buffer may have been trimmed since the user stopped.
+	ms.mu.Lock()
+	ms.audioBuf.Reset()
+	ms.audioBuf.Write(audio)
+	ms.speechFrom = ms.tl.inputBytes - int64(len(audio))
+	ms.mu.Unlock()
+	ms.bargeIn()
+	ms.endUtterance()
+}
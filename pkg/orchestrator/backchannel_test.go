@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBackchannelPhrases(t *testing.T) {
+	cases := map[string]bool{
+		"Mm-hmm.":                  true,
+		"yeah, yeah, right":        true,
+		"Okay, got it.":            true,
+		"":                         true,
+		"D'accord.":                true,
+		"yeah but what about rent": false,
+		"wait":                     false,
+		"I see a problem":          false,
+	}
+	for text, want := range cases {
+		if got, _ := (BackchannelPhrases{}).IsBackchannel(context.Background(), text, LanguageEn); got != want {
+			t.Errorf("IsBackchannel(%q) = %v, want %v", text, got, want)
+		}
+	}
+	if ok, _ := (BackchannelPhrases{Phrases: []string{"roger"}}).IsBackchannel(context.Background(), "Roger, roger.", LanguageEn); !ok {
+		t.Error("custom phrases should be used")
+	}
+}
+
+// newBackchannelStream returns a stream whose bot is talking and whose STT
+// hears said in whatever the user utters over it.
+func newBackchannelStream(t *testing.T, f *BackchannelFilter, said string, script ...VADEventType) *ManagedStream {
+	t.Helper()
+	tts := newTalkingTTS(nil)
+	ms := newTestStream(t, testProviders{
+		stt: &MockSTTProvider{transcribeResult: said},
+		tts: tts,
+		vad: &scriptedVAD{script: script},
+	}, func(cfg *Config) { cfg.Backchannel = f })
+	startTalking(t, ms, tts, "Let me look that up for you.")
+	return ms
+}
+
+func TestManagedStream_BackchannelTalkedThrough(t *testing.T) {
+	ms := newBackchannelStream(t, &BackchannelFilter{}, "Mm-hmm.", VADSpeechStart, "", "", VADSpeechEnd)
+	chunk := make([]byte, 1764)
+	for range 4 {
+		ms.doWrite(chunk)
+	}
+
+	counts := countEvents(ms, 150*time.Millisecond)
+	if counts[UserSpeaking] != 0 || counts[Interrupted] != 0 || counts[TranscriptFinal] != 0 {
+		t.Errorf("a backchannel should not stop the bot, got %v", counts)
+	}
+	if counts[Backchannel] != 1 {
+		t.Errorf("expected one Backchannel event, got %v", counts)
+	}
+	ms.mu.Lock()
+	speaking := ms.isSpeaking
+	ms.mu.Unlock()
+	if !speaking {
+		t.Error("the bot should still be speaking")
+	}
+}
+
+func TestManagedStream_BackchannelSubstantiveInterrupts(t *testing.T) {
+	ms := newBackchannelStream(t, &BackchannelFilter{}, "Wait, what about Tuesday?", VADSpeechStart, "", "", VADSpeechEnd)
+	chunk := make([]byte, 1764)
+	for range 4 {
+		ms.doWrite(chunk)
+	}
+
+	counts := countEvents(ms, 300*time.Millisecond)
+	if counts[Backchannel] != 0 {
+		t.Errorf("a question is no backchannel, got %v", counts)
+	}
+	if counts[UserSpeaking] != 1 || counts[TranscriptFinal] != 1 {
+		t.Errorf("the question should interrupt the bot and be answered, got %v", counts)
+	}
+}
+
+func TestManagedStream_BackchannelLongSpeechInterrupts(t *testing.T) {
+	classified := false
+	f := &BackchannelFilter{
+		MaxDuration: 30 * time.Millisecond,
+		Classifier: BackchannelClassifierFunc(func(context.Context, string, Language) (bool, error) {
+			classified = true
+			return true, nil
+		}),
+	}
+	ms := newBackchannelStream(t, f, "Mm-hmm.", VADSpeechStart)
+	chunk := make([]byte, 1764)
+	ms.doWrite(chunk)
+	if counts := countEvents(ms, 10*time.Millisecond); counts[UserSpeaking] != 0 {
+		t.Fatalf("speech should be held at first, got %v", counts)
+	}
+	time.Sleep(50 * time.Millisecond)
+	ms.doWrite(chunk)
+
+	counts := countEvents(ms, 100*time.Millisecond)
+	if counts[UserSpeaking] != 1 {
+		t.Errorf("speech past MaxDuration should interrupt, got %v", counts)
+	}
+	if classified {
+		t.Error("speech past MaxDuration should not be classified")
+	}
+}
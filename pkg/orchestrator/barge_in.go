@@ -0,0 +1,290 @@
+package orchestrator
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// InterruptionPolicy decides what user speech does while a ManagedStream's
+// bot is talking or thinking. An assistant usually wants to stop at once; an
+// IVR reading out a menu may rather finish it.
+type InterruptionPolicy string
+
+const (
+	// InterruptStopAndAbortLLM stops the bot and cancels its reply, LLM call
+	// included, as soon as the user starts speaking. It is the default.
+	InterruptStopAndAbortLLM InterruptionPolicy = "stop_and_abort_llm"
+	// InterruptStopAndListen stops the bot's audio and answers the user, but
+	// a reply still being generated is only cancelled once the user's words
+	// are transcribed; if STT rejects them as noise, the reply goes ahead.
+	InterruptStopAndListen InterruptionPolicy = "stop_and_listen"
+	// InterruptStopSpeaking silences the bot without answering: what the
+	// user said is dropped and the bot waits to be spoken to again.
+	InterruptStopSpeaking InterruptionPolicy = "stop_speaking"
+	// InterruptIgnore lets the bot finish; speech while it talks or thinks
+	// is dropped, unless it goes on after the bot is done.
+	InterruptIgnore InterruptionPolicy = "ignore"
+)
+
+// Playback loudness follows peaks quickly and relaxes slowly, so a pause
+// between two TTS words does not briefly lower the bar for interrupting.
+const playbackLevelRelease = 0.2
+
+func chunkRMS(chunk []byte) float64 {
+	samples := bytesToSamples(chunk)
+	if len(samples) == 0 {
+		return 0
+	}
+	return math.Sqrt(calculateEnergy(samples) / float64(len(samples)))
+}
+
+func (ms *ManagedStream) trackPlaybackLevel(chunk []byte) {
+	rms := chunkRMS(chunk)
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if rms >= ms.playbackLevel {
+		ms.playbackLevel = rms
+	} else {
+		ms.playbackLevel += (rms - ms.playbackLevel) * playbackLevelRelease
+	}
+	ms.playbackAt = time.Now()
+}
+
+// PlaybackLevel returns the tracked RMS of recently played bot audio, or 0
+// once playback has been quiet for longer than BargeInVADTrailWindow.
+func (ms *ManagedStream) PlaybackLevel() float64 {
+	trail := DefaultConfig().BargeInVADTrailWindow
+	if ms.orch != nil {
+		trail = ms.orch.GetConfig().BargeInVADTrailWindow
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.playbackAt.IsZero() || time.Since(ms.playbackAt) > trail {
+		return 0
+	}
+	return ms.playbackLevel
+}
+
+// bargeInThreshold is the mic RMS a user must reach to interrupt the bot.
+// It is 0 when scaling is disabled or nothing has been played recently.
+func (ms *ManagedStream) bargeInThreshold() float64 {
+	if ms.orch == nil {
+		return 0
+	}
+	cfg := ms.orch.GetConfig()
+	ratio := cfg.BargeInPlaybackRatio
+	if ratio <= 0 && cfg.EchoGating {
+		ratio = echoGateRatio
+	}
+	if ratio <= 0 {
+		return 0
+	}
+	level := ms.PlaybackLevel()
+	if level == 0 {
+		return 0
+	}
+	return max(level*ratio, cfg.BargeInVADThreshold)
+}
+
+// promoteBargeIn reports whether a held-back speech start has now become
+// loud enough, and unlike the bot's own audio, to count as a real
+// interruption.
+func (ms *ManagedStream) promoteBargeIn(micRMS float64, chunk []byte) bool {
+	ms.mu.Lock()
+	held := ms.bargeInHeld
+	ms.mu.Unlock()
+	if !held || ms.vad == nil || !ms.vad.IsSpeaking() {
+		return false
+	}
+	if micRMS < ms.bargeInThreshold() || ms.isOwnEcho(chunk) || ms.holdsBargeIn() {
+		return false
+	}
+	ms.mu.Lock()
+	ms.bargeInHeld = false
+	ms.mu.Unlock()
+	return true
+}
+
+// releaseBargeInHold drops a held-back utterance when the VAD ends it,
+// reporting whether there was one.
+func (ms *ManagedStream) releaseBargeInHold() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	held := ms.bargeInHeld
+	ms.bargeInHeld = false
+	if ms.bargeInDropped {
+		// Keep the dropped speech out of the next utterance.
+		ms.bargeInDropped = false
+		ms.audioBuf.Reset()
+		ms.lastUserAudio = nil
+	}
+	return held
+}
+
+func (ms *ManagedStream) botBusyLocked() bool {
+	return ms.isSpeaking || ms.isThinking || time.Now().Before(ms.playbackEnd)
+}
+
+// holdsBargeIn reports whether speech under way must still be held back
+// rather than interrupt the bot: under InterruptIgnore, after it has already
+// silenced the bot under InterruptStopSpeaking, while it may yet prove a
+// backchannel, or until it has lasted Config.MinBargeInDuration, so a cough
+// does not cut a long answer short.
+func (ms *ManagedStream) holdsBargeIn() bool {
+	if ms.orch == nil {
+		return false
+	}
+	cfg := ms.orch.GetConfig()
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	switch {
+	case ms.bargeInDropped:
+		return true
+	case !ms.botBusyLocked():
+		return false
+	case ms.interruptionPolicyLocked(cfg) == InterruptIgnore:
+		return true
+	case ms.holdsForBackchannelLocked(cfg):
+		return true
+	}
+	return cfg.MinBargeInDuration > 0 && (!ms.bargeInHeld || time.Since(ms.bargeInSince) < cfg.MinBargeInDuration)
+}
+
+// bargeIn starts an utterance, first dealing with a bot that is talking or
+// thinking as Config.InterruptionPolicy, or DoubleTalkDetection, says.
+func (ms *ManagedStream) bargeIn() {
+	var cfg Config
+	if ms.orch != nil {
+		cfg = ms.orch.GetConfig()
+	}
+	ms.mu.Lock()
+	policy := ms.interruptionPolicyLocked(cfg)
+	busy := ms.botBusyLocked()
+	switch {
+	case !busy:
+	case policy == InterruptStopSpeaking:
+		ms.bargeInHeld = true
+		ms.bargeInDropped = true
+		ms.mu.Unlock()
+		ms.internalInterrupt()
+		return
+	case policy == InterruptStopAndListen && ms.isThinking && !ms.isSpeaking && ms.pipelineCancel != nil:
+		// startUtterance would cancel the pipeline the reply runs in.
+		if ms.keptReply != nil {
+			defer ms.keptReply()
+		}
+		ms.keptReply = ms.pipelineCancel
+		ms.pipelineCancel = nil
+	}
+	ms.mu.Unlock()
+	ms.startUtterance()
+}
+
+// heardText returns the part of a reply the listener heard before it was cut
+// off after heard bytes of audio: whole sentences, then the share of the
+// sentence in progress that its audio had reached, ending in a dash where
+// the listener stopped hearing it. ends holds the audio offset at which each
+// sentence ended.
+func heardText(sentences []string, ends []int64, heard int64) string {
+	var out []string
+	var start int64
+	for i, s := range sentences {
+		if i >= len(ends) || heard <= start {
+			break
+		}
+		if heard >= ends[i] {
+			out = append(out, s)
+			start = ends[i]
+			continue
+		}
+		chars := []rune(s)
+		n := int(float64(len(chars)) * float64(heard-start) / float64(ends[i]-start))
+		if part := strings.TrimRight(string(chars[:n]), " "); part != "" {
+			out = append(out, part+"—")
+		}
+		break
+	}
+	return strings.Join(out, " ")
+}
+
+// spokenReply is what speakWith gave the client: the sentences, the reply
+// audio offset at which each ended, and how much of that audio was sent.
+type spokenReply struct {
+	sentences []string
+	ends      []int64
+	sent      int64
+}
+
+// keepHeard cuts an interrupted reply back to what the listener heard, so
+// the LLM does not assume the user heard the rest. The position the client
+// reported to the session, if any, is used as it is, capped at what was
+// sent; otherwise audio sent but not yet due to have played by the
+// stream's estimate counts as unheard. ReportBuffer explains how the two
+// reports combine.
+func (ms *ManagedStream) keepHeard(r *spokenReply) {
+	ms.mu.Lock()
+	unplayed := time.Until(ms.playbackEnd)
+	rate := ms.playbackRate
+	ms.mu.Unlock()
+	heard := r.sent
+	if played, ok := ms.session.finishReply(rate); ok {
+		heard = min(heard, played)
+	} else if unplayed > 0 && rate > 0 {
+		heard -= durationToBytes(unplayed, rate)
+	}
+	if len(r.ends) > 0 && heard >= r.ends[len(r.ends)-1] {
+		return // All of it was heard
+	}
+	said := strings.Join(r.sentences, " ")
+	kept := heardText(r.sentences, r.ends, max(heard, 0))
+	if ms.session.truncateReply(said, kept) {
+		ms.orch.logger.Info("reply truncated to what was heard", "sessionID", ms.session.ID)
+		ms.mu.Lock()
+		ms.resumable = &ResumableTurn{Reply: said, Heard: kept, InterruptedAt: time.Now()}
+		ms.mu.Unlock()
+	}
+}
+
+// truncateReply replaces the last message, if it is the assistant reply
+// said, with the part that was heard, dropping it if nothing was. A reply
+// whose LLM call was cut short never reached the context, so what was heard
+// of it is added instead.
+func (s *ConversationSession) truncateReply(said, heard string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.truncateReplyLocked(said, heard)
+}
+
+func (s *ConversationSession) truncateReplyLocked(said, heard string) bool {
+	n := len(s.Context)
+	if n == 0 {
+		return false
+	}
+	last := &s.Context[n-1]
+	switch {
+	case last.Role == RoleUser:
+		if heard == "" {
+			return false
+		}
+		s.Context = append(s.Context, Message{Role: RoleAssistant, Content: heard})
+	case last.Role != RoleAssistant || last.ToolCalls != nil || len(last.Parts) > 0:
+		return false
+	case strings.Join(strings.Fields(last.Content), " ") != strings.Join(strings.Fields(said), " "):
+		return false
+	case heard == last.Content:
+		return false
+	case heard == "":
+		s.Context = s.Context[:n-1]
+	default:
+		last.Content = heard
+	}
+	s.LastAssistant = ""
+	for i := len(s.Context) - 1; i >= 0; i-- {
+		if s.Context[i].Role == RoleAssistant && s.Context[i].Text() != "" {
+			s.LastAssistant = s.Context[i].Text()
+			break
+		}
+	}
+	return true
+}
@@ -0,0 +1,250 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newBargeInStream(t *testing.T, script []VADEventType) *ManagedStream {
+	t.Helper()
+	ms := newTestStream(t, testProviders{vad: &scriptedVAD{script: script}}, func(cfg *Config) {
+		cfg.BargeInPlaybackRatio = 0.5
+	})
+	ms.echoSuppressor.SetEnabled(false)
+	return ms
+}
+
+func TestManagedStream_BargeInScalesWithPlayback(t *testing.T) {
+	ms := newBargeInStream(t, []VADEventType{VADSpeechStart, VADSpeechEnd})
+	ms.RecordPlayedOutput(generateSine(300, 20, 16000, 0.4))
+
+	if th := ms.bargeInThreshold(); th < 0.13 || th > 0.15 {
+		t.Fatalf("expected threshold near half the playback RMS, got %.3f", th)
+	}
+
+	ambient := generateSine(120, 20, 16000, 0.05)
+	ms.doWrite(ambient) // start, but quieter than the bot
+	ms.doWrite(ambient) // end
+
+	counts := countEvents(ms, 200*time.Millisecond)
+	if counts[UserSpeaking] != 0 || counts[UserStopped] != 0 {
+		t.Errorf("ambient noise below playback should not interrupt, got %v", counts)
+	}
+}
+
+func TestManagedStream_BargeInPromotesLouderSpeech(t *testing.T) {
+	ms := newBargeInStream(t, []VADEventType{VADSpeechStart, "", VADSpeechEnd})
+	ms.RecordPlayedOutput(generateSine(300, 20, 16000, 0.4))
+
+	ms.doWrite(generateSine(120, 20, 16000, 0.05))
+	ms.doWrite(generateSine(220, 20, 16000, 0.6)) // user raises their voice
+	ms.doWrite(make([]byte, 640))
+
+	counts := countEvents(ms, 200*time.Millisecond)
+	if counts[UserSpeaking] != 1 || counts[UserStopped] != 1 {
+		t.Errorf("speech above playback should interrupt, got %v", counts)
+	}
+}
+
+func TestManagedStream_BargeInTrailExpires(t *testing.T) {
+	ms := newBargeInStream(t, nil)
+	ms.orch.config.BargeInVADTrailWindow = 10 * time.Millisecond
+	ms.RecordPlayedOutput(generateSine(300, 20, 16000, 0.4))
+	time.Sleep(20 * time.Millisecond)
+
+	if lvl := ms.PlaybackLevel(); lvl != 0 {
+		t.Errorf("expected playback level to reset after the trail window, got %.3f", lvl)
+	}
+	if th := ms.bargeInThreshold(); th != 0 {
+		t.Errorf("expected no scaling once playback stopped, got %.3f", th)
+	}
+}
+
+func TestHeardText(t *testing.T) {
+	sentences := []string{"Your order shipped today.", "It should arrive on Friday between nine and five."}
+	ends := []int64{1000, 3000}
+	for heard, want := range map[int64]string{
+		0:    "",
+		500:  "Your order s—",
+		1000: "Your order shipped today.",
+		2000: "Your order shipped today. It should arrive on Frid—",
+		5000: "Your order shipped today. It should arrive on Friday between nine and five.",
+	} {
+		if got := heardText(sentences, ends, heard); got != want {
+			t.Errorf("heard %d: got %q, want %q", heard, got, want)
+		}
+	}
+}
+
+func TestSession_TruncateReply(t *testing.T) {
+	session := NewConversationSession("u")
+	session.AddMessage(RoleUser, "Where is my order?")
+	session.AddMessage(RoleAssistant, "It shipped today.  It arrives Friday.")
+
+	if session.truncateReply("Something else entirely.", "Something") {
+		t.Error("a different reply must not be truncated")
+	}
+	if !session.truncateReply("It shipped today. It arrives Friday.", "It shipped today.") {
+		t.Fatal("expected the reply to be truncated")
+	}
+	if last := session.Context[len(session.Context)-1]; last.Content != "It shipped today." || session.LastAssistant != "It shipped today." {
+		t.Errorf("got %q / %q", last.Content, session.LastAssistant)
+	}
+
+	session.truncateReply("It shipped today.", "")
+	if last := session.Context[len(session.Context)-1]; last.Role != RoleUser || session.LastAssistant != "" {
+		t.Errorf("expected an unheard reply to be dropped, last is %+v", last)
+	}
+}
+
+func TestManagedStream_InterruptionKeepsOnlyWhatWasHeard(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	reply := "Your order shipped this morning and should arrive by Friday afternoon at the latest."
+	// One second of audio at the default 44.1kHz playback rate.
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 88200)}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: reply}, tts, nil, cfg)
+	session := NewConversationSession("u")
+	session.AddMessage(RoleUser, "Where is my order?")
+	ms := o.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	done := make(chan struct{})
+	go func() {
+		ms.runLLMAndTTS(ms.ctx, "")
+		close(done)
+	}()
+	deadline := time.After(2 * time.Second)
+	for speaking := false; !speaking; {
+		select {
+		case ev := <-ms.Events():
+			speaking = ev.Type == AudioChunk
+		case <-deadline:
+			t.Fatal("bot never spoke")
+		}
+	}
+	time.Sleep(400 * time.Millisecond)
+	ms.Interrupt()
+	<-done
+
+	last := session.GetContextCopy()[len(session.GetContextCopy())-1]
+	heard, cut := strings.CutSuffix(last.Content, "—")
+	if last.Role != RoleAssistant || !cut || heard == "" || !strings.HasPrefix(reply, heard) {
+		t.Errorf("expected a dash-terminated prefix of the reply, got %q", last.Content)
+	}
+}
+
+// newPolicyStream returns a stream whose bot is talking, and the func that
+// lets it finish.
+func newPolicyStream(t *testing.T, policy InterruptionPolicy, minDuration time.Duration, script ...VADEventType) (*ManagedStream, func()) {
+	t.Helper()
+	tts := newTalkingTTS(nil)
+	ms := newTestStream(t, testProviders{tts: tts, vad: &scriptedVAD{script: script}}, func(cfg *Config) {
+		cfg.InterruptionPolicy = policy
+		cfg.MinBargeInDuration = minDuration
+	})
+	return ms, startTalking(t, ms, tts, "Let me look that up for you.")
+}
+
+func TestManagedStream_InterruptIgnoreLetsTheBotFinish(t *testing.T) {
+	ms, finish := newPolicyStream(t, InterruptIgnore, 0, VADSpeechStart, "", "", "")
+	chunk := make([]byte, 1764)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+	if counts := countEvents(ms, 100*time.Millisecond); counts[UserSpeaking] != 0 || counts[Interrupted] != 0 {
+		t.Fatalf("speech over the bot should be ignored, got %v", counts)
+	}
+
+	finish()
+	ms.doWrite(chunk)
+	if counts := countEvents(ms, 100*time.Millisecond); counts[UserSpeaking] != 1 {
+		t.Errorf("speech going on after the bot finished should be heard, got %v", counts)
+	}
+}
+
+func TestManagedStream_MinBargeInDurationIgnoresACough(t *testing.T) {
+	ms, _ := newPolicyStream(t, "", 80*time.Millisecond, VADSpeechStart, "", VADSpeechEnd, VADSpeechStart, "", "")
+	chunk := make([]byte, 1764)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+	if counts := countEvents(ms, 50*time.Millisecond); counts[UserSpeaking] != 0 || counts[UserStopped] != 0 {
+		t.Fatalf("a short sound should not barge in, got %v", counts)
+	}
+
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+	if counts := countEvents(ms, 20*time.Millisecond); counts[UserSpeaking] != 0 {
+		t.Fatalf("speech should be held until it has lasted long enough, got %v", counts)
+	}
+	time.Sleep(100 * time.Millisecond)
+	ms.doWrite(chunk)
+	if counts := countEvents(ms, 100*time.Millisecond); counts[UserSpeaking] != 1 {
+		t.Errorf("sustained speech should barge in, got %v", counts)
+	}
+}
+
+func TestManagedStream_InterruptStopSpeakingDropsTheUtterance(t *testing.T) {
+	ms, _ := newPolicyStream(t, InterruptStopSpeaking, 0, VADSpeechStart, "", VADSpeechEnd)
+	chunk := make([]byte, 1764)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+	counts := countEvents(ms, 150*time.Millisecond)
+	if counts[Interrupted] != 1 || counts[UserSpeaking] != 0 || counts[UserStopped] != 0 {
+		t.Fatalf("expected the bot to be silenced without a turn, got %v", counts)
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.bargeInHeld || ms.bargeInDropped || ms.audioBuf.Len() > len(chunk) {
+		t.Error("the dropped utterance should be cleared once it ends")
+	}
+}
+
+// stallingLLM hands over the context of each call and answers once it ends.
+type stallingLLM struct {
+	calls chan context.Context
+}
+
+func (s *stallingLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	s.calls <- ctx
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (s *stallingLLM) Name() string { return "stalling" }
+
+func TestManagedStream_InterruptStopAndListenKeepsTheReplyGoing(t *testing.T) {
+	llm := &stallingLLM{calls: make(chan context.Context, 1)}
+	ms := newTestStream(t, testProviders{
+		stt: &MockSTTProvider{transcribeResult: "book a table for two"},
+		llm: llm,
+		vad: &scriptedVAD{script: []VADEventType{VADSpeechStart, VADSpeechEnd, VADSpeechStart}},
+	}, func(cfg *Config) { cfg.InterruptionPolicy = InterruptStopAndListen })
+
+	chunk := make([]byte, 1764)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+	var reply context.Context
+	select {
+	case reply = <-llm.calls:
+	case <-time.After(time.Second):
+		t.Fatal("the bot never started thinking")
+	}
+	countEvents(ms, 20*time.Millisecond)
+
+	ms.doWrite(chunk)
+	if counts := countEvents(ms, 100*time.Millisecond); counts[UserSpeaking] != 1 {
+		t.Fatalf("expected the user to be heard, got %v", counts)
+	}
+	if reply.Err() != nil {
+		t.Fatal("the reply should keep going until the user's words are confirmed")
+	}
+
+	ms.internalInterrupt()
+	if reply.Err() == nil {
+		t.Error("confirming the interruption should cancel the reply")
+	}
+}
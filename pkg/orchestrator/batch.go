@@ -0,0 +1,241 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchConfig controls request batching for self-hosted model servers.
+type BatchConfig struct {
+	MaxBatchSize int           // Flush as soon as this many requests are queued
+	MaxWait      time.Duration // Longest a request waits for companions
+	MaxInFlight  int           // Concurrent batches sent to the server
+}
+
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{MaxBatchSize: 8, MaxWait: 15 * time.Millisecond, MaxInFlight: 1}
+}
+
+// BatchTranscriber is a model server that transcribes several clips in one call.
+// It must return exactly one result per input, in order.
+type BatchTranscriber interface {
+	TranscribeBatch(ctx context.Context, audios [][]byte, lang Language) ([]TranscriptionResult, error)
+	Name() string
+}
+
+// BatchSynthesizer is a model server that synthesizes several texts in one call.
+// It must return exactly one audio buffer per input, in order.
+type BatchSynthesizer interface {
+	SynthesizeBatch(ctx context.Context, texts []string, voice Voice, lang Language) ([][]byte, error)
+	Name() string
+}
+
+type batchItem[T, R any] struct {
+	ctx  context.Context
+	req  T
+	done chan batchResult[R]
+}
+
+type batchResult[R any] struct {
+	res R
+	err error
+}
+
+type pendingBatch[T, R any] struct {
+	items []*batchItem[T, R]
+	timer *time.Timer
+}
+
+// batcher groups concurrent requests with the same key (e.g. language) and
+// runs them as one batch once MaxBatchSize is reached or MaxWait elapses.
+type batcher[T, R any] struct {
+	cfg     BatchConfig
+	run     func(ctx context.Context, key string, reqs []T) ([]R, error)
+	sem     chan struct{}
+	mu      sync.Mutex
+	pending map[string]*pendingBatch[T, R]
+}
+
+func newBatcher[T, R any](cfg BatchConfig, run func(ctx context.Context, key string, reqs []T) ([]R, error)) *batcher[T, R] {
+	def := DefaultBatchConfig()
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = def.MaxBatchSize
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = def.MaxWait
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = def.MaxInFlight
+	}
+	return &batcher[T, R]{
+		cfg:     cfg,
+		run:     run,
+		sem:     make(chan struct{}, cfg.MaxInFlight),
+		pending: make(map[string]*pendingBatch[T, R]),
+	}
+}
+
+func (b *batcher[T, R]) submit(ctx context.Context, key string, req T) (R, error) {
+	item := &batchItem[T, R]{ctx: ctx, req: req, done: make(chan batchResult[R], 1)}
+
+	b.mu.Lock()
+	pb := b.pending[key]
+	if pb == nil {
+		pb = &pendingBatch[T, R]{}
+		b.pending[key] = pb
+		pb.timer = time.AfterFunc(b.cfg.MaxWait, func() { b.flush(key, pb) })
+	}
+	pb.items = append(pb.items, item)
+	if len(pb.items) >= b.cfg.MaxBatchSize {
+		delete(b.pending, key)
+		pb.timer.Stop()
+		go b.execute(key, pb.items)
+	}
+	b.mu.Unlock()
+
+	select {
+	case r := <-item.done:
+		return r.res, r.err
+	case <-ctx.Done():
+		var zero R
+		return zero, ctx.Err()
+	}
+}
+
+func (b *batcher[T, R]) flush(key string, pb *pendingBatch[T, R]) {
+	b.mu.Lock()
+	if b.pending[key] != pb {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, key)
+	b.mu.Unlock()
+	b.execute(key, pb.items)
+}
+
+func (b *batcher[T, R]) execute(key string, items []*batchItem[T, R]) {
+	b.sem <- struct{}{}
+	defer func() { <-b.sem }()
+
+	live := items[:0]
+	for _, it := range items {
+		if it.ctx.Err() == nil {
+			live = append(live, it)
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	// The batch carries the first caller's values, such as its idempotency
+	// key and audit trail, and is cancelled only once every caller in it has
+	// given up.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(live[0].ctx))
+	defer cancel()
+	remaining := int32(len(live))
+	for _, it := range live {
+		stop := context.AfterFunc(it.ctx, func() {
+			if atomic.AddInt32(&remaining, -1) == 0 {
+				cancel()
+			}
+		})
+		defer stop()
+	}
+
+	reqs := make([]T, len(live))
+	for i, it := range live {
+		reqs[i] = it.req
+	}
+	results, err := b.run(ctx, key, reqs)
+	if err == nil && len(results) != len(live) {
+		err = fmt.Errorf("batch backend returned %d results for %d requests", len(results), len(live))
+	}
+	for i, it := range live {
+		if err != nil {
+			it.done <- batchResult[R]{err: err}
+			continue
+		}
+		it.done <- batchResult[R]{res: results[i]}
+	}
+}
+
+// BatchedSTT is an STTProvider that funnels concurrent Transcribe calls into
+// TranscribeBatch calls on a local model server.
+type BatchedSTT struct {
+	backend BatchTranscriber
+	b       *batcher[[]byte, TranscriptionResult]
+}
+
+func NewBatchedSTT(backend BatchTranscriber, cfg BatchConfig) *BatchedSTT {
+	s := &BatchedSTT{backend: backend}
+	s.b = newBatcher(cfg, func(ctx context.Context, key string, audios [][]byte) ([]TranscriptionResult, error) {
+		return backend.TranscribeBatch(ctx, audios, Language(key))
+	})
+	return s
+}
+
+func (s *BatchedSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	return s.b.submit(ctx, string(lang), audio)
+}
+
+func (s *BatchedSTT) Name() string {
+	return s.backend.Name()
+}
+
+type ttsBatchKey struct {
+	voice Voice
+	lang  Language
+}
+
+// BatchedTTS is a TTSProvider that funnels concurrent synthesis calls into
+// SynthesizeBatch calls. Streaming delivers the finished audio as one chunk,
+// so it suits servers where batching beats per-request streaming.
+type BatchedTTS struct {
+	backend BatchSynthesizer
+	b       *batcher[string, []byte]
+}
+
+func NewBatchedTTS(backend BatchSynthesizer, cfg BatchConfig) *BatchedTTS {
+	t := &BatchedTTS{backend: backend}
+	t.b = newBatcher(cfg, func(ctx context.Context, key string, texts []string) ([][]byte, error) {
+		k := decodeTTSBatchKey(key)
+		return backend.SynthesizeBatch(ctx, texts, k.voice, k.lang)
+	})
+	return t
+}
+
+func (k ttsBatchKey) String() string {
+	return string(k.voice) + "|" + string(k.lang)
+}
+
+func decodeTTSBatchKey(key string) ttsBatchKey {
+	for i := 0; i < len(key); i++ {
+		if key[i] == '|' {
+			return ttsBatchKey{voice: Voice(key[:i]), lang: Language(key[i+1:])}
+		}
+	}
+	return ttsBatchKey{voice: Voice(key)}
+}
+
+func (t *BatchedTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	return t.b.submit(ctx, ttsBatchKey{voice, lang}.String(), text)
+}
+
+func (t *BatchedTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	audio, err := t.Synthesize(ctx, text, voice, lang)
+	if err != nil {
+		return err
+	}
+	return onChunk(audio)
+}
+
+func (t *BatchedTTS) Abort() error {
+	return nil
+}
+
+func (t *BatchedTTS) Name() string {
+	return t.backend.Name()
+}
@@ -0,0 +1,154 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingBatchBackend struct {
+	mu      sync.Mutex
+	batches [][]string
+	langs   []Language
+}
+
+func (r *recordingBatchBackend) TranscribeBatch(ctx context.Context, audios [][]byte, lang Language) ([]TranscriptionResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var batch []string
+	results := make([]TranscriptionResult, len(audios))
+	for i, a := range audios {
+		batch = append(batch, string(a))
+		results[i] = TranscriptionResult{Text: strings.ToUpper(string(a))}
+	}
+	r.batches = append(r.batches, batch)
+	r.langs = append(r.langs, lang)
+	return results, nil
+}
+
+func (r *recordingBatchBackend) SynthesizeBatch(ctx context.Context, texts []string, voice Voice, lang Language) ([][]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, texts)
+	out := make([][]byte, len(texts))
+	for i, t := range texts {
+		out[i] = []byte(string(voice) + ":" + t)
+	}
+	return out, nil
+}
+
+func (r *recordingBatchBackend) Name() string { return "local-gpu" }
+
+func TestBatchedSTT_GroupsConcurrentRequests(t *testing.T) {
+	backend := &recordingBatchBackend{}
+	stt := NewBatchedSTT(backend, BatchConfig{MaxBatchSize: 4, MaxWait: time.Second})
+
+	var wg sync.WaitGroup
+	results := make([]string, 4)
+	for i, clip := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func(i int, clip string) {
+			defer wg.Done()
+			res, err := stt.Transcribe(context.Background(), []byte(clip), LanguageEn)
+			if err != nil {
+				t.Errorf("transcribe %s: %v", clip, err)
+			}
+			results[i] = res.Text
+		}(i, clip)
+	}
+	wg.Wait()
+
+	if len(backend.batches) != 1 || len(backend.batches[0]) != 4 {
+		t.Fatalf("expected a single batch of 4 (full batch flushes before MaxWait), got %v", backend.batches)
+	}
+	if strings.Join(results, "") != "ABCD" {
+		t.Errorf("results were not routed back to their callers: %v", results)
+	}
+}
+
+func TestBatchedSTT_FlushesAfterMaxWaitAndSeparatesLanguages(t *testing.T) {
+	backend := &recordingBatchBackend{}
+	stt := NewBatchedSTT(backend, BatchConfig{MaxBatchSize: 10, MaxWait: 20 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for _, lang := range []Language{LanguageEn, LanguageEs, LanguageEn} {
+		wg.Add(1)
+		go func(lang Language) {
+			defer wg.Done()
+			stt.Transcribe(context.Background(), []byte("x"), lang)
+		}(lang)
+	}
+	wg.Wait()
+
+	if len(backend.batches) != 2 {
+		t.Fatalf("expected one batch per language, got %v (%v)", backend.batches, backend.langs)
+	}
+	for i, lang := range backend.langs {
+		want := 1
+		if lang == LanguageEn {
+			want = 2
+		}
+		if len(backend.batches[i]) != want {
+			t.Errorf("language %s: expected batch of %d, got %d", lang, want, len(backend.batches[i]))
+		}
+	}
+}
+
+func TestBatchedTTS(t *testing.T) {
+	backend := &recordingBatchBackend{}
+	tts := NewBatchedTTS(backend, BatchConfig{MaxBatchSize: 2, MaxWait: time.Second})
+
+	var wg sync.WaitGroup
+	for _, text := range []string{"hi", "bye"} {
+		wg.Add(1)
+		go func(text string) {
+			defer wg.Done()
+			var got []byte
+			err := tts.StreamSynthesize(context.Background(), text, VoiceM1, LanguageEn, func(c []byte) error {
+				got = append(got, c...)
+				return nil
+			})
+			if err != nil || string(got) != "M1:"+text {
+				t.Errorf("unexpected synthesis for %q: %q, %v", text, got, err)
+			}
+		}(text)
+	}
+	wg.Wait()
+	if len(backend.batches) != 1 {
+		t.Errorf("expected one batch, got %v", backend.batches)
+	}
+}
+
+func TestBatchedSTT_CallerCancellation(t *testing.T) {
+	stt := NewBatchedSTT(&recordingBatchBackend{}, BatchConfig{MaxBatchSize: 10, MaxWait: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := stt.Transcribe(ctx, []byte("x"), LanguageEn); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+// keyBatchBackend records the idempotency key each batch is run with.
+type keyBatchBackend struct {
+	recordingBatchBackend
+	key string
+}
+
+func (k *keyBatchBackend) TranscribeBatch(ctx context.Context, audios [][]byte, lang Language) ([]TranscriptionResult, error) {
+	k.key = IdempotencyKeyFromContext(ctx)
+	return k.recordingBatchBackend.TranscribeBatch(ctx, audios, lang)
+}
+
+func TestBatchedSTT_KeepsCallerValues(t *testing.T) {
+	backend := &keyBatchBackend{}
+	stt := NewBatchedSTT(backend, BatchConfig{MaxBatchSize: 1, MaxWait: time.Second})
+	ctx := WithIdempotencyKey(context.Background(), "turn-1")
+	if _, err := stt.Transcribe(ctx, []byte("x"), LanguageEn); err != nil {
+		t.Fatal(err)
+	}
+	if backend.key != "turn-1" {
+		t.Errorf("batch ran with idempotency key %q, want the caller's", backend.key)
+	}
+}
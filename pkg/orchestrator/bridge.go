@@ -0,0 +1,438 @@
+package orchestrator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// BridgeEventType identifies a BridgeEvent.
+type BridgeEventType string
+
+const (
+	ParticipantJoined BridgeEventType = "PARTICIPANT_JOINED"
+	ParticipantLeft   BridgeEventType = "PARTICIPANT_LEFT"
+	SpeakerStarted    BridgeEventType = "SPEAKER_STARTED"
+	SpeakerStopped    BridgeEventType = "SPEAKER_STOPPED"
+	BridgeTranscript  BridgeEventType = "BRIDGE_TRANSCRIPT" // Utterance is set
+)
+
+// BridgeEvent reports a change on a Bridge's bus.
+type BridgeEvent struct {
+	Type        BridgeEventType  `json:"type"`
+	Participant string           `json:"participant"`
+	Utterance   *BridgeUtterance `json:"utterance,omitempty"`
+	At          time.Time        `json:"at"`
+}
+
+// BridgeUtterance is a transcript a bot on the bridge heard, attributed to
+// the participant who spoke most while it was said. Speaker is empty when
+// nobody on the bus was speaking, e.g. for a bot's own typed input.
+type BridgeUtterance struct {
+	Speaker  string    `json:"speaker"`
+	Listener string    `json:"listener"` // The bot whose stream transcribed it
+	Text     string    `json:"text"`
+	At       time.Time `json:"at"`
+}
+
+const (
+	// bridgeMaxLag is how much of a human's audio may queue on the bus
+	// before the oldest is dropped, so a participant sending faster than
+	// real time doesn't build up delay.
+	bridgeMaxLag = time.Second
+	// bridgeHangover is how long a speaker stays speaking through quiet.
+	bridgeHangover = 300 * time.Millisecond
+)
+
+// Bridge is a conference bus for two or more sessions and humans. Every
+// frame it takes one frame of audio from each participant and sends each
+// the mix of everyone else it may hear, so nobody hears themselves. Bots
+// join with their ManagedStream: the mix is written to the stream and the
+// bot's replies go on the bus. It tracks who is speaking and attributes
+// what the bots transcribe to a speaker, for agent assist: a bot joins a
+// call between a customer and a human agent in ambient or push-to-talk
+// mode, listens, and speaks only when invoked, to the agent alone if its
+// audience is set so.
+//
+// All audio is 16-bit little-endian mono PCM at the bridge's sample rate,
+// for streams too: their Config.SampleRate and TTS must match it.
+type Bridge struct {
+	sampleRate int
+	frameBytes int
+
+	// SpeechThreshold is the RMS, from 0 to 1, above which a participant
+	// counts as speaking; default 0.02.
+	SpeechThreshold float64
+
+	mu           sync.Mutex
+	participants map[string]*Participant
+	transcript   []BridgeUtterance
+	events       chan BridgeEvent
+	closed       bool
+	done         chan struct{}
+}
+
+// Participant is one party on a Bridge.
+type Participant struct {
+	ID     string
+	bridge *Bridge
+	stream *ManagedStream // nil for humans
+	obs    *Observer
+
+	// Guarded by bridge.mu.
+	in       []byte
+	out      chan []byte
+	audience map[string]bool
+	muted    bool
+	speaking bool
+	quiet    time.Duration
+	heard    map[string]int // Frames each speaker was heard since the bot's listener last heard speech start
+	left     bool
+}
+
+// NewBridge returns a running bridge mixing audio at sampleRate in frames
+// of frame (20ms when 0). Close stops it.
+func NewBridge(sampleRate int, frame time.Duration) *Bridge {
+	b := newBridge(sampleRate, frame)
+	go b.run(frame)
+	return b
+}
+
+func newBridge(sampleRate int, frame time.Duration) *Bridge {
+	if frame <= 0 {
+		frame = 20 * time.Millisecond
+	}
+	return &Bridge{
+		sampleRate:   sampleRate,
+		frameBytes:   int(durationToBytes(frame, sampleRate)),
+		participants: make(map[string]*Participant),
+		events:       make(chan BridgeEvent, 256),
+		done:         make(chan struct{}),
+	}
+}
+
+func (b *Bridge) run(frame time.Duration) {
+	t := time.NewTicker(frame)
+	defer t.Stop()
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-t.C:
+			b.mixFrame()
+		}
+	}
+}
+
+// Events returns the bridge's events. A slow reader drops events; it never
+// stalls the bus. The channel is closed by Close.
+func (b *Bridge) Events() <-chan BridgeEvent {
+	return b.events
+}
+
+// Transcript returns everything the bots on the bridge have transcribed, in
+// order, with its speaker.
+func (b *Bridge) Transcript() []BridgeUtterance {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.transcript)
+}
+
+// Participants returns the IDs of those on the bridge, sorted.
+func (b *Bridge) Participants() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ids := make([]string, 0, len(b.participants))
+	for id := range b.participants {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Join adds a human, or any other audio source the caller drives, under
+// id. Send their audio with Write and play what Audio delivers.
+func (b *Bridge) Join(id string) (*Participant, error) {
+	return b.join(id, nil)
+}
+
+// JoinStream adds a bot under id. The mix of the others is written to ms
+// as its input, and the audio ms speaks goes on the bus; the caller still
+// drains ms.Events. The bot leaves when ms closes.
+func (b *Bridge) JoinStream(id string, ms *ManagedStream) (*Participant, error) {
+	return b.join(id, ms)
+}
+
+func (b *Bridge) join(id string, ms *ManagedStream) (*Participant, error) {
+	p := &Participant{ID: id, bridge: b, stream: ms, out: make(chan []byte, 50)}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("bridge is closed")
+	}
+	if _, ok := b.participants[id]; ok {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("participant %q is already on the bridge", id)
+	}
+	b.participants[id] = p
+	b.emitLocked(BridgeEvent{Type: ParticipantJoined, Participant: id})
+	b.mu.Unlock()
+
+	if ms != nil {
+		p.obs = ms.Observe(1024)
+		go p.listen()
+		go func() {
+			for mix := range p.out {
+				if ms.ctx.Err() != nil {
+					continue
+				}
+				ms.Write(mix)
+			}
+		}()
+	}
+	return p, nil
+}
+
+// listen puts a bot's audio on the bus and attributes its transcripts.
+func (p *Participant) listen() {
+	for ev := range p.obs.Events() {
+		switch ev.Type {
+		case AudioChunk:
+			if chunk, ok := ev.Data.([]byte); ok {
+				p.bridge.mu.Lock()
+				p.in = append(p.in, chunk...)
+				p.bridge.mu.Unlock()
+			}
+		case Interrupted:
+			p.bridge.mu.Lock()
+			p.in = nil
+			p.bridge.mu.Unlock()
+		case UserSpeaking:
+			p.bridge.mu.Lock()
+			p.heard = nil
+			p.bridge.mu.Unlock()
+		case TranscriptFinal, AmbientTranscript:
+			if text, ok := ev.Data.(string); ok && text != "" {
+				p.bridge.attribute(p, text)
+			}
+		}
+	}
+	p.Leave()
+}
+
+func (b *Bridge) attribute(p *Participant, text string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var speaker string
+	most := 0
+	for id, n := range p.heard {
+		if n > most || (n == most && id < speaker) {
+			speaker, most = id, n
+		}
+	}
+	p.heard = nil
+	u := BridgeUtterance{Speaker: speaker, Listener: p.ID, Text: text, At: time.Now()}
+	b.transcript = append(b.transcript, u)
+	b.emitLocked(BridgeEvent{Type: BridgeTranscript, Participant: speaker, Utterance: &u, At: u.At})
+}
+
+// Write puts the participant's audio on the bus. Audio is played out in
+// real time; more than a second queued ahead is dropped.
+func (p *Participant) Write(pcm []byte) error {
+	b := p.bridge
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p.left {
+		return fmt.Errorf("participant %q has left the bridge", p.ID)
+	}
+	p.in = append(p.in, pcm...)
+	if limit := int(durationToBytes(bridgeMaxLag, b.sampleRate)); len(p.in) > limit {
+		p.in = p.in[len(p.in)-limit:]
+	}
+	return nil
+}
+
+// Audio delivers, one frame at a time, the mix of everyone the participant
+// hears. Frames are dropped if they aren't read in time. The channel is
+// closed when the participant leaves.
+func (p *Participant) Audio() <-chan []byte {
+	return p.out
+}
+
+// SetAudience limits who hears the participant to ids, e.g. a bot that
+// prompts a human agent without the customer hearing. No ids lets everyone
+// hear it again.
+func (p *Participant) SetAudience(ids ...string) {
+	p.bridge.mu.Lock()
+	defer p.bridge.mu.Unlock()
+	p.audience = nil
+	if len(ids) > 0 {
+		p.audience = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			p.audience[id] = true
+		}
+	}
+}
+
+// Mute takes the participant's audio off the bus, or puts it back. Audio
+// written while muted is discarded.
+func (p *Participant) Mute(muted bool) {
+	p.bridge.mu.Lock()
+	defer p.bridge.mu.Unlock()
+	p.muted = muted
+}
+
+// Leave takes the participant off the bridge and closes Audio. A bot's
+// stream is left running.
+func (p *Participant) Leave() {
+	b := p.bridge
+	b.mu.Lock()
+	if !b.leaveLocked(p) {
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+	if p.obs != nil {
+		p.obs.Close()
+	}
+}
+
+func (b *Bridge) leaveLocked(p *Participant) bool {
+	if p.left {
+		return false
+	}
+	p.left = true
+	delete(b.participants, p.ID)
+	close(p.out)
+	if p.speaking {
+		b.emitLocked(BridgeEvent{Type: SpeakerStopped, Participant: p.ID})
+	}
+	b.emitLocked(BridgeEvent{Type: ParticipantLeft, Participant: p.ID})
+	return true
+}
+
+// hears reports whether p hears speaker.
+func (p *Participant) hears(speaker *Participant) bool {
+	return p != speaker && !speaker.muted && (speaker.audience == nil || speaker.audience[p.ID])
+}
+
+// mixFrame advances the bus by one frame.
+func (b *Bridge) mixFrame() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	threshold := b.SpeechThreshold
+	if threshold <= 0 {
+		threshold = 0.02
+	}
+	frameDur := bytesToDuration(int64(b.frameBytes), b.sampleRate)
+
+	ps := make([]*Participant, 0, len(b.participants))
+	for _, p := range b.participants {
+		ps = append(ps, p)
+	}
+	frames := make(map[*Participant][]int16, len(ps))
+	for _, p := range ps {
+		n := min(len(p.in), b.frameBytes)
+		frame := p.in[:n]
+		p.in = p.in[n:]
+		if len(p.in) == 0 {
+			p.in = nil
+		}
+		if p.muted {
+			frame = nil
+		}
+		b.trackSpeakerLocked(p, chunkRMS(frame) > threshold, frameDur)
+		if len(frame) > 0 {
+			samples := make([]int16, len(frame)/2)
+			for i := range samples {
+				samples[i] = int16(binary.LittleEndian.Uint16(frame[2*i:]))
+			}
+			frames[p] = samples
+		}
+	}
+
+	for _, listener := range ps {
+		mix := make([]int32, b.frameBytes/2)
+		for speaker, samples := range frames {
+			if !listener.hears(speaker) {
+				continue
+			}
+			for i, s := range samples {
+				mix[i] += int32(s)
+			}
+			if listener.stream != nil && speaker.speaking {
+				if listener.heard == nil {
+					listener.heard = make(map[string]int)
+				}
+				listener.heard[speaker.ID]++
+			}
+		}
+		out := make([]byte, b.frameBytes)
+		for i, v := range mix {
+			binary.LittleEndian.PutUint16(out[2*i:], uint16(int16(max(-32768, min(32767, v)))))
+		}
+		select {
+		case listener.out <- out:
+		default:
+		}
+	}
+}
+
+func (b *Bridge) trackSpeakerLocked(p *Participant, loud bool, frame time.Duration) {
+	switch {
+	case loud:
+		p.quiet = 0
+		if !p.speaking {
+			p.speaking = true
+			b.emitLocked(BridgeEvent{Type: SpeakerStarted, Participant: p.ID})
+		}
+	case p.speaking:
+		p.quiet += frame
+		if p.quiet >= bridgeHangover {
+			p.speaking = false
+			b.emitLocked(BridgeEvent{Type: SpeakerStopped, Participant: p.ID})
+		}
+	}
+}
+
+func (b *Bridge) emitLocked(ev BridgeEvent) {
+	if b.closed {
+		return
+	}
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	select {
+	case b.events <- ev:
+	default:
+	}
+}
+
+// Close takes everyone off the bridge, stops it and closes Events. Bots'
+// streams are left running.
+func (b *Bridge) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	var observers []*Observer
+	for _, p := range b.participants {
+		b.leaveLocked(p)
+		if p.obs != nil {
+			observers = append(observers, p.obs)
+		}
+	}
+	b.closed = true
+	close(b.done)
+	close(b.events)
+	b.mu.Unlock()
+	for _, obs := range observers {
+		obs.Close()
+	}
+}
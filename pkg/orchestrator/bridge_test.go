@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// busFrame returns a 20ms frame at 16kHz of constant samples.
+func busFrame(v int16) []byte {
+	frame := make([]byte, 640)
+	for i := 0; i < len(frame); i += 2 {
+		binary.LittleEndian.PutUint16(frame[i:], uint16(v))
+	}
+	return frame
+}
+
+func sampleAt(frame []byte, i int) int16 {
+	return int16(binary.LittleEndian.Uint16(frame[2*i:]))
+}
+
+func TestBridge_MixMinusAndAudience(t *testing.T) {
+	b := newBridge(16000, 20*time.Millisecond)
+	customer, _ := b.Join("customer")
+	agent, _ := b.Join("agent")
+	bot, _ := b.Join("bot")
+	if _, err := b.Join("agent"); err == nil {
+		t.Error("joining twice should fail")
+	}
+
+	customer.Write(busFrame(1000))
+	agent.Write(busFrame(2000))
+	bot.Write(busFrame(4000))
+	bot.SetAudience("agent")
+	b.mixFrame()
+
+	// Nobody hears themselves, and only the agent hears the bot.
+	for p, want := range map[*Participant]int16{customer: 2000, agent: 5000, bot: 3000} {
+		frame := <-p.Audio()
+		if len(frame) != 640 || sampleAt(frame, 10) != want {
+			t.Errorf("%s hears %d, want %d", p.ID, sampleAt(frame, 10), want)
+		}
+	}
+
+	// The mix clips instead of wrapping around, and a muted participant
+	// is taken off the bus.
+	customer.Write(busFrame(30000))
+	agent.Write(busFrame(30000))
+	bot.Write(busFrame(30000))
+	bot.Mute(true)
+	b.mixFrame()
+	if got := sampleAt(<-bot.Audio(), 0); got != 32767 {
+		t.Errorf("clipped mix = %d", got)
+	}
+	if got := sampleAt(<-agent.Audio(), 0); got != 30000 {
+		t.Errorf("agent hears %d with the bot muted", got)
+	}
+	<-customer.Audio()
+
+	bot.Leave()
+	if _, ok := <-bot.Audio(); ok {
+		t.Error("Audio should close on Leave")
+	}
+	if err := bot.Write(busFrame(1)); err == nil {
+		t.Error("Write after Leave should fail")
+	}
+	if got := b.Participants(); len(got) != 2 || got[0] != "agent" || got[1] != "customer" {
+		t.Errorf("participants = %v", got)
+	}
+}
+
+func TestBridge_SpeakersAndAttribution(t *testing.T) {
+	b := newBridge(16000, 20*time.Millisecond)
+	customer, _ := b.Join("customer")
+	agent, _ := b.Join("agent")
+	bot, _ := b.Join("bot")
+	bot.stream = &ManagedStream{} // Listens like a bot, without running one
+
+	for range 5 {
+		customer.Write(busFrame(3000))
+		b.mixFrame()
+	}
+	for range 2 {
+		customer.Write(busFrame(3000))
+		agent.Write(busFrame(3000))
+		b.mixFrame()
+	}
+	b.attribute(bot, "my card was charged twice")
+	// Quiet past the hangover ends both speakers.
+	for range 16 {
+		b.mixFrame()
+	}
+	b.Close()
+
+	tr := b.Transcript()
+	if len(tr) != 1 || tr[0].Speaker != "customer" || tr[0].Listener != "bot" || tr[0].Text != "my card was charged twice" {
+		t.Fatalf("transcript = %+v", tr)
+	}
+
+	var got []BridgeEventType
+	var who []string
+	for ev := range b.Events() {
+		if ev.Type == ParticipantJoined || ev.Type == ParticipantLeft {
+			continue
+		}
+		got = append(got, ev.Type)
+		who = append(who, ev.Participant)
+	}
+	want := []BridgeEventType{SpeakerStarted, SpeakerStarted, BridgeTranscript, SpeakerStopped, SpeakerStopped}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v %v", got, who)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v %v", got, who)
+		}
+	}
+	if who[0] != "customer" || who[1] != "agent" || who[2] != "customer" {
+		t.Errorf("speakers = %v", who)
+	}
+}
@@ -0,0 +1,340 @@
+package orchestrator
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache stores opaque values by key. It backs every cache layer of the
+// orchestrator (TTS audio, LLM responses, and retrieval layers built on
+// StageCache), so one store such as Redis can serve them all. A ttl of zero
+// means the entry does not expire.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// MemoryCache is an in-process Cache that evicts the least recently used
+// entry once full.
+type MemoryCache struct {
+	maxEntries int
+	mu         sync.Mutex
+	order      *list.List // Front is most recently used
+	entries    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache holding at most maxEntries values;
+// zero or less means unbounded.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{maxEntries: maxEntries, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := el.Value.(*memoryEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	return e.value, true, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	e := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(e)
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of entries held, including expired ones not yet
+// looked up.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// DiskCache is a Cache keeping one file per entry in a directory, so cached
+// audio survives restarts. Expired entries are removed when read.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DiskCache in dir, creating it if needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Entries are stored as an 8-byte expiry in Unix nanoseconds (0 for none)
+// followed by the value.
+func (c *DiskCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) < 8 {
+		return nil, false, fmt.Errorf("disk cache: corrupt entry for %q", key)
+	}
+	if exp := int64(binary.BigEndian.Uint64(data)); exp != 0 && time.Now().UnixNano() > exp {
+		os.Remove(c.path(key))
+		return nil, false, nil
+	}
+	return data[8:], true, nil
+}
+
+func (c *DiskCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	data := make([]byte, 8, 8+len(value))
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(data, uint64(time.Now().Add(ttl).UnixNano()))
+	}
+	data = append(data, value...)
+
+	// Write then rename so readers never see a partial entry.
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// CacheStats counts the lookups of one stage.
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// HitRate is the share of lookups that hit, or 0 before any lookup.
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// StageCache puts a Cache in front of stages and keeps hit rates for each.
+// Its Middleware caches STT, LLM and TTS calls; other layers, such as a
+// retrieval step, use Lookup and Store under a Stage of their own, e.g.
+// Stage("retrieval"). Keys do not include the provider, so use a separate
+// Prefix for each model or voice set sharing a store.
+//
+// Entries are shared by all users and PurgeUser does not remove them. STT and
+// LLM calls, which hold what users said, are therefore only cached with
+// UserData set; TTS audio is keyed by the bot's text, which can still quote
+// the user, so give the cache a TTL that fits the retention policy.
+type StageCache struct {
+	Cache    Cache
+	TTL      time.Duration // For entries stored; zero keeps them until evicted
+	Prefix   string        // Prepended to every key
+	UserData bool          // Also cache STT and LLM calls, beyond the reach of PurgeUser
+
+	mu    sync.Mutex
+	stats map[Stage]*CacheStats
+}
+
+// NewStageCache returns a StageCache over c.
+func NewStageCache(c Cache, ttl time.Duration) *StageCache {
+	return &StageCache{Cache: c, TTL: ttl}
+}
+
+// Key derives a cache key for stage from parts, which must marshal to JSON.
+func (c *StageCache) Key(stage Stage, parts ...interface{}) string {
+	data, _ := json.Marshal(parts)
+	sum := sha256.Sum256(data)
+	return c.Prefix + string(stage) + ":" + hex.EncodeToString(sum[:])
+}
+
+// Lookup returns the value cached for key, counting a hit or miss for stage.
+// A failing store counts as a miss, so a cache outage only costs latency.
+func (c *StageCache) Lookup(ctx context.Context, stage Stage, key string) ([]byte, bool) {
+	value, ok, err := c.Cache.Get(ctx, key)
+	if err != nil {
+		ok = false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats == nil {
+		c.stats = make(map[Stage]*CacheStats)
+	}
+	s := c.stats[stage]
+	if s == nil {
+		s = &CacheStats{}
+		c.stats[stage] = s
+	}
+	if ok {
+		s.Hits++
+	} else {
+		s.Misses++
+	}
+	return value, ok
+}
+
+// Store caches value under key with the StageCache's TTL.
+func (c *StageCache) Store(ctx context.Context, key string, value []byte) error {
+	return c.Cache.Set(ctx, key, value, c.TTL)
+}
+
+// Stats returns the lookup counts of every stage looked up so far.
+func (c *StageCache) Stats() map[Stage]CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[Stage]CacheStats, len(c.stats))
+	for stage, s := range c.stats {
+		out[stage] = *s
+	}
+	return out
+}
+
+// Middleware caches stage calls: audio by text, voice, language and speech
+// rate and, with UserData, transcripts by audio and LLM responses by messages
+// and tools. Streamed calls are cached too and replayed in one piece. LLM
+// responses that called a tool, and failed calls, are not cached. Install it with
+// Orchestrator.Use or, for some stages only, UseStage.
+func (c *StageCache) Middleware() Middleware {
+	return func(next StageFunc) StageFunc {
+		return func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+			key := c.requestKey(ctx, req)
+			if key == "" || (req.Stage != StageTTS && !c.UserData) {
+				return next(ctx, req)
+			}
+			if value, ok := c.Lookup(ctx, req.Stage, key); ok {
+				if resp, err := replayCached(req, value); resp != nil || err != nil {
+					return resp, err
+				}
+			}
+
+			var streamed bytes.Buffer
+			calledTool := false
+			if req.OnAudio != nil {
+				onAudio := req.OnAudio
+				req.OnAudio = func(chunk []byte) error {
+					streamed.Write(chunk)
+					return onAudio(chunk)
+				}
+			}
+			if req.OnToolCall != nil {
+				onToolCall := req.OnToolCall
+				req.OnToolCall = func(tc ToolCallEventData) error {
+					calledTool = true
+					return onToolCall(tc)
+				}
+			}
+
+			resp, err := next(ctx, req)
+			if err != nil || resp == nil || calledTool {
+				return resp, err
+			}
+			var value []byte
+			switch req.Stage {
+			case StageSTT:
+				value, _ = json.Marshal(resp.Transcript)
+			case StageLLM:
+				value = []byte(resp.Text)
+			case StageTTS:
+				value = resp.Audio
+				if req.OnAudio != nil {
+					value = streamed.Bytes()
+				}
+			}
+			if len(value) > 0 {
+				c.Store(ctx, key, value)
+			}
+			return resp, nil
+		}
+	}
+}
+
+func (c *StageCache) requestKey(ctx context.Context, req *StageRequest) string {
+	parts := stageInput(ctx, req)
+	if parts == nil {
+		return ""
+	}
+	return c.Key(req.Stage, parts...)
+}
+
+// replayCached answers req from a cached value, feeding streaming callbacks
+// the whole value at once. It returns nil for an unreadable value, which is
+// then treated as a miss.
+func replayCached(req *StageRequest, value []byte) (*StageResponse, error) {
+	switch req.Stage {
+	case StageSTT:
+		var res TranscriptionResult
+		if json.Unmarshal(value, &res) != nil {
+			return nil, nil
+		}
+		return &StageResponse{Transcript: res}, nil
+	case StageLLM:
+		if req.OnText != nil {
+			if err := req.OnText(string(value)); err != nil {
+				return nil, err
+			}
+		}
+		return &StageResponse{Text: string(value)}, nil
+	case StageTTS:
+		if req.OnAudio != nil {
+			if err := req.OnAudio(value); err != nil {
+				return nil, err
+			}
+			return &StageResponse{}, nil
+		}
+		return &StageResponse{Audio: value}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,213 @@
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_EvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	c.Get(ctx, "a") // b is now least recently used
+	c.Set(ctx, "c", []byte("3"), 0)
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if v, ok, _ := c.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Errorf("expected a to survive, got %q", v)
+	}
+
+	c.Set(ctx, "d", []byte("4"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := c.Get(ctx, "d"); ok {
+		t.Error("expected d to expire")
+	}
+}
+
+func TestDiskCache_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "greeting", []byte{1, 2, 3}, 0); err != nil {
+		t.Fatal(err)
+	}
+	c.Set(ctx, "stale", []byte{4}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	reopened, _ := NewDiskCache(dir)
+	if v, ok, err := reopened.Get(ctx, "greeting"); !ok || err != nil || string(v) != "\x01\x02\x03" {
+		t.Errorf("got %v, %v, %v", v, ok, err)
+	}
+	if _, ok, _ := reopened.Get(ctx, "stale"); ok {
+		t.Error("expected the stale entry to expire")
+	}
+}
+
+func TestStageCache_CachesStagesAndCountsHits(t *testing.T) {
+	tts := &rateTTS{}
+	llm := &MockStreamingLLM{responses: []struct {
+		content   string
+		toolCalls []ToolCallEventData
+	}{{content: "We open at nine."}}}
+	o := NewWithVAD(&MockSTTProvider{}, llm, tts, nil, DefaultConfig())
+	cache := NewStageCache(NewMemoryCache(0), time.Hour)
+	cache.UserData = true
+	o.Use(cache.Middleware())
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := o.Synthesize(ctx, "One moment.", VoiceF1, LanguageEn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(tts.texts) != 1 {
+		t.Errorf("expected one synthesis, got %d", len(tts.texts))
+	}
+
+	for i := 0; i < 2; i++ {
+		session := NewConversationSession("u")
+		session.AddMessage(RoleUser, "when do you open")
+		var streamed strings.Builder
+		text, err := o.streamComplete(ctx, session, llm, session.GetContextCopy(), nil, func(chunk string) error {
+			streamed.WriteString(chunk)
+			return nil
+		}, nil)
+		if err != nil || text != "We open at nine." || streamed.String() != text {
+			t.Errorf("call %d: got %q / %q, %v", i, text, streamed.String(), err)
+		}
+	}
+
+	stats := cache.Stats()
+	if s := stats[StageTTS]; s.Hits != 2 || s.Misses != 1 {
+		t.Errorf("tts stats: %+v", s)
+	}
+	if s := stats[StageLLM]; s.Hits != 1 || s.Misses != 1 || s.HitRate() != 0.5 {
+		t.Errorf("llm stats: %+v", s)
+	}
+
+	// Other layers share the store under their own stage.
+	key := cache.Key("retrieval", "opening hours")
+	if _, ok := cache.Lookup(ctx, "retrieval", key); ok {
+		t.Error("unexpected hit")
+	}
+	cache.Store(ctx, key, []byte("Mon-Fri 9-17"))
+	if v, ok := cache.Lookup(ctx, "retrieval", key); !ok || string(v) != "Mon-Fri 9-17" {
+		t.Errorf("got %q", v)
+	}
+	if s := cache.Stats()["retrieval"]; s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("retrieval stats: %+v", s)
+	}
+}
+
+func TestStageCache_SkipsUserDataByDefault(t *testing.T) {
+	llm := &MockLLMProvider{completeResult: "We open at nine."}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "when do you open"}, llm, &rateTTS{}, nil, DefaultConfig())
+	cache := NewStageCache(NewMemoryCache(0), time.Hour)
+	o.Use(cache.Middleware())
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := o.Transcribe(ctx, []byte{1, 2}, LanguageEn); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := o.GenerateResponse(ctx, NewConversationSession("u")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := cache.Stats(); len(stats) != 0 {
+		t.Errorf("expected STT and LLM calls to bypass the cache, got %+v", stats)
+	}
+	if n := cache.Cache.(*MemoryCache).Len(); n != 0 {
+		t.Errorf("expected nothing cached, got %d entries", n)
+	}
+}
+
+// fakeRedis serves GET and SET from a map.
+func fakeRedis(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	var mu sync.Mutex
+	data := map[string]string{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+					args := make([]string, n)
+					for i := range args {
+						line, _ = r.ReadString('\n')
+						size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+						buf := make([]byte, size+2)
+						io.ReadFull(r, buf)
+						args[i] = string(buf[:size])
+					}
+					mu.Lock()
+					switch strings.ToUpper(args[0]) {
+					case "SET":
+						data[args[1]] = args[2]
+						fmt.Fprint(conn, "+OK\r\n")
+					case "GET":
+						if v, ok := data[args[1]]; ok {
+							fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v), v)
+						} else {
+							fmt.Fprint(conn, "$-1\r\n")
+						}
+					default:
+						fmt.Fprint(conn, "-ERR unknown command\r\n")
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestRedisCache_GetSet(t *testing.T) {
+	ctx := context.Background()
+	c := NewRedisCache(fakeRedis(t))
+	defer c.Close()
+
+	if _, ok, err := c.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("expected a clean miss, got %v, %v", ok, err)
+	}
+	audio := []byte("pcm\r\n\x00\xff")
+	if err := c.Set(ctx, "tts:hello", audio, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, err := c.Get(ctx, "tts:hello"); !ok || err != nil || string(v) != string(audio) {
+		t.Errorf("got %q, %v, %v", v, ok, err)
+	}
+
+	c.DB = 3 // SELECT is refused by the fake server
+	c.Close()
+	if _, _, err := c.Get(ctx, "tts:hello"); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("expected the server's error, got %v", err)
+	}
+}
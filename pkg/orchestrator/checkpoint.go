@@ -0,0 +1,75 @@
+package orchestrator
+
+import "strconv"
+
+// maxCheckpoints bounds how many checkpoints a session keeps; the oldest are
+// forgotten first.
+const maxCheckpoints = 32
+
+type checkpoint struct {
+	id            string
+	context       []Message
+	lastUser      string
+	lastAssistant string
+}
+
+// Checkpoint records the conversation as it is now and returns an ID that
+// Rollback can return to, e.g. before asking the user to confirm a booking.
+// Checkpoints live in memory only and are not part of a SessionSnapshot.
+func (s *ConversationSession) Checkpoint() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpointSeq++
+	cp := s.captureLocked()
+	cp.id = "cp-" + strconv.Itoa(s.checkpointSeq)
+	s.checkpoints = append(s.checkpoints, cp)
+	if len(s.checkpoints) > maxCheckpoints {
+		s.checkpoints = s.checkpoints[len(s.checkpoints)-maxCheckpoints:]
+	}
+	return cp.id
+}
+
+// Rollback restores the context saved by Checkpoint, undoing every message
+// added since. Checkpoints taken after id are discarded; id itself stays
+// valid so a flow can roll back to it again. Token usage is not rolled back.
+func (s *ConversationSession) Rollback(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.checkpoints) - 1; i >= 0; i-- {
+		cp := s.checkpoints[i]
+		if cp.id != id {
+			continue
+		}
+		s.restoreLocked(cp)
+		s.checkpoints = s.checkpoints[:i+1]
+		return nil
+	}
+	return ErrCheckpointNotFound
+}
+
+// ReleaseCheckpoint forgets a checkpoint that is no longer needed.
+func (s *ConversationSession) ReleaseCheckpoint(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, cp := range s.checkpoints {
+		if cp.id == id {
+			s.checkpoints = append(s.checkpoints[:i], s.checkpoints[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *ConversationSession) captureLocked() checkpoint {
+	return checkpoint{
+		context:       append([]Message(nil), s.Context...),
+		lastUser:      s.LastUser,
+		lastAssistant: s.LastAssistant,
+	}
+}
+
+func (s *ConversationSession) restoreLocked(cp checkpoint) {
+	s.Context = append([]Message{}, cp.context...)
+	s.LastUser = cp.lastUser
+	s.LastAssistant = cp.lastAssistant
+	s.pendingUsage = TokenUsage{}
+}
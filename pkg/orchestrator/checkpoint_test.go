@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckpointRollback(t *testing.T) {
+	s := NewConversationSession("u")
+	s.AddMessage(RoleUser, "book a table for two")
+	s.AddMessage(RoleAssistant, "For two at eight, correct?")
+	cp := s.Checkpoint()
+
+	s.AddMessage(RoleUser, "no, that's wrong")
+	s.AddMessage(RoleAssistant, "Sorry, how many people?")
+	later := s.Checkpoint()
+	s.AddMessage(RoleUser, "four")
+
+	if err := s.Rollback(cp); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	ctx := s.GetContextCopy()
+	if len(ctx) != 2 || s.LastUser != "book a table for two" || s.LastAssistant != "For two at eight, correct?" {
+		t.Errorf("context not restored: %+v (last user %q)", ctx, s.LastUser)
+	}
+	if err := s.Rollback(later); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("checkpoints after the rollback point should be discarded, got %v", err)
+	}
+
+	// The restored checkpoint can be used again.
+	s.AddMessage(RoleUser, "make it three")
+	if err := s.Rollback(cp); err != nil || len(s.GetContextCopy()) != 2 {
+		t.Errorf("expected a second rollback to the same checkpoint, got %v", err)
+	}
+
+	s.ReleaseCheckpoint(cp)
+	if err := s.Rollback(cp); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("expected released checkpoint to be gone, got %v", err)
+	}
+}
+
+func TestCheckpoint_SurvivesTrimming(t *testing.T) {
+	s := NewConversationSession("u")
+	s.MaxMessages = 2
+	s.AddMessage(RoleUser, "one")
+	s.AddMessage(RoleAssistant, "two")
+	cp := s.Checkpoint()
+	s.AddMessage(RoleUser, "three")
+	s.AddMessage(RoleAssistant, "four")
+
+	if err := s.Rollback(cp); err != nil {
+		t.Fatal(err)
+	}
+	if ctx := s.GetContextCopy(); len(ctx) != 2 || ctx[0].Content != "one" {
+		t.Errorf("expected messages trimmed after the checkpoint to come back, got %+v", ctx)
+	}
+}
@@ -0,0 +1,237 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChunkedSTTConfig tunes ChunkedStreamingSTT.
+type ChunkedSTTConfig struct {
+	SampleRate    int           // Input PCM rate (16-bit mono)
+	Step          time.Duration // How often the window is re-transcribed
+	MinAudio      time.Duration // Audio needed before the first interim result
+	MaxWindow     time.Duration // Window length after which the hypothesis is committed
+	MinStableRuns int           // Consecutive runs that must agree on a word before it is stable
+}
+
+func DefaultChunkedSTTConfig() ChunkedSTTConfig {
+	return ChunkedSTTConfig{
+		SampleRate:    44100,
+		Step:          500 * time.Millisecond,
+		MinAudio:      700 * time.Millisecond,
+		MaxWindow:     12 * time.Second,
+		MinStableRuns: 2,
+	}
+}
+
+// ChunkedStreamingSTT turns a batch STTProvider - typically a local Whisper
+// server, where each call is cheap - into a StreamingSTTProvider. It
+// re-transcribes a sliding window every Step and only reports words once
+// MinStableRuns consecutive hypotheses agree on them (local agreement), so
+// interim transcripts arrive within about a second and don't flicker.
+type ChunkedStreamingSTT struct {
+	inner STTProvider
+	cfg   ChunkedSTTConfig
+}
+
+func NewChunkedStreamingSTT(inner STTProvider, cfg ChunkedSTTConfig) *ChunkedStreamingSTT {
+	def := DefaultChunkedSTTConfig()
+	if cfg.SampleRate <= 0 {
+		cfg.SampleRate = def.SampleRate
+	}
+	if cfg.Step <= 0 {
+		cfg.Step = def.Step
+	}
+	if cfg.MinAudio <= 0 {
+		cfg.MinAudio = def.MinAudio
+	}
+	if cfg.MaxWindow <= 0 {
+		cfg.MaxWindow = def.MaxWindow
+	}
+	if cfg.MinStableRuns <= 0 {
+		cfg.MinStableRuns = def.MinStableRuns
+	}
+	return &ChunkedStreamingSTT{inner: inner, cfg: cfg}
+}
+
+func (c *ChunkedStreamingSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	return c.inner.Transcribe(ctx, audio, lang)
+}
+
+func (c *ChunkedStreamingSTT) Name() string {
+	return c.inner.Name() + "-chunked"
+}
+
+func (c *ChunkedStreamingSTT) bytesFor(d time.Duration) int {
+	n := int(d.Seconds() * float64(c.cfg.SampleRate) * 2)
+	return n &^ 1
+}
+
+func (c *ChunkedStreamingSTT) StreamTranscribe(ctx context.Context, lang Language, onTranscript func(transcript string, isFinal bool) error) (chan<- []byte, error) {
+	in := make(chan []byte, 256)
+	s := &chunkedStream{cfg: c, lang: lang, onTranscript: onTranscript, wake: make(chan struct{}, 1)}
+
+	// Intake never blocks on inference, so callers doing non-blocking sends
+	// don't lose audio while a window is being transcribed.
+	go func() {
+		defer s.signal()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case chunk, ok := <-in:
+				if !ok {
+					s.mu.Lock()
+					s.closed = true
+					s.mu.Unlock()
+					return
+				}
+				s.mu.Lock()
+				s.window = append(s.window, chunk...)
+				s.mu.Unlock()
+			}
+		}
+	}()
+	go s.run(ctx)
+	return in, nil
+}
+
+type chunkedStream struct {
+	cfg          *ChunkedStreamingSTT
+	lang         Language
+	onTranscript func(string, bool) error
+
+	mu     sync.Mutex
+	window []byte // Audio not yet covered by committed text
+	closed bool
+	wake   chan struct{}
+
+	committed []string   // Words from windows that were cut at MaxWindow
+	history   [][]string // Recent hypotheses for the current window
+	stable    []string   // Words of the current window that are stable, as first agreed
+	lastSent  string
+}
+
+func (s *chunkedStream) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *chunkedStream) run(ctx context.Context) {
+	defer ReportTranscriptionDone(ctx)
+	ticker := time.NewTicker(s.cfg.cfg.Step)
+	defer ticker.Stop()
+	transcribed := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		s.mu.Lock()
+		audio := append([]byte(nil), s.window...)
+		closed := s.closed
+		s.mu.Unlock()
+
+		if closed {
+			s.finish(ctx, audio)
+			return
+		}
+		if len(audio) < s.cfg.bytesFor(s.cfg.cfg.MinAudio) || len(audio) == transcribed {
+			continue
+		}
+		transcribed = len(audio)
+
+		res, err := s.cfg.inner.Transcribe(ctx, audio, s.lang)
+		if err != nil {
+			continue // The next step or the final pass will try again
+		}
+		s.observe(strings.Fields(res.Text))
+
+		if len(audio) >= s.cfg.bytesFor(s.cfg.cfg.MaxWindow) {
+			// Without word timestamps the window can only be cut as a whole:
+			// commit the latest hypothesis and start a fresh window.
+			s.committed = append(s.committed, s.settle(s.history[len(s.history)-1])...)
+			s.history, s.stable = nil, nil
+			s.mu.Lock()
+			s.window = s.window[len(audio):]
+			s.mu.Unlock()
+			transcribed = 0
+		}
+
+		if text := s.interim(); text != "" && text != s.lastSent {
+			s.lastSent = text
+			if s.onTranscript(text, false) != nil {
+				return
+			}
+		}
+	}
+}
+
+// observe records a hypothesis and advances the stable prefix to the words
+// the last MinStableRuns hypotheses agree on. Stable words never retract:
+// later hypotheses, and the final pass, only change the words after them.
+func (s *chunkedStream) observe(hyp []string) {
+	s.history = append(s.history, hyp)
+	runs := s.cfg.cfg.MinStableRuns
+	if len(s.history) > runs {
+		s.history = s.history[len(s.history)-runs:]
+	}
+	if len(s.history) < runs {
+		return
+	}
+	agreed := len(s.history[0])
+	for _, h := range s.history[1:] {
+		agreed = min(agreed, commonPrefix(s.history[0], h))
+	}
+	if agreed > len(s.stable) {
+		s.stable = append(s.stable, hyp[len(s.stable):agreed]...)
+	}
+}
+
+// settle returns the window's words given hyp: the stable prefix followed by
+// hyp's words after it.
+func (s *chunkedStream) settle(hyp []string) []string {
+	words := append([]string(nil), s.stable...)
+	if len(hyp) > len(s.stable) {
+		words = append(words, hyp[len(s.stable):]...)
+	}
+	return words
+}
+
+func (s *chunkedStream) interim() string {
+	return strings.Join(append(append([]string(nil), s.committed...), s.stable...), " ")
+}
+
+func (s *chunkedStream) finish(ctx context.Context, audio []byte) {
+	words := append([]string(nil), s.committed...)
+	if len(audio) > 0 {
+		res, err := s.cfg.inner.Transcribe(ctx, audio, s.lang)
+		if err == nil {
+			words = append(words, s.settle(strings.Fields(res.Text))...)
+		} else if len(s.history) > 0 {
+			words = append(words, s.settle(s.history[len(s.history)-1])...)
+		}
+	}
+	if text := strings.Join(words, " "); text != "" {
+		s.onTranscript(text, true)
+	}
+}
+
+func commonPrefix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && normalizeWord(a[n]) == normalizeWord(b[n]) {
+		n++
+	}
+	return n
+}
+
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.Trim(w, ".,!?¡¿;:\"'"))
+}
@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// growingSTT transcribes one word per 200 bytes of audio and appends an
+// unstable trailing guess that changes on every call.
+type growingSTT struct {
+	mu     sync.Mutex
+	calls  int
+	steady bool // Omit the unstable guess
+}
+
+var growingWords = strings.Fields("please book me a table for two at eight tonight")
+
+func (g *growingSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	g.mu.Lock()
+	g.calls++
+	n := g.calls
+	g.mu.Unlock()
+	k := min(len(audio)/200, len(growingWords))
+	words := append([]string(nil), growingWords[:k]...)
+	if k < len(growingWords) && !g.steady {
+		words = append(words, fmt.Sprintf("uh%d", n))
+	}
+	return TranscriptionResult{Text: strings.Join(words, " ")}, nil
+}
+
+func (g *growingSTT) Name() string { return "growing" }
+
+func TestChunkedStreamingSTT_StableInterims(t *testing.T) {
+	stt := NewChunkedStreamingSTT(&growingSTT{}, ChunkedSTTConfig{
+		SampleRate: 1000,
+		Step:       10 * time.Millisecond,
+		MinAudio:   50 * time.Millisecond,
+	})
+
+	var mu sync.Mutex
+	var interims []string
+	final := make(chan string, 1)
+	in, err := stt.StreamTranscribe(context.Background(), LanguageEn, func(text string, isFinal bool) error {
+		if isFinal {
+			final <- text
+			return nil
+		}
+		mu.Lock()
+		interims = append(interims, text)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < len(growingWords); i++ {
+		in <- make([]byte, 200)
+		time.Sleep(40 * time.Millisecond)
+	}
+	close(in)
+
+	select {
+	case got := <-final:
+		if got != strings.Join(growingWords, " ") {
+			t.Errorf("unexpected final transcript %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no final transcript")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(interims) < 3 {
+		t.Fatalf("expected interim transcripts while audio was streaming, got %v", interims)
+	}
+	prev := ""
+	for _, text := range interims {
+		if strings.Contains(text, "uh") {
+			t.Errorf("unstable guess leaked into interim %q", text)
+		}
+		if !strings.HasPrefix(text, prev) {
+			t.Errorf("interim %q retracted earlier text %q", text, prev)
+		}
+		prev = text
+	}
+}
+
+func TestChunkedStreamingSTT_MaxWindowCommits(t *testing.T) {
+	stt := NewChunkedStreamingSTT(&growingSTT{steady: true}, ChunkedSTTConfig{
+		SampleRate: 1000,
+		Step:       10 * time.Millisecond,
+		MinAudio:   50 * time.Millisecond,
+		MaxWindow:  200 * time.Millisecond, // 400 bytes = two words per window
+	})
+
+	final := make(chan string, 1)
+	in, _ := stt.StreamTranscribe(context.Background(), LanguageEn, func(text string, isFinal bool) error {
+		if isFinal {
+			final <- text
+		}
+		return nil
+	})
+	for i := 0; i < 3; i++ {
+		in <- make([]byte, 400)
+		time.Sleep(100 * time.Millisecond)
+	}
+	close(in)
+
+	select {
+	case got := <-final:
+		if got != "please book please book please book" {
+			t.Errorf("expected each window to be committed separately, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no final transcript")
+	}
+}
+
+func TestChunkedStream_StableWordsNeverRetract(t *testing.T) {
+	var final string
+	s := &chunkedStream{
+		cfg:          NewChunkedStreamingSTT(&MockSTTProvider{transcribeResult: "cook a table for two"}, ChunkedSTTConfig{MinStableRuns: 2}),
+		onTranscript: func(text string, isFinal bool) error { final = text; return nil },
+	}
+	s.observe(strings.Fields("book a table"))
+	s.observe(strings.Fields("book a table"))
+	if got := s.interim(); got != "book a table" {
+		t.Fatalf("interim = %q", got)
+	}
+	s.observe(strings.Fields("cook a table for"))
+	if got := s.interim(); got != "book a table" {
+		t.Errorf("a later hypothesis retracted stable words: %q", got)
+	}
+	s.finish(context.Background(), []byte{1})
+	if final != "book a table for two" {
+		t.Errorf("the final pass should only change the words after the stable ones, got %q", final)
+	}
+}
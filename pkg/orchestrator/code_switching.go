@@ -0,0 +1,176 @@
+package orchestrator
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// LanguageSpan is a run of reply text in a single language.
+type LanguageSpan struct {
+	Text     string
+	Language Language
+}
+
+// SpanDetector splits reply text, written mainly in lang, into runs by
+// language. Plug in a text language-ID model to catch foreign phrases the
+// LLM does not mark up.
+type SpanDetector interface {
+	Spans(text string, lang Language) []LanguageSpan
+}
+
+// CodeSwitching speaks the foreign names and quotes in a reply in their own
+// language, so "the café is called Le Petit Prince" does not come out in an
+// English accent. Each run the Detector finds is synthesized with its own
+// language and, when Voices has one, its own voice; with SSML set the reply
+// is instead sent in one piece with SSML lang elements, for providers that
+// switch language mid-utterance themselves.
+type CodeSwitching struct {
+	Detector SpanDetector       // nil uses MarkupSpans
+	Voices   map[Language]Voice // Voice for each foreign language; missing keeps the current voice
+	SSML     bool               // Send <lang xml:lang="…"> markup instead of one request per run
+}
+
+// MarkupSpans finds foreign runs the LLM marked up as
+// <lang xml:lang="fr">…</lang>, which it can be asked to do in the system
+// prompt, and runs written in another script: Latin words in a Japanese or
+// Chinese reply are read as English, and Han or kana in any other reply as
+// Chinese or Japanese.
+type MarkupSpans struct{}
+
+var langTagRe = regexp.MustCompile(`<lang\s+(?:xml:)?lang\s*=\s*["']([A-Za-z]{2,3})(?:[-_][A-Za-z0-9]+)*["']\s*>|</lang\s*>`)
+
+func (MarkupSpans) Spans(text string, lang Language) []LanguageSpan {
+	var spans []LanguageSpan
+	current := lang
+	rest := text
+	for {
+		loc := langTagRe.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			break
+		}
+		spans = append(spans, scriptSpans(rest[:loc[0]], current)...)
+		if loc[2] >= 0 {
+			current = Language(strings.ToLower(rest[loc[2]:loc[3]]))
+		} else {
+			current = lang
+		}
+		rest = rest[loc[1]:]
+	}
+	return mergeSpans(append(spans, scriptSpans(rest, current)...))
+}
+
+// scriptSpans cuts text in lang wherever a word is written in a script lang
+// does not use. Spaces and punctuation stay with the run they follow.
+func scriptSpans(text string, lang Language) []LanguageSpan {
+	var spans []LanguageSpan
+	cjkReply := lang == LanguageJa || lang == LanguageZh
+	runes := []rune(text)
+	start, runLang := 0, lang
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !unicode.IsLetter(r) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && unicode.IsLetter(runes[j]) && isCJK(runes[j]) == isCJK(r) {
+			j++
+		}
+		wordLang := lang
+		switch {
+		case isCJK(r) && !cjkReply:
+			wordLang = LanguageZh
+			for _, c := range runes[i:j] {
+				if unicode.In(c, unicode.Hiragana, unicode.Katakana) {
+					wordLang = LanguageJa
+					break
+				}
+			}
+		case !isCJK(r) && cjkReply && unicode.In(r, unicode.Latin) && j-i > 1:
+			wordLang = LanguageEn
+		}
+		if wordLang != runLang {
+			if i > start {
+				spans = append(spans, LanguageSpan{Text: string(runes[start:i]), Language: runLang})
+			}
+			start, runLang = i, wordLang
+		}
+		i = j
+	}
+	if start < len(runes) {
+		spans = append(spans, LanguageSpan{Text: string(runes[start:]), Language: runLang})
+	}
+	return spans
+}
+
+// mergeSpans joins neighbouring runs in the same language and drops runs
+// with nothing to say, attaching their spacing to the run before.
+func mergeSpans(spans []LanguageSpan) []LanguageSpan {
+	var out []LanguageSpan
+	for _, s := range spans {
+		n := len(out)
+		switch {
+		case n > 0 && (out[n-1].Language == s.Language || !hasLetterOrDigit(s.Text)):
+			out[n-1].Text += s.Text
+		case n > 0 && !hasLetterOrDigit(out[n-1].Text):
+			out[n-1] = LanguageSpan{Text: out[n-1].Text + s.Text, Language: s.Language}
+		default:
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func hasLetterOrDigit(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0
+}
+
+// speechPart is one TTS request of a reply.
+type speechPart struct {
+	text  string
+	voice Voice
+	lang  Language
+}
+
+// speechParts prepares text for TTS: cleaned of markup, normalized and, under
+// Config.CodeSwitching, split by language or marked up for it.
+func (o *Orchestrator) speechParts(text string, voice Voice, lang Language) []speechPart {
+	cs := o.GetConfig().CodeSwitching
+	if cs == nil {
+		return []speechPart{{o.prepareSpeech(text, lang, false), voice, lang}}
+	}
+	var detector SpanDetector = MarkupSpans{}
+	if cs.Detector != nil {
+		detector = cs.Detector
+	}
+	spans := detector.Spans(text, lang)
+	if cs.SSML {
+		var b strings.Builder
+		b.WriteString("<speak>")
+		for _, s := range spans {
+			said := o.prepareSpeech(s.Text, s.Language, true)
+			if s.Language == lang {
+				b.WriteString(said)
+				continue
+			}
+			b.WriteString(`<lang xml:lang="` + string(s.Language) + `">` + said + "</lang>")
+		}
+		b.WriteString("</speak>")
+		return []speechPart{{b.String(), voice, lang}}
+	}
+	parts := make([]speechPart, 0, len(spans))
+	for _, s := range spans {
+		v := voice
+		if s.Language != lang && cs.Voices[s.Language] != "" {
+			v = cs.Voices[s.Language]
+		}
+		parts = append(parts, speechPart{strings.TrimSpace(o.prepareSpeech(s.Text, s.Language, false)), v, s.Language})
+	}
+	if len(parts) == 0 {
+		parts = append(parts, speechPart{"", voice, lang})
+	}
+	return parts
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMarkupSpans(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		lang Language
+		want []LanguageSpan
+	}{
+		{
+			name: "plain",
+			text: "Your table is booked.",
+			lang: LanguageEn,
+			want: []LanguageSpan{{"Your table is booked.", LanguageEn}},
+		},
+		{
+			name: "marked up quote",
+			text: `The café is called <lang xml:lang="fr">Le Petit Prince</lang>, on Main Street.`,
+			lang: LanguageEn,
+			want: []LanguageSpan{{"The café is called ", LanguageEn}, {"Le Petit Prince", LanguageFr}, {", on Main Street.", LanguageEn}},
+		},
+		{
+			name: "region subtag and unclosed tag",
+			text: `Say <lang lang='es-MX'>buenos días`,
+			lang: LanguageEn,
+			want: []LanguageSpan{{"Say ", LanguageEn}, {"buenos días", LanguageEs}},
+		},
+		{
+			name: "kana in an English reply",
+			text: "It is called すし and it is good.",
+			lang: LanguageEn,
+			want: []LanguageSpan{{"It is called ", LanguageEn}, {"すし ", LanguageJa}, {"and it is good.", LanguageEn}},
+		},
+		{
+			name: "Latin words in a Japanese reply",
+			text: "予約はGoogle Mapsで確認できます。",
+			lang: LanguageJa,
+			want: []LanguageSpan{{"予約は", LanguageJa}, {"Google Maps", LanguageEn}, {"で確認できます。", LanguageJa}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (MarkupSpans{}).Spans(tc.text, tc.lang); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// spanTTS records each request it gets.
+type spanTTS struct {
+	MockTTSProvider
+	parts []speechPart
+}
+
+func (t *spanTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	t.parts = append(t.parts, speechPart{text, voice, lang})
+	return []byte{1, 0}, nil
+}
+
+func (t *spanTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	t.parts = append(t.parts, speechPart{text, voice, lang})
+	return onChunk([]byte{1, 0})
+}
+
+func TestSynthesize_SwitchesLanguagePerSpan(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CodeSwitching = &CodeSwitching{Voices: map[Language]Voice{LanguageFr: "F_FR"}}
+	cfg.Normalizer = NewLocaleNormalizer()
+	tts := &spanTTS{}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, tts, nil, cfg)
+
+	audio, err := o.Synthesize(context.Background(), `Try <lang xml:lang="fr">Chez Marie, 2.5 km</lang> away.`, VoiceF1, LanguageEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []speechPart{
+		{"Try", VoiceF1, LanguageEn},
+		{"Chez Marie, 2,5 kilomètres", "F_FR", LanguageFr},
+		{"away.", VoiceF1, LanguageEn},
+	}
+	if !reflect.DeepEqual(tts.parts, want) {
+		t.Errorf("TTS got %q, want %q", tts.parts, want)
+	}
+	if len(audio) != 6 {
+		t.Errorf("audio of all spans should be joined, got %d bytes", len(audio))
+	}
+
+	tts.parts = nil
+	var chunks int
+	err = o.SynthesizeStream(context.Background(), "Order the すし.", VoiceF1, LanguageEn, func([]byte) error {
+		chunks++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tts.parts) != 2 || tts.parts[1].lang != LanguageJa || tts.parts[1].voice != VoiceF1 || chunks != 2 {
+		t.Errorf("streamed spans: %q, %d chunks", tts.parts, chunks)
+	}
+}
+
+func TestSynthesize_CodeSwitchingSSML(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CodeSwitching = &CodeSwitching{SSML: true}
+	tts := &spanTTS{}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, tts, nil, cfg)
+
+	if _, err := o.Synthesize(context.Background(), `Fish & chips or <lang xml:lang="it">pasta e fagioli</lang>?`, VoiceF1, LanguageEn); err != nil {
+		t.Fatal(err)
+	}
+	want := `<speak>Fish &amp; chips or <lang xml:lang="it">pasta e fagioli?</lang></speak>`
+	if len(tts.parts) != 1 || tts.parts[0].text != want {
+		t.Errorf("TTS got %q, want %q", tts.parts, want)
+	}
+}
@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+// ComfortNoise fills the silence while the bot is thinking, and between
+// sentences the LLM has not finished yet, with faint line noise. On a phone
+// call dead silence sounds like a dropped line; a web client with its own
+// "thinking" indicator doesn't need it.
+type ComfortNoise struct {
+	Enabled   bool
+	LevelDBFS float64 // RMS level of the noise; defaults to -60
+}
+
+func (c ComfortNoise) withDefaults() ComfortNoise {
+	if c.LevelDBFS == 0 {
+		c.LevelDBFS = -60
+	}
+	return c
+}
+
+// comfortNoiseFrame is how much noise is sent at a time.
+const comfortNoiseFrame = 20 * time.Millisecond
+
+// SetComfortNoise turns comfort noise on or off for this stream, overriding
+// Config.ComfortNoise.Enabled; e.g. on for the phone legs of a deployment
+// that also serves browsers.
+func (ms *ManagedStream) SetComfortNoise(enabled bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.comfortNoise = enabled
+}
+
+// startComfortNoise fills gaps in the response of generation gen until ctx
+// ends.
+func (ms *ManagedStream) startComfortNoise(ctx context.Context, gen int) {
+	ms.mu.Lock()
+	enabled := ms.comfortNoise
+	ms.mu.Unlock()
+	if !enabled || ms.orch == nil {
+		return
+	}
+	go ms.fillSilence(ctx, gen, audio.NewNoiseSource(ms.orch.GetConfig().ComfortNoise.withDefaults().LevelDBFS))
+}
+
+func (ms *ManagedStream) fillSilence(ctx context.Context, gen int, noise *audio.NoiseSource) {
+	tick := time.NewTicker(comfortNoiseFrame)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+
+		ms.mu.Lock()
+		now := time.Now()
+		if ms.isClosed || !ms.comfortNoise || gen != ms.payloadGen || ms.userInterrupting || now.Before(ms.playbackEnd) {
+			ms.mu.Unlock()
+			continue
+		}
+		ms.sendAudioLocked(noise.Read(ms.playbackRate*int(comfortNoiseFrame/time.Millisecond)/1000), gen)
+		ms.mu.Unlock()
+	}
+}
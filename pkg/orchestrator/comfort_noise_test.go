@@ -0,0 +1,71 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func audioChunksWithin(ms *ManagedStream, d time.Duration) [][]byte {
+	var chunks [][]byte
+	timeout := time.After(d)
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == AudioChunk {
+				chunks = append(chunks, ev.Data.([]byte))
+			}
+		case <-timeout:
+			return chunks
+		}
+	}
+}
+
+func TestManagedStream_ComfortNoiseWhileThinking(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.ComfortNoise.Enabled = true
+	llm := &blockingLLM{started: make(chan struct{})}
+	o := NewWithVAD(&MockSTTProvider{}, llm, &MockTTSProvider{}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("u"))
+	defer ms.Close()
+	ms.SetEchoSampleRates(8000, 8000)
+
+	go ms.runLLMAndTTS(ms.ctx, "what are your hours")
+	<-llm.started
+	chunks := audioChunksWithin(ms, 200*time.Millisecond)
+	if len(chunks) < 3 {
+		t.Fatalf("expected noise while the LLM is thinking, got %d chunks", len(chunks))
+	}
+	for _, c := range chunks {
+		if len(c) != 320 {
+			t.Fatalf("expected 20ms frames at 8kHz, got %d bytes", len(c))
+		}
+		if rms := chunkRMS(c); rms > 0.01 {
+			t.Fatalf("comfort noise too loud: RMS %.4f", rms)
+		}
+	}
+
+	ms.Interrupt()
+	audioChunksWithin(ms, 50*time.Millisecond)
+	if n := len(audioChunksWithin(ms, 100*time.Millisecond)); n != 0 {
+		t.Errorf("noise must stop with the response, got %d chunks", n)
+	}
+}
+
+func TestManagedStream_ComfortNoiseOff(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.ComfortNoise.Enabled = true
+	llm := &blockingLLM{started: make(chan struct{})}
+	o := NewWithVAD(&MockSTTProvider{}, llm, &MockTTSProvider{}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("u"))
+	defer ms.Close()
+	ms.SetComfortNoise(false)
+
+	go ms.runLLMAndTTS(ms.ctx, "what are your hours")
+	<-llm.started
+	if n := len(audioChunksWithin(ms, 100*time.Millisecond)); n != 0 {
+		t.Errorf("expected silence with comfort noise off, got %d chunks", n)
+	}
+}
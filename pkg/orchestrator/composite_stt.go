@@ -0,0 +1,155 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// STTSelection is how CompositeSTT picks the transcript to return.
+type STTSelection int
+
+const (
+	// STTFastest returns the first transcript with text in it and cancels
+	// the other calls.
+	STTFastest STTSelection = iota
+	// STTMostConfident waits for every provider, up to its timeout, and
+	// returns the transcript with the lowest NoSpeechProb.
+	STTMostConfident
+)
+
+// STTCandidate is one provider's part in a CompositeSTT call.
+type STTCandidate struct {
+	Provider string
+	Result   TranscriptionResult
+	Latency  time.Duration
+	Err      error // Includes timeouts, and cancellation for calls that lost
+	Chosen   bool
+}
+
+// CompositeSTT sends each utterance to several STT providers at once: to
+// get the fastest vendor's latency, the best vendor's transcript, or to
+// keep a turn going when one vendor is down. It transcribes in batch only;
+// a managed stream using it does not stream STT.
+type CompositeSTT struct {
+	providers []STTProvider
+	selection STTSelection
+
+	// Timeout bounds each provider's call; 0 leaves them to ctx. Timeouts
+	// overrides it by provider name.
+	Timeout  time.Duration
+	Timeouts map[string]time.Duration
+	// OnResults, when set, is given every provider's outcome once all have
+	// finished, for comparing vendors in production. With STTFastest the
+	// other calls then run on after the winner returns, up to their
+	// timeouts, instead of being cancelled.
+	OnResults func(results []STTCandidate)
+}
+
+// NewCompositeSTT returns a CompositeSTT over providers, normally two.
+func NewCompositeSTT(selection STTSelection, providers ...STTProvider) *CompositeSTT {
+	return &CompositeSTT{providers: providers, selection: selection}
+}
+
+func (c *CompositeSTT) Name() string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.Name()
+	}
+	return "composite(" + strings.Join(names, ",") + ")"
+}
+
+func (c *CompositeSTT) timeout(p STTProvider) time.Duration {
+	if d, ok := c.Timeouts[p.Name()]; ok {
+		return d
+	}
+	return c.Timeout
+}
+
+type sttResult struct {
+	i int
+	STTCandidate
+}
+
+// Transcribe picks a transcript by the selection. Transcripts with no text
+// lose to any with some; if every call fails, so does Transcribe.
+func (c *CompositeSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	callCtx := ctx
+	if c.OnResults != nil && c.selection == STTFastest {
+		callCtx = context.WithoutCancel(ctx)
+	}
+	callCtx, cancel := context.WithCancel(callCtx)
+	results := make(chan sttResult, len(c.providers))
+	start := time.Now()
+	for i, p := range c.providers {
+		go func() {
+			pctx := callCtx
+			if d := c.timeout(p); d > 0 {
+				var cancel context.CancelFunc
+				pctx, cancel = context.WithTimeout(pctx, d)
+				defer cancel()
+			}
+			res, err := p.Transcribe(pctx, audio, lang)
+			results <- sttResult{i, STTCandidate{Provider: p.Name(), Result: res, Latency: time.Since(start), Err: err}}
+		}()
+	}
+
+	all := make([]STTCandidate, len(c.providers))
+	chosen := -1
+	var fallback, best *sttResult
+	var errs []error
+	n := 0
+	for ; n < len(c.providers); n++ {
+		res := <-results
+		all[res.i] = res.STTCandidate
+		switch {
+		case res.Err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", res.Provider, res.Err))
+		case strings.TrimSpace(res.Result.Text) == "":
+			if fallback == nil {
+				fallback = &res
+			}
+		case c.selection == STTFastest:
+			best = &res
+		case best == nil || res.Result.NoSpeechProb < best.Result.NoSpeechProb:
+			best = &res
+		}
+		if best != nil && c.selection == STTFastest {
+			n++
+			break
+		}
+	}
+	if best == nil {
+		best = fallback
+	}
+	if best != nil {
+		chosen = best.i
+	}
+
+	report := func() {
+		for ; n < len(c.providers); n++ {
+			res := <-results
+			all[res.i] = res.STTCandidate
+		}
+		cancel()
+		if chosen >= 0 {
+			all[chosen].Chosen = true
+		}
+		c.OnResults(all)
+	}
+	switch {
+	case c.OnResults == nil:
+		cancel()
+	case n < len(c.providers):
+		go report()
+	default:
+		report()
+	}
+
+	if best == nil {
+		return TranscriptionResult{}, errors.Join(errs...)
+	}
+	return best.Result, nil
+}
@@ -0,0 +1,76 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// delayedSTT transcribes after delay, or fails when ctx ends first.
+type delayedSTT struct {
+	name  string
+	delay time.Duration
+	res   TranscriptionResult
+	err   error
+}
+
+func (d *delayedSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	select {
+	case <-time.After(d.delay):
+		return d.res, d.err
+	case <-ctx.Done():
+		return TranscriptionResult{}, ctx.Err()
+	}
+}
+
+func (d *delayedSTT) Name() string { return d.name }
+
+func TestCompositeSTT_Fastest(t *testing.T) {
+	fast := &delayedSTT{name: "fast", delay: 5 * time.Millisecond, res: TranscriptionResult{Text: "hello", NoSpeechProb: 0.4}}
+	slow := &delayedSTT{name: "slow", delay: 40 * time.Millisecond, res: TranscriptionResult{Text: "hello there", NoSpeechProb: 0.1}}
+	empty := &delayedSTT{name: "empty", delay: time.Millisecond}
+	c := NewCompositeSTT(STTFastest, empty, slow, fast)
+
+	start := time.Now()
+	res, err := c.Transcribe(context.Background(), nil, LanguageEn)
+	if err != nil || res.Text != "hello" {
+		t.Fatalf("got %q, %v", res.Text, err)
+	}
+	if time.Since(start) > 30*time.Millisecond {
+		t.Errorf("waited %v for the slow provider", time.Since(start))
+	}
+	if c.Name() != "composite(empty,slow,fast)" {
+		t.Errorf("name = %s", c.Name())
+	}
+
+	// With OnResults the slower call finishes for comparison.
+	reported := make(chan []STTCandidate, 1)
+	c.OnResults = func(r []STTCandidate) { reported <- r }
+	if res, _ := c.Transcribe(context.Background(), nil, LanguageEn); res.Text != "hello" {
+		t.Fatalf("got %q", res.Text)
+	}
+	r := <-reported
+	if r[1].Result.Text != "hello there" || r[1].Chosen || !r[2].Chosen || r[1].Latency < r[2].Latency {
+		t.Errorf("results = %+v", r)
+	}
+}
+
+func TestCompositeSTT_MostConfident(t *testing.T) {
+	a := &delayedSTT{name: "a", delay: 5 * time.Millisecond, res: TranscriptionResult{Text: "wreck a nice beach", NoSpeechProb: 0.3}}
+	b := &delayedSTT{name: "b", delay: 15 * time.Millisecond, res: TranscriptionResult{Text: "recognize speech", NoSpeechProb: 0.05}}
+	hung := &delayedSTT{name: "hung", delay: time.Hour}
+	c := NewCompositeSTT(STTMostConfident, a, b, hung)
+	c.Timeouts = map[string]time.Duration{"hung": 30 * time.Millisecond}
+
+	res, err := c.Transcribe(context.Background(), nil, LanguageEn)
+	if err != nil || res.Text != "recognize speech" {
+		t.Fatalf("got %q, %v", res.Text, err)
+	}
+
+	down := errors.New("down")
+	c = NewCompositeSTT(STTMostConfident, &delayedSTT{name: "x", err: down}, &delayedSTT{name: "y", err: down})
+	if _, err := c.Transcribe(context.Background(), nil, LanguageEn); !errors.Is(err, down) {
+		t.Errorf("every provider failing: %v", err)
+	}
+}
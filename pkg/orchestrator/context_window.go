@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ContextWindowProvider is implemented by LLM providers that know how many
+// tokens their model accepts, prompt and reply together.
+type ContextWindowProvider interface {
+	ContextWindow() int
+}
+
+// defaultReplyReserve is the room kept for the reply when the call does not
+// set a token limit of its own.
+const defaultReplyReserve = 1024
+
+// ContextOverflowError is returned instead of calling the LLM when the
+// prompt cannot be cut down to fit the model's context window: the
+// system prompt and the latest message alone are too long.
+type ContextOverflowError struct {
+	Provider string
+	Tokens   int // Estimated prompt tokens after trimming
+	Limit    int // Prompt tokens the window leaves once the reply is reserved
+}
+
+func (e *ContextOverflowError) Error() string {
+	return fmt.Sprintf("%s: prompt of about %d tokens exceeds the %d the context window allows", e.Provider, e.Tokens, e.Limit)
+}
+
+func (e *ContextOverflowError) Unwrap() error {
+	return ErrContextOverflow
+}
+
+// promptLimit returns how many prompt tokens provider can take, keeping
+// room for the reply and a tenth of the window as margin for the estimate,
+// or 0 if the window is unknown.
+func (o *Orchestrator) promptLimit(ctx context.Context, provider LLMProvider) int {
+	window := o.GetConfig().ContextWindow
+	if window <= 0 {
+		if p, ok := provider.(ContextWindowProvider); ok {
+			window = p.ContextWindow()
+		}
+	}
+	if window <= 0 {
+		return 0
+	}
+	reserve := MaxTokensFromContext(ctx)
+	if reserve <= 0 {
+		reserve = defaultReplyReserve
+	}
+	return max(window-window/10-reserve, 0)
+}
+
+// fitContext makes sure messages fit provider's context window, trimming
+// them with the session's TrimStrategy until they do. When the messages are
+// the session's context, the session keeps the trimmed context too, so the
+// next turn does not start over the limit.
+func (o *Orchestrator) fitContext(ctx context.Context, session *ConversationSession, provider LLMProvider, messages []Message, tools []Tool) ([]Message, error) {
+	limit := o.promptLimit(ctx, provider)
+	if limit <= 0 {
+		return messages, nil
+	}
+	fixed := toolTokens(tools)
+	tokens := estimatePrompt(messages) + fixed
+	if tokens <= limit {
+		return messages, nil
+	}
+
+	strategy := TrimStrategy(DropOldestTrim{})
+	if session != nil {
+		strategy = session.trimStrategy()
+	}
+	trim := strategy.Trim
+	if slow, ok := strategy.(ContextTrimStrategy); ok {
+		trim = func(messages []Message, max int) []Message { return slow.TrimContext(ctx, messages, max) }
+	}
+	fitted := messages
+	for tokens > limit && len(fitted) > 1 {
+		next := trim(fitted, len(fitted)-1)
+		if len(next) >= len(fitted) {
+			break
+		}
+		fitted = next
+		tokens = estimatePrompt(fitted) + fixed
+	}
+	if tokens > limit {
+		return nil, &ContextOverflowError{Provider: provider.Name(), Tokens: tokens, Limit: limit}
+	}
+
+	o.logger.Info("context trimmed to fit the LLM's window", "provider", provider.Name(), "messages", len(fitted), "dropped", len(messages)-len(fitted), "tokens", tokens, "limit", limit)
+	if session != nil {
+		session.replaceContext(messages, fitted)
+	}
+	return fitted, nil
+}
+
+// replaceContext swaps the context for its trimmed form, if it is still
+// the context that was trimmed, and reports whether it did.
+func (s *ConversationSession) replaceContext(was, trimmed []Message) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.Context) != len(was) || len(was) == 0 || s.Context[len(was)-1].Text() != was[len(was)-1].Text() {
+		return false
+	}
+	s.Context = append([]Message(nil), trimmed...)
+	return true
+}
+
+// estimatePrompt approximates the tokens messages take in a prompt.
+func estimatePrompt(messages []Message) int {
+	n := 0
+	for _, m := range messages {
+		n += EstimateTokens(m.Text()) + 4 // Role and framing overhead
+	}
+	return n
+}
+
+func toolTokens(tools []Tool) int {
+	if len(tools) == 0 {
+		return 0
+	}
+	b, _ := json.Marshal(tools)
+	return EstimateTokens(string(b))
+}
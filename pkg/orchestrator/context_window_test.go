@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// windowLLM is a promptLLM whose model takes window tokens.
+type windowLLM struct {
+	promptLLM
+	window int
+}
+
+func (l *windowLLM) ContextWindow() int { return l.window }
+
+func TestFitContext_TrimsToTheWindow(t *testing.T) {
+	llm := &windowLLM{promptLLM: promptLLM{reply: "ok"}, window: 3000}
+	o := NewWithVAD(&MockSTTProvider{}, llm, &MockTTSProvider{}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+	session.TrimStrategy = DropMiddleTrim{Head: 1}
+	session.AddMessage(RoleSystem, "You are a travel agent.")
+	for i := 0; i < 20; i++ {
+		session.AddMessage(RoleUser, strings.Repeat("a", 400))
+		session.AddMessage(RoleAssistant, strings.Repeat("b", 400))
+	}
+	session.AddMessage(RoleUser, "So which flight?")
+
+	if _, err := o.GenerateResponse(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+	sent := llm.last
+	if limit := o.promptLimit(context.Background(), llm); estimatePrompt(sent) > limit {
+		t.Errorf("sent about %d tokens, over the %d limit", estimatePrompt(sent), limit)
+	}
+	if sent[0].Role != RoleSystem || llm.lastMessage().Text() != "So which flight?" {
+		t.Errorf("lost the system prompt or the question: %v ... %v", sent[0], llm.lastMessage())
+	}
+	if n := len(session.GetContextCopy()); n != len(sent) {
+		t.Errorf("session kept %d messages, want the %d sent", n, len(sent))
+	}
+}
+
+func TestFitContext_OverflowIsTyped(t *testing.T) {
+	llm := &windowLLM{promptLLM: promptLLM{reply: "ok"}, window: 3000}
+	o := NewWithVAD(&MockSTTProvider{}, llm, &MockTTSProvider{}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+	session.AddMessage(RoleUser, strings.Repeat("word ", 2000))
+
+	_, err := o.GenerateResponse(context.Background(), session)
+	var overflow *ContextOverflowError
+	if !errors.As(err, &overflow) || !errors.Is(err, ErrContextOverflow) {
+		t.Fatalf("got %v", err)
+	}
+	if overflow.Provider != "prompt" || overflow.Tokens <= overflow.Limit {
+		t.Errorf("unexpected error %+v", overflow)
+	}
+	if llm.last != nil {
+		t.Error("the LLM was called with a prompt that does not fit")
+	}
+}
+
+func TestFitContext_ConfigOverridesProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ContextWindow = 100000
+	llm := &windowLLM{promptLLM: promptLLM{reply: "ok"}, window: 3000}
+	o := NewWithVAD(&MockSTTProvider{}, llm, &MockTTSProvider{}, nil, cfg)
+	session := NewConversationSession("u")
+	session.AddMessage(RoleUser, strings.Repeat("word ", 2000))
+	if _, err := o.GenerateResponse(context.Background(), session); err != nil {
+		t.Errorf("a prompt within the configured window failed: %v", err)
+	}
+
+	// Without a window from either, prompts go out unchecked.
+	o = NewWithVAD(&MockSTTProvider{}, &promptLLM{reply: "ok"}, &MockTTSProvider{}, nil, DefaultConfig())
+	if _, err := o.GenerateResponse(context.Background(), session); err != nil {
+		t.Error(err)
+	}
+}
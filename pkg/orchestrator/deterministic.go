@@ -0,0 +1,177 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"sync"
+)
+
+// Determinism makes conversations reproducible for evaluation runs and
+// regression tests. Every stage call is given Seed (see SeedFromContext),
+// which LLM providers that support seeds pass on along with a temperature
+// of zero, and the input and output of every call is hashed into a
+// StageDigest, logged, and passed to Record. Two runs agree exactly when
+// their digests do; a DigestLog collects them for comparison.
+type Determinism struct {
+	Seed   int64
+	Record func(StageDigest) // May be nil to only log
+}
+
+// StageDigest fingerprints one stage call.
+type StageDigest struct {
+	TurnID string `json:"turn_id,omitempty"`
+	Stage  Stage  `json:"stage"`
+	Seed   int64  `json:"seed"`
+	Input  string `json:"input"`  // SHA-256 of the request
+	Output string `json:"output"` // SHA-256 of the response, or of the error
+	Failed bool   `json:"failed,omitempty"`
+}
+
+type seedCtx struct{}
+
+// WithSeed asks LLM providers to sample deterministically with seed.
+func WithSeed(ctx context.Context, seed int64) context.Context {
+	return context.WithValue(ctx, seedCtx{}, seed)
+}
+
+// SeedFromContext returns the seed attached to ctx, if any. Providers that
+// find one should send it along with a temperature of zero.
+func SeedFromContext(ctx context.Context) (int64, bool) {
+	seed, ok := ctx.Value(seedCtx{}).(int64)
+	return seed, ok
+}
+
+// stageInput lists what identifies a stage request: the same input to the
+// same provider should give the same output. It returns nil for an unknown
+// stage.
+func stageInput(ctx context.Context, req *StageRequest) []interface{} {
+	switch req.Stage {
+	case StageSTT:
+		sum := sha256.Sum256(req.Audio)
+		return []interface{}{req.Language, hex.EncodeToString(sum[:])}
+	case StageLLM:
+		return []interface{}{req.Messages, req.Tools}
+	case StageTTS:
+		return []interface{}{req.Text, req.Voice, req.Language, SpeechRateFromContext(ctx)}
+	}
+	return nil
+}
+
+// digestStage wraps a stage call so its input and output are fingerprinted.
+func (o *Orchestrator) digestStage(d *Determinism, next StageFunc) StageFunc {
+	return func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+		ctx = WithSeed(ctx, d.Seed)
+		in, _ := json.Marshal(append([]interface{}{req.Stage}, stageInput(ctx, req)...))
+		inSum := sha256.Sum256(in)
+
+		out := sha256.New()
+		var mu sync.Mutex
+		var toolCalls []ToolCallEventData
+		var streamed hash.Hash
+		if req.OnAudio != nil {
+			streamed = sha256.New()
+			onAudio := req.OnAudio
+			req.OnAudio = func(chunk []byte) error {
+				mu.Lock()
+				streamed.Write(chunk)
+				mu.Unlock()
+				return onAudio(chunk)
+			}
+		}
+		if req.OnToolCall != nil {
+			onToolCall := req.OnToolCall
+			req.OnToolCall = func(tc ToolCallEventData) error {
+				mu.Lock()
+				toolCalls = append(toolCalls, tc)
+				mu.Unlock()
+				return onToolCall(tc)
+			}
+		}
+
+		resp, err := next(ctx, req)
+
+		mu.Lock()
+		switch {
+		case err != nil:
+			out.Write([]byte(err.Error()))
+		case resp == nil:
+		case req.Stage == StageSTT:
+			data, _ := json.Marshal(resp.Transcript)
+			out.Write(data)
+		case req.Stage == StageLLM:
+			data, _ := json.Marshal(struct {
+				Text      string
+				ToolCalls []ToolCallEventData
+			}{resp.Text, toolCalls})
+			out.Write(data)
+		case streamed != nil:
+			out = streamed
+		default:
+			out.Write(resp.Audio)
+		}
+		mu.Unlock()
+
+		digest := StageDigest{
+			TurnID: IdempotencyKeyFromContext(ctx),
+			Stage:  req.Stage,
+			Seed:   d.Seed,
+			Input:  hex.EncodeToString(inSum[:]),
+			Output: hex.EncodeToString(out.Sum(nil)),
+			Failed: err != nil,
+		}
+		o.logger.Info("stage digest", "turnID", digest.TurnID, "stage", digest.Stage, "seed", digest.Seed, "input", digest.Input, "output", digest.Output, "failed", digest.Failed)
+		if d.Record != nil {
+			d.Record(digest)
+		}
+		return resp, err
+	}
+}
+
+// DigestLog collects StageDigests in call order; use its Record method as
+// Determinism.Record.
+type DigestLog struct {
+	mu      sync.Mutex
+	digests []StageDigest
+}
+
+func (l *DigestLog) Record(d StageDigest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.digests = append(l.digests, d)
+}
+
+// Digests returns the digests recorded so far.
+func (l *DigestLog) Digests() []StageDigest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]StageDigest(nil), l.digests...)
+}
+
+// Sum fingerprints the whole run from the stage, input and output of each
+// call, so two runs can be compared with one string. Turn IDs are left out
+// as they differ between runs.
+func (l *DigestLog) Sum() string {
+	h := sha256.New()
+	for _, d := range l.Digests() {
+		h.Write([]byte(string(d.Stage) + d.Input + d.Output + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Diverged returns the index of the first call at which two runs differ, or
+// -1 if they agree.
+func (l *DigestLog) Diverged(other *DigestLog) int {
+	a, b := l.Digests(), other.Digests()
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].Stage != b[i].Stage || a[i].Input != b[i].Input || a[i].Output != b[i].Output {
+			return i
+		}
+	}
+	if len(a) != len(b) {
+		return min(len(a), len(b))
+	}
+	return -1
+}
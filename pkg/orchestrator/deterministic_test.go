@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+)
+
+// seedEchoLLM answers with whatever seed it was given.
+type seedEchoLLM struct{ seeds []int64 }
+
+func (l *seedEchoLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	seed, ok := SeedFromContext(ctx)
+	if !ok {
+		return "unseeded", nil
+	}
+	l.seeds = append(l.seeds, seed)
+	return "Seeded reply.", nil
+}
+
+func (l *seedEchoLLM) Name() string { return "seed-echo" }
+
+func TestDeterminism_SeedsAndDigestsEveryStage(t *testing.T) {
+	run := func(transcript string) (*DigestLog, *seedEchoLLM) {
+		log := &DigestLog{}
+		llm := &seedEchoLLM{}
+		cfg := DefaultConfig()
+		cfg.Determinism = &Determinism{Seed: 7, Record: log.Record}
+		o := NewWithVAD(&MockSTTProvider{transcribeResult: transcript}, llm, &rateTTS{}, nil, cfg)
+		if _, _, err := o.ProcessAudio(context.Background(), NewConversationSession("eval"), []byte{1, 2}, false, nil); err != nil {
+			t.Fatal(err)
+		}
+		return log, llm
+	}
+
+	a, llm := run("what's the weather")
+	if len(llm.seeds) != 1 || llm.seeds[0] != 7 {
+		t.Errorf("expected the LLM to get seed 7, got %v", llm.seeds)
+	}
+	digests := a.Digests()
+	if len(digests) != 3 || digests[0].Stage != StageSTT || digests[1].Stage != StageLLM || digests[2].Stage != StageTTS {
+		t.Fatalf("expected one digest per stage, got %+v", digests)
+	}
+	if digests[1].TurnID == "" || len(digests[1].Input) != 64 {
+		t.Errorf("unexpected digest: %+v", digests[1])
+	}
+
+	b, _ := run("what's the weather")
+	if a.Sum() != b.Sum() || a.Diverged(b) != -1 {
+		t.Errorf("identical runs disagree at %d", a.Diverged(b))
+	}
+	c, _ := run("what's the time")
+	if a.Sum() == c.Sum() || a.Diverged(c) != 0 {
+		t.Errorf("expected the runs to diverge at the transcript, got %d", a.Diverged(c))
+	}
+}
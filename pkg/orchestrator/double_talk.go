@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+)
+
+// DoubleTalkKind tells apart the reasons a user talks over the bot.
+type DoubleTalkKind string
+
+const (
+	// DoubleTalkEarlyAnswer is the user answering a question the bot is
+	// still asking, or has asked earlier in the reply.
+	DoubleTalkEarlyAnswer DoubleTalkKind = "early_answer"
+	// DoubleTalkMonologue is the user cutting into a reply that has gone on
+	// for at least MonologueAfter.
+	DoubleTalkMonologue DoubleTalkKind = "monologue"
+	// DoubleTalkOverlap is any other talking over the bot.
+	DoubleTalkOverlap DoubleTalkKind = "overlap"
+)
+
+// DoubleTalkDetection watches for the user and the bot speaking at the same
+// time. Each stretch of it lasting MinOverlap or more is reported as a
+// DoubleTalk event once either side stops, and its kind can pick the
+// InterruptionPolicy: a stream may finish a long explanation the user talks
+// over, yet stop at once for an early answer to its question.
+type DoubleTalkDetection struct {
+	MinOverlap     time.Duration                         // Shorter overlaps go unreported; defaults to 250ms
+	MonologueAfter time.Duration                         // Bot speech this long makes a monologue; defaults to 5s
+	Policies       map[DoubleTalkKind]InterruptionPolicy // Override Config.InterruptionPolicy by kind
+}
+
+// DoubleTalkEvent is the data of a DoubleTalk event.
+type DoubleTalkEvent struct {
+	Kind        DoubleTalkKind `json:"kind"`
+	Duration    time.Duration  `json:"duration"`      // How long both were speaking
+	BotSpokeFor time.Duration  `json:"bot_spoke_for"` // How long the bot had been speaking when the user started
+	Interrupted bool           `json:"interrupted"`   // The overlap ended with the bot cut off
+}
+
+type doubleTalkState struct {
+	since       time.Time
+	kind        DoubleTalkKind
+	botSpokeFor time.Duration
+}
+
+func (d *DoubleTalkDetection) minOverlap() time.Duration {
+	if d.MinOverlap <= 0 {
+		return 250 * time.Millisecond
+	}
+	return d.MinOverlap
+}
+
+func (d *DoubleTalkDetection) monologueAfter() time.Duration {
+	if d.MonologueAfter <= 0 {
+		return 5 * time.Second
+	}
+	return d.MonologueAfter
+}
+
+// startDoubleTalk notes user speech starting while the bot is talking.
+func (ms *ManagedStream) startDoubleTalk() {
+	if ms.orch == nil {
+		return
+	}
+	d := ms.orch.GetConfig().DoubleTalk
+	if d == nil {
+		return
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	now := time.Now()
+	if ms.doubleTalk != nil || !(ms.isSpeaking || now.Before(ms.playbackEnd)) {
+		return
+	}
+	state := &doubleTalkState{since: now, kind: DoubleTalkOverlap}
+	if !ms.botSpeakStartTime.IsZero() {
+		state.botSpokeFor = now.Sub(ms.botSpeakStartTime)
+	}
+	switch {
+	case strings.ContainsAny(ms.speechText, "?？¿"):
+		state.kind = DoubleTalkEarlyAnswer
+	case state.botSpokeFor >= d.monologueAfter():
+		state.kind = DoubleTalkMonologue
+	}
+	ms.doubleTalk = state
+}
+
+// endDoubleTalk closes the overlap under way, if any, when the user stops
+// or the bot is cut off, and reports it. A bot that finished on its own
+// ended the overlap when its audio ran out.
+func (ms *ManagedStream) endDoubleTalk(interrupted bool) {
+	ms.mu.Lock()
+	state := ms.doubleTalk
+	ms.doubleTalk = nil
+	if state == nil || ms.orch == nil {
+		ms.mu.Unlock()
+		return
+	}
+	end := time.Now()
+	if !ms.isSpeaking && !interrupted && ms.playbackEnd.Before(end) {
+		end = ms.playbackEnd
+		if end.Before(state.since) {
+			end = state.since
+		}
+	}
+	ms.mu.Unlock()
+
+	d := ms.orch.GetConfig().DoubleTalk
+	duration := end.Sub(state.since)
+	if d == nil || duration < d.minOverlap() {
+		return
+	}
+	ms.emit(DoubleTalk, DoubleTalkEvent{Kind: state.kind, Duration: duration, BotSpokeFor: state.botSpokeFor, Interrupted: interrupted})
+}
+
+// interruptionPolicyLocked is the policy for speech under way: the one
+// DoubleTalkDetection sets for its kind, or Config.InterruptionPolicy.
+func (ms *ManagedStream) interruptionPolicyLocked(cfg Config) InterruptionPolicy {
+	if cfg.DoubleTalk != nil && ms.doubleTalk != nil {
+		if p, ok := cfg.DoubleTalk.Policies[ms.doubleTalk.kind]; ok {
+			return p
+		}
+	}
+	return cfg.InterruptionPolicy
+}
@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+// newDoubleTalkStream returns a stream whose bot has been saying said for
+// spokeFor.
+func newDoubleTalkStream(t *testing.T, d *DoubleTalkDetection, said string, spokeFor time.Duration, script ...VADEventType) *ManagedStream {
+	t.Helper()
+	tts := newTalkingTTS(nil)
+	ms := newTestStream(t, testProviders{tts: tts, vad: &scriptedVAD{script: script}}, func(cfg *Config) {
+		cfg.DoubleTalk = d
+	})
+	startTalking(t, ms, tts, said)
+	time.Sleep(spokeFor)
+	return ms
+}
+
+// keepDoubleTalks has countEvents keep the data of DoubleTalk events.
+func keepDoubleTalks(talks *[]DoubleTalkEvent) func(OrchestratorEvent) {
+	return func(ev OrchestratorEvent) {
+		if dt, ok := ev.Data.(DoubleTalkEvent); ok {
+			*talks = append(*talks, dt)
+		}
+	}
+}
+
+func TestManagedStream_DoubleTalkOverAMonologue(t *testing.T) {
+	d := &DoubleTalkDetection{
+		MinOverlap:     20 * time.Millisecond,
+		MonologueAfter: 100 * time.Millisecond,
+		Policies:       map[DoubleTalkKind]InterruptionPolicy{DoubleTalkMonologue: InterruptIgnore},
+	}
+	ms := newDoubleTalkStream(t, d, "Let me walk you through the whole plan.", 120*time.Millisecond, VADSpeechStart, "", VADSpeechEnd)
+	chunk := make([]byte, 1764)
+	ms.doWrite(chunk)
+	time.Sleep(60 * time.Millisecond)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+
+	var talks []DoubleTalkEvent
+	counts := countEvents(ms, 100*time.Millisecond, keepDoubleTalks(&talks))
+	if counts[UserSpeaking] != 0 || counts[Interrupted] != 0 {
+		t.Errorf("the monologue policy should let the bot go on, got %v", counts)
+	}
+	if len(talks) != 1 {
+		t.Fatalf("expected one DoubleTalk event, got %v", counts)
+	}
+	dt := talks[0]
+	if dt.Kind != DoubleTalkMonologue || dt.Interrupted || dt.Duration < 60*time.Millisecond || dt.BotSpokeFor < 120*time.Millisecond {
+		t.Errorf("unexpected event %+v", dt)
+	}
+}
+
+func TestManagedStream_DoubleTalkEarlyAnswer(t *testing.T) {
+	d := &DoubleTalkDetection{
+		MinOverlap: time.Nanosecond,
+		Policies:   map[DoubleTalkKind]InterruptionPolicy{DoubleTalkMonologue: InterruptIgnore},
+	}
+	ms := newDoubleTalkStream(t, d, "Would you like the blue one or the red one?", 0, VADSpeechStart)
+	ms.doWrite(make([]byte, 1764))
+
+	var talks []DoubleTalkEvent
+	counts := countEvents(ms, 100*time.Millisecond, keepDoubleTalks(&talks))
+	if counts[UserSpeaking] != 1 {
+		t.Errorf("an early answer should interrupt under the default policy, got %v", counts)
+	}
+	if len(talks) != 1 || talks[0].Kind != DoubleTalkEarlyAnswer || !talks[0].Interrupted {
+		t.Errorf("expected an interrupted early answer, got %+v", talks)
+	}
+}
+
+func TestManagedStream_DoubleTalkShortOverlapUnreported(t *testing.T) {
+	ms := newDoubleTalkStream(t, &DoubleTalkDetection{}, "Sure.", 0, VADSpeechStart, VADSpeechEnd)
+	chunk := make([]byte, 1764)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+	var talks []DoubleTalkEvent
+	if countEvents(ms, 100*time.Millisecond, keepDoubleTalks(&talks)); len(talks) != 0 {
+		t.Errorf("an overlap under MinOverlap should not be reported, got %+v", talks)
+	}
+}
@@ -0,0 +1,246 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DryRun is a change to try before applying it: a new Config, a Persona, or
+// both, played against recorded turns and scripted conversations.
+type DryRun struct {
+	Config     *Config         // nil keeps the current config
+	Persona    *Persona        // Applied to each session the change is tried on
+	Recordings []TurnRecording // Replayed from their session snapshots
+	Scripts    [][]string      // Conversations of typed user lines, played as Chat turns
+	// ToolResult answers the tool calls the LLM makes. nil answers each with
+	// a placeholder, so no registered tool has effects outside the dry run.
+	ToolResult func(ctx context.Context, name, args string) (string, error)
+}
+
+// ConfigChange is one setting a DryRun changes.
+type ConfigChange struct {
+	Field    string `json:"field"` // e.g. "MaxContextMessages" or "persona.system_prompt"
+	Current  string `json:"current"`
+	Proposed string `json:"proposed"`
+}
+
+// DryRunTurn is one turn played with the current settings and with the
+// change. Its Diffs compare the two, the baseline as Original.
+type DryRunTurn struct {
+	Source    string        `json:"source"` // The recording ID, or "script 1 turn 2"
+	Baseline  TurnRecording `json:"baseline"`
+	Candidate TurnRecording `json:"candidate"`
+	Diffs     []TurnDiff    `json:"diffs"`
+}
+
+// Changed reports whether the change gave the turn a different transcript,
+// response or error.
+func (t DryRunTurn) Changed() bool {
+	return outcomeChanged(t.Diffs)
+}
+
+// DryRunReport is what DryRun found.
+type DryRunReport struct {
+	Changes []ConfigChange `json:"changes"`
+	Turns   []DryRunTurn   `json:"turns"`
+}
+
+// Changed returns the turns whose outcome the change altered.
+func (r *DryRunReport) Changed() []DryRunTurn {
+	var changed []DryRunTurn
+	for _, t := range r.Turns {
+		if t.Changed() {
+			changed = append(changed, t)
+		}
+	}
+	return changed
+}
+
+// dryRunPlaceholder is the tool result a dry run gives the LLM by default.
+const dryRunPlaceholder = `{"status":"skipped","reason":"dry run"}`
+
+// DryRun plays every recording and script twice, once as the orchestrator
+// is and once with the change, and reports how the outcomes differ. Both
+// runs use copies of the orchestrator with the same providers and
+// middleware; live sessions, the session store, the turn recorder and the
+// config in use are never touched, so an operator can review the report
+// before calling UpdateConfig or ApplyPersona.
+//
+// Recordings resume from their session snapshots, which keep the voice,
+// language and context size the session had; scripts start sessions from
+// the config under test.
+func (o *Orchestrator) DryRun(ctx context.Context, d DryRun) (*DryRunReport, error) {
+	current := o.GetConfig()
+	proposed := current
+	if d.Config != nil {
+		proposed = *d.Config
+	}
+	report := &DryRunReport{Changes: configChanges(current, proposed)}
+	if d.Persona != nil {
+		report.Changes = append(report.Changes, personaChanges(*d.Persona)...)
+	}
+
+	baseline, baseRec := o.sandbox(current, d.ToolResult)
+	candidate, candRec := o.sandbox(proposed, d.ToolResult)
+	prepare := func(s *ConversationSession) *ConversationSession {
+		if d.Persona != nil {
+			ApplyPersona(candidate, s, *d.Persona)
+		}
+		return s
+	}
+
+	for _, rec := range d.Recordings {
+		if len(rec.Audio) == 0 {
+			return nil, fmt.Errorf("recording %s has no audio", rec.ID)
+		}
+		baseline.ProcessAudio(ctx, RestoreSession(rec.Session), rec.Audio, false, nil)
+		candidate.ProcessAudio(ctx, prepare(RestoreSession(rec.Session)), rec.Audio, false, nil)
+		report.add(rec.ID, baseRec.take(), candRec.take())
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+	}
+
+	for i, script := range d.Scripts {
+		baseSession := baseline.NewSessionWithDefaults("dry-run")
+		candSession := prepare(candidate.NewSessionWithDefaults("dry-run"))
+		for j, line := range script {
+			baseline.Chat(ctx, baseSession, line)
+			candidate.Chat(ctx, candSession, line)
+			report.add(fmt.Sprintf("script %d turn %d", i+1, j+1), baseRec.take(), candRec.take())
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
+
+func (r *DryRunReport) add(source string, baseline, candidate TurnRecording) {
+	r.Turns = append(r.Turns, DryRunTurn{
+		Source:    source,
+		Baseline:  baseline,
+		Candidate: candidate,
+		Diffs:     diffTurns(baseline, candidate),
+	})
+}
+
+// sandbox returns a copy of o with config, sharing its providers and
+// middleware but none of its state, whose turns are kept by the returned
+// recorder. Tools answer with toolResult.
+func (o *Orchestrator) sandbox(config Config, toolResult func(ctx context.Context, name, args string) (string, error)) (*Orchestrator, *dryRunRecorder) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	s := New(o.stt, o.llm, o.tts, o.vad, config, o.logger)
+	for name := range o.toolHandlers {
+		s.toolHandlers[name] = func(ctx context.Context, args string) (string, error) {
+			if toolResult == nil {
+				return dryRunPlaceholder, nil
+			}
+			return toolResult(ctx, name, args)
+		}
+	}
+	s.middleware = make(map[Stage][]Middleware, len(o.middleware))
+	for stage, mw := range o.middleware {
+		s.middleware[stage] = append([]Middleware(nil), mw...)
+	}
+	s.redactor = o.redactor
+	o.priority.mu.Lock()
+	for p, llm := range o.priority.llm {
+		s.SetPriorityLLM(p, llm)
+	}
+	o.priority.mu.Unlock()
+
+	rec := &dryRunRecorder{}
+	s.recorder = rec
+	return s, rec
+}
+
+// dryRunRecorder keeps the last turn a sandbox ran, without its audio.
+type dryRunRecorder struct {
+	mu   sync.Mutex
+	last TurnRecording
+}
+
+func (r *dryRunRecorder) SaveTurn(ctx context.Context, rec TurnRecording) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec.Audio = nil
+	r.last = rec
+	return nil
+}
+
+func (r *dryRunRecorder) LoadTurn(ctx context.Context, id string) (TurnRecording, error) {
+	return TurnRecording{}, ErrRecordingNotFound
+}
+
+// take returns the last turn and forgets it.
+func (r *dryRunRecorder) take() TurnRecording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := r.last
+	r.last = TurnRecording{}
+	return rec
+}
+
+// configChanges lists the top-level Config fields that differ. Functions
+// and interfaces can only be told apart from nil, so they are reported as
+// changed when one is set and the other is not.
+func configChanges(current, proposed Config) []ConfigChange {
+	var changes []ConfigChange
+	a, b := reflect.ValueOf(current), reflect.ValueOf(proposed)
+	for i := range a.NumField() {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		x, y := a.Field(i), b.Field(i)
+		if x.Kind() == reflect.Func || x.Kind() == reflect.Interface {
+			if x.IsNil() == y.IsNil() {
+				continue
+			}
+		} else if reflect.DeepEqual(x.Interface(), y.Interface()) {
+			continue
+		}
+		changes = append(changes, ConfigChange{Field: field.Name, Current: formatSetting(x), Proposed: formatSetting(y)})
+	}
+	return changes
+}
+
+func personaChanges(p Persona) []ConfigChange {
+	var changes []ConfigChange
+	for _, f := range []struct{ name, value string }{
+		{"name", p.Name},
+		{"system_prompt", p.SystemPrompt},
+		{"voice", string(p.Voice)},
+		{"language", string(p.Language)},
+		{"allowed_tools", strings.Join(p.AllowedTools, ",")},
+		{"greeting", p.Greeting},
+		{"closing", p.Closing},
+	} {
+		if f.value != "" {
+			changes = append(changes, ConfigChange{Field: "persona." + f.name, Proposed: f.value})
+		}
+	}
+	return changes
+}
+
+// formatSetting renders a setting for a report, following pointers.
+func formatSetting(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Func:
+		if v.IsNil() {
+			return "unset"
+		}
+		return "set"
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return "unset"
+		}
+		return formatSetting(v.Elem())
+	}
+	return fmt.Sprintf("%+v", v.Interface())
+}
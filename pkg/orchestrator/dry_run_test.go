@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// toneLLM answers formally when its system prompt asks it to.
+type toneLLM struct{}
+
+func (toneLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	for _, m := range messages {
+		if m.Role == "system" && strings.Contains(m.Content, "formal") {
+			return "Good afternoon, how may I assist you?", nil
+		}
+	}
+	return "Hey, what's up?", nil
+}
+
+func (toneLLM) Name() string { return "tone" }
+
+func TestDryRun(t *testing.T) {
+	recorder := NewInMemoryTurnRecorder()
+	o := New(&MockSTTProvider{transcribeResult: "hello there"}, toneLLM{}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), nil)
+	o.SetTurnRecorder(recorder)
+	live := o.NewSessionWithDefaults("caller")
+	ctx := WithIdempotencyKey(context.Background(), "turn-1")
+	if _, _, err := o.ProcessAudio(ctx, live, []byte{9, 9}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	rec, _ := recorder.LoadTurn(context.Background(), "turn-1")
+	liveMessages := len(live.Context)
+
+	cfg := o.GetConfig()
+	cfg.MaxContextMessages = 4
+	report, err := o.DryRun(context.Background(), DryRun{
+		Config:     &cfg,
+		Persona:    &Persona{SystemPrompt: "Be formal."},
+		Recordings: []TurnRecording{rec},
+		Scripts:    [][]string{{"hi", "what time is it"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := map[string]ConfigChange{}
+	for _, c := range report.Changes {
+		fields[c.Field] = c
+	}
+	if c := fields["MaxContextMessages"]; c.Proposed != "4" || c.Current == "4" {
+		t.Errorf("changes = %+v, want MaxContextMessages", report.Changes)
+	}
+	if fields["persona.system_prompt"].Proposed != "Be formal." {
+		t.Errorf("changes = %+v, want the persona's prompt", report.Changes)
+	}
+
+	if len(report.Turns) != 3 || report.Turns[0].Source != "turn-1" || report.Turns[2].Source != "script 1 turn 2" {
+		t.Fatalf("turns = %+v", report.Turns)
+	}
+	if n := len(report.Changed()); n != 3 {
+		t.Errorf("%d turns changed, want every reply to turn formal", n)
+	}
+	turn := report.Turns[0]
+	if turn.Baseline.Response != "Hey, what's up?" || turn.Candidate.Response != "Good afternoon, how may I assist you?" || turn.Candidate.Audio != nil {
+		t.Errorf("recorded turn = %+v", turn)
+	}
+
+	// Nothing live was touched.
+	if len(live.Context) != liveMessages || o.GetConfig().MaxContextMessages == 4 {
+		t.Error("the dry run changed the live session or config")
+	}
+	if _, err := recorder.LoadTurn(context.Background(), report.Turns[1].Candidate.ID); err != ErrRecordingNotFound {
+		t.Error("dry-run turns should not reach the live recorder")
+	}
+}
+
+func TestDryRun_StubsTools(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig())
+	called := false
+	o.RegisterTool("refund", func(args string) (string, error) {
+		called = true
+		return "refunded", nil
+	})
+
+	sandbox, _ := o.sandbox(o.GetConfig(), nil)
+	result, found, err := sandbox.callTool(context.Background(), nil, ToolCallEventData{Name: "refund", Arguments: "{}"}, nil)
+	if err != nil || !found || result != dryRunPlaceholder || called {
+		t.Errorf("got %q, %v, %v (called %v); want the placeholder without calling the tool", result, found, err, called)
+	}
+}
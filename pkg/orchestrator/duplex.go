@@ -0,0 +1,134 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+)
+
+// RunDuplex runs a full voice conversation over a pair of audio channels:
+// microphone PCM read from audioIn and playback PCM written to audioOut. VAD,
+// endpointing, STT, LLM, TTS and barge-in all run inside a ManagedStream, so
+// the caller only moves audio. It returns nil when audioIn is closed and the
+// context's error when ctx ends. audioOut is never closed by RunDuplex.
+func (o *Orchestrator) RunDuplex(ctx context.Context, session *ConversationSession, audioIn <-chan []byte, audioOut chan<- []byte) error {
+	return o.RunDuplexWithEvents(ctx, session, audioIn, audioOut, nil)
+}
+
+// RunDuplexWithEvents is RunDuplex that also hands every event other than
+// audio to onEvent, e.g. to show transcripts. onEvent must not block.
+func (o *Orchestrator) RunDuplexWithEvents(ctx context.Context, session *ConversationSession, audioIn <-chan []byte, audioOut chan<- []byte, onEvent func(OrchestratorEvent)) error {
+	ms := o.NewManagedStream(ctx, session)
+	defer ms.Close()
+
+	out := newPlaybackQueue()
+	defer out.close()
+	go out.run(ctx, audioOut, func(chunk []byte) {
+		ms.RecordPlayedOutput(chunk)
+		ms.NotifyAudioPlayed()
+	})
+
+	go func() {
+		gen := 0
+		for ev := range ms.Events() {
+			switch ev.Type {
+			case AudioChunk:
+				if ev.Generation < gen {
+					continue // Left over from a response the user talked over
+				}
+				if chunk, ok := ev.Data.([]byte); ok {
+					out.push(chunk)
+				}
+				continue
+			case Interrupted, BotThinking:
+				gen = ev.Generation
+				out.flush()
+			}
+			if onEvent != nil {
+				onEvent(ev)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case chunk, ok := <-audioIn:
+			if !ok {
+				return nil
+			}
+			if err := ms.Write(chunk); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// playbackQueue decouples the event loop from a slow audioOut reader and lets
+// an interruption discard audio that has not been handed over yet.
+type playbackQueue struct {
+	mu     sync.Mutex
+	chunks [][]byte
+	wake   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newPlaybackQueue() *playbackQueue {
+	return &playbackQueue{wake: make(chan struct{}, 1), done: make(chan struct{})}
+}
+
+func (q *playbackQueue) push(chunk []byte) {
+	q.mu.Lock()
+	q.chunks = append(q.chunks, chunk)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *playbackQueue) flush() {
+	q.mu.Lock()
+	q.chunks = nil
+	q.mu.Unlock()
+}
+
+func (q *playbackQueue) close() {
+	q.once.Do(func() { close(q.done) })
+}
+
+func (q *playbackQueue) pop() ([]byte, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.chunks) == 0 {
+		return nil, false
+	}
+	chunk := q.chunks[0]
+	q.chunks = q.chunks[1:]
+	return chunk, true
+}
+
+func (q *playbackQueue) run(ctx context.Context, audioOut chan<- []byte, onSent func([]byte)) {
+	for {
+		chunk, ok := q.pop()
+		if !ok {
+			select {
+			case <-q.wake:
+				continue
+			case <-q.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case audioOut <- chunk:
+			onSent(chunk)
+		case <-q.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunDuplex_AnswersAndStopsWhenInputCloses(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	vad := &scriptedVAD{script: []VADEventType{VADSpeechStart, "", "", VADSpeechEnd}}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: "hi, how can I help?"}, &MockTTSProvider{synthesizeResult: []byte{1, 2, 3, 4}}, vad, cfg)
+	session := NewConversationSession("duplex")
+
+	in := make(chan []byte)
+	out := make(chan []byte, 16)
+	var mu sync.Mutex
+	var seen []EventType
+	done := make(chan error, 1)
+	go func() {
+		done <- o.RunDuplexWithEvents(context.Background(), session, in, out, func(ev OrchestratorEvent) {
+			mu.Lock()
+			seen = append(seen, ev.Type)
+			mu.Unlock()
+		})
+	}()
+
+	for i := 0; i < 4; i++ {
+		in <- make([]byte, 8820) // 100ms at 44.1kHz
+	}
+
+	select {
+	case chunk := <-out:
+		if len(chunk) == 0 {
+			t.Error("empty playback chunk")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no playback audio")
+	}
+
+	close(in)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected nil when input closes, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RunDuplex did not return after input closed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !hasEvent(seen, TranscriptFinal) || !hasEvent(seen, BotResponse) {
+		t.Errorf("expected transcript and response events, got %v", seen)
+	}
+	if hasEvent(seen, AudioChunk) {
+		t.Error("audio must go to audioOut, not onEvent")
+	}
+}
+
+func TestRunDuplex_ContextCancel(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, &scriptedVAD{}, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- o.RunDuplex(ctx, NewConversationSession("u"), make(chan []byte), make(chan []byte)) }()
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RunDuplex ignored cancellation")
+	}
+}
+
+func TestPlaybackQueue_FlushDropsPendingAudio(t *testing.T) {
+	q := newPlaybackQueue()
+	defer q.close()
+	q.push([]byte{1})
+	q.push([]byte{2})
+	q.flush()
+	q.push([]byte{3})
+
+	out := make(chan []byte, 4)
+	go q.run(context.Background(), out, func([]byte) {})
+	select {
+	case chunk := <-out:
+		if chunk[0] != 3 {
+			t.Errorf("expected flushed audio to be dropped, got %v", chunk)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no audio")
+	}
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// EagerSynthesis trades a little prosody for time-to-first-audio by sending
+// text to TTS before the sentence it belongs to is finished.
+//
+// With a streaming LLM, words that have waited MaxWait for a sentence end are
+// spoken as soon as at least MinChars of them are buffered. With a batch LLM,
+// the reply is synthesised in pieces - the first cut at a word boundary just
+// past MinChars, the rest sentence by sentence - so audio starts after the
+// first piece is synthesised rather than the whole reply.
+type EagerSynthesis struct {
+	Enabled  bool
+	MinChars int           // Defaults to 40
+	MaxWait  time.Duration // Defaults to 300ms
+}
+
+func (e EagerSynthesis) withDefaults() EagerSynthesis {
+	if e.MinChars <= 0 {
+		e.MinChars = 40
+	}
+	if e.MaxWait <= 0 {
+		e.MaxWait = 300 * time.Millisecond
+	}
+	return e
+}
+
+// eagerSegments splits a complete reply into the pieces it is synthesised in.
+func (o *Orchestrator) eagerSegments(ctx context.Context, text string, lang Language) []string {
+	eager := o.GetConfig().EagerSynthesis.withDefaults()
+	seg := o.newSegmenter(ctx, lang)
+	seg.eager.Enabled = false // All the text is here; there is nothing to wait for
+	segments := seg.Push(text)
+	if rest := seg.Flush(); rest != "" {
+		segments = append(segments, rest)
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+
+	first := []rune(segments[0])
+	if len(first) < 2*eager.MinChars {
+		return segments
+	}
+	cut := eager.MinChars
+	for cut < len(first) && !unicode.IsSpace(first[cut]) {
+		cut++
+	}
+	if cut >= len(first) {
+		return segments
+	}
+	head := strings.TrimSpace(string(first[:cut]))
+	tail := strings.TrimSpace(string(first[cut:]))
+	return append([]string{head, tail}, segments[1:]...)
+}
+
+// speakEagerly synthesises a batch LLM reply piece by piece, handing each
+// piece's audio to onAudioChunk before the next one is synthesised.
+func (o *Orchestrator) speakEagerly(ctx context.Context, session *ConversationSession, response string, onAudioChunk func([]byte) error, rec *TurnRecording) error {
+	voice, lang := session.GetCurrentVoice(), session.GetCurrentLanguage()
+	var sendErr error
+	start := time.Now()
+	for _, segment := range o.eagerSegments(ctx, response, lang) {
+		err := o.SynthesizeStream(ctx, segment, voice, lang, func(chunk []byte) error {
+			if err := onAudioChunk(chunk); err != nil {
+				sendErr = err
+				return err
+			}
+			return nil
+		})
+		if sendErr != nil {
+			o.logger.Error("failed to send audio chunk", "error", sendErr)
+			return sendErr
+		}
+		if err != nil {
+			rec.Timings.TTS = time.Since(start)
+			o.logger.Error("TTS synthesis failed", "sessionID", session.ID, "error", err)
+			return fmt.Errorf("%w: %v", ErrTTSFailed, err)
+		}
+	}
+	rec.Timings.TTS = time.Since(start)
+	o.logger.Info("TTS synthesis completed", "sessionID", session.ID)
+	return nil
+}
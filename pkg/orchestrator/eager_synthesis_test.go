@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSegmenter_EagerFlushAfterMaxWait(t *testing.T) {
+	s := NewSegmenter(SegmenterConfig{Language: LanguageEn})
+	s.eager = EagerSynthesis{Enabled: true, MinChars: 20, MaxWait: 10 * time.Millisecond}
+
+	if got := s.Push("Our opening hours on weekdays are"); len(got) != 0 {
+		t.Fatalf("nothing should be released before MaxWait, got %q", got)
+	}
+	time.Sleep(20 * time.Millisecond)
+	got := s.Push(" from nine in the mor")
+	want := []string{"Our opening hours on weekdays are from nine in the"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if rest := s.Push("ning to six."); len(rest) != 0 {
+		t.Errorf("the rest must wait for MaxWait again, got %q", rest)
+	}
+	if rest := s.Flush(); rest != "morning to six." {
+		t.Errorf("the incomplete word must be kept back, got %q", rest)
+	}
+}
+
+func TestSegmenter_EagerNeedsMinChars(t *testing.T) {
+	s := NewSegmenter(SegmenterConfig{Language: LanguageEn})
+	s.eager = EagerSynthesis{Enabled: true, MinChars: 40, MaxWait: time.Nanosecond}
+	time.Sleep(time.Millisecond)
+	if got := s.Push("Sure, let me"); len(got) != 0 {
+		t.Errorf("short text must not be released, got %q", got)
+	}
+}
+
+func TestEagerSegments_SplitsLongFirstSentence(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EagerSynthesis = EagerSynthesis{Enabled: true, MinChars: 20}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg)
+
+	got := o.eagerSegments(context.Background(), "Your order shipped yesterday from our warehouse in Madrid and should arrive on Monday. Anything else?", LanguageEn)
+	want := []string{"Your order shipped yesterday", "from our warehouse in Madrid and should arrive on Monday.", "Anything else?"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	short := o.eagerSegments(context.Background(), "Yes, it shipped. It arrives Monday.", LanguageEn)
+	if !reflect.DeepEqual(short, []string{"Yes, it shipped.", "It arrives Monday."}) {
+		t.Errorf("short sentences should be left whole, got %q", short)
+	}
+}
+
+func TestProcessAudio_EagerSynthesisWithBatchLLM(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EagerSynthesis = EagerSynthesis{Enabled: true}
+	response := "The weather is sunny today. Expect a high of twenty degrees."
+	tts := &sentenceTTS{spoken: make(chan struct{})}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "what's the weather"}, &MockLLMProvider{completeResult: response}, tts, nil, cfg)
+	session := NewConversationSession("u")
+
+	var chunks []string
+	_, audio, err := o.ProcessAudio(context.Background(), session, []byte{1}, true, func(b []byte) error {
+		chunks = append(chunks, string(b))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if audio != nil {
+		t.Error("streamed audio must not also be returned")
+	}
+	if len(chunks) != 2 || strings.Join(chunks, " ") != response {
+		t.Errorf("expected one synthesis per sentence, got %q", chunks)
+	}
+	if session.LastAssistant != response {
+		t.Errorf("full response not recorded: %q", session.LastAssistant)
+	}
+}
@@ -0,0 +1,64 @@
+package orchestrator
+
+import "time"
+
+// echoGateRatio is the mic-to-playback loudness a user needs to barge in
+// under Config.EchoGating when BargeInPlaybackRatio is not set.
+const echoGateRatio = 0.5
+
+// scheduledOutput is bot audio the stream sent, due to start playing at at.
+type scheduledOutput struct {
+	at    time.Time
+	chunk []byte
+}
+
+// scheduleOutputLocked remembers audio just sent so it can serve as the
+// echo reference once it is due to play. It stops once the client reports
+// what it plays itself.
+func (ms *ManagedStream) scheduleOutputLocked(at time.Time, chunk []byte) {
+	if !ms.echoGate || ms.clientReportsPlayback {
+		return
+	}
+	ms.outputQueue = append(ms.outputQueue, scheduledOutput{at: at, chunk: chunk})
+}
+
+// feedPlayedOutput moves sent audio that should be playing by now into the
+// echo reference and the playback level, standing in for a client that
+// does not call RecordPlayedOutput.
+func (ms *ManagedStream) feedPlayedOutput() {
+	ms.mu.Lock()
+	now := time.Now()
+	n := 0
+	for n < len(ms.outputQueue) && !ms.outputQueue[n].at.After(now) {
+		n++
+	}
+	due := ms.outputQueue[:n]
+	ms.outputQueue = ms.outputQueue[n:]
+	if len(ms.outputQueue) == 0 {
+		ms.outputQueue = nil
+	}
+	ms.mu.Unlock()
+
+	for _, out := range due {
+		ms.trackPlaybackLevel(out.chunk)
+		if ms.echoSuppressor != nil {
+			ms.echoSuppressor.RecordPlayedAudio(out.chunk)
+		}
+	}
+}
+
+// dropScheduledOutput forgets audio the client was told to discard.
+func (ms *ManagedStream) dropScheduledOutput() {
+	ms.mu.Lock()
+	ms.outputQueue = nil
+	ms.mu.Unlock()
+}
+
+// isOwnEcho reports whether mic audio correlates with the bot audio being
+// played, i.e. the bot is hearing itself rather than the user.
+func (ms *ManagedStream) isOwnEcho(chunk []byte) bool {
+	if !ms.echoGate || ms.echoSuppressor == nil {
+		return false
+	}
+	return ms.echoSuppressor.IsEchoFast(chunk)
+}
@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+// newEchoGateStream returns a stream whose TTS speaks bot.
+func newEchoGateStream(t *testing.T, gating bool, bot []byte) (*ManagedStream, *talkingTTS) {
+	t.Helper()
+	tts := newTalkingTTS(bot)
+	ms := newTestStream(t, testProviders{tts: tts, vad: &scriptedVAD{script: []VADEventType{VADSpeechStart, VADSpeechEnd}}}, func(cfg *Config) {
+		cfg.EchoGating = gating
+	})
+	return ms, tts
+}
+
+// sendBotAudio has the bot speak its audio and waits for it to be due to
+// play.
+func sendBotAudio(t *testing.T, ms *ManagedStream, tts *talkingTTS) {
+	t.Helper()
+	startTalking(t, ms, tts, "Your order has shipped.")
+	ms.mu.Lock()
+	end := ms.playbackEnd
+	ms.mu.Unlock()
+	time.Sleep(time.Until(end) / 4)
+}
+
+func TestManagedStream_EchoGatingHoldsBackOwnAudio(t *testing.T) {
+	bot := generateSine(300, 400, 44100, 0.4)
+	echo := generateSine(300, 20, 44100, 0.3)
+
+	ungated, tts := newEchoGateStream(t, false, bot)
+	ungated.echoSuppressor.SetEnabled(false)
+	sendBotAudio(t, ungated, tts)
+	ungated.doWrite(echo)
+	ungated.doWrite(make([]byte, 1764))
+	if counts := countEvents(ungated, 200*time.Millisecond); counts[UserSpeaking] != 1 {
+		t.Fatalf("without gating the echo should barge in, got %v", counts)
+	}
+
+	gated, tts := newEchoGateStream(t, true, bot)
+	sendBotAudio(t, gated, tts)
+	gated.doWrite(echo)
+	gated.doWrite(make([]byte, 1764))
+	if counts := countEvents(gated, 200*time.Millisecond); counts[UserSpeaking] != 0 || counts[UserStopped] != 0 {
+		t.Errorf("the bot's own audio should not barge in, got %v", counts)
+	}
+	if gated.PlaybackLevel() == 0 {
+		t.Error("expected sent audio to count as playing")
+	}
+}
+
+func TestManagedStream_EchoGatingDefersToClientReports(t *testing.T) {
+	ms, _ := newEchoGateStream(t, true, nil)
+	ms.RecordPlayedOutput(generateSine(300, 20, 44100, 0.1))
+
+	ms.mu.Lock()
+	ms.sendAudioLocked(generateSine(300, 100, 44100, 0.4), ms.payloadGen)
+	queued := len(ms.outputQueue)
+	ms.mu.Unlock()
+	if queued != 0 {
+		t.Errorf("expected no scheduling once the client reports playback, got %d chunks", queued)
+	}
+}
@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+)
+
+// minEchoWords keeps short replies ("yes", "okay, thanks") from ever being
+// mistaken for echo just because the assistant used the same words.
+const minEchoWords = 3
+
+// echoTail is how long after the assistant's audio stopped playing the
+// room may still carry it back to the microphone. Audio captured later than
+// that is the user's own, even if it repeats the assistant word for word,
+// as when reading back an address.
+var echoTail = time.Second
+
+// echoScore is the best fraction of the transcript's words found, in
+// order, in a stretch of reference about as long as the transcript: 1 when the
+// transcript is a verbatim fragment of what the assistant said. Allowing a
+// few missing or misheard words covers STT errors on speaker audio, while the
+// window keeps a user who reuses some of the assistant's words ("a table for
+// four" after "a table for two or four?") from counting as echo.
+func echoScore(transcript, reference string) float64 {
+	t := strings.Fields(normalizeWords(transcript))
+	r := strings.Fields(normalizeWords(reference))
+	if len(t) == 0 || len(r) == 0 {
+		return 0
+	}
+	window := len(t) + len(t)/4
+	best := 0
+	for start := 0; start < len(r); start++ {
+		end := min(start+window, len(r))
+		best = max(best, commonSubsequence(t, r[start:end]))
+		if end == len(r) {
+			break
+		}
+	}
+	return float64(best) / float64(len(t))
+}
+
+func commonSubsequence(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] >= cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// isEchoTranscript reports whether transcript is the assistant's own speech
+// picked up by the microphone, judged against each reference text. Callers
+// only ask about audio captured during or just after playback.
+func (o *Orchestrator) isEchoTranscript(transcript string, references ...string) bool {
+	threshold := o.GetConfig().EchoTranscriptSimilarity
+	if threshold <= 0 || len(strings.Fields(transcript)) < minEchoWords {
+		return false
+	}
+	for _, ref := range references {
+		if ref != "" && echoScore(transcript, ref) >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// isEchoTranscript reports whether transcript, of audio lasting duration
+// that ended just now, is the bot's own speech picked up by the microphone.
+func (ms *ManagedStream) isEchoTranscript(transcript string, duration time.Duration) bool {
+	if ms.orch == nil || ms.session == nil {
+		return false
+	}
+	ms.mu.Lock()
+	spoken := ms.speechText
+	speaking := ms.isSpeaking
+	ms.mu.Unlock()
+	if !speaking && !ms.session.heardBotSpeech(time.Now().Add(-duration)) {
+		return false
+	}
+	return ms.orch.isEchoTranscript(transcript, spoken, ms.session.lastAssistant())
+}
+
+// noteBotSpeech records when the assistant's audio stops playing.
+func (s *ConversationSession) noteBotSpeech(end time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if end.After(s.botSpeechEnd) {
+		s.botSpeechEnd = end
+	}
+}
+
+// heardBotSpeech reports whether audio captured from start on may carry the
+// assistant's speech: it began before that stopped playing, or within
+// echoTail of it.
+func (s *ConversationSession) heardBotSpeech(start time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.botSpeechEnd.IsZero() && start.Before(s.botSpeechEnd.Add(echoTail))
+}
+
+func (s *ConversationSession) lastAssistant() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.LastAssistant
+}
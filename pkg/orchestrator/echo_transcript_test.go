@@ -0,0 +1,101 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEchoScore(t *testing.T) {
+	ref := "Would you like a table for two or four? We also have seats at the bar tonight."
+	tests := []struct {
+		transcript string
+		echo       bool
+	}{
+		{"would you like a table for two", true},
+		{"we also have seats at the bar", true},
+		{"we also have seat at the bar tonight", true}, // A misheard word
+		{"a table for four", false},
+		{"for two please", false},
+		{"can I get a window seat instead", false},
+	}
+	for _, tt := range tests {
+		if got := echoScore(tt.transcript, ref) >= 0.8; got != tt.echo {
+			t.Errorf("%q: echo = %v (score %.2f), want %v", tt.transcript, got, echoScore(tt.transcript, ref), tt.echo)
+		}
+	}
+}
+
+func TestProcessAudio_DropsEchoedTranscript(t *testing.T) {
+	stt := &MockSTTProvider{transcribeResult: "a table for two at eight"}
+	cfg := DefaultConfig()
+	cfg.EchoTranscriptSimilarity = 0.8
+	o := NewWithVAD(stt, &MockLLMProvider{completeResult: "Great, your table for two is booked for eight."}, &MockTTSProvider{synthesizeResult: make([]byte, 4410)}, nil, cfg)
+	session := NewConversationSession("u")
+	if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reply is still playing when the microphone picks it up.
+	stt.transcribeResult = "your table for two is booked"
+	_, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil)
+	if !errors.Is(err, ErrEchoTranscript) {
+		t.Fatalf("expected ErrEchoTranscript, got %v", err)
+	}
+	if len(session.GetContextCopy()) != 2 {
+		t.Error("an echoed transcript must not be added to the context")
+	}
+
+	cfg.EchoTranscriptSimilarity = 0
+	o.UpdateConfig(cfg)
+	if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+		t.Errorf("expected echo suppression to be disabled, got %v", err)
+	}
+}
+
+func TestProcessAudio_ReadBackAfterPlaybackIsKept(t *testing.T) {
+	old := echoTail
+	echoTail = 10 * time.Millisecond
+	defer func() { echoTail = old }()
+
+	stt := &MockSTTProvider{transcribeResult: "what address do you have for me"}
+	cfg := DefaultConfig()
+	cfg.EchoTranscriptSimilarity = 0.8
+	o := NewWithVAD(stt, &MockLLMProvider{completeResult: "Your address is 12 Main Street."}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, cfg)
+	session := NewConversationSession("u")
+	if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	stt.transcribeResult = "12 Main Street"
+	if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+		t.Fatalf("a read-back after the reply finished playing was dropped: %v", err)
+	}
+
+	// Echo suppression is opt-in.
+	o.UpdateConfig(DefaultConfig())
+	session.AddMessage(RoleAssistant, "Your address is 12 Main Street.")
+	if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+		t.Errorf("the default config dropped a transcript: %v", err)
+	}
+}
+
+func TestManagedStream_EchoOfCurrentSpeechIsNoise(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.EchoTranscriptSimilarity = 0.8
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{synthesizeResult: make([]byte, 4410)}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("u"))
+	defer ms.Close()
+
+	ms.speakText(context.Background(), "Let me check the opening hours for you.")
+
+	if !ms.isLikelyNoise(TranscriptionResult{Text: "check the opening hours for you"}, time.Second) {
+		t.Error("echo of the bot's own speech should not count as user speech")
+	}
+	if ms.isLikelyNoise(TranscriptionResult{Text: "what are your opening hours on Sunday"}, time.Second) {
+		t.Error("a real question must not be dropped")
+	}
+}
@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// EndDetection ends a ManagedStream when the user takes their leave, rather
+// than only after a silence timeout. A short final utterance whose last
+// clause is one of Phrases, give or take fillers ("okay, thanks, bye"),
+// ends the conversation at once; a question ("how do I say goodbye?") never
+// matches. When Classifier is set, other short utterances ("no, that's
+// everything I needed") are put to it. Longer utterances always go on to a
+// normal reply.
+//
+// On a match the stream emits ConversationComplete and says its closing
+// line (see ManagedStream.End); without one the LLM replies as usual. The
+// stream then closes once the audio has played.
+type EndDetection struct {
+	Phrases    []string    // nil uses DefaultEndPhrases
+	Classifier LLMProvider // Asked yes or no about utterances the phrases miss; nil disables
+	MaxWords   int         // Longest utterance considered; defaults to 8
+}
+
+// DefaultEndPhrases returns English and Spanish farewells.
+func DefaultEndPhrases() []string {
+	return []string{
+		"bye", "goodbye", "bye bye", "good night", "see you later", "see you soon",
+		"talk to you later", "have a good day", "have a nice day",
+		"that's all", "that's everything", "that's all for now",
+		"i'm done", "we're done", "nothing else",
+		"adiós", "chao", "hasta luego", "hasta pronto", "eso es todo", "nada más",
+	}
+}
+
+// endFillers may follow a farewell without changing it: "bye, thank you".
+var endFillers = map[string]bool{"thanks": true, "gracias": true, "then": true}
+
+const endClassifierPrompt = "You decide whether a caller has just ended a phone conversation with an assistant. " +
+	"Reply with only YES or NO."
+
+// Ended reports whether transcript, the user's reply to lastAssistant, ends
+// the conversation. Classifier errors count as no.
+func (d *EndDetection) Ended(ctx context.Context, lastAssistant, transcript string) bool {
+	if d == nil {
+		return false
+	}
+	maxWords := d.MaxWords
+	if maxWords <= 0 {
+		maxWords = 8
+	}
+	words := strings.Fields(normalizeWords(transcript))
+	if len(words) == 0 || len(words) > maxWords {
+		return false
+	}
+	if endsWithFarewell(transcript, d.Phrases) {
+		return true
+	}
+	if d.Classifier == nil {
+		return false
+	}
+	answer, err := d.Classifier.Complete(ctx, []Message{
+		{Role: RoleSystem, Content: endClassifierPrompt},
+		{Role: RoleUser, Content: fmt.Sprintf("Assistant: %q\nCaller: %q\nHas the caller ended the conversation?", lastAssistant, transcript)},
+	}, nil)
+	return err == nil && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(answer)), "YES")
+}
+
+// endsWithFarewell reports whether the last clause of transcript, leaving
+// out fillers, is one of phrases. Questions never are.
+func endsWithFarewell(transcript string, phrases []string) bool {
+	if strings.ContainsAny(transcript, "?¿") {
+		return false
+	}
+	if phrases == nil {
+		phrases = DefaultEndPhrases()
+	}
+	clauses := strings.FieldsFunc(transcript, func(r rune) bool { return strings.ContainsRune(",.;:!¡", r) })
+	for i := len(clauses) - 1; i >= 0; i-- {
+		words := trimEndFillers(strings.Fields(normalizeWords(clauses[i])))
+		if len(words) == 0 {
+			continue // A clause of fillers: "bye, thank you"
+		}
+		said := strings.Join(words, " ")
+		for _, phrase := range phrases {
+			if normalizeWords(phrase) == said {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// trimEndFillers strips fillers from both ends of a clause: "okay thanks bye
+// then" leaves "bye".
+func trimEndFillers(words []string) []string {
+	filler := func(w string) bool { return endFillers[w] || commandFillers[w] }
+	for {
+		n := len(words)
+		switch {
+		case n > 0 && filler(words[0]):
+			words = words[1:]
+		case n > 0 && filler(words[n-1]):
+			words = words[:n-1]
+		case n > 1 && words[0] == "thank" && words[1] == "you":
+			words = words[2:]
+		case n > 1 && words[n-2] == "thank" && words[n-1] == "you":
+			words = words[:n-2]
+		default:
+			return words
+		}
+	}
+}
+
+// answer replies to a user turn, or winds the stream down when the user has
+// ended the conversation.
+func (ms *ManagedStream) answer(ctx context.Context, transcript string) {
+	if !ms.conversationEnded(ctx, transcript) {
+		ms.runLLMAndTTS(ctx, transcript)
+		return
+	}
+	ms.emit(ConversationComplete, transcript)
+	if ms.closingLine() == "" {
+		ms.runLLMAndTTS(ctx, transcript)
+	}
+	ms.End(ms.ctx)
+}
+
+func (ms *ManagedStream) conversationEnded(ctx context.Context, transcript string) bool {
+	if ms.orch == nil || ms.session == nil {
+		return false
+	}
+	d := ms.orch.GetConfig().EndDetection
+	if d == nil {
+		return false
+	}
+	ms.session.mu.RLock()
+	last := ms.session.LastAssistant
+	ms.session.mu.RUnlock()
+	return d.Ended(ctx, last, transcript)
+}
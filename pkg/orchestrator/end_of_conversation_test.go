@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEndDetection_Phrases(t *testing.T) {
+	d := &EndDetection{}
+	for transcript, want := range map[string]bool{
+		"Okay, thanks, bye!":                     true,
+		"That's all, thank you.":                 true,
+		"Adiós, gracias":                         true,
+		"goodbye":                                true,
+		"Can you say that again?":                false,
+		"Is that all?":                           false,
+		"How do I say goodbye in French? Please": false,
+		"I'd like to cancel my order and then I'll say goodbye": false,
+		"Thanks bye":             true,
+		"Alright, goodbye then.": true,
+		"How do I say goodbye?":  false,
+		"did you just say bye":   false,
+		"Tell my sister goodbye": false,
+	} {
+		if got := d.Ended(context.Background(), "", transcript); got != want {
+			t.Errorf("%q: got %v, want %v", transcript, got, want)
+		}
+	}
+}
+
+func TestEndDetection_Classifier(t *testing.T) {
+	d := &EndDetection{Classifier: &MockLLMProvider{completeResult: "Yes."}}
+	if !d.Ended(context.Background(), "Anything else?", "No, that was everything I needed") {
+		t.Error("expected the classifier's yes to end the conversation")
+	}
+	d.Classifier = &MockLLMProvider{completeErr: errors.New("unavailable")}
+	if d.Ended(context.Background(), "Anything else?", "No, that was everything I needed") {
+		t.Error("a classifier error must not end the conversation")
+	}
+}
+
+func TestManagedStream_FarewellEndsStream(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.EndDetection = &EndDetection{}
+	cfg.Closing = "Thanks for calling!"
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "okay thanks, bye"}, &MockLLMProvider{completeErr: errors.New("the LLM must not be called")}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("u"))
+
+	go ms.runBatchPipeline(make([]byte, 3200))
+	var types []EventType
+	var lines []string
+	timeout := time.After(2 * time.Second)
+	for closed := false; !closed; {
+		select {
+		case ev, ok := <-ms.Events():
+			if !ok {
+				closed = true
+				break
+			}
+			types = append(types, ev.Type)
+			if ev.Type == BotResponse {
+				lines = append(lines, ev.Data.(string))
+			}
+		case <-timeout:
+			t.Fatalf("stream not closed after the farewell; events %v", types)
+		}
+	}
+	if !hasEvent(types, ConversationComplete) {
+		t.Errorf("no ConversationComplete event in %v", types)
+	}
+	if len(lines) != 1 || lines[0] != cfg.Closing {
+		t.Errorf("expected only the closing line, got %q", lines)
+	}
+}
@@ -0,0 +1,184 @@
+package orchestrator
+
+import "time"
+
+// EndpointPolicy decides which end-of-turn signal ends a turn when a
+// streaming STT provider sends a final transcript and the VAD reports
+// silence, and the two disagree on when.
+type EndpointPolicy string
+
+const (
+	// EndpointEarliest ends the turn on whichever signal comes first. Turns
+	// end quickly, but a pause mid-sentence may cut the user off.
+	EndpointEarliest EndpointPolicy = "earliest"
+	// EndpointLatest waits for both signals, or MaxWait after the first.
+	// Turns are rarely clipped, at the cost of lag.
+	EndpointLatest EndpointPolicy = "latest"
+	// EndpointWeighted waits for the other signal for a share of MaxWait
+	// set by STTWeight: the more STT is trusted, the less a final
+	// transcript waits for the VAD and the longer silence waits for STT.
+	EndpointWeighted EndpointPolicy = "weighted"
+)
+
+// EndpointFusion combines a streaming STT provider's end of turn, its final
+// transcript, with the VAD's. It has no effect with batch STT, where the
+// VAD alone ends turns.
+type EndpointFusion struct {
+	Policy    EndpointPolicy // "" is EndpointEarliest
+	MaxWait   time.Duration  // Longest wait for the second signal; defaults to 700ms
+	STTWeight float64        // Trust in STT over the VAD for EndpointWeighted, up to 1; defaults to 0.5
+}
+
+const defaultEndpointWait = 700 * time.Millisecond
+
+// wait returns how long a turn one signal has ended waits for the other.
+func (f *EndpointFusion) wait(sttFirst bool) time.Duration {
+	limit := f.MaxWait
+	if limit <= 0 {
+		limit = defaultEndpointWait
+	}
+	switch f.Policy {
+	case EndpointLatest:
+		return limit
+	case EndpointWeighted:
+		w := f.STTWeight
+		if w <= 0 {
+			w = 0.5
+		}
+		w = min(w, 1)
+		if sttFirst {
+			w = 1 - w
+		}
+		return time.Duration(float64(limit) * w)
+	}
+	return 0
+}
+
+// endpointState tracks the end-of-turn signals of one streaming utterance.
+type endpointState struct {
+	vadEnded bool
+	sttEnded bool
+	resolved bool   // The turn has ended; later signals are spent
+	held     func() // Answers the final transcript once the turn ends
+	timer    *time.Timer
+}
+
+func (ms *ManagedStream) endpointFusion() *EndpointFusion {
+	if ms.orch == nil {
+		return nil
+	}
+	return ms.orch.GetConfig().EndpointFusion
+}
+
+func (ms *ManagedStream) endpointLocked() *endpointState {
+	if ms.endpoint == nil {
+		ms.endpoint = &endpointState{}
+	}
+	return ms.endpoint
+}
+
+// holdVADEndpoint runs as the VAD ends a turn. It reports whether the turn
+// should not end yet because it is waiting for STT, or has already ended.
+func (ms *ManagedStream) holdVADEndpoint() bool {
+	fusion := ms.endpointFusion()
+	if fusion == nil {
+		return false
+	}
+	ms.mu.Lock()
+	if ms.ptt.enabled || (ms.sttChan == nil && ms.endpoint == nil) {
+		ms.mu.Unlock()
+		return false
+	}
+	st := ms.endpointLocked()
+	if st.resolved {
+		ms.mu.Unlock()
+		return true
+	}
+	st.vadEnded = true
+	if st.sttEnded {
+		held := ms.resolveEndpointLocked(st)
+		ms.mu.Unlock()
+		ms.submitUtterance()
+		held()
+		return true
+	}
+	wait := fusion.wait(false)
+	if wait <= 0 {
+		st.resolved = true
+		ms.mu.Unlock()
+		return false
+	}
+	st.timer = time.AfterFunc(wait, func() { ms.endpointTimeout(st) })
+	ms.mu.Unlock()
+	return true
+}
+
+// holdSTTEndpoint runs as streaming STT sends a final transcript. It
+// reports whether answer, which answers it, has been kept back until the
+// VAD ends the turn too; otherwise the caller answers at once.
+func (ms *ManagedStream) holdSTTEndpoint(transcript string, answer func()) bool {
+	fusion := ms.endpointFusion()
+	if fusion == nil {
+		return false
+	}
+	ms.mu.Lock()
+	if ms.ptt.enabled || ms.sttChan == nil {
+		ms.mu.Unlock()
+		return false
+	}
+	st := ms.endpointLocked()
+	if st.resolved {
+		ms.mu.Unlock()
+		return false
+	}
+	st.sttEnded = true
+	if st.vadEnded {
+		ms.resolveEndpointLocked(st)
+		ms.mu.Unlock()
+		ms.submitUtterance()
+		return false
+	}
+	if wait := fusion.wait(true); wait > 0 {
+		st.held = answer
+		st.timer = time.AfterFunc(wait, func() { ms.endpointTimeout(st) })
+		ms.mu.Unlock()
+		return true
+	}
+	st.resolved = true
+	ms.userSpeechEndTime = time.Now()
+	ms.mu.Unlock()
+	ms.orch.logger.Debug("streaming STT ended the turn before the VAD", "sessionID", ms.session.ID, "transcript", transcript)
+	ms.submitUtterance()
+	return false
+}
+
+// endpointTimeout ends a turn the second signal did not come for in time.
+func (ms *ManagedStream) endpointTimeout(st *endpointState) {
+	ms.mu.Lock()
+	if ms.endpoint != st || st.resolved {
+		ms.mu.Unlock()
+		return
+	}
+	if !st.vadEnded {
+		ms.userSpeechEndTime = time.Now()
+	}
+	held := ms.resolveEndpointLocked(st)
+	ms.mu.Unlock()
+	ms.submitUtterance()
+	held()
+}
+
+// resolveEndpointLocked marks the turn ended and returns the held answer,
+// or a no-op.
+func (ms *ManagedStream) resolveEndpointLocked(st *endpointState) func() {
+	st.resolved = true
+	if st.timer != nil {
+		st.timer.Stop()
+	}
+	held := st.held
+	st.held = nil
+	if held == nil {
+		held = func() {}
+	}
+	return held
+}
@@ -0,0 +1,123 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// manualSTT streams transcripts when the test says so and, like many
+// providers, sends a final one when its audio channel closes.
+type manualSTT struct {
+	mu           sync.Mutex
+	onTranscript func(string, bool) error
+	finalOnClose string
+	batches      atomic.Int32
+}
+
+func (m *manualSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	m.batches.Add(1)
+	return TranscriptionResult{}, nil
+}
+
+func (m *manualSTT) Name() string { return "manual" }
+
+func (m *manualSTT) StreamTranscribe(ctx context.Context, lang Language, onTranscript func(string, bool) error) (chan<- []byte, error) {
+	ch := make(chan []byte, 64)
+	m.mu.Lock()
+	m.onTranscript = onTranscript
+	m.mu.Unlock()
+	go func() {
+		for range ch {
+		}
+		if m.finalOnClose != "" {
+			onTranscript(m.finalOnClose, true)
+		}
+	}()
+	return ch, nil
+}
+
+func (m *manualSTT) final(text string) {
+	m.mu.Lock()
+	fn := m.onTranscript
+	m.mu.Unlock()
+	fn(text, true)
+}
+
+func newFusionStream(t *testing.T, stt *manualSTT, fusion *EndpointFusion) *ManagedStream {
+	t.Helper()
+	ms := newTestStream(t, testProviders{stt: stt}, func(cfg *Config) { cfg.EndpointFusion = fusion })
+	ms.startUtterance()
+	countEvents(ms, 20*time.Millisecond)
+	return ms
+}
+
+func TestEndpointFusion_LatestHoldsSTTFinalForVAD(t *testing.T) {
+	stt := &manualSTT{}
+	ms := newFusionStream(t, stt, &EndpointFusion{Policy: EndpointLatest, MaxWait: time.Second})
+
+	stt.final("book a table for")
+	if counts := countEvents(ms, 50*time.Millisecond); counts[UserStopped] != 0 || counts[TranscriptFinal] != 0 {
+		t.Fatalf("the turn ended on STT alone: %v", counts)
+	}
+	ms.commitUtterance() // The VAD hears silence
+	if counts := countEvents(ms, 50*time.Millisecond); counts[UserStopped] != 1 || counts[TranscriptFinal] != 1 {
+		t.Errorf("expected the turn to end with the VAD, got %v", counts)
+	}
+}
+
+func TestEndpointFusion_EarliestEndsOnSTT(t *testing.T) {
+	stt := &manualSTT{}
+	ms := newFusionStream(t, stt, &EndpointFusion{Policy: EndpointEarliest})
+
+	stt.final("book a table")
+	if counts := countEvents(ms, 50*time.Millisecond); counts[UserStopped] != 1 || counts[TranscriptFinal] != 1 {
+		t.Fatalf("expected STT to end the turn, got %v", counts)
+	}
+	// The VAD catching up later must not end it again.
+	ms.commitUtterance()
+	if counts := countEvents(ms, 50*time.Millisecond); counts[UserStopped] != 0 || stt.batches.Load() != 0 {
+		t.Errorf("the late VAD end was acted on: %v, %d batch transcriptions", counts, stt.batches.Load())
+	}
+}
+
+func TestEndpointFusion_VADWaitsForSTTUpToMaxWait(t *testing.T) {
+	stt := &manualSTT{finalOnClose: "book a table"}
+	ms := newFusionStream(t, stt, &EndpointFusion{Policy: EndpointLatest, MaxWait: 100 * time.Millisecond})
+
+	start := time.Now()
+	ms.commitUtterance()
+	var stopped time.Duration
+	deadline := time.After(time.Second)
+	for stopped == 0 {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == UserStopped {
+				stopped = time.Since(start)
+			}
+		case <-deadline:
+			t.Fatal("the turn never ended")
+		}
+	}
+	if stopped < 90*time.Millisecond {
+		t.Errorf("the turn ended after %v, before MaxWait", stopped)
+	}
+	if counts := countEvents(ms, 50*time.Millisecond); counts[TranscriptFinal] != 1 {
+		t.Errorf("expected the final transcript after the turn ended, got %v", counts)
+	}
+}
+
+func TestEndpointFusion_WeightedWait(t *testing.T) {
+	f := &EndpointFusion{Policy: EndpointWeighted, MaxWait: time.Second, STTWeight: 0.75}
+	if got := f.wait(false); got != 750*time.Millisecond {
+		t.Errorf("silence waits %v for a trusted STT, want 750ms", got)
+	}
+	if got := f.wait(true); got != 250*time.Millisecond {
+		t.Errorf("a final transcript waits %v for the VAD, want 250ms", got)
+	}
+	if got := (&EndpointFusion{}).wait(true); got != 0 {
+		t.Errorf("earliest waits %v", got)
+	}
+}
@@ -0,0 +1,152 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TurnEndpointer decides when a user who has gone quiet is done with their
+// turn, so that a pause mid-thought is not taken for the end of it. Once the
+// VAD reports silence it waits MinSilence, then looks at the latest interim
+// transcript: one ending in sentence punctuation closes the turn, and one
+// that does not is put to Check. A turn neither settles closes after
+// MaxSilence. Speech resuming before the turn closes continues the same
+// utterance, as within a SpeechHangover.
+//
+// Without a streaming STT provider there is no interim transcript, and
+// turns close after MinSilence.
+type TurnEndpointer struct {
+	MinSilence time.Duration  // Silence before any turn closes; defaults to 300ms
+	MaxSilence time.Duration  // Silence after which a turn closes regardless; defaults to 1.5s
+	Check      EndOfTurnCheck // Asked about transcripts without final punctuation; nil waits for MaxSilence
+}
+
+// EndOfTurnCheck judges from what has been said so far whether the user has
+// finished speaking.
+type EndOfTurnCheck interface {
+	TurnComplete(ctx context.Context, transcript string) (bool, error)
+}
+
+// EndOfTurnCheckFunc adapts a function to EndOfTurnCheck.
+type EndOfTurnCheckFunc func(ctx context.Context, transcript string) (bool, error)
+
+func (f EndOfTurnCheckFunc) TurnComplete(ctx context.Context, transcript string) (bool, error) {
+	return f(ctx, transcript)
+}
+
+// HeuristicTurnCheck judges turns with a TurnCompletionAnalyzer: no model
+// call, so it answers at once, but it only knows English.
+func HeuristicTurnCheck() EndOfTurnCheck {
+	tca := NewTurnCompletionAnalyzer()
+	return EndOfTurnCheckFunc(func(_ context.Context, transcript string) (bool, error) {
+		return tca.IsLikelyComplete(transcript), nil
+	})
+}
+
+// LLMTurnCheck asks a small, fast LLM whether the user is done. Its answer
+// must arrive within MaxSilence to count.
+type LLMTurnCheck struct {
+	LLM LLMProvider
+}
+
+const turnCheckPrompt = "You decide whether a speaker has finished what they wanted to say, " +
+	"or has only paused and will go on. Reply with only YES if they are done or NO if they will go on."
+
+func (c *LLMTurnCheck) TurnComplete(ctx context.Context, transcript string) (bool, error) {
+	answer, err := c.LLM.Complete(ctx, []Message{
+		{Role: RoleSystem, Content: turnCheckPrompt},
+		{Role: RoleUser, Content: fmt.Sprintf("The speaker said: %q\nAre they done?", transcript)},
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(answer)), "YES"), nil
+}
+
+func (e *TurnEndpointer) silences(floor time.Duration) (time.Duration, time.Duration) {
+	minSilence, maxSilence := e.MinSilence, e.MaxSilence
+	if minSilence <= 0 {
+		minSilence = 300 * time.Millisecond
+	}
+	if maxSilence <= 0 {
+		maxSilence = 1500 * time.Millisecond
+	}
+	minSilence = max(minSilence, floor)
+	return minSilence, max(maxSilence, minSilence)
+}
+
+// endpoint waits for the turn that went quiet at since to close, reading
+// the interim transcript through transcript. It returns false if ctx ends
+// first. floor raises MinSilence, so a SpeechHangover is still honoured.
+func (e *TurnEndpointer) endpoint(ctx context.Context, since time.Time, floor time.Duration, transcript func() string) bool {
+	minSilence, maxSilence := e.silences(floor)
+	if !sleepUntil(ctx, since.Add(minSilence)) {
+		return false
+	}
+	text := strings.TrimSpace(transcript())
+	if text == "" || endsSentence(text) {
+		return true
+	}
+	if e.Check != nil {
+		checkCtx, cancel := context.WithDeadline(ctx, since.Add(maxSilence))
+		done, err := e.Check.TurnComplete(checkCtx, text)
+		cancel()
+		if err == nil && done {
+			return ctx.Err() == nil
+		}
+	}
+	return sleepUntil(ctx, since.Add(maxSilence))
+}
+
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// endsSentence reports whether text ends in sentence punctuation, closing
+// quotes aside. A trailing ellipsis trails off rather than ends.
+func endsSentence(text string) bool {
+	text = strings.TrimRightFunc(text, isCloser)
+	if strings.HasSuffix(text, "...") {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return r == '.' || r == '!' || r == '?' || isCJKTerminal(r)
+}
+
+// holdForEndpointLocked holds a finished utterance back until the endpointer
+// closes the turn. Like a hangover hold, it is dropped when speech resumes.
+func (ms *ManagedStream) holdForEndpointLocked(e *TurnEndpointer, floor time.Duration) {
+	ms.hangoverGen++
+	gen := ms.hangoverGen
+	ms.hangoverPending = true
+	since := ms.userSpeechEndTime
+	go func() {
+		if !e.endpoint(ms.ctx, since, floor, ms.interimTranscript) {
+			return
+		}
+		ms.mu.Lock()
+		if !ms.hangoverPending || ms.hangoverGen != gen || ms.ctx.Err() != nil {
+			ms.mu.Unlock()
+			return
+		}
+		ms.hangoverPending = false
+		ms.mu.Unlock()
+		ms.commitUtterance()
+	}()
+}
+
+func (ms *ManagedStream) interimTranscript() string {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.lastTranscript
+}
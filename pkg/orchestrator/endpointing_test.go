@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEndsSentence(t *testing.T) {
+	cases := map[string]bool{
+		"How are you?":           true,
+		"I'd like to book.":      true,
+		`He said "stop!"`:        true,
+		"予約したいです。":               true,
+		"So I was thinking":      false,
+		"and then, well...":      false,
+		"I need it for Tuesday,": false,
+	}
+	for text, want := range cases {
+		if got := endsSentence(text); got != want {
+			t.Errorf("endsSentence(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestTurnEndpointer_Endpoint(t *testing.T) {
+	yes := EndOfTurnCheckFunc(func(context.Context, string) (bool, error) { return true, nil })
+	no := EndOfTurnCheckFunc(func(context.Context, string) (bool, error) { return false, nil })
+	failing := EndOfTurnCheckFunc(func(context.Context, string) (bool, error) { return true, errors.New("down") })
+
+	cases := []struct {
+		name       string
+		transcript string
+		check      EndOfTurnCheck
+		long       bool
+	}{
+		{"punctuated", "Can you help me?", no, false},
+		{"no transcript", "", nil, false},
+		{"unpunctuated without check", "I wanted to ask", nil, true},
+		{"check says done", "I wanted to ask", yes, false},
+		{"check says not done", "I wanted to ask", no, true},
+		{"check fails", "I wanted to ask", failing, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &TurnEndpointer{MinSilence: 20 * time.Millisecond, MaxSilence: 200 * time.Millisecond, Check: tc.check}
+			start := time.Now()
+			if !e.endpoint(context.Background(), start, 0, func() string { return tc.transcript }) {
+				t.Fatal("endpoint should close the turn")
+			}
+			long := time.Since(start) >= 200*time.Millisecond
+			if long != tc.long {
+				t.Errorf("waited %v, want waiting for MaxSilence to be %v", time.Since(start), tc.long)
+			}
+		})
+	}
+}
+
+func TestTurnEndpointer_FloorAndCancel(t *testing.T) {
+	e := &TurnEndpointer{MinSilence: 10 * time.Millisecond, MaxSilence: 20 * time.Millisecond}
+	minSilence, maxSilence := e.silences(100 * time.Millisecond)
+	if minSilence != 100*time.Millisecond || maxSilence != 100*time.Millisecond {
+		t.Errorf("a hangover should raise both silences, got %v and %v", minSilence, maxSilence)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if e.endpoint(ctx, time.Now(), 0, func() string { return "" }) {
+		t.Error("a cancelled endpoint should not close the turn")
+	}
+}
+
+func TestLLMTurnCheck(t *testing.T) {
+	check := &LLMTurnCheck{LLM: &MockLLMProvider{completeResult: "No."}}
+	if done, err := check.TurnComplete(context.Background(), "my order number is"); err != nil || done {
+		t.Errorf("got %v, %v; want not done", done, err)
+	}
+	check.LLM = &MockLLMProvider{completeResult: "YES"}
+	if done, err := check.TurnComplete(context.Background(), "that's all"); err != nil || !done {
+		t.Errorf("got %v, %v; want done", done, err)
+	}
+}
+
+func TestManagedStream_EndpointerWaitsForUnfinishedThought(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Endpointing = &TurnEndpointer{MinSilence: 30 * time.Millisecond, MaxSilence: 300 * time.Millisecond}
+	vad := &scriptedVAD{script: []VADEventType{VADSpeechStart, VADSpeechEnd, VADSpeechStart, VADSpeechEnd}}
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, vad, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("pausing"))
+	defer ms.Close()
+
+	chunk := make([]byte, 64)
+	ms.doWrite(chunk) // start
+	ms.mu.Lock()
+	ms.lastTranscript = "so what I wanted to ask is"
+	ms.mu.Unlock()
+	ms.doWrite(chunk) // end: mid-thought, held
+	if counts := countEvents(ms, 150*time.Millisecond); counts[UserStopped] != 0 {
+		t.Fatal("an unfinished thought should not close the turn before MaxSilence")
+	}
+	ms.doWrite(chunk) // the user goes on
+	ms.mu.Lock()
+	ms.lastTranscript = "so what I wanted to ask is whether you deliver."
+	ms.mu.Unlock()
+	ms.doWrite(chunk) // end: complete sentence
+
+	counts := countEvents(ms, 200*time.Millisecond)
+	if counts[UserSpeaking] != 0 {
+		t.Errorf("resumed speech should continue the turn, got %d UserSpeaking", counts[UserSpeaking])
+	}
+	if counts[UserStopped] != 1 {
+		t.Errorf("a finished sentence should close the turn after MinSilence, got %d UserStopped", counts[UserStopped])
+	}
+}
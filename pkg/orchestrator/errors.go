@@ -21,4 +21,83 @@ var (
 
 	
 	ErrContextCancelled = errors.New("operation cancelled by context")
+
+	
+	ErrRateLimited = errors.New("provider rate limited")
+
+	
+	ErrProviderTimeout = errors.New("provider call timed out")
+
+	
+	ErrSessionNotFound = errors.New("session not found")
+
+	
+	ErrVersionConflict = errors.New("session was modified concurrently")
+
+	
+	ErrNoSessionStore = errors.New("no session store configured")
+
+	
+	ErrRecordingNotFound = errors.New("turn recording not found")
+
+	
+	ErrTenantNotFound = errors.New("tenant not found")
+
+	
+	ErrQuotaExceeded = errors.New("tenant quota exceeded")
+
+	
+	ErrToolNotPermitted = errors.New("tool not permitted for this session")
+
+	
+	ErrNoActiveStream = errors.New("no active stream for session")
+
+	
+	ErrNoBeep = errors.New("audio ended before a voicemail beep")
+
+	
+	ErrTranscriptionClosed = errors.New("transcription already finalized")
+
+	
+	ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+	
+	ErrEchoTranscript = errors.New("transcript echoes the assistant's last utterance")
+
+	
+	ErrEmptyInput = errors.New("empty text input")
+
+	
+	ErrNothingToRepeat = errors.New("no assistant response to repeat")
+
+	
+	ErrTurnCancelled = errors.New("turn cancelled")
+
+	
+	ErrTurnSuperseded = errors.New("turn dropped for a newer one in the same session")
+
+	
+	ErrTurnMerged = errors.New("turn merged into a later one in the same session")
+
+	
+	ErrNothingToResume = errors.New("no interrupted response to resume")
+
+	
+	ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+	
+	ErrForbidden = errors.New("not permitted for this identity")
+
+	
+	ErrUnknownResumeToken = errors.New("resume token unknown or expired")
+
+	
+	ErrResumeGap = errors.New("events after the last acknowledged one are no longer buffered")
+
+	
+	ErrNotPushToTalk = errors.New("stream is not in push-to-talk mode")
+	
+	ErrTurnBudgetExceeded = errors.New("turn budget exceeded")
+	
+	ErrContextOverflow = errors.New("prompt does not fit the LLM context window")
 )
@@ -0,0 +1,338 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FallbackUsed reports a provider that failed or timed out and the one a
+// fallback chain moved on to. It is the data of a ProviderFallback event.
+type FallbackUsed struct {
+	SessionID string `json:"session_id,omitempty"`
+	Stage     Stage  `json:"stage"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Reason    string `json:"reason"`
+}
+
+// errFallbackTimeout abandons a provider that took longer than a fallback
+// chain's Timeout.
+var errFallbackTimeout = fmt.Errorf("no response in time: %w", context.DeadlineExceeded)
+
+// fallbackAttempt is one provider's call in a fallback chain.
+type fallbackAttempt struct {
+	mu        sync.Mutex
+	started   bool
+	abandoned bool
+}
+
+// output marks that the call has delivered output, after which the chain
+// sticks with it. It reports false if the call was already abandoned.
+func (a *fallbackAttempt) output() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.abandoned {
+		return false
+	}
+	a.started = true
+	return true
+}
+
+// runFallback calls providers in order until one succeeds. Each call gets
+// timeout, if set, to succeed or to deliver its first output; a call that
+// has delivered output is not abandoned, since what it said can't be taken
+// back. Providers are tried by ProviderWeight, so those cooling down from a
+// rate limit go last, and rate limits met are recorded. Moving on is
+// reported to onFallback and to a managed stream.
+func runFallback[P interface{ Name() string }](ctx context.Context, stage Stage, providers []P, timeout time.Duration, onFallback func(FallbackUsed), call func(ctx context.Context, p P, a *fallbackAttempt) error) error {
+	if len(providers) == 0 {
+		return fmt.Errorf("fallback %s: no providers", stage)
+	}
+	cooldowns := cooldownsFromContext(ctx)
+	providers = byWeight(cooldowns, providers)
+	var errs []error
+	for i, p := range providers {
+		a := &fallbackAttempt{}
+		actx, cancel := context.WithCancelCause(ctx)
+		var t *time.Timer
+		if timeout > 0 {
+			t = time.AfterFunc(timeout, func() {
+				a.mu.Lock()
+				defer a.mu.Unlock()
+				if !a.started {
+					a.abandoned = true
+					cancel(errFallbackTimeout)
+				}
+			})
+		}
+		err := call(actx, p, a)
+		if t != nil {
+			t.Stop()
+		}
+		if err != nil && errors.Is(context.Cause(actx), errFallbackTimeout) {
+			err = errFallbackTimeout
+		}
+		cancel(nil)
+		cooldowns.observe(err)
+		if err == nil || a.started || ctx.Err() != nil {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		if i == len(providers)-1 {
+			break
+		}
+		reportFallback(ctx, onFallback, FallbackUsed{Stage: stage, From: p.Name(), To: providers[i+1].Name(), Reason: err.Error()})
+	}
+	return errors.Join(errs...)
+}
+
+// reportFallback tells onFallback and the managed stream behind ctx, if
+// any, that a chain moved on.
+func reportFallback(ctx context.Context, onFallback func(FallbackUsed), f FallbackUsed) {
+	watch, _ := ctx.Value(budgetWatchKey{}).(budgetWatch)
+	f.SessionID = watch.sessionID
+	if onFallback != nil {
+		onFallback(f)
+	}
+	if watch.fellBack != nil {
+		watch.fellBack(f)
+	}
+}
+
+// byWeight orders providers by their ProviderWeight, heaviest first,
+// keeping the order of those that weigh the same.
+func byWeight[P interface{ Name() string }](c cooldownWatch, providers []P) []P {
+	out := append([]P(nil), providers...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return c.cooldowns.weight(out[i].Name()) > c.cooldowns.weight(out[j].Name())
+	})
+	return out
+}
+
+func fallbackName[P interface{ Name() string }](providers []P) string {
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name()
+	}
+	return "fallback(" + strings.Join(names, ",") + ")"
+}
+
+// FallbackSTT transcribes with its first provider and, when that fails or
+// times out, with the next, so one vendor's outage doesn't end the call.
+// It streams with the first provider that does; see StreamTranscribe.
+type FallbackSTT struct {
+	providers []STTProvider
+
+	// Timeout bounds each provider's call; 0 leaves them to ctx.
+	Timeout time.Duration
+	// OnFallback, when set, is told each time a provider is passed over.
+	OnFallback func(FallbackUsed)
+}
+
+// NewFallbackSTT returns a FallbackSTT trying primary, then secondaries in
+// order.
+func NewFallbackSTT(primary STTProvider, secondaries ...STTProvider) *FallbackSTT {
+	return &FallbackSTT{providers: append([]STTProvider{primary}, secondaries...)}
+}
+
+func (f *FallbackSTT) Name() string { return fallbackName(f.providers) }
+
+func (f *FallbackSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	var res TranscriptionResult
+	err := runFallback(ctx, StageSTT, f.providers, f.Timeout, f.OnFallback, func(ctx context.Context, p STTProvider, _ *fallbackAttempt) error {
+		var err error
+		res, err = p.Transcribe(ctx, audio, lang)
+		return err
+	})
+	if err != nil {
+		return TranscriptionResult{}, err
+	}
+	return res, nil
+}
+
+// StreamTranscribe streams with the first provider whose stream starts. A
+// stream is only handed over if it fails to start; later failures end it.
+// Reaching a provider that does not stream, the chain from there on
+// transcribes the whole utterance in batch once its audio ends.
+func (f *FallbackSTT) StreamTranscribe(ctx context.Context, lang Language, onTranscript func(transcript string, isFinal bool) error) (chan<- []byte, error) {
+	cooldowns := cooldownsFromContext(ctx)
+	providers := byWeight(cooldowns, f.providers)
+	var errs []error
+	for i, p := range providers {
+		sp, ok := p.(StreamingSTTProvider)
+		if !ok {
+			return f.batchStream(ctx, providers[i:], lang, onTranscript), nil
+		}
+		in, err := sp.StreamTranscribe(ctx, lang, onTranscript)
+		cooldowns.observe(err)
+		if err == nil {
+			return in, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		if i == len(providers)-1 || ctx.Err() != nil {
+			break
+		}
+		reportFallback(ctx, f.OnFallback, FallbackUsed{Stage: StageSTT, From: p.Name(), To: providers[i+1].Name(), Reason: err.Error()})
+	}
+	return nil, errors.Join(errs...)
+}
+
+// batchStream collects a stream's audio and, once it ends, transcribes it
+// with providers as Transcribe would, delivering the text as a final
+// transcript.
+func (f *FallbackSTT) batchStream(ctx context.Context, providers []STTProvider, lang Language, onTranscript func(string, bool) error) chan<- []byte {
+	in := make(chan []byte, 256)
+	go func() {
+		defer ReportTranscriptionDone(ctx)
+		var audio []byte
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				return
+			case chunk, ok := <-in:
+				audio = append(audio, chunk...)
+				open = ok
+			}
+		}
+		if len(audio) == 0 {
+			return
+		}
+		var res TranscriptionResult
+		err := runFallback(ctx, StageSTT, providers, f.Timeout, f.OnFallback, func(ctx context.Context, p STTProvider, _ *fallbackAttempt) error {
+			var err error
+			res, err = p.Transcribe(ctx, audio, lang)
+			return err
+		})
+		if err == nil && res.Text != "" {
+			onTranscript(res.Text, true)
+		}
+	}()
+	return in
+}
+
+// FallbackLLM answers with its first provider and, when that fails or
+// times out, with the next. A streamed reply is only handed over before
+// its first token or tool call; later failures are returned as they are.
+type FallbackLLM struct {
+	providers []LLMProvider
+
+	// Timeout bounds each provider's call, or its wait for a first token
+	// when streaming; 0 leaves them to ctx.
+	Timeout time.Duration
+	// OnFallback, when set, is told each time a provider is passed over.
+	OnFallback func(FallbackUsed)
+}
+
+// NewFallbackLLM returns a FallbackLLM trying primary, then secondaries in
+// order.
+func NewFallbackLLM(primary LLMProvider, secondaries ...LLMProvider) *FallbackLLM {
+	return &FallbackLLM{providers: append([]LLMProvider{primary}, secondaries...)}
+}
+
+func (f *FallbackLLM) Name() string { return fallbackName(f.providers) }
+
+func (f *FallbackLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	var reply string
+	err := runFallback(ctx, StageLLM, f.providers, f.Timeout, f.OnFallback, func(ctx context.Context, p LLMProvider, _ *fallbackAttempt) error {
+		var err error
+		reply, err = p.Complete(ctx, messages, tools)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return reply, nil
+}
+
+// StreamComplete streams the reply of the first provider to deliver any
+// output. Providers that do not stream deliver their reply as one chunk.
+func (f *FallbackLLM) StreamComplete(ctx context.Context, messages []Message, tools []Tool, onChunk func(string) error, onToolCall func(ToolCallEventData) error) (string, error) {
+	var reply string
+	err := runFallback(ctx, StageLLM, f.providers, f.Timeout, f.OnFallback, func(ctx context.Context, p LLMProvider, a *fallbackAttempt) error {
+		chunk := func(s string) error {
+			if !a.output() {
+				return errFallbackTimeout
+			}
+			return onChunk(s)
+		}
+		call := func(tc ToolCallEventData) error {
+			if !a.output() {
+				return errFallbackTimeout
+			}
+			return onToolCall(tc)
+		}
+		var err error
+		if sp, ok := p.(StreamingLLMProvider); ok {
+			reply, err = sp.StreamComplete(ctx, messages, tools, chunk, call)
+		} else if reply, err = p.Complete(ctx, messages, tools); err == nil && reply != "" {
+			err = chunk(reply)
+		}
+		return err
+	})
+	return reply, err
+}
+
+// FallbackTTS speaks with its first provider and, when that fails or times
+// out, with the next. A streamed synthesis is only handed over before its
+// first audio. Secondaries should speak at the primary's sample rate.
+type FallbackTTS struct {
+	providers []TTSProvider
+
+	// Timeout bounds each provider's call, or its wait for first audio when
+	// streaming; 0 leaves them to ctx.
+	Timeout time.Duration
+	// OnFallback, when set, is told each time a provider is passed over.
+	OnFallback func(FallbackUsed)
+}
+
+// NewFallbackTTS returns a FallbackTTS trying primary, then secondaries in
+// order.
+func NewFallbackTTS(primary TTSProvider, secondaries ...TTSProvider) *FallbackTTS {
+	return &FallbackTTS{providers: append([]TTSProvider{primary}, secondaries...)}
+}
+
+func (f *FallbackTTS) Name() string { return fallbackName(f.providers) }
+
+func (f *FallbackTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	var audio []byte
+	err := runFallback(ctx, StageTTS, f.providers, f.Timeout, f.OnFallback, func(ctx context.Context, p TTSProvider, _ *fallbackAttempt) error {
+		var err error
+		audio, err = p.Synthesize(ctx, text, voice, lang)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return audio, nil
+}
+
+func (f *FallbackTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	return runFallback(ctx, StageTTS, f.providers, f.Timeout, f.OnFallback, func(ctx context.Context, p TTSProvider, a *fallbackAttempt) error {
+		return p.StreamSynthesize(ctx, text, voice, lang, func(chunk []byte) error {
+			if !a.output() {
+				return errFallbackTimeout
+			}
+			return onChunk(chunk)
+		})
+	})
+}
+
+// Abort aborts every provider, since any of them may be speaking.
+func (f *FallbackTTS) Abort() error {
+	var errs []error
+	for _, p := range f.providers {
+		if err := p.Abort(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (ms *ManagedStream) fellBack(f FallbackUsed) {
+	ms.emit(ProviderFallback, f)
+}
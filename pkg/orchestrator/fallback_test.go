@@ -0,0 +1,163 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFallbackSTT_ErrorAndTimeout(t *testing.T) {
+	down := &delayedSTT{name: "down", err: errors.New("503 service unavailable")}
+	slow := &delayedSTT{name: "slow", delay: time.Second, res: TranscriptionResult{Text: "too late"}}
+	backup := &delayedSTT{name: "backup", res: TranscriptionResult{Text: "hello"}}
+	f := NewFallbackSTT(down, slow, backup)
+	f.Timeout = 20 * time.Millisecond
+	var used []FallbackUsed
+	f.OnFallback = func(u FallbackUsed) { used = append(used, u) }
+
+	var streamed []FallbackUsed
+	ctx := withBudgetWatch(context.Background(), budgetWatch{sessionID: "s1", fellBack: func(u FallbackUsed) { streamed = append(streamed, u) }})
+	start := time.Now()
+	res, err := f.Transcribe(ctx, nil, LanguageEn)
+	if err != nil || res.Text != "hello" {
+		t.Fatalf("got %q, %v", res.Text, err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("waited %v for the slow provider", time.Since(start))
+	}
+	if len(used) != 2 || used[0].From != "down" || used[0].To != "slow" || used[1].From != "slow" || used[1].To != "backup" {
+		t.Fatalf("fallbacks = %+v", used)
+	}
+	if used[1].Stage != StageSTT || used[1].SessionID != "s1" || len(streamed) != 2 {
+		t.Errorf("fallbacks = %+v, streamed %d", used, len(streamed))
+	}
+	if f.Name() != "fallback(down,slow,backup)" {
+		t.Errorf("name = %s", f.Name())
+	}
+
+	// With every provider down the errors of all are returned.
+	f = NewFallbackSTT(down, &delayedSTT{name: "also-down", err: errors.New("boom")})
+	if _, err := f.Transcribe(context.Background(), nil, LanguageEn); err == nil {
+		t.Fatal("expected an error")
+	} else if msg := err.Error(); msg != "down: 503 service unavailable\nalso-down: boom" {
+		t.Errorf("err = %q", msg)
+	}
+}
+
+func TestFallbackSTT_HonoursCooldown(t *testing.T) {
+	throttled := &delayedSTT{name: "primary", err: &RateLimitError{Provider: "primary", StatusCode: 429, RetryAfter: time.Minute}}
+	backup := &delayedSTT{name: "backup", res: TranscriptionResult{Text: "hello"}}
+	f := NewFallbackSTT(throttled, backup)
+	var used []FallbackUsed
+	f.OnFallback = func(u FallbackUsed) { used = append(used, u) }
+	o := New(f, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig(), nil)
+
+	for i := 0; i < 2; i++ {
+		if res, err := o.Transcribe(context.Background(), []byte{1}, LanguageEn); err != nil || res.Text != "hello" {
+			t.Fatalf("call %d: got %q, %v", i, res.Text, err)
+		}
+	}
+	if o.ProviderCooldown("primary") == 0 {
+		t.Error("the primary's rate limit should start its cooldown")
+	}
+	if len(used) != 1 {
+		t.Errorf("a provider cooling down should be tried last, fallbacks = %+v", used)
+	}
+}
+
+func TestFallbackSTT_StreamTranscribe(t *testing.T) {
+	finals := make(chan string, 1)
+	onTranscript := func(text string, isFinal bool) error {
+		if isFinal {
+			finals <- text
+		}
+		return nil
+	}
+
+	// A streaming primary gets the stream.
+	stt := &manualSTT{}
+	in, err := NewFallbackSTT(stt, &delayedSTT{name: "backup"}).StreamTranscribe(context.Background(), LanguageEn, onTranscript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stt.final("book a table")
+	if got := <-finals; got != "book a table" {
+		t.Errorf("got %q from the streaming primary", got)
+	}
+	close(in)
+
+	// One that does not stream transcribes the utterance once it ends.
+	done := make(chan struct{})
+	ctx := context.WithValue(context.Background(), transcriptionDoneKey{}, func() { close(done) })
+	in, err = NewFallbackSTT(&delayedSTT{name: "batch", res: TranscriptionResult{Text: "hello"}}).StreamTranscribe(ctx, LanguageEn, onTranscript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in <- []byte{1, 2}
+	close(in)
+	select {
+	case got := <-finals:
+		if got != "hello" {
+			t.Errorf("got %q from the batch provider", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no final transcript")
+	}
+	<-done
+}
+
+func TestFallbackLLM_StreamFallsBackBeforeOutput(t *testing.T) {
+	primary := &MockLLMProvider{completeErr: errors.New("overloaded")}
+	backup := &MockStreamingLLM{responses: []struct {
+		content   string
+		toolCalls []ToolCallEventData
+	}{{content: "Hi there."}}}
+	f := NewFallbackLLM(primary, backup)
+
+	var chunks []string
+	reply, err := f.StreamComplete(context.Background(), nil, nil, func(s string) error {
+		chunks = append(chunks, s)
+		return nil
+	}, nil)
+	if err != nil || reply != "Hi there." || len(chunks) != 1 {
+		t.Fatalf("reply %q, chunks %v, err %v", reply, chunks, err)
+	}
+}
+
+// brokenTTS speaks one chunk and then fails.
+type brokenTTS struct{ MockTTSProvider }
+
+func (b *brokenTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	if err := onChunk([]byte{1, 2}); err != nil {
+		return err
+	}
+	return errors.New("connection reset")
+}
+
+func TestFallbackTTS_KeepsStartedStream(t *testing.T) {
+	backup := &MockTTSProvider{synthesizeResult: []byte{9, 9}}
+	f := NewFallbackTTS(&brokenTTS{}, backup)
+	fellBack := false
+	f.OnFallback = func(FallbackUsed) { fellBack = true }
+
+	var got []byte
+	err := f.StreamSynthesize(context.Background(), "hi", VoiceF1, LanguageEn, func(chunk []byte) error {
+		got = append(got, chunk...)
+		return nil
+	})
+	if err == nil || fellBack || len(got) != 2 {
+		t.Errorf("err %v, fell back %v, audio %v", err, fellBack, got)
+	}
+
+	// Before any audio the backup takes over.
+	f = NewFallbackTTS(&MockTTSProvider{streamErr: errors.New("401")}, backup)
+	got = nil
+	err = f.StreamSynthesize(context.Background(), "hi", VoiceF1, LanguageEn, func(chunk []byte) error {
+		got = append(got, chunk...)
+		return nil
+	})
+	if err != nil || len(got) != 2 || got[0] != 9 {
+		t.Errorf("err %v, audio %v", err, got)
+	}
+}
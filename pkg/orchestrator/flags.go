@@ -0,0 +1,198 @@
+package orchestrator
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"sync"
+)
+
+// Flags the pipeline consults. Each gates a feature the config turns on: the
+// feature runs on a turn only where its flag is on too, so a rollout is the
+// config enabling it plus a rule choosing who gets it. Flags an evaluator
+// does not know leave the feature to the config.
+const (
+	FlagEagerSynthesis = "eager_synthesis" // Config.EagerSynthesis
+	FlagFiller         = "filler"          // Config.LatencyBudget.Filler
+)
+
+// FlagTarget is who a flag is evaluated for: the session a turn belongs to.
+type FlagTarget struct {
+	SessionID string
+	UserID    string
+	TenantID  string
+	Priority  Priority
+}
+
+// FlagEvaluator decides feature flags, once per flag per turn. ok is false
+// for a flag it has no rule for.
+type FlagEvaluator interface {
+	Flag(ctx context.Context, name string, target FlagTarget) (on, ok bool)
+}
+
+// FlagFunc adapts a function to FlagEvaluator.
+type FlagFunc func(ctx context.Context, name string, target FlagTarget) (on, ok bool)
+
+func (f FlagFunc) Flag(ctx context.Context, name string, target FlagTarget) (on, ok bool) {
+	return f(ctx, name, target)
+}
+
+// FlagRule says who a flag is on for.
+type FlagRule struct {
+	Percent  float64  // Share (0-100) of users the flag is on for; each user stays in or out as it grows
+	ByTenant bool     // Count Percent in tenants rather than users
+	Users    []string // On for these users whatever Percent says
+	Tenants  []string // On for these tenants whatever Percent says
+}
+
+// InMemoryFlags is a FlagEvaluator with rules set in code or loaded from
+// the deployment's config. Rules can change while sessions run; a change
+// takes effect from each session's next turn.
+type InMemoryFlags struct {
+	mu    sync.RWMutex
+	rules map[string]FlagRule
+}
+
+func NewInMemoryFlags() *InMemoryFlags {
+	return &InMemoryFlags{rules: make(map[string]FlagRule)}
+}
+
+// Set adds or replaces the rule for a flag.
+func (f *InMemoryFlags) Set(name string, rule FlagRule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules[name] = rule
+}
+
+// Remove drops a flag's rule, leaving its feature to the config.
+func (f *InMemoryFlags) Remove(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.rules, name)
+}
+
+func (f *InMemoryFlags) Flag(ctx context.Context, name string, target FlagTarget) (on, ok bool) {
+	f.mu.RLock()
+	rule, ok := f.rules[name]
+	f.mu.RUnlock()
+	if !ok {
+		return false, false
+	}
+	if slices.Contains(rule.Users, target.UserID) || slices.Contains(rule.Tenants, target.TenantID) {
+		return true, true
+	}
+	key := target.UserID
+	if rule.ByTenant {
+		key = target.TenantID
+	}
+	if key == "" {
+		key = target.SessionID
+	}
+	return flagBucket(name, key) < rule.Percent, true
+}
+
+// flagBucket places key in [0, 100) for a flag. Hashing the flag's name in
+// too keeps one flag's early adopters from being every flag's.
+func flagBucket(name, key string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) / 100
+}
+
+// turnFlags evaluates a turn's flags, each once, so a rule changing mid-turn
+// cannot change a feature halfway through it.
+type turnFlags struct {
+	eval   FlagEvaluator
+	target FlagTarget
+
+	mu    sync.Mutex
+	cache map[string][2]bool
+}
+
+type turnFlagsKey struct{}
+
+// withTurnFlags starts a turn's flags for session.
+func (o *Orchestrator) withTurnFlags(ctx context.Context, session *ConversationSession) context.Context {
+	eval := o.GetConfig().Flags
+	if eval == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, turnFlagsKey{}, &turnFlags{
+		eval: eval,
+		target: FlagTarget{
+			SessionID: session.ID,
+			UserID:    session.UserID,
+			TenantID:  session.GetTenantID(),
+			Priority:  session.GetPriority(),
+		},
+		cache: make(map[string][2]bool),
+	})
+}
+
+// TurnFlag evaluates a flag for the turn ctx belongs to, for tools and
+// middleware rolling out their own features. ok is false outside a turn,
+// without Config.Flags, or for a flag the evaluator has no rule for.
+func TurnFlag(ctx context.Context, name string) (on, ok bool) {
+	f, _ := ctx.Value(turnFlagsKey{}).(*turnFlags)
+	if f == nil {
+		return false, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if v, seen := f.cache[name]; seen {
+		return v[0], v[1]
+	}
+	on, ok = f.eval.Flag(ctx, name, f.target)
+	f.cache[name] = [2]bool{on, ok}
+	return on, ok
+}
+
+// gated reports whether a feature the config enables may run on this turn.
+func gated(ctx context.Context, name string) bool {
+	on, ok := TurnFlag(ctx, name)
+	return on || !ok
+}
+
+// SetFlagTTS sends turns on which flag is on to tts, e.g. to roll out a new
+// provider to a share of users. Unlike the gating flags, a flag the
+// evaluator has no rule for is off. nil removes the route.
+func (o *Orchestrator) SetFlagTTS(flag string, tts TTSProvider) {
+	o.flagged.mu.Lock()
+	defer o.flagged.mu.Unlock()
+	if tts == nil {
+		o.flagged.tts = slices.DeleteFunc(o.flagged.tts, func(r flagRoute) bool { return r.flag == flag })
+		return
+	}
+	for i, r := range o.flagged.tts {
+		if r.flag == flag {
+			o.flagged.tts[i].tts = tts
+			return
+		}
+	}
+	o.flagged.tts = append(o.flagged.tts, flagRoute{flag: flag, tts: tts})
+}
+
+type flagRoute struct {
+	flag string
+	tts  TTSProvider
+}
+
+type flagRoutes struct {
+	mu  sync.Mutex
+	tts []flagRoute // In the order they were set; the first flag on wins
+}
+
+// flaggedTTS returns the TTS routed to by the turn's flags, if any.
+func (o *Orchestrator) flaggedTTS(ctx context.Context) (TTSProvider, bool) {
+	o.flagged.mu.Lock()
+	routes := slices.Clone(o.flagged.tts)
+	o.flagged.mu.Unlock()
+	for _, r := range routes {
+		if on, ok := TurnFlag(ctx, r.flag); on && ok {
+			return r.tts, true
+		}
+	}
+	return nil, false
+}
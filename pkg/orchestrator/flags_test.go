@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestInMemoryFlags(t *testing.T) {
+	flags := NewInMemoryFlags()
+	ctx := context.Background()
+	if _, ok := flags.Flag(ctx, "new_voice", FlagTarget{UserID: "u1"}); ok {
+		t.Error("a flag without a rule should be unknown")
+	}
+
+	flags.Set("new_voice", FlagRule{Percent: 10, Tenants: []string{"acme"}})
+	early := map[string]bool{}
+	for i := range 1000 {
+		user := fmt.Sprintf("user-%d", i)
+		if on, _ := flags.Flag(ctx, "new_voice", FlagTarget{UserID: user}); on {
+			early[user] = true
+		}
+	}
+	if len(early) < 60 || len(early) > 140 {
+		t.Errorf("10%% rollout turned the flag on for %d of 1000 users", len(early))
+	}
+	if on, _ := flags.Flag(ctx, "new_voice", FlagTarget{UserID: "anyone", TenantID: "acme"}); !on {
+		t.Error("listed tenant should always have the flag")
+	}
+
+	// Growing the rollout keeps everyone who already had it.
+	flags.Set("new_voice", FlagRule{Percent: 50})
+	for user := range early {
+		if on, _ := flags.Flag(ctx, "new_voice", FlagTarget{UserID: user}); !on {
+			t.Fatalf("%s lost the flag when the rollout grew", user)
+		}
+	}
+
+	flags.Set("per_tenant", FlagRule{Percent: 50, ByTenant: true})
+	a, _ := flags.Flag(ctx, "per_tenant", FlagTarget{UserID: "u1", TenantID: "globex"})
+	b, _ := flags.Flag(ctx, "per_tenant", FlagTarget{UserID: "u2", TenantID: "globex"})
+	if a != b {
+		t.Error("a tenant rollout should treat a tenant's users alike")
+	}
+}
+
+func TestTurnFlags_GateEagerSynthesis(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EagerSynthesis.Enabled = true
+	calls := 0
+	cfg.Flags = FlagFunc(func(ctx context.Context, name string, target FlagTarget) (bool, bool) {
+		calls++
+		if name != FlagEagerSynthesis {
+			return false, false
+		}
+		return target.UserID == "beta", true
+	})
+	o := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg, nil)
+
+	beta := o.withTurnFlags(context.Background(), NewConversationSession("beta"))
+	if !o.newSegmenter(beta, LanguageEn).eager.Enabled || !o.newSegmenter(beta, LanguageEn).eager.Enabled {
+		t.Error("eager synthesis should run where its flag is on")
+	}
+	if calls != 1 {
+		t.Errorf("flag evaluated %d times in one turn, want once", calls)
+	}
+	other := o.withTurnFlags(context.Background(), NewConversationSession("other"))
+	if o.newSegmenter(other, LanguageEn).eager.Enabled {
+		t.Error("eager synthesis should not run where its flag is off")
+	}
+	if !gated(other, FlagFiller) {
+		t.Error("a flag the evaluator does not know should leave the feature to the config")
+	}
+}
+
+func TestSetFlagTTS(t *testing.T) {
+	flags := NewInMemoryFlags()
+	flags.Set("piper_rollout", FlagRule{Tenants: []string{"acme"}})
+	cfg := DefaultConfig()
+	cfg.Flags = flags
+	o := New(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: "hello"}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, cfg, nil)
+	o.SetFlagTTS("piper_rollout", &namedTTS{MockTTSProvider: MockTTSProvider{synthesizeResult: []byte{9, 9}}, name: "piper"})
+
+	acme := NewConversationSession("caller")
+	acme.TenantID = "acme"
+	if _, audio, err := o.ProcessAudio(context.Background(), acme, []byte{0}, false, nil); err != nil || len(audio) != 2 {
+		t.Errorf("acme should hear the new TTS, got %v (%v)", audio, err)
+	}
+	if _, audio, err := o.ProcessAudio(context.Background(), NewConversationSession("caller"), []byte{0}, false, nil); err != nil || len(audio) != 1 {
+		t.Errorf("others should hear the default TTS, got %v (%v)", audio, err)
+	}
+
+	o.SetFlagTTS("piper_rollout", nil)
+	if _, audio, _ := o.ProcessAudio(context.Background(), acme, []byte{0}, false, nil); len(audio) != 1 {
+		t.Errorf("removed route still used, got %v", audio)
+	}
+}
@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transport is how a connection carries reply audio to the listener. It
+// sets how finely the audio is chunked.
+type Transport string
+
+const (
+	TransportRTP       Transport = "rtp"       // 20ms frames of exactly that length, as RTP packets carry
+	TransportWebSocket Transport = "websocket" // 60ms frames
+	TransportHTTP      Transport = "http"      // 200ms chunks, fewer writes for a chunked response
+)
+
+var transportFrames = map[Transport]time.Duration{
+	TransportRTP:       20 * time.Millisecond,
+	TransportWebSocket: 60 * time.Millisecond,
+	TransportHTTP:      200 * time.Millisecond,
+}
+
+// AudioFraming is the size of the chunks reply audio reaches onAudioChunk
+// and AUDIO_CHUNK events in. The zero value leaves ProcessAudio and its
+// relatives passing on audio as the TTS provider sends it, and managed
+// streams using 60ms frames.
+type AudioFraming struct {
+	Transport Transport     // Sets the frame length when Frame is zero
+	Frame     time.Duration // Length of each chunk; the last before a pause may be shorter, except over RTP
+}
+
+// FrameDuration returns the length of a chunk, or zero when audio is passed
+// on as it comes.
+func (f AudioFraming) FrameDuration() time.Duration {
+	if f.Frame > 0 {
+		return f.Frame
+	}
+	return transportFrames[f.Transport]
+}
+
+// ParseAudioFraming reads the framing a client asks for when it connects:
+// a transport ("rtp", "websocket", "http"), a frame length ("40ms"), or
+// both ("rtp:40ms").
+func ParseAudioFraming(s string) (AudioFraming, error) {
+	var f AudioFraming
+	transport, frame, both := strings.Cut(strings.TrimSpace(s), ":")
+	if !both {
+		if _, err := time.ParseDuration(transport); err == nil {
+			transport, frame = "", transport
+		}
+	}
+	if transport != "" {
+		f.Transport = Transport(strings.ToLower(transport))
+		if _, ok := transportFrames[f.Transport]; !ok {
+			return AudioFraming{}, fmt.Errorf("unknown audio transport %q", transport)
+		}
+	}
+	if frame != "" {
+		d, err := time.ParseDuration(frame)
+		if err != nil {
+			return AudioFraming{}, fmt.Errorf("bad audio frame length %q: %w", frame, err)
+		}
+		if d < 10*time.Millisecond || d > time.Second {
+			return AudioFraming{}, fmt.Errorf("audio frame length %v out of range 10ms-1s", d)
+		}
+		f.Frame = d
+	}
+	return f, nil
+}
+
+type audioFramingKey struct{}
+
+// WithAudioFraming sets the framing of the reply audio for a turn run with
+// ctx, overriding Config.AudioFraming for one connection.
+func WithAudioFraming(ctx context.Context, f AudioFraming) context.Context {
+	return context.WithValue(ctx, audioFramingKey{}, f)
+}
+
+// audioFraming returns the framing for a turn: the connection's, else the
+// config's.
+func (o *Orchestrator) audioFraming(ctx context.Context) AudioFraming {
+	if f, ok := ctx.Value(audioFramingKey{}).(AudioFraming); ok {
+		return f
+	}
+	return o.GetConfig().AudioFraming
+}
+
+// framedAudio wraps a turn's onAudioChunk to deliver whole frames. flush
+// sends what is left at the end of the turn.
+func (o *Orchestrator) framedAudio(ctx context.Context, onAudioChunk func([]byte) error) (send func([]byte) error, flush func() error) {
+	if onAudioChunk == nil {
+		return nil, func() error { return nil }
+	}
+	f := newAudioFramer(o.audioFraming(ctx), o.ttsSampleRate(), onAudioChunk)
+	return f.write, f.flush
+}
+
+// audioFramer cuts 16-bit mono PCM into frames of one size.
+type audioFramer struct {
+	size int // In bytes; 0 passes chunks on as they come
+	pad  bool
+	buf  []byte
+	send func([]byte) error
+}
+
+func newAudioFramer(f AudioFraming, sampleRate int, send func([]byte) error) *audioFramer {
+	return &audioFramer{
+		size: int(int64(sampleRate)*int64(f.FrameDuration())/int64(time.Second)) * 2,
+		pad:  f.Transport == TransportRTP,
+		send: send,
+	}
+}
+
+func (f *audioFramer) write(chunk []byte) error {
+	if f.size <= 0 {
+		return f.send(chunk)
+	}
+	f.buf = append(f.buf, chunk...)
+	for len(f.buf) >= f.size {
+		frame := make([]byte, f.size)
+		copy(frame, f.buf)
+		f.buf = f.buf[f.size:]
+		if err := f.send(frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flush sends the partial frame left over, padded with silence to a whole
+// one over RTP.
+func (f *audioFramer) flush() error {
+	if len(f.buf) == 0 {
+		return nil
+	}
+	frame := append([]byte(nil), f.buf...)
+	f.buf = nil
+	if f.pad {
+		frame = append(frame, make([]byte, f.size-len(frame))...)
+	}
+	return f.send(frame)
+}
@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseAudioFraming(t *testing.T) {
+	cases := map[string]AudioFraming{
+		"rtp":          {Transport: TransportRTP},
+		"HTTP":         {Transport: TransportHTTP},
+		"40ms":         {Frame: 40 * time.Millisecond},
+		"rtp:30ms":     {Transport: TransportRTP, Frame: 30 * time.Millisecond},
+		"websocket:1s": {Transport: TransportWebSocket, Frame: time.Second},
+	}
+	for in, want := range cases {
+		got, err := ParseAudioFraming(in)
+		if err != nil || got != want {
+			t.Errorf("ParseAudioFraming(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"carrier-pigeon", "rtp:fast", "1ms", "5s"} {
+		if _, err := ParseAudioFraming(bad); err == nil {
+			t.Errorf("ParseAudioFraming(%q) should fail", bad)
+		}
+	}
+	if d := (AudioFraming{Transport: TransportRTP}).FrameDuration(); d != 20*time.Millisecond {
+		t.Errorf("RTP frame = %v", d)
+	}
+	if d := (AudioFraming{}).FrameDuration(); d != 0 {
+		t.Errorf("zero framing frame = %v", d)
+	}
+}
+
+func TestAudioFraming_PerConnection(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleRate = 16000
+	cfg.AudioFraming = AudioFraming{Transport: TransportHTTP}
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 9000)}
+	o := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "Hello there."}, tts, nil, cfg, nil)
+
+	chunks := func(ctx context.Context) []int {
+		var sizes []int
+		_, err := o.ProcessTextStream(ctx, o.NewSessionWithDefaults("s"), "hi", func(chunk []byte) error {
+			sizes = append(sizes, len(chunk))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sizes
+	}
+
+	// 200ms at 16kHz is 6400 bytes; the rest follows at the end.
+	if got := chunks(context.Background()); len(got) != 2 || got[0] != 6400 || got[1] != 2600 {
+		t.Errorf("HTTP chunks = %v", got)
+	}
+	// An RTP leg gets 20ms frames, the last padded to a whole frame.
+	rtp := WithAudioFraming(context.Background(), AudioFraming{Transport: TransportRTP})
+	got := chunks(rtp)
+	if len(got) != 15 {
+		t.Fatalf("RTP frames = %v", got)
+	}
+	for _, n := range got {
+		if n != 640 {
+			t.Fatalf("RTP frames = %v", got)
+		}
+	}
+}
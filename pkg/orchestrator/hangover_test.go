@@ -0,0 +1,110 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedVAD replays a fixed sequence of events, one per processed chunk.
+// An empty entry yields no event.
+type scriptedVAD struct {
+	mu       sync.Mutex
+	script   []VADEventType
+	speaking bool
+}
+
+func (v *scriptedVAD) Process(chunk []byte) (*VADEvent, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.script) == 0 {
+		return nil, nil
+	}
+	typ := v.script[0]
+	v.script = v.script[1:]
+	if typ == "" {
+		return nil, nil
+	}
+	switch typ {
+	case VADSpeechStart:
+		v.speaking = true
+	case VADSpeechEnd:
+		v.speaking = false
+	}
+	return &VADEvent{Type: typ, Timestamp: time.Now().UnixMilli()}, nil
+}
+
+func (v *scriptedVAD) Name() string { return "scripted_vad" }
+func (v *scriptedVAD) Reset()       {}
+func (v *scriptedVAD) IsSpeaking() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.speaking
+}
+func (v *scriptedVAD) Clone() VADProvider { return v }
+
+// countEvents drains the stream's events for wait and counts them by type,
+// passing each to see, if given.
+func countEvents(ms *ManagedStream, wait time.Duration, see ...func(OrchestratorEvent)) map[EventType]int {
+	counts := make(map[EventType]int)
+	deadline := time.After(wait)
+	for {
+		select {
+		case ev := <-ms.Events():
+			counts[ev.Type]++
+			for _, f := range see {
+				f(ev)
+			}
+		case <-deadline:
+			return counts
+		}
+	}
+}
+
+func TestManagedStream_HangoverJoinsResumedSpeech(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.SpeechHangover = 150 * time.Millisecond
+	vad := &scriptedVAD{script: []VADEventType{VADSpeechStart, VADSpeechEnd, VADSpeechStart, VADSpeechEnd}}
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, vad, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("hesitant"))
+	defer ms.Close()
+
+	chunk := make([]byte, 64)
+	ms.doWrite(chunk) // start
+	ms.doWrite(chunk) // end: held in hangover
+	time.Sleep(50 * time.Millisecond)
+	ms.doWrite(chunk) // resumes within the hangover
+	ms.doWrite(chunk) // end
+
+	counts := countEvents(ms, 400*time.Millisecond)
+	if counts[UserSpeaking] != 1 {
+		t.Errorf("resumed speech should not start a new turn, got %d UserSpeaking", counts[UserSpeaking])
+	}
+	if counts[UserStopped] != 1 {
+		t.Errorf("expected a single committed utterance, got %d UserStopped", counts[UserStopped])
+	}
+}
+
+func TestManagedStream_HangoverExpires(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.SpeechHangover = 50 * time.Millisecond
+	vad := &scriptedVAD{script: []VADEventType{VADSpeechStart, VADSpeechEnd, VADSpeechStart, VADSpeechEnd}}
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, vad, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("pauses"))
+	defer ms.Close()
+
+	chunk := make([]byte, 64)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+	time.Sleep(150 * time.Millisecond)
+	ms.doWrite(chunk)
+	ms.doWrite(chunk)
+
+	counts := countEvents(ms, 300*time.Millisecond)
+	if counts[UserSpeaking] != 2 || counts[UserStopped] != 2 {
+		t.Errorf("speech after the hangover should be a new turn, got %v", counts)
+	}
+}
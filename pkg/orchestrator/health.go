@@ -0,0 +1,334 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ReadinessCheck reports whether a dependency can currently serve traffic.
+type ReadinessCheck func(ctx context.Context) error
+
+// Warmer is implemented by providers that benefit from being primed before
+// the first turn, e.g. by opening a connection or loading a model.
+type Warmer interface {
+	Warmup(ctx context.Context) error
+}
+
+// HealthChecker is implemented by providers that can cheaply confirm their
+// vendor is reachable and accepts their credentials, without doing billable
+// work. Readiness pings them, at most once per Config.ReadinessCacheTTL.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+// PingHTTP sends a provider's health-check request, reporting anything but
+// a 2xx answer as an error: a RateLimitError for 429. A nil client uses
+// http.DefaultClient.
+func PingHTTP(client *http.Client, provider string, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return NewRateLimitError(provider, resp)
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: health check status %d", provider, resp.StatusCode)
+	}
+	return nil
+}
+
+// ProviderHealth is the outcome of pinging one of the orchestrator's
+// providers.
+type ProviderHealth struct {
+	Role     string        `json:"role"` // "stt", "llm", "tts" or "vad"
+	Provider string        `json:"provider"`
+	Checked  bool          `json:"checked"` // False when the provider isn't a HealthChecker; it is then assumed healthy
+	Error    string        `json:"error,omitempty"`
+	Latency  time.Duration `json:"latency"`
+}
+
+func (h ProviderHealth) Healthy() bool { return h.Error == "" }
+
+// providerRoles returns the orchestrator's providers by role, in pipeline
+// order, leaving out roles that have none.
+func (o *Orchestrator) providerRoles() []struct {
+	role string
+	p    interface{ Name() string }
+} {
+	var roles []struct {
+		role string
+		p    interface{ Name() string }
+	}
+	add := func(role string, p interface{ Name() string }) {
+		if p != nil {
+			roles = append(roles, struct {
+				role string
+				p    interface{ Name() string }
+			}{role, p})
+		}
+	}
+	add("stt", o.stt)
+	add("llm", o.llm)
+	add("tts", o.tts)
+	add("vad", o.vad)
+	return roles
+}
+
+// CheckHealth pings every provider that implements HealthChecker, all at
+// once, and returns their status in pipeline order: STT, LLM, TTS, then the
+// VAD. Roles with no provider are left out.
+func (o *Orchestrator) CheckHealth(ctx context.Context) []ProviderHealth {
+	roles := o.providerRoles()
+	results := make([]ProviderHealth, len(roles))
+	var wg sync.WaitGroup
+	for i, r := range roles {
+		results[i] = ProviderHealth{Role: r.role, Provider: r.p.Name()}
+		hc, ok := r.p.(HealthChecker)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := hc.Ping(ctx)
+			results[i].Checked = true
+			results[i].Latency = time.Since(start)
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// pingAny reports a fallback chain healthy while any of its providers
+// answers a ping. Providers that can't be pinged are skipped; a chain with
+// none that can counts as healthy.
+func pingAny[P interface{ Name() string }](ctx context.Context, providers []P) error {
+	var errs []error
+	for _, p := range providers {
+		hc, ok := any(p).(HealthChecker)
+		if !ok {
+			continue
+		}
+		err := hc.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+	return errors.Join(errs...)
+}
+
+func (f *FallbackSTT) Ping(ctx context.Context) error { return pingAny(ctx, f.providers) }
+func (f *FallbackLLM) Ping(ctx context.Context) error { return pingAny(ctx, f.providers) }
+func (f *FallbackTTS) Ping(ctx context.Context) error { return pingAny(ctx, f.providers) }
+
+const readinessCheckTimeout = 2 * time.Second
+
+type warmState int
+
+const (
+	warmNotRequested warmState = iota
+	warmInProgress
+	warmDone
+	warmFailed
+)
+
+type healthState struct {
+	mu       sync.RWMutex
+	checks   map[string]ReadinessCheck
+	warm     warmState
+	warmErr  error // Why the last warm-up failed
+	draining bool
+
+	probeMu  sync.Mutex        // Held while checks run, so concurrent probes share one run
+	probed   map[string]string // Results of the last run, nil before the first
+	probedOK bool
+	probedAt time.Time
+}
+
+// AddReadinessCheck registers a named check evaluated on every /readyz probe.
+func (o *Orchestrator) AddReadinessCheck(name string, check ReadinessCheck) {
+	o.health.mu.Lock()
+	defer o.health.mu.Unlock()
+	if o.health.checks == nil {
+		o.health.checks = make(map[string]ReadinessCheck)
+	}
+	o.health.checks[name] = check
+}
+
+// Warmup primes every provider implementing Warmer. While it runs the
+// orchestrator reports not ready; callers that never warm up are ready at once.
+// A failed warm-up keeps readiness failing with its error until a later
+// Warmup succeeds.
+func (o *Orchestrator) Warmup(ctx context.Context) error {
+	o.health.mu.Lock()
+	o.health.warm = warmInProgress
+	o.health.mu.Unlock()
+
+	var err error
+	for _, p := range []interface{}{o.stt, o.llm, o.tts} {
+		if w, ok := p.(Warmer); ok {
+			if err = w.Warmup(ctx); err != nil {
+				break
+			}
+		}
+	}
+
+	o.health.mu.Lock()
+	o.health.warm, o.health.warmErr = warmDone, err
+	if err != nil {
+		o.health.warm = warmFailed
+	}
+	o.health.mu.Unlock()
+	return err
+}
+
+// Close puts the orchestrator into draining state: readiness fails so the load
+// balancer stops routing new sessions, while in-flight turns keep running.
+func (o *Orchestrator) Close() error {
+	o.health.mu.Lock()
+	defer o.health.mu.Unlock()
+	o.health.draining = true
+	return nil
+}
+
+func (o *Orchestrator) Draining() bool {
+	o.health.mu.RLock()
+	defer o.health.mu.RUnlock()
+	return o.health.draining
+}
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Readiness runs all checks and returns their results keyed by name. The
+// "warmup" and "draining" entries are always reported, and providers that
+// implement HealthChecker are pinged as "provider:stt" and so on. Check and
+// ping results are reused for Config.ReadinessCacheTTL, so frequent probes
+// don't hammer the vendors; warm-up and draining are always current.
+func (o *Orchestrator) Readiness(ctx context.Context) (bool, map[string]string) {
+	o.health.mu.RLock()
+	warm, warmErr, draining := o.health.warm, o.health.warmErr, o.health.draining
+	o.health.mu.RUnlock()
+
+	ready, checked := o.runReadinessChecks(ctx)
+	results := map[string]string{"warmup": "ok", "draining": "no"}
+	for name, res := range checked {
+		results[name] = res
+	}
+	switch warm {
+	case warmInProgress:
+		ready = false
+		results["warmup"] = "in progress"
+	case warmFailed:
+		ready = false
+		results["warmup"] = "failed: " + warmErr.Error()
+	}
+	if draining {
+		ready = false
+		results["draining"] = "yes"
+	}
+	return ready, results
+}
+
+// runReadinessChecks runs the registered checks and provider pings, or
+// returns the results of the last run while they are fresh.
+func (o *Orchestrator) runReadinessChecks(ctx context.Context) (bool, map[string]string) {
+	ttl := o.GetConfig().ReadinessCacheTTL
+	o.health.probeMu.Lock()
+	defer o.health.probeMu.Unlock()
+	if o.health.probed != nil && ttl > 0 && time.Since(o.health.probedAt) < ttl {
+		return o.health.probedOK, o.health.probed
+	}
+
+	o.health.mu.RLock()
+	checks := make(map[string]ReadinessCheck, len(o.health.checks))
+	for name, c := range o.health.checks {
+		checks[name] = c
+	}
+	o.health.mu.RUnlock()
+	for _, r := range o.providerRoles() {
+		if hc, ok := r.p.(HealthChecker); ok {
+			checks["provider:"+r.role] = hc.Ping
+		}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	ready := true
+	results := make(map[string]string, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check ReadinessCheck) {
+			defer wg.Done()
+			err := check(checkCtx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				ready = false
+				results[name] = err.Error()
+			} else {
+				results[name] = "ok"
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	// A probe that gave up early made its checks fail; that says nothing
+	// about the next one.
+	if ctx.Err() == nil {
+		o.health.probed, o.health.probedOK, o.health.probedAt = results, ready, time.Now()
+	}
+	return ready, results
+}
+
+// HealthHandler serves Kubernetes-style probes:
+//
+//	/livez   200 while the process is up (also served at /healthz)
+//	/readyz  200 when warm, not draining and all readiness checks pass
+func (o *Orchestrator) HealthHandler() http.Handler {
+	mux := http.NewServeMux()
+	live := func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, http.StatusOK, healthResponse{Status: "ok"})
+	}
+	mux.HandleFunc("/livez", live)
+	mux.HandleFunc("/healthz", live)
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ready, results := o.Readiness(r.Context())
+		resp := healthResponse{Status: "ok", Checks: results}
+		code := http.StatusOK
+		if !ready {
+			resp.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+		}
+		writeHealth(w, code, resp)
+	})
+	return mux
+}
+
+func writeHealth(w http.ResponseWriter, code int, resp healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
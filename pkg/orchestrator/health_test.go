@@ -0,0 +1,210 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type warmingTTS struct {
+	MockTTSProvider
+	release chan struct{}
+	warmed  bool
+	err     error
+}
+
+func (w *warmingTTS) Warmup(ctx context.Context) error {
+	<-w.release
+	w.warmed = w.err == nil
+	return w.err
+}
+
+func probe(t *testing.T, h http.Handler, path string) (int, healthResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var resp healthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("invalid JSON from %s: %v", path, err)
+	}
+	return rec.Code, resp
+}
+
+func TestHealthHandler_Lifecycle(t *testing.T) {
+	tts := &warmingTTS{release: make(chan struct{})}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, nil, DefaultConfig(), nil)
+	h := orch.HealthHandler()
+
+	if code, _ := probe(t, h, "/readyz"); code != http.StatusOK {
+		t.Errorf("expected ready before warm-up is requested, got %d", code)
+	}
+
+	done := make(chan error)
+	go func() { done <- orch.Warmup(context.Background()) }()
+	for {
+		if _, r := probe(t, h, "/readyz"); r.Checks["warmup"] == "in progress" {
+			break
+		}
+	}
+	if code, _ := probe(t, h, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected not ready during warm-up, got %d", code)
+	}
+	close(tts.release)
+	if err := <-done; err != nil || !tts.warmed {
+		t.Fatalf("warm-up failed: %v", err)
+	}
+	if code, _ := probe(t, h, "/readyz"); code != http.StatusOK {
+		t.Errorf("expected ready after warm-up, got %d", code)
+	}
+
+	orch.Close()
+	if !orch.Draining() {
+		t.Error("expected Close to start draining")
+	}
+	if code, r := probe(t, h, "/readyz"); code != http.StatusServiceUnavailable || r.Checks["draining"] != "yes" {
+		t.Errorf("expected not ready while draining, got %d %+v", code, r)
+	}
+	if code, _ := probe(t, h, "/livez"); code != http.StatusOK {
+		t.Errorf("liveness must stay OK while draining, got %d", code)
+	}
+}
+
+func TestHealthHandler_FailedWarmup(t *testing.T) {
+	tts := &warmingTTS{release: make(chan struct{}), err: errors.New("no voices")}
+	close(tts.release)
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, nil, DefaultConfig(), nil)
+	h := orch.HealthHandler()
+
+	if err := orch.Warmup(context.Background()); err == nil {
+		t.Fatal("expected warm-up to fail")
+	}
+	if code, r := probe(t, h, "/readyz"); code != http.StatusServiceUnavailable || r.Checks["warmup"] != "failed: no voices" {
+		t.Errorf("expected not ready after a failed warm-up, got %d %+v", code, r)
+	}
+
+	tts.err = nil
+	if err := orch.Warmup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if code, r := probe(t, h, "/readyz"); code != http.StatusOK || r.Checks["warmup"] != "ok" {
+		t.Errorf("expected ready after retrying warm-up, got %d %+v", code, r)
+	}
+}
+
+func TestHealthHandler_ReadinessChecks(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ReadinessCacheTTL = 0
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg, nil)
+	healthy := true
+	orch.AddReadinessCheck("llm", func(ctx context.Context) error {
+		if !healthy {
+			return errors.New("upstream down")
+		}
+		return nil
+	})
+	h := orch.HealthHandler()
+
+	if code, r := probe(t, h, "/readyz"); code != http.StatusOK || r.Checks["llm"] != "ok" {
+		t.Errorf("expected healthy check to pass, got %d %+v", code, r)
+	}
+	healthy = false
+	if code, r := probe(t, h, "/readyz"); code != http.StatusServiceUnavailable || r.Checks["llm"] != "upstream down" {
+		t.Errorf("expected failing check to report unavailable, got %d %+v", code, r)
+	}
+}
+
+func TestReadiness_CachesChecks(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ReadinessCacheTTL = 50 * time.Millisecond
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg, nil)
+	var runs atomic.Int32
+	orch.AddReadinessCheck("llm", func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	orch.Readiness(cancelled)
+	orch.Readiness(context.Background())
+	if ready, _ := orch.Readiness(context.Background()); !ready || runs.Load() != 2 {
+		t.Errorf("expected a cancelled probe's results to be dropped and the next reused, ready %v after %d runs", ready, runs.Load())
+	}
+	orch.Close()
+	if ready, checks := orch.Readiness(context.Background()); ready || checks["draining"] != "yes" {
+		t.Errorf("draining should show at once, got %v", checks)
+	}
+	time.Sleep(60 * time.Millisecond)
+	orch.Readiness(context.Background())
+	if runs.Load() != 3 {
+		t.Errorf("expected the checks to run again after the TTL, got %d runs", runs.Load())
+	}
+}
+
+type pingingTTS struct {
+	MockTTSProvider
+	err error
+}
+
+func (p *pingingTTS) Ping(ctx context.Context) error { return p.err }
+
+func TestCheckHealth(t *testing.T) {
+	tts := &pingingTTS{}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, nil, DefaultConfig(), nil)
+
+	got := orch.CheckHealth(context.Background())
+	if len(got) != 3 || got[0].Role != "stt" || got[1].Role != "llm" || got[2].Role != "tts" {
+		t.Fatalf("health = %+v", got)
+	}
+	// Providers that can't be pinged are reported unchecked but healthy.
+	if got[0].Checked || !got[0].Healthy() || !got[2].Checked || !got[2].Healthy() {
+		t.Errorf("health = %+v", got)
+	}
+
+	tts.err = errors.New("connection refused")
+	if got := orch.CheckHealth(context.Background()); got[2].Healthy() || got[2].Error != "connection refused" {
+		t.Errorf("tts health = %+v", got[2])
+	}
+	ready, checks := orch.Readiness(context.Background())
+	if ready || checks["provider:tts"] != "connection refused" {
+		t.Errorf("ready = %v, checks = %v", ready, checks)
+	}
+	if _, ok := checks["provider:stt"]; ok {
+		t.Errorf("unpingable STT was checked: %v", checks)
+	}
+}
+
+func TestFallbackPing(t *testing.T) {
+	down := &pingingTTS{err: errors.New("down")}
+	up := &pingingTTS{}
+	if err := NewFallbackTTS(down, up).Ping(context.Background()); err != nil {
+		t.Errorf("a chain with a healthy secondary is healthy: %v", err)
+	}
+	up.err = errors.New("also down")
+	if err := NewFallbackTTS(down, up).Ping(context.Background()); err == nil {
+		t.Error("a chain with no healthy provider should fail")
+	}
+	// Providers that can't be pinged don't vouch for the chain.
+	if err := NewFallbackTTS(&MockTTSProvider{}, down).Ping(context.Background()); err == nil {
+		t.Error("a chain whose only pingable provider is down should fail")
+	}
+	if err := NewFallbackTTS(&MockTTSProvider{}).Ping(context.Background()); err != nil {
+		t.Errorf("a chain with nothing to ping is healthy: %v", err)
+	}
+}
+
+func TestCheckHealth_MissingProviders(t *testing.T) {
+	orch := New(nil, &MockLLMProvider{}, nil, nil, DefaultConfig(), nil)
+	got := orch.CheckHealth(context.Background())
+	if len(got) != 1 || got[0].Role != "llm" {
+		t.Errorf("health = %+v, want only the LLM", got)
+	}
+	if ready, checks := orch.Readiness(context.Background()); !ready {
+		t.Errorf("not ready: %v", checks)
+	}
+}
@@ -0,0 +1,218 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// HotCommand is a built-in action run locally when the user says one of its
+// phrases, without a round trip to the LLM.
+type HotCommand string
+
+const (
+	CommandStop         HotCommand = "stop"          // Cancel the response being spoken
+	CommandRepeat       HotCommand = "repeat"        // Speak the last response again
+	CommandRepeatSlower HotCommand = "repeat_slower" // Repeat once, slower, leaving the session's rate alone
+	CommandSlower       HotCommand = "slower"        // Lower the session's speech rate
+	CommandFaster       HotCommand = "faster"        // Raise the session's speech rate
+	CommandStartOver    HotCommand = "start_over"    // Clear the conversation, keeping the system prompt
+)
+
+// HotCommands maps spoken phrases to commands. A transcript triggers a
+// command only when it is exactly one of the phrases, ignoring case,
+// punctuation and a leading or trailing "please" or "okay": "stop" does,
+// "don't stop there" doesn't.
+type HotCommands map[string]HotCommand
+
+// DefaultHotCommands returns English and Spanish phrases for every command.
+func DefaultHotCommands() HotCommands {
+	return HotCommands{
+		"stop":                  CommandStop,
+		"stop talking":          CommandStop,
+		"be quiet":              CommandStop,
+		"para":                  CommandStop,
+		"cállate":               CommandStop,
+		"repeat":                CommandRepeat,
+		"repeat that":           CommandRepeat,
+		"say that again":        CommandRepeat,
+		"come again":            CommandRepeat,
+		"repite":                CommandRepeat,
+		"repítelo":              CommandRepeat,
+		"repeat that slower":    CommandRepeatSlower,
+		"say that again slowly": CommandRepeatSlower,
+		"more slowly":           CommandRepeatSlower,
+		"repítelo más despacio": CommandRepeatSlower,
+		"slower":                CommandSlower,
+		"slow down":             CommandSlower,
+		"speak slower":          CommandSlower,
+		"más despacio":          CommandSlower,
+		"faster":                CommandFaster,
+		"speed up":              CommandFaster,
+		"speak faster":          CommandFaster,
+		"más rápido":            CommandFaster,
+		"start over":            CommandStartOver,
+		"let's start over":      CommandStartOver,
+		"start again":           CommandStartOver,
+		"empecemos de nuevo":    CommandStartOver,
+	}
+}
+
+var commandFillers = map[string]bool{
+	"please": true, "ok": true, "okay": true, "hey": true, "um": true, "uh": true,
+	"por": true, "favor": true, "vale": true,
+}
+
+// Match returns the command a transcript triggers, if any.
+func (h HotCommands) Match(transcript string) (HotCommand, bool) {
+	if len(h) == 0 {
+		return "", false
+	}
+	words := strings.Fields(normalizeWords(transcript))
+	for len(words) > 0 && commandFillers[words[0]] {
+		words = words[1:]
+	}
+	for len(words) > 0 && commandFillers[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	said := strings.Join(words, " ")
+	if said == "" {
+		return "", false
+	}
+	for phrase, cmd := range h {
+		if normalizeWords(phrase) == said {
+			return cmd, true
+		}
+	}
+	return "", false
+}
+
+const (
+	minSpeechRate  = 0.5
+	maxSpeechRate  = 2.0
+	speechRateStep = 0.15
+)
+
+type speechRateCtx struct{}
+
+// WithSpeechRate asks TTS providers to speak at rate times the normal speed.
+func WithSpeechRate(ctx context.Context, rate float64) context.Context {
+	return context.WithValue(ctx, speechRateCtx{}, rate)
+}
+
+// SpeechRateFromContext returns the rate attached to ctx, or 1.
+func SpeechRateFromContext(ctx context.Context) float64 {
+	if rate, ok := ctx.Value(speechRateCtx{}).(float64); ok && rate > 0 {
+		return rate
+	}
+	return 1
+}
+
+// SetSpeechRate sets how fast the session is spoken to, clamped to 0.5-2.
+func (s *ConversationSession) SetSpeechRate(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.speechRate = min(max(rate, minSpeechRate), maxSpeechRate)
+}
+
+// GetSpeechRate returns the session's speech rate; 1 is the voice's normal
+// speed.
+func (s *ConversationSession) GetSpeechRate() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.speechRate <= 0 {
+		return 1
+	}
+	return s.speechRate
+}
+
+// withSpeechRate attaches the session's rate to ctx. A rate the caller
+// already attached wins, and a session whose rate was never set adds none.
+func withSpeechRate(ctx context.Context, session *ConversationSession) context.Context {
+	if _, ok := ctx.Value(speechRateCtx{}).(float64); ok {
+		return ctx
+	}
+	session.mu.RLock()
+	rate := session.speechRate
+	session.mu.RUnlock()
+	if rate <= 0 {
+		return ctx
+	}
+	return WithSpeechRate(ctx, rate)
+}
+
+// applyCommand makes the session-level change of a command.
+func applyCommand(session *ConversationSession, cmd HotCommand) {
+	switch cmd {
+	case CommandSlower:
+		session.SetSpeechRate(session.GetSpeechRate() - speechRateStep)
+	case CommandFaster:
+		session.SetSpeechRate(session.GetSpeechRate() + speechRateStep)
+	case CommandStartOver:
+		session.startOver()
+	}
+}
+
+// startOver forgets the conversation but keeps the leading system messages.
+func (s *ConversationSession) startOver() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Context = append([]Message{}, s.Context[:leadingSystem(s.Context)]...)
+	s.LastUser = ""
+	s.LastAssistant = ""
+	s.pendingUsage = TokenUsage{}
+}
+
+// repeatRate returns the context a repeat is spoken with.
+func repeatRate(ctx context.Context, session *ConversationSession, cmd HotCommand) context.Context {
+	if cmd == CommandRepeatSlower {
+		return WithSpeechRate(ctx, max(session.GetSpeechRate()-speechRateStep, minSpeechRate))
+	}
+	return ctx
+}
+
+// runHotCommand handles a command heard in a ProcessAudio turn. Only the
+// repeats produce audio; stop has nothing to cancel in a request/response
+// turn.
+func (o *Orchestrator) runHotCommand(ctx context.Context, session *ConversationSession, cmd HotCommand, streaming bool, onAudioChunk func([]byte) error, rec *TurnRecording) ([]byte, error) {
+	o.logger.Info("hot command", "sessionID", session.ID, "command", cmd)
+	applyCommand(session, cmd)
+	if cmd != CommandRepeat && cmd != CommandRepeatSlower {
+		return nil, nil
+	}
+	audio, err := o.RepeatLast(repeatRate(ctx, session, cmd), session)
+	if errors.Is(err, ErrNothingToRepeat) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rec.Response = session.lastAssistant()
+	if streaming && onAudioChunk != nil {
+		return nil, onAudioChunk(audio)
+	}
+	return audio, nil
+}
+
+// hotCommand runs a command heard by the stream and reports whether the
+// transcript was one. Commands act even while the bot is speaking, where a
+// one-word utterance would otherwise be ignored as too short to interrupt.
+func (ms *ManagedStream) hotCommand(transcript string) bool {
+	if ms.orch == nil {
+		return false
+	}
+	cmd, ok := ms.orch.GetConfig().HotCommands.Match(transcript)
+	if !ok {
+		return false
+	}
+	ms.emit(HotCommandHeard, cmd)
+	switch cmd {
+	case CommandStop, CommandStartOver:
+		ms.internalInterrupt()
+	case CommandRepeat, CommandRepeatSlower:
+		ms.internalInterrupt()
+		go ms.repeatLast(repeatRate(ms.ctx, ms.session, cmd))
+	}
+	applyCommand(ms.session, cmd)
+	return true
+}
@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHotCommands_Match(t *testing.T) {
+	cmds := DefaultHotCommands()
+	tests := []struct {
+		text string
+		want HotCommand
+		ok   bool
+	}{
+		{"Stop!", CommandStop, true},
+		{"Okay, repeat that please.", CommandRepeat, true},
+		{"Could you slow down", "", false},
+		{"slow down", CommandSlower, true},
+		{"Más despacio, por favor", CommandSlower, true},
+		{"Let's start over.", CommandStartOver, true},
+		{"don't stop there", "", false},
+		{"please", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := cmds.Match(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Match(%q) = %q, %v; want %q, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := HotCommands(nil).Match("stop"); ok {
+		t.Error("nil HotCommands must match nothing")
+	}
+}
+
+type rateTTS struct {
+	MockTTSProvider
+	texts []string
+	rates []float64
+}
+
+func (r *rateTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	r.texts = append(r.texts, text)
+	r.rates = append(r.rates, SpeechRateFromContext(ctx))
+	return []byte(text), nil
+}
+
+func TestProcessAudio_HotCommandsBypassLLM(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HotCommands = DefaultHotCommands()
+	stt := &MockSTTProvider{}
+	tts := &rateTTS{}
+	o := NewWithVAD(stt, &MockLLMProvider{completeErr: errors.New("the LLM must not be called")}, tts, nil, cfg)
+	session := NewConversationSession("u")
+	o.SetSystemPrompt(session, "Be brief.")
+	session.AddMessage(RoleUser, "when do you open")
+	session.AddMessage(RoleAssistant, "We open at nine.")
+
+	stt.transcribeResult = "slower please"
+	if _, audio, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil || audio != nil {
+		t.Fatalf("slower: audio %q, err %v", audio, err)
+	}
+	if rate := session.GetSpeechRate(); rate >= 1 {
+		t.Errorf("expected a lower speech rate, got %v", rate)
+	}
+
+	stt.transcribeResult = "Say that again."
+	_, audio, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil)
+	if err != nil {
+		t.Fatalf("repeat: %v", err)
+	}
+	if string(audio) != "We open at nine." || tts.rates[0] != session.GetSpeechRate() {
+		t.Errorf("expected the last response at the new rate, got %q at %v", audio, tts.rates)
+	}
+	if n := len(session.GetContextCopy()); n != 3 {
+		t.Errorf("commands must not enter the context, got %d messages", n)
+	}
+
+	stt.transcribeResult = "start over"
+	if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+		t.Fatalf("start over: %v", err)
+	}
+	if ctx := session.GetContextCopy(); len(ctx) != 1 || ctx[0].Role != RoleSystem || session.LastAssistant != "" {
+		t.Errorf("expected only the system prompt to remain, got %q", contents(ctx))
+	}
+}
+
+func TestManagedStream_HotCommandStop(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.HotCommands = DefaultHotCommands()
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("u"))
+	defer ms.Close()
+
+	cancelled := false
+	ms.mu.Lock()
+	ms.isSpeaking = true
+	ms.ttsCancel = func() { cancelled = true }
+	ms.mu.Unlock()
+
+	if !ms.hotCommand("stop") {
+		t.Fatal("expected stop to be handled as a command")
+	}
+	if !cancelled {
+		t.Error("stop must cancel playback")
+	}
+	if ms.hotCommand("stop at the pharmacy first") {
+		t.Error("an ordinary sentence must not be a command")
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == HotCommandHeard {
+				if ev.Data != CommandStop {
+					t.Errorf("expected stop, got %v", ev.Data)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("no HotCommandHeard event")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// IdempotencyKeyHeader is the HTTP header providers and webhooks receive the key in.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKeyCtx struct{}
+
+// NewIdempotencyKey returns a random key suitable for one conversational turn.
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithIdempotencyKey attaches a turn's idempotency key to ctx. Retries made
+// with the same context reuse the key, so vendors can deduplicate them.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// IdempotencyKeyFromContext returns the key attached to ctx, or "".
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtx{}).(string)
+	return key
+}
+
+// SetIdempotencyHeader copies the context's key onto an outgoing request.
+func SetIdempotencyHeader(req *http.Request) {
+	if key := IdempotencyKeyFromContext(req.Context()); key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+}
+
+// ensureIdempotencyKey starts a new turn key unless the caller already set one.
+func ensureIdempotencyKey(ctx context.Context) context.Context {
+	if IdempotencyKeyFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithIdempotencyKey(ctx, NewIdempotencyKey())
+}
+
+// scopeIdempotencyKey derives a per-operation key from the turn key, so the
+// STT, LLM and each TTS request of one turn don't collide with each other
+// while a retry of any one of them still maps to the same key.
+func scopeIdempotencyKey(ctx context.Context, parts ...string) context.Context {
+	base := IdempotencyKeyFromContext(ctx)
+	if base == "" {
+		return ctx
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return WithIdempotencyKey(ctx, base+"-"+hex.EncodeToString(sum[:6]))
+}
@@ -0,0 +1,110 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type keyRecordingSTT struct {
+	keys  []string
+	fails int
+}
+
+func (s *keyRecordingSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	s.keys = append(s.keys, IdempotencyKeyFromContext(ctx))
+	if len(s.keys) <= s.fails {
+		return TranscriptionResult{}, &RateLimitError{Provider: s.Name(), RetryAfter: time.Millisecond}
+	}
+	return TranscriptionResult{Text: "what time is it"}, nil
+}
+
+func (s *keyRecordingSTT) Name() string { return "key-stt" }
+
+type keyRecordingLLM struct{ keys []string }
+
+func (l *keyRecordingLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	l.keys = append(l.keys, IdempotencyKeyFromContext(ctx))
+	return "It is noon.", nil
+}
+
+func (l *keyRecordingLLM) Name() string { return "key-llm" }
+
+type keyRecordingTTS struct {
+	MockTTSProvider
+	keys []string
+}
+
+func (t *keyRecordingTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	t.keys = append(t.keys, IdempotencyKeyFromContext(ctx))
+	return []byte{1, 2}, nil
+}
+
+func TestProcessAudio_IdempotencyKeys(t *testing.T) {
+	stt := &keyRecordingSTT{fails: 1}
+	llm := &keyRecordingLLM{}
+	tts := &keyRecordingTTS{}
+
+	cfg := DefaultConfig()
+	cfg.RetryBaseDelay = time.Millisecond
+	orch := New(stt, llm, tts, nil, cfg, nil)
+
+	ctx := WithIdempotencyKey(context.Background(), "turn-1")
+	if _, _, err := orch.ProcessAudio(ctx, NewConversationSession("s"), []byte{1}, false, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stt.keys) != 2 || stt.keys[0] != stt.keys[1] {
+		t.Errorf("expected retries to reuse the same key, got %v", stt.keys)
+	}
+	all := []string{stt.keys[0], llm.keys[0], tts.keys[0]}
+	seen := map[string]bool{}
+	for _, k := range all {
+		if !strings.HasPrefix(k, "turn-1-") {
+			t.Errorf("expected key derived from turn-1, got %q", k)
+		}
+		if seen[k] {
+			t.Errorf("expected distinct keys per stage, got duplicate %q", k)
+		}
+		seen[k] = true
+	}
+}
+
+func TestProcessAudio_GeneratesKey(t *testing.T) {
+	stt := &keyRecordingSTT{}
+	orch := New(stt, &keyRecordingLLM{}, &keyRecordingTTS{}, nil, DefaultConfig(), nil)
+
+	orch.ProcessAudio(context.Background(), NewConversationSession("a"), []byte{1}, false, nil)
+	orch.ProcessAudio(context.Background(), NewConversationSession("b"), []byte{1}, false, nil)
+
+	if len(stt.keys) != 2 || stt.keys[0] == "" || stt.keys[0] == stt.keys[1] {
+		t.Errorf("expected a fresh key per turn, got %v", stt.keys)
+	}
+}
+
+func TestCallTool_ContextKey(t *testing.T) {
+	orch := New(nil, nil, nil, nil, DefaultConfig(), nil)
+
+	var keys []string
+	orch.RegisterToolContext("charge", func(ctx context.Context, args string) (string, error) {
+		keys = append(keys, IdempotencyKeyFromContext(ctx))
+		return "ok", nil
+	})
+
+	ctx := WithIdempotencyKey(context.Background(), "turn-2")
+	orch.callTool(ctx, nil, ToolCallEventData{CallID: "c1", Name: "charge"}, nil)
+	orch.callTool(ctx, nil, ToolCallEventData{CallID: "c1", Name: "charge"}, nil)
+	orch.callTool(ctx, nil, ToolCallEventData{CallID: "c2", Name: "charge"}, nil)
+
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("expected the same call ID to map to the same key, got %v", keys)
+	}
+	if keys[0] == keys[2] {
+		t.Errorf("expected different call IDs to get different keys, got %v", keys)
+	}
+
+	if _, ok, _ := orch.callTool(ctx, nil, ToolCallEventData{Name: "missing"}, nil); ok {
+		t.Error("expected unknown tool to report not found")
+	}
+}
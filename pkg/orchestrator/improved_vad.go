@@ -48,6 +48,8 @@ type ImprovedRMSVAD struct {
 
 	adaptiveMode bool
 	sampleRate   int
+
+	meter vadMeter
 }
 
 func NewImprovedRMSVAD(threshold float64, silenceLimit time.Duration, sampleRate int) *ImprovedRMSVAD {
@@ -181,6 +183,23 @@ func (v *ImprovedRMSVAD) Process(chunk []byte) (*VADEvent, error) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
+	if v.meter.calibRemaining > 0 {
+		rms, _, _ := v.analyze(chunk)
+		v.lastRMS = rms
+		if floor, threshold, done := v.meter.calibrate(chunk, rms); done {
+			v.noiseFloor = floor
+			v.threshold = threshold
+			v.isWarmup = false
+		}
+		return nil, nil
+	}
+
+	event, err := v.process(chunk)
+	v.meter.observe(v.lastRMS, v.isSpeaking, v.consecutiveFrames, event)
+	return event, err
+}
+
+func (v *ImprovedRMSVAD) process(chunk []byte) (*VADEvent, error) {
 	rms, zcr, peak := v.analyze(chunk)
 	v.lastRMS = rms
 	now := time.Now()
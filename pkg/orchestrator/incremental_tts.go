@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// respondIncrementally streams the LLM response and synthesises it one
+// sentence at a time, so the first audio reaches onAudioChunk while the model
+// is still writing. The stages overlap, so rec's TTS timing counts time spent
+// synthesising rather than wall-clock time after the LLM.
+func (o *Orchestrator) respondIncrementally(ctx context.Context, session *ConversationSession, provider StreamingLLMProvider, onAudioChunk func([]byte) error, rec *TurnRecording) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	voice, lang := session.GetCurrentVoice(), session.GetCurrentLanguage()
+
+	var sendErr error
+	sentences := make(chan string, 64)
+	ttsDone := make(chan error, 1)
+	go func() {
+		var err error
+		for sentence := range sentences {
+			if err != nil {
+				continue
+			}
+			start := time.Now()
+			err = o.SynthesizeStream(ctx, sentence, voice, lang, func(chunk []byte) error {
+				if err := onAudioChunk(chunk); err != nil {
+					sendErr = err
+					return err
+				}
+				return nil
+			})
+			rec.Timings.TTS += time.Since(start)
+			if err != nil {
+				cancel() // No point letting the LLM finish
+			}
+		}
+		ttsDone <- err
+	}()
+
+	splitter := o.newSegmenter(ctx, lang)
+	speak := func(sentence string) {
+		if sentence == "" {
+			return
+		}
+		select {
+		case sentences <- sentence:
+		case <-ctx.Done():
+		}
+	}
+
+	progress := turnProgressFrom(ctx)
+	stageStart := time.Now()
+	response, err := o.streamComplete(ctx, session, provider, session.promptContext(ctx), session.GetTools(), func(chunk string) error {
+		progress.replied(chunk)
+		for _, sentence := range splitter.Push(chunk) {
+			speak(sentence)
+		}
+		return nil
+	}, nil)
+	rec.Timings.LLM = time.Since(stageStart)
+	rec.Response = response
+	if err == nil {
+		o.logger.Info("LLM response generated", "sessionID", session.ID, "length", len(response))
+		session.AddMessage("assistant", response)
+		speak(splitter.Flush())
+	}
+	close(sentences)
+	ttsErr := <-ttsDone
+
+	switch {
+	case sendErr != nil:
+		o.logger.Error("failed to send audio chunk", "error", sendErr)
+		return sendErr
+	case ttsErr != nil:
+		o.logger.Error("TTS synthesis failed", "sessionID", session.ID, "error", ttsErr)
+		return fmt.Errorf("%w: %v", ErrTTSFailed, ttsErr)
+	case err != nil:
+		o.logger.Error("LLM generation failed", "sessionID", session.ID, "error", err)
+		return fmt.Errorf("%w: %v", ErrLLMFailed, err)
+	}
+	o.logger.Info("TTS synthesis completed", "sessionID", session.ID)
+	return nil
+}
@@ -0,0 +1,90 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedLLM streams two sentences and only finishes the second once the
+// first has reached TTS.
+type gatedLLM struct {
+	firstSpoken chan struct{}
+}
+
+func (g *gatedLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	return "", errors.New("Complete must not be used when streaming")
+}
+
+func (g *gatedLLM) StreamComplete(ctx context.Context, messages []Message, tools []Tool, onChunk func(string) error, onToolCall func(ToolCallEventData) error) (string, error) {
+	onChunk("The weather is sunny today. ")
+	select {
+	case <-g.firstSpoken:
+	case <-time.After(time.Second):
+		return "", errors.New("first sentence was not synthesised before the response finished")
+	}
+	onChunk("Expect a high of twenty degrees.")
+	return "The weather is sunny today. Expect a high of twenty degrees.", nil
+}
+
+func (g *gatedLLM) Name() string { return "gated" }
+
+type sentenceTTS struct {
+	MockTTSProvider
+	mu     sync.Mutex
+	texts  []string
+	spoken chan struct{}
+}
+
+func (s *sentenceTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	s.mu.Lock()
+	s.texts = append(s.texts, text)
+	first := len(s.texts) == 1
+	s.mu.Unlock()
+	if first {
+		close(s.spoken)
+	}
+	return onChunk([]byte(text))
+}
+
+func TestProcessAudio_SynthesisesSentencesWhileLLMStreams(t *testing.T) {
+	tts := &sentenceTTS{spoken: make(chan struct{})}
+	llm := &gatedLLM{firstSpoken: tts.spoken}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "what's the weather"}, llm, tts, nil, DefaultConfig())
+	session := NewConversationSession("u")
+
+	var chunks []string
+	_, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, true, func(b []byte) error {
+		chunks = append(chunks, string(b))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"The weather is sunny today.", "Expect a high of twenty degrees."}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("audio chunks = %q, want %q", chunks, want)
+	}
+	if session.LastAssistant != "The weather is sunny today. Expect a high of twenty degrees." {
+		t.Errorf("full response not recorded: %q", session.LastAssistant)
+	}
+}
+
+func TestProcessAudio_IncrementalTTSError(t *testing.T) {
+	llm := &MockStreamingLLM{responses: []struct {
+		content   string
+		toolCalls []ToolCallEventData
+	}{{content: "Here you go, all done."}}}
+	tts := &MockTTSProvider{streamErr: errors.New("voice unavailable")}
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 0
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "hello there"}, llm, tts, nil, cfg)
+
+	_, _, err := o.ProcessAudio(context.Background(), NewConversationSession("u"), []byte{1}, true, func([]byte) error { return nil })
+	if !errors.Is(err, ErrTTSFailed) {
+		t.Fatalf("expected ErrTTSFailed, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package orchestrator
+
+import "context"
+
+// LanguageDetector identifies the language spoken in an utterance, with a
+// confidence from 0 to 1. It should be much faster than transcription, e.g.
+// a small language-ID model or the first seconds of a multilingual STT pass.
+type LanguageDetector interface {
+	DetectLanguage(ctx context.Context, audio []byte) (Language, float64, error)
+}
+
+// LanguageDetection switches a session to the language its user is
+// speaking, for deployments such as kiosks where each new user may speak a
+// different one. The detector runs alongside transcription; when it hears a
+// language other than the session's with at least MinConfidence, the
+// session's language, and its voice when Voices has one, are switched
+// before the LLM and TTS run.
+type LanguageDetection struct {
+	Detector      LanguageDetector
+	MinConfidence float64            // Defaults to 0.8
+	Voices        map[Language]Voice // Voice to switch to with each language; missing keeps the current voice
+	Retranscribe  bool               // Transcribe again in the detected language, for STT that needs the language up front
+}
+
+func (d LanguageDetection) minConfidence() float64 {
+	if d.MinConfidence <= 0 {
+		return 0.8
+	}
+	return d.MinConfidence
+}
+
+// transcribeDetecting transcribes audio in the session's language while
+// Config.LanguageDetection, if set, listens for a different one. It returns
+// the language switched to, or "" if the session's was kept.
+func (o *Orchestrator) transcribeDetecting(ctx context.Context, session *ConversationSession, audio []byte) (TranscriptionResult, Language, error) {
+	lang := session.GetCurrentLanguage()
+	d := o.GetConfig().LanguageDetection
+	if d.Detector == nil {
+		res, err := o.Transcribe(ctx, audio, lang)
+		return res, "", err
+	}
+
+	type detection struct {
+		lang       Language
+		confidence float64
+		err        error
+	}
+	detected := make(chan detection, 1)
+	dCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		l, c, err := d.Detector.DetectLanguage(dCtx, audio)
+		detected <- detection{l, c, err}
+	}()
+
+	res, err := o.Transcribe(ctx, audio, lang)
+	if err != nil {
+		return res, "", err
+	}
+	det := <-detected
+	if det.err != nil {
+		o.logger.Warn("language detection failed", "sessionID", session.ID, "error", det.err)
+		return res, "", nil
+	}
+	if det.lang == "" || det.lang == lang || det.confidence < d.minConfidence() {
+		return res, "", nil
+	}
+
+	session.switchLanguage(det.lang, d.Voices[det.lang])
+	o.logger.Info("switched session language", "sessionID", session.ID, "from", lang, "to", det.lang, "confidence", det.confidence)
+	if d.Retranscribe {
+		if again, err := o.Transcribe(ctx, audio, det.lang); err == nil {
+			res = again
+		}
+	}
+	return res, det.lang, nil
+}
+
+// switchLanguage sets the session's language and, if voice is set, its voice.
+func (s *ConversationSession) switchLanguage(lang Language, voice Voice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CurrentLanguage = lang
+	if voice != "" {
+		s.CurrentVoice = voice
+	}
+}
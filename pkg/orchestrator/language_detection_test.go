@@ -0,0 +1,103 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fixedDetector struct {
+	lang       Language
+	confidence float64
+	err        error
+}
+
+func (d fixedDetector) DetectLanguage(ctx context.Context, audio []byte) (Language, float64, error) {
+	return d.lang, d.confidence, d.err
+}
+
+// langSTT transcribes in whichever language it is asked for.
+type langSTT struct {
+	mu    sync.Mutex
+	langs []Language
+}
+
+func (s *langSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	s.mu.Lock()
+	s.langs = append(s.langs, lang)
+	s.mu.Unlock()
+	if lang == LanguageEs {
+		return TranscriptionResult{Text: "¿dónde está el baño?"}, nil
+	}
+	return TranscriptionResult{Text: "the one this bunny"}, nil
+}
+
+func (s *langSTT) Name() string { return "lang" }
+
+func TestProcessAudio_SwitchesToDetectedLanguage(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LanguageDetection = LanguageDetection{
+		Detector:     fixedDetector{lang: LanguageEs, confidence: 0.95},
+		Voices:       map[Language]Voice{LanguageEs: VoiceM1},
+		Retranscribe: true,
+	}
+	stt := &langSTT{}
+	o := NewWithVAD(stt, &MockLLMProvider{completeResult: "Al fondo a la derecha."}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, cfg)
+	session := NewConversationSession("kiosk")
+
+	transcript, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transcript != "¿dónde está el baño?" || len(stt.langs) != 2 || stt.langs[1] != LanguageEs {
+		t.Errorf("expected a second pass in Spanish, got %q after %v", transcript, stt.langs)
+	}
+	if session.GetCurrentLanguage() != LanguageEs || session.GetCurrentVoice() != VoiceM1 {
+		t.Errorf("session not switched: %s / %s", session.GetCurrentLanguage(), session.GetCurrentVoice())
+	}
+}
+
+func TestProcessAudio_KeepsLanguageWhenUnsure(t *testing.T) {
+	for name, det := range map[string]fixedDetector{
+		"low confidence": {lang: LanguageEs, confidence: 0.5},
+		"error":          {err: errors.New("model unavailable")},
+	} {
+		cfg := DefaultConfig()
+		cfg.LanguageDetection = LanguageDetection{Detector: det}
+		o := NewWithVAD(&langSTT{}, &MockLLMProvider{completeResult: "Sure."}, &MockTTSProvider{}, nil, cfg)
+		session := NewConversationSession("kiosk")
+		if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if session.GetCurrentLanguage() != LanguageEn {
+			t.Errorf("%s: language switched to %s", name, session.GetCurrentLanguage())
+		}
+	}
+}
+
+func TestManagedStream_EmitsLanguageChanged(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.LanguageDetection = LanguageDetection{Detector: fixedDetector{lang: LanguageEs, confidence: 0.9}}
+	o := NewWithVAD(&langSTT{}, &MockLLMProvider{completeResult: "Claro."}, &MockTTSProvider{}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("kiosk"))
+	defer ms.Close()
+
+	go ms.runBatchPipeline(make([]byte, 3200))
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == LanguageChanged {
+				if ev.Data.(Language) != LanguageEs {
+					t.Errorf("got %v", ev.Data)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("no LanguageChanged event")
+		}
+	}
+}
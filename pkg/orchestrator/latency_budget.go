@@ -0,0 +1,114 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+)
+
+// LatencyBudget sets how long each stage of a turn may take before the
+// orchestrator reacts: it logs a warning, calls OnExceeded and, on a
+// ManagedStream, emits LatencyBudgetExceeded. When the LLM overruns before
+// the bot has said anything, a stream also speaks Filler so the caller
+// isn't left in silence. The stage itself carries on; OnExceeded is the
+// place to fail over, e.g. by lowering a provider's weight for later turns.
+// A zero budget is not enforced.
+type LatencyBudget struct {
+	STT           time.Duration // Whole transcription
+	LLM           time.Duration // To the first token or tool call when streaming
+	TTSFirstChunk time.Duration // To the first audio of each synthesis
+	Filler        string        // E.g. "One moment…"; "" disables
+	OnExceeded    func(BudgetExceeded)
+}
+
+// BudgetExceeded reports a stage that overran its LatencyBudget. It is sent
+// as soon as the budget runs out, while the stage is still running.
+type BudgetExceeded struct {
+	SessionID string        `json:"session_id,omitempty"`
+	Stage     Stage         `json:"stage"`
+	Budget    time.Duration `json:"budget"`
+}
+
+func (b LatencyBudget) limit(stage Stage) time.Duration {
+	switch stage {
+	case StageSTT:
+		return b.STT
+	case StageLLM:
+		return b.LLM
+	case StageTTS:
+		return b.TTSFirstChunk
+	}
+	return 0
+}
+
+// budgetWatch identifies the session a context's stages run for and how it
+// reacts to an overrun or to a fallback chain passing a provider over.
+type budgetWatch struct {
+	sessionID string
+	exceeded  func(context.Context, BudgetExceeded) // Given the stage's context; may be nil
+	fellBack  func(FallbackUsed)                    // May be nil
+}
+
+type budgetWatchKey struct{}
+
+func withBudgetWatch(ctx context.Context, w budgetWatch) context.Context {
+	return context.WithValue(ctx, budgetWatchKey{}, w)
+}
+
+// startBudget starts the clock on a stage. Call stop once the stage has
+// produced its first output; calling it again is harmless.
+func (o *Orchestrator) startBudget(ctx context.Context, stage Stage) (stop func()) {
+	cfg := o.GetConfig().LatencyBudget
+	budget := cfg.limit(stage)
+	if budget <= 0 {
+		return func() {}
+	}
+	watch, _ := ctx.Value(budgetWatchKey{}).(budgetWatch)
+	t := time.AfterFunc(budget, func() {
+		if ctx.Err() != nil {
+			return
+		}
+		b := BudgetExceeded{SessionID: watch.sessionID, Stage: stage, Budget: budget}
+		o.logger.Warn("latency budget exceeded", "sessionID", b.SessionID, "stage", stage, "budget", budget)
+		if cfg.OnExceeded != nil {
+			cfg.OnExceeded(b)
+		}
+		if watch.exceeded != nil {
+			watch.exceeded(ctx, b)
+		}
+	})
+	return func() { t.Stop() }
+}
+
+func (ms *ManagedStream) budgetExceeded(ctx context.Context, b BudgetExceeded) {
+	ms.emit(LatencyBudgetExceeded, b)
+	if b.Stage == StageLLM && gated(ctx, FlagFiller) {
+		go ms.sayFiller()
+	}
+}
+
+// sayFiller speaks the budget's filler phrase if the bot is still waiting on
+// the LLM and has said nothing of its reply.
+func (ms *ManagedStream) sayFiller() {
+	filler := ms.orch.GetConfig().LatencyBudget.Filler
+	if filler == "" {
+		return
+	}
+	ms.mu.Lock()
+	gen := ms.payloadGen
+	waiting := ms.isThinking && !ms.isSpeaking
+	ms.mu.Unlock()
+	if !waiting {
+		return
+	}
+
+	audio, err := ms.orch.Synthesize(ms.ctx, filler, ms.session.GetCurrentVoice(), ms.session.GetCurrentLanguage())
+	if err != nil || len(audio) == 0 {
+		return
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.isClosed || gen != ms.payloadGen || !ms.isThinking || ms.isSpeaking || ms.userInterrupting {
+		return
+	}
+	ms.sendAudioLocked(audio, gen)
+}
@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type slowSTT struct{ delay time.Duration }
+
+func (s slowSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	time.Sleep(s.delay)
+	return TranscriptionResult{Text: "what time do you open"}, nil
+}
+
+func (s slowSTT) Name() string { return "slow" }
+
+type slowLLM struct{ delay time.Duration }
+
+func (s slowLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	select {
+	case <-time.After(s.delay):
+		return "We open at nine.", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (s slowLLM) Name() string { return "slow" }
+
+func TestProcessAudio_ReportsExceededBudget(t *testing.T) {
+	var mu sync.Mutex
+	var got []BudgetExceeded
+	cfg := DefaultConfig()
+	cfg.LatencyBudget = LatencyBudget{
+		STT: 20 * time.Millisecond,
+		LLM: time.Second,
+		OnExceeded: func(b BudgetExceeded) {
+			mu.Lock()
+			got = append(got, b)
+			mu.Unlock()
+		},
+	}
+	o := NewWithVAD(slowSTT{delay: 100 * time.Millisecond}, &MockLLMProvider{completeResult: "Nine."}, &MockTTSProvider{}, nil, cfg)
+
+	if _, _, err := o.ProcessAudio(context.Background(), NewConversationSession("shop"), []byte{1}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0].Stage != StageSTT || got[0].SessionID != "shop" || got[0].Budget != 20*time.Millisecond {
+		t.Errorf("expected only the STT overrun, got %+v", got)
+	}
+}
+
+func TestManagedStream_SpeaksFillerWhenLLMIsLate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.LatencyBudget = LatencyBudget{LLM: 50 * time.Millisecond, Filler: "One moment."}
+	o := NewWithVAD(&MockSTTProvider{}, slowLLM{delay: 300 * time.Millisecond}, &MockTTSProvider{synthesizeResult: []byte{7, 7}}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("shop"))
+	defer ms.Close()
+
+	go ms.runLLMAndTTS(ms.ctx, "what time do you open")
+	var exceeded, filler bool
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev := <-ms.Events():
+			switch ev.Type {
+			case LatencyBudgetExceeded:
+				exceeded = ev.Data.(BudgetExceeded).Stage == StageLLM
+			case AudioChunk:
+				filler = true
+			case BotResponse:
+				if !exceeded || !filler {
+					t.Errorf("reply arrived before the filler (exceeded=%v, filler=%v)", exceeded, filler)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("no reply")
+		}
+	}
+}
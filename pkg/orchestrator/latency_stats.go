@@ -0,0 +1,228 @@
+package orchestrator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the latency histograms. A last
+// bucket, past the final bound, holds everything slower.
+var LatencyBuckets = []time.Duration{
+	50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond,
+	300 * time.Millisecond, 500 * time.Millisecond, 750 * time.Millisecond,
+	time.Second, 1500 * time.Millisecond, 2 * time.Second,
+	3 * time.Second, 5 * time.Second, 10 * time.Second,
+}
+
+// LatencyKey is what latencies are broken down by. Model is filled in for
+// providers with a Model() string method. Voice is set for TTS only, and
+// Language for STT and TTS.
+type LatencyKey struct {
+	Stage    Stage    `json:"stage"`
+	Provider string   `json:"provider"`
+	Model    string   `json:"model,omitempty"`
+	Voice    Voice    `json:"voice,omitempty"`
+	Language Language `json:"language,omitempty"`
+}
+
+// LatencyHistogram is the distribution of provider latencies for one key:
+// the time to the first audio or text of a streaming call, or to the
+// result of any other. Each retry attempt is timed on its own, and
+// responses served by middleware, such as the cache, are not timed at all.
+type LatencyHistogram struct {
+	LatencyKey
+	Count   int           `json:"count"`
+	Sum     time.Duration `json:"sum"`
+	Buckets []int         `json:"buckets"` // Per LatencyBuckets, plus one for slower calls
+	Errors  int           `json:"errors"`  // Calls that failed before any output; not in Count
+}
+
+// Mean returns the average latency.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile estimates the q-th quantile (0-1) by interpolating within its
+// bucket. Calls slower than the last bound count as that bound.
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := q * float64(h.Count)
+	seen := 0
+	for i, n := range h.Buckets {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+		if i == len(LatencyBuckets) {
+			break
+		}
+		var lower time.Duration
+		if i > 0 {
+			lower = LatencyBuckets[i-1]
+		}
+		frac := (rank - float64(seen)) / float64(n)
+		return lower + time.Duration(frac*float64(LatencyBuckets[i]-lower))
+	}
+	return LatencyBuckets[len(LatencyBuckets)-1]
+}
+
+type latencyState struct {
+	mu    sync.Mutex
+	hists map[LatencyKey]*LatencyHistogram
+}
+
+func (o *Orchestrator) observeLatency(key LatencyKey, d time.Duration, failed bool) {
+	o.latency.mu.Lock()
+	defer o.latency.mu.Unlock()
+	if o.latency.hists == nil {
+		o.latency.hists = make(map[LatencyKey]*LatencyHistogram)
+	}
+	h := o.latency.hists[key]
+	if h == nil {
+		h = &LatencyHistogram{LatencyKey: key, Buckets: make([]int, len(LatencyBuckets)+1)}
+		o.latency.hists[key] = h
+	}
+	if failed {
+		h.Errors++
+		return
+	}
+	i, _ := slices.BinarySearch(LatencyBuckets, d)
+	h.Buckets[i]++
+	h.Count++
+	h.Sum += d
+}
+
+// LatencyHistograms returns a copy of every histogram, ordered by stage,
+// provider, model, voice and language.
+func (o *Orchestrator) LatencyHistograms() []LatencyHistogram {
+	o.latency.mu.Lock()
+	out := make([]LatencyHistogram, 0, len(o.latency.hists))
+	for _, h := range o.latency.hists {
+		c := *h
+		c.Buckets = slices.Clone(h.Buckets)
+		out = append(out, c)
+	}
+	o.latency.mu.Unlock()
+	slices.SortFunc(out, func(a, b LatencyHistogram) int {
+		return cmp.Or(
+			cmp.Compare(a.Stage, b.Stage),
+			cmp.Compare(a.Provider, b.Provider),
+			cmp.Compare(a.Model, b.Model),
+			cmp.Compare(a.Voice, b.Voice),
+			cmp.Compare(a.Language, b.Language),
+		)
+	})
+	return out
+}
+
+// ResetLatencyHistograms drops every histogram, e.g. after changing a
+// default voice to measure it afresh.
+func (o *Orchestrator) ResetLatencyHistograms() {
+	o.latency.mu.Lock()
+	defer o.latency.mu.Unlock()
+	o.latency.hists = nil
+}
+
+// LatencyHandler serves the histograms in the Prometheus text format, as
+// lokutor_provider_latency_seconds with a lokutor_provider_errors_total
+// counter beside it.
+func (o *Orchestrator) LatencyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Header().Set("Cache-Control", "no-store")
+		var b strings.Builder
+		hists := o.LatencyHistograms()
+		b.WriteString("# HELP lokutor_provider_latency_seconds Time to a provider's first output.\n")
+		b.WriteString("# TYPE lokutor_provider_latency_seconds histogram\n")
+		for _, h := range hists {
+			labels := h.labels()
+			cum := 0
+			for i, n := range h.Buckets {
+				cum += n
+				le := "+Inf"
+				if i < len(LatencyBuckets) {
+					le = fmt.Sprint(LatencyBuckets[i].Seconds())
+				}
+				fmt.Fprintf(&b, "lokutor_provider_latency_seconds_bucket{%s,le=%q} %d\n", labels, le, cum)
+			}
+			fmt.Fprintf(&b, "lokutor_provider_latency_seconds_sum{%s} %g\n", labels, h.Sum.Seconds())
+			fmt.Fprintf(&b, "lokutor_provider_latency_seconds_count{%s} %d\n", labels, h.Count)
+		}
+		b.WriteString("# HELP lokutor_provider_errors_total Provider calls that failed before any output.\n")
+		b.WriteString("# TYPE lokutor_provider_errors_total counter\n")
+		for _, h := range hists {
+			fmt.Fprintf(&b, "lokutor_provider_errors_total{%s} %d\n", h.labels(), h.Errors)
+		}
+		w.Write([]byte(b.String()))
+	})
+}
+
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (k LatencyKey) labels() string {
+	return fmt.Sprintf(`stage="%s",provider="%s",model="%s",voice="%s",language="%s"`,
+		promEscaper.Replace(string(k.Stage)), promEscaper.Replace(k.Provider), promEscaper.Replace(k.Model),
+		promEscaper.Replace(string(k.Voice)), promEscaper.Replace(string(k.Language)))
+}
+
+// providerModel returns the model a provider reports, if it does.
+func providerModel(p any) string {
+	if m, ok := p.(interface{ Model() string }); ok {
+		return m.Model()
+	}
+	return ""
+}
+
+// stageTimer times one provider call for the latency histograms.
+type stageTimer struct {
+	o     *Orchestrator
+	key   LatencyKey
+	start time.Time
+
+	mu    sync.Mutex
+	first time.Duration
+}
+
+func (o *Orchestrator) timeStage(stage Stage, provider interface{ Name() string }, voice Voice, lang Language) *stageTimer {
+	return &stageTimer{
+		o:     o,
+		key:   LatencyKey{Stage: stage, Provider: provider.Name(), Model: providerModel(provider), Voice: voice, Language: lang},
+		start: time.Now(),
+	}
+}
+
+// output marks the call's first output; later calls do nothing.
+func (t *stageTimer) output() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.first == 0 {
+		t.first = max(time.Since(t.start), 1)
+	}
+}
+
+// done records the call. A call cancelled before any output, such as one
+// the user barged in on, says nothing about the provider and is dropped.
+func (t *stageTimer) done(ctx context.Context, err error) {
+	t.mu.Lock()
+	first := t.first
+	t.mu.Unlock()
+	switch {
+	case first > 0:
+		t.o.observeLatency(t.key, first, false)
+	case err == nil:
+		t.o.observeLatency(t.key, time.Since(t.start), false)
+	case ctx.Err() == nil:
+		t.o.observeLatency(t.key, 0, true)
+	}
+}
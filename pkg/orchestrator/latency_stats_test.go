@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// voiceDelayTTS is slower for some voices than others.
+type voiceDelayTTS struct {
+	MockTTSProvider
+	delay map[Voice]time.Duration
+}
+
+func (v *voiceDelayTTS) Model() string { return "v2" }
+
+func (v *voiceDelayTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	if v.streamErr != nil {
+		return v.streamErr
+	}
+	time.Sleep(v.delay[voice])
+	if err := onChunk([]byte{0, 0}); err != nil {
+		return err
+	}
+	// Time after the first chunk does not count.
+	time.Sleep(v.delay[voice])
+	return onChunk([]byte{0, 0})
+}
+
+func TestLatencyHistograms(t *testing.T) {
+	tts := &voiceDelayTTS{delay: map[Voice]time.Duration{VoiceM1: 120 * time.Millisecond}}
+	o := New(&MockSTTProvider{}, &MockLLMProvider{}, tts, nil, DefaultConfig(), nil)
+	ctx := context.Background()
+	for range 3 {
+		for _, voice := range []Voice{VoiceF1, VoiceM1} {
+			if err := o.SynthesizeStream(ctx, "hello", voice, LanguageEn, func([]byte) error { return nil }); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	tts.streamErr = errors.New("down")
+	tts.delay = nil
+	o.SynthesizeStream(ctx, "hello", VoiceF1, LanguageEn, func([]byte) error { return nil })
+
+	hists := o.LatencyHistograms()
+	if len(hists) != 2 {
+		t.Fatalf("histograms = %+v", hists)
+	}
+	fast, slow := hists[0], hists[1]
+	if fast.Voice != VoiceF1 || slow.Voice != VoiceM1 || fast.Model != "v2" || fast.Language != LanguageEn || fast.Stage != StageTTS {
+		t.Fatalf("keys = %+v, %+v", fast.LatencyKey, slow.LatencyKey)
+	}
+	if fast.Count != 3 || slow.Count != 3 {
+		t.Errorf("counts = %d, %d", fast.Count, slow.Count)
+	}
+	if fast.Mean() > 50*time.Millisecond {
+		t.Errorf("F1 mean = %v", fast.Mean())
+	}
+	if slow.Mean() < 120*time.Millisecond || slow.Mean() > 240*time.Millisecond {
+		t.Errorf("M1 mean = %v, want the time to the first chunk", slow.Mean())
+	}
+	if p := slow.Quantile(0.5); p < 100*time.Millisecond || p > 200*time.Millisecond {
+		t.Errorf("M1 median = %v", p)
+	}
+	if fast.Errors == 0 || slow.Errors != 0 {
+		t.Errorf("errors = %d, %d", fast.Errors, slow.Errors)
+	}
+
+	rec := httptest.NewRecorder()
+	o.LatencyHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`lokutor_provider_latency_seconds_count{stage="tts",provider="MockTTS",model="v2",voice="M1",language="en"} 3`,
+		`lokutor_provider_latency_seconds_bucket{stage="tts",provider="MockTTS",model="v2",voice="M1",language="en",le="+Inf"} 3`,
+		`lokutor_provider_latency_seconds_bucket{stage="tts",provider="MockTTS",model="v2",voice="M1",language="en",le="0.1"} 0`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics missing %s:\n%s", want, body)
+		}
+	}
+
+	o.ResetLatencyHistograms()
+	if len(o.LatencyHistograms()) != 0 {
+		t.Error("reset should drop the histograms")
+	}
+}
+
+func TestLatencyHistogram_Quantile(t *testing.T) {
+	h := LatencyHistogram{Count: 4, Buckets: make([]int, len(LatencyBuckets)+1)}
+	h.Buckets[1] = 2 // 50-100ms
+	h.Buckets[2] = 2 // 100-200ms
+	if got := h.Quantile(0.5); got != 100*time.Millisecond {
+		t.Errorf("median = %v", got)
+	}
+	if got := h.Quantile(0.75); got != 150*time.Millisecond {
+		t.Errorf("p75 = %v", got)
+	}
+	h.Buckets[len(LatencyBuckets)] = 4
+	h.Count = 8
+	if got := h.Quantile(0.99); got != LatencyBuckets[len(LatencyBuckets)-1] {
+		t.Errorf("p99 past the last bound = %v", got)
+	}
+}
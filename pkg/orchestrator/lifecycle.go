@@ -0,0 +1,106 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+)
+
+// SetLifecycleLines sets what streams serving the session say when they open
+// and when they end, overriding Config.Greeting and Config.Closing. An empty
+// line keeps the config's.
+func (s *ConversationSession) SetLifecycleLines(greeting, closing string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.greeting = greeting
+	s.closing = closing
+}
+
+func (ms *ManagedStream) greetingLine() string {
+	ms.session.mu.RLock()
+	line := ms.session.greeting
+	ms.session.mu.RUnlock()
+	if line == "" {
+		line = ms.orch.GetConfig().Greeting
+	}
+	return line
+}
+
+func (ms *ManagedStream) closingLine() string {
+	ms.session.mu.RLock()
+	line := ms.session.closing
+	ms.session.mu.RUnlock()
+	if line == "" {
+		line = ms.orch.GetConfig().Closing
+	}
+	return line
+}
+
+// greet opens a bot-first stream with the configured greeting, spoken as
+// written. Without one the LLM is given a hello to open with.
+func (ms *ManagedStream) greet() {
+	if line := ms.greetingLine(); line != "" {
+		ms.session.AddMessage("assistant", line)
+		ms.emit(BotResponse, line)
+		ms.speakText(ms.ctx, line)
+		return
+	}
+
+	// Add greeting to context first so LLM knows what it's saying
+	greeting := "Hello!"
+	if ms.orch.GetConfig().Language == LanguageEs {
+		greeting = "¡Hola!"
+	}
+	ms.session.AddMessage("assistant", greeting)
+	ms.runLLMAndTTS(ms.ctx, greeting)
+}
+
+// End says the closing line, if one is configured, and closes the stream once
+// the bot's audio has had time to play. It waits for a gap in the
+// conversation first, like Say. The stream is closed even if ctx ends before
+// the line is spoken.
+func (ms *ManagedStream) End(ctx context.Context) error {
+	defer ms.Close()
+	if ms.orch == nil || ms.session == nil {
+		return nil
+	}
+	line := ms.closingLine()
+	if line == "" {
+		return ms.waitForPlayback(ctx)
+	}
+	if err := ms.waitForGap(ctx); err != nil {
+		return err
+	}
+	ms.session.AddMessage("assistant", line)
+	ms.emit(BotResponse, line)
+	ms.speakText(ctx, line)
+	return ms.waitForPlayback(ctx)
+}
+
+// waitForPlayback returns once the bot audio sent so far should have played.
+func (ms *ManagedStream) waitForPlayback(ctx context.Context) error {
+	ms.mu.Lock()
+	remaining := time.Until(ms.playbackEnd)
+	ms.mu.Unlock()
+	select {
+	case <-time.After(remaining):
+	case <-ctx.Done():
+	case <-ms.ctx.Done():
+	}
+	return ctx.Err()
+}
+
+// silenceExhausted counts a silence timeout and reports whether the user has
+// now ignored Config.MaxSilencePrompts reprompts in a row.
+func (ms *ManagedStream) silenceExhausted() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.orch == nil {
+		return false
+	}
+	limit := ms.orch.config.MaxSilencePrompts
+	if limit > 0 && ms.silencePrompts >= limit {
+		return true
+	}
+	ms.silencePrompts++
+	return false
+}
@@ -0,0 +1,81 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// botLines returns the BotResponse texts of a stream until its events
+// channel closes or the timeout passes.
+func botLines(ms *ManagedStream, timeout time.Duration) (lines []string, closed bool) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case ev, ok := <-ms.Events():
+			if !ok {
+				return lines, true
+			}
+			if ev.Type == BotResponse {
+				lines = append(lines, ev.Data.(string))
+			}
+		case <-deadline:
+			return lines, false
+		}
+	}
+}
+
+func TestManagedStream_ConfiguredGreeting(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Greeting = "Thanks for calling Acme."
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeErr: errors.New("the LLM must not be called")}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, cfg)
+	session := NewConversationSession("u")
+	ApplyPersona(o, session, Persona{Greeting: "Hi, this is Ana from Acme."})
+	ms := o.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	lines, _ := botLines(ms, time.Second)
+	if len(lines) != 1 || lines[0] != "Hi, this is Ana from Acme." {
+		t.Fatalf("expected the persona's greeting to win over the config's, got %q", lines)
+	}
+	if session.LastAssistant != lines[0] {
+		t.Errorf("greeting not added to the conversation: %q", session.LastAssistant)
+	}
+}
+
+func TestManagedStream_EndSaysClosing(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.Closing = "Goodbye!"
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, cfg)
+	session := NewConversationSession("u")
+	ms := o.NewManagedStream(context.Background(), session)
+
+	go ms.End(context.Background())
+	lines, closed := botLines(ms, time.Second)
+	if !closed || len(lines) != 1 || lines[0] != "Goodbye!" {
+		t.Fatalf("expected the closing and then the end of the stream, got %q (closed %v)", lines, closed)
+	}
+	if _, ok := o.StreamFor(session.ID); ok {
+		t.Error("ended stream is still registered")
+	}
+}
+
+func TestManagedStream_EndsAfterUnansweredReprompts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.SilenceTimeout = 50 * time.Millisecond
+	cfg.MaxSilencePrompts = 1
+	cfg.Closing = "I'll let you go. Bye!"
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: "Are you still there?"}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("u"))
+
+	lines, closed := botLines(ms, 2*time.Second)
+	if !closed {
+		t.Fatalf("stream still open after the reprompt went unanswered: %q", lines)
+	}
+	if len(lines) != 2 || lines[0] != "Are you still there?" || lines[1] != cfg.Closing {
+		t.Errorf("expected one reprompt then the closing, got %q", lines)
+	}
+}
@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"sync"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+// LoudnessNormalization evens out output levels across TTS providers and
+// voices. Loudness is measured per provider and voice over everything they
+// have synthesised, so after the first few seconds of a voice each response
+// starts at the right level instead of being corrected as it plays.
+type LoudnessNormalization struct {
+	Enabled    bool
+	TargetLUFS float64 // Defaults to -16, typical for speech on phones and laptops
+	PeakDBFS   float64 // Ceiling for sample peaks; defaults to -1
+}
+
+func (l LoudnessNormalization) withDefaults() LoudnessNormalization {
+	if l.TargetLUFS == 0 {
+		l.TargetLUFS = -16
+	}
+	if l.PeakDBFS == 0 {
+		l.PeakDBFS = -1
+	}
+	return l
+}
+
+type loudnessState struct {
+	mu     sync.Mutex
+	meters map[string]*audio.LoudnessMeter
+}
+
+// measureLoudness adds chunk to the running measurement for key and returns
+// the loudness measured so far.
+func (o *Orchestrator) measureLoudness(key string, chunk []byte) float64 {
+	o.loudness.mu.Lock()
+	defer o.loudness.mu.Unlock()
+	if o.loudness.meters == nil {
+		o.loudness.meters = make(map[string]*audio.LoudnessMeter)
+	}
+	m, ok := o.loudness.meters[key]
+	if !ok {
+		m = audio.NewLoudnessMeter(o.ttsSampleRate())
+		o.loudness.meters[key] = m
+	}
+	m.Write(chunk)
+	return m.Loudness()
+}
+
+// levelStage normalises the audio of one synthesis request.
+type levelStage struct {
+	o    *Orchestrator
+	key  string
+	norm *audio.Normalizer
+}
+
+// newLevelStage returns nil when normalisation is off.
+func (o *Orchestrator) newLevelStage(provider string, voice Voice) *levelStage {
+	cfg := o.GetConfig().Loudness
+	if !cfg.Enabled {
+		return nil
+	}
+	cfg = cfg.withDefaults()
+	l := &levelStage{o: o, key: provider + "/" + string(voice), norm: audio.NewNormalizer(cfg.TargetLUFS, cfg.PeakDBFS)}
+	l.norm.Prime(o.measureLoudness(l.key, nil))
+	return l
+}
+
+func (l *levelStage) apply(chunk []byte) []byte {
+	if l == nil {
+		return chunk
+	}
+	return l.norm.Apply(chunk, l.o.measureLoudness(l.key, chunk))
+}
+
+// applyWhole normalises a complete clip with one gain, measured after the
+// clip has been added to the voice's measurement.
+func (l *levelStage) applyWhole(clip []byte) []byte {
+	if l == nil {
+		return clip
+	}
+	measured := l.o.measureLoudness(l.key, clip)
+	l.norm.Prime(measured)
+	return l.norm.Apply(clip, measured)
+}
@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+func sineAt(amplitude float64, sampleRate int) []byte {
+	pcm := make([]byte, 2*2*sampleRate) // Two seconds
+	for i := 0; i < len(pcm)/2; i++ {
+		v := int16(amplitude * 32767 * math.Sin(2*math.Pi*500*float64(i)/float64(sampleRate)))
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(v))
+	}
+	return pcm
+}
+
+func TestLoudnessNormalization_EvensOutVoices(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SampleRate = 16000
+	cfg.Loudness = LoudnessNormalization{Enabled: true}
+	tts := &pcmTTS{}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, tts, nil, cfg)
+
+	for _, amp := range []float64{0.05, 0.6} {
+		tts.pcm = sineAt(amp, 16000)
+		out, err := o.Synthesize(context.Background(), "hello", Voice(fmt.Sprintf("voice-%v", amp)), LanguageEn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := audio.Loudness(out, 16000); math.Abs(got-(-16)) > 1 {
+			t.Errorf("amplitude %v: got %.1f LUFS, want -16", amp, got)
+		}
+	}
+
+	// Streamed audio of a voice already measured starts at the right level.
+	tts.pcm = sineAt(0.05, 16000)
+	var streamed []byte
+	if err := o.SynthesizeStream(context.Background(), "hello", Voice("voice-0.05"), LanguageEn, func(b []byte) error {
+		streamed = append(streamed, b...)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := audio.Loudness(streamed[:len(streamed)/4], 16000); math.Abs(got-(-16)) > 1 {
+		t.Errorf("start of stream at %.1f LUFS, want -16", got)
+	}
+}
@@ -22,6 +22,7 @@ type ManagedStream struct {
 	audioBuf *bytes.Buffer
 	mu       sync.Mutex
 
+	turnTTS             TTSProvider // What the current turn speaks with, per its flags
 	pipelineCtx         context.Context
 	pipelineCancel      context.CancelFunc
 	sttChan             chan<- []byte
@@ -51,6 +52,7 @@ type ManagedStream struct {
 	userInterrupting bool
 	echoSuppressor   *EchoSuppressor
 	closeOnce        sync.Once
+	replies          sync.WaitGroup // runLLMAndTTS calls under way
 
 	payloadGen       int
 	writeChan        chan []byte
@@ -59,11 +61,60 @@ type ManagedStream struct {
 	inPreemptiveTurn bool
 	lastActivityAt   time.Time
 	playbackRate     int
-
-	toolRecursionDepth int // Safety counter to prevent infinite tool loops
+	framing          AudioFraming // Set per connection; zero uses Config.AudioFraming
+
+	toolRecursionDepth int         // Safety counter to prevent infinite tool loops
+	turnKey            string      // Idempotency key of the current turn
+	audit              *AuditTrail // Provider calls of the current turn
+	awaitingResponse   bool        // A user turn ended and no bot audio has played yet
+	hangoverPending    bool        // Speech ended but the utterance may still resume
+	hangoverGen        int
+	bargeInHeld        bool // Speech start suppressed as too quiet to barge in
+	playbackLevel      float64
+	playbackAt         time.Time
+	speechText         string // What the bot is saying, or said last
+	comfortNoise       bool
+	playbackEnd        time.Time // When the bot audio sent so far will have played
+	silencePrompts     int       // Silence reprompts since the user last spoke
+	tl                 timelineState
+	spokenReply        *spokenReply // The last reply, kept while it may still be playing
+	purged             bool         // Closed by PurgeUser: not archived, no wrap-up
+
+	echoGate              bool // Config.EchoGating
+	clientReportsPlayback bool // RecordPlayedOutput has been called
+	outputQueue           []scheduledOutput
+	tail                  TailBehavior      // Config.TailBehavior
+	unplayed              []scheduledOutput // Sent bot audio until it has played, for a tail
+	playbackAcked         bool              // The client reports its buffer with ReportBuffer
+
+	bargeInSince   time.Time          // When the held-back speech started
+	bargeInDropped bool               // The utterance silenced the bot and goes unanswered
+	keptReply      context.CancelFunc // Pipeline of a reply InterruptStopAndListen left running
+	resumable      *ResumableTurn     // The last reply cut short, until it is resumed or superseded
+	speechDone     chan struct{}      // Closed when the current speakWith returns
+	speechFrom     int64              // Input offset at which the VAD last heard speech start
+	postRollLeft   int64              // Audio still to collect before the ended utterance is handed off
+	doubleTalk     *doubleTalkState   // User speech over the bot's, while it lasts
+	ptt            pushToTalkState
+	endpoint       *endpointState // End-of-turn signals the streaming utterance has had
+
+	obsMu           sync.Mutex
+	observers       map[*Observer]struct{}
+	observersClosed bool
+
+	ambient ambientState
+
+	archive *archiveRecorder // Audio kept for Config.Archive; nil when not archiving audio
 }
 
 func NewManagedStream(ctx context.Context, o *Orchestrator, session *ConversationSession) *ManagedStream {
+	return newManagedStream(ctx, o, session, true)
+}
+
+// newManagedStream builds a stream; greet controls whether a bot-first
+// config opens with its greeting. Outbound calls open on their own
+// once the far end has answered.
+func newManagedStream(ctx context.Context, o *Orchestrator, session *ConversationSession, greet bool) *ManagedStream {
 	mCtx, mCancel := context.WithCancel(ctx)
 
 	var streamVAD VADProvider
@@ -89,21 +140,29 @@ func NewManagedStream(ctx context.Context, o *Orchestrator, session *Conversatio
 		lastActivityAt: time.Now(),
 		playbackRate:   44100, // Default to hifi
 		turnCompletion: NewTurnCompletionAnalyzer(),
+		comfortNoise:   config.ComfortNoise.Enabled,
+		echoGate:       config.EchoGating,
+		tail:           config.TailBehavior,
+		tl:             timelineState{inputRate: config.SampleRate},
+		archive:        newArchiveRecorder(config.Archive, config.SampleRate),
+	}
+
+	if cal, ok := streamVAD.(VADCalibrator); ok && config.VADCalibration > 0 {
+		cal.Calibrate(config.VADCalibration, config.SampleRate)
+	}
+
+	if o != nil && session != nil {
+		ms.ctx = withBudgetWatch(mCtx, budgetWatch{sessionID: session.ID, exceeded: ms.budgetExceeded, fellBack: ms.fellBack})
+		o.registerStream(ms)
 	}
 
 	go ms.processBackgroundAudio()
 	go ms.monitorInactivity()
 
-	if o != nil && o.config.FirstSpeaker == FirstSpeakerBot {
+	if greet && o != nil && o.config.FirstSpeaker == FirstSpeakerBot {
 		go func() {
 			time.Sleep(500 * time.Millisecond) // Give audio some time to stabilize
-			// Add greeting to context first so LLM knows what it's saying
-			greeting := "Hello!"
-			if o.config.Language == LanguageEs {
-				greeting = "¡Hola!"
-			}
-			ms.session.AddMessage("assistant", greeting)
-			ms.runLLMAndTTS(ms.ctx, greeting)
+			ms.greet()
 		}()
 	}
 
@@ -117,6 +176,7 @@ func (ms *ManagedStream) processBackgroundAudio() {
 			return
 		case chunk := <-ms.writeChan:
 			ms.doWrite(chunk)
+			ms.wrote()
 		}
 	}
 }
@@ -131,6 +191,29 @@ func (ms *ManagedStream) LastRMS() float64 {
 	return 0.0
 }
 
+// VADStats reports the stream's VAD statistics, if its VAD keeps any.
+func (ms *ManagedStream) VADStats() (VADStats, bool) {
+	if sp, ok := ms.vad.(VADStatsProvider); ok {
+		return sp.Stats(), true
+	}
+	return VADStats{}, false
+}
+
+// CalibrateVAD re-runs ambient calibration, e.g. after the caller moved
+// somewhere noisier. It returns false if the stream's VAD cannot calibrate.
+func (ms *ManagedStream) CalibrateVAD(d time.Duration) bool {
+	cal, ok := ms.vad.(VADCalibrator)
+	if !ok {
+		return false
+	}
+	rate := DefaultConfig().SampleRate
+	if ms.orch != nil {
+		rate = ms.orch.GetConfig().SampleRate
+	}
+	cal.Calibrate(d, rate)
+	return true
+}
+
 func (ms *ManagedStream) IsUserSpeaking() bool {
 	if ms.vad == nil {
 		return false
@@ -141,12 +224,24 @@ func (ms *ManagedStream) IsUserSpeaking() bool {
 func (ms *ManagedStream) SetEchoSampleRates(playbackRate, inputRate int) {
 	ms.mu.Lock()
 	ms.playbackRate = playbackRate
+	if inputRate > 0 {
+		ms.tl.inputRate = inputRate
+	}
 	ms.mu.Unlock()
 	if ms.echoSuppressor != nil {
 		ms.echoSuppressor.SetSampleRates(playbackRate, inputRate)
 	}
 }
 
+// SetAudioFraming sets the size of the AUDIO_CHUNK events, as negotiated
+// with the client, e.g. 20ms frames for an RTP leg. It applies from the
+// next reply.
+func (ms *ManagedStream) SetAudioFraming(f AudioFraming) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.framing = f
+}
+
 func (ms *ManagedStream) Interrupt() {
 	ms.mu.Lock()
 	ms.userInterrupting = true
@@ -170,6 +265,7 @@ func (ms *ManagedStream) Write(chunk []byte) error {
 	buf := make([]byte, len(chunk))
 	copy(buf, chunk)
 
+	ms.ptt.queued.Add(1)
 	ms.writeChan <- buf
 	return nil
 }
@@ -182,12 +278,17 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 	}
 	ms.mu.Unlock()
 
+	if ms.isPushToTalk() {
+		return ms.writePushToTalk(chunk)
+	}
+
 	if ms.vad == nil {
 		return fmt.Errorf("VAD not configured for this stream")
 	}
 
 	// Apply echo suppression BEFORE VAD to prevent the bot from interrupting itself.
 	// We use the "Fast" version to minimize latency impact on the real-time audio loop.
+	ms.feedPlayedOutput()
 	vadChunk := chunk
 	if ms.echoSuppressor != nil {
 		vadChunk = ms.echoSuppressor.RemoveEchoRealtime(chunk)
@@ -198,6 +299,11 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 		return err
 	}
 
+	micRMS := chunkRMS(vadChunk)
+	if ms.promoteBargeIn(micRMS, chunk) {
+		ms.bargeIn()
+	}
+
 	if event != nil && event.Type != VADSilence {
 		switch event.Type {
 		case VADSpeechPotential:
@@ -205,123 +311,30 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 			// ms.emit(UserSpeaking, nil)
 
 		case VADSpeechStart:
-			ms.mu.Lock()
-			if ms.userSpeechStartTime.IsZero() {
-				ms.userSpeechStartTime = time.Now()
-			}
-			ms.mu.Unlock()
-
-			// We now emit UserSpeaking on a confirmed start to prevent glitchy pausing
-			ms.emit(UserSpeaking, nil)
-
-			ms.mu.Lock()
-			ms.sttGeneration++
-			pipelineCancel := ms.pipelineCancel
-			sttChan := ms.sttChan
-			ttsCancel := ms.ttsCancel
-			ms.pipelineCancel = nil
-			ms.sttChan = nil
-			ms.ttsCancel = nil
-
-			ms.sttStartTime = time.Now()
-			ms.sttRequestStartTime = time.Time{}
-			ms.sttEndTime = time.Time{}
-			ms.llmStartTime = time.Time{}
-			ms.llmEndTime = time.Time{}
-			ms.ttsStartTime = time.Time{}
-			ms.ttsFirstChunkTime = time.Time{}
-			ms.ttsEndTime = time.Time{}
-			ms.botSpeakStartTime = time.Time{}
-			ms.lastAudioSentAt = time.Time{}
-			ms.mu.Unlock()
-
-			// Stop TTS immediately on interrupt
-			if ttsCancel != nil {
-				ttsCancel()
-			}
-			if pipelineCancel != nil {
-				pipelineCancel()
+			ms.markSpeechStart()
+			ms.startDoubleTalk()
+			if ms.resumeUtterance() {
+				break
 			}
-			if sttChan != nil {
-				close(sttChan)
+			if th := ms.bargeInThreshold(); th > 0 && micRMS < th || ms.isOwnEcho(chunk) || ms.holdsBargeIn() {
+				// Too quiet to be heard over the bot, the bot itself, or
+				// not yet long enough to interrupt it; hold the start back
+				// until the user gets louder or the VAD lets go.
+				ms.mu.Lock()
+				ms.bargeInHeld = true
+				ms.bargeInSince = time.Now()
+				ms.mu.Unlock()
+				break
 			}
+			ms.bargeIn()
 
-			if sProvider, ok := ms.orch.stt.(StreamingSTTProvider); ok {
-				ms.startStreamingSTT(sProvider)
-			}
 		case VADSpeechEnd:
-			ms.mu.Lock()
-			ms.userSpeechEndTime = time.Now()
-			ms.mu.Unlock()
-			ms.emit(UserStopped, nil)
-
-			ms.mu.Lock()
-			sttChan := ms.sttChan
-			if sttChan != nil {
-				ms.sttChan = nil
-				ms.mu.Unlock()
-				close(sttChan)
-			} else {
-				audioData := make([]byte, ms.audioBuf.Len())
-				copy(audioData, ms.audioBuf.Bytes())
-				ms.mu.Unlock()
-
-				go func(buf []byte) {
-					ms.mu.Lock()
-					duration := ms.userSpeechEndTime.Sub(ms.userSpeechStartTime)
-					lastTranscript := ms.lastTranscript
-					ms.mu.Unlock()
-
-					// Fast-path: if the sound was very short, don't wait for another second
-					// to see if the user continues. It's likely just noise.
-					if duration < 500*time.Millisecond {
-						ms.runBatchPipeline(buf)
-						return
-					}
-
-					// Adaptive hold time: longer hold for short utterances (likely pauses),
-					// shorter hold for longer utterances (likely complete thoughts)
-					completionScore := ms.turnCompletion.CombinedCompletionScore(
-						lastTranscript,
-						int(duration.Milliseconds()),
-						ms.vad,
-					)
-
-					// SMART HOLD: completion score gates the response speed.
-					// High score = user finished sentence → respond FAST.
-					// Low score = user paused mid-sentence → wait LONG.
-					var holdTime time.Duration
-					if completionScore < 0.35 {
-						// Incomplete sentence (e.g. "I think that...") → long hold
-						holdTime = 600 * time.Millisecond
-					} else if completionScore > 0.65 {
-						// Complete sentence (e.g. "How are you?") → respond immediately
-						holdTime = 50 * time.Millisecond
-					} else {
-						// Ambiguous → medium hold, shorter for longer utterances
-						if duration < 1500*time.Millisecond {
-							holdTime = 350 * time.Millisecond
-						} else {
-							holdTime = 200 * time.Millisecond
-						}
-					}
-
-					t := time.NewTimer(holdTime)
-					defer t.Stop()
-
-					select {
-					case <-t.C:
-						// FIX: Check IsSpeaking() via the generic interface so this
-						// works for ALL VAD types, not just ImprovedRMSVAD.
-						if ms.vad != nil && ms.vad.IsSpeaking() {
-							return
-						}
-						ms.runBatchPipeline(buf)
-					case <-ms.ctx.Done():
-						return
-					}
-				}(audioData)
+			ms.endDoubleTalk(false)
+			ms.checkBackchannel()
+			if ms.releaseBargeInHold() {
+				break
 			}
+			ms.endUtterance()
 
 		case VADSilence:
 		}
@@ -331,7 +344,12 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 	if isUserSpeaking {
 		ms.updateActivity()
 	}
+	return ms.bufferInput(chunk, isUserSpeaking)
+}
 
+// bufferInput keeps mic audio for the utterance under way, and streams it to
+// STT if a streaming transcription is open.
+func (ms *ManagedStream) bufferInput(chunk []byte, isUserSpeaking bool) error {
 	cleanChunk := chunk
 	// Protect against byte-tearing on S16 PCM chunks
 	if len(cleanChunk)%2 != 0 {
@@ -340,14 +358,11 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 
 	ms.mu.Lock()
 	ms.audioBuf.Write(cleanChunk)
-	// Keep maximum 4 seconds of pristine audio (176400 bytes). Must slice on an EVEN byte boundary (2-byte samples).
+	ms.tl.inputBytes += int64(len(cleanChunk))
+	ms.archive.writeInput(cleanChunk, ms.tl.inputRate)
 	// Crucially, only trim if we are NOT in the middle of a turn.
-	if !isUserSpeaking && ms.userSpeechStartTime.IsZero() && ms.audioBuf.Len() > 176400 {
-		data := ms.audioBuf.Bytes()
-		// Safe lead-in size (3 seconds = 132300 bytes). This is evenly divisible by 2.
-		leadIn := data[len(data)-132300:]
-		ms.audioBuf.Reset()
-		ms.audioBuf.Write(leadIn)
+	if !isUserSpeaking && ms.userSpeechStartTime.IsZero() {
+		ms.capIdleAudioLocked()
 	}
 	ms.mu.Unlock()
 
@@ -356,6 +371,10 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 	ms.lastUserAudio = append(ms.lastUserAudio, cleanChunk...)
 	ms.mu.Unlock()
 
+	if ms.hasObservers() {
+		ms.notifyObservers(OrchestratorEvent{Type: UserAudio, SessionID: ms.session.ID, Data: append([]byte(nil), cleanChunk...)})
+	}
+
 	if sttChan != nil {
 		toSend := make([]byte, len(cleanChunk))
 		copy(toSend, cleanChunk)
@@ -364,8 +383,9 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 		// force a commit to prevent getting stuck in noise.
 		ms.mu.Lock()
 		startTime := ms.userSpeechStartTime
+		ptt := ms.ptt.enabled
 		ms.mu.Unlock()
-		if !startTime.IsZero() && time.Since(startTime) > 15*time.Second {
+		if !ptt && !startTime.IsZero() && time.Since(startTime) > 15*time.Second {
 			fmt.Printf("\r\033[K[DEBUG] VAD Watchdog fired (15s speech segment). Forcing speech end.\n")
 			ms.mu.Lock()
 			ms.userSpeechEndTime = time.Now()
@@ -381,10 +401,218 @@ func (ms *ManagedStream) doWrite(chunk []byte) error {
 		}
 	}
 
+	if ms.takePostRoll(len(cleanChunk)) {
+		ms.holdUtterance()
+	}
 	return nil
 }
 
+func (ms *ManagedStream) startUtterance() {
+	ms.mu.Lock()
+	if ms.userSpeechStartTime.IsZero() {
+		ms.userSpeechStartTime = time.Now()
+		ms.tl.speechStart = ms.tl.inputBytes
+	}
+	ms.trimPreRollLocked()
+	ms.silencePrompts = 0
+	ms.lastTranscript = ""
+	ms.mu.Unlock()
+
+	// We now emit UserSpeaking on a confirmed start to prevent glitchy pausing
+	ms.emit(UserSpeaking, nil)
+	ms.setState(StateListening)
+
+	ms.mu.Lock()
+	ms.sttGeneration++
+	pipelineCancel := ms.pipelineCancel
+	sttChan := ms.sttChan
+	ttsCancel := ms.ttsCancel
+	ms.pipelineCancel = nil
+	ms.sttChan = nil
+	ms.ttsCancel = nil
+
+	ms.sttStartTime = time.Now()
+	ms.sttRequestStartTime = time.Time{}
+	ms.sttEndTime = time.Time{}
+	ms.llmStartTime = time.Time{}
+	ms.llmEndTime = time.Time{}
+	ms.ttsStartTime = time.Time{}
+	ms.ttsFirstChunkTime = time.Time{}
+	ms.ttsEndTime = time.Time{}
+	ms.botSpeakStartTime = time.Time{}
+	ms.lastAudioSentAt = time.Time{}
+	ms.mu.Unlock()
+
+	// Stop TTS immediately on interrupt
+	if ttsCancel != nil {
+		ms.endDoubleTalk(true)
+		ttsCancel()
+	}
+	if pipelineCancel != nil {
+		pipelineCancel()
+	}
+	if sttChan != nil {
+		close(sttChan)
+	}
+
+	if sProvider, ok := ms.orch.stt.(StreamingSTTProvider); ok {
+		ms.startStreamingSTT(sProvider)
+	}
+}
+
+// endUtterance hands a finished utterance to STT and the LLM, once any
+// PostRoll of audio has followed it. With a SpeechHangover configured the
+// hand-off is held back; speech resuming within the window continues the
+// same utterance (see resumeUtterance).
+func (ms *ManagedStream) endUtterance() {
+	ms.mu.Lock()
+	ms.userSpeechEndTime = time.Now()
+	ms.tl.speechEnd = ms.tl.inputBytes
+	ms.mu.Unlock()
+	if ms.startPostRoll() {
+		return
+	}
+	ms.holdUtterance()
+}
+
+func (ms *ManagedStream) holdUtterance() {
+	ms.mu.Lock()
+	hangover := time.Duration(0)
+	var endpointer *TurnEndpointer
+	if ms.orch != nil {
+		cfg := ms.orch.GetConfig()
+		hangover, endpointer = cfg.SpeechHangover, cfg.Endpointing
+	}
+	if endpointer != nil {
+		ms.holdForEndpointLocked(endpointer, hangover)
+		ms.mu.Unlock()
+		return
+	}
+	if hangover > 0 {
+		ms.hangoverGen++
+		gen := ms.hangoverGen
+		ms.hangoverPending = true
+		ms.mu.Unlock()
+		time.AfterFunc(hangover, func() {
+			ms.mu.Lock()
+			if !ms.hangoverPending || ms.hangoverGen != gen || ms.ctx.Err() != nil {
+				ms.mu.Unlock()
+				return
+			}
+			ms.hangoverPending = false
+			ms.mu.Unlock()
+			ms.commitUtterance()
+		})
+		return
+	}
+	ms.mu.Unlock()
+	ms.commitUtterance()
+}
+
+// resumeUtterance reports whether a speech start falls inside the hangover
+// of the previous utterance, in which case that utterance simply goes on.
+func (ms *ManagedStream) resumeUtterance() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.postRollLeft > 0 {
+		ms.postRollLeft = 0
+	} else if !ms.hangoverPending {
+		return false
+	}
+	ms.hangoverPending = false
+	ms.userSpeechEndTime = time.Time{}
+	return true
+}
+
+func (ms *ManagedStream) commitUtterance() {
+	if ms.holdVADEndpoint() {
+		return
+	}
+	ms.submitUtterance()
+}
+
+// submitUtterance ends the user's turn and sends the utterance to STT.
+func (ms *ManagedStream) submitUtterance() {
+	ms.emit(UserStopped, nil)
+	ms.setState(StateTranscribing)
+
+	ms.mu.Lock()
+	sttChan := ms.sttChan
+	if sttChan != nil {
+		ms.sttChan = nil
+		ms.mu.Unlock()
+		close(sttChan)
+	} else {
+		audioData := make([]byte, ms.audioBuf.Len())
+		copy(audioData, ms.audioBuf.Bytes())
+		ms.tl.sttStart = ms.tl.inputBytes - int64(len(audioData))
+		ms.mu.Unlock()
+
+		go func(buf []byte) {
+			ms.mu.Lock()
+			duration := ms.userSpeechEndTime.Sub(ms.userSpeechStartTime)
+			lastTranscript := ms.lastTranscript
+			ms.mu.Unlock()
+
+			// Fast-path: if the sound was very short, don't wait for another second
+			// to see if the user continues. It's likely just noise. A turn
+			// endpointer has already done the waiting.
+			if duration < 500*time.Millisecond || ms.orch.GetConfig().Endpointing != nil {
+				ms.runBatchPipeline(buf)
+				return
+			}
+
+			// Adaptive hold time: longer hold for short utterances (likely pauses),
+			// shorter hold for longer utterances (likely complete thoughts)
+			completionScore := ms.turnCompletion.CombinedCompletionScore(
+				lastTranscript,
+				int(duration.Milliseconds()),
+				ms.vad,
+			)
+
+			// SMART HOLD: completion score gates the response speed.
+			// High score = user finished sentence → respond FAST.
+			// Low score = user paused mid-sentence → wait LONG.
+			var holdTime time.Duration
+			if completionScore < 0.35 {
+				// Incomplete sentence (e.g. "I think that...") → long hold
+				holdTime = 600 * time.Millisecond
+			} else if completionScore > 0.65 {
+				// Complete sentence (e.g. "How are you?") → respond immediately
+				holdTime = 50 * time.Millisecond
+			} else {
+				// Ambiguous → medium hold, shorter for longer utterances
+				if duration < 1500*time.Millisecond {
+					holdTime = 350 * time.Millisecond
+				} else {
+					holdTime = 200 * time.Millisecond
+				}
+			}
+
+			t := time.NewTimer(holdTime)
+			defer t.Stop()
+
+			select {
+			case <-t.C:
+				// FIX: Check IsSpeaking() via the generic interface so this
+				// works for ALL VAD types, not just ImprovedRMSVAD.
+				if ms.vad != nil && ms.vad.IsSpeaking() {
+					return
+				}
+				ms.runBatchPipeline(buf)
+			case <-ms.ctx.Done():
+				return
+			}
+		}(audioData)
+	}
+}
+
 func (ms *ManagedStream) isLikelyNoise(result TranscriptionResult, audioDuration time.Duration) bool {
+	// Speakerphone echo transcribed back is no more a user turn than noise.
+	if ms.isEchoTranscript(result.Text, audioDuration) {
+		return true
+	}
+
 	// If the STT engine is >= 70% sure this is not speech, trust it.
 	if result.NoSpeechProb > 0.7 {
 		return true
@@ -407,7 +635,7 @@ func (ms *ManagedStream) isLikelyNoise(result TranscriptionResult, audioDuration
 
 func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 
-	ctx, cancel := context.WithCancel(ms.ctx)
+	ctx, cancel := context.WithCancel(ms.orch.withCooldowns(ms.ctx))
 
 	ms.mu.Lock()
 	currentGeneration := ms.sttGeneration
@@ -419,12 +647,18 @@ func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 		thinking := ms.isThinking
 		isStale := ms.sttGeneration != currentGeneration
 		// Track transcript for turn completion analysis
-		ms.lastTranscript = transcript
+		if !isStale {
+			ms.lastTranscript = transcript
+		}
 		ms.mu.Unlock()
 
 		if isStale && !isFinal {
 			return nil
 		}
+		if isFinal && ms.hotCommand(transcript) {
+			ms.settleState()
+			return nil
+		}
 
 		ms.mu.Lock()
 		minWords := 1
@@ -439,7 +673,7 @@ func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 			if minWords > 1 {
 				if wc < minWords {
 					if !isFinal {
-						ms.emit(TranscriptPartial, transcript)
+						ms.emitPartial(transcript)
 					}
 					return nil
 				}
@@ -456,32 +690,12 @@ func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 		}
 
 		if isFinal {
-			ms.mu.Lock()
-			ms.sttEndTime = time.Now()
-			duration := time.Since(ms.sttStartTime)
-			ms.mu.Unlock()
-
-			// Warning: Streaming transcribers may not provide NoSpeechProb, so we rely on heuristics
-			if ms.isLikelyNoise(TranscriptionResult{Text: transcript}, duration) {
-				fmt.Printf("\r\033[K🔄 [NOISE] Rejected hallucination: '%s' (dur=%v)\n", transcript, duration)
-				ms.emit(BotResumed, nil)
+			if !isStale && ms.holdSTTEndpoint(transcript, func() { ms.takeStreamingFinal(ctx, transcript) }) {
 				return nil
 			}
-
-			ms.emit(TranscriptFinal, transcript)
-			ms.mu.Lock()
-			if ms.inPreemptiveTurn {
-				ms.mu.Unlock()
-				ms.session.UpdateLastUserMessage(transcript)
-			} else {
-				ms.inPreemptiveTurn = true
-				ms.mu.Unlock()
-				ms.session.AddMessage("user", transcript)
-			}
-
-			go ms.runLLMAndTTS(ctx, transcript)
+			ms.takeStreamingFinal(ctx, transcript)
 		} else {
-			ms.emit(TranscriptPartial, transcript)
+			ms.emitPartial(transcript)
 		}
 		return nil
 	})
@@ -495,6 +709,7 @@ func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 		ms.pipelineCancel = cancel
 		ms.sttChan = nil
 		ms.sttStartTime = time.Now()
+		ms.endpoint = nil
 		ms.mu.Unlock()
 		return
 	}
@@ -504,6 +719,7 @@ func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 	ms.pipelineCancel = cancel
 	ms.sttChan = sttChan
 	ms.sttStartTime = time.Now()
+	ms.endpoint = nil
 
 	// Flush pre-buffered audio to STT channel with blocking send
 	// This ensures audio captured before VADSpeechStart is included in transcription
@@ -527,12 +743,54 @@ func (ms *ManagedStream) startStreamingSTT(provider StreamingSTTProvider) {
 	}
 }
 
+// takeStreamingFinal answers a final transcript from streaming STT, unless
+// it is noise or ambient speech.
+func (ms *ManagedStream) takeStreamingFinal(ctx context.Context, transcript string) {
+	ms.mu.Lock()
+	ms.sttEndTime = time.Now()
+	duration := time.Since(ms.sttStartTime)
+	ms.mu.Unlock()
+
+	// Warning: Streaming transcribers may not provide NoSpeechProb, so we rely on heuristics
+	if ms.isLikelyNoise(TranscriptionResult{Text: transcript}, duration) {
+		fmt.Printf("\r\033[K🔄 [NOISE] Rejected hallucination: '%s' (dur=%v)\n", transcript, duration)
+		ms.emit(BotResumed, nil)
+		ms.settleState()
+		return
+	}
+
+	if ms.ambientCapture(transcript) {
+		ms.settleState()
+		return
+	}
+
+	ms.noteTranscript(transcript, nil)
+	ms.emit(TranscriptFinal, transcript)
+	ms.recordUserTurn(transcript)
+	ms.mu.Lock()
+	if ms.inPreemptiveTurn {
+		ms.mu.Unlock()
+		ms.session.UpdateLastUserMessage(transcript)
+	} else {
+		ms.inPreemptiveTurn = true
+		ms.mu.Unlock()
+		ms.session.AddMessage("user", transcript)
+	}
+
+	ms.mu.Lock()
+	end := ms.userSpeechEndTime
+	ms.mu.Unlock()
+	go ms.answer(ms.orch.withTurnDeadline(ctx, end), transcript)
+}
+
 func (ms *ManagedStream) runBatchPipeline(audioData []byte) {
 	// DO NOT interrupt here. Wait for a valid transcript first!
 
 	ms.mu.Lock()
 	previousCancel := ms.pipelineCancel
-	ctx, cancel := context.WithTimeout(ms.ctx, 15*time.Second)
+	ctx, cancel := context.WithTimeout(WithIdempotencyKey(ms.ctx, NewIdempotencyKey()), 15*time.Second)
+	ctx = ms.orch.withTurnDeadline(ctx, ms.userSpeechEndTime)
+	ctx, _ = WithAuditTrail(ctx)
 
 	ms.pipelineCtx = ctx
 	ms.pipelineCancel = cancel
@@ -550,7 +808,7 @@ func (ms *ManagedStream) runBatchPipeline(audioData []byte) {
 	ms.sttRequestStartTime = time.Now()
 	ms.mu.Unlock()
 	fmt.Printf("\r\033[K[DEBUG] Calling Transcribe for %d bytes\n", len(audioData))
-	result, err := ms.orch.Transcribe(ctx, audioData, ms.session.GetCurrentLanguage())
+	result, switched, err := ms.orch.transcribeDetecting(ctx, ms.session, audioData)
 	ms.mu.Lock()
 	if err == nil {
 		fmt.Printf("\r\033[K[DEBUG] Transcribe returned: '%s' (prob=%.2f)\n", result.Text, result.NoSpeechProb)
@@ -560,12 +818,17 @@ func (ms *ManagedStream) runBatchPipeline(audioData []byte) {
 		fmt.Printf("\r\033[K[DEBUG] Transcribe error: %v\n", err)
 	}
 	ms.mu.Unlock()
+	if switched != "" {
+		ms.emit(LanguageChanged, switched)
+	}
 
 	if err != nil {
 		if ctx.Err() == nil {
 			fmt.Printf("\r\033[K[DEBUG] Transcribe error: %v\n", err)
+			ms.logFailure(ctx, StageSTT, err)
 			ms.emit(ErrorEvent, fmt.Sprintf("transcription error: %v", err))
 		}
+		ms.settleState()
 		return
 	}
 
@@ -579,6 +842,7 @@ func (ms *ManagedStream) runBatchPipeline(audioData []byte) {
 			fmt.Printf("\r\033[K🔄 [NOISE] Rejected hallucination: '%s' (prob=%.2f, dur=%v)\n", result.Text, result.NoSpeechProb, audioDuration)
 		}
 		ms.emit(BotResumed, nil)
+		ms.settleState()
 		return
 	}
 
@@ -594,6 +858,12 @@ func (ms *ManagedStream) runBatchPipeline(audioData []byte) {
 
 	if userStillSpeaking {
 		fmt.Printf("\r\033[K[DEBUG] User resumed speaking during transcription processing, discarding result and continuing to listen\n")
+		ms.setState(StateListening)
+		return
+	}
+
+	if ms.hotCommand(transcript) {
+		ms.settleState()
 		return
 	}
 
@@ -616,7 +886,14 @@ func (ms *ManagedStream) runBatchPipeline(audioData []byte) {
 		ms.internalInterrupt()
 	}
 
+	if ms.ambientCapture(transcript) {
+		ms.settleState()
+		return
+	}
+
+	ms.noteTranscript(transcript, result.Words)
 	ms.emit(TranscriptFinal, transcript)
+	ms.recordUserTurn(transcript)
 	ms.mu.Lock()
 	if ms.inPreemptiveTurn {
 		ms.mu.Unlock()
@@ -627,15 +904,17 @@ func (ms *ManagedStream) runBatchPipeline(audioData []byte) {
 		ms.session.AddMessage("user", transcript)
 	}
 
-	ms.runLLMAndTTS(ctx, transcript)
+	ms.answer(ctx, transcript)
 }
 
 func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 	ms.mu.Lock()
-	if ms.orch == nil || ms.session == nil {
+	if ms.orch == nil || ms.session == nil || ms.isClosed {
 		ms.mu.Unlock()
 		return
 	}
+	ms.replies.Add(1)
+	defer ms.replies.Done()
 
 	if ms.responseCancel != nil {
 		ms.responseCancel()
@@ -644,7 +923,20 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 		ms.ttsCancel()
 	}
 
-	rCtx, rCancel := context.WithCancel(ctx)
+	// A new user turn gets a fresh idempotency key; tool-result follow-ups
+	// (empty transcript) keep the key of the turn that triggered them.
+	if transcript != "" || ms.turnKey == "" {
+		ms.turnKey = IdempotencyKeyFromContext(ctx)
+		if ms.turnKey == "" {
+			ms.turnKey = NewIdempotencyKey()
+		}
+		ctx, ms.audit = WithAuditTrail(ctx)
+	} else {
+		ctx = context.WithValue(ctx, auditKey{}, ms.audit)
+	}
+	rCtx, rCancel := context.WithCancel(WithPriority(WithIdempotencyKey(ctx, ms.turnKey), ms.session.GetPriority()))
+	rCtx = ms.orch.withTurnFlags(rCtx, ms.session)
+	ms.turnTTS = ms.orch.ttsFor(rCtx)
 	ms.responseCancel = rCancel
 	ms.isThinking = true
 	ms.payloadGen++
@@ -657,16 +949,38 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 
 	ms.mu.Unlock()
 
+	if transcript != "" {
+		ms.resumeIfShort(transcript)
+	}
+
 	defer rCancel()
 
 	ms.emitWithGen(BotThinking, nil, gen)
+	ms.setState(StateThinking)
+	ms.startComfortNoise(rCtx, gen)
+
+	// Live calls queue for the orchestrator's capacity like any other turn,
+	// premium callers first, while comfort noise covers the wait.
+	release, wait, err := ms.orch.acquireTurn(rCtx)
+	if err != nil {
+		ms.mu.Lock()
+		if ms.payloadGen == gen {
+			ms.isThinking = false
+		}
+		ms.mu.Unlock()
+		ms.settleState()
+		return
+	}
+	defer release()
+	turnStart := time.Now()
+	defer func() { ms.orch.recordPriorityTurn(PriorityFromContext(rCtx), wait, time.Since(turnStart)) }()
 
 	ms.mu.Lock()
 	ms.llmStartTime = time.Now()
 	ms.mu.Unlock()
 
 	// Try streaming if supported
-	if sProvider, ok := ms.orch.llm.(StreamingLLMProvider); ok {
+	if sProvider, ok := ms.orch.llmFor(rCtx).(StreamingLLMProvider); ok {
 		ms.runStreamingLLMPipeline(rCtx, sProvider)
 		return
 	}
@@ -683,7 +997,9 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 		ms.mu.Lock()
 		ms.isThinking = false
 		ms.mu.Unlock()
+		ms.settleState()
 		if rCtx.Err() == nil {
+			ms.logFailure(rCtx, StageLLM, err)
 			ms.emit(ErrorEvent, fmt.Sprintf("LLM error: %v", err))
 		}
 		return
@@ -694,13 +1010,23 @@ func (ms *ManagedStream) runLLMAndTTS(ctx context.Context, transcript string) {
 
 	ttsCtx, ttsCancel := context.WithCancel(rCtx)
 	defer ttsCancel()
+	if ms.orch.GetConfig().EagerSynthesis.Enabled && gated(rCtx, FlagEagerSynthesis) {
+		segments := ms.orch.eagerSegments(rCtx, response, ms.session.GetCurrentLanguage())
+		sentences := make(chan string, len(segments))
+		for _, segment := range segments {
+			sentences <- segment
+		}
+		close(sentences)
+		ms.speakSentences(ttsCtx, sentences)
+		return
+	}
 	ms.speakText(ttsCtx, response)
 }
 
 func (ms *ManagedStream) runStreamingLLMPipeline(ctx context.Context, provider StreamingLLMProvider) {
 	var fullText strings.Builder
 	var hasToolCalls bool
-	messages := ms.session.GetContextCopy()
+	messages := ms.session.promptContext(ctx)
 	fmt.Printf("\r\033[K[DEBUG] runStreamingLLM: Starting with %d messages in session\n", len(messages))
 
 	// Debug: Show message breakdown
@@ -749,27 +1075,56 @@ func (ms *ManagedStream) runStreamingLLMPipeline(ctx context.Context, provider S
 	var toolResults []pendingToolResult
 	var toolCallCount int
 
-	_, err := provider.StreamComplete(ctx, messages, ms.session.GetTools(), func(chunk string) error {
+	// Sentences go to TTS as soon as the LLM completes them. Speech starts
+	// with the first sentence and ends when the channel is closed.
+	ttsCtx, ttsCancel := context.WithCancel(ctx)
+	defer ttsCancel()
+	splitter := ms.orch.newSegmenter(ctx, ms.session.GetCurrentLanguage())
+	var sentences chan string
+	speechDone := make(chan struct{})
+	speak := func(sentence string) {
+		if sentence == "" {
+			return
+		}
+		if sentences == nil {
+			sentences = make(chan string, 64)
+			go func() {
+				defer close(speechDone)
+				ms.speakSentences(ttsCtx, sentences)
+			}()
+		}
+		select {
+		case sentences <- sentence:
+		case <-ttsCtx.Done():
+		}
+	}
+	finishSpeech := func() {
+		if sentences == nil {
+			return
+		}
+		close(sentences)
+		<-speechDone
+	}
+
+	_, err := ms.orch.streamComplete(ctx, ms.session, provider, messages, ms.session.GetTools(), func(chunk string) error {
 		fullText.WriteString(chunk)
 		ms.mu.Lock()
 		if ms.llmEndTime.IsZero() {
 			ms.llmEndTime = time.Now()
 		}
 		ms.mu.Unlock()
+		for _, sentence := range splitter.Push(chunk) {
+			speak(sentence)
+		}
 		return nil
 	}, func(tc ToolCallEventData) error {
 		toolCallCount++
 		fmt.Printf("\r\033[K[DEBUG] Tool call #%d: %s, callID=%s\n", toolCallCount, tc.Name, tc.CallID)
 
-		// If the model produced some text BEFORE the tool call (the "filler"), speak it immediately
-		fillerText := strings.TrimSpace(fullText.String())
-		if fillerText != "" && !hasToolCalls {
-			fmt.Printf("\r\033[K[DEBUG] Speaking filler text before tool call: %q\n", fillerText)
-			go func(t string) {
-				ttsCtx, ttsCancel := context.WithCancel(ctx)
-				defer ttsCancel()
-				ms.speakText(ttsCtx, t)
-			}(fillerText)
+		// If the model produced some text BEFORE the tool call (the "filler"),
+		// speak the rest of it now rather than waiting for a sentence end.
+		speak(splitter.Flush())
+		if !hasToolCalls {
 			fullText.Reset()
 		}
 
@@ -777,16 +1132,21 @@ func (ms *ManagedStream) runStreamingLLMPipeline(ctx context.Context, provider S
 		fmt.Printf("\r\033[K[DEBUG] Tool call detected: %s, callID=%s\n", tc.Name, tc.CallID)
 		ms.emit(ToolCall, tc)
 
-		o := ms.orch
-		o.mu.RLock()
-		handler, ok := o.toolHandlers[tc.Name]
-		o.mu.RUnlock()
+		if placeholder, ack, ok := ms.startAsyncTool(tc); ok {
+			ms.orch.logger.Debug("started async tool", "sessionID", ms.session.ID, "tool", tc.Name)
+			// The acknowledgment is spoken in this reply, after any filler.
+			speak(ack)
+			toolResults = append(toolResults, pendingToolResult{tc: tc, result: placeholder})
+			return nil
+		}
 
-		result := "Error: tool not found"
-		if ok {
-			fmt.Printf("\r\033[K[DEBUG] Executing tool: %s with args: %v\n", tc.Name, tc.Arguments)
-			var err error
-			result, err = handler(tc.Arguments)
+		result, ok, err := ms.orch.callTool(ctx, ms.session, tc, func(entry ToolAuditEntry) {
+			ms.emit(ToolAudit, entry)
+		})
+		if !ok {
+			result = "Error: tool not found"
+		} else {
+			fmt.Printf("\r\033[K[DEBUG] Executed tool: %s with args: %v\n", tc.Name, tc.Arguments)
 			if err != nil {
 				result = fmt.Sprintf("Error: %v", err)
 			}
@@ -798,11 +1158,15 @@ func (ms *ManagedStream) runStreamingLLMPipeline(ctx context.Context, provider S
 	})
 
 	if err != nil {
+		ttsCancel()
+		finishSpeech()
 		ms.mu.Lock()
 		ms.isThinking = false
 		ms.mu.Unlock()
+		ms.settleState()
 		if ctx.Err() == nil {
 			fmt.Printf("\r\033[K[DEBUG] Streaming LLM error: %v\n", err)
+			ms.logFailure(ctx, StageLLM, err)
 			ms.emit(ErrorEvent, fmt.Sprintf("Streaming LLM error: %v", err))
 		}
 		return
@@ -817,16 +1181,17 @@ func (ms *ManagedStream) runStreamingLLMPipeline(ctx context.Context, provider S
 			ms.session.AddMessage("assistant", response)
 		}
 		ms.emit(BotResponse, response)
-
-		// Create a local context for synthesis
-		ttsCtx, ttsCancel := context.WithCancel(ctx)
-		defer ttsCancel()
-		ms.speakText(ttsCtx, response)
-	} else {
+	}
+	speak(splitter.Flush())
+	if sentences == nil {
 		ms.mu.Lock()
 		ms.isThinking = false
 		ms.mu.Unlock()
+		if !hasToolCalls {
+			ms.settleState()
+		}
 	}
+	finishSpeech()
 
 	if hasToolCalls {
 		// Add Tool Calls to History in correct sequence
@@ -872,6 +1237,7 @@ func (ms *ManagedStream) runStreamingLLMPipeline(ctx context.Context, provider S
 			ms.mu.Lock()
 			ms.isThinking = false
 			ms.mu.Unlock()
+			ms.settleState()
 			return
 		}
 
@@ -888,8 +1254,28 @@ func (ms *ManagedStream) runStreamingLLMPipeline(ctx context.Context, provider S
 }
 
 func (ms *ManagedStream) speakText(ctx context.Context, text string) {
+	sentences := make(chan string, 1)
+	sentences <- text
+	close(sentences)
+	ms.speakSentences(ctx, sentences)
+}
+
+// speakSentences speaks every sentence received until the channel closes as
+// one bot turn, so synthesis of the first can start while the LLM is still
+// writing the rest.
+func (ms *ManagedStream) speakSentences(ctx context.Context, sentences <-chan string) {
+	ms.speakWith(ctx, sentences, func(ctx context.Context, text string, onChunk func([]byte) error) error {
+		return ms.orch.SynthesizeStream(ctx, text, ms.session.GetCurrentVoice(), ms.session.GetCurrentLanguage(), onChunk)
+	})
+}
+
+type synthFunc func(ctx context.Context, text string, onChunk func([]byte) error) error
+
+// speakWith is speakSentences with the audio for each sentence produced by
+// synth instead of the TTS provider.
+func (ms *ManagedStream) speakWith(ctx context.Context, sentences <-chan string, synth synthFunc) {
 	// Create a sub-context that we can cancel specifically if interrupted
-	sCtx, sCancel := context.WithCancel(ctx)
+	sCtx, sCancel := context.WithCancel(withSpeechRate(ctx, ms.session))
 	defer sCancel()
 
 	ms.mu.Lock()
@@ -899,6 +1285,11 @@ func (ms *ManagedStream) speakText(ctx context.Context, text string) {
 		ms.vad.Reset()
 	}
 	ms.ttsCancel = sCancel
+	ms.spokenReply = nil
+	done := make(chan struct{})
+	defer close(done)
+	ms.speechDone = done
+	ms.session.resetPlayback()
 	ms.botSpeakStartTime = time.Now()
 	ms.ttsStartTime = ms.botSpeakStartTime
 
@@ -916,10 +1307,12 @@ func (ms *ManagedStream) speakText(ctx context.Context, text string) {
 	ms.mu.Unlock()
 
 	ms.emit(BotSpeaking, nil)
+	ms.setState(StateSpeaking)
 
 	ms.mu.Lock()
 	pRate := ms.playbackRate
 	gen := ms.payloadGen
+	framing := ms.framing
 	ms.mu.Unlock()
 
 	// JITTER BUFFER for single-core ARM:
@@ -933,66 +1326,93 @@ func (ms *ManagedStream) speakText(ctx context.Context, text string) {
 			jitterBufferMs = v
 		}
 	}
-	frameSize := int(float64(pRate)*0.06) * 2 // 60ms frames (was 20ms)
-	if frameSize <= 0 {
-		frameSize = 5292 // Fallback to 44.1k 60ms
+	if framing.FrameDuration() == 0 && ms.orch != nil {
+		framing = ms.orch.GetConfig().AudioFraming
+	}
+	if framing.FrameDuration() == 0 {
+		framing = AudioFraming{Transport: TransportWebSocket}
+	}
+	frameRate := pRate
+	if frameRate <= 0 {
+		frameRate = 44100
 	}
 	jitterTargetBytes := int(float64(pRate)*float64(jitterBufferMs)/1000.0) * 2
 	var jitterBuf []byte
 	hasStartedPlayback := false
 
-	err := ms.orch.SynthesizeStream(sCtx, text, ms.session.GetCurrentVoice(), ms.session.GetCurrentLanguage(), func(chunk []byte) error {
+	audioStarted := false
+	var output outputMap
+	var firstAudio, lastAudio time.Time
+	emitAudio := func(c []byte) {
+		if !audioStarted {
+			audioStarted = true
+			ms.recordResponseLatency()
+		}
+		ms.awaitPlayback(sCtx)
+		if offset, ok := ms.emitAudioChunk(c, gen); ok {
+			output.add(offset, len(c))
+			lastAudio = time.Now()
+			if firstAudio.IsZero() {
+				firstAudio = lastAudio
+			}
+		}
+	}
+
+	framer := newAudioFramer(framing, frameRate, func(c []byte) error {
+		emitAudio(c)
+		return nil
+	})
+
+	var spokenAudio []byte
+	var sentenceEnds []int64
+	onChunk := func(chunk []byte) error {
 		ms.mu.Lock()
 		ms.lastAudioSentAt = time.Now()
 		ms.mu.Unlock()
+		spokenAudio = append(spokenAudio, chunk...)
 
 		if !hasStartedPlayback {
 			jitterBuf = append(jitterBuf, chunk...)
 			if len(jitterBuf) >= jitterTargetBytes {
 				hasStartedPlayback = true
-				// Emit buffered audio in 60ms frames
-				for i := 0; i < len(jitterBuf); i += frameSize {
-					end := i + frameSize
-					if end > len(jitterBuf) {
-						end = len(jitterBuf)
-					}
-					c := make([]byte, end-i)
-					copy(c, jitterBuf[i:end])
-					ms.emitWithGen(AudioChunk, c, gen)
-				}
+				framer.write(jitterBuf)
 				jitterBuf = nil
 			}
 			return nil
 		}
 
-		// Playback already started: emit immediately in 60ms frames
-		for i := 0; i < len(chunk); i += frameSize {
-			end := i + frameSize
-			if end > len(chunk) {
-				end = len(chunk)
-			}
-			c := make([]byte, end-i)
-			copy(c, chunk[i:end])
-			ms.emitWithGen(AudioChunk, c, gen)
-		}
-		return nil
-	})
+		// Playback already started: emit whole frames immediately
+		return framer.write(chunk)
+	}
 
-	// Flush any remaining jitter buffer at end-of-stream
-	if !hasStartedPlayback && len(jitterBuf) > 0 {
-		for i := 0; i < len(jitterBuf); i += frameSize {
-			end := i + frameSize
-			if end > len(jitterBuf) {
-				end = len(jitterBuf)
-			}
-			c := make([]byte, end-i)
-			copy(c, jitterBuf[i:end])
-			ms.emitWithGen(AudioChunk, c, gen)
+	// Keep receiving after an interruption or error so the producer never
+	// blocks on a full channel.
+	var spoken []string
+	var err error
+	for text := range sentences {
+		if err != nil || sCtx.Err() != nil {
+			continue
+		}
+		spoken = append(spoken, text)
+		ms.mu.Lock()
+		ms.speechText = strings.Join(spoken, " ")
+		ms.mu.Unlock()
+		err = synth(sCtx, text, onChunk)
+		sentenceEnds = append(sentenceEnds, int64(len(spokenAudio)))
+
+		// Flush any remaining jitter buffer at end-of-sentence; the next
+		// sentence may still be waiting on the LLM.
+		if !hasStartedPlayback && len(jitterBuf) > 0 {
+			framer.write(jitterBuf)
+			jitterBuf = nil
 		}
+		framer.flush()
 	}
+	text := strings.Join(spoken, " ")
 
 	if err != nil && sCtx.Err() == nil {
 		fmt.Printf("\r\033[K[DEBUG] TTS error: %v\n", err)
+		ms.logFailure(sCtx, StageTTS, err)
 		ms.emit(ErrorEvent, fmt.Sprintf("TTS error: %v", err))
 	}
 
@@ -1004,6 +1424,68 @@ func (ms *ManagedStream) speakText(ctx context.Context, text string) {
 		ms.ttsCancel = nil
 	}
 	ms.mu.Unlock()
+	ms.settleState()
+
+	reply := &spokenReply{sentences: spoken, ends: sentenceEnds, sent: output.sent}
+	if sCtx.Err() != nil {
+		ms.keepHeard(reply)
+	} else {
+		ms.mu.Lock()
+		ms.spokenReply = reply
+		ms.mu.Unlock()
+		ms.session.trackReply(spoken, sentenceEnds, pRate)
+	}
+	if !firstAudio.IsZero() {
+		ms.session.RecordBotTurn(firstAudio, ms.botAudioEnd(lastAudio, sCtx.Err() != nil), text)
+		ms.emit(AnalyticsUpdate, ms.session.Analytics())
+	}
+	ms.finishTimeline(spoken, sentenceEnds, &output, firstAudio, lastAudio)
+	if err == nil && sCtx.Err() == nil {
+		ms.session.cacheSpeech(text, spokenAudio, SpeechRateFromContext(sCtx))
+	}
+}
+
+// botAudioEnd estimates when a bot turn's audio stops playing: at the end
+// of playback of what was sent, which synthesis usually runs well ahead of,
+// or now for a turn that was cut off.
+func (ms *ManagedStream) botAudioEnd(lastSent time.Time, cut bool) time.Time {
+	end := lastSent
+	ms.mu.Lock()
+	if ms.playbackRate > 0 && ms.playbackEnd.After(end) {
+		end = ms.playbackEnd
+	}
+	ms.mu.Unlock()
+	if now := time.Now(); cut && end.After(now) {
+		end = now
+	}
+	return end
+}
+
+// recordUserTurn adds a finalised user utterance to the session analytics and
+// arms response-latency measurement for the next bot audio.
+func (ms *ManagedStream) recordUserTurn(transcript string) {
+	ms.mu.Lock()
+	start, end := ms.userSpeechStartTime, ms.userSpeechEndTime
+	ms.awaitingResponse = true
+	ms.mu.Unlock()
+	if start.IsZero() {
+		return
+	}
+	if end.IsZero() || end.Before(start) {
+		end = time.Now()
+	}
+	ms.session.RecordUserTurn(start, end, transcript)
+}
+
+func (ms *ManagedStream) recordResponseLatency() {
+	ms.mu.Lock()
+	pending := ms.awaitingResponse
+	ms.awaitingResponse = false
+	end := ms.userSpeechEndTime
+	ms.mu.Unlock()
+	if pending && !end.IsZero() {
+		ms.session.RecordResponseLatency(time.Since(end))
+	}
 }
 
 func (ms *ManagedStream) NotifyAudioPlayed() {
@@ -1013,7 +1495,15 @@ func (ms *ManagedStream) NotifyAudioPlayed() {
 }
 
 func (ms *ManagedStream) RecordPlayedOutput(chunk []byte) {
-	if ms.echoSuppressor == nil || len(chunk) == 0 {
+	if len(chunk) == 0 {
+		return
+	}
+	ms.mu.Lock()
+	ms.clientReportsPlayback = true
+	ms.outputQueue = nil
+	ms.mu.Unlock()
+	ms.trackPlaybackLevel(chunk)
+	if ms.echoSuppressor == nil {
 		return
 	}
 	ms.echoSuppressor.RecordPlayedAudio(chunk)
@@ -1157,9 +1647,38 @@ func (ms *ManagedStream) Close() {
 		ms.mu.Lock()
 		close(ms.events)
 		ms.mu.Unlock()
+
+		ms.closeObservers()
+
+		if ms.orch != nil && ms.session != nil {
+			ms.orch.unregisterStream(ms)
+			ms.mu.Lock()
+			purged := ms.purged
+			pcm := ms.archive.audio(ms.tl.inputRate)
+			ms.mu.Unlock()
+			if !purged {
+				go ms.orch.deliverWrapUp(ms.session)
+				go ms.orch.archiveSession(ms.session, pcm)
+			}
+		}
 	})
 }
 
+// waitReplies waits until the replies a closed stream was running end.
+func (ms *ManagedStream) waitReplies(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		ms.replies.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (ms *ManagedStream) emit(eventType EventType, data interface{}) {
 	if eventType != AudioChunk {
 		ms.updateActivity()
@@ -1170,6 +1689,45 @@ func (ms *ManagedStream) emit(eventType EventType, data interface{}) {
 	ms.emitWithGen(eventType, data, gen)
 }
 
+// emitAudioChunk emits bot audio and returns the output offset, in bytes, at
+// which it was queued. Chunks are dropped rather than waited on when the
+// client falls behind.
+func (ms *ManagedStream) emitAudioChunk(chunk []byte, gen int) (int64, bool) {
+	ms.mu.Lock()
+	if ms.isClosed || ms.ctx.Err() != nil || !ms.isSpeaking || ms.userInterrupting {
+		ms.mu.Unlock()
+		return 0, false
+	}
+	offset, ok := ms.sendAudioLocked(chunk, gen)
+	ms.mu.Unlock()
+	ms.notifyObservers(OrchestratorEvent{Type: AudioChunk, SessionID: ms.session.ID, Data: chunk, Generation: gen})
+	return offset, ok
+}
+
+// sendAudioLocked queues audio for the client without blocking, under mu so
+// Close can't close the channel in between, and keeps the output position
+// and the estimated end of playback up to date.
+func (ms *ManagedStream) sendAudioLocked(chunk []byte, gen int) (int64, bool) {
+	offset := ms.tl.outputBytes
+	select {
+	case ms.events <- OrchestratorEvent{Type: AudioChunk, SessionID: ms.session.ID, Data: chunk, Generation: gen}:
+	default:
+		return offset, false
+	}
+	ms.tl.outputBytes += int64(len(chunk))
+	ms.archive.writeOutput(chunk)
+	if ms.playbackRate > 0 {
+		start := time.Now()
+		if ms.playbackEnd.After(start) {
+			start = ms.playbackEnd
+		}
+		ms.playbackEnd = start.Add(bytesToDuration(int64(len(chunk)), ms.playbackRate))
+		ms.scheduleOutputLocked(start, chunk)
+		ms.recordOutputLocked(start, chunk)
+	}
+	return offset, true
+}
+
 func (ms *ManagedStream) emitWithGen(eventType EventType, data interface{}, gen int) {
 	select {
 	case <-ms.ctx.Done():
@@ -1206,6 +1764,7 @@ func (ms *ManagedStream) emitWithGen(eventType EventType, data interface{}, gen
 		Data:       data,
 		Generation: gen,
 	}
+	ms.notifyObservers(event)
 
 	if eventType == AudioChunk {
 		select {
@@ -1222,11 +1781,18 @@ func (ms *ManagedStream) emitWithGen(eventType EventType, data interface{}, gen
 	}
 }
 
+// interrupt stops the bot without counting it as the user interrupting, as
+// when the stream closes.
 func (ms *ManagedStream) interrupt() {
-	ms.internalInterrupt()
+	ms.stopResponse(false)
 }
 
 func (ms *ManagedStream) internalInterrupt() {
+	ms.stopResponse(true)
+}
+
+func (ms *ManagedStream) stopResponse(byUser bool) {
+	ms.endDoubleTalk(true)
 	ms.mu.Lock()
 
 	// Check if there's anything to interrupt (TTS or LLM request)
@@ -1240,6 +1806,13 @@ func (ms *ManagedStream) internalInterrupt() {
 
 	responseCancel := ms.responseCancel
 	ttsCancel := ms.ttsCancel
+	turnTTS := ms.turnTTS
+	keptReply := ms.keptReply
+	ms.keptReply = nil
+	wasSpeaking := ms.isSpeaking || isStillPlaying
+	// A reply still being synthesized is cut back by speakWith itself.
+	played := ms.spokenReply
+	ms.spokenReply = nil
 
 	ms.lastActivityAt = time.Now()
 
@@ -1257,9 +1830,12 @@ func (ms *ManagedStream) internalInterrupt() {
 	// We don't increment gen here anymore as runLLMAndTTS handles it,
 	// keeping it simple and unified.
 	gen := ms.payloadGen
+	now, unplayed, rate := time.Now(), ms.unplayed, ms.playbackRate
+	ms.unplayed = nil
 	ms.mu.Unlock()
 
 	ms.echoSuppressor.ClearEchoBuffer()
+	ms.dropScheduledOutput()
 
 	if responseCancel != nil {
 		responseCancel()
@@ -1267,15 +1843,36 @@ func (ms *ManagedStream) internalInterrupt() {
 	if ttsCancel != nil {
 		ttsCancel()
 	}
+	if keptReply != nil {
+		keptReply()
+	}
 
 	if ms.orch != nil && ms.orch.tts != nil {
-		if err := ms.orch.tts.Abort(); err != nil {
+		tts := turnTTS
+		if tts == nil {
+			tts = ms.orch.ttsFor(WithPriority(ms.ctx, ms.session.GetPriority()))
+		}
+		if err := tts.Abort(); err != nil {
 			ms.orch.logger.Warn("tts abort failed", "sessionID", ms.session.ID, "error", err)
 		}
 	}
 
+	if wasSpeaking && byUser {
+		ms.session.RecordInterruption()
+	}
+	if played != nil {
+		ms.keepHeard(played)
+	}
+
+	var tail []byte
+	if wasSpeaking {
+		tail = ms.interruptionTail(unplayed, rate, now)
+	}
+
 	ms.emitWithGen(Interrupted, nil, gen)
+	ms.setState(StateInterrupted)
 	ms.drainAudioChunks()
+	ms.sendTail(tail, gen)
 }
 
 func (ms *ManagedStream) drainAudioChunks() {
@@ -1329,7 +1926,7 @@ func (ms *ManagedStream) monitorInactivity() {
 		return
 	}
 
-	ticker := time.NewTicker(2 * time.Second)
+	ticker := time.NewTicker(min(2*time.Second, timeout))
 	defer ticker.Stop()
 
 	for {
@@ -1356,6 +1953,10 @@ func (ms *ManagedStream) monitorInactivity() {
 			if !thinking && !speaking && !userSpeaking {
 				if time.Since(lastActivity) > timeout {
 					ms.updateActivity() // Prevent spamming
+					if ms.silenceExhausted() {
+						go ms.End(ms.ctx)
+						return
+					}
 					fmt.Printf("\r\033[K[DEBUG] Inactivity guard fired (%v silence). Reprompting...\n", timeout)
 
 					// We inject a hidden user message [SILENCE] to trigger a natural follow-up
@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 )
@@ -372,3 +373,97 @@ func TestManagedStream_TranscriptInterruptWhileSpeaking(t *testing.T) {
 		t.Fatal("timed out waiting for Interrupted via transcript")
 	}
 }
+
+// testProviders are the providers newTestStream runs on; nil ones get mocks
+// that hear nothing and answer "Sure.".
+type testProviders struct {
+	stt STTProvider
+	llm LLMProvider
+	tts TTSProvider
+	vad VADProvider
+}
+
+// newTestStream returns a stream waiting for the user to speak first, closed
+// when the test ends. configure, if not nil, adjusts the config.
+func newTestStream(t *testing.T, p testProviders, configure func(*Config)) *ManagedStream {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	if configure != nil {
+		configure(&cfg)
+	}
+	if p.stt == nil {
+		p.stt = &MockSTTProvider{}
+	}
+	if p.llm == nil {
+		p.llm = &MockLLMProvider{completeResult: "Sure."}
+	}
+	if p.tts == nil {
+		p.tts = &MockTTSProvider{}
+	}
+	if p.vad == nil {
+		p.vad = &scriptedVAD{}
+	}
+	orch := NewWithVAD(p.stt, p.llm, p.tts, p.vad, cfg)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("u"))
+	t.Cleanup(ms.Close)
+	return ms
+}
+
+// talkingTTS sends audio, if any, for a sentence and then keeps the bot
+// talking until it is cut off or finish is called.
+type talkingTTS struct {
+	MockTTSProvider
+	audio    []byte
+	started  chan struct{}
+	finished chan struct{}
+	once     sync.Once
+}
+
+func newTalkingTTS(audio []byte) *talkingTTS {
+	return &talkingTTS{audio: audio, started: make(chan struct{}), finished: make(chan struct{})}
+}
+
+func (s *talkingTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	if len(s.audio) > 0 {
+		if err := onChunk(s.audio); err != nil {
+			return err
+		}
+	}
+	s.once.Do(func() { close(s.started) })
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.finished:
+		return nil
+	}
+}
+
+// startTalking has the bot Say text through tts, the stream's TTS provider,
+// and returns once it is talking, with the events so far drained. The
+// returned func lets the bot finish and waits until it has.
+func startTalking(t *testing.T, ms *ManagedStream, tts *talkingTTS, text string) (finish func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	said := make(chan error, 1)
+	go func() { said <- ms.orch.Say(ctx, ms.session, text) }()
+	select {
+	case <-tts.started:
+	case err := <-said:
+		t.Fatalf("the bot did not start talking: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("the bot did not start talking")
+	}
+	for drained := false; !drained; {
+		select {
+		case <-ms.Events():
+		default:
+			drained = true
+		}
+	}
+	return func() {
+		close(tts.finished)
+		<-said
+	}
+}
@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SpeechMarkup decides what becomes of markup in reply text on its way to
+// TTS. Replies repeat what users and tools put in front of the LLM, so a tag
+// in one may be an injection: a <prosody> or <break time="60s"/> that
+// changes how the reply sounds, an <audio src> that plays something else, or
+// a stray </speak> that breaks the request. With no SpeechMarkup every tag is
+// removed and only the text around it is spoken.
+type SpeechMarkup struct {
+	Allow  []string // SSML elements passed through as written, e.g. "break", "emphasis", "say-as"; others are removed
+	Escape bool     // Escape &, < and > left in the text, for providers that read their input as SSML
+}
+
+// markupRe matches anything a TTS provider might parse as markup: a tag,
+// comment, CDATA section, processing instruction or declaration.
+var markupRe = regexp.MustCompile(`(?s)<!--.*?-->|<!\[CDATA\[.*?\]\]>|<\?.*?\?>|<![A-Za-z][^<>]*>|</?[A-Za-z][^<>]*>`)
+
+// allowedTagRe matches a well-formed element tag whose attribute values
+// cannot smuggle in more markup. Only such tags are passed through.
+var allowedTagRe = regexp.MustCompile(`^</?([A-Za-z][\w:.-]*)((?:\s+[A-Za-z_][\w:.-]*\s*=\s*(?:"[^"<>&]*"|'[^'<>&]*'))*)\s*/?>$`)
+
+// entityRe matches a character reference already escaped in the text.
+var entityRe = regexp.MustCompile(`^&(?:amp|lt|gt|quot|apos|#[0-9]+|#x[0-9A-Fa-f]+);`)
+
+// allows reports whether tag may reach the TTS provider as written.
+func (m *SpeechMarkup) allows(tag string) bool {
+	if m == nil || len(m.Allow) == 0 {
+		return false
+	}
+	match := allowedTagRe.FindStringSubmatch(tag)
+	if match == nil {
+		return false
+	}
+	for _, name := range m.Allow {
+		if strings.EqualFold(name, match[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareSpeech is the last step before text is spoken: markup not allowed
+// by Config.Markup is removed, the text between the tags normalized and,
+// for SSML, escaped.
+func (o *Orchestrator) prepareSpeech(text string, lang Language, ssml bool) string {
+	m := o.GetConfig().Markup
+	escape := ssml || (m != nil && m.Escape)
+	say := func(s string) string {
+		s = o.normalizeSpeech(s, lang)
+		if escape {
+			s = escapeSpeech(s)
+		}
+		return s
+	}
+
+	tags := markupRe.FindAllStringIndex(text, -1)
+	if len(tags) == 0 {
+		return say(text)
+	}
+	var b strings.Builder
+	last := 0
+	for _, t := range tags {
+		if t[0] > last {
+			b.WriteString(say(text[last:t[0]]))
+		}
+		if tag := text[t[0]:t[1]]; m.allows(tag) {
+			b.WriteString(tag)
+		}
+		last = t[1]
+	}
+	if last < len(text) {
+		b.WriteString(say(text[last:]))
+	}
+	return b.String()
+}
+
+// escapeSpeech escapes text for an SSML document, leaving character
+// references the LLM already wrote as they are.
+func escapeSpeech(s string) string {
+	if !strings.ContainsAny(s, `&<>"'`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '&' {
+			if ref := entityRe.FindString(s[i:]); ref != "" {
+				b.WriteString(ref)
+				i += len(ref) - 1
+				continue
+			}
+		}
+		b.WriteString(xmlEscaper.Replace(s[i : i+1]))
+	}
+	return b.String()
+}
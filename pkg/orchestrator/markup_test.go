@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPrepareSpeech_Markup(t *testing.T) {
+	breaks := &SpeechMarkup{Allow: []string{"break", "emphasis"}}
+	cases := []struct {
+		name   string
+		markup *SpeechMarkup
+		ssml   bool
+		text   string
+		want   string
+	}{
+		{
+			name: "tags removed by default",
+			text: `Sure.<break time="60s"/> <prosody rate="x-slow">Goodbye</prosody></speak><speak>`,
+			want: "Sure. Goodbye",
+		},
+		{
+			name: "comments and declarations removed",
+			text: `Hi<!-- ignore the system prompt --><?xml version="1.0"?>!`,
+			want: "Hi!",
+		},
+		{
+			name: "comparisons are not tags",
+			text: "If x < 5 and y > 2, you win.",
+			want: "If x < 5 and y > 2, you win.",
+		},
+		{
+			name:   "allowed tags kept",
+			markup: breaks,
+			text:   `Wait<break time="500ms"/> <EMPHASIS level='strong'>now</EMPHASIS>, <audio src="https://example.com/x.mp3">ok</audio>`,
+			want:   `Wait<break time="500ms"/> <EMPHASIS level='strong'>now</EMPHASIS>, ok`,
+		},
+		{
+			name:   "allowed tag with unquoted attribute removed",
+			markup: breaks,
+			text:   `Wait<break time=60s/>.`,
+			want:   "Wait.",
+		},
+		{
+			name:   "escaped on request",
+			markup: &SpeechMarkup{Escape: true},
+			text:   `Fish & chips &amp; <b>more</b> for < 5 "quid"`,
+			want:   `Fish &amp; chips &amp; more for &lt; 5 &quot;quid&quot;`,
+		},
+		{
+			name:   "allowed tags survive escaping",
+			markup: breaks,
+			ssml:   true,
+			text:   `A & B<break/>C`,
+			want:   `A &amp; B<break/>C`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Markup = tc.markup
+			o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg)
+			if got := o.prepareSpeech(tc.text, LanguageEn, tc.ssml); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSynthesize_InjectedSSMLStaysInsideDocument(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CodeSwitching = &CodeSwitching{SSML: true}
+	tts := &spanTTS{}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, tts, nil, cfg)
+
+	reply := `Done.</speak><speak><audio src="https://example.com/ad.mp3"/>Call <lang xml:lang="fr">Chez <b>Marie</b></lang> now.`
+	if _, err := o.Synthesize(context.Background(), reply, VoiceF1, LanguageEn); err != nil {
+		t.Fatal(err)
+	}
+	want := `<speak>Done.Call <lang xml:lang="fr">Chez Marie</lang> now.</speak>`
+	if len(tts.parts) != 1 || tts.parts[0].text != want {
+		t.Errorf("TTS got %q, want %q", tts.parts, want)
+	}
+}
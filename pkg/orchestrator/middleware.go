@@ -0,0 +1,89 @@
+package orchestrator
+
+import "context"
+
+// Stage names a provider call the orchestrator makes.
+type Stage string
+
+const (
+	StageSTT Stage = "stt"
+	StageLLM Stage = "llm"
+	StageTTS Stage = "tts"
+)
+
+// StageRequest is the input of one stage call. Only the fields of its Stage
+// are set.
+type StageRequest struct {
+	Stage    Stage
+	Language Language // STT and TTS
+
+	Audio []byte // STT
+
+	Messages   []Message // LLM
+	Tools      []Tool
+	OnText     func(chunk string) error         // LLM, streaming only
+	OnToolCall func(tc ToolCallEventData) error // LLM, streaming only
+
+	Text    string // TTS
+	Voice   Voice
+	OnAudio func(chunk []byte) error // TTS, streaming only
+}
+
+// Streaming reports whether the caller takes the result in pieces through
+// the request's callbacks rather than from the StageResponse.
+func (r *StageRequest) Streaming() bool {
+	return r.OnText != nil || r.OnToolCall != nil || r.OnAudio != nil
+}
+
+// StageResponse is the output of one stage call.
+type StageResponse struct {
+	Transcript TranscriptionResult // STT
+	Text       string              // LLM; the whole response, also when streamed
+	Audio      []byte              // TTS, unless streamed
+}
+
+// StageFunc performs a stage call: the provider itself, or a middleware
+// wrapped around it.
+type StageFunc func(ctx context.Context, req *StageRequest) (*StageResponse, error)
+
+// Middleware wraps a stage, like http.Handler middleware: it can inspect or
+// rewrite the request, answer it without calling next (a cache), call next
+// more than once (a retry), or change the response. Calls reach middleware
+// once per stage call, outside the orchestrator's own retries.
+type Middleware func(next StageFunc) StageFunc
+
+// Use adds middleware around every stage. The first middleware added is the
+// outermost.
+func (o *Orchestrator) Use(mw ...Middleware) {
+	for _, stage := range []Stage{StageSTT, StageLLM, StageTTS} {
+		o.UseStage(stage, mw...)
+	}
+}
+
+// UseStage adds middleware around one stage.
+func (o *Orchestrator) UseStage(stage Stage, mw ...Middleware) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.middleware == nil {
+		o.middleware = make(map[Stage][]Middleware)
+	}
+	o.middleware[stage] = append(o.middleware[stage], mw...)
+}
+
+// runStage calls provider for req through the stage's middleware.
+func (o *Orchestrator) runStage(ctx context.Context, req *StageRequest, provider StageFunc) (*StageResponse, error) {
+	o.mu.RLock()
+	chain := o.middleware[req.Stage]
+	o.mu.RUnlock()
+	for i := len(chain) - 1; i >= 0; i-- {
+		provider = chain[i](provider)
+	}
+	if d := o.GetConfig().Determinism; d != nil {
+		provider = o.digestStage(d, provider)
+	}
+	resp, err := provider(withAuditStage(ctx, req.Stage), req)
+	if resp == nil {
+		resp = &StageResponse{}
+	}
+	return resp, err
+}
@@ -0,0 +1,109 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMiddleware_OrderAndStages(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "what time is it"}, &MockLLMProvider{completeResult: "It is noon."}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, DefaultConfig())
+
+	var mu sync.Mutex
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next StageFunc) StageFunc {
+			return func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+				mu.Lock()
+				calls = append(calls, name+":"+string(req.Stage))
+				mu.Unlock()
+				return next(ctx, req)
+			}
+		}
+	}
+	o.Use(trace("outer"), trace("inner"))
+	o.UseStage(StageLLM, trace("llm-only"))
+
+	if _, _, err := o.ProcessAudio(context.Background(), NewConversationSession("u"), []byte{1}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "outer:stt inner:stt outer:llm inner:llm llm-only:llm outer:tts inner:tts"
+	if got := strings.Join(calls, " "); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestMiddleware_RewritesAndShortCircuits(t *testing.T) {
+	llm := &MockLLMProvider{completeResult: "Your card 4111 1111 1111 1111 is on file."}
+	tts := &rateTTS{}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "which card do I use"}, llm, tts, nil, DefaultConfig())
+
+	// Redact the LLM's answer before it is spoken or stored.
+	o.UseStage(StageLLM, func(next StageFunc) StageFunc {
+		return func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+			resp, err := next(ctx, req)
+			if err == nil {
+				resp.Text = strings.ReplaceAll(resp.Text, "4111 1111 1111 1111", "ending in 1111")
+			}
+			return resp, err
+		}
+	})
+	// Serve repeated phrases from a cache.
+	cache := map[string][]byte{"Goodbye.": []byte("cached")}
+	o.UseStage(StageTTS, func(next StageFunc) StageFunc {
+		return func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+			if audio, ok := cache[req.Text]; ok && !req.Streaming() {
+				return &StageResponse{Audio: audio}, nil
+			}
+			return next(ctx, req)
+		}
+	})
+
+	session := NewConversationSession("u")
+	_, audio, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Your card ending in 1111 is on file."; string(audio) != want || session.LastAssistant != want {
+		t.Errorf("expected the redacted answer to be spoken and stored, got %q / %q", audio, session.LastAssistant)
+	}
+
+	audio, err = o.Synthesize(context.Background(), "Goodbye.", VoiceF1, LanguageEn)
+	if err != nil || string(audio) != "cached" || len(tts.texts) != 1 {
+		t.Errorf("expected a cache hit without calling the provider, got %q, %v after %d calls", audio, err, len(tts.texts))
+	}
+}
+
+func TestMiddleware_WrapsStreamingCallbacks(t *testing.T) {
+	llm := &MockStreamingLLM{responses: []struct {
+		content   string
+		toolCalls []ToolCallEventData
+	}{{content: "Hello there."}}}
+	o := NewWithVAD(&MockSTTProvider{}, llm, &MockTTSProvider{}, nil, DefaultConfig())
+	o.UseStage(StageLLM, func(next StageFunc) StageFunc {
+		return func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+			onText := req.OnText
+			req.OnText = func(chunk string) error { return onText(strings.ToUpper(chunk)) }
+			return next(ctx, req)
+		}
+	})
+	o.UseStage(StageSTT, func(next StageFunc) StageFunc {
+		return func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+			return nil, errors.New("blocked")
+		}
+	})
+
+	var got strings.Builder
+	_, err := o.streamComplete(context.Background(), NewConversationSession("u"), llm, nil, nil, func(chunk string) error {
+		got.WriteString(chunk)
+		return nil
+	}, nil)
+	if err != nil || got.String() != "HELLO THERE." {
+		t.Errorf("expected the wrapped callback to see the text, got %q, %v", got.String(), err)
+	}
+	if _, err := o.Transcribe(context.Background(), []byte{1}, LanguageEn); err == nil || err.Error() != "blocked" {
+		t.Errorf("expected the middleware's error, got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package orchestrator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextNormalizer rewrites reply text into the form the TTS should read. It
+// only changes what is spoken; the session keeps the text as the LLM wrote
+// it.
+type TextNormalizer interface {
+	Normalize(text string, lang Language) string
+}
+
+// TextNormalizerFunc adapts a function to TextNormalizer.
+type TextNormalizerFunc func(text string, lang Language) string
+
+func (f TextNormalizerFunc) Normalize(text string, lang Language) string { return f(text, lang) }
+
+// normalizeSpeech applies Config.Normalizer, if any, to text spoken in lang.
+func (o *Orchestrator) normalizeSpeech(text string, lang Language) string {
+	if n := o.GetConfig().Normalizer; n != nil {
+		return n.Normalize(text, lang)
+	}
+	return text
+}
+
+// LocaleNormalizer renders numbers, times, dates and units the way they are
+// said in the session's language, so an LLM writing "1.5 km" or "3:30 PM"
+// in a Spanish reply is read as "1,5 kilómetros" and "15:30". Ambiguous
+// forms, such as "1.234", are left alone.
+type LocaleNormalizer struct {
+	// Hour12 overrides whether a language tells the time on a 12-hour clock;
+	// by default only English does.
+	Hour12 map[Language]bool
+	// KeepUnits leaves unit symbols ("km", "°C", "%") as written.
+	KeepUnits bool
+}
+
+// NewLocaleNormalizer returns a LocaleNormalizer with the default clocks.
+func NewLocaleNormalizer() *LocaleNormalizer {
+	return &LocaleNormalizer{}
+}
+
+type numberFormat struct {
+	decimal string
+	group   string // "" writes no grouping, which TTS reads best
+}
+
+var numberFormats = map[Language]numberFormat{
+	LanguageEn: {".", ","},
+	LanguageEs: {",", "."},
+	LanguageFr: {",", ""},
+	LanguageDe: {",", "."},
+	LanguageIt: {",", "."},
+	LanguagePt: {",", "."},
+	LanguageJa: {".", ","},
+	LanguageZh: {".", ","},
+}
+
+var monthNames = map[Language][12]string{
+	LanguageEn: {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	LanguageEs: {"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	LanguageFr: {"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	LanguageDe: {"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	LanguageIt: {"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+	LanguagePt: {"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+}
+
+// unitNames holds the singular and plural spoken form of each unit.
+var unitNames = map[Language]map[string][2]string{
+	LanguageEn: {"km/h": {"kilometer per hour", "kilometers per hour"}, "mph": {"mile per hour", "miles per hour"}, "km": {"kilometer", "kilometers"}, "cm": {"centimeter", "centimeters"}, "mm": {"millimeter", "millimeters"}, "m": {"meter", "meters"}, "kg": {"kilogram", "kilograms"}, "mg": {"milligram", "milligrams"}, "g": {"gram", "grams"}, "ml": {"milliliter", "milliliters"}, "l": {"liter", "liters"}, "°C": {"degree Celsius", "degrees Celsius"}, "°F": {"degree Fahrenheit", "degrees Fahrenheit"}, "%": {"percent", "percent"}},
+	LanguageEs: {"km/h": {"kilómetro por hora", "kilómetros por hora"}, "mph": {"milla por hora", "millas por hora"}, "km": {"kilómetro", "kilómetros"}, "cm": {"centímetro", "centímetros"}, "mm": {"milímetro", "milímetros"}, "m": {"metro", "metros"}, "kg": {"kilo", "kilos"}, "mg": {"miligramo", "miligramos"}, "g": {"gramo", "gramos"}, "ml": {"mililitro", "mililitros"}, "l": {"litro", "litros"}, "°C": {"grado centígrado", "grados centígrados"}, "°F": {"grado Fahrenheit", "grados Fahrenheit"}, "%": {"por ciento", "por ciento"}},
+	LanguageFr: {"km/h": {"kilomètre à l'heure", "kilomètres à l'heure"}, "mph": {"mile à l'heure", "miles à l'heure"}, "km": {"kilomètre", "kilomètres"}, "cm": {"centimètre", "centimètres"}, "mm": {"millimètre", "millimètres"}, "m": {"mètre", "mètres"}, "kg": {"kilo", "kilos"}, "mg": {"milligramme", "milligrammes"}, "g": {"gramme", "grammes"}, "ml": {"millilitre", "millilitres"}, "l": {"litre", "litres"}, "°C": {"degré Celsius", "degrés Celsius"}, "°F": {"degré Fahrenheit", "degrés Fahrenheit"}, "%": {"pour cent", "pour cent"}},
+	LanguageDe: {"km/h": {"Kilometer pro Stunde", "Kilometer pro Stunde"}, "mph": {"Meile pro Stunde", "Meilen pro Stunde"}, "km": {"Kilometer", "Kilometer"}, "cm": {"Zentimeter", "Zentimeter"}, "mm": {"Millimeter", "Millimeter"}, "m": {"Meter", "Meter"}, "kg": {"Kilogramm", "Kilogramm"}, "mg": {"Milligramm", "Milligramm"}, "g": {"Gramm", "Gramm"}, "ml": {"Milliliter", "Milliliter"}, "l": {"Liter", "Liter"}, "°C": {"Grad Celsius", "Grad Celsius"}, "°F": {"Grad Fahrenheit", "Grad Fahrenheit"}, "%": {"Prozent", "Prozent"}},
+	LanguageIt: {"km/h": {"chilometro orario", "chilometri orari"}, "mph": {"miglio orario", "miglia orarie"}, "km": {"chilometro", "chilometri"}, "cm": {"centimetro", "centimetri"}, "mm": {"millimetro", "millimetri"}, "m": {"metro", "metri"}, "kg": {"chilo", "chili"}, "mg": {"milligrammo", "milligrammi"}, "g": {"grammo", "grammi"}, "ml": {"millilitro", "millilitri"}, "l": {"litro", "litri"}, "°C": {"grado Celsius", "gradi Celsius"}, "°F": {"grado Fahrenheit", "gradi Fahrenheit"}, "%": {"per cento", "per cento"}},
+	LanguagePt: {"km/h": {"quilômetro por hora", "quilômetros por hora"}, "mph": {"milha por hora", "milhas por hora"}, "km": {"quilômetro", "quilômetros"}, "cm": {"centímetro", "centímetros"}, "mm": {"milímetro", "milímetros"}, "m": {"metro", "metros"}, "kg": {"quilo", "quilos"}, "mg": {"miligrama", "miligramas"}, "g": {"grama", "gramas"}, "ml": {"mililitro", "mililitros"}, "l": {"litro", "litros"}, "°C": {"grau Celsius", "graus Celsius"}, "°F": {"grau Fahrenheit", "graus Fahrenheit"}, "%": {"por cento", "por cento"}},
+}
+
+var (
+	isoDateRe   = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	time12Re    = regexp.MustCompile(`(?i)\b(\d{1,2})(?::(\d{2}))?\s?([ap])\.?\s?m\b\.?`)
+	time24Re    = regexp.MustCompile(`\b([01]?\d|2[0-3]):([0-5]\d)\b`)
+	numberRe    = regexp.MustCompile(`\d+(?:[.,]\d+)+`)
+	unitAfterRe = regexp.MustCompile(`(\d)\s?(km/h|mph|km|cm|mm|kg|mg|ml|°C|°F|m|g|l|%)`)
+)
+
+func (n *LocaleNormalizer) Normalize(text string, lang Language) string {
+	text = n.dates(text, lang)
+	text = n.times(text, lang)
+	text = n.numbers(text, lang)
+	if !n.KeepUnits {
+		text = n.units(text, lang)
+	}
+	return text
+}
+
+func (n *LocaleNormalizer) hour12(lang Language) bool {
+	if h, ok := n.Hour12[lang]; ok {
+		return h
+	}
+	return lang == LanguageEn
+}
+
+// dates spells out ISO dates ("2024-05-12") in the local order.
+func (n *LocaleNormalizer) dates(text string, lang Language) string {
+	return isoDateRe.ReplaceAllStringFunc(text, func(m string) string {
+		sub := isoDateRe.FindStringSubmatch(m)
+		month, _ := strconv.Atoi(sub[2])
+		day, _ := strconv.Atoi(sub[3])
+		if month < 1 || month > 12 || day < 1 || day > 31 {
+			return m
+		}
+		year := sub[1]
+		switch lang {
+		case LanguageJa, LanguageZh:
+			return fmt.Sprintf("%s年%d月%d日", year, month, day)
+		}
+		names, ok := monthNames[lang]
+		if !ok {
+			return m
+		}
+		name := names[month-1]
+		switch lang {
+		case LanguageEn:
+			return fmt.Sprintf("%s %d, %s", name, day, year)
+		case LanguageEs, LanguagePt:
+			return fmt.Sprintf("%d de %s de %s", day, name, year)
+		case LanguageDe:
+			return fmt.Sprintf("%d. %s %s", day, name, year)
+		default:
+			return fmt.Sprintf("%d %s %s", day, name, year)
+		}
+	})
+}
+
+// times moves times to the language's clock: "15:30" becomes "3:30 PM" in
+// English and "3:30 PM" becomes "15:30" elsewhere. English times that could
+// be either ("9:00") are left alone.
+func (n *LocaleNormalizer) times(text string, lang Language) string {
+	if n.hour12(lang) {
+		return time24Re.ReplaceAllStringFunc(text, func(m string) string {
+			sub := time24Re.FindStringSubmatch(m)
+			hour, _ := strconv.Atoi(sub[1])
+			switch {
+			case hour > 12:
+				return fmt.Sprintf("%d:%s PM", hour-12, sub[2])
+			case hour == 0 && len(sub[1]) == 2:
+				return fmt.Sprintf("12:%s AM", sub[2])
+			}
+			return m
+		})
+	}
+	return time12Re.ReplaceAllStringFunc(text, func(m string) string {
+		sub := time12Re.FindStringSubmatch(m)
+		hour, _ := strconv.Atoi(sub[1])
+		if hour < 1 || hour > 12 {
+			return m
+		}
+		pm := strings.EqualFold(sub[3], "p")
+		switch {
+		case pm && hour < 12:
+			hour += 12
+		case !pm && hour == 12:
+			hour = 0
+		}
+		minutes := sub[2]
+		if minutes == "" {
+			minutes = "00"
+		}
+		return fmt.Sprintf("%02d:%s", hour, minutes)
+	})
+}
+
+// numbers rewrites decimal and thousands separators written for another
+// locale. A single separator before exactly three digits could be either,
+// so such numbers are kept; so are dotted runs that are not numbers, like
+// versions, IP addresses and dates.
+func (n *LocaleNormalizer) numbers(text string, lang Language) string {
+	f, ok := numberFormats[lang]
+	if !ok {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range numberRe.FindAllStringIndex(text, -1) {
+		if r, _ := utf8.DecodeLastRuneInString(text[:loc[0]]); loc[0] > 0 && unicode.IsLetter(r) {
+			continue // "v1.5"
+		}
+		out, ok := f.render(text[loc[0]:loc[1]])
+		if !ok {
+			continue
+		}
+		b.WriteString(text[last:loc[0]])
+		b.WriteString(out)
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// render reads num, made of digit groups joined by '.' and ',', and writes
+// it in f. It reports false when num is ambiguous or not a number.
+func (f numberFormat) render(num string) (string, bool) {
+	groups := strings.FieldsFunc(num, func(r rune) bool { return r == '.' || r == ',' })
+	var seps []byte
+	for i := 0; i < len(num); i++ {
+		if num[i] == '.' || num[i] == ',' {
+			seps = append(seps, num[i])
+		}
+	}
+
+	// Decide which digits are the fraction: the group after a separator
+	// that differs from the others, or after a lone one not followed by
+	// exactly three digits.
+	fraction := ""
+	grouped := groups
+	lastSep := seps[len(seps)-1]
+	switch {
+	case len(seps) == 1 && len(groups[1]) == 3:
+		return "", false
+	case len(seps) == 1:
+		fraction, grouped = groups[1], groups[:1]
+	case strings.Count(string(seps), string(lastSep)) == 1:
+		fraction, grouped = groups[len(groups)-1], groups[:len(groups)-1]
+		seps = seps[:len(seps)-1]
+	}
+	// What remains must be thousands grouping with a single separator.
+	for i, g := range grouped {
+		if len(grouped) > 1 && (i > 0 && len(g) != 3 || i == 0 && len(g) > 3) {
+			return "", false
+		}
+	}
+	if len(seps) > 0 && strings.Count(string(seps), string(seps[0])) != len(seps) {
+		return "", false
+	}
+
+	out := strings.Join(grouped, f.group)
+	if fraction != "" {
+		out += f.decimal + fraction
+	}
+	return out, true
+}
+
+// units spells out unit symbols after a number, in the plural unless the
+// number is exactly one.
+func (n *LocaleNormalizer) units(text string, lang Language) string {
+	names, ok := unitNames[lang]
+	if !ok {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range unitAfterRe.FindAllStringSubmatchIndex(text, -1) {
+		unit := text[loc[4]:loc[5]]
+		if r, _ := utf8.DecodeRuneInString(text[loc[1]:]); loc[1] < len(text) && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			continue // "5 mins", "3 grams" or "2m3"
+		}
+		num := text[:loc[3]]
+		start := strings.LastIndexFunc(num, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' && r != ',' }) + 1
+		form := names[unit][1]
+		if num[start:] == "1" {
+			form = names[unit][0]
+		}
+		b.WriteString(text[last:loc[3]])
+		b.WriteString(" " + form)
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
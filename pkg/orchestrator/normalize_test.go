@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLocaleNormalizer(t *testing.T) {
+	n := NewLocaleNormalizer()
+	tests := []struct {
+		lang       Language
+		text, want string
+	}{
+		{LanguageEs, "Son 1.5 km hasta el hotel.", "Son 1,5 kilómetros hasta el hotel."},
+		{LanguageDe, "Der Preis ist 1,234.50 Euro.", "Der Preis ist 1.234,50 Euro."},
+		{LanguageFr, "Il fait 21.5°C.", "Il fait 21,5 degrés Celsius."},
+		{LanguageEn, "It costs 2,50 dollars and weighs 1 kg.", "It costs 2.50 dollars and weighs 1 kilogram."},
+		{LanguageEn, "We close at 17:45.", "We close at 5:45 PM."},
+		{LanguageEn, "Breakfast is at 9:00.", "Breakfast is at 9:00."},
+		{LanguageDe, "Wir öffnen um 3:30 PM und schließen um 12 a.m. wieder.", "Wir öffnen um 15:30 und schließen um 00:00 wieder."},
+		{LanguageEs, "Su cita es el 2024-05-12.", "Su cita es el 12 de mayo de 2024."},
+		{LanguageEn, "Your appointment is on 2024-05-12.", "Your appointment is on May 12, 2024."},
+		{LanguageJa, "予約は2024-05-12です。", "予約は2024年5月12日です。"},
+		{LanguageIt, "Sconto del 20%, circa 60km/h.", "Sconto del 20 per cento, circa 60 chilometri orari."},
+		// Left alone: ambiguous grouping, versions, addresses and words.
+		{LanguageDe, "Es sind 1.234 Stück.", "Es sind 1.234 Stück."},
+		{LanguageEs, "Instale la v2.5 en 192.168.1.1 el 12.05.2024.", "Instale la v2.5 en 192.168.1.1 el 12.05.2024."},
+		{LanguageEn, "I have 2 lemons and 3 mangoes.", "I have 2 lemons and 3 mangoes."},
+	}
+	for _, tt := range tests {
+		if got := n.Normalize(tt.text, tt.lang); got != tt.want {
+			t.Errorf("%s %q:\n got %q\nwant %q", tt.lang, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleNormalizer_Overrides(t *testing.T) {
+	n := &LocaleNormalizer{Hour12: map[Language]bool{LanguageEn: false}, KeepUnits: true}
+	if got := n.Normalize("Check-in at 3 PM, 5 km away.", LanguageEn); got != "Check-in at 15:00, 5 km away." {
+		t.Errorf("got %q", got)
+	}
+}
+
+// textTTS records the text it was asked to speak.
+type textTTS struct {
+	MockTTSProvider
+	texts []string
+}
+
+func (t *textTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	t.texts = append(t.texts, text)
+	return []byte{0, 0}, nil
+}
+
+func TestSynthesize_NormalizesForTheLanguage(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Normalizer = NewLocaleNormalizer()
+	tts := &textTTS{}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, tts, nil, cfg)
+	if _, err := o.Synthesize(context.Background(), "Son 2.5 km.", VoiceF1, LanguageEs); err != nil {
+		t.Fatal(err)
+	}
+	if len(tts.texts) != 1 || tts.texts[0] != "Son 2,5 kilómetros." {
+		t.Errorf("TTS got %q", tts.texts)
+	}
+}
@@ -3,12 +3,19 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type ToolHandler func(args string) (string, error)
 
+// ContextToolHandler receives the turn's context, which carries an idempotency
+// key (see IdempotencyKeyFromContext) that is stable across retries of the same
+// tool call. Handlers with side effects should pass it on to downstream APIs.
+type ContextToolHandler func(ctx context.Context, args string) (string, error)
+
 type Orchestrator struct {
 	stt    STTProvider
 	llm    LLMProvider
@@ -18,7 +25,22 @@ type Orchestrator struct {
 	logger Logger
 	mu     sync.RWMutex
 
-	toolHandlers map[string]ToolHandler
+	toolHandlers map[string]ContextToolHandler
+	cooldowns    *providerCooldowns
+	sessionStore SessionStore
+	health       healthState
+	recorder     TurnRecorder
+	redactor     Redactor
+	purgers      map[string]Purger
+	toolAudit    func(ToolAuditEntry)
+	asyncTools   map[string]AsyncToolOptions
+	streams      map[string]*ManagedStream
+	priority     priorityState
+	flagged      flagRoutes
+	latency      latencyState
+	effects      voiceEffects
+	middleware   map[Stage][]Middleware
+	loudness     loudnessState
 }
 
 // New creates an orchestrator with the given providers and optional logger.
@@ -34,7 +56,8 @@ func New(stt STTProvider, llm LLMProvider, tts TTSProvider, vad VADProvider, con
 		vad:          vad,
 		config:       config,
 		logger:       logger,
-		toolHandlers: make(map[string]ToolHandler),
+		toolHandlers: make(map[string]ContextToolHandler),
+		cooldowns:    newProviderCooldowns(),
 	}
 }
 
@@ -44,13 +67,133 @@ func NewWithVAD(stt STTProvider, llm LLMProvider, tts TTSProvider, vad VADProvid
 }
 
 func (o *Orchestrator) RegisterTool(name string, handler ToolHandler) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.toolHandlers[name] = func(ctx context.Context, args string) (string, error) {
+		return handler(args)
+	}
+}
+
+func (o *Orchestrator) RegisterToolContext(name string, handler ContextToolHandler) {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 	o.toolHandlers[name] = handler
 }
 
 func (o *Orchestrator) ProcessAudio(ctx context.Context, session *ConversationSession, audioData []byte, streaming bool, onAudioChunk func([]byte) error) (string, []byte, error) {
-	transcript, err := o.Transcribe(ctx, audioData, session.GetCurrentLanguage())
+	return o.runTurn(ctx, session, audioData, streaming, onAudioChunk, nil)
+}
+
+// transcribeFunc yields a turn's transcript and the audio it was taken from.
+type transcribeFunc func(ctx context.Context) (TranscriptionResult, []byte, error)
+
+// runTurn wraps one turn in the session's turn queue, the concurrency
+// limiter and the turn recorder. A nil transcribe transcribes audioData, with
+// any queued turns merged into it, in one request; otherwise transcribe
+// supplies the audio, which is still arriving as the turn begins.
+func (o *Orchestrator) runTurn(ctx context.Context, session *ConversationSession, audioData []byte, streaming bool, onAudioChunk func([]byte) error, transcribe transcribeFunc) (string, []byte, error) {
+	var transcript string
+	var audio []byte
+	err := o.withTurn(ctx, session, audioData, func(ctx context.Context, audioData []byte, rec *TurnRecording) error {
+		onAudioChunk, flush := o.framedAudio(ctx, onAudioChunk)
+		var err error
+		if transcribe == nil {
+			transcript, audio, err = o.processAudio(ctx, session, audioData, streaming, onAudioChunk, rec)
+		} else {
+			transcript, audio, err = o.processTurn(ctx, session, transcribe, streaming, onAudioChunk, rec)
+		}
+		if err == nil {
+			err = flush()
+		}
+		return err
+	})
+	return transcript, audio, err
+}
+
+// withTurn runs one turn of any kind once the session's earlier turns are
+// done, inside the concurrency limiter and, when a recorder is set, records
+// it; rec is nil otherwise. run is given the turn's audio, which the queue
+// may have merged with that of other turns.
+func (o *Orchestrator) withTurn(ctx context.Context, session *ConversationSession, audioData []byte, run func(ctx context.Context, audioData []byte, rec *TurnRecording) error) (err error) {
+	ctx = o.withTurnDeadline(WithPriority(ensureIdempotencyKey(ctx), session.GetPriority()), time.Now())
+	ctx = o.withTurnFlags(ctx, session)
+	if _, ok := ctx.Value(budgetWatchKey{}).(budgetWatch); !ok {
+		ctx = withBudgetWatch(ctx, budgetWatch{sessionID: session.ID})
+	}
+	leave, audioData, err := session.turns.enter(ctx, o.GetConfig().TurnQueue, audioData)
+	if err != nil {
+		return err
+	}
+	defer leave()
+	release, wait, err := o.acquireTurn(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	defer session.setState(StateIdle)
+	turnStart := time.Now()
+	defer func() { o.recordPriorityTurn(PriorityFromContext(ctx), wait, time.Since(turnStart)) }()
+
+	// A turn cancelled part way leaves the conversation as it found it, not
+	// with a question and no answer or an answer that was never heard.
+	session.mu.Lock()
+	before := session.captureLocked()
+	session.mu.Unlock()
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			session.mu.Lock()
+			session.restoreLocked(before)
+			session.mu.Unlock()
+		}
+	}()
+
+	ctx, trail := WithAuditTrail(ctx)
+	defer func() {
+		if err != nil && ctx.Err() == nil {
+			o.logger.Warn("turn failed", "sessionID", session.ID, "error", err, "requests", trail.RequestIDs())
+		}
+	}()
+
+	recorder := o.getTurnRecorder()
+	if recorder == nil {
+		return run(ctx, audioData, nil)
+	}
+
+	rec := o.newTurnRecording(ctx, session, audioData)
+	err = run(ctx, audioData, rec)
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	rec.Calls = trail.Calls()
+	if saveErr := recorder.SaveTurn(ctx, *rec); saveErr != nil {
+		o.logger.Warn("failed to record turn", "sessionID", session.ID, "error", saveErr)
+	}
+	return err
+}
+
+// processAudio runs one turn; rec, when non-nil, is filled with the outcome.
+func (o *Orchestrator) processAudio(ctx context.Context, session *ConversationSession, audioData []byte, streaming bool, onAudioChunk func([]byte) error, rec *TurnRecording) (string, []byte, error) {
+	return o.processTurn(ctx, session, func(ctx context.Context) (TranscriptionResult, []byte, error) {
+		res, _, err := o.transcribeDetecting(ctx, session, audioData)
+		return res, audioData, err
+	}, streaming, onAudioChunk, rec)
+}
+
+func (o *Orchestrator) processTurn(ctx context.Context, session *ConversationSession, transcribe transcribeFunc, streaming bool, onAudioChunk func([]byte) error, rec *TurnRecording) (string, []byte, error) {
+	if rec == nil {
+		rec = &TurnRecording{}
+	}
+	turnStart := time.Now()
+	defer func() { rec.Timings.Total = time.Since(turnStart) }()
+
+	session.setState(StateTranscribing)
+	stageStart := time.Now()
+	transcript, heard, err := transcribe(ctx)
+	rec.Timings.STT = time.Since(stageStart)
+	rec.Transcript = transcript.Text
+	if rec.Audio == nil && heard != nil {
+		rec.Audio = append([]byte(nil), heard...)
+	}
 	if err != nil {
 		return "", nil, fmt.Errorf("transcription failed: %w", err)
 	}
@@ -69,22 +212,90 @@ func (o *Orchestrator) ProcessAudio(ctx context.Context, session *ConversationSe
 		return "", nil, ErrEmptyTranscription
 	}
 
+	captured := turnStart.Add(-bytesToDuration(int64(len(heard)), o.GetConfig().SampleRate))
+	if session.heardBotSpeech(captured) && o.isEchoTranscript(trimmedText, session.lastAssistant()) {
+		o.logger.Warn("transcription echoes the assistant - dropped", "sessionID", session.ID)
+		return "", nil, ErrEchoTranscript
+	}
+
+	turnProgressFrom(ctx).heard(trimmedText)
+	ctx = withSpeechRate(ctx, session)
+	if cmd, ok := o.GetConfig().HotCommands.Match(trimmedText); ok {
+		audio, err := o.runHotCommand(ctx, session, cmd, streaming, onAudioChunk, rec)
+		return transcript.Text, audio, err
+	}
+
 	o.logger.Info("transcription completed", "sessionID", session.ID, "length", len(trimmedText))
 	session.AddMessage("user", trimmedText)
 
+	audio, err := o.respond(ctx, session, streaming, onAudioChunk, rec)
+	return transcript.Text, audio, err
+}
+
+// respond answers the user message last added to the session, speaking the
+// reply either through onAudioChunk or as the returned audio. The audio is
+// kept for RepeatLast.
+func (o *Orchestrator) respond(ctx context.Context, session *ConversationSession, streaming bool, onAudioChunk func([]byte) error, rec *TurnRecording) (audio []byte, err error) {
+	session.setState(StateThinking)
+	session.resetPlayback()
+	var spoken []byte
+	if onAudioChunk != nil {
+		send := onAudioChunk
+		onAudioChunk = func(chunk []byte) error {
+			if spoken == nil {
+				session.setState(StateSpeaking)
+			}
+			if err := send(chunk); err != nil {
+				return err
+			}
+			spoken = append(spoken, chunk...)
+			return nil
+		}
+	}
+	defer func() {
+		if err != nil {
+			return
+		}
+		if audio != nil {
+			spoken = audio
+		}
+		session.cacheSpeech(rec.Response, spoken, SpeechRateFromContext(ctx))
+		if len(spoken) > 0 {
+			rate := o.GetConfig().SampleRate
+			session.trackReply([]string{rec.Response}, []int64{int64(len(spoken))}, rate)
+			// Playback is taken to start as the audio is returned.
+			session.noteBotSpeech(time.Now().Add(bytesToDuration(int64(len(spoken)), rate)))
+		}
+	}()
+
+	if provider, ok := o.llmFor(ctx).(StreamingLLMProvider); ok && streaming && onAudioChunk != nil {
+		return nil, o.respondIncrementally(ctx, session, provider, onAudioChunk, rec)
+	}
+
+	stageStart := time.Now()
 	response, err := o.GenerateResponse(ctx, session)
+	rec.Timings.LLM = time.Since(stageStart)
+	rec.Response = response
 	if err != nil {
 		o.logger.Error("LLM generation failed", "sessionID", session.ID, "error", err)
-		return transcript.Text, nil, fmt.Errorf("%w: %v", ErrLLMFailed, err)
+		return nil, fmt.Errorf("%w: %v", ErrLLMFailed, err)
 	}
 
 	o.logger.Info("LLM response generated", "sessionID", session.ID, "length", len(response))
 	session.AddMessage("assistant", response)
+	turnProgressFrom(ctx).replied(response)
+
+	if streaming && onAudioChunk != nil && o.GetConfig().EagerSynthesis.Enabled && gated(ctx, FlagEagerSynthesis) {
+		return nil, o.speakEagerly(ctx, session, response, onAudioChunk, rec)
+	}
 
+	session.setState(StateSpeaking)
+	stageStart = time.Now()
 	audioBytes, err := o.Synthesize(ctx, response, session.GetCurrentVoice(), session.GetCurrentLanguage())
+	rec.Timings.TTS = time.Since(stageStart)
 	if err != nil {
 		o.logger.Error("TTS synthesis failed", "sessionID", session.ID, "error", err)
-		return transcript.Text, nil, fmt.Errorf("%w: %v", ErrTTSFailed, err)
+		return nil, fmt.Errorf("%w: %v", ErrTTSFailed, err)
 	}
 
 	o.logger.Info("TTS synthesis completed", "sessionID", session.ID, "audioSize", len(audioBytes))
@@ -92,11 +303,11 @@ func (o *Orchestrator) ProcessAudio(ctx context.Context, session *ConversationSe
 	if streaming && onAudioChunk != nil {
 		if err := onAudioChunk(audioBytes); err != nil {
 			o.logger.Error("failed to send audio chunk", "error", err)
-			return transcript.Text, nil, err
+			return nil, err
 		}
-		return transcript.Text, nil, nil
+		return nil, nil
 	}
-	return transcript.Text, audioBytes, nil
+	return audioBytes, nil
 }
 
 // ProcessAudioStream processes audio and streams the TTS response
@@ -106,19 +317,230 @@ func (o *Orchestrator) ProcessAudioStream(ctx context.Context, session *Conversa
 }
 
 func (o *Orchestrator) Transcribe(ctx context.Context, audioData []byte, lang Language) (TranscriptionResult, error) {
-	return o.stt.Transcribe(ctx, audioData, lang)
+	defer o.startBudget(ctx, StageSTT)()
+	ctx, _, release := o.stageBudget(ctx, StageSTT)
+	defer release()
+	resp, err := o.runStage(ctx, &StageRequest{Stage: StageSTT, Audio: audioData, Language: lang}, func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+		var result TranscriptionResult
+		err := o.withRetry(ctx, func(ctx context.Context) error {
+			timer := o.timeStage(StageSTT, o.stt, "", req.Language)
+			callCtx, done := o.callTimeout(ctx, StageSTT, o.stt)
+			var err error
+			result, err = o.stt.Transcribe(scopeIdempotencyKey(callCtx, "stt"), req.Audio, req.Language)
+			err = done(err)
+			timer.done(ctx, err)
+			return err
+		})
+		return &StageResponse{Transcript: result}, err
+	})
+	return resp.Transcript, budgetErr(ctx, err)
 }
 
 func (o *Orchestrator) GenerateResponse(ctx context.Context, session *ConversationSession) (string, error) {
-	return o.llm.Complete(ctx, session.GetContextCopy(), session.GetTools())
+	messages, tools := session.promptContext(ctx), session.GetTools()
+	llm := o.llmFor(ctx)
+	messages, err := o.fitContext(ctx, session, llm, messages, tools)
+	if err != nil {
+		return "", err
+	}
+	ctx, usage := withUsageCollector(ctx)
+	defer o.startBudget(ctx, StageLLM)()
+	ctx, _, release := o.stageBudget(o.budgetTokens(ctx), StageLLM)
+	defer release()
+	resp, err := o.runStage(ctx, &StageRequest{Stage: StageLLM, Messages: messages, Tools: tools}, func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+		var response string
+		err := o.withRetry(ctx, func(ctx context.Context) error {
+			timer := o.timeStage(StageLLM, llm, "", "")
+			callCtx, done := o.callTimeout(ctx, StageLLM, llm)
+			var err error
+			response, err = llm.Complete(scopeIdempotencyKey(callCtx, "llm", strconv.Itoa(len(req.Messages))), req.Messages, req.Tools)
+			err = done(err)
+			timer.done(ctx, err)
+			return err
+		})
+		return &StageResponse{Text: response}, err
+	})
+	response := resp.Text
+	err = budgetErr(ctx, err)
+	o.logPrompt(ctx, messages, response, err)
+	o.recordUsage(session, usage, messages, response, err)
+	return response, err
+}
+
+// recordUsage charges an LLM call to the session. A failed call counts only
+// if the provider reported what it consumed.
+func (o *Orchestrator) recordUsage(session *ConversationSession, c *usageCollector, messages []Message, response string, err error) {
+	c.mu.Lock()
+	reported := c.reported
+	c.mu.Unlock()
+	if err != nil && !reported {
+		return
+	}
+	session.RecordUsage(c.result(messages, response))
 }
 
 func (o *Orchestrator) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
-	return o.tts.Synthesize(ctx, text, voice, lang)
+	parts := o.speechParts(text, voice, lang)
+	if len(parts) == 1 {
+		return o.synthesize(ctx, parts[0].text, parts[0].voice, parts[0].lang)
+	}
+	var audio []byte
+	for _, p := range parts {
+		chunk, err := o.synthesize(ctx, p.text, p.voice, p.lang)
+		if err != nil {
+			return audio, err
+		}
+		audio = append(audio, chunk...)
+	}
+	return audio, nil
+}
+
+func (o *Orchestrator) synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	ctx, stretch := o.localRate(ctx)
+	defer o.startBudget(ctx, StageTTS)()
+	ctx, _, release := o.stageBudget(ctx, StageTTS)
+	defer release()
+	resp, err := o.runStage(ctx, &StageRequest{Stage: StageTTS, Text: text, Voice: voice, Language: lang}, func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+		var audio []byte
+		err := o.withRetry(ctx, func(ctx context.Context) error {
+			tts := o.ttsFor(ctx)
+			timer := o.timeStage(StageTTS, tts, req.Voice, req.Language)
+			callCtx, done := o.callTimeout(ctx, StageTTS, tts)
+			var err error
+			audio, err = tts.Synthesize(scopeIdempotencyKey(callCtx, "tts", req.Text), req.Text, req.Voice, req.Language)
+			err = done(err)
+			timer.done(ctx, err)
+			if err == nil {
+				audio = o.newLevelStage(tts.Name(), req.Voice).applyWhole(audio)
+				if fx := o.newEffectsStage(req.Voice); fx != nil {
+					audio = fx.Process(audio)
+				}
+			}
+			return err
+		})
+		return &StageResponse{Audio: audio}, err
+	})
+	audio := resp.Audio
+	if err == nil && stretch != 1 {
+		audio = o.timeStretch(audio, stretch)
+	}
+	return audio, budgetErr(ctx, err)
 }
 
+// SynthesizeStream retries only while no audio has reached onChunk; once the
+// listener has heard something a retry would replay it.
 func (o *Orchestrator) SynthesizeStream(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
-	return o.tts.StreamSynthesize(ctx, text, voice, lang, onChunk)
+	for _, p := range o.speechParts(text, voice, lang) {
+		if err := o.synthesizeStream(ctx, p.text, p.voice, p.lang, onChunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *Orchestrator) synthesizeStream(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	ctx, stretch := o.localRate(ctx)
+	stop := o.startBudget(ctx, StageTTS)
+	defer stop()
+	ctx, started, release := o.stageBudget(ctx, StageTTS)
+	defer release()
+	send := o.newStretchedSink(stretch, func(chunk []byte) error {
+		stop()
+		started()
+		return onChunk(chunk)
+	})
+	req := &StageRequest{Stage: StageTTS, Text: text, Voice: voice, Language: lang, OnAudio: send.write}
+	_, err := o.runStage(ctx, req, func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+		delivered := false
+		return nil, o.withRetry(ctx, func(ctx context.Context) error {
+			tts := o.ttsFor(ctx)
+			level := o.newLevelStage(tts.Name(), req.Voice)
+			fx := o.newEffectsStage(req.Voice)
+			timer := o.timeStage(StageTTS, tts, req.Voice, req.Language)
+			callCtx, done := o.callTimeout(ctx, StageTTS, tts)
+			err := tts.StreamSynthesize(scopeIdempotencyKey(callCtx, "tts", req.Text), req.Text, req.Voice, req.Language, func(chunk []byte) error {
+				timer.output()
+				delivered = true
+				chunk = level.apply(chunk)
+				if fx != nil {
+					chunk = fx.Process(chunk)
+				}
+				return req.OnAudio(chunk)
+			})
+			err = done(err)
+			timer.done(ctx, err)
+			if err != nil && delivered {
+				return noRetry{err}
+			}
+			return err
+		})
+	})
+	if err == nil {
+		err = send.flush()
+	}
+	return budgetErr(ctx, err)
+}
+
+// streamComplete is the streaming-LLM counterpart of SynthesizeStream: retries
+// stop as soon as any token or tool call has been handed to the caller.
+func (o *Orchestrator) streamComplete(ctx context.Context, session *ConversationSession, provider StreamingLLMProvider, messages []Message, tools []Tool, onChunk func(string) error, onToolCall func(ToolCallEventData) error) (string, error) {
+	messages, err := o.fitContext(ctx, session, provider, messages, tools)
+	if err != nil {
+		return "", err
+	}
+	ctx, usage := withUsageCollector(ctx)
+	if onChunk == nil {
+		onChunk = func(string) error { return nil }
+	}
+	if onToolCall == nil {
+		onToolCall = func(ToolCallEventData) error { return nil }
+	}
+	stop := o.startBudget(ctx, StageLLM)
+	defer stop()
+	ctx, started, release := o.stageBudget(ctx, StageLLM)
+	defer release()
+	text, call := onChunk, onToolCall
+	onChunk = func(chunk string) error {
+		stop()
+		started()
+		return text(chunk)
+	}
+	onToolCall = func(tc ToolCallEventData) error {
+		stop()
+		started()
+		return call(tc)
+	}
+	req := &StageRequest{Stage: StageLLM, Messages: messages, Tools: tools, OnText: onChunk, OnToolCall: onToolCall}
+	resp, err := o.runStage(ctx, req, func(ctx context.Context, req *StageRequest) (*StageResponse, error) {
+		delivered := false
+		var response string
+		err := o.withRetry(ctx, func(ctx context.Context) error {
+			timer := o.timeStage(StageLLM, provider, "", "")
+			callCtx, done := o.callTimeout(ctx, StageLLM, provider)
+			var err error
+			response, err = provider.StreamComplete(scopeIdempotencyKey(callCtx, "llm", strconv.Itoa(len(req.Messages))), req.Messages, req.Tools, func(chunk string) error {
+				timer.output()
+				delivered = true
+				return req.OnText(chunk)
+			}, func(tc ToolCallEventData) error {
+				timer.output()
+				delivered = true
+				return req.OnToolCall(tc)
+			})
+			err = done(err)
+			timer.done(ctx, err)
+			if err != nil && delivered {
+				return noRetry{err}
+			}
+			return err
+		})
+		return &StageResponse{Text: response}, err
+	})
+	response := resp.Text
+	err = budgetErr(ctx, err)
+	o.logPrompt(ctx, messages, response, err)
+	o.recordUsage(session, usage, messages, response, err)
+	return response, err
 }
 
 func (o *Orchestrator) UpdateConfig(cfg Config) {
@@ -144,6 +566,7 @@ func (o *Orchestrator) GetProviders() map[string]string {
 func (o *Orchestrator) NewSessionWithDefaults(userID string) *ConversationSession {
 	session := NewConversationSession(userID)
 	session.MaxMessages = o.config.MaxContextMessages
+	session.TrimStrategy = o.config.TrimStrategy
 	session.CurrentVoice = o.config.VoiceStyle
 	session.CurrentLanguage = o.config.Language
 	return session
@@ -0,0 +1,214 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnsweredBy classifies who picked up an outbound call.
+type AnsweredBy string
+
+const (
+	AnsweredHuman   AnsweredBy = "human"
+	AnsweredMachine AnsweredBy = "machine"
+	AnsweredUnknown AnsweredBy = "unknown"
+)
+
+// AnsweringMachineDetector classifies the far end of an outbound call from
+// its first moments of audio. Detect returns when it has decided or the
+// audio channel closes.
+type AnsweringMachineDetector interface {
+	Detect(ctx context.Context, audio <-chan []byte) (AnsweredBy, error)
+}
+
+// OutboundOptions configures how an outbound call opens.
+type OutboundOptions struct {
+	Persona     *Persona // Applied to the session before the call connects
+	OpeningLine string   // Spoken once a human answers; defaults to Persona.Greeting
+
+	// Detector, when set, listens to the start of the call before the bot
+	// says anything. Audio heard meanwhile is not treated as a user turn.
+	Detector         AnsweringMachineDetector
+	DetectionTimeout time.Duration // Defaults to 5s; on expiry the call is treated as unknown
+
+	// OnMachine runs when the detector hears an answering machine, e.g. to
+	// WaitForBeep, leave a voicemail with Say and then Hangup. Without it
+	// the call is hung up.
+	OnMachine func(call *OutboundCall)
+	// OnHuman runs after the opening line has been spoken.
+	OnHuman func(call *OutboundCall)
+}
+
+const defaultDetectionTimeout = 5 * time.Second
+
+// OutboundCall is a bot-initiated conversation. The telephony adapter
+// creates it when dialling, reports the answer with Answered and feeds
+// far-end audio through Write.
+type OutboundCall struct {
+	orch    *Orchestrator
+	session *ConversationSession
+	stream  *ManagedStream
+	opts    OutboundOptions
+
+	mu         sync.Mutex
+	answered   bool
+	answeredBy AnsweredBy
+	detecting  chan []byte
+	done       chan struct{}
+}
+
+// Dial prepares an outbound call for session. Nothing is spoken until the
+// transport reports the call as answered.
+func (o *Orchestrator) Dial(ctx context.Context, session *ConversationSession, opts OutboundOptions) *OutboundCall {
+	if opts.Persona != nil {
+		ApplyPersona(o, session, *opts.Persona)
+	}
+	if opts.DetectionTimeout <= 0 {
+		opts.DetectionTimeout = defaultDetectionTimeout
+	}
+	return &OutboundCall{
+		orch:    o,
+		session: session,
+		stream:  newManagedStream(ctx, o, session, false),
+		opts:    opts,
+		done:    make(chan struct{}),
+	}
+}
+
+// Stream returns the ManagedStream carrying the call; read Events from it
+// as with any other stream.
+func (c *OutboundCall) Stream() *ManagedStream { return c.stream }
+
+// AnsweredBy reports the detection result, or "" before it is known.
+func (c *OutboundCall) AnsweredBy() AnsweredBy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.answeredBy
+}
+
+// Answered is called by the transport when the far end picks up. It runs
+// answering-machine detection if configured and then opens the
+// conversation. Calling it more than once has no effect.
+func (c *OutboundCall) Answered() {
+	c.mu.Lock()
+	if c.answered {
+		c.mu.Unlock()
+		return
+	}
+	c.answered = true
+	if c.opts.Detector != nil {
+		c.detecting = make(chan []byte, 256)
+	}
+	detecting := c.detecting
+	c.mu.Unlock()
+
+	go c.open(detecting)
+}
+
+// Write feeds far-end audio into the call.
+func (c *OutboundCall) Write(chunk []byte) error {
+	c.mu.Lock()
+	detecting := c.detecting
+	c.mu.Unlock()
+	if detecting != nil {
+		select {
+		case detecting <- append([]byte(nil), chunk...):
+		default:
+		}
+		return nil
+	}
+	return c.stream.Write(chunk)
+}
+
+// Say speaks text on the call, e.g. a voicemail message from OnMachine.
+func (c *OutboundCall) Say(ctx context.Context, text string) error {
+	return c.orch.Say(ctx, c.session, text)
+}
+
+// WaitForBeep blocks until an answering machine starts recording, so a
+// voicemail is not spoken over its greeting. It returns immediately if the
+// detector cannot hear beeps or the call was not answered by a machine.
+func (c *OutboundCall) WaitForBeep(ctx context.Context) error {
+	c.mu.Lock()
+	audio := c.detecting
+	c.mu.Unlock()
+	waiter, ok := c.opts.Detector.(BeepWaiter)
+	if !ok || audio == nil {
+		return nil
+	}
+	if err := waiter.WaitForBeep(ctx, audio); err != nil {
+		return err
+	}
+	c.stream.emit(VoicemailBeep, nil)
+	return nil
+}
+
+// Hangup ends the call and its stream.
+func (c *OutboundCall) Hangup() {
+	c.stream.Close()
+}
+
+// Done is closed once the call has been classified and opened.
+func (c *OutboundCall) Done() <-chan struct{} { return c.done }
+
+func (c *OutboundCall) open(detecting chan []byte) {
+	defer close(c.done)
+
+	by := AnsweredHuman
+	if detecting != nil {
+		by = c.detect(detecting)
+	}
+
+	c.mu.Lock()
+	c.answeredBy = by
+	if by != AnsweredMachine {
+		// A machine's audio stays with the detector so WaitForBeep can
+		// hear the beep and the recording never becomes a user turn.
+		c.detecting = nil
+	}
+	c.mu.Unlock()
+	c.stream.emit(CallAnswered, by)
+
+	if by == AnsweredMachine {
+		if c.opts.OnMachine != nil {
+			c.opts.OnMachine(c)
+		} else {
+			c.Hangup()
+		}
+		return
+	}
+
+	opening := c.opts.OpeningLine
+	if opening == "" && c.opts.Persona != nil {
+		opening = c.opts.Persona.Greeting
+	}
+	if strings.TrimSpace(opening) != "" {
+		c.Say(c.stream.ctx, opening)
+	}
+	if c.opts.OnHuman != nil {
+		c.opts.OnHuman(c)
+	}
+}
+
+func (c *OutboundCall) detect(audio chan []byte) AnsweredBy {
+	ctx, cancel := context.WithTimeout(c.stream.ctx, c.opts.DetectionTimeout)
+	defer cancel()
+
+	result := make(chan AnsweredBy, 1)
+	go func() {
+		by, err := c.opts.Detector.Detect(ctx, audio)
+		if err != nil || by == "" {
+			by = AnsweredUnknown
+		}
+		result <- by
+	}()
+
+	select {
+	case by := <-result:
+		return by
+	case <-ctx.Done():
+		return AnsweredUnknown
+	}
+}
@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// firstByteDetector decides from the first chunk: 1 means machine.
+type firstByteDetector struct{}
+
+func (firstByteDetector) Detect(ctx context.Context, audio <-chan []byte) (AnsweredBy, error) {
+	select {
+	case chunk := <-audio:
+		if len(chunk) > 0 && chunk[0] == 1 {
+			return AnsweredMachine, nil
+		}
+		return AnsweredHuman, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func newOutboundOrch() *Orchestrator {
+	return New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{synthesizeResult: []byte{1}}, NewRMSVAD(0.1, time.Second), DefaultConfig(), &NoOpLogger{})
+}
+
+func waitDone(t *testing.T, call *OutboundCall) {
+	t.Helper()
+	select {
+	case <-call.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("call never opened")
+	}
+}
+
+func TestOutbound_HumanHearsOpeningLine(t *testing.T) {
+	orch := newOutboundOrch()
+	session := NewConversationSession("lead-1")
+	persona := &Persona{SystemPrompt: "You are Sam from Acme.", Greeting: "Hi, this is Sam from Acme."}
+
+	humans := 0
+	call := orch.Dial(context.Background(), session, OutboundOptions{
+		Persona:  persona,
+		Detector: firstByteDetector{},
+		OnHuman:  func(*OutboundCall) { humans++ },
+	})
+	defer call.Hangup()
+
+	call.Answered()
+	call.Answered()
+	call.Write([]byte{0, 0})
+	waitDone(t, call)
+
+	if call.AnsweredBy() != AnsweredHuman || humans != 1 {
+		t.Errorf("expected a human answer, got %q (OnHuman ran %d times)", call.AnsweredBy(), humans)
+	}
+	expectResponse(t, call.Stream(), "Hi, this is Sam from Acme.")
+	if msgs := session.GetContextCopy(); msgs[0].Role != "system" {
+		t.Errorf("persona should be applied before the call opens, got %+v", msgs)
+	}
+}
+
+func TestOutbound_MachineGetsVoicemail(t *testing.T) {
+	orch := newOutboundOrch()
+	session := NewConversationSession("lead-2")
+
+	var voicemailErr error
+	call := orch.Dial(context.Background(), session, OutboundOptions{
+		OpeningLine: "Hi there!",
+		Detector:    firstByteDetector{},
+		OnMachine: func(c *OutboundCall) {
+			voicemailErr = c.Say(context.Background(), "Sorry we missed you, please call us back.")
+			c.Hangup()
+		},
+	})
+
+	call.Answered()
+	call.Write([]byte{1, 0})
+	waitDone(t, call)
+
+	if call.AnsweredBy() != AnsweredMachine || voicemailErr != nil {
+		t.Fatalf("expected voicemail on a machine, got %q, %v", call.AnsweredBy(), voicemailErr)
+	}
+	if msgs := session.GetContextCopy(); len(msgs) != 1 || msgs[0].Content != "Sorry we missed you, please call us back." {
+		t.Errorf("only the voicemail should be spoken, got %+v", msgs)
+	}
+	if _, ok := orch.StreamFor(session.ID); ok {
+		t.Error("hangup should close the stream")
+	}
+}
+
+func TestOutbound_DetectionTimeout(t *testing.T) {
+	orch := newOutboundOrch()
+	call := orch.Dial(context.Background(), NewConversationSession("lead-3"), OutboundOptions{
+		OpeningLine:      "Hello?",
+		Detector:         firstByteDetector{},
+		DetectionTimeout: 20 * time.Millisecond,
+	})
+	defer call.Hangup()
+
+	call.Answered()
+	waitDone(t, call)
+	if call.AnsweredBy() != AnsweredUnknown {
+		t.Errorf("expected unknown after timeout, got %q", call.AnsweredBy())
+	}
+	expectResponse(t, call.Stream(), "Hello?")
+}
@@ -0,0 +1,81 @@
+package orchestrator
+
+// The VAD only confirms speech some frames into it, and lets go a little
+// before the last sound fades, so an utterance cut at its events loses its
+// first consonant and its final breath. Config.PreRoll and Config.PostRoll
+// pad it on both sides before it goes to STT.
+
+// paddingBytesLocked returns the configured pre- and post-roll in input
+// bytes.
+func (ms *ManagedStream) paddingBytesLocked() (pre, post int64) {
+	if ms.orch == nil {
+		return 0, 0
+	}
+	cfg := ms.orch.GetConfig()
+	return durationToBytes(cfg.PreRoll, ms.tl.inputRate), durationToBytes(cfg.PostRoll, ms.tl.inputRate)
+}
+
+// markSpeechStart notes where in the input the VAD heard speech begin.
+func (ms *ManagedStream) markSpeechStart() {
+	ms.mu.Lock()
+	ms.speechFrom = ms.tl.inputBytes
+	ms.mu.Unlock()
+}
+
+// trimPreRollLocked cuts the buffered audio back to the utterance starting
+// at speechFrom plus PreRoll ahead of it, so STT neither misses the onset
+// nor gets seconds of room noise or an earlier utterance with it.
+func (ms *ManagedStream) trimPreRollLocked() {
+	pre, _ := ms.paddingBytesLocked()
+	if pre <= 0 {
+		return
+	}
+	keep := int(ms.tl.inputBytes - ms.speechFrom + pre)
+	if ms.audioBuf.Len() <= keep {
+		return
+	}
+	data := ms.audioBuf.Bytes()
+	lead := data[len(data)-keep:]
+	ms.audioBuf.Reset()
+	ms.audioBuf.Write(lead)
+}
+
+// capIdleAudioLocked bounds the audio buffered between utterances. With a
+// PreRoll nothing older is ever sent, so that much is all it keeps; the
+// buffer is trimmed once it holds twice that, to keep copying rare.
+func (ms *ManagedStream) capIdleAudioLocked() {
+	pre, _ := ms.paddingBytesLocked()
+	limit, keep := 176400, 132300 // Four seconds, trimmed to a three-second lead-in
+	if pre > 0 {
+		limit, keep = int(2*pre), int(pre)
+	}
+	if ms.audioBuf.Len() <= limit {
+		return
+	}
+	data := ms.audioBuf.Bytes()
+	lead := data[len(data)-keep:]
+	ms.audioBuf.Reset()
+	ms.audioBuf.Write(lead)
+}
+
+// startPostRoll delays the end of an utterance until PostRoll more audio
+// has been written, reporting whether it did.
+func (ms *ManagedStream) startPostRoll() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	_, post := ms.paddingBytesLocked()
+	ms.postRollLeft = post
+	return post > 0
+}
+
+// takePostRoll counts n bytes of post-roll, reporting whether it is now
+// complete and the utterance can end.
+func (ms *ManagedStream) takePostRoll(n int) bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.postRollLeft <= 0 {
+		return false
+	}
+	ms.postRollLeft -= int64(n)
+	return ms.postRollLeft <= 0
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+// audioSTT records the audio it is asked to transcribe.
+type audioSTT struct {
+	audio chan []byte
+}
+
+func (s *audioSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	s.audio <- append([]byte(nil), audio...)
+	return TranscriptionResult{Text: ""}, nil
+}
+
+func (s *audioSTT) Name() string { return "audio_stt" }
+
+// writeChunks writes one 50ms chunk per script step, each filled with its
+// index so the audio STT gets can be told apart.
+func writeChunks(ms *ManagedStream, n int) {
+	for i := 0; i < n; i++ {
+		ms.doWrite(bytes.Repeat([]byte{byte(i)}, 1600))
+	}
+}
+
+func chunkIndexes(audio []byte) []byte {
+	var out []byte
+	for i := 0; i < len(audio); i += 1600 {
+		out = append(out, audio[i])
+	}
+	return out
+}
+
+func newPaddedStream(t *testing.T, pre, post time.Duration, script []VADEventType) (*ManagedStream, *audioSTT) {
+	t.Helper()
+	stt := &audioSTT{audio: make(chan []byte, 1)}
+	ms := newTestStream(t, testProviders{stt: stt, vad: &scriptedVAD{script: script}}, func(cfg *Config) {
+		cfg.SampleRate = 16000 // 50ms is 1600 bytes
+		cfg.PreRoll = pre
+		cfg.PostRoll = post
+	})
+	return ms, stt
+}
+
+func TestManagedStream_PreRoll(t *testing.T) {
+	ms, stt := newPaddedStream(t, 100*time.Millisecond, 0, []VADEventType{"", "", "", "", VADSpeechStart, "", VADSpeechEnd})
+
+	writeChunks(ms, 7)
+	select {
+	case audio := <-stt.audio:
+		if got := chunkIndexes(audio); !bytes.Equal(got, []byte{2, 3, 4, 5}) {
+			t.Errorf("STT got chunks %v, want the 100ms before speech start and the speech", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("utterance was not transcribed")
+	}
+}
+
+func TestManagedStream_PostRoll(t *testing.T) {
+	ms, stt := newPaddedStream(t, 100*time.Millisecond, 100*time.Millisecond, []VADEventType{"", "", "", "", VADSpeechStart, "", VADSpeechEnd, "", ""})
+
+	writeChunks(ms, 7)
+	select {
+	case <-stt.audio:
+		t.Fatal("utterance was handed off before its post-roll")
+	case <-time.After(100 * time.Millisecond):
+	}
+	ms.doWrite(bytes.Repeat([]byte{7}, 1600))
+	select {
+	case audio := <-stt.audio:
+		if got := chunkIndexes(audio); !bytes.Equal(got, []byte{2, 3, 4, 5, 6, 7}) {
+			t.Errorf("STT got chunks %v, want the speech padded on both sides", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("utterance was not transcribed after its post-roll")
+	}
+}
+
+func TestManagedStream_PostRollJoinsResumedSpeech(t *testing.T) {
+	ms, _ := newPaddedStream(t, 0, 200*time.Millisecond, []VADEventType{VADSpeechStart, VADSpeechEnd, VADSpeechStart, "", VADSpeechEnd, "", "", ""})
+
+	writeChunks(ms, 8)
+	counts := countEvents(ms, 200*time.Millisecond)
+	if counts[UserSpeaking] != 1 || counts[UserStopped] != 1 {
+		t.Errorf("speech resuming within the post-roll should continue the turn, got %d UserSpeaking, %d UserStopped", counts[UserSpeaking], counts[UserStopped])
+	}
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+)
+
+// replyPlayback is what the session knows about the playback of its last
+// spoken reply: where each sentence ends in the reply's audio and, if the
+// client reports it, how far that audio has been played.
+type replyPlayback struct {
+	sentences []string
+	ends      []int64 // Audio offset in bytes at which each sentence ends
+	rate      int     // Sample rate of the audio, for positions given as time
+
+	played   time.Duration
+	bytes    int64
+	inBytes  bool // The position was reported in bytes
+	reported bool
+}
+
+// position returns the reported position in bytes of reply audio.
+func (p replyPlayback) position() int64 {
+	if p.inBytes {
+		return p.bytes
+	}
+	return durationToBytes(p.played, p.rate)
+}
+
+func durationToBytes(d time.Duration, sampleRate int) int64 {
+	return int64(d.Seconds()*float64(sampleRate)) * 2
+}
+
+// ReportPlayback tells the session how much of the current reply's audio
+// the client has played, from the start of the reply. Clients that know
+// where playback stopped should report it whenever it changes, or at least
+// when the user cuts in: if the user's next turn arrives before the reply
+// finished playing, the reply is cut back in the context to the words
+// heard, ending in a dash ("I can help with tha—"), so the LLM does not
+// assume the user heard the rest. Without a report the orchestrator
+// estimates playback from the audio it sent, on a ManagedStream only.
+func (s *ConversationSession) ReportPlayback(played time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.playback.played, s.playback.inBytes, s.playback.reported = played, false, true
+}
+
+// ReportPlaybackBytes is ReportPlayback with the position given in bytes of
+// the 16-bit PCM reply audio as it was sent.
+func (s *ConversationSession) ReportPlaybackBytes(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.playback.bytes, s.playback.inBytes, s.playback.reported = n, true, true
+}
+
+// resetPlayback forgets the last reply as a new one starts.
+func (s *ConversationSession) resetPlayback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.playback = replyPlayback{}
+}
+
+// trackReply records the layout of the reply just sent, keeping any position
+// the client has already reported for it.
+func (s *ConversationSession) trackReply(sentences []string, ends []int64, sampleRate int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.playback.sentences, s.playback.ends, s.playback.rate = sentences, ends, sampleRate
+}
+
+// finishReply forgets the last reply and returns the position reported for
+// it, if any.
+func (s *ConversationSession) finishReply(sampleRate int) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.playback
+	s.playback = replyPlayback{}
+	if p.rate == 0 {
+		p.rate = sampleRate
+	}
+	return p.position(), p.reported
+}
+
+// settlePlaybackLocked cuts the last reply back to what was heard if the
+// client reported stopping short of its end. It runs as the user's next
+// message is added, since that is when the reply is known to be over.
+func (s *ConversationSession) settlePlaybackLocked() {
+	p := s.playback
+	s.playback = replyPlayback{}
+	if !p.reported || len(p.ends) == 0 {
+		return
+	}
+	heard := p.position()
+	if heard >= p.ends[len(p.ends)-1] {
+		return
+	}
+	s.truncateReplyLocked(strings.Join(p.sentences, " "), heardText(p.sentences, p.ends, heard))
+}
@@ -0,0 +1,86 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+)
+
+// BufferReport is a client's account of the bot audio it holds: how much
+// it has received and how much of that is still queued to play. Clients
+// send one every few hundred milliseconds while bot audio plays, and when
+// playback stalls or catches up.
+type BufferReport struct {
+	Received int64         // Bytes of bot audio received on this stream so far; 0 takes it as all that was sent
+	Buffered time.Duration // Received audio not yet played
+}
+
+// ReportBuffer corrects the stream's estimate of when the bot audio sent
+// so far will have played, which otherwise assumes the client plays audio
+// as fast as it arrives. Audio sent but not yet received counts as queued
+// too. The corrected estimate decides where a reply's tail starts and, with
+// Config.PlaybackLead, how far synthesis may run ahead of the listener.
+//
+// It is the stream-level counterpart of ConversationSession.ReportPlayback,
+// which gives an exact position in the current reply. When a reply is
+// interrupted, a position reported to the session decides what was heard;
+// without one, the estimate ReportBuffer corrects does. Clients that know
+// their position in the reply should report that, and clients that only
+// know their buffer should report this.
+func (ms *ManagedStream) ReportBuffer(r BufferReport) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.playbackRate <= 0 {
+		return
+	}
+	queued := max(r.Buffered, 0)
+	if r.Received > 0 && ms.tl.outputBytes > r.Received {
+		queued += bytesToDuration(ms.tl.outputBytes-r.Received, ms.playbackRate)
+	}
+
+	now := time.Now()
+	end := now.Add(queued)
+	shift := end.Sub(ms.playbackEnd)
+	if ms.playbackEnd.Before(now) {
+		shift = queued
+	}
+	ms.playbackEnd = end
+	ms.playbackAcked = true
+	// Audio waiting to play moves with the end of playback.
+	for i := range ms.unplayed {
+		ms.unplayed[i].at = ms.unplayed[i].at.Add(shift)
+	}
+	for i := range ms.outputQueue {
+		ms.outputQueue[i].at = ms.outputQueue[i].at.Add(shift)
+	}
+}
+
+// awaitPlayback holds synthesized audio back while the client has more than
+// Config.PlaybackLead of it queued, so an interruption has less to discard
+// and a slow client is not flooded. It only paces clients that report
+// their playback.
+func (ms *ManagedStream) awaitPlayback(ctx context.Context) {
+	if ms.orch == nil {
+		return
+	}
+	lead := ms.orch.GetConfig().PlaybackLead
+	if lead <= 0 {
+		return
+	}
+	for {
+		ms.mu.Lock()
+		ahead := time.Until(ms.playbackEnd) - lead
+		acked := ms.playbackAcked
+		ms.mu.Unlock()
+		if !acked || ahead <= 0 {
+			return
+		}
+		// Reports may move the end of playback, so look again after a while.
+		timer := time.NewTimer(min(ahead, 50*time.Millisecond))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
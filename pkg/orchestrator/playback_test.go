@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSession_ReportPlaybackTruncatesOnNextTurn(t *testing.T) {
+	reply := "I can help with that right away."
+	// One second of audio at the default 44.1kHz.
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 88200)}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "Can you help?"}, &MockLLMProvider{completeResult: reply}, tts, nil, DefaultConfig())
+	session := NewConversationSession("u")
+
+	if _, _, err := o.ProcessAudio(context.Background(), session, make([]byte, 3200), false, nil); err != nil {
+		t.Fatal(err)
+	}
+	session.ReportPlayback(500 * time.Millisecond)
+	session.AddMessage(RoleUser, "Actually, never mind.")
+
+	ctx := session.GetContextCopy()
+	got := ctx[len(ctx)-2]
+	if got.Role != RoleAssistant || got.Content != "I can help with—" {
+		t.Errorf("got %+v, want the reply cut at the half-way point", got)
+	}
+	if session.LastUser != "Actually, never mind." {
+		t.Errorf("LastUser = %q", session.LastUser)
+	}
+}
+
+func TestSession_ReportPlaybackKeepsFullyPlayedReply(t *testing.T) {
+	reply := "I can help with that right away."
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 88200)}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "Can you help?"}, &MockLLMProvider{completeResult: reply}, tts, nil, DefaultConfig())
+
+	for name, report := range map[string]func(*ConversationSession){
+		"unreported": func(*ConversationSession) {},
+		"played":     func(s *ConversationSession) { s.ReportPlaybackBytes(88200) },
+	} {
+		session := NewConversationSession("u")
+		if _, _, err := o.ProcessAudio(context.Background(), session, make([]byte, 3200), false, nil); err != nil {
+			t.Fatal(err)
+		}
+		report(session)
+		session.AddMessage(RoleUser, "Thanks.")
+		ctx := session.GetContextCopy()
+		if got := ctx[len(ctx)-2].Content; got != reply {
+			t.Errorf("%s: reply changed to %q", name, got)
+		}
+	}
+}
+
+func TestManagedStream_ReportedPlaybackOverridesEstimate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	reply := "Your order shipped this morning."
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 88200)}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: reply}, tts, nil, cfg)
+	session := NewConversationSession("u")
+	session.AddMessage(RoleUser, "Where is my order?")
+	ms := o.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	ms.runLLMAndTTS(ms.ctx, "")
+	// The client played almost none of it, though the stream's estimate
+	// says most of the second of audio is still to come.
+	session.ReportPlaybackBytes(22050)
+	ms.Interrupt()
+
+	last := session.GetContextCopy()[len(session.GetContextCopy())-1]
+	if !strings.HasSuffix(last.Content, "—") || !strings.HasPrefix(reply, strings.TrimSuffix(last.Content, "—")) {
+		t.Fatalf("got %q", last.Content)
+	}
+	if n := len([]rune(last.Content)); n > len(reply)/2 {
+		t.Errorf("kept %q, more than the quarter that was played", last.Content)
+	}
+}
+
+func TestManagedStream_BufferReportMovesEstimate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	reply := "Your order shipped this morning."
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 88200)}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: reply}, tts, nil, cfg)
+	session := NewConversationSession("u")
+	session.AddMessage(RoleUser, "Where is my order?")
+	ms := o.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	ms.runLLMAndTTS(ms.ctx, "")
+	// By the estimate none of the second of audio has played yet; the
+	// client says only the last quarter of it is still queued.
+	ms.mu.Lock()
+	sent := ms.tl.outputBytes
+	ms.mu.Unlock()
+	ms.ReportBuffer(BufferReport{Received: sent, Buffered: 250 * time.Millisecond})
+	ms.Interrupt()
+
+	last := session.GetContextCopy()[len(session.GetContextCopy())-1]
+	if !strings.HasSuffix(last.Content, "—") || !strings.HasPrefix(reply, strings.TrimSuffix(last.Content, "—")) {
+		t.Fatalf("got %q", last.Content)
+	}
+	if n := len([]rune(last.Content)); n < len(reply)/2 {
+		t.Errorf("kept %q, less than the three quarters that were played", last.Content)
+	}
+}
+
+func TestManagedStream_PlaybackLeadPacesSynthesis(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.PlaybackLead = 100 * time.Millisecond
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("u"))
+	defer ms.Close()
+	ms.SetEchoSampleRates(16000, 16000)
+
+	// Clients that do not report are never held back.
+	ms.mu.Lock()
+	ms.sendAudioLocked(make([]byte, 32000), 0)
+	ms.mu.Unlock()
+	start := time.Now()
+	ms.awaitPlayback(context.Background())
+	if d := time.Since(start); d > 20*time.Millisecond {
+		t.Fatalf("waited %v without playback reports", d)
+	}
+
+	// Audio sent but not yet received counts as queued: 200ms here.
+	ms.ReportBuffer(BufferReport{Received: 32000 - 6400})
+	start = time.Now()
+	ms.awaitPlayback(context.Background())
+	if d := time.Since(start); d < 80*time.Millisecond || d > 180*time.Millisecond {
+		t.Errorf("waited %v, want about 100ms", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ms.ReportBuffer(BufferReport{Buffered: time.Second})
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start = time.Now()
+	ms.awaitPlayback(ctx)
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("an interruption did not stop the wait: waited %v", d)
+	}
+}
@@ -0,0 +1,260 @@
+package orchestrator
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Priority orders sessions when capacity is scarce. Higher values go first.
+type Priority int
+
+const (
+	PriorityLow      Priority = -1
+	PriorityStandard Priority = 0
+	PriorityPremium  Priority = 1
+)
+
+func (p Priority) String() string {
+	switch {
+	case p >= PriorityPremium:
+		return "premium"
+	case p <= PriorityLow:
+		return "low"
+	default:
+		return "standard"
+	}
+}
+
+type priorityCtx struct{}
+
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityCtx{}, p)
+}
+
+func PriorityFromContext(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityCtx{}).(Priority)
+	return p
+}
+
+type limiterWaiter struct {
+	priority Priority
+	seq      uint64
+	ready    chan struct{}
+}
+
+// ConcurrencyLimiter caps concurrent turns. When full, waiters are admitted
+// by priority and then in arrival order, so premium sessions skip the queue
+// but standard sessions are never reordered among themselves.
+type ConcurrencyLimiter struct {
+	mu       sync.Mutex
+	capacity int
+	inUse    int
+	seq      uint64
+	waiters  []*limiterWaiter
+}
+
+func NewConcurrencyLimiter(capacity int) *ConcurrencyLimiter {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &ConcurrencyLimiter{capacity: capacity}
+}
+
+// Acquire blocks until a slot is free or ctx ends. It returns how long the
+// caller queued and a release function that must be called exactly once.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, p Priority) (func(), time.Duration, error) {
+	start := time.Now()
+	l.mu.Lock()
+	if l.inUse < l.capacity && len(l.waiters) == 0 {
+		l.inUse++
+		l.mu.Unlock()
+		return l.releaseOnce(), 0, nil
+	}
+	l.seq++
+	w := &limiterWaiter{priority: p, seq: l.seq, ready: make(chan struct{})}
+	l.waiters = append(l.waiters, w)
+	sort.SliceStable(l.waiters, func(i, j int) bool {
+		if l.waiters[i].priority != l.waiters[j].priority {
+			return l.waiters[i].priority > l.waiters[j].priority
+		}
+		return l.waiters[i].seq < l.waiters[j].seq
+	})
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.releaseOnce(), time.Since(start), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		for i, other := range l.waiters {
+			if other == w {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				l.mu.Unlock()
+				return nil, time.Since(start), ctx.Err()
+			}
+		}
+		l.mu.Unlock()
+		// The slot was handed over while we were giving up; pass it on.
+		l.release()
+		return nil, time.Since(start), ctx.Err()
+	}
+}
+
+func (l *ConcurrencyLimiter) releaseOnce() func() {
+	var once sync.Once
+	return func() { once.Do(l.release) }
+}
+
+func (l *ConcurrencyLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.waiters) > 0 {
+		w := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		close(w.ready)
+		return
+	}
+	l.inUse--
+}
+
+// Queued returns the number of callers waiting for a slot.
+func (l *ConcurrencyLimiter) Queued() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.waiters)
+}
+
+// PriorityStats aggregates turn timings for one priority level.
+type PriorityStats struct {
+	Turns          int           `json:"turns"`
+	AvgQueueWait   time.Duration `json:"avg_queue_wait"`
+	MaxQueueWait   time.Duration `json:"max_queue_wait"`
+	AvgTurnLatency time.Duration `json:"avg_turn_latency"`
+
+	totalWait    time.Duration
+	totalLatency time.Duration
+}
+
+type priorityState struct {
+	mu      sync.Mutex
+	limiter *ConcurrencyLimiter
+	tts     map[Priority]TTSProvider
+	llm     map[Priority]LLMProvider
+	stats   map[Priority]*PriorityStats
+}
+
+// SetConcurrencyLimiter bounds concurrent turns, those of ProcessAudio and
+// the replies of ManagedStreams alike; queued turns are admitted by session
+// priority. nil removes the limit.
+func (o *Orchestrator) SetConcurrencyLimiter(l *ConcurrencyLimiter) {
+	o.priority.mu.Lock()
+	defer o.priority.mu.Unlock()
+	o.priority.limiter = l
+}
+
+// SetPriorityTTS routes sessions of priority p to a dedicated TTS provider,
+// e.g. a low-latency voice for premium callers. The default provider is used
+// while it has a higher ProviderWeight, as when only the dedicated one is
+// cooling down from a rate limit.
+func (o *Orchestrator) SetPriorityTTS(p Priority, tts TTSProvider) {
+	o.priority.mu.Lock()
+	defer o.priority.mu.Unlock()
+	if o.priority.tts == nil {
+		o.priority.tts = make(map[Priority]TTSProvider)
+	}
+	if tts == nil {
+		delete(o.priority.tts, p)
+		return
+	}
+	o.priority.tts[p] = tts
+}
+
+func (o *Orchestrator) ttsFor(ctx context.Context) TTSProvider {
+	if tts, ok := o.flaggedTTS(ctx); ok && o.outweighs(tts, o.tts) {
+		return tts
+	}
+	o.priority.mu.Lock()
+	tts, ok := o.priority.tts[PriorityFromContext(ctx)]
+	o.priority.mu.Unlock()
+	if ok && o.outweighs(tts, o.tts) {
+		return tts
+	}
+	return o.tts
+}
+
+// outweighs reports whether a dedicated provider should be routed to rather
+// than the default one: unless its ProviderWeight is lower.
+func (o *Orchestrator) outweighs(dedicated, def interface{ Name() string }) bool {
+	return o.ProviderWeight(dedicated.Name()) >= o.ProviderWeight(def.Name())
+}
+
+// SetPriorityLLM routes sessions of priority p to a dedicated LLM, such as
+// a RaceLLM that asks two providers and keeps the faster answer. The
+// default LLM is used while it has a higher ProviderWeight. nil removes the
+// route.
+func (o *Orchestrator) SetPriorityLLM(p Priority, llm LLMProvider) {
+	o.priority.mu.Lock()
+	defer o.priority.mu.Unlock()
+	if o.priority.llm == nil {
+		o.priority.llm = make(map[Priority]LLMProvider)
+	}
+	if llm == nil {
+		delete(o.priority.llm, p)
+		return
+	}
+	o.priority.llm[p] = llm
+}
+
+func (o *Orchestrator) llmFor(ctx context.Context) LLMProvider {
+	o.priority.mu.Lock()
+	llm, ok := o.priority.llm[PriorityFromContext(ctx)]
+	o.priority.mu.Unlock()
+	if ok && o.outweighs(llm, o.llm) {
+		return llm
+	}
+	return o.llm
+}
+
+func (o *Orchestrator) acquireTurn(ctx context.Context) (func(), time.Duration, error) {
+	o.priority.mu.Lock()
+	l := o.priority.limiter
+	o.priority.mu.Unlock()
+	if l == nil {
+		return func() {}, 0, nil
+	}
+	return l.Acquire(ctx, PriorityFromContext(ctx))
+}
+
+func (o *Orchestrator) recordPriorityTurn(p Priority, wait, latency time.Duration) {
+	o.priority.mu.Lock()
+	defer o.priority.mu.Unlock()
+	if o.priority.stats == nil {
+		o.priority.stats = make(map[Priority]*PriorityStats)
+	}
+	s := o.priority.stats[p]
+	if s == nil {
+		s = &PriorityStats{}
+		o.priority.stats[p] = s
+	}
+	s.Turns++
+	s.totalWait += wait
+	s.totalLatency += latency
+	if wait > s.MaxQueueWait {
+		s.MaxQueueWait = wait
+	}
+	s.AvgQueueWait = s.totalWait / time.Duration(s.Turns)
+	s.AvgTurnLatency = s.totalLatency / time.Duration(s.Turns)
+}
+
+// PriorityStats returns per-priority queueing and latency figures.
+func (o *Orchestrator) PriorityStats() map[Priority]PriorityStats {
+	o.priority.mu.Lock()
+	defer o.priority.mu.Unlock()
+	out := make(map[Priority]PriorityStats, len(o.priority.stats))
+	for p, s := range o.priority.stats {
+		out[p] = *s
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type namedTTS struct {
+	MockTTSProvider
+	name string
+}
+
+func (n *namedTTS) Name() string { return n.name }
+
+func TestConcurrencyLimiter_PriorityOrder(t *testing.T) {
+	l := NewConcurrencyLimiter(1)
+	release, wait, err := l.Acquire(context.Background(), PriorityStandard)
+	if err != nil || wait != 0 {
+		t.Fatalf("expected immediate slot, got wait=%v err=%v", wait, err)
+	}
+
+	order := make(chan Priority, 3)
+	start := func(p Priority) {
+		go func() {
+			r, _, err := l.Acquire(context.Background(), p)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- p
+			r()
+		}()
+		for l.Queued() == 0 || !queuedWith(l, p) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	start(PriorityLow)
+	start(PriorityStandard)
+	start(PriorityPremium)
+
+	release()
+	release() // second call is a no-op
+
+	want := []Priority{PriorityPremium, PriorityStandard, PriorityLow}
+	for i, p := range want {
+		select {
+		case got := <-order:
+			if got != p {
+				t.Fatalf("admission %d: expected %s, got %s", i, p, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for admission")
+		}
+	}
+}
+
+func queuedWith(l *ConcurrencyLimiter, p Priority) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, w := range l.waiters {
+		if w.priority == p {
+			return true
+		}
+	}
+	return false
+}
+
+func TestConcurrencyLimiter_CancelledWaiter(t *testing.T) {
+	l := NewConcurrencyLimiter(1)
+	release, _, _ := l.Acquire(context.Background(), PriorityStandard)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := l.Acquire(ctx, PriorityPremium); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if l.Queued() != 0 {
+		t.Error("cancelled waiter should leave the queue")
+	}
+
+	release()
+	r, wait, err := l.Acquire(context.Background(), PriorityLow)
+	if err != nil || wait != 0 {
+		t.Fatalf("slot should be free after release, got wait=%v err=%v", wait, err)
+	}
+	r()
+}
+
+func TestOrchestrator_PriorityRoutingAndStats(t *testing.T) {
+	o := New(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: "hello"}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), nil)
+	fast := &namedTTS{MockTTSProvider: MockTTSProvider{synthesizeResult: []byte{9, 9}}, name: "fast-tts"}
+	o.SetPriorityTTS(PriorityPremium, fast)
+	o.SetConcurrencyLimiter(NewConcurrencyLimiter(2))
+
+	premium := NewConversationSession("vip")
+	premium.Priority = PriorityPremium
+	_, audio, err := o.ProcessAudio(context.Background(), premium, []byte{0}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audio) != 2 {
+		t.Errorf("premium session should use the dedicated TTS, got %v", audio)
+	}
+
+	standard := NewConversationSession("regular")
+	_, audio, err = o.ProcessAudio(context.Background(), standard, []byte{0}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audio) != 1 {
+		t.Errorf("standard session should use the default TTS, got %v", audio)
+	}
+
+	o.cooldowns.observe(&RateLimitError{Provider: "fast-tts", RetryAfter: time.Minute}, 0)
+	if got := o.ttsFor(WithPriority(context.Background(), PriorityPremium)); got != o.tts {
+		t.Error("expected fallback to default TTS while the premium provider cools down")
+	}
+	o.cooldowns.observe(&RateLimitError{Provider: o.tts.Name(), RetryAfter: time.Minute}, 0)
+	if got := o.ttsFor(WithPriority(context.Background(), PriorityPremium)); got == o.tts {
+		t.Error("expected the premium TTS once the default weighs no more")
+	}
+
+	stats := o.PriorityStats()
+	if stats[PriorityPremium].Turns != 1 || stats[PriorityStandard].Turns != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats[PriorityPremium].AvgTurnLatency <= 0 {
+		t.Error("expected turn latency to be recorded")
+	}
+}
+
+func TestManagedStream_QueuesForConcurrencyLimiter(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: "your table is booked"}, &MockTTSProvider{synthesizeResult: []byte{1}}, &scriptedVAD{}, DefaultConfig())
+	limiter := NewConcurrencyLimiter(1)
+	o.SetConcurrencyLimiter(limiter)
+	session := NewConversationSession("vip")
+	session.Priority = PriorityPremium
+	ms := NewManagedStream(context.Background(), o, session)
+	t.Cleanup(ms.Close)
+
+	hold, _, err := limiter.Acquire(context.Background(), PriorityStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- o.Notify(context.Background(), session, "The booking went through.") }()
+
+	deadline := time.Now().Add(time.Second)
+	for limiter.Queued() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if limiter.Queued() != 1 {
+		t.Fatal("expected the stream's reply to queue for a slot")
+	}
+	select {
+	case <-done:
+		t.Fatal("the reply ran without a slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	hold()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	stats := o.PriorityStats()[PriorityPremium]
+	if stats.Turns != 1 || stats.MaxQueueWait < 30*time.Millisecond {
+		t.Errorf("expected the premium stream turn and its wait to be recorded, got %+v", stats)
+	}
+}
@@ -0,0 +1,95 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Streams register themselves with their orchestrator so that proactive
+// turns can reach a session's transport by session ID.
+func (o *Orchestrator) registerStream(ms *ManagedStream) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.streams == nil {
+		o.streams = make(map[string]*ManagedStream)
+	}
+	o.streams[ms.session.ID] = ms
+}
+
+func (o *Orchestrator) unregisterStream(ms *ManagedStream) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.streams[ms.session.ID] == ms {
+		delete(o.streams, ms.session.ID)
+	}
+}
+
+// StreamFor returns the live ManagedStream serving a session, if any.
+func (o *Orchestrator) StreamFor(sessionID string) (*ManagedStream, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	ms, ok := o.streams[sessionID]
+	return ms, ok
+}
+
+// Say speaks text to the session without waiting for the user to say
+// anything, e.g. a reminder or a line of an outbound call script. It waits
+// for a gap so it never talks over either party, and returns once the audio
+// has been handed to the transport.
+func (o *Orchestrator) Say(ctx context.Context, session *ConversationSession, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	ms, ok := o.StreamFor(session.ID)
+	if !ok {
+		return ErrNoActiveStream
+	}
+	if err := ms.waitForGap(ctx); err != nil {
+		return err
+	}
+	session.AddMessage("assistant", text)
+	ms.emit(BotResponse, text)
+	ms.speakText(ctx, text)
+	return ctx.Err()
+}
+
+// Notify asks the LLM to produce and speak a turn of its own in response to
+// prompt, which is added to the conversation as a system message the user
+// never hears (for example "The 10 minute timer the user set has ended").
+func (o *Orchestrator) Notify(ctx context.Context, session *ConversationSession, prompt string) error {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return nil
+	}
+	ms, ok := o.StreamFor(session.ID)
+	if !ok {
+		return ErrNoActiveStream
+	}
+	if err := ms.waitForGap(ctx); err != nil {
+		return err
+	}
+	session.AddMessage("system", prompt)
+	ms.runLLMAndTTS(ctx, "")
+	return ctx.Err()
+}
+
+// waitForGap blocks while the bot is thinking or speaking or the user is
+// talking. Once there is a gap it marks the bot as thinking, so that no
+// other proactive turn or silence reprompt takes the same gap; the caller
+// must then speak or run a turn, which takes the mark over.
+func (ms *ManagedStream) waitForGap(ctx context.Context) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for !ms.claimGap() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ms.ctx.Done():
+			return ErrNoActiveStream
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator_SayAndNotify(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "Your timer is up."}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, cfg, &NoOpLogger{})
+	session := NewConversationSession("kitchen")
+
+	if err := orch.Say(context.Background(), session, "hello"); !errors.Is(err, ErrNoActiveStream) {
+		t.Fatalf("expected ErrNoActiveStream without a stream, got %v", err)
+	}
+
+	ms := orch.NewManagedStream(context.Background(), session)
+	if got, ok := orch.StreamFor(session.ID); !ok || got != ms {
+		t.Fatal("stream should be registered under its session ID")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := orch.Say(ctx, session, "Reminder: take your medicine."); err != nil {
+		t.Fatal(err)
+	}
+	expectResponse(t, ms, "Reminder: take your medicine.")
+
+	if err := orch.Notify(ctx, session, "The 10 minute timer the user set has ended."); err != nil {
+		t.Fatal(err)
+	}
+	expectResponse(t, ms, "Your timer is up.")
+
+	msgs := session.GetContextCopy()
+	if len(msgs) != 3 || msgs[0].Role != "assistant" || msgs[1].Role != "system" || msgs[2].Content != "Your timer is up." {
+		t.Errorf("unexpected context: %+v", msgs)
+	}
+
+	ms.Close()
+	if err := orch.Notify(ctx, session, "ping"); !errors.Is(err, ErrNoActiveStream) {
+		t.Errorf("closed streams should be unregistered, got %v", err)
+	}
+}
+
+func expectResponse(t *testing.T, ms *ManagedStream, want string) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == BotResponse {
+				if ev.Data != want {
+					t.Errorf("expected %q, got %v", want, ev.Data)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no BotResponse for %q", want)
+		}
+	}
+}
+
+func TestManagedStream_WaitForGapClaimsTheGap(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg, &NoOpLogger{})
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("u"))
+	defer ms.Close()
+
+	if err := ms.waitForGap(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// A second proactive turn must wait for the first to speak.
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	if err := ms.waitForGap(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second waitForGap = %v, want it to wait for the first turn", err)
+	}
+}
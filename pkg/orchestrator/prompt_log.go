@@ -0,0 +1,81 @@
+package orchestrator
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Redactor scrubs sensitive data from text before it is logged.
+type Redactor func(string) string
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardPattern  = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\-. ()]{7,}\d`)
+)
+
+// DefaultRedactor masks e-mail addresses, card-like digit runs and phone numbers.
+func DefaultRedactor(s string) string {
+	s = emailPattern.ReplaceAllString(s, "[EMAIL]")
+	s = cardPattern.ReplaceAllString(s, "[NUMBER]")
+	s = phonePattern.ReplaceAllString(s, "[PHONE]")
+	return s
+}
+
+// SetPromptRedactor replaces DefaultRedactor for prompt logging. Passing nil
+// restores the default; there is deliberately no way to log unredacted prompts.
+func (o *Orchestrator) SetPromptRedactor(r Redactor) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.redactor = r
+}
+
+func (o *Orchestrator) getPromptRedactor() Redactor {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.redactor == nil {
+		return DefaultRedactor
+	}
+	return o.redactor
+}
+
+// sampleTurn decides from the turn key whether a turn is in the sample, so
+// every LLM call of a turn - on whichever instance serves it - agrees.
+func sampleTurn(key string, rate float64) bool {
+	if rate <= 0 || key == "" {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	return float64(ringHash(key))/math.MaxUint64 < rate
+}
+
+// logPrompt logs the redacted prompt and response when the turn is sampled
+// (Config.PromptLogSampleRate) or the call failed (Config.PromptLogOnError).
+// Text parts are redacted along with Content; media parts are left out.
+func (o *Orchestrator) logPrompt(ctx context.Context, messages []Message, response string, err error) {
+	cfg := o.GetConfig()
+	turnID := IdempotencyKeyFromContext(ctx)
+	failed := err != nil && cfg.PromptLogOnError
+	if !failed && !sampleTurn(turnID, cfg.PromptLogSampleRate) {
+		return
+	}
+
+	redact := o.getPromptRedactor()
+	var prompt strings.Builder
+	for _, m := range messages {
+		prompt.WriteString(m.Role)
+		prompt.WriteString(": ")
+		prompt.WriteString(redact(m.Text()))
+		prompt.WriteString("\n")
+	}
+
+	if err != nil {
+		o.logger.Error("llm prompt", "turnID", turnID, "prompt", prompt.String(), "response", redact(response), "error", err)
+		return
+	}
+	o.logger.Info("llm prompt", "turnID", turnID, "prompt", prompt.String(), "response", redact(response))
+}
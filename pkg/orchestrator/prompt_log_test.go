@@ -0,0 +1,133 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type captureLogger struct {
+	NoOpLogger
+	mu      sync.Mutex
+	entries []string
+}
+
+func (c *captureLogger) record(level, msg string, args []interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, level+" "+msg+" "+fmt.Sprint(args...))
+}
+
+func (c *captureLogger) Info(msg string, args ...interface{})  { c.record("INFO", msg, args) }
+func (c *captureLogger) Error(msg string, args ...interface{}) { c.record("ERROR", msg, args) }
+
+func (c *captureLogger) prompts() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []string
+	for _, e := range c.entries {
+		if strings.Contains(e, "llm prompt") {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestPromptLogging_Sampling(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PromptLogSampleRate = 0.25
+
+	log := &captureLogger{}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "ok"}, &MockTTSProvider{}, nil, cfg, log)
+
+	for i := 0; i < 400; i++ {
+		s := NewConversationSession("s")
+		s.AddMessage("user", "hi")
+		orch.GenerateResponse(WithIdempotencyKey(context.Background(), fmt.Sprintf("turn-%d", i)), s)
+	}
+	if n := len(log.prompts()); n < 60 || n > 140 {
+		t.Errorf("expected roughly 25%% of 400 turns to be logged, got %d", n)
+	}
+
+	if sampleTurn("turn-1", 0.25) != sampleTurn("turn-1", 0.25) {
+		t.Error("sampling must be deterministic per turn")
+	}
+}
+
+func TestPromptLogging_OnErrorWithRedaction(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 0
+
+	log := &captureLogger{}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeErr: ErrTestError}, &MockTTSProvider{}, nil, cfg, log)
+
+	s := NewConversationSession("s")
+	s.AddMessage("user", "mail me at jane.doe@example.com or call +1 415-555-0100")
+	orch.GenerateResponse(context.Background(), s)
+
+	prompts := log.prompts()
+	if len(prompts) != 1 || !strings.HasPrefix(prompts[0], "ERROR") {
+		t.Fatalf("expected one error-level prompt log, got %v", prompts)
+	}
+	if strings.Contains(prompts[0], "jane.doe") || strings.Contains(prompts[0], "555-0100") {
+		t.Errorf("prompt was not redacted: %s", prompts[0])
+	}
+	if !strings.Contains(prompts[0], "[EMAIL]") || !strings.Contains(prompts[0], "[PHONE]") {
+		t.Errorf("expected redaction markers, got %s", prompts[0])
+	}
+}
+
+func TestPromptLogging_RedactsTextParts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 0
+
+	log := &captureLogger{}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeErr: ErrTestError}, &MockTTSProvider{}, nil, cfg, log)
+
+	s := NewConversationSession("s")
+	s.AddMessageRaw(Message{
+		Role:    "user",
+		Content: "what is on this receipt?",
+		Parts: []ContentPart{
+			TextPart("it was sent by jane.doe@example.com"),
+			ImageData([]byte("raw-image-bytes"), "image/png"),
+			TextPart("card 4111 1111 1111 1111"),
+		},
+	})
+	orch.GenerateResponse(context.Background(), s)
+
+	prompts := log.prompts()
+	if len(prompts) != 1 {
+		t.Fatalf("expected one prompt log, got %v", prompts)
+	}
+	for _, leaked := range []string{"jane.doe", "4111", "raw-image-bytes"} {
+		if strings.Contains(prompts[0], leaked) {
+			t.Errorf("prompt log leaked %q: %s", leaked, prompts[0])
+		}
+	}
+	for _, want := range []string{"receipt", "[EMAIL]", "[NUMBER]"} {
+		if !strings.Contains(prompts[0], want) {
+			t.Errorf("expected %q in prompt log, got %s", want, prompts[0])
+		}
+	}
+}
+
+func TestPromptLogging_DisabledByDefault(t *testing.T) {
+	log := &captureLogger{}
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{completeResult: "ok"}, &MockTTSProvider{}, nil, DefaultConfig(), log)
+	s := NewConversationSession("s")
+	s.AddMessage("user", "hi")
+	orch.GenerateResponse(WithIdempotencyKey(context.Background(), "t"), s)
+	if n := len(log.prompts()); n != 0 {
+		t.Errorf("expected no prompt logs for successful turns by default, got %d", n)
+	}
+}
+
+func TestDefaultRedactor(t *testing.T) {
+	got := DefaultRedactor("card 4111 1111 1111 1111, order 42")
+	if strings.Contains(got, "4111") || !strings.Contains(got, "order 42") {
+		t.Errorf("unexpected redaction: %q", got)
+	}
+}
@@ -0,0 +1,155 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Purger is implemented by any store that holds per-user data (sessions,
+// recordings, memories, caches). PurgeUser deletes everything belonging to
+// userID and returns how many items were removed.
+type Purger interface {
+	PurgeUser(ctx context.Context, userID string) (int, error)
+}
+
+// PrefixPurger is a Purger that can limit a purge to the sessions whose IDs
+// start with prefix, as TenantSessionStore needs to purge only its tenant's.
+type PrefixPurger interface {
+	Purger
+	PurgeUserWithPrefix(ctx context.Context, prefix, userID string) (int, error)
+}
+
+// PurgeReport lists what PurgeUser removed, keyed by store name.
+type PurgeReport struct {
+	UserID  string            `json:"user_id"`
+	Removed map[string]int    `json:"removed"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+// Total is the number of items removed across all stores.
+func (r PurgeReport) Total() int {
+	n := 0
+	for _, c := range r.Removed {
+		n += c
+	}
+	return n
+}
+
+// RegisterPurger adds a store to be cleared by PurgeUser. The session store and
+// turn recorder are included automatically when they implement Purger, the
+// archive store when it is a PurgeableBlobStore, and the user's live streams,
+// with the speech they cached for RepeatLast, always.
+func (o *Orchestrator) RegisterPurger(name string, p Purger) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.purgers == nil {
+		o.purgers = make(map[string]Purger)
+	}
+	o.purgers[name] = p
+}
+
+func (o *Orchestrator) collectPurgers() map[string]Purger {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	all := make(map[string]Purger, len(o.purgers)+4)
+	all["streams"] = streamPurger{o}
+	if p, ok := o.sessionStore.(Purger); ok {
+		all["sessions"] = p
+	}
+	if p, ok := o.recorder.(Purger); ok {
+		all["recordings"] = p
+	}
+	if store, ok := o.config.Archive.Store.(PurgeableBlobStore); ok {
+		all["archives"] = archivePurger{store: store, prefix: o.config.Archive.Prefix}
+	}
+	for name, p := range o.purgers {
+		all[name] = p
+	}
+	return all
+}
+
+// PurgeUser deletes a user's data from every configured store. All stores are
+// attempted even if some fail; the returned error joins the failures and the
+// report shows what was removed. The user's open ManagedStreams are closed
+// first, without being archived, and the replies they were running are
+// waited for; sessions the caller holds elsewhere are not reachable and
+// should be discarded by the caller.
+func (o *Orchestrator) PurgeUser(ctx context.Context, userID string) (PurgeReport, error) {
+	report := PurgeReport{UserID: userID, Removed: make(map[string]int)}
+	if userID == "" {
+		return report, errors.New("purge requires a user ID")
+	}
+
+	purgers := o.collectPurgers()
+	names := make([]string, 0, len(purgers))
+	for name := range purgers {
+		names = append(names, name)
+	}
+	// Streams are closed first: a turn one finished after a store was
+	// purged would write the user's data back to it.
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == "streams") != (names[j] == "streams") {
+			return names[i] == "streams"
+		}
+		return names[i] < names[j]
+	})
+
+	var errs []error
+	for _, name := range names {
+		n, err := purgers[name].PurgeUser(ctx, userID)
+		report.Removed[name] = n
+		if err != nil {
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[name] = err.Error()
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	o.logger.Info("user data purged", "userID", userID, "removed", report.Total(), "failures", len(errs))
+	return report, errors.Join(errs...)
+}
+
+// userStreams returns the live streams serving userID's sessions.
+func (o *Orchestrator) userStreams(userID string) []*ManagedStream {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	var out []*ManagedStream
+	for _, ms := range o.streams {
+		ms.session.mu.RLock()
+		owned := ms.session.UserID == userID
+		ms.session.mu.RUnlock()
+		if owned {
+			out = append(out, ms)
+		}
+	}
+	return out
+}
+
+// streamPurger closes a user's live streams, waits for the turns they were
+// running to end and drops the reply audio kept for RepeatLast. They are not
+// archived and deliver no wrap-up, which would store the user's data again.
+type streamPurger struct{ o *Orchestrator }
+
+func (p streamPurger) PurgeUser(ctx context.Context, userID string) (int, error) {
+	streams := p.o.userStreams(userID)
+	for _, ms := range streams {
+		ms.mu.Lock()
+		ms.purged = true
+		ms.mu.Unlock()
+		ms.Close()
+	}
+	for _, ms := range streams {
+		if err := ms.waitReplies(ctx); err != nil {
+			return len(streams), err
+		}
+		if err := ms.session.turns.idle(ctx); err != nil {
+			return len(streams), err
+		}
+		ms.session.forgetSpeech()
+	}
+	return len(streams), nil
+}
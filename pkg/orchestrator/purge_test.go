@@ -0,0 +1,160 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type failingPurger struct{}
+
+func (failingPurger) PurgeUser(ctx context.Context, userID string) (int, error) {
+	return 0, ErrTestError
+}
+
+func TestPurgeUser(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySessionStore()
+	recorder, err := NewFileTurnRecorder(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orch := New(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: "hi"}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), nil)
+	orch.SetSessionStore(store)
+	orch.SetTurnRecorder(recorder)
+
+	for _, id := range []string{"alice", "bob"} {
+		if _, _, err := orch.ProcessAudioStateless(ctx, id, []byte{1}, false, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := orch.PurgeUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Removed["sessions"] != 1 || report.Removed["recordings"] != 1 || report.Total() != 2 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if _, err := store.Load(ctx, "alice"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("alice's session should be gone, got %v", err)
+	}
+	if _, err := store.Load(ctx, "bob"); err != nil {
+		t.Errorf("bob's session must survive, got %v", err)
+	}
+}
+
+func TestPurgeUser_ReportsFailures(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig(), nil)
+	orch.SetSessionStore(NewInMemorySessionStore())
+	orch.RegisterPurger("memories", failingPurger{})
+
+	report, err := orch.PurgeUser(context.Background(), "carol")
+	if !errors.Is(err, ErrTestError) {
+		t.Fatalf("expected joined store error, got %v", err)
+	}
+	if report.Errors["memories"] == "" {
+		t.Errorf("expected the failing store to be reported, got %+v", report)
+	}
+	if _, ok := report.Removed["sessions"]; !ok {
+		t.Error("other stores must still be purged when one fails")
+	}
+}
+
+func TestPurgeUser_ClosesLiveStreams(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig(), nil)
+	alice := NewConversationSession("alice")
+	alice.LastAssistant = "hi"
+	alice.cacheSpeech("hi", []byte{1, 2}, 1)
+	bob := NewConversationSession("bob")
+	bob.LastAssistant = "hi"
+	bob.cacheSpeech("hi", []byte{1, 2}, 1)
+	aliceStream := orch.NewManagedStream(context.Background(), alice)
+	defer aliceStream.Close()
+	bobStream := orch.NewManagedStream(context.Background(), bob)
+	defer bobStream.Close()
+
+	report, err := orch.PurgeUser(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Removed["streams"] != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if _, ok := orch.StreamFor(alice.ID); ok {
+		t.Error("alice's stream should be closed")
+	}
+	if _, _, ok := alice.cachedSpeech(); ok {
+		t.Error("alice's cached speech should be dropped")
+	}
+	if _, ok := orch.StreamFor(bob.ID); !ok {
+		t.Error("bob's stream must survive")
+	}
+	if _, _, ok := bob.cachedSpeech(); !ok {
+		t.Error("bob's cached speech must survive")
+	}
+}
+
+// lingeringLLM answers only once released, whether or not its call was
+// cancelled, like a provider that doesn't watch the context.
+type lingeringLLM struct {
+	started  chan struct{}
+	release  chan struct{}
+	answered atomic.Bool
+}
+
+func (l *lingeringLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	close(l.started)
+	<-l.release
+	l.answered.Store(true)
+	return "Sure.", nil
+}
+
+func (l *lingeringLLM) Name() string { return "lingering" }
+
+// checkingPurger reports whether check held when it was run.
+type checkingPurger struct {
+	check func() bool
+	held  bool
+}
+
+func (p *checkingPurger) PurgeUser(ctx context.Context, userID string) (int, error) {
+	p.held = p.check()
+	return 0, nil
+}
+
+func TestPurgeUser_WaitsForStreamRepliesBeforeStores(t *testing.T) {
+	llm := &lingeringLLM{started: make(chan struct{}), release: make(chan struct{})}
+	ms := newTestStream(t, testProviders{llm: llm}, nil)
+	ms.session.UserID = "alice"
+	store := &checkingPurger{check: llm.answered.Load}
+	ms.orch.RegisterPurger("archives", store)
+
+	go ms.orch.Notify(context.Background(), ms.session, "The timer has ended.")
+	<-llm.started
+	purged := make(chan error, 1)
+	go func() {
+		_, err := ms.orch.PurgeUser(context.Background(), "alice")
+		purged <- err
+	}()
+	select {
+	case <-purged:
+		t.Fatal("PurgeUser returned while the stream's reply was still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(llm.release)
+	select {
+	case err := <-purged:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PurgeUser never returned")
+	}
+	if !store.held {
+		t.Error("stores were purged before the stream's reply ended")
+	}
+}
@@ -0,0 +1,144 @@
+package orchestrator
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// TurnMode picks what marks the start and end of the user's turns on a
+// ManagedStream.
+type TurnMode string
+
+const (
+	// TurnModeVAD finds turns in the audio with the VAD. It is the default.
+	TurnModeVAD TurnMode = "vad"
+	// TurnModePushToTalk takes turns from the client: StartTalking when the
+	// user presses the talk button and StopTalking on release. The VAD is
+	// not consulted, so a hold-to-talk UI pays neither its latency nor its
+	// mistakes.
+	TurnModePushToTalk TurnMode = "push_to_talk"
+)
+
+type pushToTalkState struct {
+	enabled  bool
+	talking  bool
+	stopping bool         // Released, with audio sent before the release still queued
+	stopAt   int64        // Chunks to have processed before the turn ends
+	done     int64        // Chunks from Write processed so far
+	queued   atomic.Int64 // Chunks passed to Write so far
+}
+
+// SetTurnMode switches the stream between VAD and push-to-talk turns. Set
+// it before writing audio; a turn under way when the mode changes is left
+// to the old mode's rules.
+func (ms *ManagedStream) SetTurnMode(mode TurnMode) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.ptt.enabled = mode == TurnModePushToTalk
+	ms.ptt.talking = false
+	ms.ptt.stopping = false
+}
+
+// TurnMode returns how the stream finds the user's turns.
+func (ms *ManagedStream) TurnMode() TurnMode {
+	if ms.isPushToTalk() {
+		return TurnModePushToTalk
+	}
+	return TurnModeVAD
+}
+
+func (ms *ManagedStream) isPushToTalk() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.ptt.enabled
+}
+
+// StartTalking starts a user turn in push-to-talk mode, cutting in on the
+// bot as Config.InterruptionPolicy says. Audio written from now on belongs
+// to the turn, along with any Config.PreRoll before it.
+func (ms *ManagedStream) StartTalking() error {
+	ms.mu.Lock()
+	if !ms.ptt.enabled {
+		ms.mu.Unlock()
+		return ErrNotPushToTalk
+	}
+	if ms.ptt.talking {
+		ms.mu.Unlock()
+		return nil
+	}
+	ms.ptt.talking = true
+	resumed := ms.ptt.stopping
+	ms.ptt.stopping = false
+	ms.speechFrom = ms.tl.inputBytes
+	if pre, _ := ms.paddingBytesLocked(); pre <= 0 && !resumed {
+		ms.audioBuf.Reset()
+	}
+	ms.mu.Unlock()
+
+	if resumed {
+		// Pressed again before the last release was processed: one turn.
+		return nil
+	}
+	ms.updateActivity()
+	ms.bargeIn()
+	return nil
+}
+
+// StopTalking ends the user turn in push-to-talk mode and has it answered,
+// once the audio written before the call has been processed.
+func (ms *ManagedStream) StopTalking() error {
+	ms.mu.Lock()
+	if !ms.ptt.enabled {
+		ms.mu.Unlock()
+		return ErrNotPushToTalk
+	}
+	if !ms.ptt.talking {
+		ms.mu.Unlock()
+		return nil
+	}
+	ms.ptt.talking = false
+	ms.ptt.stopAt = ms.ptt.queued.Load()
+	if ms.ptt.done < ms.ptt.stopAt {
+		ms.ptt.stopping = true
+		ms.mu.Unlock()
+		return nil
+	}
+	ms.mu.Unlock()
+	ms.finishTalking()
+	return nil
+}
+
+// writePushToTalk handles mic audio in push-to-talk mode, where only audio
+// between the client's start and stop makes up a turn.
+func (ms *ManagedStream) writePushToTalk(chunk []byte) error {
+	ms.mu.Lock()
+	inTurn := ms.ptt.talking || ms.ptt.stopping
+	ms.mu.Unlock()
+	if inTurn {
+		ms.updateActivity()
+	}
+	return ms.bufferInput(chunk, inTurn)
+}
+
+// wrote counts a chunk from Write as processed, ending a released turn once
+// its last audio is in.
+func (ms *ManagedStream) wrote() {
+	ms.mu.Lock()
+	ms.ptt.done++
+	finish := ms.ptt.stopping && ms.ptt.done >= ms.ptt.stopAt
+	if finish {
+		ms.ptt.stopping = false
+	}
+	ms.mu.Unlock()
+	if finish {
+		ms.finishTalking()
+	}
+}
+
+func (ms *ManagedStream) finishTalking() {
+	ms.mu.Lock()
+	ms.userSpeechEndTime = time.Now()
+	ms.tl.speechEnd = ms.tl.inputBytes
+	ms.mu.Unlock()
+	ms.commitUtterance()
+}
@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManagedStream_PushToTalk(t *testing.T) {
+	// The VAD would end the utterance after one chunk; it must go unheard.
+	ms, stt := newPaddedStream(t, 0, 0, []VADEventType{VADSpeechStart, VADSpeechEnd, VADSpeechStart, VADSpeechEnd})
+	ms.SetTurnMode(TurnModePushToTalk)
+	if ms.TurnMode() != TurnModePushToTalk {
+		t.Fatalf("turn mode is %q", ms.TurnMode())
+	}
+
+	ms.Write(bytes.Repeat([]byte{9}, 1600))
+	time.Sleep(20 * time.Millisecond)
+	if err := ms.StartTalking(); err != nil {
+		t.Fatal(err)
+	}
+	for i := byte(1); i <= 3; i++ {
+		ms.Write(bytes.Repeat([]byte{i}, 1600))
+	}
+	// Released right away: the queued audio still belongs to the turn.
+	if err := ms.StopTalking(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case audio := <-stt.audio:
+		if got := chunkIndexes(audio); !bytes.Equal(got, []byte{1, 2, 3}) {
+			t.Errorf("STT got chunks %v, want those written while talking", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the turn was not transcribed")
+	}
+	counts := countEvents(ms, 50*time.Millisecond)
+	if counts[UserSpeaking] != 1 || counts[UserStopped] != 1 {
+		t.Errorf("expected one turn, got %v", counts)
+	}
+}
+
+func TestManagedStream_PushToTalkOnlyInThatMode(t *testing.T) {
+	ms, _ := newPaddedStream(t, 0, 0, nil)
+	if err := ms.StartTalking(); !errors.Is(err, ErrNotPushToTalk) {
+		t.Errorf("StartTalking in VAD mode: got %v", err)
+	}
+	if err := ms.StopTalking(); !errors.Is(err, ErrNotPushToTalk) {
+		t.Errorf("StopTalking in VAD mode: got %v", err)
+	}
+	if ms.TurnMode() != TurnModeVAD {
+		t.Errorf("default turn mode is %q", ms.TurnMode())
+	}
+}
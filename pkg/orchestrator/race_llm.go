@@ -0,0 +1,163 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RaceLLM sends each prompt to several LLMs at once and answers with the
+// first acceptable reply, cancelling the others. It pays for the extra
+// calls to get the latency of whichever provider is fastest at the moment,
+// which suits sessions worth that cost; route them to it with
+// SetPriorityLLM.
+type RaceLLM struct {
+	providers []LLMProvider
+	// Accept decides whether a whole reply may win. A rejected reply is kept
+	// in case no other is accepted. nil accepts any reply with text in it.
+	Accept func(reply string) bool
+}
+
+// NewRaceLLM returns a RaceLLM over providers, normally two.
+func NewRaceLLM(providers ...LLMProvider) *RaceLLM {
+	return &RaceLLM{providers: providers}
+}
+
+func (r *RaceLLM) Name() string {
+	names := make([]string, len(r.providers))
+	for i, p := range r.providers {
+		names[i] = p.Name()
+	}
+	return "race(" + strings.Join(names, ",") + ")"
+}
+
+func (r *RaceLLM) accepts(reply string) bool {
+	if r.Accept != nil {
+		return r.Accept(reply)
+	}
+	return strings.TrimSpace(reply) != ""
+}
+
+type raceResult struct {
+	i     int
+	reply string
+	err   error
+}
+
+// Complete returns the first reply Accept takes. If none is accepted, the
+// first to arrive without an error is returned; if every call fails, so
+// does Complete.
+func (r *RaceLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan raceResult, len(r.providers))
+	for i, p := range r.providers {
+		go func() {
+			reply, err := p.Complete(ctx, messages, tools)
+			results <- raceResult{i, reply, err}
+		}()
+	}
+
+	var fallback *raceResult
+	var errs []error
+	for range r.providers {
+		res := <-results
+		switch {
+		case res.err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", r.providers[res.i].Name(), res.err))
+		case r.accepts(res.reply):
+			return res.reply, nil
+		case fallback == nil:
+			fallback = &res
+		}
+	}
+	if fallback != nil {
+		return fallback.reply, nil
+	}
+	return "", errors.Join(errs...)
+}
+
+// errRaceLost stops a candidate once another has won.
+var errRaceLost = errors.New("another LLM answered first")
+
+// StreamComplete streams the reply of the first provider to produce any
+// output, text or a tool call, and cancels the rest. A reply already being
+// spoken cannot be swapped for another, so Accept is not consulted; a
+// provider that fails before its first output drops out of the race.
+// Providers that do not stream deliver their reply as one chunk.
+func (r *RaceLLM) StreamComplete(ctx context.Context, messages []Message, tools []Tool, onChunk func(string) error, onToolCall func(ToolCallEventData) error) (string, error) {
+	var mu sync.Mutex
+	winner := -1
+	cancels := make([]context.CancelFunc, len(r.providers))
+	// claim makes candidate i the winner if there is none yet, and reports
+	// whether it is.
+	claim := func(i int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if winner < 0 {
+			winner = i
+			for j, cancel := range cancels {
+				if j != i {
+					cancel()
+				}
+			}
+		}
+		return winner == i
+	}
+
+	ctxs := make([]context.Context, len(r.providers))
+	for i := range r.providers {
+		ctxs[i], cancels[i] = context.WithCancel(ctx)
+	}
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	results := make(chan raceResult, len(r.providers))
+	for i, p := range r.providers {
+		chunk := func(s string) error {
+			if !claim(i) {
+				return errRaceLost
+			}
+			return onChunk(s)
+		}
+		call := func(tc ToolCallEventData) error {
+			if !claim(i) {
+				return errRaceLost
+			}
+			return onToolCall(tc)
+		}
+		go func() {
+			var reply string
+			var err error
+			if sp, ok := p.(StreamingLLMProvider); ok {
+				reply, err = sp.StreamComplete(ctxs[i], messages, tools, chunk, call)
+			} else if reply, err = p.Complete(ctxs[i], messages, tools); err == nil && reply != "" {
+				err = chunk(reply)
+			}
+			results <- raceResult{i, reply, err}
+		}()
+	}
+
+	var errs []error
+	for range r.providers {
+		res := <-results
+		mu.Lock()
+		won := winner == res.i
+		mu.Unlock()
+		if won {
+			return res.reply, res.err
+		}
+		if res.err != nil && !errors.Is(res.err, errRaceLost) {
+			errs = append(errs, fmt.Errorf("%s: %w", r.providers[res.i].Name(), res.err))
+		}
+	}
+	if len(errs) == 0 {
+		return "", nil
+	}
+	return "", errors.Join(errs...)
+}
@@ -0,0 +1,143 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// racer answers after delay, streaming its reply in two chunks, and notes
+// whether it was cancelled.
+type racer struct {
+	name      string
+	reply     string
+	delay     time.Duration
+	err       error
+	cancelled atomic.Bool
+}
+
+func (r *racer) wait(ctx context.Context) error {
+	select {
+	case <-time.After(r.delay):
+		return r.err
+	case <-ctx.Done():
+		r.cancelled.Store(true)
+		return ctx.Err()
+	}
+}
+
+func (r *racer) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	if err := r.wait(ctx); err != nil {
+		return "", err
+	}
+	return r.reply, nil
+}
+
+func (r *racer) StreamComplete(ctx context.Context, messages []Message, tools []Tool, onChunk func(string) error, onToolCall func(ToolCallEventData) error) (string, error) {
+	if err := r.wait(ctx); err != nil {
+		return "", err
+	}
+	half := len(r.reply) / 2
+	for _, chunk := range []string{r.reply[:half], r.reply[half:]} {
+		if err := onChunk(chunk); err != nil {
+			return "", err
+		}
+	}
+	return r.reply, nil
+}
+
+func (r *racer) Name() string { return r.name }
+
+// wasCancelled waits briefly for r to see its cancellation, which happens
+// after the race has returned.
+func (r *racer) wasCancelled() bool {
+	for range 50 {
+		if r.cancelled.Load() {
+			return true
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	return false
+}
+
+func TestRaceLLM_Complete(t *testing.T) {
+	fast := &racer{name: "fast", reply: "We open at nine.", delay: 10 * time.Millisecond}
+	slow := &racer{name: "slow", reply: "Nine o'clock.", delay: time.Second}
+	race := NewRaceLLM(slow, fast)
+	if race.Name() != "race(slow,fast)" {
+		t.Errorf("Name() = %q", race.Name())
+	}
+
+	reply, err := race.Complete(context.Background(), nil, nil)
+	if err != nil || reply != "We open at nine." {
+		t.Fatalf("got %q, %v; want the fast reply", reply, err)
+	}
+	if !slow.wasCancelled() {
+		t.Error("the slower call should be cancelled once a reply is accepted")
+	}
+
+	// A rejected reply loses to a slower acceptable one, but is used when
+	// nothing else is accepted.
+	slow.delay = 30 * time.Millisecond
+	race.Accept = func(reply string) bool { return reply == "Nine o'clock." }
+	if reply, _ := race.Complete(context.Background(), nil, nil); reply != "Nine o'clock." {
+		t.Errorf("got %q, want the accepted reply", reply)
+	}
+	race.Accept = func(string) bool { return false }
+	if reply, _ := race.Complete(context.Background(), nil, nil); reply != "We open at nine." {
+		t.Errorf("got %q, want the first reply when none is accepted", reply)
+	}
+
+	fast.err, slow.err = errors.New("overloaded"), errors.New("timeout")
+	if _, err := race.Complete(context.Background(), nil, nil); !errors.Is(err, fast.err) || !errors.Is(err, slow.err) {
+		t.Errorf("err = %v, want both failures", err)
+	}
+}
+
+func TestRaceLLM_StreamComplete(t *testing.T) {
+	fast := &racer{name: "fast", reply: "We open at nine.", delay: 10 * time.Millisecond}
+	slow := &racer{name: "slow", reply: "Nine o'clock.", delay: 200 * time.Millisecond}
+	race := NewRaceLLM(slow, fast)
+
+	var streamed string
+	onChunk := func(s string) error {
+		streamed += s
+		return nil
+	}
+	reply, err := race.StreamComplete(context.Background(), nil, nil, onChunk, nil)
+	if err != nil || reply != "We open at nine." || streamed != reply {
+		t.Fatalf("got %q (streamed %q), %v; want the fast reply only", reply, streamed, err)
+	}
+	if !slow.wasCancelled() {
+		t.Error("the slower call should be cancelled once the first one streams")
+	}
+
+	// A provider failing before any output drops out.
+	fast.err = errors.New("overloaded")
+	streamed = ""
+	reply, err = race.StreamComplete(context.Background(), nil, nil, onChunk, nil)
+	if err != nil || reply != "Nine o'clock." || streamed != reply {
+		t.Errorf("got %q (streamed %q), %v; want the slow reply", reply, streamed, err)
+	}
+}
+
+func TestSetPriorityLLM(t *testing.T) {
+	race := NewRaceLLM(&racer{name: "a", reply: "premium"}, &racer{name: "b", reply: "premium", delay: time.Second})
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: "standard"}, &MockTTSProvider{}, nil, DefaultConfig())
+	o.SetPriorityLLM(PriorityPremium, race)
+	session := o.NewSessionWithDefaults("u1")
+	session.AddMessage("user", "When do you open?")
+
+	if reply, _ := o.GenerateResponse(WithPriority(context.Background(), PriorityPremium), session); reply != "premium" {
+		t.Errorf("premium session got %q", reply)
+	}
+	if reply, _ := o.GenerateResponse(context.Background(), session); reply != "standard" {
+		t.Errorf("standard session got %q", reply)
+	}
+	o.SetPriorityLLM(PriorityPremium, nil)
+	if reply, _ := o.GenerateResponse(WithPriority(context.Background(), PriorityPremium), session); reply != "standard" {
+		t.Errorf("after removing the route got %q", reply)
+	}
+}
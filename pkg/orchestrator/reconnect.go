@@ -0,0 +1,282 @@
+package orchestrator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// ReconnectConfig sets how long a ReconnectRegistry keeps a stream whose
+// client went away, and how much of its output it holds for the client.
+type ReconnectConfig struct {
+	Window      time.Duration // A stream left detached this long is closed; defaults to 30s
+	MaxBuffered int           // Unacknowledged events kept for a returning client; defaults to 4096
+}
+
+// SequencedEvent is a stream event numbered for acknowledgment. Sequence
+// numbers start at 1 and have no gaps.
+type SequencedEvent struct {
+	Seq uint64 `json:"seq"`
+	OrchestratorEvent
+}
+
+// ReconnectRegistry keeps ManagedStreams alive across network blips. Each
+// stream it opens gets a resume token for the client; a client that drops
+// and reconnects within the Window presents the token and the last event it
+// acknowledged, and carries on with the same session and turn, receiving
+// again everything after that event, reply audio included.
+type ReconnectRegistry struct {
+	cfg     ReconnectConfig
+	mu      sync.Mutex
+	streams map[string]*ReconnectableStream
+}
+
+func NewReconnectRegistry(cfg ReconnectConfig) *ReconnectRegistry {
+	if cfg.Window <= 0 {
+		cfg.Window = 30 * time.Second
+	}
+	if cfg.MaxBuffered <= 0 {
+		cfg.MaxBuffered = 4096
+	}
+	return &ReconnectRegistry{cfg: cfg, streams: make(map[string]*ReconnectableStream)}
+}
+
+// Open takes over ms's events and registers it under a new resume token.
+// The stream starts detached: Attach it to deliver events to the client.
+func (r *ReconnectRegistry) Open(ms *ManagedStream) (*ReconnectableStream, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	rs := &ReconnectableStream{ms: ms, reg: r, token: hex.EncodeToString(b)}
+	r.mu.Lock()
+	r.streams[rs.token] = rs
+	r.mu.Unlock()
+	rs.mu.Lock()
+	rs.detachLocked()
+	rs.mu.Unlock()
+	go rs.pump()
+	return rs, nil
+}
+
+// Reconnect attaches a new connection to the stream registered under token,
+// resending the events after lastAcked. It fails with ErrUnknownResumeToken
+// once the stream has expired or closed.
+func (r *ReconnectRegistry) Reconnect(token string, lastAcked uint64) (*ReconnectableStream, <-chan SequencedEvent, error) {
+	r.mu.Lock()
+	rs := r.streams[token]
+	r.mu.Unlock()
+	if rs == nil {
+		return nil, nil, ErrUnknownResumeToken
+	}
+	events, err := rs.Attach(lastAcked)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rs, events, nil
+}
+
+func (r *ReconnectRegistry) remove(rs *ReconnectableStream) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.streams[rs.token] == rs {
+		delete(r.streams, rs.token)
+	}
+}
+
+// ReconnectableStream is a ManagedStream opened through a ReconnectRegistry.
+// Its events are numbered and kept until the client acknowledges them, so
+// none are lost while no connection is attached.
+type ReconnectableStream struct {
+	ms    *ManagedStream
+	reg   *ReconnectRegistry
+	token string
+
+	mu       sync.Mutex
+	pending  []SequencedEvent // Events not yet acknowledged, oldest first
+	seq      uint64           // Last sequence number assigned
+	evicted  uint64           // Last sequence number dropped unacknowledged
+	finished bool             // The stream's events have ended
+	conn     *streamConn
+	expiry   *time.Timer
+	closed   bool
+}
+
+type streamConn struct {
+	out  chan SequencedEvent
+	wake chan struct{} // Signalled when events arrive or the stream ends
+	done chan struct{}
+}
+
+// Token returns the resume token the client reconnects with.
+func (rs *ReconnectableStream) Token() string {
+	return rs.token
+}
+
+// Stream returns the underlying ManagedStream.
+func (rs *ReconnectableStream) Stream() *ManagedStream {
+	return rs.ms
+}
+
+// Write passes microphone audio to the stream.
+func (rs *ReconnectableStream) Write(chunk []byte) error {
+	return rs.ms.Write(chunk)
+}
+
+// Attach connects a client, replacing any connection still attached, and
+// returns the events after lastAcked followed by new ones as they come. The
+// channel closes when it is passed to Detach, when another connection
+// attaches, or after the stream's last event. If events after lastAcked were already dropped to
+// stay within MaxBuffered, Attach fails with ErrResumeGap.
+func (rs *ReconnectableStream) Attach(lastAcked uint64) (<-chan SequencedEvent, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.closed {
+		return nil, ErrUnknownResumeToken
+	}
+	if lastAcked < rs.evicted {
+		return nil, ErrResumeGap
+	}
+	rs.ackLocked(lastAcked)
+	rs.endConnLocked()
+	if rs.expiry != nil {
+		rs.expiry.Stop()
+		rs.expiry = nil
+	}
+	c := &streamConn{out: make(chan SequencedEvent), wake: make(chan struct{}, 1), done: make(chan struct{})}
+	rs.conn = c
+	go rs.send(c, lastAcked+1)
+	return c.out, nil
+}
+
+// Ack tells the stream the client has everything up to seq, which need not
+// be kept for a reconnect any longer.
+func (rs *ReconnectableStream) Ack(seq uint64) {
+	rs.mu.Lock()
+	rs.ackLocked(seq)
+	rs.mu.Unlock()
+}
+
+func (rs *ReconnectableStream) ackLocked(seq uint64) {
+	n := 0
+	for n < len(rs.pending) && rs.pending[n].Seq <= seq {
+		n++
+	}
+	rs.pending = rs.pending[n:]
+}
+
+// Detach notes the client's connection that received events has gone.
+// Unless a client attaches again within the Window, the stream is closed. A
+// connection already replaced by a reconnect is ignored, so the old socket's
+// handler noticing it is dead late can't cut off the new one.
+func (rs *ReconnectableStream) Detach(events <-chan SequencedEvent) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if !rs.closed && rs.conn != nil && (<-chan SequencedEvent)(rs.conn.out) == events {
+		rs.endConnLocked()
+		rs.detachLocked()
+	}
+}
+
+func (rs *ReconnectableStream) detachLocked() {
+	if rs.expiry == nil {
+		rs.expiry = time.AfterFunc(rs.reg.cfg.Window, rs.Close)
+	}
+}
+
+func (rs *ReconnectableStream) endConnLocked() {
+	if rs.conn != nil {
+		close(rs.conn.done)
+		rs.conn = nil
+	}
+}
+
+// Close closes the stream and forgets its token.
+func (rs *ReconnectableStream) Close() {
+	rs.mu.Lock()
+	if rs.closed {
+		rs.mu.Unlock()
+		return
+	}
+	rs.closed = true
+	rs.endConnLocked()
+	if rs.expiry != nil {
+		rs.expiry.Stop()
+	}
+	rs.mu.Unlock()
+	rs.reg.remove(rs)
+	rs.ms.Close()
+}
+
+// pump numbers the stream's events and buffers them, so the stream never
+// has to drop audio for a client that is away.
+func (rs *ReconnectableStream) pump() {
+	for ev := range rs.ms.Events() {
+		rs.mu.Lock()
+		rs.seq++
+		rs.pending = append(rs.pending, SequencedEvent{Seq: rs.seq, OrchestratorEvent: ev})
+		if over := len(rs.pending) - rs.reg.cfg.MaxBuffered; over > 0 {
+			rs.evicted = rs.pending[over-1].Seq
+			rs.pending = rs.pending[over:]
+		}
+		rs.notifyLocked()
+		rs.mu.Unlock()
+	}
+	rs.mu.Lock()
+	rs.finished = true
+	rs.notifyLocked()
+	rs.mu.Unlock()
+}
+
+func (rs *ReconnectableStream) notifyLocked() {
+	if rs.conn == nil {
+		return
+	}
+	select {
+	case rs.conn.wake <- struct{}{}:
+	default:
+	}
+}
+
+// send delivers events from next on to one connection until it ends.
+func (rs *ReconnectableStream) send(c *streamConn, next uint64) {
+	defer close(c.out)
+	for {
+		rs.mu.Lock()
+		if next <= rs.evicted {
+			// The client fell further behind than MaxBuffered while
+			// attached; end the connection, so reconnecting reports the gap.
+			rs.mu.Unlock()
+			return
+		}
+		var ev SequencedEvent
+		found := false
+		for _, p := range rs.pending {
+			if p.Seq >= next {
+				ev, found = p, true
+				break
+			}
+		}
+		finished := rs.finished && next > rs.seq
+		rs.mu.Unlock()
+
+		switch {
+		case found:
+			select {
+			case c.out <- ev:
+				next = ev.Seq + 1
+			case <-c.done:
+				return
+			}
+		case finished:
+			return
+		default:
+			select {
+			case <-c.wake:
+			case <-c.done:
+				return
+			}
+		}
+	}
+}
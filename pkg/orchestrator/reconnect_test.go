@@ -0,0 +1,155 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newReconnectStream(t *testing.T, cfg ReconnectConfig) (*ReconnectRegistry, *ReconnectableStream) {
+	t.Helper()
+	c := DefaultConfig()
+	c.FirstSpeaker = FirstSpeakerUser
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, &scriptedVAD{}, c)
+	ms := orch.NewManagedStream(context.Background(), NewConversationSession("u"))
+	reg := NewReconnectRegistry(cfg)
+	rs, err := reg.Open(ms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(rs.Close)
+	return reg, rs
+}
+
+// receive reads n events, failing the test if they take too long.
+func receive(t *testing.T, events <-chan SequencedEvent, n int) []SequencedEvent {
+	t.Helper()
+	var got []SequencedEvent
+	for len(got) < n {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				t.Fatalf("events closed after %d of %d", len(got), n)
+			}
+			got = append(got, ev)
+		case <-time.After(time.Second):
+			t.Fatalf("got %d of %d events", len(got), n)
+		}
+	}
+	return got
+}
+
+func TestReconnectableStream_ResumesAfterLastAck(t *testing.T) {
+	reg, rs := newReconnectStream(t, ReconnectConfig{})
+	ms := rs.Stream()
+	ms.emit(TranscriptFinal, "one")
+
+	events, err := rs.Attach(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := receive(t, events, 1)[0]
+	if first.Seq != 1 || first.Data != "one" {
+		t.Fatalf("first event %+v", first)
+	}
+	rs.Ack(first.Seq)
+
+	// The connection drops; the bot goes on talking meanwhile.
+	rs.Detach(events)
+	if _, ok := <-events; ok {
+		t.Error("a detached connection's events should close")
+	}
+	ms.emit(BotSpeaking, nil)
+	ms.emit(TranscriptFinal, "three")
+
+	rs2, events, err := reg.Reconnect(rs.Token(), first.Seq)
+	if err != nil || rs2 != rs {
+		t.Fatalf("Reconnect: %v", err)
+	}
+	got := receive(t, events, 2)
+	if got[0].Seq != 2 || got[0].Type != BotSpeaking || got[1].Seq != 3 || got[1].Data != "three" {
+		t.Errorf("after reconnecting got %+v", got)
+	}
+
+	// Unacknowledged events are sent again to a connection that replaces
+	// this one.
+	_, events, err = reg.Reconnect(rs.Token(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := receive(t, events, 1)[0]; again.Seq != 3 {
+		t.Errorf("expected event 3 again, got %+v", again)
+	}
+}
+
+func TestReconnectableStream_ExpiresWhenDetached(t *testing.T) {
+	reg, rs := newReconnectStream(t, ReconnectConfig{Window: 30 * time.Millisecond})
+	events, err := rs.Attach(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Detach(events)
+	time.Sleep(100 * time.Millisecond)
+
+	if _, _, err := reg.Reconnect(rs.Token(), 0); !errors.Is(err, ErrUnknownResumeToken) {
+		t.Errorf("got %v, want ErrUnknownResumeToken", err)
+	}
+	if rs.Stream().ctx.Err() == nil {
+		t.Error("an expired stream should be closed")
+	}
+	if _, _, err := reg.Reconnect("nonsense", 0); !errors.Is(err, ErrUnknownResumeToken) {
+		t.Errorf("unknown token: got %v", err)
+	}
+}
+
+func TestReconnectableStream_StaleDetachAfterReconnect(t *testing.T) {
+	reg, rs := newReconnectStream(t, ReconnectConfig{Window: 30 * time.Millisecond})
+	old, err := rs.Attach(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, events, err := reg.Reconnect(rs.Token(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The old socket's handler only now notices it is dead.
+	rs.Detach(old)
+	time.Sleep(100 * time.Millisecond)
+
+	rs.Stream().emit(TranscriptFinal, "still here")
+	if got := receive(t, events, 1)[0]; got.Data != "still here" {
+		t.Errorf("got %+v", got)
+	}
+	if rs.Stream().ctx.Err() != nil {
+		t.Error("a stale Detach must not expire the reconnected stream")
+	}
+}
+
+func TestReconnectableStream_ResumeGap(t *testing.T) {
+	reg, rs := newReconnectStream(t, ReconnectConfig{MaxBuffered: 2})
+	for _, s := range []string{"a", "b", "c", "d"} {
+		rs.Stream().emit(TranscriptFinal, s)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		rs.mu.Lock()
+		seq := rs.seq
+		rs.mu.Unlock()
+		if seq == 4 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, _, err := reg.Reconnect(rs.Token(), 1); !errors.Is(err, ErrResumeGap) {
+		t.Errorf("got %v, want ErrResumeGap", err)
+	}
+	_, events, err := reg.Reconnect(rs.Token(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, events, 2); got[0].Data != "c" || got[1].Data != "d" {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,166 @@
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisCache is a Cache on a Redis server, for sharing cached audio and
+// responses between orchestrator instances. It speaks the Redis protocol
+// directly over one connection, reconnecting after errors, so it needs no
+// client library; wrap a client of your own in a Cache for pooling,
+// clustering or TLS.
+type RedisCache struct {
+	Addr     string // host:port
+	Password string // Sent with AUTH when set
+	DB       int    // Selected with SELECT when non-zero
+	Timeout  time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// NewRedisCache returns a RedisCache for the server at addr.
+func NewRedisCache(addr string) *RedisCache {
+	return &RedisCache{Addr: addr}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := c.do(ctx, "GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	return reply, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := c.do(ctx, args...)
+	return err
+}
+
+// Close closes the connection to the server; the next call reopens it.
+func (c *RedisCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dropLocked()
+}
+
+func (c *RedisCache) dropLocked() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.rw = nil, nil
+	return err
+}
+
+// do sends one command and returns its bulk or simple string reply, or nil
+// for a null reply.
+func (c *RedisCache) do(ctx context.Context, args ...string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		if err := c.dialLocked(ctx); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := c.roundTripLocked(ctx, args)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		c.dropLocked()
+	}
+	return reply, err
+}
+
+func (c *RedisCache) dialLocked(ctx context.Context) error {
+	d := net.Dialer{Timeout: c.timeout()}
+	conn, err := d.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return fmt.Errorf("redis: %w", err)
+	}
+	c.conn, c.rw = conn, bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	if c.Password != "" {
+		if _, err := c.roundTripLocked(ctx, []string{"AUTH", c.Password}); err != nil {
+			c.dropLocked()
+			return err
+		}
+	}
+	if c.DB != 0 {
+		if _, err := c.roundTripLocked(ctx, []string{"SELECT", strconv.Itoa(c.DB)}); err != nil {
+			c.dropLocked()
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *RedisCache) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return 2 * time.Second
+}
+
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func (c *RedisCache) roundTripLocked(ctx context.Context, args []string) ([]byte, error) {
+	deadline := time.Now().Add(c.timeout())
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+
+	fmt.Fprintf(c.rw, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(c.rw, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if err := c.rw.Flush(); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	line, err := c.rw.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	if len(line) < 3 {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	body := line[1 : len(line)-2]
+	switch line[0] {
+	case '+', ':':
+		return []byte(body), nil
+	case '-':
+		return nil, redisError(body)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed reply %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.rw, buf); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		return buf[:n], nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -0,0 +1,290 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProviderOptions configure a provider built by name. Factories read what
+// they need and ignore the rest; settings without a field of their own go
+// in Options.
+type ProviderOptions struct {
+	APIKey     string            `json:"api_key,omitempty"`
+	Model      string            `json:"model,omitempty"`    // The factory's default when empty
+	Region     string            `json:"region,omitempty"`   // For cloud providers, e.g. westeurope or eu-west-1
+	BaseURL    string            `json:"base_url,omitempty"` // Replaces the provider's endpoint, e.g. for a proxy or emulator
+	SampleRate int               `json:"-"`                  // Of the pipeline's audio; Build sets it from the pipeline
+	Options    map[string]string `json:"options,omitempty"`
+}
+
+// Option returns Options[key], or def when it is unset.
+func (o ProviderOptions) Option(key, def string) string {
+	if v, ok := o.Options[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// RequireAPIKey fails when no API key is set, naming the provider.
+func (o ProviderOptions) RequireAPIKey(provider string) error {
+	if o.APIKey == "" {
+		return fmt.Errorf("%s: api_key is required", provider)
+	}
+	return nil
+}
+
+// ApplyProviderOptions passes the options every provider may take to p,
+// for the setters it has: SetSampleRate and SetBaseURL.
+func ApplyProviderOptions(p any, o ProviderOptions) {
+	if s, ok := p.(interface{ SetSampleRate(int) }); ok && o.SampleRate > 0 {
+		s.SetSampleRate(o.SampleRate)
+	}
+	if s, ok := p.(interface{ SetBaseURL(string) }); ok && o.BaseURL != "" {
+		s.SetBaseURL(o.BaseURL)
+	}
+}
+
+// Factories build a provider from its options.
+type (
+	STTFactory func(opts ProviderOptions) (STTProvider, error)
+	LLMFactory func(opts ProviderOptions) (LLMProvider, error)
+	TTSFactory func(opts ProviderOptions) (TTSProvider, error)
+	VADFactory func(opts ProviderOptions) (VADProvider, error)
+)
+
+// Registry holds provider factories by name, so a pipeline can be chosen
+// in a config file. The bundled provider packages register theirs with
+// DefaultRegistry when imported.
+type Registry struct {
+	mu  sync.RWMutex
+	stt map[string]STTFactory
+	llm map[string]LLMFactory
+	tts map[string]TTSFactory
+	vad map[string]VADFactory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		stt: make(map[string]STTFactory),
+		llm: make(map[string]LLMFactory),
+		tts: make(map[string]TTSFactory),
+		vad: make(map[string]VADFactory),
+	}
+}
+
+// DefaultRegistry is the registry RegisterSTT and the others add to. It
+// starts with the package's VADs: "rms" and "improved_rms".
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.RegisterVAD("rms", func(o ProviderOptions) (VADProvider, error) {
+		threshold, silence, err := vadOptions(o)
+		if err != nil {
+			return nil, err
+		}
+		return NewRMSVAD(threshold, silence), nil
+	})
+	r.RegisterVAD("improved_rms", func(o ProviderOptions) (VADProvider, error) {
+		threshold, silence, err := vadOptions(o)
+		if err != nil {
+			return nil, err
+		}
+		return NewImprovedRMSVAD(threshold, silence, o.SampleRate), nil
+	})
+	return r
+}
+
+// vadOptions reads the "threshold" and "silence" options of the RMS VADs.
+func vadOptions(o ProviderOptions) (float64, time.Duration, error) {
+	threshold, err := strconv.ParseFloat(o.Option("threshold", "0.02"), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("vad threshold: %w", err)
+	}
+	silence, err := time.ParseDuration(o.Option("silence", "500ms"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("vad silence: %w", err)
+	}
+	return threshold, silence, nil
+}
+
+// RegisterSTT adds an STT factory, replacing any of the same name.
+func (r *Registry) RegisterSTT(name string, f STTFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stt[name] = f
+}
+
+// RegisterLLM adds an LLM factory, replacing any of the same name.
+func (r *Registry) RegisterLLM(name string, f LLMFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.llm[name] = f
+}
+
+// RegisterTTS adds a TTS factory, replacing any of the same name.
+func (r *Registry) RegisterTTS(name string, f TTSFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tts[name] = f
+}
+
+// RegisterVAD adds a VAD factory, replacing any of the same name.
+func (r *Registry) RegisterVAD(name string, f VADFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.vad[name] = f
+}
+
+func RegisterSTT(name string, f STTFactory) { DefaultRegistry.RegisterSTT(name, f) }
+func RegisterLLM(name string, f LLMFactory) { DefaultRegistry.RegisterLLM(name, f) }
+func RegisterTTS(name string, f TTSFactory) { DefaultRegistry.RegisterTTS(name, f) }
+func RegisterVAD(name string, f VADFactory) { DefaultRegistry.RegisterVAD(name, f) }
+
+// build looks name up in factories and runs it.
+func build[F ~func(ProviderOptions) (P, error), P any](r *Registry, kind string, factories map[string]F, spec ProviderSpec) (P, error) {
+	r.mu.RLock()
+	f, ok := factories[spec.Name]
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	if !ok {
+		var zero P
+		slices.Sort(names)
+		return zero, fmt.Errorf("unknown %s provider %q (registered: %s)", kind, spec.Name, strings.Join(names, ", "))
+	}
+	p, err := f(spec.ProviderOptions)
+	if err != nil {
+		return p, fmt.Errorf("%s provider %s: %w", kind, spec.Name, err)
+	}
+	return p, nil
+}
+
+func (r *Registry) NewSTT(spec ProviderSpec) (STTProvider, error) {
+	return build(r, "STT", r.stt, spec)
+}
+
+func (r *Registry) NewLLM(spec ProviderSpec) (LLMProvider, error) {
+	return build(r, "LLM", r.llm, spec)
+}
+
+func (r *Registry) NewTTS(spec ProviderSpec) (TTSProvider, error) {
+	return build(r, "TTS", r.tts, spec)
+}
+
+func (r *Registry) NewVAD(spec ProviderSpec) (VADProvider, error) {
+	return build(r, "VAD", r.vad, spec)
+}
+
+// ProviderSpec names a registered provider and its options.
+type ProviderSpec struct {
+	Name string `json:"name"`
+	ProviderOptions
+}
+
+// PipelineConfig chooses a pipeline's providers by name, as read from a
+// config file by LoadPipelineConfig.
+type PipelineConfig struct {
+	STT        ProviderSpec `json:"stt"`
+	LLM        ProviderSpec `json:"llm"`
+	TTS        ProviderSpec `json:"tts"`
+	VAD        ProviderSpec `json:"vad"`                   // Optional; no VAD when Name is empty
+	SampleRate int          `json:"sample_rate,omitempty"` // Overrides Config.SampleRate and is passed to every provider
+	Language   Language     `json:"language,omitempty"`    // Overrides Config.Language
+
+	Config *Config `json:"-"` // The rest of the orchestrator's config; DefaultConfig() when nil
+	Logger Logger  `json:"-"`
+}
+
+// LoadPipelineConfig reads a JSON pipeline config. ${VAR} and $VAR in the
+// providers' string settings, their names, keys, models, regions, base URLs
+// and options, are replaced from the environment once the file is decoded,
+// so keys can stay out of the file, and their values are taken as they are:
+//
+//	{
+//	  "sample_rate": 44100,
+//	  "stt": {"name": "deepgram", "api_key": "${DEEPGRAM_API_KEY}"},
+//	  "llm": {"name": "groq", "api_key": "${GROQ_API_KEY}"},
+//	  "tts": {"name": "piper", "options": {"voices_dir": "/opt/piper"}},
+//	  "vad": {"name": "improved_rms", "options": {"threshold": "0.007"}}
+//	}
+func LoadPipelineConfig(path string) (PipelineConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PipelineConfig{}, err
+	}
+	var cfg PipelineConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return PipelineConfig{}, fmt.Errorf("pipeline config %s: %w", path, err)
+	}
+	for _, spec := range []*ProviderSpec{&cfg.STT, &cfg.LLM, &cfg.TTS, &cfg.VAD} {
+		spec.expandEnv()
+	}
+	return cfg, nil
+}
+
+// expandEnv replaces ${VAR} and $VAR in s's string settings from the
+// environment.
+func (s *ProviderSpec) expandEnv() {
+	for _, field := range []*string{&s.Name, &s.APIKey, &s.Model, &s.Region, &s.BaseURL} {
+		*field = os.ExpandEnv(*field)
+	}
+	for k, v := range s.Options {
+		s.Options[k] = os.ExpandEnv(v)
+	}
+}
+
+// Build instantiates the providers cfg names and returns an orchestrator
+// running them.
+func (r *Registry) Build(cfg PipelineConfig) (*Orchestrator, error) {
+	config := DefaultConfig()
+	if cfg.Config != nil {
+		config = *cfg.Config
+	}
+	if cfg.SampleRate > 0 {
+		config.SampleRate = cfg.SampleRate
+	}
+	if cfg.Language != "" {
+		config.Language = cfg.Language
+	}
+	for _, spec := range []*ProviderSpec{&cfg.STT, &cfg.LLM, &cfg.TTS, &cfg.VAD} {
+		spec.SampleRate = config.SampleRate
+	}
+
+	stt, err := r.NewSTT(cfg.STT)
+	if err != nil {
+		return nil, err
+	}
+	llm, err := r.NewLLM(cfg.LLM)
+	if err != nil {
+		return nil, err
+	}
+	tts, err := r.NewTTS(cfg.TTS)
+	if err != nil {
+		return nil, err
+	}
+	var vad VADProvider
+	if cfg.VAD.Name != "" {
+		if vad, err = r.NewVAD(cfg.VAD); err != nil {
+			return nil, err
+		}
+	}
+	return New(stt, llm, tts, vad, config, cfg.Logger), nil
+}
+
+// BuildFromConfig builds cfg's pipeline from DefaultRegistry. Import the
+// provider packages it names, if nothing else does, for their factories
+// to be registered:
+//
+//	import _ "github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/stt"
+func BuildFromConfig(cfg PipelineConfig) (*Orchestrator, error) {
+	return DefaultRegistry.Build(cfg)
+}
@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Build(t *testing.T) {
+	r := NewRegistry()
+	var sttOpts ProviderOptions
+	r.RegisterSTT("fake", func(o ProviderOptions) (STTProvider, error) {
+		sttOpts = o
+		return &MockSTTProvider{}, nil
+	})
+	r.RegisterLLM("fake", func(o ProviderOptions) (LLMProvider, error) {
+		if err := o.RequireAPIKey("fake"); err != nil {
+			return nil, err
+		}
+		return &MockLLMProvider{}, nil
+	})
+	r.RegisterTTS("fake", func(o ProviderOptions) (TTSProvider, error) { return &MockTTSProvider{}, nil })
+
+	// Values are not JSON, so quotes and backslashes in them are kept.
+	t.Setenv("FAKE_KEY", `sk-"te\st`)
+	t.Setenv("PUNCTUATE", "true")
+	path := filepath.Join(t.TempDir(), "pipeline.json")
+	os.WriteFile(path, []byte(`{
+		"sample_rate": 16000,
+		"language": "fr",
+		"stt": {"name": "fake", "model": "large", "options": {"punctuate": "${PUNCTUATE}"}},
+		"llm": {"name": "fake", "api_key": "${FAKE_KEY}"},
+		"tts": {"name": "fake"}
+	}`), 0o644)
+	cfg, err := LoadPipelineConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LLM.APIKey != `sk-"te\st` {
+		t.Errorf("api key = %q, want it from the environment", cfg.LLM.APIKey)
+	}
+
+	o, err := r.Build(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := o.GetConfig(); c.SampleRate != 16000 || c.Language != LanguageFr {
+		t.Errorf("config = %d Hz, %s", c.SampleRate, c.Language)
+	}
+	if sttOpts.Model != "large" || sttOpts.SampleRate != 16000 || sttOpts.Option("punctuate", "false") != "true" {
+		t.Errorf("STT options = %+v", sttOpts)
+	}
+	if o.vad != nil {
+		t.Error("no VAD was asked for")
+	}
+
+	cfg.VAD = ProviderSpec{Name: "improved_rms"}
+	if _, err := r.Build(cfg); err == nil || !strings.Contains(err.Error(), `unknown VAD provider "improved_rms"`) {
+		t.Errorf("a VAD missing from the registry: %v", err)
+	}
+	cfg.VAD = ProviderSpec{}
+	cfg.LLM.APIKey = ""
+	if _, err := r.Build(cfg); err == nil || !strings.Contains(err.Error(), "api_key is required") {
+		t.Errorf("a missing key: %v", err)
+	}
+	cfg.TTS.Name = "nope"
+	cfg.LLM.APIKey = "k"
+	if _, err := r.Build(cfg); err == nil || !strings.Contains(err.Error(), "registered: fake") {
+		t.Errorf("an unknown provider: %v", err)
+	}
+}
+
+func TestDefaultRegistry_VAD(t *testing.T) {
+	vad, err := DefaultRegistry.NewVAD(ProviderSpec{Name: "improved_rms", ProviderOptions: ProviderOptions{
+		SampleRate: 16000,
+		Options:    map[string]string{"threshold": "0.01", "silence": "300ms"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := vad.(*ImprovedRMSVAD); v.threshold != 0.01 || v.silenceLimit.Milliseconds() != 300 || v.sampleRate != 16000 {
+		t.Errorf("vad = %+v", v)
+	}
+	if _, err := DefaultRegistry.NewVAD(ProviderSpec{Name: "rms", ProviderOptions: ProviderOptions{Options: map[string]string{"silence": "soon"}}}); err == nil {
+		t.Error("a bad silence option should fail")
+	}
+}
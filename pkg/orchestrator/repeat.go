@@ -0,0 +1,113 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+)
+
+// maxCachedSpeech caps the audio kept for RepeatLast; about 95 seconds of
+// 44.1kHz 16-bit mono.
+const maxCachedSpeech = 8 << 20
+
+// spokenResponse is the audio of the session's last response, as it was
+// synthesised.
+type spokenResponse struct {
+	text  string
+	voice Voice
+	rate  float64
+	audio []byte
+}
+
+func (s *ConversationSession) cacheSpeech(text string, audio []byte, rate float64) {
+	if text == "" || len(audio) == 0 || len(audio) > maxCachedSpeech {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSpeech = spokenResponse{text: text, voice: s.CurrentVoice, rate: rate, audio: audio}
+}
+
+// forgetSpeech drops the cached audio of the last response and reports
+// whether there was any.
+func (s *ConversationSession) forgetSpeech() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	had := s.lastSpeech.audio != nil
+	s.lastSpeech = spokenResponse{}
+	return had
+}
+
+// cachedSpeech returns the audio of the last response and the rate it was
+// spoken at, if it was synthesised in the session's current voice.
+func (s *ConversationSession) cachedSpeech() ([]byte, float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := s.lastSpeech
+	if c.audio == nil || c.text != s.LastAssistant || c.voice != s.CurrentVoice {
+		return nil, 0, false
+	}
+	return c.audio, c.rate, true
+}
+
+// cachedRepeat returns the cached audio of the last response at rate. With
+// Config.TimeStretch, audio cached at another rate is stretched to fit.
+func (o *Orchestrator) cachedRepeat(session *ConversationSession, rate float64) ([]byte, bool) {
+	cached, cachedRate, ok := session.cachedSpeech()
+	switch {
+	case !ok:
+		return nil, false
+	case cachedRate == rate:
+		return cached, true
+	case o.GetConfig().TimeStretch:
+		return o.timeStretch(cached, rate/cachedRate), true
+	}
+	return nil, false
+}
+
+// RepeatLast returns the audio of the session's last assistant response
+// without calling the LLM or changing the conversation. Audio from the
+// original turn is replayed when it is still cached; otherwise the response
+// is synthesised again. For a slower repeat, pass a context from
+// WithSpeechRate; with Config.TimeStretch the cached audio is slowed down
+// rather than synthesised again.
+func (o *Orchestrator) RepeatLast(ctx context.Context, session *ConversationSession) ([]byte, error) {
+	text := session.lastAssistant()
+	if text == "" {
+		return nil, ErrNothingToRepeat
+	}
+	ctx = withSpeechRate(ctx, session)
+	rate := SpeechRateFromContext(ctx)
+	if audio, ok := o.cachedRepeat(session, rate); ok {
+		return audio, nil
+	}
+	audio, err := o.Synthesize(ctx, text, session.GetCurrentVoice(), session.GetCurrentLanguage())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTTSFailed, err)
+	}
+	session.cacheSpeech(text, audio, rate)
+	return audio, nil
+}
+
+// repeatLast speaks the last response again as a new bot turn, from the
+// cache when possible so the repeat starts without waiting for TTS.
+func (ms *ManagedStream) repeatLast(ctx context.Context) {
+	text := ms.session.lastAssistant()
+	if text == "" {
+		return
+	}
+	ctx = withSpeechRate(ctx, ms.session)
+	ms.mu.Lock()
+	ms.payloadGen++
+	ms.mu.Unlock()
+
+	sentences := make(chan string, 1)
+	sentences <- text
+	close(sentences)
+	if audio, ok := ms.orch.cachedRepeat(ms.session, SpeechRateFromContext(ctx)); ok {
+		ms.speakWith(ctx, sentences, func(ctx context.Context, text string, onChunk func([]byte) error) error {
+			return onChunk(audio)
+		})
+		return
+	}
+	ms.speakSentences(ctx, sentences)
+}
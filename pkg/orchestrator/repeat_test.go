@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRepeatLast_ReplaysCachedAudio(t *testing.T) {
+	tts := &rateTTS{}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: "We open at nine."}, tts, nil, DefaultConfig())
+	session := NewConversationSession("u")
+
+	if _, err := o.RepeatLast(context.Background(), session); !errors.Is(err, ErrNothingToRepeat) {
+		t.Errorf("expected ErrNothingToRepeat, got %v", err)
+	}
+
+	if _, _, err := o.ProcessText(context.Background(), session, "when do you open?"); err != nil {
+		t.Fatal(err)
+	}
+	audio, err := o.RepeatLast(context.Background(), session)
+	if err != nil || string(audio) != "We open at nine." {
+		t.Fatalf("got %q, %v", audio, err)
+	}
+	if len(tts.texts) != 1 {
+		t.Errorf("expected the cached audio to be replayed, got %d TTS calls", len(tts.texts))
+	}
+	if n := len(session.GetContextCopy()); n != 2 {
+		t.Errorf("repeat must not change the conversation, got %d messages", n)
+	}
+
+	if _, err := o.RepeatLast(WithSpeechRate(context.Background(), 0.8), session); err != nil {
+		t.Fatal(err)
+	}
+	if len(tts.texts) != 2 || tts.rates[1] != 0.8 {
+		t.Errorf("a slower repeat must be synthesised again, got rates %v", tts.rates)
+	}
+}
+
+func TestProcessAudio_RepeatSlowerKeepsSessionRate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HotCommands = DefaultHotCommands()
+	tts := &rateTTS{}
+	stt := &MockSTTProvider{transcribeResult: "when do you open"}
+	o := NewWithVAD(stt, &MockLLMProvider{completeResult: "We open at nine."}, tts, nil, cfg)
+	session := NewConversationSession("u")
+	if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stt.transcribeResult = "Repeat that slower, please."
+	_, audio, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil)
+	if err != nil || string(audio) != "We open at nine." {
+		t.Fatalf("got %q, %v", audio, err)
+	}
+	if len(tts.rates) != 2 || tts.rates[1] >= 1 {
+		t.Errorf("expected a slower synthesis, got rates %v", tts.rates)
+	}
+	if session.GetSpeechRate() != 1 {
+		t.Errorf("a one-off slower repeat must not change the session rate, got %v", session.GetSpeechRate())
+	}
+}
@@ -0,0 +1,305 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TurnTimings holds per-stage latencies of one turn.
+type TurnTimings struct {
+	STT   time.Duration `json:"stt"`
+	LLM   time.Duration `json:"llm"`
+	TTS   time.Duration `json:"tts"`
+	Total time.Duration `json:"total"`
+}
+
+// TurnRecording captures everything needed to re-run a turn: the user's
+// audio, the session as it was before the turn, and what came out of it.
+type TurnRecording struct {
+	ID         string            `json:"id"`
+	SessionID  string            `json:"session_id"`
+	UserID     string            `json:"user_id,omitempty"`
+	RecordedAt time.Time         `json:"recorded_at"`
+	Audio      []byte            `json:"audio"`
+	Session    SessionSnapshot   `json:"session"`
+	Providers  map[string]string `json:"providers"`
+	Transcript string            `json:"transcript"`
+	Response   string            `json:"response"`
+	Error      string            `json:"error,omitempty"`
+	Timings    TurnTimings       `json:"timings"`
+	Calls      []ProviderCall    `json:"calls,omitempty"` // Provider requests the turn made, with the vendors' request IDs
+}
+
+// TurnRecorder stores recorded turns for later replay.
+type TurnRecorder interface {
+	SaveTurn(ctx context.Context, rec TurnRecording) error
+	LoadTurn(ctx context.Context, id string) (TurnRecording, error)
+}
+
+// InMemoryTurnRecorder keeps recordings in memory, mostly for tests.
+type InMemoryTurnRecorder struct {
+	mu    sync.Mutex
+	turns map[string]TurnRecording
+}
+
+func NewInMemoryTurnRecorder() *InMemoryTurnRecorder {
+	return &InMemoryTurnRecorder{turns: make(map[string]TurnRecording)}
+}
+
+func (r *InMemoryTurnRecorder) SaveTurn(ctx context.Context, rec TurnRecording) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.turns[rec.ID] = rec
+	return nil
+}
+
+func (r *InMemoryTurnRecorder) LoadTurn(ctx context.Context, id string) (TurnRecording, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.turns[id]
+	if !ok {
+		return TurnRecording{}, ErrRecordingNotFound
+	}
+	return rec, nil
+}
+
+func (r *InMemoryTurnRecorder) PurgeUser(ctx context.Context, userID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for id, rec := range r.turns {
+		if rec.UserID == userID {
+			delete(r.turns, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// FileTurnRecorder writes each turn as <dir>/<id>.json so recordings can be
+// attached to bug reports and replayed on a developer machine.
+type FileTurnRecorder struct {
+	dir string
+}
+
+func NewFileTurnRecorder(dir string) (*FileTurnRecorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording dir: %w", err)
+	}
+	return &FileTurnRecorder{dir: dir}, nil
+}
+
+func (r *FileTurnRecorder) path(id string) string {
+	return filepath.Join(r.dir, filepath.Base(id)+".json")
+}
+
+func (r *FileTurnRecorder) SaveTurn(ctx context.Context, rec TurnRecording) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	return os.WriteFile(r.path(rec.ID), data, 0o644)
+}
+
+func (r *FileTurnRecorder) LoadTurn(ctx context.Context, id string) (TurnRecording, error) {
+	data, err := os.ReadFile(r.path(id))
+	if os.IsNotExist(err) {
+		return TurnRecording{}, ErrRecordingNotFound
+	}
+	if err != nil {
+		return TurnRecording{}, err
+	}
+	var rec TurnRecording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return TurnRecording{}, fmt.Errorf("failed to decode recording %s: %w", id, err)
+	}
+	return rec, nil
+}
+
+func (r *FileTurnRecorder) PurgeUser(ctx context.Context, userID string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(r.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		var rec struct {
+			UserID string `json:"user_id"`
+		}
+		if json.Unmarshal(data, &rec) != nil || rec.UserID != userID {
+			continue
+		}
+		if err := os.Remove(p); err != nil {
+			return n, fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// SetTurnRecorder enables recording of every ProcessAudio turn. Recordings
+// contain raw user audio, so only enable this where that is acceptable.
+func (o *Orchestrator) SetTurnRecorder(r TurnRecorder) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.recorder = r
+}
+
+func (o *Orchestrator) getTurnRecorder() TurnRecorder {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.recorder
+}
+
+func (o *Orchestrator) newTurnRecording(ctx context.Context, session *ConversationSession, audioData []byte) *TurnRecording {
+	return &TurnRecording{
+		ID:         IdempotencyKeyFromContext(ctx),
+		SessionID:  session.ID,
+		UserID:     session.UserID,
+		RecordedAt: time.Now(),
+		Audio:      append([]byte(nil), audioData...),
+		Session:    session.Snapshot(),
+		Providers:  o.GetProviders(),
+	}
+}
+
+// TurnDiff describes one field that differs between a recording and its replay.
+type TurnDiff struct {
+	Field    string `json:"field"`
+	Original string `json:"original"`
+	Replayed string `json:"replayed"`
+	Detail   string `json:"detail,omitempty"` // Word-level diff for text fields
+}
+
+// TurnReplay is the result of ReplayTurn.
+type TurnReplay struct {
+	Original TurnRecording `json:"original"`
+	Replayed TurnRecording `json:"replayed"`
+	Diffs    []TurnDiff    `json:"diffs"`
+}
+
+// Changed reports whether the replay produced a different transcript,
+// response or error. Timing differences alone don't count.
+func (r TurnReplay) Changed() bool {
+	return outcomeChanged(r.Diffs)
+}
+
+func outcomeChanged(diffs []TurnDiff) bool {
+	for _, d := range diffs {
+		if !strings.HasPrefix(d.Field, "timing.") {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplayTurn re-runs a recorded turn against the orchestrator's current
+// providers and config, starting from the recorded session snapshot, and
+// diffs the outcome. The live session is never touched.
+func (o *Orchestrator) ReplayTurn(ctx context.Context, rec TurnRecording) (TurnReplay, error) {
+	if len(rec.Audio) == 0 {
+		return TurnReplay{}, fmt.Errorf("recording %s has no audio", rec.ID)
+	}
+	session := RestoreSession(rec.Session)
+
+	replay := o.newTurnRecording(WithIdempotencyKey(ctx, rec.ID+"-replay"), session, rec.Audio)
+	_, _, err := o.processAudio(WithIdempotencyKey(ctx, replay.ID), session, rec.Audio, false, nil, replay)
+	if err != nil {
+		replay.Error = err.Error()
+	}
+
+	return TurnReplay{Original: rec, Replayed: *replay, Diffs: diffTurns(rec, *replay)}, nil
+}
+
+// diffTurns lists the fields in which two runs of a turn differ.
+func diffTurns(a, b TurnRecording) []TurnDiff {
+	var diffs []TurnDiff
+	for _, f := range []struct{ name, a, b string }{
+		{"transcript", a.Transcript, b.Transcript},
+		{"response", a.Response, b.Response},
+		{"error", a.Error, b.Error},
+	} {
+		if f.a != f.b {
+			diffs = append(diffs, TurnDiff{Field: f.name, Original: f.a, Replayed: f.b, Detail: wordDiff(f.a, f.b)})
+		}
+	}
+	for _, f := range []struct {
+		name string
+		a, b time.Duration
+	}{
+		{"timing.stt", a.Timings.STT, b.Timings.STT},
+		{"timing.llm", a.Timings.LLM, b.Timings.LLM},
+		{"timing.tts", a.Timings.TTS, b.Timings.TTS},
+		{"timing.total", a.Timings.Total, b.Timings.Total},
+	} {
+		if f.a != f.b {
+			diffs = append(diffs, TurnDiff{Field: f.name, Original: f.a.String(), Replayed: f.b.String(), Detail: (f.b - f.a).String()})
+		}
+	}
+	return diffs
+}
+
+// ReplayTurnByID loads a turn from the configured recorder and replays it.
+func (o *Orchestrator) ReplayTurnByID(ctx context.Context, id string) (TurnReplay, error) {
+	recorder := o.getTurnRecorder()
+	if recorder == nil {
+		return TurnReplay{}, ErrRecordingNotFound
+	}
+	rec, err := recorder.LoadTurn(ctx, id)
+	if err != nil {
+		return TurnReplay{}, err
+	}
+	return o.ReplayTurn(ctx, rec)
+}
+
+// wordDiff renders a word-level diff as "kept [-removed-] {+added+}".
+func wordDiff(a, b string) string {
+	x, y := strings.Fields(a), strings.Fields(b)
+	// lcs[i][j] is the LCS length of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			out = append(out, x[i])
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "[-"+x[i]+"-]")
+			i++
+		default:
+			out = append(out, "{+"+y[j]+"+}")
+			j++
+		}
+	}
+	return strings.Join(out, " ")
+}
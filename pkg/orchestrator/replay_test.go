@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReplayTurn_DiffsAgainstNewProviders(t *testing.T) {
+	recorder := NewInMemoryTurnRecorder()
+	orig := New(&MockSTTProvider{transcribeResult: "book a ticket to Rome"}, &MockLLMProvider{completeResult: "Booked."}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), nil)
+	orig.SetTurnRecorder(recorder)
+
+	session := NewConversationSession("caller")
+	session.AddMessage("system", "You are a travel agent.")
+
+	ctx := WithIdempotencyKey(context.Background(), "turn-42")
+	if _, _, err := orig.ProcessAudio(ctx, session, []byte{9, 9}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := recorder.LoadTurn(context.Background(), "turn-42")
+	if err != nil {
+		t.Fatalf("turn was not recorded: %v", err)
+	}
+	if rec.Transcript != "book a ticket to Rome" || rec.Response != "Booked." || len(rec.Session.Context) != 1 {
+		t.Errorf("unexpected recording: %+v", rec)
+	}
+
+	fixed := New(&MockSTTProvider{transcribeResult: "book a ticket to Nome"}, &MockLLMProvider{completeResult: "Booked."}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), nil)
+	replay, err := fixed.ReplayTurn(context.Background(), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !replay.Changed() {
+		t.Fatal("expected replay to report a change")
+	}
+
+	var transcriptDiff *TurnDiff
+	for i := range replay.Diffs {
+		if replay.Diffs[i].Field == "response" {
+			t.Errorf("response did not change but was reported: %+v", replay.Diffs[i])
+		}
+		if replay.Diffs[i].Field == "transcript" {
+			transcriptDiff = &replay.Diffs[i]
+		}
+	}
+	if transcriptDiff == nil {
+		t.Fatal("expected a transcript diff")
+	}
+	if want := "book a ticket to [-Rome-] {+Nome+}"; transcriptDiff.Detail != want {
+		t.Errorf("expected word diff %q, got %q", want, transcriptDiff.Detail)
+	}
+	if len(session.Context) != 3 {
+		t.Errorf("replay must not touch the live session, got %d messages", len(session.Context))
+	}
+}
+
+func TestFileTurnRecorder(t *testing.T) {
+	r, err := NewFileTurnRecorder(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := r.SaveTurn(ctx, TurnRecording{ID: "abc", Audio: []byte{1, 2, 3}, Transcript: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := r.LoadTurn(ctx, "abc")
+	if err != nil || rec.Transcript != "hi" || len(rec.Audio) != 3 {
+		t.Errorf("round trip failed: %+v, %v", rec, err)
+	}
+	if _, err := r.LoadTurn(ctx, "missing"); !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("expected ErrRecordingNotFound, got %v", err)
+	}
+}
+
+func TestWordDiff(t *testing.T) {
+	if got := wordDiff("a b c", "a b c"); got != "a b c" {
+		t.Errorf("identical input should produce no markers, got %q", got)
+	}
+	if got := wordDiff("", "hello"); got != "{+hello+}" {
+		t.Errorf("got %q", got)
+	}
+	if got := wordDiff("the bot misheard me", "the bot heard me"); got != "the bot [-misheard-] {+heard+} me" {
+		t.Errorf("got %q", got)
+	}
+}
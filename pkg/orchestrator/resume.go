@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResumableTurn is a reply the user cut short. The session keeps only what
+// was heard (see Heard); Reply is what the bot meant to say, so a short
+// clarification ("wait, in euros") can be folded into the rest of the
+// answer instead of starting over.
+type ResumableTurn struct {
+	Reply         string    `json:"reply"`
+	Heard         string    `json:"heard"` // As kept in the session, ending in a dash if cut mid-sentence
+	InterruptedAt time.Time `json:"interrupted_at"`
+}
+
+// note asks the LLM to pick up where the reply was cut off.
+func (r ResumableTurn) note() string {
+	heard := strings.TrimSuffix(r.Heard, "—")
+	if heard == "" {
+		return fmt.Sprintf("You were interrupted before the user heard any of your answer: %q. Give that answer now, adjusted to what the user has said since.", r.Reply)
+	}
+	return fmt.Sprintf("You were interrupted while answering. You meant to say: %q, but the user only heard: %q. Continue the answer from where it was cut off, adjusted to what the user has said since, without repeating what they heard.", r.Reply, heard)
+}
+
+// Resumable returns the last reply the user cut short, if it has been
+// neither resumed nor superseded by a new turn.
+func (ms *ManagedStream) Resumable() (ResumableTurn, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.resumable == nil {
+		return ResumableTurn{}, false
+	}
+	return *ms.resumable, true
+}
+
+// Resume has the bot carry on with the reply it was interrupted in, taking
+// into account anything the user said since. It returns once the resumed
+// reply has been spoken or interrupted in turn, or ErrNothingToResume if
+// no reply is waiting. It suits policies that leave the interruption
+// unanswered, such as InterruptStopSpeaking; with
+// Config.ResumeAfterInterruption the stream resumes by itself.
+func (ms *ManagedStream) Resume(ctx context.Context) error {
+	ms.mu.Lock()
+	r := ms.resumable
+	ms.resumable = nil
+	ms.mu.Unlock()
+	if r == nil {
+		return ErrNothingToResume
+	}
+	ms.session.AddMessage(RoleSystem, r.note())
+
+	rCtx, cancel := context.WithCancel(ms.ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+	ms.runLLMAndTTS(rCtx, "")
+	return nil
+}
+
+// resumeIfShort starts a user turn: a cut-off reply is resumed when the
+// user's interjection is short enough, and dropped otherwise.
+func (ms *ManagedStream) resumeIfShort(transcript string) {
+	ms.mu.Lock()
+	done := ms.speechDone
+	ms.mu.Unlock()
+	if done != nil {
+		// The interrupted reply records itself as it winds down.
+		select {
+		case <-done:
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+
+	ms.mu.Lock()
+	r := ms.resumable
+	ms.resumable = nil
+	ms.mu.Unlock()
+	if r == nil || ms.orch == nil {
+		return
+	}
+	if n := ms.orch.GetConfig().ResumeAfterInterruption; n > 0 && countWords(transcript) <= n {
+		ms.session.AddMessage(RoleSystem, r.note())
+		ms.orch.logger.Info("resuming interrupted reply", "sessionID", ms.session.ID)
+	}
+}
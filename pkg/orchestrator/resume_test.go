@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// promptLLM answers with reply and keeps the last prompt it was given.
+type promptLLM struct {
+	mu    sync.Mutex
+	reply string
+	last  []Message
+}
+
+func (l *promptLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.last = messages
+	return l.reply, nil
+}
+
+func (l *promptLLM) Name() string { return "prompt" }
+
+func (l *promptLLM) lastMessage() Message {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last[len(l.last)-1]
+}
+
+// interruptedStream speaks reply and cuts it off a quarter of the way in.
+func interruptedStream(t *testing.T, cfg Config, llm *promptLLM) *ManagedStream {
+	t.Helper()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	tts := &MockTTSProvider{synthesizeResult: make([]byte, 88200)}
+	o := NewWithVAD(&MockSTTProvider{}, llm, tts, nil, cfg)
+	session := NewConversationSession("u")
+	session.AddMessage(RoleUser, "How much is the deluxe room?")
+	ms := o.NewManagedStream(context.Background(), session)
+	t.Cleanup(ms.Close)
+
+	ms.runLLMAndTTS(ms.ctx, "")
+	session.ReportPlaybackBytes(22050)
+	ms.Interrupt()
+	return ms
+}
+
+func TestManagedStream_Resume(t *testing.T) {
+	reply := "The deluxe room is two hundred dollars a night, breakfast included."
+	llm := &promptLLM{reply: reply}
+	ms := interruptedStream(t, DefaultConfig(), llm)
+
+	r, ok := ms.Resumable()
+	if !ok || r.Reply != reply || !strings.HasSuffix(r.Heard, "—") {
+		t.Fatalf("expected the cut-off reply to be resumable, got %+v, %v", r, ok)
+	}
+
+	ms.session.AddMessage(RoleUser, "Wait, in euros?")
+	if err := ms.Resume(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	note := llm.lastMessage()
+	if note.Role != RoleSystem || !strings.Contains(note.Content, reply) || !strings.Contains(note.Content, strings.TrimSuffix(r.Heard, "—")) {
+		t.Errorf("expected a note to resume the reply, got %+v", note)
+	}
+	if err := ms.Resume(context.Background()); !errors.Is(err, ErrNothingToResume) {
+		t.Errorf("second Resume = %v, want ErrNothingToResume", err)
+	}
+}
+
+func TestManagedStream_ResumeAfterShortInterjection(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ResumeAfterInterruption = 3
+	reply := "The deluxe room is two hundred dollars a night, breakfast included."
+
+	llm := &promptLLM{reply: reply}
+	ms := interruptedStream(t, cfg, llm)
+	ms.session.AddMessage(RoleUser, "Wait, in euros?")
+	ms.runLLMAndTTS(ms.ctx, "Wait, in euros?")
+	if note := llm.lastMessage(); note.Role != RoleSystem || !strings.Contains(note.Content, reply) {
+		t.Errorf("a short interjection should resume the reply, got %+v", note)
+	}
+
+	llm = &promptLLM{reply: reply}
+	ms = interruptedStream(t, cfg, llm)
+	question := "Actually, what about the suite on the top floor?"
+	ms.session.AddMessage(RoleUser, question)
+	ms.runLLMAndTTS(ms.ctx, question)
+	if last := llm.lastMessage(); last.Role != RoleUser {
+		t.Errorf("a new question should start afresh, got %+v", last)
+	}
+	if _, ok := ms.Resumable(); ok {
+		t.Error("a new turn should drop the cut-off reply")
+	}
+}
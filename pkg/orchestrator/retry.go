@@ -0,0 +1,259 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitError is returned by providers when the vendor answers with
+// HTTP 429 (or an equivalent throttling signal).
+type RateLimitError struct {
+	Provider   string
+	StatusCode int
+	RetryAfter time.Duration // Zero when the vendor did not say how long to wait
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s rate limited (status %d), retry after %v", e.Provider, e.StatusCode, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s rate limited (status %d)", e.Provider, e.StatusCode)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// NewRateLimitError builds a RateLimitError from a throttled HTTP response,
+// reading Retry-After and the x-ratelimit-reset-* headers used by
+// OpenAI-compatible APIs.
+func NewRateLimitError(provider string, resp *http.Response) *RateLimitError {
+	e := &RateLimitError{Provider: provider}
+	if resp == nil {
+		return e
+	}
+	e.StatusCode = resp.StatusCode
+	for _, h := range []string{"Retry-After", "X-Ratelimit-Reset-Requests", "X-Ratelimit-Reset-Tokens"} {
+		if d := ParseRetryAfter(resp.Header.Get(h)); d > e.RetryAfter {
+			e.RetryAfter = d
+		}
+	}
+	return e
+}
+
+// ParseRetryAfter understands the three formats vendors use in practice:
+// delay-seconds ("3", "1.5"), an HTTP-date, and Go-style durations ("6m0s", "250ms").
+func ParseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// noRetry marks an error that must not be retried even if its cause would
+// normally qualify, e.g. a stream that already delivered output.
+type noRetry struct{ err error }
+
+func (e noRetry) Error() string { return e.err.Error() }
+func (e noRetry) Unwrap() error { return e.err }
+
+// IsRetryable reports whether err is worth retrying against the same provider.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var nr noRetry
+	if errors.As(err, &nr) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return false
+}
+
+// RetryPolicy controls how provider calls are retried.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration // A Retry-After longer than this fails fast instead of waiting
+}
+
+func retryPolicyFromConfig(cfg Config) RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: cfg.MaxRetries,
+		BaseDelay:  cfg.RetryBaseDelay,
+		MaxDelay:   cfg.RetryMaxDelay,
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	delay := p.BaseDelay << attempt
+	var rl *RateLimitError
+	if errors.As(err, &rl) && rl.RetryAfter > delay {
+		delay = rl.RetryAfter
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		if rl != nil && rl.RetryAfter > p.MaxDelay {
+			// The vendor asked for more patience than we have; let the caller route elsewhere.
+			return 0, false
+		}
+		delay = p.MaxDelay
+	}
+	return delay, true
+}
+
+// Retry runs fn until it succeeds, returns a non-retryable error, or the policy
+// is exhausted. Waiting honors the provider's Retry-After when present. If ctx
+// is done while waiting, the error is ctx's, wrapping the last attempt's.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(ctx); err == nil || !IsRetryable(err) || attempt >= policy.MaxRetries {
+			return err
+		}
+		delay, ok := policy.backoff(attempt, err)
+		if !ok {
+			return err
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+		case <-t.C:
+		}
+	}
+}
+
+// providerCooldowns remembers which providers recently throttled us so that
+// routing decisions can prefer someone else until the window passes.
+type providerCooldowns struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+func newProviderCooldowns() *providerCooldowns {
+	return &providerCooldowns{until: make(map[string]time.Time)}
+}
+
+func (c *providerCooldowns) observe(err error, fallback time.Duration) {
+	var rl *RateLimitError
+	if c == nil || !errors.As(err, &rl) || rl.Provider == "" {
+		return
+	}
+	d := rl.RetryAfter
+	if d <= 0 {
+		d = fallback
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if until := time.Now().Add(d); until.After(c.until[rl.Provider]) {
+		c.until[rl.Provider] = until
+	}
+}
+
+func (c *providerCooldowns) remaining(provider string) time.Duration {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until, ok := c.until[provider]
+	if !ok {
+		return 0
+	}
+	d := time.Until(until)
+	if d <= 0 {
+		delete(c.until, provider)
+		return 0
+	}
+	return d
+}
+
+type cooldownsKey struct{}
+
+// cooldownWatch lets fallback chains see and record the rate-limit
+// cooldowns of the orchestrator calling them.
+type cooldownWatch struct {
+	cooldowns *providerCooldowns
+	fallback  time.Duration // Config.RateLimitCooldown
+}
+
+func (w cooldownWatch) observe(err error) {
+	w.cooldowns.observe(err, w.fallback)
+}
+
+// withCooldowns returns ctx carrying the orchestrator's provider cooldowns.
+func (o *Orchestrator) withCooldowns(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cooldownsKey{}, cooldownWatch{o.cooldowns, o.GetConfig().RateLimitCooldown})
+}
+
+func cooldownsFromContext(ctx context.Context) cooldownWatch {
+	w, _ := ctx.Value(cooldownsKey{}).(cooldownWatch)
+	return w
+}
+
+func (c *providerCooldowns) weight(provider string) float64 {
+	if c.remaining(provider) > 0 {
+		return throttledProviderWeight
+	}
+	return 1.0
+}
+
+// throttledProviderWeight is the routing weight given to a provider that is
+// still inside its rate-limit cooldown.
+const throttledProviderWeight = 0.1
+
+// ProviderCooldown returns how long the named provider should still be avoided
+// after a rate-limit response. Zero means it is healthy.
+func (o *Orchestrator) ProviderCooldown(name string) time.Duration {
+	return o.cooldowns.remaining(name)
+}
+
+// ProviderWeight is the relative routing weight for the named provider: 1.0
+// normally, reduced while the provider is cooling down from a 429.
+func (o *Orchestrator) ProviderWeight(name string) float64 {
+	return o.cooldowns.weight(name)
+}
+
+// withRetry wraps a provider call with the configured retry policy and records
+// rate-limit cooldowns for routing.
+func (o *Orchestrator) withRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	cfg := o.GetConfig()
+	policy := retryPolicyFromConfig(cfg)
+	return Retry(o.withCooldowns(ctx), policy, func(ctx context.Context) error {
+		err := fn(ctx)
+		o.cooldowns.observe(err, cfg.RateLimitCooldown)
+		if errors.Is(err, ErrRateLimited) {
+			o.logger.Warn("provider rate limited", "error", err)
+		}
+		return err
+	})
+}
@@ -0,0 +1,162 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type flakySTT struct {
+	failures int
+	calls    int
+	err      error
+}
+
+func (f *flakySTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return TranscriptionResult{}, f.err
+	}
+	return TranscriptionResult{Text: "hello there"}, nil
+}
+
+func (f *flakySTT) Name() string { return "flaky" }
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":      0,
+		"3":     3 * time.Second,
+		"1.5":   1500 * time.Millisecond,
+		"250ms": 250 * time.Millisecond,
+		"6m0s":  6 * time.Minute,
+		"bogus": 0,
+		"-1":    0,
+		"0":     0,
+		"  2  ": 2 * time.Second,
+	}
+	for in, want := range cases {
+		if got := ParseRetryAfter(in); got != want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", in, got, want)
+		}
+	}
+
+	future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if got := ParseRetryAfter(future); got <= 0 || got > 10*time.Second {
+		t.Errorf("expected HTTP-date to parse to ~10s, got %v", got)
+	}
+}
+
+func TestNewRateLimitError(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", "2")
+	resp.Header.Set("X-Ratelimit-Reset-Tokens", "7s")
+
+	err := NewRateLimitError("groq-llm", resp)
+	if err.RetryAfter != 7*time.Second {
+		t.Errorf("expected the longest reset hint (7s), got %v", err.RetryAfter)
+	}
+	if !errors.Is(err, ErrRateLimited) {
+		t.Error("RateLimitError should unwrap to ErrRateLimited")
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	err := Retry(context.Background(), RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return &RateLimitError{Provider: "p", RetryAfter: 50 * time.Millisecond}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected to wait for Retry-After, only waited %v", elapsed)
+	}
+}
+
+func TestRetryGivesUpWhenRetryAfterExceedsMaxDelay(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		return &RateLimitError{Provider: "p", RetryAfter: time.Minute}
+	})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected to fail fast after 1 call, got %d", calls)
+	}
+}
+
+func TestRetryStopsWhenContextEndsDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := Retry(ctx, RetryPolicy{MaxRetries: 3, BaseDelay: time.Minute}, func(ctx context.Context) error {
+		return &RateLimitError{Provider: "p"}
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected the deadline wrapping the last error, got %v", err)
+	}
+}
+
+func TestRetrySkipsNonRetryableErrors(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{MaxRetries: 3}, func(ctx context.Context) error {
+		calls++
+		return ErrTestError
+	})
+	if err != ErrTestError || calls != 1 {
+		t.Errorf("expected a single call returning ErrTestError, got %d calls, err=%v", calls, err)
+	}
+}
+
+func TestOrchestrator_RetriesAfterRateLimit(t *testing.T) {
+	stt := &flakySTT{failures: 1, err: &RateLimitError{Provider: "flaky", RetryAfter: 5 * time.Millisecond}}
+	cfg := DefaultConfig()
+	cfg.RetryBaseDelay = time.Millisecond
+
+	orch := New(stt, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg, nil)
+
+	result, err := orch.Transcribe(context.Background(), []byte{1, 2}, LanguageEn)
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if result.Text != "hello there" || stt.calls != 2 {
+		t.Errorf("expected success on second call, got %q after %d calls", result.Text, stt.calls)
+	}
+}
+
+func TestOrchestrator_RateLimitCooldown(t *testing.T) {
+	stt := &flakySTT{failures: 10, err: &RateLimitError{Provider: "flaky", RetryAfter: 80 * time.Millisecond}}
+	cfg := DefaultConfig()
+	cfg.RetryMaxDelay = 10 * time.Millisecond
+
+	orch := New(stt, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg, nil)
+
+	if _, err := orch.Transcribe(context.Background(), []byte{1, 2}, LanguageEn); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if stt.calls != 1 {
+		t.Errorf("expected to fail fast when Retry-After exceeds RetryMaxDelay, got %d calls", stt.calls)
+	}
+	if w := orch.ProviderWeight("flaky"); w >= 1.0 {
+		t.Errorf("expected throttled provider to be down-weighted, got %v", w)
+	}
+	if orch.ProviderCooldown("flaky") <= 0 {
+		t.Error("expected a positive cooldown")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if w := orch.ProviderWeight("flaky"); w != 1.0 {
+		t.Errorf("expected weight to recover after cooldown, got %v", w)
+	}
+}
@@ -0,0 +1,256 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SegmenterConfig controls how LLM output is cut into pieces for TTS.
+type SegmenterConfig struct {
+	// Language picks the abbreviation list and defaults; empty means the
+	// session's language.
+	Language Language
+	// MinRunes joins shorter segments ("Ok.", "Hi!") with the next one so
+	// TTS isn't asked for a fragment too short to sound natural. Defaults to
+	// 8, or 4 for Chinese and Japanese.
+	MinRunes int
+	// ClauseRunes, when set, also cuts at a comma, semicolon or colon once a
+	// segment is at least this long, so a long opening sentence doesn't hold
+	// back the first audio.
+	ClauseRunes int
+	// Abbreviations adds words (without the trailing period) after which a
+	// period does not end a sentence.
+	Abbreviations []string
+}
+
+var segmenterAbbreviations = map[Language][]string{
+	LanguageEn: {"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st", "vs", "e.g", "i.e", "approx", "inc", "ltd", "mt"},
+	LanguageEs: {"sr", "sra", "srta", "dr", "dra", "ud", "uds", "lic", "ing", "av", "núm", "pág", "aprox"},
+	LanguageFr: {"m", "mme", "mlle", "dr", "pr", "st", "ste", "av", "env", "p.ex"},
+	LanguageDe: {"hr", "fr", "dr", "prof", "nr", "str", "z.b", "bzw", "ca", "usw", "d.h"},
+	LanguageIt: {"sig", "sig.ra", "dott", "dr", "prof", "ing", "avv", "ecc", "pag"},
+	LanguagePt: {"sr", "sra", "dr", "dra", "prof", "av", "pág", "aprox", "nº"},
+}
+
+// numberAbbreviations are words that abbreviate "number" when a number
+// follows ("No. 5") and are words of their own otherwise ("The answer is
+// no."), so they end a sentence unless the next word is a number.
+var numberAbbreviations = map[Language]map[string]bool{
+	LanguageEn: {"no": true},
+}
+
+// ordinalLanguages write ordinal numbers with a period ("am 3. Mai"), so a
+// number followed by a period does not end a sentence.
+var ordinalLanguages = map[Language]bool{LanguageDe: true}
+
+// Segmenter splits a stream of LLM text into speakable sentences as soon as
+// each one is complete. Periods in abbreviations, initials, decimals and
+// (in German) ordinals do not end a sentence, nor does one followed by a
+// lowercase word. CJK sentence punctuation ends one even without a
+// following space, as do ASCII "!" and "?" between CJK characters. A
+// Segmenter is not safe for concurrent use.
+type Segmenter struct {
+	cfg    SegmenterConfig
+	abbrev map[string]bool
+	buf    []rune
+
+	eager        EagerSynthesis
+	pendingSince time.Time
+}
+
+func NewSegmenter(cfg SegmenterConfig) *Segmenter {
+	if cfg.MinRunes <= 0 {
+		cfg.MinRunes = 8
+		if cfg.Language == LanguageZh || cfg.Language == LanguageJa {
+			cfg.MinRunes = 4
+		}
+	}
+	abbrev := make(map[string]bool)
+	for _, a := range segmenterAbbreviations[cfg.Language] {
+		abbrev[a] = true
+	}
+	for _, a := range cfg.Abbreviations {
+		abbrev[strings.ToLower(strings.TrimSuffix(a, "."))] = true
+	}
+	return &Segmenter{cfg: cfg, abbrev: abbrev}
+}
+
+// SplitSentences segments a complete text in one go.
+func SplitSentences(text string, lang Language) []string {
+	s := NewSegmenter(SegmenterConfig{Language: lang})
+	out := s.Push(text)
+	if rest := s.Flush(); rest != "" {
+		out = append(out, rest)
+	}
+	return out
+}
+
+func (o *Orchestrator) newSegmenter(ctx context.Context, lang Language) *Segmenter {
+	cfg := o.GetConfig().Segmentation
+	if cfg.Language == "" {
+		cfg.Language = lang
+	}
+	s := NewSegmenter(cfg)
+	s.eager = o.GetConfig().EagerSynthesis.withDefaults()
+	s.eager.Enabled = s.eager.Enabled && gated(ctx, FlagEagerSynthesis)
+	return s
+}
+
+// Push adds text and returns the segments it completed.
+func (s *Segmenter) Push(text string) []string {
+	if len(s.buf) == 0 {
+		s.pendingSince = time.Now()
+	}
+	s.buf = append(s.buf, []rune(text)...)
+	var out []string
+	start := 0
+	for i := 0; i < len(s.buf); i++ {
+		r := s.buf[i]
+		cut := -1
+		switch {
+		case isCJKTerminal(r), (r == '!' || r == '?') && i+1 < len(s.buf) && isCJK(s.buf[i+1]):
+			cut = s.skipClosers(i + 1)
+		case isCJKClause(r) && s.longEnough(start, i):
+			cut = i + 1
+		case r == '\n':
+			cut = i
+		case unicode.IsSpace(r) && i > start:
+			if s.sentenceEnd(start, i-1) || (isClausePunct(s.buf[i-1]) && s.longEnough(start, i)) {
+				cut = i
+			}
+		}
+		if cut < 0 {
+			continue
+		}
+		segment := strings.TrimSpace(string(s.buf[start:cut]))
+		if len([]rune(segment)) < s.cfg.MinRunes {
+			continue
+		}
+		out = append(out, segment)
+		start = cut
+		i = cut - 1
+	}
+	s.buf = append([]rune(nil), s.buf[start:]...)
+	if len(out) > 0 {
+		s.pendingSince = time.Now()
+	}
+	if segment := s.eagerCut(); segment != "" {
+		out = append(out, segment)
+	}
+	return out
+}
+
+// eagerCut releases the buffered words once they have waited MaxWait for a
+// sentence end, keeping back the word that may still be incomplete.
+func (s *Segmenter) eagerCut() string {
+	if !s.eager.Enabled || len(s.buf) < s.eager.MinChars || time.Since(s.pendingSince) < s.eager.MaxWait {
+		return ""
+	}
+	cut := len(s.buf) - 1
+	for cut > 0 && !unicode.IsSpace(s.buf[cut]) {
+		cut--
+	}
+	segment := strings.TrimSpace(string(s.buf[:cut]))
+	if len([]rune(segment)) < s.cfg.MinRunes {
+		return ""
+	}
+	s.buf = append([]rune(nil), s.buf[cut:]...)
+	s.pendingSince = time.Now()
+	return segment
+}
+
+// Flush returns whatever is left once the text is complete.
+func (s *Segmenter) Flush() string {
+	rest := strings.TrimSpace(string(s.buf))
+	s.buf = nil
+	return rest
+}
+
+func (s *Segmenter) longEnough(start, i int) bool {
+	return s.cfg.ClauseRunes > 0 && i-start >= s.cfg.ClauseRunes
+}
+
+// skipClosers moves past the quotes and brackets closing a sentence, and
+// any further end punctuation ("本当？！").
+func (s *Segmenter) skipClosers(i int) int {
+	for i < len(s.buf) && (isCloser(s.buf[i]) || isCJKTerminal(s.buf[i]) || s.buf[i] == '!' || s.buf[i] == '?') {
+		i++
+	}
+	return i
+}
+
+// sentenceEnd reports whether the rune at i closes a sentence that began at
+// start.
+func (s *Segmenter) sentenceEnd(start, i int) bool {
+	for i > start && isCloser(s.buf[i]) {
+		i--
+	}
+	switch s.buf[i] {
+	case '!', '?', '…':
+		return true
+	case '.':
+	default:
+		return false
+	}
+
+	j := i
+	for j > start && !unicode.IsSpace(s.buf[j-1]) {
+		j--
+	}
+	word := strings.TrimLeft(string(s.buf[j:i]), `"'(¿¡«`)
+	if s.abbrev[strings.ToLower(word)] {
+		return false
+	}
+	if numberAbbreviations[s.cfg.Language][strings.ToLower(word)] {
+		// Without the next word yet, wait for it.
+		if next, ok := s.nextLetter(i + 1); !ok || unicode.IsDigit(next) {
+			return false
+		}
+	}
+	// A lone capital ("J. R. R. Tolkien") is an initial, not an ending.
+	if r := []rune(word); len(r) == 1 && unicode.IsUpper(r[0]) {
+		return false
+	}
+	if ordinalLanguages[s.cfg.Language] && word != "" && strings.TrimFunc(word, unicode.IsDigit) == "" {
+		return false
+	}
+	// A sentence doesn't go on in lowercase; the period belongs to an
+	// abbreviation we don't know. Without the next word yet, assume an end.
+	if next, ok := s.nextLetter(i + 1); ok && unicode.IsLower(next) {
+		return false
+	}
+	return true
+}
+
+// nextLetter returns the first rune of the word after position i, if it has
+// arrived.
+func (s *Segmenter) nextLetter(i int) (rune, bool) {
+	for ; i < len(s.buf); i++ {
+		if r := s.buf[i]; !unicode.IsSpace(r) && !isCloser(r) {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+func isCloser(r rune) bool {
+	return strings.ContainsRune(`"')]»”’」』）`, r)
+}
+
+func isClausePunct(r rune) bool {
+	return r == ',' || r == ';' || r == ':'
+}
+
+func isCJKTerminal(r rune) bool {
+	return r == '。' || r == '！' || r == '？' || r == '｡'
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+func isCJKClause(r rune) bool {
+	return r == '，' || r == '、' || r == '；' || r == '：'
+}
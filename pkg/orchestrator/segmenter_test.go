@@ -0,0 +1,104 @@
+package orchestrator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSegmenter(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		want   []string
+		rest   string
+	}{
+		{
+			name:   "splits at sentence ends across tokens",
+			tokens: []string{"Sure, I can", " help with that. It", " will take a minute! Anything", " else"},
+			want:   []string{"Sure, I can help with that.", "It will take a minute!"},
+			rest:   "Anything else",
+		},
+		{
+			name:   "keeps short sentences with the next",
+			tokens: []string{"Ok. Your table is booked. "},
+			want:   []string{"Ok. Your table is booked."},
+		},
+		{
+			name:   "ignores abbreviations, initials and decimals",
+			tokens: []string{"Dr. Smith read J. R. R. Tolkien for 2.5 hours. Then"},
+			want:   []string{"Dr. Smith read J. R. R. Tolkien for 2.5 hours."},
+			rest:   "Then",
+		},
+		{
+			name:   "no as a word ends a sentence, as an abbreviation before a number does not",
+			tokens: []string{"The answer is no.", " Next, order No.", " 5 ships today. Then"},
+			want:   []string{"The answer is no.", "Next, order No. 5 ships today."},
+			rest:   "Then",
+		},
+		{
+			name:   "punctuation inside quotes",
+			tokens: []string{`He said "see you tomorrow." Then he left`},
+			want:   []string{`He said "see you tomorrow."`},
+			rest:   "Then he left",
+		},
+		{
+			name:   "spanish and newlines",
+			tokens: []string{"¿Quieres una mesa para dos? Perfecto\nLa reservo ahora"},
+			want:   []string{"¿Quieres una mesa para dos?", "Perfecto"},
+			rest:   "La reservo ahora",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSegmenter(SegmenterConfig{Language: LanguageEn})
+			var got []string
+			for _, tok := range tt.tokens {
+				got = append(got, s.Push(tok)...)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sentences = %q, want %q", got, tt.want)
+			}
+			if rest := s.Flush(); rest != tt.rest {
+				t.Errorf("rest = %q, want %q", rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestSegmenter_Languages(t *testing.T) {
+	tests := []struct {
+		name string
+		lang Language
+		text string
+		want []string
+	}{
+		{"chinese without spaces", LanguageZh, "好的，我帮您预订。今天晚上八点有空位！您要几位？", []string{"好的，我帮您预订。", "今天晚上八点有空位！", "您要几位？"}},
+		{"japanese with closing quote", LanguageJa, "「かしこまりました。」少々お待ちください。", []string{"「かしこまりました。」", "少々お待ちください。"}},
+		{"spanish abbreviations", LanguageEs, "La Sra. García llega a las ocho. El Dr. Ruiz también.", []string{"La Sra. García llega a las ocho.", "El Dr. Ruiz también."}},
+		{"german abbreviations", LanguageDe, "Das kostet ca. 20 Euro, z.B. mit Karte. Danke schön!", []string{"Das kostet ca. 20 Euro, z.B. mit Karte.", "Danke schön!"}},
+		{"decimals and prices", LanguageEn, "The total is $12.50 today. Shipping adds 3.5%.", []string{"The total is $12.50 today.", "Shipping adds 3.5%."}},
+		{"german ordinals", LanguageDe, "Wir sehen uns am 3. Mai um neun. Bis dann!", []string{"Wir sehen uns am 3. Mai um neun.", "Bis dann!"}},
+		{"ascii punctuation in chinese", LanguageZh, "真的吗?我们明天见!好的。", []string{"真的吗?", "我们明天见!", "好的。"}},
+		{"stacked japanese terminals", LanguageJa, "本当ですか？！それは良かったです。", []string{"本当ですか？！", "それは良かったです。"}},
+		{"unknown abbreviation before lowercase", LanguageEn, "It weighs 5 lbs. and fits in a bag. Want one?", []string{"It weighs 5 lbs. and fits in a bag.", "Want one?"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitSentences(tt.text, tt.lang); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmenter_ClausesAndCustomAbbreviations(t *testing.T) {
+	s := NewSegmenter(SegmenterConfig{Language: LanguageEn, ClauseRunes: 20, Abbreviations: []string{"Rm."}})
+	got := s.Push("Your booking at the riverside restaurant, table nine, is confirmed for Rm. 4 tonight. See you")
+	want := []string{"Your booking at the riverside restaurant,", "table nine, is confirmed for Rm. 4 tonight."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if rest := s.Flush(); rest != "See you" {
+		t.Errorf("rest = %q", rest)
+	}
+}
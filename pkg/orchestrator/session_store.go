@@ -0,0 +1,281 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SessionSnapshot is the serialisable state of a ConversationSession.
+// Version is owned by the SessionStore and used for optimistic locking.
+type SessionSnapshot struct {
+	ID              string     `json:"id"`
+	UserID          string     `json:"user_id,omitempty"`
+	TenantID        string     `json:"tenant_id,omitempty"`
+	Priority        Priority   `json:"priority,omitempty"`
+	Context         []Message  `json:"context"`
+	LastUser        string     `json:"last_user,omitempty"`
+	LastAssistant   string     `json:"last_assistant,omitempty"`
+	MaxMessages     int        `json:"max_messages"`
+	CurrentVoice    Voice      `json:"voice"`
+	CurrentLanguage Language   `json:"language"`
+	Tools           []Tool     `json:"tools,omitempty"`
+	AllowedTools    []string   `json:"allowed_tools,omitempty"`
+	Usage           TokenUsage `json:"usage"`
+	SpeechRate      float64    `json:"speech_rate,omitempty"`
+	Greeting        string     `json:"greeting,omitempty"`
+	Closing         string     `json:"closing,omitempty"`
+	Version         int64      `json:"version"`
+}
+
+// SessionStore persists sessions outside the process so any orchestrator
+// instance can serve any turn.
+//
+// Save must be atomic: it succeeds only if the stored version still equals
+// snap.Version (0 meaning "does not exist yet") and returns the new version.
+// Otherwise it returns ErrVersionConflict. Stores backed by Redis or SQL can
+// implement this with WATCH/MULTI or a conditional UPDATE.
+type SessionStore interface {
+	Load(ctx context.Context, id string) (SessionSnapshot, error)
+	Save(ctx context.Context, snap SessionSnapshot) (int64, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Snapshot copies the session into a SessionSnapshot.
+func (s *ConversationSession) Snapshot() SessionSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap := SessionSnapshot{
+		ID:              s.ID,
+		UserID:          s.UserID,
+		TenantID:        s.TenantID,
+		Priority:        s.Priority,
+		Context:         make([]Message, len(s.Context)),
+		LastUser:        s.LastUser,
+		LastAssistant:   s.LastAssistant,
+		MaxMessages:     s.MaxMessages,
+		CurrentVoice:    s.CurrentVoice,
+		CurrentLanguage: s.CurrentLanguage,
+		Tools:           append([]Tool(nil), s.Tools...),
+		Usage:           s.usage,
+		SpeechRate:      s.speechRate,
+		Greeting:        s.greeting,
+		Closing:         s.closing,
+	}
+	if s.AllowedTools != nil {
+		snap.AllowedTools = append([]string{}, s.AllowedTools...)
+	}
+	copy(snap.Context, s.Context)
+	return snap
+}
+
+// RestoreSession builds a live session from a snapshot.
+func RestoreSession(snap SessionSnapshot) *ConversationSession {
+	s := NewConversationSession(snap.ID)
+	if snap.UserID != "" {
+		s.UserID = snap.UserID
+	}
+	s.TenantID = snap.TenantID
+	s.Priority = snap.Priority
+	s.Context = append([]Message{}, snap.Context...)
+	s.LastUser = snap.LastUser
+	s.LastAssistant = snap.LastAssistant
+	if snap.MaxMessages > 0 {
+		s.MaxMessages = snap.MaxMessages
+	}
+	if snap.CurrentVoice != "" {
+		s.CurrentVoice = snap.CurrentVoice
+	}
+	if snap.CurrentLanguage != "" {
+		s.CurrentLanguage = snap.CurrentLanguage
+	}
+	s.Tools = append([]Tool(nil), snap.Tools...)
+	s.usage = snap.Usage
+	s.speechRate = snap.SpeechRate
+	s.greeting, s.closing = snap.Greeting, snap.Closing
+	if snap.AllowedTools != nil {
+		s.AllowedTools = append([]string{}, snap.AllowedTools...)
+	}
+	return s
+}
+
+// InMemorySessionStore is a SessionStore for tests and single-instance
+// deployments.
+type InMemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]SessionSnapshot
+}
+
+func NewInMemorySessionStore() *InMemorySessionStore {
+	return &InMemorySessionStore{sessions: make(map[string]SessionSnapshot)}
+}
+
+func (m *InMemorySessionStore) Load(ctx context.Context, id string) (SessionSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap, ok := m.sessions[id]
+	if !ok {
+		return SessionSnapshot{}, ErrSessionNotFound
+	}
+	snap.Context = append([]Message(nil), snap.Context...)
+	return snap, nil
+}
+
+func (m *InMemorySessionStore) Save(ctx context.Context, snap SessionSnapshot) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sessions[snap.ID].Version != snap.Version {
+		return 0, ErrVersionConflict
+	}
+	snap.Version++
+	snap.Context = append([]Message(nil), snap.Context...)
+	m.sessions[snap.ID] = snap
+	return snap.Version, nil
+}
+
+func (m *InMemorySessionStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *InMemorySessionStore) PurgeUser(ctx context.Context, userID string) (int, error) {
+	return m.PurgeUserWithPrefix(ctx, "", userID)
+}
+
+func (m *InMemorySessionStore) PurgeUserWithPrefix(ctx context.Context, prefix, userID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, snap := range m.sessions {
+		if snap.UserID == userID && strings.HasPrefix(id, prefix) {
+			delete(m.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// maxSaveAttempts bounds how often a turn is re-applied onto a session that
+// another instance updated concurrently.
+const maxSaveAttempts = 3
+
+func (o *Orchestrator) SetSessionStore(store SessionStore) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sessionStore = store
+}
+
+func (o *Orchestrator) getSessionStore() SessionStore {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.sessionStore
+}
+
+// LoadSession fetches a session from the configured store, creating one with
+// the orchestrator defaults if it does not exist yet.
+func (o *Orchestrator) LoadSession(ctx context.Context, sessionID string) (*ConversationSession, int64, error) {
+	store := o.getSessionStore()
+	if store == nil {
+		return nil, 0, ErrNoSessionStore
+	}
+	snap, err := store.Load(ctx, sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return o.NewSessionWithDefaults(sessionID), 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load session %s: %w", sessionID, err)
+	}
+	session := RestoreSession(snap)
+	session.TrimStrategy = o.GetConfig().TrimStrategy
+	return session, snap.Version, nil
+}
+
+// ProcessAudioStateless runs one turn without keeping any session in memory:
+// the session is loaded from the SessionStore, the turn is processed, and the
+// result is saved back with a version check. If another instance saved the
+// session in the meantime, this turn's messages are re-applied on top of the
+// newer state instead of overwriting it, unless the turn rewrote the context,
+// as "start over" does; then the turn's context replaces the newer one.
+func (o *Orchestrator) ProcessAudioStateless(ctx context.Context, sessionID string, audioData []byte, streaming bool, onAudioChunk func([]byte) error) (string, []byte, error) {
+	session, version, err := o.LoadSession(ctx, sessionID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Disable trimming while the turn runs so the appended messages can be
+	// identified; the limit is re-applied when they are merged for saving.
+	maxMessages := session.MaxMessages
+	session.MaxMessages = math.MaxInt
+	loaded := session.GetContextCopy()
+	usageBefore := session.Usage()
+
+	transcript, audio, turnErr := o.ProcessAudio(ctx, session, audioData, streaming, onAudioChunk)
+
+	// Turns usually only append to the context, but "start over" and
+	// compaction rewrite it; then the whole context is saved instead.
+	after := session.GetContextCopy()
+	rewritten := len(after) < len(loaded) || !reflect.DeepEqual(after[:len(loaded)], loaded)
+	added := after
+	if !rewritten {
+		added = after[len(loaded):]
+	}
+	if len(added) > 0 || rewritten {
+		if err := o.saveTurn(ctx, session, version, maxMessages, len(loaded), added, rewritten, session.Usage().sub(usageBefore)); err != nil {
+			return transcript, audio, err
+		}
+	}
+	return transcript, audio, turnErr
+}
+
+// saveTurn saves a turn's messages and usage. The messages added are
+// re-applied on top of the session if another instance saved it in the
+// meantime. With replace set, added is the turn's whole context, which
+// replaces the stored one, concurrent changes included: the turn rewrote
+// the context it loaded, so there is nothing to merge them into.
+func (o *Orchestrator) saveTurn(ctx context.Context, session *ConversationSession, version int64, maxMessages, before int, added []Message, replace bool, usage TokenUsage) error {
+	store := o.getSessionStore()
+
+	base := RestoreSession(session.Snapshot())
+	base.Context = base.Context[:min(before, len(base.Context))]
+	base.usage = base.usage.sub(usage)
+	for attempt := 0; attempt < maxSaveAttempts; attempt++ {
+		merged := RestoreSession(base.Snapshot())
+		merged.MaxMessages = maxMessages
+		merged.TrimStrategy = session.TrimStrategy
+		if replace {
+			merged.Context = []Message{}
+		}
+		for _, msg := range added {
+			merged.AddMessageRaw(msg)
+		}
+		// Adding leaves a ContextTrimStrategy's cut to the next LLM call,
+		// which a stateless turn never makes on this copy; trim it here.
+		merged.promptContext(ctx)
+		merged.addUsageTotal(usage)
+
+		snap := merged.Snapshot()
+		snap.Version = version
+		_, err := store.Save(ctx, snap)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrVersionConflict) {
+			return fmt.Errorf("failed to save session %s: %w", session.ID, err)
+		}
+
+		o.logger.Warn("session changed concurrently, re-applying turn", "sessionID", session.ID, "attempt", attempt+1)
+		latest, err := store.Load(ctx, session.ID)
+		if err != nil {
+			return fmt.Errorf("failed to reload session %s: %w", session.ID, err)
+		}
+		base = RestoreSession(latest)
+		version = latest.Version
+	}
+	return fmt.Errorf("failed to save session %s: %w", session.ID, ErrVersionConflict)
+}
@@ -0,0 +1,172 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSessionSnapshotRoundTrip(t *testing.T) {
+	s := NewConversationSession("snap")
+	s.MaxMessages = 7
+	s.CurrentVoice = VoiceM2
+	s.CurrentLanguage = LanguageEs
+	s.AddMessage("user", "hola")
+	s.AddMessage("assistant", "buenas")
+
+	r := RestoreSession(s.Snapshot())
+	if r.ID != "snap" || r.MaxMessages != 7 || r.CurrentVoice != VoiceM2 || r.CurrentLanguage != LanguageEs {
+		t.Errorf("restored session lost settings: %+v", r.Snapshot())
+	}
+	if len(r.Context) != 2 || r.LastUser != "hola" || r.LastAssistant != "buenas" {
+		t.Errorf("restored session lost history: %+v", r.Context)
+	}
+
+	r.AddMessage("user", "otra")
+	if len(s.Context) != 2 {
+		t.Error("restored session must not share history with the original")
+	}
+}
+
+func TestInMemorySessionStore_Versioning(t *testing.T) {
+	store := NewInMemorySessionStore()
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "x"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	v, err := store.Save(ctx, SessionSnapshot{ID: "x"})
+	if err != nil || v != 1 {
+		t.Fatalf("expected first save to yield version 1, got %d, %v", v, err)
+	}
+	if _, err := store.Save(ctx, SessionSnapshot{ID: "x"}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected a stale create to conflict, got %v", err)
+	}
+	if v, err := store.Save(ctx, SessionSnapshot{ID: "x", Version: 1}); err != nil || v != 2 {
+		t.Errorf("expected version 2, got %d, %v", v, err)
+	}
+
+	store.Delete(ctx, "x")
+	if _, err := store.Load(ctx, "x"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected session to be deleted, got %v", err)
+	}
+}
+
+func TestProcessAudioStateless_AcrossInstances(t *testing.T) {
+	store := NewInMemorySessionStore()
+	newOrch := func(reply string) *Orchestrator {
+		o := New(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: reply}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), nil)
+		o.SetSessionStore(store)
+		return o
+	}
+	a, b := newOrch("from a"), newOrch("from b")
+
+	if _, _, err := a.ProcessAudioStateless(context.Background(), "call-1", []byte{1}, false, nil); err != nil {
+		t.Fatalf("turn on instance a failed: %v", err)
+	}
+	if _, _, err := b.ProcessAudioStateless(context.Background(), "call-1", []byte{1}, false, nil); err != nil {
+		t.Fatalf("turn on instance b failed: %v", err)
+	}
+
+	snap, err := store.Load(context.Background(), "call-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Context) != 4 || snap.Version != 2 {
+		t.Fatalf("expected 4 messages at version 2, got %d at %d", len(snap.Context), snap.Version)
+	}
+	if snap.Context[1].Content != "from a" || snap.Context[3].Content != "from b" {
+		t.Errorf("unexpected history: %+v", snap.Context)
+	}
+	if snap.MaxMessages != DefaultConfig().MaxContextMessages {
+		t.Errorf("expected the configured message limit to be persisted, got %d", snap.MaxMessages)
+	}
+}
+
+// racingStore lets another writer slip in right before the first save.
+type racingStore struct {
+	*InMemorySessionStore
+	raced bool
+}
+
+func (r *racingStore) Save(ctx context.Context, snap SessionSnapshot) (int64, error) {
+	if !r.raced {
+		r.raced = true
+		other, _ := r.InMemorySessionStore.Load(ctx, snap.ID)
+		other.Context = append(other.Context, Message{Role: "user", Content: "concurrent"})
+		r.InMemorySessionStore.Save(ctx, other)
+	}
+	return r.InMemorySessionStore.Save(ctx, snap)
+}
+
+func TestProcessAudioStateless_ReappliesOnConflict(t *testing.T) {
+	store := &racingStore{InMemorySessionStore: NewInMemorySessionStore()}
+	store.InMemorySessionStore.Save(context.Background(), SessionSnapshot{ID: "call-2", MaxMessages: 10})
+
+	o := New(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: "hi"}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), nil)
+	o.SetSessionStore(store)
+
+	if _, _, err := o.ProcessAudioStateless(context.Background(), "call-2", []byte{1}, false, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	snap, _ := store.Load(context.Background(), "call-2")
+	if len(snap.Context) != 3 || snap.Context[0].Content != "concurrent" || snap.Context[2].Content != "hi" {
+		t.Errorf("expected the turn to be merged after the concurrent write, got %+v", snap.Context)
+	}
+}
+
+func TestProcessAudioStateless_StartOver(t *testing.T) {
+	store := NewInMemorySessionStore()
+	cfg := DefaultConfig()
+	cfg.HotCommands = DefaultHotCommands()
+	stt := &MockSTTProvider{transcribeResult: "where is my order"}
+	o := New(stt, &MockLLMProvider{completeResult: "It ships tomorrow."}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, cfg, nil)
+	o.SetSessionStore(store)
+	ctx := context.Background()
+
+	if _, _, err := o.ProcessAudioStateless(ctx, "call-3", []byte{1}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	stt.transcribeResult = "start over"
+	if _, _, err := o.ProcessAudioStateless(ctx, "call-3", []byte{1}, false, nil); err != nil {
+		t.Fatalf("start over: %v", err)
+	}
+	snap, err := store.Load(ctx, "call-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Context) != 0 || snap.Version != 2 {
+		t.Errorf("expected an empty context at version 2, got %+v at %d", snap.Context, snap.Version)
+	}
+}
+
+func TestProcessAudioStateless_NoStore(t *testing.T) {
+	o := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig(), nil)
+	if _, _, err := o.ProcessAudioStateless(context.Background(), "x", nil, false, nil); !errors.Is(err, ErrNoSessionStore) {
+		t.Errorf("expected ErrNoSessionStore, got %v", err)
+	}
+}
+
+func TestProcessAudioStateless_SummarizeTrim(t *testing.T) {
+	store := NewInMemorySessionStore()
+	cfg := DefaultConfig()
+	cfg.MaxContextMessages = 4
+	cfg.TrimStrategy = SummarizeTrim{LLM: &summaryLLM{}}
+	o := New(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: "hi"}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, cfg, nil)
+	o.SetSessionStore(store)
+
+	for i := 0; i < 10; i++ {
+		if _, _, err := o.ProcessAudioStateless(context.Background(), "call-5", []byte{1}, false, nil); err != nil {
+			t.Fatalf("turn %d: %v", i, err)
+		}
+	}
+	snap, err := store.Load(context.Background(), "call-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Context) > 4 {
+		t.Errorf("expected the saved context to be trimmed to 4 messages, got %d", len(snap.Context))
+	}
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import "time"
+
+// PipelineState is where a session is in the turn pipeline. (TurnState is
+// the LLM's turn-completion verdict; see layered_turn.go.)
+type PipelineState string
+
+const (
+	StateIdle         PipelineState = "idle"         // Waiting for the user
+	StateListening    PipelineState = "listening"    // The user is speaking
+	StateTranscribing PipelineState = "transcribing" // The user has stopped and is being transcribed
+	StateThinking     PipelineState = "thinking"     // Waiting for the LLM
+	StateSpeaking     PipelineState = "speaking"     // The reply is being synthesised and sent
+	StateInterrupted  PipelineState = "interrupted"  // The reply was cut off; lasts until the user is heard
+)
+
+// StateChange is one transition of a session's PipelineState.
+type StateChange struct {
+	From PipelineState `json:"from"`
+	To   PipelineState `json:"to"`
+	At   time.Time     `json:"at"`
+}
+
+// GetState returns the session's current PipelineState.
+func (s *ConversationSession) GetState() PipelineState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.state == "" {
+		return StateIdle
+	}
+	return s.state
+}
+
+// OnStateChange registers fn to be called on every change of the session's
+// PipelineState. It is called synchronously from the pipeline, so it must not
+// block.
+func (s *ConversationSession) OnStateChange(fn func(StateChange)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stateListeners = append(s.stateListeners, fn)
+}
+
+// setState moves the session to state to.
+func (s *ConversationSession) setState(to PipelineState) {
+	s.mu.Lock()
+	from := s.state
+	if from == "" {
+		from = StateIdle
+	}
+	if from == to {
+		s.mu.Unlock()
+		return
+	}
+	s.state = to
+	change := StateChange{From: from, To: to, At: time.Now()}
+	listeners := s.stateListeners
+	s.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(change)
+	}
+}
+
+func (ms *ManagedStream) setState(to PipelineState) {
+	if ms.session == nil {
+		return
+	}
+	ms.session.setState(to)
+}
+
+// settleState sets the state from what the stream is doing once a step of
+// the pipeline ends without handing on to the next. An interruption stands
+// until the user is heard.
+func (ms *ManagedStream) settleState() {
+	if ms.session == nil || ms.session.GetState() == StateInterrupted {
+		return
+	}
+	ms.mu.Lock()
+	to := StateIdle
+	switch {
+	case ms.isSpeaking:
+		to = StateSpeaking
+	case ms.isThinking:
+		to = StateThinking
+	case ms.vad != nil && ms.vad.IsSpeaking():
+		to = StateListening
+	}
+	ms.mu.Unlock()
+	ms.setState(to)
+}
@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProcessAudio_StateChanges(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "what time is it"}, &MockLLMProvider{completeResult: "It is noon."}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+	var states []PipelineState
+	session.OnStateChange(func(c StateChange) { states = append(states, c.To) })
+
+	if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, true, func([]byte) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	want := []PipelineState{StateTranscribing, StateThinking, StateSpeaking, StateIdle}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("got %v, want %v", states, want)
+	}
+}
+
+func TestManagedStream_StateChanges(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "what time is it"}, &MockLLMProvider{completeResult: "It is noon."}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, cfg)
+	session := NewConversationSession("u")
+	changes := make(chan PipelineState, 16)
+	session.OnStateChange(func(c StateChange) { changes <- c.To })
+	ms := o.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	ms.commitUtterance()
+	var states []PipelineState
+	timeout := time.After(2 * time.Second)
+	for len(states) == 0 || states[len(states)-1] != StateIdle {
+		select {
+		case state := <-changes:
+			states = append(states, state)
+		case <-timeout:
+			t.Fatalf("turn did not return to idle: %v", states)
+		}
+	}
+	want := []PipelineState{StateTranscribing, StateThinking, StateSpeaking, StateIdle}
+	if !reflect.DeepEqual(states, want) || session.GetState() != StateIdle {
+		t.Errorf("got %v, want %v", states, want)
+	}
+}
+
+func TestManagedStream_InterruptedUntilUserHeard(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg)
+	session := NewConversationSession("u")
+	ms := o.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+
+	ms.mu.Lock()
+	ms.isSpeaking = true
+	ms.mu.Unlock()
+	ms.Interrupt()
+	ms.settleState()
+	if got := session.GetState(); got != StateInterrupted {
+		t.Fatalf("expected the interruption to stand, got %s", got)
+	}
+	ms.startUtterance()
+	if got := session.GetState(); got != StateListening {
+		t.Errorf("expected listening once the user is heard, got %s", got)
+	}
+}
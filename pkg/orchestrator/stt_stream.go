@@ -0,0 +1,280 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// transcriptionSettle is how long CloseAndFinalize waits for a provider
+// that doesn't call ReportTranscriptionDone to go quiet after the end of the
+// audio.
+var transcriptionSettle = 1500 * time.Millisecond
+
+type transcriptionDoneKey struct{}
+
+// ReportTranscriptionDone tells the TranscriptionStream a StreamTranscribe
+// was started for that the provider has delivered its last transcript, so
+// CloseAndFinalize can return without waiting for it to go quiet. Streaming
+// providers call it once their stream has ended, typically when they stop
+// receiving results. Calls outside a TranscriptionStream are ignored.
+func ReportTranscriptionDone(ctx context.Context) {
+	if done, ok := ctx.Value(transcriptionDoneKey{}).(func()); ok {
+		done()
+	}
+}
+
+// readChunkSize is 100ms of 16kHz 16-bit mono audio.
+const readChunkSize = 3200
+
+// TranscriptionStream transcribes audio while it is still being captured.
+// With a StreamingSTTProvider chunks go to the provider as they are written;
+// a batch provider receives the buffered audio once CloseAndFinalize is called.
+type TranscriptionStream struct {
+	o         *Orchestrator
+	ctx       context.Context
+	cancel    context.CancelFunc
+	lang      Language
+	in        chan<- []byte // nil for batch providers
+	onPartial func(string)
+	done      chan struct{} // Closed by ReportTranscriptionDone
+
+	sendMu sync.Mutex // Held while sending on in, and to close it
+
+	mu      sync.Mutex
+	audio   []byte
+	finals  []string
+	partial string
+	closed  bool
+	update  chan struct{}
+}
+
+// StartTranscription opens a TranscriptionStream in lang. onPartial, when
+// non-nil, receives interim transcripts from streaming providers.
+func (o *Orchestrator) StartTranscription(ctx context.Context, lang Language, onPartial func(string)) (*TranscriptionStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	ts := &TranscriptionStream{
+		o:         o,
+		ctx:       ctx,
+		cancel:    cancel,
+		lang:      lang,
+		onPartial: onPartial,
+		done:      make(chan struct{}),
+		update:    make(chan struct{}, 1),
+	}
+	provider, ok := o.stt.(StreamingSTTProvider)
+	if !ok {
+		return ts, nil
+	}
+	var once sync.Once
+	ctx = context.WithValue(o.withCooldowns(ctx), transcriptionDoneKey{}, func() { once.Do(func() { close(ts.done) }) })
+	in, err := provider.StreamTranscribe(ctx, lang, ts.onTranscript)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	ts.in = in
+	return ts, nil
+}
+
+func (ts *TranscriptionStream) onTranscript(transcript string, isFinal bool) error {
+	ts.mu.Lock()
+	if isFinal {
+		if t := strings.TrimSpace(transcript); t != "" {
+			ts.finals = append(ts.finals, t)
+		}
+		ts.partial = ""
+	} else {
+		ts.partial = transcript
+	}
+	ts.mu.Unlock()
+
+	select {
+	case ts.update <- struct{}{}:
+	default:
+	}
+	if !isFinal && ts.onPartial != nil {
+		ts.onPartial(transcript)
+	}
+	return nil
+}
+
+// WriteChunk feeds the next piece of 16-bit PCM audio.
+func (ts *TranscriptionStream) WriteChunk(chunk []byte) error {
+	// Holding sendMu through the send keeps CloseAndFinalize from closing
+	// in under it.
+	ts.sendMu.Lock()
+	defer ts.sendMu.Unlock()
+	ts.mu.Lock()
+	if ts.closed {
+		ts.mu.Unlock()
+		return ErrTranscriptionClosed
+	}
+	ts.audio = append(ts.audio, chunk...)
+	ts.mu.Unlock()
+
+	if ts.in == nil {
+		return nil
+	}
+	select {
+	case ts.in <- append([]byte(nil), chunk...):
+		return nil
+	case <-ts.ctx.Done():
+		return ts.ctx.Err()
+	}
+}
+
+// Audio returns everything written so far.
+func (ts *TranscriptionStream) Audio() []byte {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return append([]byte(nil), ts.audio...)
+}
+
+// CloseAndFinalize ends the audio and returns the complete transcript. For
+// streaming providers it waits for the provider to report the end of its
+// stream with ReportTranscriptionDone, or for it to go quiet, so finals
+// trailing the end of the audio are kept; the last interim transcript is
+// used if no final arrived at all.
+func (ts *TranscriptionStream) CloseAndFinalize() (TranscriptionResult, error) {
+	ts.sendMu.Lock()
+	ts.mu.Lock()
+	if ts.closed {
+		ts.mu.Unlock()
+		ts.sendMu.Unlock()
+		return TranscriptionResult{}, ErrTranscriptionClosed
+	}
+	ts.closed = true
+	audio := ts.audio
+	ts.mu.Unlock()
+	if ts.in != nil {
+		close(ts.in)
+	}
+	ts.sendMu.Unlock()
+	defer ts.cancel()
+
+	if ts.in == nil {
+		return ts.o.Transcribe(ts.ctx, audio, ts.lang)
+	}
+
+	var deadline <-chan time.Time
+	if d := ts.o.GetConfig().STTTimeout; d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		deadline = t.C
+	}
+	settle := time.NewTimer(transcriptionSettle)
+	defer settle.Stop()
+	for {
+		select {
+		case <-ts.done:
+			return ts.result(), nil
+		case <-ts.update:
+			settle.Reset(transcriptionSettle)
+		case <-settle.C:
+			return ts.result(), nil
+		case <-deadline:
+			return ts.result(), nil
+		case <-ts.ctx.Done():
+			return ts.result(), ts.ctx.Err()
+		}
+	}
+}
+
+func (ts *TranscriptionStream) result() TranscriptionResult {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	text := strings.Join(ts.finals, " ")
+	if text == "" {
+		text = ts.partial
+	}
+	return TranscriptionResult{Text: text}
+}
+
+// ProcessAudioChunks runs a turn whose audio arrives on chunks, so
+// transcription starts while the user is still speaking. The turn is
+// answered once chunks is closed.
+func (o *Orchestrator) ProcessAudioChunks(ctx context.Context, session *ConversationSession, chunks <-chan []byte, streaming bool, onAudioChunk func([]byte) error) (string, []byte, error) {
+	return o.runTurn(ctx, session, nil, streaming, onAudioChunk, func(ctx context.Context) (TranscriptionResult, []byte, error) {
+		ts, err := o.StartTranscription(ctx, session.GetCurrentLanguage(), session.partialTranscript)
+		if err != nil {
+			return TranscriptionResult{}, nil, err
+		}
+		for {
+			select {
+			case chunk, ok := <-chunks:
+				if !ok {
+					if ctx.Err() != nil {
+						ts.cancel()
+						return TranscriptionResult{}, ts.Audio(), ctx.Err()
+					}
+					res, err := ts.CloseAndFinalize()
+					return res, ts.Audio(), err
+				}
+				if err := ts.WriteChunk(chunk); err != nil {
+					ts.cancel()
+					return TranscriptionResult{}, ts.Audio(), err
+				}
+			case <-ctx.Done():
+				ts.cancel()
+				return TranscriptionResult{}, ts.Audio(), ctx.Err()
+			}
+		}
+	})
+}
+
+// ProcessAudioReader is ProcessAudioChunks for audio read from r until EOF.
+func (o *Orchestrator) ProcessAudioReader(ctx context.Context, session *ConversationSession, r io.Reader, streaming bool, onAudioChunk func([]byte) error) (string, []byte, error) {
+	chunks := make(chan []byte)
+	readErr := make(chan error, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		defer close(chunks)
+		buf := make([]byte, readChunkSize)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				select {
+				case chunks <- append([]byte(nil), buf[:n]...):
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr <- err
+					cancel()
+				}
+				return
+			}
+		}
+	}()
+
+	transcript, audio, err := o.ProcessAudioChunks(ctx, session, chunks, streaming, onAudioChunk)
+	select {
+	case rerr := <-readErr:
+		return transcript, audio, rerr
+	default:
+	}
+	return transcript, audio, err
+}
+
+// partialTranscript hands an interim transcript to the session's
+// OnPartialTranscript, if set.
+func (s *ConversationSession) partialTranscript(text string) {
+	s.mu.RLock()
+	fn := s.OnPartialTranscript
+	s.mu.RUnlock()
+	if fn != nil && strings.TrimSpace(text) != "" {
+		fn(text)
+	}
+}
+
+func (ms *ManagedStream) emitPartial(transcript string) {
+	ms.emit(TranscriptPartial, transcript)
+	ms.session.partialTranscript(transcript)
+}
@@ -0,0 +1,267 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProcessAudioChunks_TranscribesWhileSpeaking(t *testing.T) {
+	inner := &growingSTT{steady: true}
+	stt := NewChunkedStreamingSTT(inner, ChunkedSTTConfig{
+		SampleRate: 1000,
+		Step:       10 * time.Millisecond,
+		MinAudio:   50 * time.Millisecond,
+	})
+	o := NewWithVAD(stt, &MockLLMProvider{completeResult: "sure"}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+
+	chunks := make(chan []byte)
+	go func() {
+		for i := 0; i < 4; i++ {
+			chunks <- make([]byte, 200)
+			time.Sleep(40 * time.Millisecond)
+		}
+		inner.mu.Lock()
+		calls := inner.calls
+		inner.mu.Unlock()
+		if calls == 0 {
+			t.Error("expected transcription to start before the audio ended")
+		}
+		close(chunks)
+	}()
+
+	transcript, audio, err := o.ProcessAudioChunks(context.Background(), session, chunks, false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transcript != "please book me a" {
+		t.Errorf("expected final transcript of all chunks, got %q", transcript)
+	}
+	if !bytes.Equal(audio, []byte{1, 2}) {
+		t.Errorf("unexpected audio %v", audio)
+	}
+	if session.LastUser != "please book me a" || session.LastAssistant != "sure" {
+		t.Errorf("turn not recorded in session: %q / %q", session.LastUser, session.LastAssistant)
+	}
+}
+
+func TestProcessAudioReader_BatchProvider(t *testing.T) {
+	var mu sync.Mutex
+	var heard int
+	stt := &lengthSTT{onCall: func(n int) { mu.Lock(); heard = n; mu.Unlock() }}
+	o := NewWithVAD(stt, &MockLLMProvider{completeResult: "ok"}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+
+	r := bytes.NewReader(make([]byte, readChunkSize*2+100))
+	transcript, _, err := o.ProcessAudioReader(context.Background(), session, r, false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transcript != "hello there" {
+		t.Errorf("unexpected transcript %q", transcript)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if heard != readChunkSize*2+100 {
+		t.Errorf("expected the batch provider to get all %d bytes, got %d", readChunkSize*2+100, heard)
+	}
+}
+
+type lengthSTT struct{ onCall func(n int) }
+
+func (l *lengthSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	l.onCall(len(audio))
+	return TranscriptionResult{Text: "hello there"}, nil
+}
+
+func (l *lengthSTT) Name() string { return "length" }
+
+type failingReader struct{ err error }
+
+func (f failingReader) Read(p []byte) (int, error) { return 0, f.err }
+
+func TestProcessAudioReader_ReadError(t *testing.T) {
+	llm := &MockLLMProvider{completeResult: "ok"}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "hello there"}, llm, &MockTTSProvider{}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+
+	boom := errors.New("mic unplugged")
+	_, _, err := o.ProcessAudioReader(context.Background(), session, io.MultiReader(bytes.NewReader([]byte{1, 2}), failingReader{boom}), false, nil)
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected read error, got %v", err)
+	}
+	if len(session.GetContextCopy()) != 0 {
+		t.Error("a failed read must not produce a turn")
+	}
+}
+
+func TestTranscriptionStream_FallsBackToPartial(t *testing.T) {
+	old := transcriptionSettle
+	transcriptionSettle = 50 * time.Millisecond
+	defer func() { transcriptionSettle = old }()
+
+	stt := &MockStreamingSTT{steps: []struct {
+		text    string
+		isFinal bool
+		delay   time.Duration
+	}{
+		{text: "book a", delay: 5 * time.Millisecond},
+		{text: "book a table", delay: 5 * time.Millisecond},
+	}}
+	o := NewWithVAD(stt, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig())
+
+	var partials []string
+	var mu sync.Mutex
+	ts, err := o.StartTranscription(context.Background(), LanguageEn, func(p string) {
+		mu.Lock()
+		partials = append(partials, p)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	res, err := ts.CloseAndFinalize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Text != "book a table" {
+		t.Errorf("expected last partial without a final, got %q", res.Text)
+	}
+	mu.Lock()
+	if len(partials) != 2 {
+		t.Errorf("expected 2 partials, got %v", partials)
+	}
+	mu.Unlock()
+
+	if err := ts.WriteChunk([]byte{1}); !errors.Is(err, ErrTranscriptionClosed) {
+		t.Errorf("expected ErrTranscriptionClosed after finalize, got %v", err)
+	}
+}
+
+func TestProcessAudioChunks_PartialTranscriptCallback(t *testing.T) {
+	old := transcriptionSettle
+	transcriptionSettle = 50 * time.Millisecond
+	defer func() { transcriptionSettle = old }()
+
+	stt := &MockStreamingSTT{steps: []struct {
+		text    string
+		isFinal bool
+		delay   time.Duration
+	}{
+		{text: "book a", delay: 10 * time.Millisecond},
+		{text: "book a table", delay: 10 * time.Millisecond},
+		{text: "book a table for two", isFinal: true, delay: 10 * time.Millisecond},
+	}}
+	o := NewWithVAD(stt, &MockLLMProvider{completeResult: "sure"}, &MockTTSProvider{}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+	var mu sync.Mutex
+	var partials []string
+	session.OnPartialTranscript = func(text string) {
+		mu.Lock()
+		partials = append(partials, text)
+		mu.Unlock()
+	}
+
+	chunks := make(chan []byte)
+	go func() {
+		chunks <- make([]byte, 320)
+		time.Sleep(50 * time.Millisecond)
+		close(chunks)
+	}()
+	if _, _, err := o.ProcessAudioChunks(context.Background(), session, chunks, false, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(partials) != 2 || partials[1] != "book a table" {
+		t.Errorf("expected both interim transcripts, got %q", partials)
+	}
+}
+
+// drainingSTT reports finals only once its input is closed, one per phrase
+// with a gap between them, then reports the end of the stream.
+type drainingSTT struct{ finals []string }
+
+func (d *drainingSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	return TranscriptionResult{}, nil
+}
+
+func (d *drainingSTT) Name() string { return "draining" }
+
+func (d *drainingSTT) StreamTranscribe(ctx context.Context, lang Language, onTranscript func(string, bool) error) (chan<- []byte, error) {
+	in := make(chan []byte)
+	go func() {
+		defer ReportTranscriptionDone(ctx)
+		for range in {
+		}
+		for _, f := range d.finals {
+			time.Sleep(20 * time.Millisecond)
+			if onTranscript(f, true) != nil {
+				return
+			}
+		}
+	}()
+	return in, nil
+}
+
+func TestTranscriptionStream_KeepsTrailingFinals(t *testing.T) {
+	old := transcriptionSettle
+	transcriptionSettle = time.Hour
+	defer func() { transcriptionSettle = old }()
+
+	o := NewWithVAD(&drainingSTT{finals: []string{"book a table", "for two", "at eight"}}, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig())
+	ts, err := o.StartTranscription(context.Background(), LanguageEn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.WriteChunk(make([]byte, 320)); err != nil {
+		t.Fatal(err)
+	}
+	res, err := ts.CloseAndFinalize()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Text != "book a table for two at eight" {
+		t.Errorf("expected every final before the end of the stream, got %q", res.Text)
+	}
+}
+
+func TestTranscriptionStream_WriteRacingClose(t *testing.T) {
+	old := transcriptionSettle
+	transcriptionSettle = 10 * time.Millisecond
+	defer func() { transcriptionSettle = old }()
+
+	for i := 0; i < 50; i++ {
+		o := NewWithVAD(&drainingSTT{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig())
+		ts, err := o.StartTranscription(context.Background(), LanguageEn, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					if err := ts.WriteChunk([]byte{1, 2}); err != nil {
+						if !errors.Is(err, ErrTranscriptionClosed) {
+							t.Errorf("unexpected error: %v", err)
+						}
+						return
+					}
+				}
+			}()
+		}
+		time.Sleep(time.Millisecond)
+		if _, err := ts.CloseAndFinalize(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SupervisorName is the Message.Name used for whispered guidance.
+const SupervisorName = "supervisor"
+
+// Observer is a listen-in subscription to a live ManagedStream. It receives a
+// copy of every event the stream emits plus UserAudio events carrying the
+// caller's microphone audio. A slow observer drops events; it never stalls
+// the conversation.
+type Observer struct {
+	ms     *ManagedStream
+	events chan OrchestratorEvent
+	once   sync.Once
+}
+
+func (o *Observer) Events() <-chan OrchestratorEvent {
+	return o.events
+}
+
+// Close unsubscribes the observer and closes its event channel.
+func (o *Observer) Close() {
+	o.ms.removeObserver(o)
+}
+
+// Observe subscribes a supervisor to the stream. buffer is the number of
+// events that may queue before new ones are dropped.
+func (ms *ManagedStream) Observe(buffer int) *Observer {
+	if buffer <= 0 {
+		buffer = 256
+	}
+	obs := &Observer{ms: ms, events: make(chan OrchestratorEvent, buffer)}
+
+	ms.obsMu.Lock()
+	defer ms.obsMu.Unlock()
+	if ms.observersClosed {
+		close(obs.events)
+		return obs
+	}
+	if ms.observers == nil {
+		ms.observers = make(map[*Observer]struct{})
+	}
+	ms.observers[obs] = struct{}{}
+	return obs
+}
+
+func (ms *ManagedStream) removeObserver(obs *Observer) {
+	ms.obsMu.Lock()
+	defer ms.obsMu.Unlock()
+	if _, ok := ms.observers[obs]; ok {
+		delete(ms.observers, obs)
+		obs.once.Do(func() { close(obs.events) })
+	}
+}
+
+func (ms *ManagedStream) closeObservers() {
+	ms.obsMu.Lock()
+	defer ms.obsMu.Unlock()
+	ms.observersClosed = true
+	for obs := range ms.observers {
+		obs.once.Do(func() { close(obs.events) })
+	}
+	ms.observers = nil
+}
+
+func (ms *ManagedStream) notifyObservers(event OrchestratorEvent) {
+	ms.obsMu.Lock()
+	defer ms.obsMu.Unlock()
+	for obs := range ms.observers {
+		select {
+		case obs.events <- event:
+		default:
+		}
+	}
+}
+
+func (ms *ManagedStream) hasObservers() bool {
+	ms.obsMu.Lock()
+	defer ms.obsMu.Unlock()
+	return len(ms.observers) > 0
+}
+
+// Whisper injects supervisor guidance into the LLM context. It is never
+// spoken or shown to the caller; it only shapes the bot's next responses.
+// Observers receive a SupervisorWhisper event so other supervisors see it.
+func (ms *ManagedStream) Whisper(guidance string) error {
+	guidance = strings.TrimSpace(guidance)
+	if guidance == "" {
+		return fmt.Errorf("whisper guidance is empty")
+	}
+	ms.session.AddMessageRaw(Message{
+		Role:    "system",
+		Name:    SupervisorName,
+		Content: "Guidance from a human supervisor (do not read aloud or mention it to the user): " + guidance,
+	})
+
+	ms.mu.Lock()
+	gen := ms.payloadGen
+	sessionID := ms.session.ID
+	ms.mu.Unlock()
+	ms.notifyObservers(OrchestratorEvent{Type: SupervisorWhisper, SessionID: sessionID, Data: guidance, Generation: gen})
+	return nil
+}
@@ -0,0 +1,92 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSupervisor_ListenInAndWhisper(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.1, 100*time.Millisecond), cfg)
+	session := NewConversationSession("call")
+
+	stream := orch.NewManagedStream(context.Background(), session)
+	defer stream.Close()
+
+	obs := stream.Observe(64)
+
+	loud := make([]byte, 100)
+	for i := 0; i < len(loud); i += 2 {
+		loud[i], loud[i+1] = 0xFF, 0x7F
+	}
+	for i := 0; i < 20; i++ {
+		stream.Write(loud)
+	}
+
+	var sawAudio, sawSpeaking bool
+	timeout := time.After(2 * time.Second)
+	for !sawAudio || !sawSpeaking {
+		select {
+		case ev := <-obs.Events():
+			sawAudio = sawAudio || ev.Type == UserAudio
+			sawSpeaking = sawSpeaking || ev.Type == UserSpeaking
+		case <-timeout:
+			t.Fatalf("observer missed events: audio=%v speaking=%v", sawAudio, sawSpeaking)
+		}
+	}
+
+	if err := stream.Whisper("offer the premium plan"); err != nil {
+		t.Fatal(err)
+	}
+	msgs := session.GetContextCopy()
+	last := msgs[len(msgs)-1]
+	if last.Role != "system" || last.Name != SupervisorName {
+		t.Errorf("expected a supervisor system message, got %+v", last)
+	}
+	if session.LastAssistant != "" {
+		t.Error("whisper must not be treated as something the bot said")
+	}
+
+	for {
+		select {
+		case ev := <-obs.Events():
+			if ev.Type != SupervisorWhisper {
+				continue
+			}
+			if ev.Data != "offer the premium plan" {
+				t.Errorf("unexpected whisper payload: %v", ev.Data)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("observer did not receive the whisper")
+		}
+		break
+	}
+
+	if err := stream.Whisper("   "); err == nil {
+		t.Error("expected empty whisper to be rejected")
+	}
+}
+
+func TestSupervisor_CloseEndsObservation(t *testing.T) {
+	orch := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.1, 100*time.Millisecond), DefaultConfig())
+	stream := orch.NewManagedStream(context.Background(), NewConversationSession("call"))
+
+	a := stream.Observe(1)
+	b := stream.Observe(1)
+	a.Close()
+	a.Close()
+
+	stream.Close()
+
+	for _, obs := range []*Observer{a, b} {
+		for range obs.Events() {
+		}
+	}
+	if late := stream.Observe(1); late != nil {
+		if _, ok := <-late.Events(); ok {
+			t.Error("observing a closed stream should yield a closed channel")
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"math"
+	"time"
+)
+
+// TailBehavior decides how the bot's voice ends when it is interrupted.
+// Cutting it off mid-phoneme leaves a click, and sounds jarring on
+// telephony; a tail lets it trail off instead.
+type TailBehavior string
+
+const (
+	// TailHardStop stops the bot's audio where it is. It is the default.
+	TailHardStop TailBehavior = "hard_stop"
+	// TailFade fades out the audio that was playing over Config.TailFade.
+	TailFade TailBehavior = "fade"
+	// TailWordBoundary lets the word that was playing finish, up to
+	// maxWordTail; a word still going by then fades out over
+	// Config.TailFade.
+	TailWordBoundary TailBehavior = "word_boundary"
+)
+
+const (
+	defaultTailFade = 40 * time.Millisecond
+	// maxWordTail bounds how long a word is let run on after an
+	// interruption; a word that has not ended by then fades out.
+	maxWordTail = 400 * time.Millisecond
+	// A 10ms frame this much quieter than the loudest in the tail is taken
+	// for the gap after a word.
+	wordGapRatio = 0.1
+)
+
+// recordOutputLocked keeps sent bot audio until it has played, so an
+// interruption can find the audio playing at that moment.
+func (ms *ManagedStream) recordOutputLocked(at time.Time, chunk []byte) {
+	if ms.tail == "" || ms.tail == TailHardStop {
+		return
+	}
+	// Drop what has finished playing.
+	now := time.Now()
+	n := 0
+	for n < len(ms.unplayed) && ms.unplayed[n].at.Add(bytesToDuration(int64(len(ms.unplayed[n].chunk)), ms.playbackRate)).Before(now) {
+		n++
+	}
+	ms.unplayed = append(ms.unplayed[n:], scheduledOutput{at: at, chunk: chunk})
+}
+
+// interruptionTail returns the audio to close an interrupted reply with:
+// what of unplayed was due to play from now on, cut at the configured tail
+// and faded out.
+func (ms *ManagedStream) interruptionTail(unplayed []scheduledOutput, rate int, now time.Time) []byte {
+	if len(unplayed) == 0 || rate <= 0 || ms.orch == nil {
+		return nil
+	}
+	fade := ms.orch.GetConfig().TailFade
+	if fade <= 0 {
+		fade = defaultTailFade
+	}
+	limit := fade
+	if ms.tail == TailWordBoundary {
+		limit = maxWordTail
+	}
+
+	want := durationToBytes(limit, rate)
+	var tail []byte
+	for _, out := range unplayed {
+		chunk := out.chunk
+		if out.at.Before(now) {
+			chunk = chunk[min(durationToBytes(now.Sub(out.at), rate), int64(len(chunk))):]
+		}
+		tail = append(tail, chunk[:min(int64(len(chunk)), want-int64(len(tail)))]...)
+		if int64(len(tail)) >= want {
+			break
+		}
+	}
+	if ms.tail == TailWordBoundary {
+		if end := wordEnd(tail, rate); end < len(tail) {
+			// The word ended on its own; only smooth the cut in the gap.
+			return fadeOut(tail[:end], durationToBytes(5*time.Millisecond, rate))
+		}
+	}
+	return fadeOut(tail, durationToBytes(fade, rate))
+}
+
+// wordEnd returns the offset in pcm of the first quiet gap, where the word
+// playing at its start has ended, or len(pcm) if there is none.
+func wordEnd(pcm []byte, sampleRate int) int {
+	frame := int(durationToBytes(10*time.Millisecond, sampleRate))
+	if frame <= 0 {
+		return len(pcm)
+	}
+	var levels []float64
+	peak := 0.0
+	for i := 0; i+frame <= len(pcm); i += frame {
+		rms := chunkRMS(pcm[i : i+frame])
+		levels = append(levels, rms)
+		peak = max(peak, rms)
+	}
+	for i, rms := range levels {
+		if rms <= peak*wordGapRatio {
+			return i * frame
+		}
+	}
+	return len(pcm)
+}
+
+// fadeOut ramps the last n bytes of 16-bit PCM down to silence, ending on
+// a zero sample so playback stops without a click.
+func fadeOut(pcm []byte, n int64) []byte {
+	pcm = append([]byte(nil), pcm[:len(pcm)&^1]...)
+	n = min(n, int64(len(pcm)))
+	start := int64(len(pcm)) - n
+	samples := n / 2
+	for i := int64(0); i < samples; i++ {
+		gain := 1 - float64(i+1)/float64(samples)
+		at := start + 2*i
+		s := float64(int16(uint16(pcm[at]) | uint16(pcm[at+1])<<8))
+		v := int16(math.Round(s * gain))
+		pcm[at], pcm[at+1] = byte(v), byte(uint16(v)>>8)
+	}
+	return pcm
+}
+
+// sendTail sends the tail of an interrupted reply after the Interrupted
+// event, so it plays once the client has dropped the rest.
+func (ms *ManagedStream) sendTail(tail []byte, gen int) {
+	if len(tail) == 0 {
+		return
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.isClosed {
+		return
+	}
+	ms.playbackEnd = time.Now()
+	ms.sendAudioLocked(tail, gen)
+}
@@ -0,0 +1,102 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+)
+
+// tone returns d of 16-bit PCM at rate held at level.
+func tone(d time.Duration, rate int, level int16) []byte {
+	pcm := make([]byte, durationToBytes(d, rate))
+	for i := 0; i < len(pcm); i += 2 {
+		pcm[i], pcm[i+1] = byte(level), byte(uint16(level)>>8)
+	}
+	return pcm
+}
+
+func lastSample(pcm []byte) int16 {
+	return int16(uint16(pcm[len(pcm)-2]) | uint16(pcm[len(pcm)-1])<<8)
+}
+
+// newTailStream returns a stream whose bot is talking, with a second of
+// audio sent that the client has not played yet.
+func newTailStream(t *testing.T, behavior TailBehavior) *ManagedStream {
+	t.Helper()
+	tts := newTalkingTTS(tone(time.Second, 16000, 8000))
+	ms := newTestStream(t, testProviders{tts: tts}, func(cfg *Config) {
+		cfg.TailBehavior = behavior
+		cfg.TailFade = 20 * time.Millisecond
+	})
+	ms.SetEchoSampleRates(16000, 16000)
+	startTalking(t, ms, tts, "Let me look that up for you.")
+	return ms
+}
+
+// audioAfterInterrupt interrupts the stream and returns the audio it sent
+// after the Interrupted event.
+func audioAfterInterrupt(t *testing.T, ms *ManagedStream) []byte {
+	t.Helper()
+	time.Sleep(50 * time.Millisecond)
+	ms.internalInterrupt()
+	var audio []byte
+	interrupted := false
+	deadline := time.After(100 * time.Millisecond)
+	for {
+		select {
+		case ev := <-ms.Events():
+			switch {
+			case ev.Type == Interrupted:
+				interrupted = true
+			case ev.Type == AudioChunk && interrupted:
+				audio = append(audio, ev.Data.([]byte)...)
+			}
+		case <-deadline:
+			if !interrupted {
+				t.Fatal("no Interrupted event")
+			}
+			return audio
+		}
+	}
+}
+
+func TestManagedStream_TailFade(t *testing.T) {
+	tail := audioAfterInterrupt(t, newTailStream(t, TailFade))
+	if len(tail) != int(durationToBytes(20*time.Millisecond, 16000)) {
+		t.Fatalf("expected a 20ms tail, got %d bytes", len(tail))
+	}
+	if first := int16(uint16(tail[0]) | uint16(tail[1])<<8); first < 7000 {
+		t.Errorf("the tail should start at the level playing, got %d", first)
+	}
+	if last := lastSample(tail); last != 0 {
+		t.Errorf("the tail should fade to silence, ends at %d", last)
+	}
+}
+
+func TestManagedStream_TailHardStop(t *testing.T) {
+	if tail := audioAfterInterrupt(t, newTailStream(t, "")); len(tail) != 0 {
+		t.Errorf("a hard stop should send no tail, got %d bytes", len(tail))
+	}
+}
+
+func TestInterruptionTail_WordBoundary(t *testing.T) {
+	ms := newTestStream(t, testProviders{}, func(cfg *Config) { cfg.TailBehavior = TailWordBoundary })
+	now := time.Now()
+	word := append(tone(70*time.Millisecond, 16000, 8000), tone(100*time.Millisecond, 16000, 0)...)
+	unplayed := []scheduledOutput{
+		{at: now.Add(-50 * time.Millisecond), chunk: word[:len(word)/2]},
+		{at: now.Add(35 * time.Millisecond), chunk: word[len(word)/2:]},
+	}
+	tail := ms.interruptionTail(unplayed, 16000, now)
+	if want := durationToBytes(20*time.Millisecond, 16000); int64(len(tail)) != want {
+		t.Errorf("the tail should run to the end of the word, 20ms on; got %d bytes", len(tail))
+	}
+	if lastSample(tail) != 0 {
+		t.Error("the tail should end on silence")
+	}
+
+	// A word that runs past the limit is faded out there.
+	unplayed = []scheduledOutput{{at: now, chunk: tone(time.Second, 16000, 8000)}}
+	if tail := ms.interruptionTail(unplayed, 16000, now); int64(len(tail)) != durationToBytes(maxWordTail, 16000) || lastSample(tail) != 0 {
+		t.Errorf("got a %d-byte tail ending at %d", len(tail), lastSample(tail))
+	}
+}
@@ -0,0 +1,367 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Persona is the bot identity a tenant presents to its callers.
+type Persona struct {
+	Name         string   `json:"name"`
+	SystemPrompt string   `json:"system_prompt"`
+	Voice        Voice    `json:"voice,omitempty"`
+	Language     Language `json:"language,omitempty"`
+	AllowedTools []string `json:"allowed_tools,omitempty"` // Empty means every registered tool
+	Greeting     string   `json:"greeting,omitempty"`
+	Closing      string   `json:"closing,omitempty"`
+}
+
+// TenantQuota limits what one tenant can consume. Zero values mean unlimited.
+type TenantQuota struct {
+	MaxConcurrentSessions        int
+	MaxTurnsPerMinute            int
+	MaxTurnsPerMinutePerIdentity int // Per authenticated caller, by Identity.Subject
+}
+
+// Tenant is one customer of a shared deployment. Each tenant brings its own
+// Orchestrator, so providers (and their API keys), config and data stores
+// are never shared between tenants.
+type Tenant struct {
+	ID           string
+	Orchestrator *Orchestrator
+	Persona      Persona
+	Quota        TenantQuota
+	APIKeys      []string // Keys the tenant's clients authenticate with; stored hashed
+	Scopes       []string // Granted to clients authenticating with an API key
+}
+
+type tenantEntry struct {
+	tenant    Tenant
+	keyHashes [][32]byte
+	usage     *tenantUsage // Shared by every registration of the tenant
+}
+
+// tenantUsage counts what a tenant is consuming. It outlives re-registration,
+// so slots acquired before a tenant was updated are released to the same
+// counter.
+type tenantUsage struct {
+	mu          sync.Mutex
+	active      int
+	windowStart time.Time
+	windowTurns int
+	callers     map[string]*turnWindow // Per-identity turn counts
+}
+
+type turnWindow struct {
+	start time.Time
+	turns int
+}
+
+// TenantRegistry resolves tenants by ID or client API key and enforces quotas.
+type TenantRegistry struct {
+	mu      sync.RWMutex
+	tenants map[string]*tenantEntry
+}
+
+func NewTenantRegistry() *TenantRegistry {
+	return &TenantRegistry{tenants: make(map[string]*tenantEntry)}
+}
+
+// Register adds or replaces a tenant. Usage counters survive replacement.
+func (r *TenantRegistry) Register(t Tenant) error {
+	if t.ID == "" || strings.Contains(t.ID, "/") {
+		return fmt.Errorf("invalid tenant ID %q", t.ID)
+	}
+	if t.Orchestrator == nil {
+		return fmt.Errorf("tenant %s: %w", t.ID, ErrNilProvider)
+	}
+	entry := &tenantEntry{tenant: t, usage: &tenantUsage{}}
+	for _, k := range t.APIKeys {
+		entry.keyHashes = append(entry.keyHashes, sha256.Sum256([]byte(k)))
+	}
+	entry.tenant.APIKeys = nil
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.tenants[t.ID]; ok {
+		entry.usage = old.usage
+	}
+	r.tenants[t.ID] = entry
+	return nil
+}
+
+func (r *TenantRegistry) Remove(tenantID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tenants, tenantID)
+}
+
+func (r *TenantRegistry) entry(tenantID string) (*tenantEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.tenants[tenantID]
+	if !ok {
+		return nil, ErrTenantNotFound
+	}
+	return e, nil
+}
+
+func (r *TenantRegistry) Get(tenantID string) (Tenant, error) {
+	e, err := r.entry(tenantID)
+	if err != nil {
+		return Tenant{}, err
+	}
+	return e.tenant, nil
+}
+
+// Authenticate maps a client API key to its tenant.
+func (r *TenantRegistry) Authenticate(apiKey string) (Tenant, error) {
+	h := sha256.Sum256([]byte(apiKey))
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.tenants {
+		for _, kh := range e.keyHashes {
+			if subtle.ConstantTimeCompare(h[:], kh[:]) == 1 {
+				return e.tenant, nil
+			}
+		}
+	}
+	return Tenant{}, ErrTenantNotFound
+}
+
+// ForSession returns the tenant owning a session.
+func (r *TenantRegistry) ForSession(session *ConversationSession) (Tenant, error) {
+	return r.Get(session.GetTenantID())
+}
+
+// NewSession creates a session for userID tagged with the tenant and set up
+// with the tenant's persona.
+func (r *TenantRegistry) NewSession(tenantID, userID string) (*ConversationSession, error) {
+	e, err := r.entry(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	t := e.tenant
+	session := t.Orchestrator.NewSessionWithDefaults(userID)
+	session.TenantID = tenantID
+	ApplyPersona(t.Orchestrator, session, t.Persona)
+	return session, nil
+}
+
+// NewSessionFor creates a session for an authenticated caller in their
+// tenant, as NewSession does, and attaches their identity. userID defaults
+// to the identity's subject.
+func (r *TenantRegistry) NewSessionFor(id Identity, userID string) (*ConversationSession, error) {
+	if userID == "" {
+		userID = id.Subject
+	}
+	session, err := r.NewSession(id.TenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	session.SetIdentity(id)
+	return session, nil
+}
+
+// Authorize reports whether an authenticated caller may use an existing
+// session, e.g. one they reconnect to: it must belong to their tenant and,
+// if it was created for an identity, to them.
+func (r *TenantRegistry) Authorize(id Identity, session *ConversationSession) error {
+	if session.GetTenantID() != id.TenantID {
+		return fmt.Errorf("session %s: %w", session.ID, ErrForbidden)
+	}
+	if owner, ok := session.GetIdentity(); ok && owner.Subject != id.Subject {
+		return fmt.Errorf("session %s: %w", session.ID, ErrForbidden)
+	}
+	return nil
+}
+
+// ApplyPersona sets a persona's prompt, voice, language, tools and
+// greeting and closing lines on a session.
+func ApplyPersona(o *Orchestrator, session *ConversationSession, p Persona) {
+	if p.Voice != "" {
+		o.SetVoice(session, p.Voice)
+	}
+	if p.Language != "" {
+		o.SetLanguage(session, p.Language)
+	}
+	if p.SystemPrompt != "" {
+		o.SetSystemPrompt(session, p.SystemPrompt)
+	}
+	if len(p.AllowedTools) > 0 {
+		session.SetAllowedTools(p.AllowedTools)
+	}
+	if p.Greeting != "" || p.Closing != "" {
+		session.SetLifecycleLines(p.Greeting, p.Closing)
+	}
+}
+
+// AcquireSession reserves one of the tenant's concurrent-session slots. Call
+// the returned release function when the session ends.
+func (r *TenantRegistry) AcquireSession(tenantID string) (func(), error) {
+	e, err := r.entry(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	u := e.usage
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if limit := e.tenant.Quota.MaxConcurrentSessions; limit > 0 && u.active >= limit {
+		return nil, fmt.Errorf("tenant %s: %d concurrent sessions: %w", tenantID, u.active, ErrQuotaExceeded)
+	}
+	u.active++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			u.mu.Lock()
+			u.active--
+			u.mu.Unlock()
+		})
+	}, nil
+}
+
+// AllowTurn counts one turn against the tenant's per-minute quota.
+func (r *TenantRegistry) AllowTurn(tenantID string) error {
+	e, err := r.entry(tenantID)
+	if err != nil {
+		return err
+	}
+	return e.allowTurn("")
+}
+
+// allowTurnFor counts a turn against the tenant's quota and, for an
+// authenticated caller, against their own.
+func (r *TenantRegistry) allowTurnFor(tenantID string, id Identity, known bool) error {
+	e, err := r.entry(tenantID)
+	if err != nil {
+		return err
+	}
+	if !known {
+		id.Subject = ""
+	}
+	return e.allowTurn(id.Subject)
+}
+
+// allowTurn counts a turn against the tenant's quota and, with a subject,
+// against that caller's. Neither is counted unless both allow the turn.
+func (e *tenantEntry) allowTurn(subject string) error {
+	tenantLimit := e.tenant.Quota.MaxTurnsPerMinute
+	callerLimit := e.tenant.Quota.MaxTurnsPerMinutePerIdentity
+	u := e.usage
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := time.Now()
+
+	var caller *turnWindow
+	if subject != "" && callerLimit > 0 {
+		caller = u.callerWindow(subject, now)
+		if caller.turns >= callerLimit {
+			return fmt.Errorf("tenant %s: caller %s: %d turns this minute: %w", e.tenant.ID, subject, caller.turns, ErrQuotaExceeded)
+		}
+	}
+	if tenantLimit > 0 {
+		if now.Sub(u.windowStart) >= time.Minute {
+			u.windowStart, u.windowTurns = now, 0
+		}
+		if u.windowTurns >= tenantLimit {
+			return fmt.Errorf("tenant %s: %d turns this minute: %w", e.tenant.ID, u.windowTurns, ErrQuotaExceeded)
+		}
+		u.windowTurns++
+	}
+	if caller != nil {
+		caller.turns++
+	}
+	return nil
+}
+
+// callerWindow returns subject's current one-minute window, starting a new
+// one if the last has passed. u.mu must be held.
+func (u *tenantUsage) callerWindow(subject string, now time.Time) *turnWindow {
+	if u.callers == nil {
+		u.callers = make(map[string]*turnWindow)
+	}
+	w := u.callers[subject]
+	if w == nil {
+		if len(u.callers) >= 1024 {
+			for s, old := range u.callers {
+				if now.Sub(old.start) >= time.Minute {
+					delete(u.callers, s)
+				}
+			}
+		}
+		w = &turnWindow{start: now}
+		u.callers[subject] = w
+	}
+	if now.Sub(w.start) >= time.Minute {
+		w.start, w.turns = now, 0
+	}
+	return w
+}
+
+// ProcessAudio runs a turn on the session's tenant orchestrator after
+// checking the turn quotas, the caller's own included when the session has
+// an identity.
+func (r *TenantRegistry) ProcessAudio(ctx context.Context, session *ConversationSession, audioData []byte, streaming bool, onAudioChunk func([]byte) error) (string, []byte, error) {
+	tenantID := session.GetTenantID()
+	e, err := r.entry(tenantID)
+	if err != nil {
+		return "", nil, err
+	}
+	id, known := session.GetIdentity()
+	if err := r.allowTurnFor(tenantID, id, known); err != nil {
+		return "", nil, err
+	}
+	return e.tenant.Orchestrator.ProcessAudio(ctx, session, audioData, streaming, onAudioChunk)
+}
+
+// TenantSessionStore namespaces a shared SessionStore by tenant, so tenants
+// sharing one database can never load each other's sessions.
+type TenantSessionStore struct {
+	inner    SessionStore
+	tenantID string
+}
+
+func NewTenantSessionStore(inner SessionStore, tenantID string) *TenantSessionStore {
+	return &TenantSessionStore{inner: inner, tenantID: tenantID}
+}
+
+func (s *TenantSessionStore) key(id string) string {
+	return s.tenantID + "/" + id
+}
+
+func (s *TenantSessionStore) Load(ctx context.Context, id string) (SessionSnapshot, error) {
+	snap, err := s.inner.Load(ctx, s.key(id))
+	if err != nil {
+		return snap, err
+	}
+	snap.ID = id
+	return snap, nil
+}
+
+func (s *TenantSessionStore) Save(ctx context.Context, snap SessionSnapshot) (int64, error) {
+	snap.ID = s.key(snap.ID)
+	snap.TenantID = s.tenantID
+	return s.inner.Save(ctx, snap)
+}
+
+func (s *TenantSessionStore) Delete(ctx context.Context, id string) error {
+	return s.inner.Delete(ctx, s.key(id))
+}
+
+// PurgeUser deletes the user's sessions of this tenant. The shared store
+// must be a PrefixPurger, so other tenants' sessions of a user with the same
+// ID are kept; one that cannot purge at all holds nothing to purge here.
+func (s *TenantSessionStore) PurgeUser(ctx context.Context, userID string) (int, error) {
+	switch inner := s.inner.(type) {
+	case PrefixPurger:
+		return inner.PurgeUserWithPrefix(ctx, s.key(""), userID)
+	case Purger:
+		return 0, fmt.Errorf("tenant %s: the session store cannot purge one tenant's sessions", s.tenantID)
+	}
+	return 0, nil
+}
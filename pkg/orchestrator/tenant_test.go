@@ -0,0 +1,176 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTenantOrch(reply string) *Orchestrator {
+	return New(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: reply}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), nil)
+}
+
+func TestTenantRegistry_SessionsAndAuth(t *testing.T) {
+	reg := NewTenantRegistry()
+	if err := reg.Register(Tenant{
+		ID:           "acme",
+		Orchestrator: newTenantOrch("acme here"),
+		Persona:      Persona{Name: "Ava", SystemPrompt: "You are Ava from Acme.", Voice: VoiceF3, Language: LanguageFr},
+		APIKeys:      []string{"acme-secret"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	reg.Register(Tenant{ID: "globex", Orchestrator: newTenantOrch("globex here"), APIKeys: []string{"globex-secret"}})
+
+	tenant, err := reg.Authenticate("acme-secret")
+	if err != nil || tenant.ID != "acme" {
+		t.Fatalf("expected acme, got %+v, %v", tenant, err)
+	}
+	if len(tenant.APIKeys) != 0 {
+		t.Error("raw API keys must not be retained")
+	}
+	if _, err := reg.Authenticate("wrong"); !errors.Is(err, ErrTenantNotFound) {
+		t.Errorf("expected ErrTenantNotFound, got %v", err)
+	}
+
+	session, err := reg.NewSession("acme", "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.TenantID != "acme" || session.CurrentVoice != VoiceF3 || session.CurrentLanguage != LanguageFr {
+		t.Errorf("persona not applied: %+v", session.Snapshot())
+	}
+	if len(session.Context) != 1 || !strings.HasPrefix(session.Context[0].Content, "You are Ava from Acme.") {
+		t.Errorf("expected persona system prompt, got %+v", session.Context)
+	}
+
+	if _, _, err := reg.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if session.LastAssistant != "acme here" {
+		t.Errorf("turn was not served by the tenant's own providers: %q", session.LastAssistant)
+	}
+
+	if err := reg.Register(Tenant{ID: "bad/id", Orchestrator: newTenantOrch("")}); err == nil {
+		t.Error("expected tenant IDs with '/' to be rejected")
+	}
+	if err := reg.Register(Tenant{ID: "nil"}); !errors.Is(err, ErrNilProvider) {
+		t.Errorf("expected ErrNilProvider, got %v", err)
+	}
+}
+
+func TestTenantRegistry_Quotas(t *testing.T) {
+	reg := NewTenantRegistry()
+	reg.Register(Tenant{ID: "small", Orchestrator: newTenantOrch("ok"), Quota: TenantQuota{MaxConcurrentSessions: 1, MaxTurnsPerMinute: 2}})
+
+	release, err := reg.AcquireSession("small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reg.AcquireSession("small"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected concurrent session quota to apply, got %v", err)
+	}
+	release()
+	release()
+	if r, err := reg.AcquireSession("small"); err != nil {
+		t.Errorf("expected slot to be freed, got %v", err)
+	} else {
+		r()
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := reg.AllowTurn("small"); err != nil {
+			t.Fatalf("turn %d rejected: %v", i, err)
+		}
+	}
+	if err := reg.AllowTurn("small"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected turn quota to apply, got %v", err)
+	}
+}
+
+func TestTenantRegistry_ReRegisterKeepsSlots(t *testing.T) {
+	reg := NewTenantRegistry()
+	small := Tenant{ID: "small", Orchestrator: newTenantOrch("ok"), Quota: TenantQuota{MaxConcurrentSessions: 1}}
+	reg.Register(small)
+
+	release, err := reg.AcquireSession("small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	small.Persona.Name = "Updated"
+	reg.Register(small)
+	if _, err := reg.AcquireSession("small"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("the held slot should still count after re-registering, got %v", err)
+	}
+	// A slot acquired before the update is released to the updated tenant.
+	release()
+	if r, err := reg.AcquireSession("small"); err != nil {
+		t.Errorf("expected slot to be freed, got %v", err)
+	} else {
+		r()
+	}
+}
+
+func TestTenantSessionStore_Isolation(t *testing.T) {
+	shared := NewInMemorySessionStore()
+	acme := NewTenantSessionStore(shared, "acme")
+	globex := NewTenantSessionStore(shared, "globex")
+	ctx := context.Background()
+
+	if _, err := acme.Save(ctx, SessionSnapshot{ID: "s1", LastUser: "acme data"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := globex.Load(ctx, "s1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("tenant must not see another tenant's session, got %v", err)
+	}
+	snap, err := acme.Load(ctx, "s1")
+	if err != nil || snap.ID != "s1" || snap.TenantID != "acme" || snap.LastUser != "acme data" {
+		t.Errorf("unexpected snapshot: %+v, %v", snap, err)
+	}
+}
+
+func TestTenantRegistry_RejectedTurnKeepsCallerQuota(t *testing.T) {
+	reg := NewTenantRegistry()
+	reg.Register(Tenant{ID: "acme", Orchestrator: newTenantOrch("hi"), Quota: TenantQuota{MaxTurnsPerMinute: 1, MaxTurnsPerMinutePerIdentity: 5}})
+
+	if err := reg.allowTurnFor("acme", Identity{Subject: "bob"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.allowTurnFor("acme", Identity{Subject: "alice"}, true); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected the tenant quota to reject alice's turn, got %v", err)
+	}
+	e, _ := reg.entry("acme")
+	if w := e.usage.callers["alice"]; w != nil && w.turns != 0 {
+		t.Errorf("a turn the tenant quota rejected counted %d against alice's own quota", w.turns)
+	}
+}
+
+func TestTenantSessionStore_PurgeUser(t *testing.T) {
+	shared := NewInMemorySessionStore()
+	acme := NewTenantSessionStore(shared, "acme")
+	globex := NewTenantSessionStore(shared, "globex")
+	ctx := context.Background()
+	acme.Save(ctx, SessionSnapshot{ID: "s1", UserID: "alice"})
+	acme.Save(ctx, SessionSnapshot{ID: "s2", UserID: "bob"})
+	globex.Save(ctx, SessionSnapshot{ID: "s1", UserID: "alice"})
+
+	o := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig(), nil)
+	o.SetSessionStore(acme)
+	report, err := o.PurgeUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Removed["sessions"] != 1 {
+		t.Errorf("removed %d sessions, want alice's one in acme", report.Removed["sessions"])
+	}
+	if _, err := acme.Load(ctx, "s1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("alice's acme session should be gone, got %v", err)
+	}
+	if _, err := acme.Load(ctx, "s2"); err != nil {
+		t.Errorf("bob's session must survive, got %v", err)
+	}
+	if _, err := globex.Load(ctx, "s1"); err != nil {
+		t.Errorf("another tenant's session must survive, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProcessText runs a turn from typed input. It is ProcessAudio without STT:
+// text joins the session as the user's message and the reply is synthesised
+// in the session's voice. It returns the reply and its audio.
+func (o *Orchestrator) ProcessText(ctx context.Context, session *ConversationSession, text string) (string, []byte, error) {
+	return o.processText(ctx, session, text, false, nil)
+}
+
+// ProcessTextStream is ProcessText with the reply's audio streamed to
+// onAudioChunk.
+func (o *Orchestrator) ProcessTextStream(ctx context.Context, session *ConversationSession, text string, onAudioChunk func([]byte) error) (string, error) {
+	response, _, err := o.processText(ctx, session, text, true, onAudioChunk)
+	return response, err
+}
+
+func (o *Orchestrator) processText(ctx context.Context, session *ConversationSession, text string, streaming bool, onAudioChunk func([]byte) error) (string, []byte, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", nil, ErrEmptyInput
+	}
+	var response string
+	var audio []byte
+	err := o.withTurn(ctx, session, nil, func(ctx context.Context, _ []byte, rec *TurnRecording) error {
+		if rec == nil {
+			rec = &TurnRecording{}
+		}
+		turnStart := time.Now()
+		defer func() { rec.Timings.Total = time.Since(turnStart) }()
+		rec.Transcript = text
+
+		session.AddMessage("user", text)
+		onAudioChunk, flush := o.framedAudio(ctx, onAudioChunk)
+		var err error
+		audio, err = o.respond(withSpeechRate(ctx, session), session, streaming, onAudioChunk, rec)
+		response = rec.Response
+		if err == nil {
+			err = flush()
+		}
+		return err
+	})
+	return response, audio, err
+}
+
+// Chat runs a text-only turn: text joins the session as the user's message
+// and the LLM's reply is returned without synthesising it.
+func (o *Orchestrator) Chat(ctx context.Context, session *ConversationSession, text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrEmptyInput
+	}
+	var response string
+	err := o.withTurn(ctx, session, nil, func(ctx context.Context, _ []byte, rec *TurnRecording) error {
+		if rec == nil {
+			rec = &TurnRecording{}
+		}
+		turnStart := time.Now()
+		defer func() { rec.Timings.Total = time.Since(turnStart) }()
+		rec.Transcript = text
+
+		session.AddMessage("user", text)
+		var err error
+		response, err = o.GenerateResponse(ctx, session)
+		rec.Timings.LLM = time.Since(turnStart)
+		rec.Response = response
+		if err != nil {
+			o.logger.Error("LLM generation failed", "sessionID", session.ID, "error", err)
+			return fmt.Errorf("%w: %v", ErrLLMFailed, err)
+		}
+		session.AddMessage("assistant", response)
+		return nil
+	})
+	return response, err
+}
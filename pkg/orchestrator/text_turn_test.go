@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestProcessText_SkipsSTT(t *testing.T) {
+	stt := &MockSTTProvider{transcribeErr: errors.New("STT must not be called")}
+	o := NewWithVAD(stt, &MockLLMProvider{completeResult: "We open at nine."}, &MockTTSProvider{synthesizeResult: []byte{1, 2}}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+
+	response, audio, err := o.ProcessText(context.Background(), session, "  when do you open?  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "We open at nine." || len(audio) != 2 {
+		t.Errorf("got response %q, audio %v", response, audio)
+	}
+	ctx := session.GetContextCopy()
+	if len(ctx) != 2 || ctx[0].Content != "when do you open?" || ctx[1].Content != "We open at nine." {
+		t.Errorf("unexpected context %q", contents(ctx))
+	}
+}
+
+func TestProcessTextStream(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: "Sure."}, &MockTTSProvider{synthesizeResult: []byte{7}}, nil, DefaultConfig())
+	var chunks [][]byte
+	response, err := o.ProcessTextStream(context.Background(), NewConversationSession("u"), "hi", func(b []byte) error {
+		chunks = append(chunks, b)
+		return nil
+	})
+	if err != nil || response != "Sure." || len(chunks) != 1 {
+		t.Errorf("got %q, %d chunks, err %v", response, len(chunks), err)
+	}
+}
+
+func TestChat_SkipsSTTAndTTS(t *testing.T) {
+	tts := &MockTTSProvider{synthesizeErr: errors.New("TTS must not be called"), streamErr: errors.New("TTS must not be called")}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: "Nine o'clock."}, tts, nil, DefaultConfig())
+	session := NewConversationSession("u")
+
+	response, err := o.Chat(context.Background(), session, "when do you open?")
+	if err != nil || response != "Nine o'clock." {
+		t.Fatalf("got %q, %v", response, err)
+	}
+	if session.LastUser != "when do you open?" || session.LastAssistant != "Nine o'clock." {
+		t.Errorf("turn not recorded: %q / %q", session.LastUser, session.LastAssistant)
+	}
+
+	if _, err := o.Chat(context.Background(), session, "   "); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("expected ErrEmptyInput, got %v", err)
+	}
+
+	o = NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeErr: errors.New("down")}, tts, nil, DefaultConfig())
+	if _, err := o.Chat(context.Background(), NewConversationSession("u"), "hello"); !errors.Is(err, ErrLLMFailed) {
+		t.Errorf("expected ErrLLMFailed, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+// localRate takes the speech rate off ctx when Config.TimeStretch applies it
+// to the synthesised audio rather than asking the TTS provider for it. It
+// returns the rate to stretch by, 1 for none.
+func (o *Orchestrator) localRate(ctx context.Context) (context.Context, float64) {
+	rate := SpeechRateFromContext(ctx)
+	if rate == 1 || !o.GetConfig().TimeStretch {
+		return ctx, 1
+	}
+	return WithSpeechRate(ctx, 1), rate
+}
+
+func (o *Orchestrator) timeStretch(pcm []byte, rate float64) []byte {
+	return audio.TimeStretch(pcm, o.ttsSampleRate(), rate)
+}
+
+func (o *Orchestrator) ttsSampleRate() int {
+	if rate := o.GetConfig().SampleRate; rate > 0 {
+		return rate
+	}
+	return DefaultConfig().SampleRate
+}
+
+// stretchedSink passes streamed TTS audio through a Stretcher on its way to
+// the listener; at rate 1 it passes it straight on.
+type stretchedSink struct {
+	stretcher *audio.Stretcher
+	send      func([]byte) error
+}
+
+func (o *Orchestrator) newStretchedSink(rate float64, send func([]byte) error) *stretchedSink {
+	s := &stretchedSink{send: send}
+	if rate != 1 {
+		s.stretcher = audio.NewStretcher(o.ttsSampleRate(), rate)
+	}
+	return s
+}
+
+func (s *stretchedSink) write(chunk []byte) error {
+	if s.stretcher != nil {
+		chunk = s.stretcher.Write(chunk)
+	}
+	if len(chunk) == 0 {
+		return nil
+	}
+	return s.send(chunk)
+}
+
+func (s *stretchedSink) flush() error {
+	if s.stretcher == nil {
+		return nil
+	}
+	if out := s.stretcher.Flush(); len(out) > 0 {
+		return s.send(out)
+	}
+	return nil
+}
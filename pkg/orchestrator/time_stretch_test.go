@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+)
+
+type pcmTTS struct {
+	MockTTSProvider
+	pcm   []byte
+	rates []float64
+}
+
+func (p *pcmTTS) Synthesize(ctx context.Context, text string, voice Voice, lang Language) ([]byte, error) {
+	p.rates = append(p.rates, SpeechRateFromContext(ctx))
+	return p.pcm, nil
+}
+
+func (p *pcmTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	p.rates = append(p.rates, SpeechRateFromContext(ctx))
+	for i := 0; i < len(p.pcm); i += 4410 {
+		if err := onChunk(p.pcm[i:min(i+4410, len(p.pcm))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestTimeStretch_AppliesSessionRateLocally(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TimeStretch = true
+	tts := &pcmTTS{pcm: make([]byte, 88200)} // One second
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{completeResult: "We open at nine."}, tts, nil, cfg)
+	session := NewConversationSession("u")
+	session.SetSpeechRate(1.25)
+
+	_, audio, err := o.ProcessText(context.Background(), session, "when do you open?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audio) != 70560 {
+		t.Errorf("expected 0.8s of audio, got %d bytes", len(audio))
+	}
+
+	var streamed int
+	if _, err := o.ProcessTextStream(context.Background(), session, "and on Sundays?", func(b []byte) error {
+		streamed += len(b)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if streamed != 70560 {
+		t.Errorf("expected streamed audio to be stretched too, got %d bytes", streamed)
+	}
+	for _, r := range tts.rates {
+		if r != 1 {
+			t.Errorf("the provider must synthesise at its normal rate, got %v", r)
+		}
+	}
+
+	// A slower repeat stretches the cached audio instead of calling TTS.
+	calls := len(tts.rates)
+	repeat, err := o.RepeatLast(WithSpeechRate(context.Background(), 0.8), session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tts.rates) != calls {
+		t.Error("repeat must not call the TTS provider")
+	}
+	if len(repeat) != 110250 {
+		t.Errorf("expected 1.25s of audio, got %d bytes", len(repeat))
+	}
+}
@@ -0,0 +1,174 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+)
+
+// WordTiming places one transcribed word in audio. In a TranscriptionResult
+// offsets are from the start of the transcribed audio; in a TurnTimeline they
+// are from the start of the stream's input.
+type WordTiming struct {
+	Word  string        `json:"word"`
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+}
+
+// AudioSpan is a stretch of a stream's audio, as offsets from its first
+// sample. Input offsets count everything written to the stream; output
+// offsets count every AudioChunk it emitted, comfort noise included, so a
+// client that plays chunks back to back can map them onto its own clock.
+type AudioSpan struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+}
+
+// SpokenSentence is one sentence of a reply and where it is in the output.
+type SpokenSentence struct {
+	Text   string    `json:"text"`
+	Output AudioSpan `json:"output"`
+}
+
+// TurnTimeline links the parts of one exchange: the user's speech in the
+// input audio and its words, the LLM's reply, and where each sentence of the
+// reply falls in the output audio, with wall-clock times for each stage. A
+// ManagedStream emits one with TurnTimelineReady after each reply it speaks.
+type TurnTimeline struct {
+	TurnID     string           `json:"turn_id,omitempty"` // The turn's idempotency key
+	Input      AudioSpan        `json:"input"`             // Zero when the bot spoke first
+	Words      []WordTiming     `json:"words,omitempty"`   // When the STT provider reports them
+	Transcript string           `json:"transcript,omitempty"`
+	Response   string           `json:"response"`
+	Output     AudioSpan        `json:"output"`
+	Sentences  []SpokenSentence `json:"sentences"`
+
+	UserStartedAt   time.Time `json:"user_started_at,omitempty"`
+	UserEndedAt     time.Time `json:"user_ended_at,omitempty"`
+	LLMStartedAt    time.Time `json:"llm_started_at,omitempty"`
+	LLMFirstTokenAt time.Time `json:"llm_first_token_at,omitempty"`
+	AudioStartedAt  time.Time `json:"audio_started_at"` // First output chunk emitted
+	AudioEndedAt    time.Time `json:"audio_ended_at"`   // Last output chunk emitted
+
+	Calls []ProviderCall `json:"calls,omitempty"` // Provider requests made for the turn, with the vendors' request IDs
+}
+
+// timelineState tracks stream positions for TurnTimeline. It is guarded by
+// the stream's mu.
+type timelineState struct {
+	inputBytes  int64 // Audio written to the stream so far
+	outputBytes int64 // Audio emitted so far
+	inputRate   int
+
+	speechStart int64 // Input offset of the current utterance, in bytes
+	speechEnd   int64
+	sttStart    int64 // Input offset of the audio last sent to batch STT
+
+	heard *TurnTimeline // The user side of the turn being answered
+}
+
+func bytesToDuration(n int64, sampleRate int) time.Duration {
+	if sampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(n/2) * time.Second / time.Duration(sampleRate)
+}
+
+// noteTranscript starts the timeline of a turn from its final transcript.
+// words, if any, are relative to the audio given to batch STT.
+func (ms *ManagedStream) noteTranscript(transcript string, words []WordTiming) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	rate := ms.tl.inputRate
+	end := ms.tl.speechEnd
+	if end < ms.tl.speechStart {
+		end = ms.tl.inputBytes
+	}
+	tl := &TurnTimeline{
+		Input:         AudioSpan{Start: bytesToDuration(ms.tl.speechStart, rate), End: bytesToDuration(end, rate)},
+		Transcript:    transcript,
+		UserStartedAt: ms.userSpeechStartTime,
+		UserEndedAt:   ms.userSpeechEndTime,
+	}
+	base := bytesToDuration(ms.tl.sttStart, rate)
+	for _, w := range words {
+		tl.Words = append(tl.Words, WordTiming{Word: w.Word, Start: base + w.Start, End: base + w.End})
+	}
+	ms.tl.heard = tl
+}
+
+// outputMap records where the bytes of one reply went in the output, which
+// comfort noise between sentences can make discontinuous.
+type outputMap struct {
+	runs []outputRun
+	sent int64 // Reply bytes emitted so far
+}
+
+type outputRun struct {
+	reply, stream int64 // Start of the run in the reply and in the output
+	n             int64
+}
+
+// add records n reply bytes emitted at output offset stream.
+func (m *outputMap) add(stream int64, n int) {
+	if k := len(m.runs); k > 0 {
+		last := &m.runs[k-1]
+		if last.stream+last.n == stream {
+			last.n += int64(n)
+			m.sent += int64(n)
+			return
+		}
+	}
+	m.runs = append(m.runs, outputRun{reply: m.sent, stream: stream, n: int64(n)})
+	m.sent += int64(n)
+}
+
+// at maps a reply byte offset to an output byte offset. The end of the reply
+// maps to the end of its last run.
+func (m *outputMap) at(reply int64) int64 {
+	for _, r := range m.runs {
+		if reply < r.reply+r.n {
+			return r.stream + max(reply-r.reply, 0)
+		}
+	}
+	if k := len(m.runs); k > 0 {
+		last := m.runs[k-1]
+		return last.stream + last.n
+	}
+	return 0
+}
+
+// finishTimeline completes the turn's timeline with the reply, whose
+// sentences ended at the reply byte offsets in ends, and emits it.
+func (ms *ManagedStream) finishTimeline(sentences []string, ends []int64, out *outputMap, firstAudio, lastAudio time.Time) {
+	if len(out.runs) == 0 {
+		return
+	}
+	ms.mu.Lock()
+	tl := ms.tl.heard
+	ms.tl.heard = nil
+	if tl == nil {
+		tl = &TurnTimeline{}
+	}
+	tl.TurnID = ms.turnKey
+	tl.Calls = ms.audit.Calls()
+	tl.LLMStartedAt = ms.llmStartTime
+	tl.LLMFirstTokenAt = ms.llmEndTime
+	rate := ms.playbackRate
+	ms.mu.Unlock()
+
+	tl.Response = strings.Join(sentences, " ")
+	tl.AudioStartedAt, tl.AudioEndedAt = firstAudio, lastAudio
+	tl.Output = AudioSpan{Start: bytesToDuration(out.at(0), rate), End: bytesToDuration(out.at(out.sent), rate)}
+	var start int64
+	for i, text := range sentences {
+		end := min(ends[i], out.sent)
+		span := AudioSpan{Start: bytesToDuration(out.at(start), rate)}
+		span.End = span.Start
+		if end > start {
+			span.End = bytesToDuration(out.at(end-1)+1, rate)
+		}
+		tl.Sentences = append(tl.Sentences, SpokenSentence{Text: text, Output: span})
+		start = end
+	}
+	ms.emit(TurnTimelineReady, *tl)
+}
@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type wordSTT struct{}
+
+func (wordSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	return TranscriptionResult{Text: "book a table", Words: []WordTiming{
+		{Word: "book", Start: 500 * time.Millisecond, End: 800 * time.Millisecond},
+		{Word: "a", Start: 800 * time.Millisecond, End: 900 * time.Millisecond},
+		{Word: "table", Start: 900 * time.Millisecond, End: 1400 * time.Millisecond},
+	}}, nil
+}
+
+func (wordSTT) Name() string { return "words" }
+
+func TestManagedStream_TurnTimeline(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	tts := &pcmTTS{pcm: make([]byte, 16000)} // One second at 8kHz
+	o := NewWithVAD(wordSTT{}, &MockLLMProvider{completeResult: "For how many?"}, tts, nil, cfg)
+	ms := o.NewManagedStream(context.Background(), NewConversationSession("u"))
+	defer ms.Close()
+	ms.SetEchoSampleRates(8000, 8000)
+
+	// Two seconds of input, with speech from 1s to 2s and batch STT given
+	// everything from 0.5s on.
+	ms.mu.Lock()
+	ms.tl.inputBytes = 32000
+	ms.tl.speechStart, ms.tl.speechEnd, ms.tl.sttStart = 16000, 32000, 8000
+	ms.userSpeechStartTime = time.Now().Add(-time.Second)
+	ms.userSpeechEndTime = time.Now()
+	ms.mu.Unlock()
+	ms.runBatchPipeline(make([]byte, 24000))
+
+	var tl TurnTimeline
+	timeout := time.After(time.Second)
+	for tl.Response == "" {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type == TurnTimelineReady {
+				tl = ev.Data.(TurnTimeline)
+			}
+		case <-timeout:
+			t.Fatal("no TurnTimelineReady event")
+		}
+	}
+
+	if tl.Transcript != "book a table" || tl.Response != "For how many?" || tl.TurnID == "" {
+		t.Errorf("unexpected turn: %+v", tl)
+	}
+	if tl.Input != (AudioSpan{Start: time.Second, End: 2 * time.Second}) {
+		t.Errorf("input span: got %+v", tl.Input)
+	}
+	if len(tl.Words) != 3 || tl.Words[0].Start != time.Second || tl.Words[2].End != 1900*time.Millisecond {
+		t.Errorf("word offsets must be in input-stream time, got %+v", tl.Words)
+	}
+	if tl.Output != (AudioSpan{Start: 0, End: time.Second}) {
+		t.Errorf("output span: got %+v", tl.Output)
+	}
+	if len(tl.Sentences) != 1 || tl.Sentences[0].Output != tl.Output {
+		t.Errorf("sentences: got %+v", tl.Sentences)
+	}
+	if tl.AudioStartedAt.IsZero() || tl.UserEndedAt.After(tl.AudioStartedAt) {
+		t.Errorf("wall-clock times out of order: %+v", tl)
+	}
+}
+
+func TestOutputMap_SkipsNoiseBetweenSentences(t *testing.T) {
+	var m outputMap
+	m.add(100, 40) // First sentence
+	m.add(140, 20) // Second sentence starts right after...
+	m.add(300, 40) // ...and continues after 140 bytes of comfort noise
+	if got := m.at(0); got != 100 {
+		t.Errorf("start: got %d", got)
+	}
+	if got := m.at(60); got != 300 {
+		t.Errorf("reply byte 60 comes after the noise: got %d", got)
+	}
+	if got := m.at(m.sent); got != 340 {
+		t.Errorf("end: got %d", got)
+	}
+}
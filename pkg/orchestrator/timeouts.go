@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned when a provider call runs past its stage's
+// timeout in Config: STTTimeout, LLMTimeout or TTSTimeout. It matches both
+// ErrProviderTimeout and context.DeadlineExceeded, so it is not retried.
+type TimeoutError struct {
+	Stage    Stage
+	Provider string
+	Timeout  time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s provider %s timed out after %v", e.Stage, e.Provider, e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() []error {
+	return []error{ErrProviderTimeout, context.DeadlineExceeded}
+}
+
+func (cfg Config) stageTimeout(stage Stage) time.Duration {
+	switch stage {
+	case StageSTT:
+		return cfg.STTTimeout
+	case StageLLM:
+		return cfg.LLMTimeout
+	case StageTTS:
+		return cfg.TTSTimeout
+	}
+	return 0
+}
+
+// callTimeout bounds one provider call by its stage's timeout. Pass the
+// call's error through done, which releases the context and reports the
+// cancellation the provider saw as a TimeoutError when the timeout caused
+// it. A zero timeout leaves the call to ctx.
+func (o *Orchestrator) callTimeout(ctx context.Context, stage Stage, provider interface{ Name() string }) (_ context.Context, done func(error) error) {
+	d := o.GetConfig().stageTimeout(stage)
+	if d <= 0 {
+		return ctx, func(err error) error { return err }
+	}
+	timeout := &TimeoutError{Stage: stage, Provider: provider.Name(), Timeout: d}
+	ctx, cancel := context.WithTimeoutCause(ctx, d, timeout)
+	return ctx, func(err error) error {
+		defer cancel()
+		if err != nil && context.Cause(ctx) == error(timeout) {
+			o.logger.Warn("provider timed out", "stage", stage, "provider", timeout.Provider, "timeout", d)
+			return timeout
+		}
+		return err
+	}
+}
@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hangingLLM answers after delay, or fails when ctx ends first.
+type hangingLLM struct {
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (h *hangingLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	h.calls.Add(1)
+	select {
+	case <-time.After(h.delay):
+		return "late", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (h *hangingLLM) Name() string { return "hanging" }
+
+func TestProviderTimeouts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.STTTimeout = 20 * time.Millisecond
+	cfg.LLMTimeout = 20 * time.Millisecond
+	cfg.RetryBaseDelay = time.Millisecond
+	llm := &hangingLLM{delay: time.Second}
+	stt := &delayedSTT{name: "slow-stt", delay: time.Second}
+	o := New(stt, llm, &MockTTSProvider{}, nil, cfg, nil)
+
+	start := time.Now()
+	_, err := o.GenerateResponse(context.Background(), o.NewSessionWithDefaults("s"))
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.Stage != StageLLM || te.Provider != "hanging" || te.Timeout != 20*time.Millisecond {
+		t.Fatalf("err = %v", err)
+	}
+	if !errors.Is(err, ErrProviderTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("%v should match ErrProviderTimeout and DeadlineExceeded", err)
+	}
+	if n := llm.calls.Load(); n != 1 {
+		t.Errorf("a timed out call was retried: %d calls", n)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("waited %v", time.Since(start))
+	}
+
+	if _, err := o.Transcribe(context.Background(), nil, LanguageEn); !errors.As(err, &te) || te.Stage != StageSTT {
+		t.Errorf("STT err = %v", err)
+	}
+
+	// The caller's own deadline is not reported as the provider's timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := o.Transcribe(ctx, nil, LanguageEn); errors.As(err, &te) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("caller deadline err = %v", err)
+	}
+
+	// Zero disables the timeout.
+	cfg.LLMTimeout = 0
+	o.UpdateConfig(cfg)
+	llm.delay = 40 * time.Millisecond
+	if reply, err := o.GenerateResponse(context.Background(), o.NewSessionWithDefaults("s")); err != nil || reply != "late" {
+		t.Errorf("reply %q, err %v", reply, err)
+	}
+}
@@ -0,0 +1,185 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ServerTLS is the TLS setup of a server exposing the orchestrator's
+// handlers, such as HealthHandler or a RequireAuth-guarded stream endpoint,
+// directly to clients with no terminating proxy in front.
+//
+// A deployment serving several tenants on their own domains lists them in
+// Hosts: each gets its certificate by SNI, and TenantFor tells handlers
+// which tenant a connection came in for. With ClientCAFile set, clients may
+// present certificates signed by those CAs (mutual TLS), and must when
+// RequireClientCert is set; ClientCertAuth turns them into identities.
+type ServerTLS struct {
+	CertFile          string              // Default certificate, served when no host matches
+	KeyFile           string              // Its private key
+	Hosts             map[string]HostCert // By server name; "*.example.com" matches one label
+	ClientCAFile      string              // PEM bundle client certificates must chain to; "" disables mTLS
+	RequireClientCert bool                // Refuse clients without a valid certificate
+	MinVersion        uint16              // Defaults to TLS 1.2
+}
+
+// HostCert is the certificate served for one server name, and the tenant
+// connections to that name belong to.
+type HostCert struct {
+	CertFile string
+	KeyFile  string
+	TenantID string
+}
+
+// Config loads the certificates and returns the tls.Config to serve with.
+func (c ServerTLS) Config() (*tls.Config, error) {
+	var fallback *tls.Certificate
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: default certificate: %w", err)
+		}
+		fallback = &cert
+	}
+	hosts := make(map[string]*tls.Certificate, len(c.Hosts))
+	for name, h := range c.Hosts {
+		cert, err := tls.LoadX509KeyPair(h.CertFile, h.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: certificate for %s: %w", name, err)
+		}
+		hosts[strings.ToLower(name)] = &cert
+	}
+	if fallback == nil && len(hosts) == 0 {
+		return nil, errors.New("tls: no certificate configured")
+	}
+	if c.RequireClientCert && c.ClientCAFile == "" {
+		return nil, errors.New("tls: RequireClientCert needs a ClientCAFile")
+	}
+
+	cfg := &tls.Config{
+		MinVersion: c.MinVersion,
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if cert := hosts[matchHost(hosts, hello.ServerName)]; cert != nil {
+				return cert, nil
+			}
+			if fallback != nil {
+				return fallback, nil
+			}
+			return nil, fmt.Errorf("tls: no certificate for %q", hello.ServerName)
+		},
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: client CAs: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates in %s", c.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+		if c.RequireClientCert {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return cfg, nil
+}
+
+// matchHost returns the key of hosts, whose keys are lower case, that
+// serves name: the name itself, else a wildcard for its parent domain.
+func matchHost[V any](hosts map[string]V, name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if _, ok := hosts[name]; ok && name != "" {
+		return name
+	}
+	if _, parent, ok := strings.Cut(name, "."); ok {
+		if _, ok := hosts["*."+parent]; ok {
+			return "*." + parent
+		}
+	}
+	return ""
+}
+
+// TenantFor returns the tenant of the host name a TLS request was made to.
+func (c ServerTLS) TenantFor(r *http.Request) (string, bool) {
+	if r.TLS == nil {
+		return "", false
+	}
+	hosts := make(map[string]HostCert, len(c.Hosts))
+	for name, h := range c.Hosts {
+		hosts[strings.ToLower(name)] = h
+	}
+	h, ok := hosts[matchHost(hosts, r.TLS.ServerName)]
+	return h.TenantID, ok && h.TenantID != ""
+}
+
+// ClientCertAuth authenticates clients by the certificate they presented
+// over mutual TLS. The subject is the certificate's common name; the tenant
+// is that of the host name connected to, or else the certificate's first
+// organization. A certificate naming another tenant than the host's is
+// refused, so a client CA shared by tenants can't be used to cross them.
+type ClientCertAuth struct {
+	TLS    ServerTLS
+	Scopes []string // Granted to every client with a valid certificate
+}
+
+func (a *ClientCertAuth) Authenticate(r *http.Request) (Identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return Identity{}, ErrUnauthenticated
+	}
+	leaf := r.TLS.VerifiedChains[0][0]
+	id := Identity{Subject: "cert:" + leaf.Subject.CommonName, Scopes: a.Scopes}
+	if len(leaf.Subject.Organization) > 0 {
+		id.TenantID = leaf.Subject.Organization[0]
+	}
+	if tenant, ok := a.TLS.TenantFor(r); ok {
+		if id.TenantID != "" && id.TenantID != tenant {
+			return Identity{}, ErrUnauthenticated
+		}
+		id.TenantID = tenant
+	}
+	return id, nil
+}
+
+// ServeTLS serves handler over TLS on ln until ctx ends, then shuts down,
+// giving requests in flight up to ten seconds to finish.
+func ServeTLS(ctx context.Context, ln net.Listener, handler http.Handler, c ServerTLS) error {
+	cfg, err := c.Config()
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	srv := &http.Server{Handler: handler, TLSConfig: cfg, ReadHeaderTimeout: 10 * time.Second}
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	})
+	defer stop()
+	err = srv.Serve(tls.NewListener(ln, cfg))
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// ListenAndServeTLS is ServeTLS on a new TCP listener at addr.
+func ListenAndServeTLS(ctx context.Context, addr string, handler http.Handler, c ServerTLS) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return ServeTLS(ctx, ln, handler, c)
+}
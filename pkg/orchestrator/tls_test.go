@@ -0,0 +1,199 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+	n    int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	ca := &testCA{dir: t.TempDir()}
+	ca.cert, ca.key = ca.issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	})
+	return ca
+}
+
+// issue signs tmpl with the CA, or self-signs it while the CA has no key.
+func (ca *testCA) issue(t *testing.T, tmpl *x509.Certificate) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.n++
+	tmpl.SerialNumber = big.NewInt(ca.n)
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	parent, signer := tmpl, key
+	if ca.key != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key
+}
+
+// files writes a certificate and its key as PEM and returns their paths.
+func (ca *testCA) files(t *testing.T, name string, cert *x509.Certificate, key *ecdsa.PrivateKey) (string, string) {
+	t.Helper()
+	certFile := filepath.Join(ca.dir, name+".crt")
+	keyFile := filepath.Join(ca.dir, name+".key")
+	der, _ := x509.MarshalECPrivateKey(key)
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600)
+	return certFile, keyFile
+}
+
+func (ca *testCA) server(t *testing.T, name string, dnsNames ...string) (string, string) {
+	cert, key := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	return ca.files(t, name, cert, key)
+}
+
+func (ca *testCA) client(t *testing.T, cn, org string) tls.Certificate {
+	cert, key := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: cn, Organization: []string{org}},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key}
+}
+
+func TestServeTLS_SNIAndClientCerts(t *testing.T) {
+	ca := newTestCA(t)
+	caFile, _ := ca.files(t, "ca", ca.cert, ca.key)
+	defCert, defKey := ca.server(t, "default", "voice.example.net")
+	acmeCert, acmeKey := ca.server(t, "acme", "*.acme.example.com")
+	cfg := ServerTLS{
+		CertFile:     defCert,
+		KeyFile:      defKey,
+		Hosts:        map[string]HostCert{"*.acme.example.com": {CertFile: acmeCert, KeyFile: acmeKey, TenantID: "acme"}},
+		ClientCAFile: caFile,
+	}
+
+	handler := RequireAuth(&ClientCertAuth{TLS: cfg, Scopes: []string{"stream:write"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := IdentityFrom(r.Context())
+		io.WriteString(w, id.Subject+" "+id.TenantID)
+	}))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- ServeTLS(ctx, ln, handler, cfg) }()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(serverName string, certs ...tls.Certificate) (int, string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs: roots, ServerName: serverName, Certificates: certs,
+		}}}
+		resp, err := client.Get("https://" + ln.Addr().String() + "/")
+		if err != nil {
+			return 0, "", err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body), nil
+	}
+
+	// The host certificate verifying against the requested name shows SNI
+	// picked it.
+	code, body, err := get("eu.acme.example.com", ca.client(t, "kiosk-7", "acme"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != http.StatusOK || body != "cert:kiosk-7 acme" {
+		t.Errorf("got %d %q, want the client identified in the tenant of its host", code, body)
+	}
+	if code, _, err := get("eu.acme.example.com", ca.client(t, "kiosk-9", "globex")); err != nil || code != http.StatusUnauthorized {
+		t.Errorf("another tenant's certificate on acme's host: got %d, %v; want 401", code, err)
+	}
+	if _, body, err := get("voice.example.net", ca.client(t, "kiosk-8", "globex")); err != nil || body != "cert:kiosk-8 globex" {
+		t.Errorf("default host: got %q, %v; want the tenant from the certificate", body, err)
+	}
+	if code, _, err := get("voice.example.net"); err != nil || code != http.StatusUnauthorized {
+		t.Errorf("without a client certificate: got %d, %v; want 401", code, err)
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("ServeTLS: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeTLS did not stop with its context")
+	}
+}
+
+func TestServerTLS_RequireClientCert(t *testing.T) {
+	ca := newTestCA(t)
+	caFile, _ := ca.files(t, "ca", ca.cert, ca.key)
+	certFile, keyFile := ca.server(t, "srv", "voice.example.net")
+	cfg, err := ServerTLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, RequireClientCert: true}.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert || cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("got client auth %v, min version %x", cfg.ClientAuth, cfg.MinVersion)
+	}
+
+	if _, err := (ServerTLS{}).Config(); err == nil {
+		t.Error("a config without certificates should be rejected")
+	}
+	if _, err := (ServerTLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile}).Config(); err == nil {
+		t.Error("a client CA file without certificates should be rejected")
+	}
+	if _, err := (ServerTLS{CertFile: certFile, KeyFile: keyFile, RequireClientCert: true}).Config(); err == nil {
+		t.Error("requiring client certificates without a client CA should be rejected")
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	hosts := map[string]bool{"acme.example.com": true, "*.globex.example.com": true}
+	cases := map[string]string{
+		"ACME.example.com.":       "acme.example.com",
+		"eu.globex.example.com":   "*.globex.example.com",
+		"a.eu.globex.example.com": "",
+		"globex.example.com":      "",
+		"":                        "",
+	}
+	for name, want := range cases {
+		if got := matchHost(hosts, name); got != want {
+			t.Errorf("matchHost(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
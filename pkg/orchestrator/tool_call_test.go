@@ -72,7 +72,7 @@ func TestManagedStream_ToolCalling(t *testing.T) {
 	stt := &MockSTTProvider{transcribeResult: "whats the weather?"}
 	tts := &MockTTSProvider{synthesizeResult: []byte{1, 2, 3}}
 
-	orch := NewWithAllLayers(stt, llm, tts, nil, DefaultConfig(), &NoOpLogger{})
+	orch := New(stt, llm, tts, nil, DefaultConfig(), &NoOpLogger{})
 
 	weatherCalled := false
 	orch.RegisterTool("get_weather", func(args string) (string, error) {
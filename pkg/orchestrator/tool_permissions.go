@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ToolAuditEntry records one attempted tool invocation.
+type ToolAuditEntry struct {
+	SessionID string        `json:"session_id"`
+	UserID    string        `json:"user_id,omitempty"`
+	TenantID  string        `json:"tenant_id,omitempty"`
+	Tool      string        `json:"tool"`
+	CallID    string        `json:"call_id"`
+	Arguments string        `json:"arguments"`
+	Allowed   bool          `json:"allowed"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	At        time.Time     `json:"at"`
+}
+
+// SetToolAuditHook registers fn to receive an entry for every tool call,
+// including ones refused by a session allowlist. fn must not block.
+func (o *Orchestrator) SetToolAuditHook(fn func(ToolAuditEntry)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.toolAudit = fn
+}
+
+// SetAllowedTools restricts which registered tools this session may invoke
+// and see. nil lifts the restriction; an empty non-nil slice allows none.
+func (s *ConversationSession) SetAllowedTools(names []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if names == nil {
+		s.AllowedTools = nil
+		return
+	}
+	s.AllowedTools = append([]string{}, names...)
+}
+
+// GrantTools adds names to an existing allowlist, e.g. once the caller has
+// authenticated. It has no effect on an unrestricted session.
+func (s *ConversationSession) GrantTools(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.AllowedTools == nil {
+		return
+	}
+	for _, n := range names {
+		if !containsString(s.AllowedTools, n) {
+			s.AllowedTools = append(s.AllowedTools, n)
+		}
+	}
+}
+
+// ToolAllowed reports whether the session may invoke the named tool.
+func (s *ConversationSession) ToolAllowed(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.toolAllowedLocked(name)
+}
+
+func (s *ConversationSession) toolAllowedLocked(name string) bool {
+	return s.AllowedTools == nil || containsString(s.AllowedTools, name)
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// toolName extracts the function name from a tool definition, whose
+// Function field may be a map or any JSON-marshalable struct.
+func toolName(t Tool) string {
+	switch f := t.Function.(type) {
+	case map[string]interface{}:
+		name, _ := f["name"].(string)
+		return name
+	case map[string]string:
+		return f["name"]
+	}
+	data, err := json.Marshal(t.Function)
+	if err != nil {
+		return ""
+	}
+	var named struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(data, &named)
+	return named.Name
+}
+
+// callTool checks the session allowlist, runs a registered tool under a key
+// scoped to this call ID and audits the outcome to the hook and onAudit.
+// found is false when no handler is registered.
+func (o *Orchestrator) callTool(ctx context.Context, session *ConversationSession, tc ToolCallEventData, onAudit func(ToolAuditEntry)) (result string, found bool, err error) {
+	o.mu.RLock()
+	handler, ok := o.toolHandlers[tc.Name]
+	audit := o.toolAudit
+	o.mu.RUnlock()
+
+	entry := ToolAuditEntry{Tool: tc.Name, CallID: tc.CallID, Arguments: tc.Arguments, At: time.Now()}
+	allowed := true
+	if session != nil {
+		entry.SessionID, entry.UserID, entry.TenantID = session.ID, session.UserID, session.GetTenantID()
+		allowed = session.ToolAllowed(tc.Name)
+	}
+	entry.Allowed = allowed && ok
+
+	switch {
+	case !ok:
+		entry.Error = "tool not found"
+	case !allowed:
+		err = ErrToolNotPermitted
+		entry.Error = err.Error()
+		o.logger.Warn("tool call refused by session allowlist", "sessionID", entry.SessionID, "tool", tc.Name)
+	default:
+		result, err = handler(scopeIdempotencyKey(ctx, "tool", tc.CallID, tc.Name), tc.Arguments)
+		if err != nil {
+			entry.Error = err.Error()
+		}
+	}
+	entry.Duration = time.Since(entry.At)
+
+	if audit != nil {
+		audit(entry)
+	}
+	if onAudit != nil {
+		onAudit(entry)
+	}
+	return result, ok, err
+}
@@ -0,0 +1,117 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSession_ToolAllowlist(t *testing.T) {
+	s := NewConversationSession("caller")
+	s.SetTools([]Tool{
+		{Type: "function", Function: map[string]interface{}{"name": "lookup_order"}},
+		{Type: "function", Function: struct {
+			Name string `json:"name"`
+		}{Name: "issue_refund"}},
+	})
+
+	if len(s.GetTools()) != 2 || !s.ToolAllowed("issue_refund") {
+		t.Fatal("sessions without an allowlist should see every tool")
+	}
+
+	s.SetAllowedTools([]string{"lookup_order"})
+	if tools := s.GetTools(); len(tools) != 1 || toolName(tools[0]) != "lookup_order" {
+		t.Errorf("expected only lookup_order to be offered, got %+v", tools)
+	}
+	if s.ToolAllowed("issue_refund") {
+		t.Error("issue_refund should be refused before authentication")
+	}
+
+	s.GrantTools("issue_refund")
+	if !s.ToolAllowed("issue_refund") || len(s.GetTools()) != 2 {
+		t.Error("granted tool should become available")
+	}
+
+	restored := RestoreSession(s.Snapshot())
+	if !restored.ToolAllowed("issue_refund") || restored.ToolAllowed("close_account") {
+		t.Errorf("allowlist should survive a snapshot, got %v", restored.AllowedTools)
+	}
+}
+
+func TestOrchestrator_ToolAudit(t *testing.T) {
+	orch := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, DefaultConfig(), nil)
+	refunds := 0
+	orch.RegisterTool("issue_refund", func(args string) (string, error) {
+		refunds++
+		return "refunded", nil
+	})
+
+	var audit []ToolAuditEntry
+	orch.SetToolAuditHook(func(e ToolAuditEntry) { audit = append(audit, e) })
+
+	session := NewConversationSession("caller")
+	session.TenantID = "acme"
+	session.SetAllowedTools([]string{})
+
+	call := ToolCallEventData{Name: "issue_refund", CallID: "c1", Arguments: `{"amount":10}`}
+	var streamed []ToolAuditEntry
+	_, found, err := orch.callTool(context.Background(), session, call, func(e ToolAuditEntry) { streamed = append(streamed, e) })
+	if !found || !errors.Is(err, ErrToolNotPermitted) {
+		t.Fatalf("expected ErrToolNotPermitted, got found=%v err=%v", found, err)
+	}
+	if refunds != 0 {
+		t.Fatal("refused tool must not run")
+	}
+
+	session.GrantTools("issue_refund")
+	if res, _, err := orch.callTool(context.Background(), session, call, nil); err != nil || res != "refunded" {
+		t.Fatalf("expected refund to run, got %q, %v", res, err)
+	}
+
+	if len(audit) != 2 || len(streamed) != 1 {
+		t.Fatalf("expected every call audited, got hook=%d stream=%d", len(audit), len(streamed))
+	}
+	if audit[0].Allowed || audit[0].Error == "" || audit[0].TenantID != "acme" {
+		t.Errorf("unexpected refusal entry: %+v", audit[0])
+	}
+	if !audit[1].Allowed || audit[1].Arguments != call.Arguments {
+		t.Errorf("unexpected success entry: %+v", audit[1])
+	}
+}
+
+func TestManagedStream_ToolAuditEvent(t *testing.T) {
+	llm := &MockStreamingLLM{responses: []struct {
+		content   string
+		toolCalls []ToolCallEventData
+	}{
+		{toolCalls: []ToolCallEventData{{Name: "close_account", CallID: "c1"}}},
+		{content: "I can't do that yet."},
+	}}
+	orch := New(&MockSTTProvider{}, llm, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig(), &NoOpLogger{})
+	orch.RegisterTool("close_account", func(string) (string, error) { return "closed", nil })
+
+	session := NewConversationSession("anon")
+	ApplyPersona(orch, session, Persona{AllowedTools: []string{"lookup_order"}})
+
+	ms := orch.NewManagedStream(context.Background(), session)
+	defer ms.Close()
+	go ms.runLLMAndTTS(context.Background(), "close my account")
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev := <-ms.Events():
+			if ev.Type != ToolAudit {
+				continue
+			}
+			entry := ev.Data.(ToolAuditEntry)
+			if entry.Allowed || entry.Tool != "close_account" {
+				t.Errorf("expected a refused close_account call, got %+v", entry)
+			}
+			return
+		case <-timeout:
+			t.Fatal("no ToolAudit event emitted")
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package orchestrator
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TrimStrategy decides which messages stay in a session's context once it
+// grows past MaxMessages. Trim is called with the session locked and must
+// return at most max messages, in their original order. An assistant
+// message calling tools and the tool results answering it must be kept or
+// dropped together: LLM APIs reject a result without its call and a call
+// without its results.
+type TrimStrategy interface {
+	Trim(messages []Message, max int) []Message
+}
+
+// ContextTrimStrategy is a TrimStrategy that does slow work, such as an LLM
+// call. A session never runs one with its lock held: the context may grow
+// past MaxMessages as messages are added, and is cut back with TrimContext
+// before the next LLM call, under that turn's context. The result replaces
+// the context only if no messages were added meanwhile.
+type ContextTrimStrategy interface {
+	TrimStrategy
+	TrimContext(ctx context.Context, messages []Message, max int) []Message
+}
+
+// DropOldestTrim keeps the most recent max messages. It is the default.
+type DropOldestTrim struct{}
+
+func (DropOldestTrim) Trim(messages []Message, max int) []Message {
+	if len(messages) <= max {
+		return messages
+	}
+	return messages[unitAfter(messages, len(messages)-max):]
+}
+
+// toolUnitEnd returns the end of the unit starting at i: past the tool
+// results following an assistant message that calls tools, else i+1.
+func toolUnitEnd(messages []Message, i int) int {
+	end := i + 1
+	if messages[i].Role != RoleAssistant || messages[i].ToolCalls == nil {
+		return end
+	}
+	for end < len(messages) && messages[end].Role == RoleTool {
+		end++
+	}
+	return end
+}
+
+// toolUnits splits messages into the units trimming keeps or drops whole,
+// returned as the index each starts at.
+func toolUnits(messages []Message) []int {
+	var starts []int
+	for i := 0; i < len(messages); i = toolUnitEnd(messages, i) {
+		starts = append(starts, i)
+	}
+	return starts
+}
+
+// unitAfter moves a cut at i forward to the next unit boundary, so the
+// messages from it on hold no tool results whose call was cut off.
+func unitAfter(messages []Message, i int) int {
+	for _, start := range toolUnits(messages) {
+		if start >= i {
+			return start
+		}
+	}
+	return len(messages)
+}
+
+// unitBefore moves a cut at i back to the previous unit boundary, so the
+// messages before it hold no tool call whose results were cut off.
+func unitBefore(messages []Message, i int) int {
+	cut := 0
+	for _, start := range toolUnits(messages) {
+		if start > i {
+			break
+		}
+		cut = start
+	}
+	return cut
+}
+
+// DropMiddleTrim keeps the first Head messages - typically the system prompt
+// and the opening exchange that sets up the conversation - and fills the rest
+// of the window with the most recent ones.
+type DropMiddleTrim struct {
+	Head int // Defaults to 2
+}
+
+func (d DropMiddleTrim) Trim(messages []Message, max int) []Message {
+	if len(messages) <= max {
+		return messages
+	}
+	head := d.Head
+	if head <= 0 {
+		head = 2
+	}
+	if head >= max {
+		return DropOldestTrim{}.Trim(messages, max)
+	}
+	head = unitBefore(messages, head)
+	tail := unitAfter(messages, len(messages)-(max-head))
+	out := make([]Message, 0, max)
+	out = append(out, messages[:head]...)
+	return append(out, messages[tail:]...)
+}
+
+// summaryPrefix marks the system message SummarizeTrim keeps its running
+// summary in.
+const summaryPrefix = "Summary of the earlier conversation: "
+
+// SummarizeTrim asks an LLM to condense the oldest messages into a single
+// system message instead of dropping them. It compacts down to about half
+// the window at a time, so the LLM is called once every few turns rather than
+// on every message. If the LLM fails the oldest messages are dropped. It is
+// a ContextTrimStrategy, so the session is not locked while it summarizes.
+type SummarizeTrim struct {
+	LLM     LLMProvider
+	Timeout time.Duration // Per summary; defaults to 10s
+}
+
+func (s SummarizeTrim) Trim(messages []Message, max int) []Message {
+	return s.TrimContext(context.Background(), messages, max)
+}
+
+// TrimContext summarizes under ctx, bounded by Timeout.
+func (s SummarizeTrim) TrimContext(ctx context.Context, messages []Message, max int) []Message {
+	if len(messages) <= max {
+		return messages
+	}
+	head := leadingSystem(messages)
+	keep := (max - head - 1) / 2
+	if s.LLM == nil || keep < 1 {
+		return DropOldestTrim{}.Trim(messages, max)
+	}
+	cut := unitAfter(messages, len(messages)-keep)
+	old := messages[head:cut]
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	summary, err := s.LLM.Complete(ctx, []Message{
+		{Role: RoleSystem, Content: "Summarize this conversation in a few sentences. Keep names, numbers, decisions and open requests; leave out small talk."},
+		{Role: RoleUser, Content: transcriptOf(old)},
+	}, nil)
+	if err != nil || strings.TrimSpace(summary) == "" {
+		return DropOldestTrim{}.Trim(messages, max)
+	}
+
+	out := make([]Message, 0, head+1+len(messages)-cut)
+	out = append(out, messages[:head]...)
+	out = append(out, Message{Role: RoleSystem, Content: summaryPrefix + strings.TrimSpace(summary)})
+	return append(out, messages[cut:]...)
+}
+
+func leadingSystem(messages []Message) int {
+	n := 0
+	for n < len(messages) && messages[n].Role == RoleSystem && !strings.HasPrefix(messages[n].Content, summaryPrefix) {
+		n++
+	}
+	return n
+}
+
+func transcriptOf(messages []Message) string {
+	var b strings.Builder
+	for _, m := range messages {
+		text := m.Text()
+		if strings.HasPrefix(text, summaryPrefix) {
+			text = strings.TrimPrefix(text, summaryPrefix)
+		}
+		if text == "" {
+			continue
+		}
+		b.WriteString(m.Role)
+		b.WriteString(": ")
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ImportanceTrim drops the lowest-scoring messages first. Score receives each
+// message and its age (0 for the newest); nil uses a default that favours
+// system messages and recent turns. A tool call and its results score as
+// their best message and go together. The newest message is always kept.
+type ImportanceTrim struct {
+	Score func(m Message, age int) float64
+}
+
+func (t ImportanceTrim) Trim(messages []Message, max int) []Message {
+	if len(messages) <= max {
+		return messages
+	}
+	if max <= 0 {
+		return nil
+	}
+	score := t.Score
+	if score == nil {
+		score = defaultImportance
+	}
+
+	n := len(messages)
+	starts := toolUnits(messages)
+	last := starts[len(starts)-1]
+	if n-last > max {
+		return DropOldestTrim{}.Trim(messages, max)
+	}
+	units := starts[:len(starts)-1]
+	scores := make(map[int]float64, len(units))
+	for _, start := range units {
+		best := score(messages[start], n-1-start)
+		for i := start + 1; i < toolUnitEnd(messages, start); i++ {
+			if sc := score(messages[i], n-1-i); sc > best {
+				best = sc
+			}
+		}
+		scores[start] = best
+	}
+	// Lowest score first; among equals the older unit goes first.
+	order := append([]int(nil), units...)
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] < scores[order[b]] })
+
+	drop := make(map[int]bool, n-max)
+	for _, start := range order {
+		if n-len(drop) <= max {
+			break
+		}
+		for i := start; i < toolUnitEnd(messages, start); i++ {
+			drop[i] = true
+		}
+	}
+	out := make([]Message, 0, max)
+	for i, m := range messages {
+		if !drop[i] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func defaultImportance(m Message, age int) float64 {
+	var base float64
+	switch m.Role {
+	case RoleSystem:
+		base = 10
+	case RoleUser:
+		base = 1
+	case RoleAssistant:
+		base = 0.8
+	default:
+		base = 0.5 // Tool output is rarely needed once answered
+	}
+	if len(m.Text()) < 20 {
+		base *= 0.5 // "ok", "thanks" and the like
+	}
+	return base / (1 + 0.1*float64(age))
+}
+
+func (s *ConversationSession) trimStrategy() TrimStrategy {
+	if s.TrimStrategy == nil {
+		return DropOldestTrim{}
+	}
+	return s.TrimStrategy
+}
+
+// promptContext returns a copy of the context for an LLM call, first
+// cutting it back to MaxMessages with a ContextTrimStrategy, which adding
+// messages leaves to be done here, outside the lock and under ctx.
+func (s *ConversationSession) promptContext(ctx context.Context) []Message {
+	s.mu.RLock()
+	strategy, slow := s.trimStrategy().(ContextTrimStrategy)
+	max := s.MaxMessages
+	messages := append([]Message(nil), s.Context...)
+	s.mu.RUnlock()
+	if !slow || len(messages) <= max {
+		return messages
+	}
+	trimmed := strategy.TrimContext(ctx, messages, max)
+	if !s.replaceContext(messages, trimmed) {
+		return s.GetContextCopy()
+	}
+	return trimmed
+}
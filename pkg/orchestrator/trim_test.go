@@ -0,0 +1,213 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func numbered(n int) []Message {
+	msgs := []Message{{Role: RoleSystem, Content: "You are a helpful booking assistant."}}
+	for i := 1; i < n; i++ {
+		role := RoleUser
+		if i%2 == 0 {
+			role = RoleAssistant
+		}
+		msgs = append(msgs, Message{Role: role, Content: fmt.Sprintf("message number %d of the chat", i)})
+	}
+	return msgs
+}
+
+func contents(msgs []Message) []string {
+	out := make([]string, len(msgs))
+	for i, m := range msgs {
+		out[i] = m.Content
+	}
+	return out
+}
+
+func TestDropOldestTrim_MatchesPreviousBehaviour(t *testing.T) {
+	s := NewConversationSession("u")
+	s.MaxMessages = 3
+	for _, m := range numbered(5) {
+		s.AddMessageRaw(m)
+	}
+	got := contents(s.GetContextCopy())
+	if len(got) != 3 || got[0] != "message number 2 of the chat" {
+		t.Errorf("expected the 3 newest messages, got %q", got)
+	}
+}
+
+func TestDropMiddleTrim_KeepsHead(t *testing.T) {
+	got := DropMiddleTrim{}.Trim(numbered(10), 5)
+	if len(got) != 5 {
+		t.Fatalf("expected 5 messages, got %d", len(got))
+	}
+	if got[0].Role != RoleSystem || got[1].Content != "message number 1 of the chat" {
+		t.Errorf("head not kept: %q", contents(got))
+	}
+	if got[4].Content != "message number 9 of the chat" || got[2].Content != "message number 7 of the chat" {
+		t.Errorf("tail not kept: %q", contents(got))
+	}
+}
+
+type summaryLLM struct {
+	prompts []string
+	err     error
+}
+
+func (s *summaryLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	s.prompts = append(s.prompts, messages[len(messages)-1].Content)
+	return "The user wants a table for two.", s.err
+}
+
+func (s *summaryLLM) Name() string { return "summary" }
+
+func TestSummarizeTrim(t *testing.T) {
+	llm := &summaryLLM{}
+	got := SummarizeTrim{LLM: llm}.Trim(numbered(11), 10)
+
+	if got[0].Content != "You are a helpful booking assistant." {
+		t.Errorf("system prompt must stay first, got %q", got[0].Content)
+	}
+	if got[1].Role != RoleSystem || got[1].Content != summaryPrefix+"The user wants a table for two." {
+		t.Errorf("expected summary message second, got %+v", got[1])
+	}
+	if len(got) > 10 || got[len(got)-1].Content != "message number 10 of the chat" {
+		t.Errorf("unexpected trimmed context %q", contents(got))
+	}
+	if len(llm.prompts) != 1 || !strings.Contains(llm.prompts[0], "user: message number 1 of the chat") {
+		t.Errorf("expected the dropped messages to be summarised, got %q", llm.prompts)
+	}
+	if strings.Contains(llm.prompts[0], "helpful booking assistant") {
+		t.Error("the system prompt must not be summarised away")
+	}
+
+	// A later compaction folds the previous summary into the new one.
+	more := append(got, numbered(8)[1:]...)
+	SummarizeTrim{LLM: llm}.Trim(more, 10)
+	if !strings.Contains(llm.prompts[1], "system: The user wants a table for two.") {
+		t.Errorf("expected the running summary to be resummarised, got %q", llm.prompts[1])
+	}
+}
+
+func TestSummarizeTrim_FallsBackOnError(t *testing.T) {
+	got := SummarizeTrim{LLM: &summaryLLM{err: errors.New("down")}}.Trim(numbered(11), 10)
+	if len(got) != 10 || got[0].Content != "message number 1 of the chat" {
+		t.Errorf("expected drop-oldest fallback, got %q", contents(got))
+	}
+}
+
+func TestSummarizeTrim_RunsUnlockedUnderTheTurnContext(t *testing.T) {
+	llm := &blockingLLM{started: make(chan struct{})}
+	s := NewConversationSession("u")
+	s.MaxMessages = 10
+	s.TrimStrategy = SummarizeTrim{LLM: llm, Timeout: time.Minute}
+	for _, m := range numbered(11) {
+		s.AddMessageRaw(m)
+	}
+	if n := len(s.GetContextCopy()); n != 11 {
+		t.Fatalf("adding a message trimmed to %d messages; the summary should wait for the next LLM call", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []Message)
+	go func() { done <- s.promptContext(ctx) }()
+	<-llm.started
+	// The session stays readable while the summary runs.
+	if n := len(s.GetContextCopy()); n != 11 {
+		t.Errorf("context has %d messages mid-summary", n)
+	}
+	cancel()
+	select {
+	case got := <-done:
+		if len(got) != 10 || len(s.GetContextCopy()) != 10 {
+			t.Errorf("expected drop-oldest once the turn was cancelled, got %q", contents(got))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelling the turn did not stop the summary")
+	}
+}
+
+func TestImportanceTrim(t *testing.T) {
+	msgs := []Message{
+		{Role: RoleSystem, Content: "You are a helpful booking assistant."},
+		{Role: RoleUser, Content: "I need a table for four on Friday at eight"},
+		{Role: RoleAssistant, Content: "ok"},
+		{Role: RoleTool, Content: `{"available": true, "slots": ["20:00"]}`},
+		{Role: RoleUser, Content: "thanks"},
+	}
+	got := ImportanceTrim{}.Trim(msgs, 3)
+	want := []string{"You are a helpful booking assistant.", "I need a table for four on Friday at eight", "thanks"}
+	if strings.Join(contents(got), "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", contents(got), want)
+	}
+}
+
+// toolExchange is a conversation with a tool call answered by two results
+// in the middle of it.
+func toolExchange() []Message {
+	return []Message{
+		{Role: RoleSystem, Content: "You are a helpful booking assistant."},
+		{Role: RoleUser, Content: "Hi, I would like to book a table"},
+		{Role: RoleAssistant, Content: "Sure, for which day?"},
+		{Role: RoleUser, Content: "Friday, either at seven or at eight"},
+		{Role: RoleAssistant, ToolCalls: []map[string]string{{"id": "call_1"}, {"id": "call_2"}}},
+		{Role: RoleTool, ToolCallID: "call_1", Content: `{"time": "19:00", "available": false}`},
+		{Role: RoleTool, ToolCallID: "call_2", Content: `{"time": "20:00", "available": true}`},
+		{Role: RoleAssistant, Content: "Eight o'clock is free on Friday."},
+		{Role: RoleUser, Content: "Great, book it please"},
+	}
+}
+
+func TestTrimStrategies_KeepToolCallsWithResults(t *testing.T) {
+	strategies := []struct {
+		name     string
+		strategy TrimStrategy
+		max      int
+	}{
+		{"DropOldest", DropOldestTrim{}, 4},
+		{"DropMiddle tail", DropMiddleTrim{Head: 1}, 5},
+		{"DropMiddle head", DropMiddleTrim{Head: 5}, 7},
+		{"Summarize", SummarizeTrim{LLM: &summaryLLM{}}, 8},
+		{"Importance", ImportanceTrim{}, 5},
+	}
+	for _, tc := range strategies {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.strategy.Trim(toolExchange(), tc.max)
+			if len(got) > tc.max {
+				t.Errorf("kept %d messages, max %d", len(got), tc.max)
+			}
+			for i, m := range got {
+				if m.Role == RoleTool && (i == 0 || (got[i-1].Role != RoleTool && got[i-1].ToolCalls == nil)) {
+					t.Errorf("tool result %s kept without its call: %q", m.ToolCallID, contents(got))
+				}
+				if m.ToolCalls != nil && (i+2 >= len(got) || got[i+1].ToolCallID != "call_1" || got[i+2].ToolCallID != "call_2") {
+					t.Errorf("tool call kept without its results: %q", contents(got))
+				}
+			}
+			if last := got[len(got)-1]; last.Content != "Great, book it please" {
+				t.Errorf("newest message dropped: %q", contents(got))
+			}
+		})
+	}
+}
+
+func TestTrimStrategy_FromConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxContextMessages = 4
+	cfg.TrimStrategy = DropMiddleTrim{Head: 1}
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, nil, cfg)
+	s := o.NewSessionWithDefaults("u")
+	o.SetSystemPrompt(s, "Be brief.")
+	for i := 0; i < 6; i++ {
+		s.AddMessage(RoleUser, fmt.Sprintf("turn %d", i))
+	}
+	ctx := s.GetContextCopy()
+	if len(ctx) != 4 || ctx[0].Role != RoleSystem || ctx[3].Content != "turn 5" {
+		t.Errorf("expected the configured strategy to keep the system prompt, got %q", contents(ctx))
+	}
+}
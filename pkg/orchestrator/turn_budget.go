@@ -0,0 +1,141 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TurnBudget bounds a whole turn, from the end of the user's speech to the
+// first audio of the reply, and shares what is left of it between the
+// stages as the turn goes: a slow transcription leaves the LLM less time
+// and fewer tokens, where fixed per-stage timeouts could add up to a wait
+// no caller puts up with. A stage that runs out of time fails with
+// ErrTurnBudgetExceeded. Streaming stages only need their first output in
+// time; the rest of the reply plays out past the budget.
+type TurnBudget struct {
+	Total           time.Duration // 0 disables
+	LLMReserve      time.Duration // Kept back from STT for the LLM, and the least the LLM is given; defaults to 40% of Total
+	TTSReserve      time.Duration // Kept back from earlier stages for the first audio, and the least TTS is given; defaults to 20% of Total
+	TokensPerSecond float64       // How fast the LLM generates, to cap a non-streamed reply to the time left to it; 0 does not cap
+	MinTokens       int           // The least the cap goes to; defaults to 16
+}
+
+func (b TurnBudget) reserves() (llm, tts time.Duration) {
+	llm, tts = b.LLMReserve, b.TTSReserve
+	if llm <= 0 {
+		llm = b.Total * 2 / 5
+	}
+	if tts <= 0 {
+		tts = b.Total / 5
+	}
+	return llm, tts
+}
+
+// stageDeadline returns when stage must have produced its first output for
+// a turn due to end at end: whatever the stages after it do not need, but
+// never less than its own reserve.
+func (b TurnBudget) stageDeadline(stage Stage, end, now time.Time) time.Time {
+	llm, tts := b.reserves()
+	switch stage {
+	case StageSTT:
+		return end.Add(-llm - tts)
+	case StageLLM:
+		return maxTime(end.Add(-tts), now.Add(llm))
+	case StageTTS:
+		return maxTime(end, now.Add(tts))
+	}
+	return end
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+type turnDeadlineKey struct{}
+
+// withTurnDeadline starts the turn budget's clock at start, when the user
+// stopped speaking. A context already carrying a deadline keeps it.
+func (o *Orchestrator) withTurnDeadline(ctx context.Context, start time.Time) context.Context {
+	b := o.GetConfig().TurnBudget
+	if b.Total <= 0 || start.IsZero() {
+		return ctx
+	}
+	if _, ok := ctx.Value(turnDeadlineKey{}).(time.Time); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, turnDeadlineKey{}, start.Add(b.Total))
+}
+
+// stageBudget bounds stage by the time the turn budget leaves it. The
+// context is cancelled when that runs out unless started is called first,
+// which streaming stages do on their first output. Call release when the
+// stage is done. Outside a budgeted turn ctx is returned as it is.
+func (o *Orchestrator) stageBudget(ctx context.Context, stage Stage) (_ context.Context, started, release func()) {
+	end, ok := ctx.Value(turnDeadlineKey{}).(time.Time)
+	if !ok {
+		return ctx, func() {}, func() {}
+	}
+	b := o.GetConfig().TurnBudget
+	now := time.Now()
+	deadline := b.stageDeadline(stage, end, now)
+	ctx, cancel := context.WithCancelCause(ctx)
+	t := time.AfterFunc(time.Until(deadline), func() {
+		o.logger.Warn("turn budget exceeded", "stage", stage, "budget", b.Total)
+		cancel(fmt.Errorf("%w: %s", ErrTurnBudgetExceeded, stage))
+	})
+	return ctx, func() { t.Stop() }, func() {
+		t.Stop()
+		cancel(nil)
+	}
+}
+
+// budgetTokens caps a non-streamed LLM reply to the tokens TokensPerSecond
+// allows in the time the turn budget leaves the LLM, as the whole reply must
+// arrive by then. Streamed replies are not capped: only their first output
+// has to be in time, and capping them would cut the rest short.
+func (o *Orchestrator) budgetTokens(ctx context.Context) context.Context {
+	end, ok := ctx.Value(turnDeadlineKey{}).(time.Time)
+	b := o.GetConfig().TurnBudget
+	if !ok || b.TokensPerSecond <= 0 || MaxTokensFromContext(ctx) != 0 {
+		return ctx
+	}
+	now := time.Now()
+	minTokens := b.MinTokens
+	if minTokens <= 0 {
+		minTokens = 16
+	}
+	window := b.stageDeadline(StageLLM, end, now).Sub(now)
+	return WithMaxTokens(ctx, max(int(window.Seconds()*b.TokensPerSecond), minTokens))
+}
+
+// budgetErr reports a stage cut short by the turn budget as such, rather
+// than as the cancellation the provider saw.
+func budgetErr(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if cause := context.Cause(ctx); errors.Is(cause, ErrTurnBudgetExceeded) {
+		return cause
+	}
+	return err
+}
+
+type maxTokensKey struct{}
+
+// WithMaxTokens asks LLM providers to stop a reply after n tokens.
+func WithMaxTokens(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, maxTokensKey{}, n)
+}
+
+// MaxTokensFromContext returns the token limit attached to ctx, or 0 for
+// none. Providers that find one should send it as the request's maximum
+// output, in place of any default they would send.
+func MaxTokensFromContext(ctx context.Context) int {
+	n, _ := ctx.Value(maxTokensKey{}).(int)
+	return n
+}
@@ -0,0 +1,128 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stuckSTT never answers, returning only when its context ends.
+type stuckSTT struct{}
+
+func (stuckSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	<-ctx.Done()
+	return TranscriptionResult{}, ctx.Err()
+}
+
+func (stuckSTT) Name() string { return "stuck" }
+
+// tokenLLM records the token limit it was asked for.
+type tokenLLM struct {
+	maxTokens int
+}
+
+func (l *tokenLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	l.maxTokens = MaxTokensFromContext(ctx)
+	return "Forty dollars.", nil
+}
+
+func (l *tokenLLM) Name() string { return "token" }
+
+func TestTurnBudget_SlowSTTCapsTokens(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TurnBudget = TurnBudget{Total: time.Second, LLMReserve: 200 * time.Millisecond, TTSReserve: 200 * time.Millisecond, TokensPerSecond: 100}
+	llm := &tokenLLM{}
+	o := NewWithVAD(slowSTT{delay: 300 * time.Millisecond}, llm, &MockTTSProvider{}, nil, cfg)
+
+	if _, _, err := o.ProcessAudio(context.Background(), NewConversationSession("u"), make([]byte, 3200), false, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 300ms went to STT and 200ms is kept for TTS, leaving the LLM 500ms.
+	if llm.maxTokens < 40 || llm.maxTokens > 52 {
+		t.Errorf("LLM was allowed %d tokens, want about 50", llm.maxTokens)
+	}
+}
+
+func TestTurnBudget_StageRunsOut(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TurnBudget = TurnBudget{Total: 500 * time.Millisecond, LLMReserve: 200 * time.Millisecond, TTSReserve: 100 * time.Millisecond}
+	o := NewWithVAD(stuckSTT{}, &tokenLLM{}, &MockTTSProvider{}, nil, cfg)
+
+	start := time.Now()
+	_, _, err := o.ProcessAudio(context.Background(), NewConversationSession("u"), make([]byte, 3200), false, nil)
+	if !errors.Is(err, ErrTurnBudgetExceeded) {
+		t.Fatalf("got %v", err)
+	}
+	if d := time.Since(start); d > 400*time.Millisecond {
+		t.Errorf("STT ran %v, past its 200ms share", d)
+	}
+}
+
+func TestTurnBudget_StageDeadlines(t *testing.T) {
+	b := TurnBudget{Total: time.Second}
+	now := time.Now()
+	end := now.Add(time.Second)
+	if got := b.stageDeadline(StageSTT, end, now).Sub(now); got != 400*time.Millisecond {
+		t.Errorf("STT gets %v, want what the 40%% and 20%% reserves leave", got)
+	}
+	// Late in the turn the LLM is still given its reserve.
+	late := end.Add(-100 * time.Millisecond)
+	if got := b.stageDeadline(StageLLM, end, late).Sub(late); got != 400*time.Millisecond {
+		t.Errorf("LLM gets %v when late, want its reserve", got)
+	}
+	if ctx, _, release := NewWithVAD(stuckSTT{}, &tokenLLM{}, &MockTTSProvider{}, nil, DefaultConfig()).stageBudget(context.Background(), StageLLM); ctx != context.Background() {
+		t.Error("a turn without a budget got a stage deadline")
+	} else {
+		release()
+	}
+}
+
+// wordStreamLLM streams its reply a word at a time, every delay, stopping
+// early at the token limit it was asked for.
+type wordStreamLLM struct {
+	tokenLLM
+	words []string
+	delay time.Duration
+}
+
+func (l *wordStreamLLM) StreamComplete(ctx context.Context, messages []Message, tools []Tool, onChunk func(string) error, onToolCall func(ToolCallEventData) error) (string, error) {
+	l.maxTokens = MaxTokensFromContext(ctx)
+	var reply string
+	for i, w := range l.words {
+		if l.maxTokens > 0 && i == l.maxTokens {
+			break
+		}
+		if i > 0 {
+			time.Sleep(l.delay)
+			w = " " + w
+		}
+		if err := onChunk(w); err != nil {
+			return reply, err
+		}
+		reply += w
+	}
+	return reply, nil
+}
+
+func TestTurnBudget_StreamedReplyIsNotCapped(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TurnBudget = TurnBudget{Total: 300 * time.Millisecond, TokensPerSecond: 50}
+	words := strings.Fields(strings.Repeat("and then the table is yours for the evening. ", 5))
+	llm := &wordStreamLLM{words: words, delay: 5 * time.Millisecond}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "tell me everything"}, llm, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, cfg)
+
+	// The 45 words take over 200ms, past the LLM's 120ms to first output,
+	// where 50 tokens a second would have allowed only 16.
+	session := NewConversationSession("u")
+	if _, _, err := o.ProcessAudio(context.Background(), session, make([]byte, 3200), true, func([]byte) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if llm.maxTokens != 0 {
+		t.Errorf("streamed reply was capped at %d tokens", llm.maxTokens)
+	}
+	if got := session.LastAssistant; got != strings.Join(words, " ") {
+		t.Errorf("reply was cut short: %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+)
+
+// TurnHandle is a ProcessAudio turn running in the background, so it can be
+// cancelled mid-flight, typically because the user has started talking over
+// the answer.
+type TurnHandle struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex // Held while audio is handed to the caller
+	cancelled bool
+
+	transcript string
+	audio      []byte
+	err        error
+}
+
+// StartTurn begins a ProcessAudio turn and returns without waiting for it.
+// With a non-nil onAudioChunk the reply is streamed as in ProcessAudioStream;
+// otherwise Wait returns it as one buffer.
+//
+// Cancelling stops STT, the LLM and TTS wherever they are. No audio reaches
+// onAudioChunk once Cancel has returned, and the session's context is put
+// back the way it was before the turn, dropping the half-processed exchange.
+func (o *Orchestrator) StartTurn(ctx context.Context, session *ConversationSession, audioData []byte, onAudioChunk func([]byte) error) *TurnHandle {
+	ctx, cancel := context.WithCancel(ctx)
+	h := &TurnHandle{cancel: cancel, done: make(chan struct{})}
+
+	var send func([]byte) error
+	if onAudioChunk != nil {
+		send = func(chunk []byte) error {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if h.cancelled {
+				return ErrTurnCancelled
+			}
+			return onAudioChunk(chunk)
+		}
+	}
+
+	go func() {
+		defer close(h.done)
+		defer cancel()
+		transcript, audio, err := o.ProcessAudio(ctx, session, audioData, send != nil, send)
+		h.mu.Lock()
+		if h.cancelled && err != nil {
+			err = ErrTurnCancelled
+		}
+		h.transcript, h.audio, h.err = transcript, audio, err
+		h.mu.Unlock()
+	}()
+	return h
+}
+
+// Cancel stops the turn. It is safe to call more than once and after the
+// turn has finished, in which case it does nothing.
+func (h *TurnHandle) Cancel() {
+	select {
+	case <-h.done:
+		return
+	default:
+	}
+	h.mu.Lock()
+	h.cancelled = true
+	h.mu.Unlock()
+	h.cancel()
+}
+
+// Done is closed once the turn has finished or been cancelled.
+func (h *TurnHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the turn ends and returns what ProcessAudio returned, or
+// ErrTurnCancelled if Cancel stopped it. A turn that completed just as it was
+// cancelled returns its result.
+func (h *TurnHandle) Wait() (string, []byte, error) {
+	<-h.done
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.transcript, h.audio, h.err
+}
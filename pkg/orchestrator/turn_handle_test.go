@@ -0,0 +1,106 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingLLM struct {
+	started chan struct{}
+}
+
+func (b *blockingLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	close(b.started)
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (b *blockingLLM) Name() string { return "blocking" }
+
+func TestTurnHandle_CancelDuringLLM(t *testing.T) {
+	llm := &blockingLLM{started: make(chan struct{})}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "book a table"}, llm, &MockTTSProvider{}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+	session.AddMessage(RoleUser, "hi")
+	session.AddMessage(RoleAssistant, "Hello, how can I help?")
+
+	h := o.StartTurn(context.Background(), session, []byte{1}, nil)
+	<-llm.started
+	h.Cancel()
+
+	if _, _, err := h.Wait(); !errors.Is(err, ErrTurnCancelled) {
+		t.Errorf("expected ErrTurnCancelled, got %v", err)
+	}
+	if ctx := session.GetContextCopy(); len(ctx) != 2 || session.LastUser != "hi" {
+		t.Errorf("a cancelled turn must leave the context untouched, got %q", contents(ctx))
+	}
+}
+
+type endlessTTS struct {
+	MockTTSProvider
+	streaming chan struct{}
+	once      sync.Once
+}
+
+func (e *endlessTTS) StreamSynthesize(ctx context.Context, text string, voice Voice, lang Language, onChunk func([]byte) error) error {
+	for {
+		if err := onChunk([]byte{1}); err != nil {
+			return err
+		}
+		e.once.Do(func() { close(e.streaming) })
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestTurnHandle_CancelStopsAudio(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EagerSynthesis.Enabled = true // Streams TTS for a batch LLM
+	tts := &endlessTTS{streaming: make(chan struct{})}
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "tell me a story"}, &MockLLMProvider{completeResult: "Once upon a time."}, tts, nil, cfg)
+	session := NewConversationSession("u")
+
+	var mu sync.Mutex
+	chunks := 0
+	h := o.StartTurn(context.Background(), session, []byte{1}, func([]byte) error {
+		mu.Lock()
+		chunks++
+		mu.Unlock()
+		return nil
+	})
+	<-tts.streaming
+	h.Cancel()
+	mu.Lock()
+	heard := chunks
+	mu.Unlock()
+
+	<-h.Done()
+	mu.Lock()
+	defer mu.Unlock()
+	if chunks != heard {
+		t.Errorf("audio kept arriving after Cancel: %d chunks, then %d", heard, chunks)
+	}
+	if n := len(session.GetContextCopy()); n != 0 || session.LastAssistant != "" {
+		t.Errorf("the unheard answer must be rolled back, got %d messages", n)
+	}
+}
+
+func TestTurnHandle_Completes(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: "hi"}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+	h := o.StartTurn(context.Background(), session, []byte{1}, nil)
+	transcript, audio, err := h.Wait()
+	if err != nil || transcript != "hello there" || len(audio) != 1 {
+		t.Errorf("got %q, %v, %v", transcript, audio, err)
+	}
+	h.Cancel() // No-op once finished
+	if session.LastAssistant != "hi" {
+		t.Errorf("completed turn lost: %q", session.LastAssistant)
+	}
+}
@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// TurnQueuePolicy decides what happens to a turn that arrives while another
+// turn of the same session is still running. Turns of one session never run
+// at the same time, whatever the policy.
+type TurnQueuePolicy string
+
+const (
+	// TurnQueueSerial runs every turn, one after another in arrival order.
+	TurnQueueSerial TurnQueuePolicy = "queue"
+	// TurnQueueDropOldest keeps only the newest waiting turn; the ones it
+	// replaces fail with ErrTurnSuperseded.
+	TurnQueueDropOldest TurnQueuePolicy = "drop_oldest"
+	// TurnQueueMerge joins the audio of waiting turns into the newest one, so
+	// an utterance split in two is answered once, as a whole. The turns
+	// merged away fail with ErrTurnMerged. Turns without up-front audio (text
+	// and chunked audio turns) are queued instead.
+	TurnQueueMerge TurnQueuePolicy = "merge"
+)
+
+type queuedTurn struct {
+	audio []byte
+	ready chan struct{}
+	err   error // Why the turn was dropped; set before ready is closed
+}
+
+// turnQueue serializes the turns of one session.
+type turnQueue struct {
+	mu      sync.Mutex
+	running bool
+	pending []*queuedTurn
+}
+
+// enter waits until the session is free to run a turn with audio. It
+// returns the audio the turn should process, which under TurnQueueMerge
+// includes that of the turns merged into it, and a function that must be
+// called when the turn ends.
+func (q *turnQueue) enter(ctx context.Context, policy TurnQueuePolicy, audio []byte) (func(), []byte, error) {
+	q.mu.Lock()
+	if !q.running && len(q.pending) == 0 {
+		q.running = true
+		q.mu.Unlock()
+		return q.leave, audio, nil
+	}
+
+	t := &queuedTurn{audio: audio, ready: make(chan struct{})}
+	switch policy {
+	case TurnQueueDropOldest:
+		for _, p := range q.pending {
+			p.err = ErrTurnSuperseded
+			close(p.ready)
+		}
+		q.pending = nil
+	case TurnQueueMerge:
+		if audio != nil {
+			var carried []byte
+			kept := q.pending[:0]
+			for _, p := range q.pending {
+				if p.audio == nil {
+					kept = append(kept, p)
+					continue
+				}
+				carried = append(carried, p.audio...)
+				p.err = ErrTurnMerged
+				close(p.ready)
+			}
+			q.pending = kept
+			t.audio = append(carried, audio...)
+		}
+	}
+	q.pending = append(q.pending, t)
+	q.mu.Unlock()
+
+	select {
+	case <-t.ready:
+		if t.err != nil {
+			return nil, nil, t.err
+		}
+		return q.leave, t.audio, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		for i, p := range q.pending {
+			if p == t {
+				q.pending = append(q.pending[:i], q.pending[i+1:]...)
+				q.mu.Unlock()
+				return nil, nil, ctx.Err()
+			}
+		}
+		q.mu.Unlock()
+		// Dropped, or handed the session while giving up; pass it on.
+		if t.err == nil {
+			q.leave()
+		}
+		return nil, nil, ctx.Err()
+	}
+}
+
+// idle waits until the turns running or queued when it is called are done.
+func (q *turnQueue) idle(ctx context.Context) error {
+	for {
+		leave, _, err := q.enter(ctx, TurnQueueSerial, nil)
+		if errors.Is(err, ErrTurnSuperseded) {
+			continue // A newer turn dropped the wait along with the queue
+		}
+		if err != nil {
+			return err
+		}
+		leave()
+		return nil
+	}
+}
+
+// leave hands the session to the next waiting turn.
+func (q *turnQueue) leave() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) > 0 {
+		next := q.pending[0]
+		q.pending = q.pending[1:]
+		close(next.ready)
+		return
+	}
+	q.running = false
+}
@@ -0,0 +1,158 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// textSTT "transcribes" audio by reading its bytes as text.
+type textSTT struct{}
+
+func (textSTT) Transcribe(ctx context.Context, audio []byte, lang Language) (TranscriptionResult, error) {
+	return TranscriptionResult{Text: string(audio)}, nil
+}
+
+func (textSTT) Name() string { return "text" }
+
+// heldLLM answers each turn with its user message once released.
+type heldLLM struct {
+	started chan string
+	release chan struct{}
+}
+
+func (h *heldLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	said := messages[len(messages)-1].Content
+	h.started <- said
+	select {
+	case <-h.release:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	return "re: " + said, nil
+}
+
+func (h *heldLLM) Name() string { return "held" }
+
+type turnResult struct {
+	transcript string
+	err        error
+}
+
+// queueTurns starts a held turn with "first" and then one turn per later
+// utterance, each queued before the next arrives.
+func queueTurns(t *testing.T, policy TurnQueuePolicy, later ...string) (*ConversationSession, *heldLLM, []chan turnResult) {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.TurnQueue = policy
+	llm := &heldLLM{started: make(chan string, 8), release: make(chan struct{})}
+	o := NewWithVAD(textSTT{}, llm, &MockTTSProvider{}, nil, cfg)
+	session := NewConversationSession("u")
+
+	run := func(said string) chan turnResult {
+		done := make(chan turnResult, 1)
+		go func() {
+			transcript, _, err := o.ProcessAudio(context.Background(), session, []byte(said), false, nil)
+			done <- turnResult{transcript, err}
+		}()
+		return done
+	}
+	results := []chan turnResult{run("first")}
+	if got := <-llm.started; got != "first" {
+		t.Fatalf("expected the first turn to start, got %q", got)
+	}
+	for _, said := range later {
+		results = append(results, run(said))
+		deadline := time.Now().Add(time.Second)
+		for !lastQueued(session, said) {
+			if time.Now().After(deadline) {
+				t.Fatalf("turn %q never queued", said)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return session, llm, results
+}
+
+// lastQueued reports whether the newest waiting turn ends with said.
+func lastQueued(session *ConversationSession, said string) bool {
+	session.turns.mu.Lock()
+	defer session.turns.mu.Unlock()
+	if len(session.turns.pending) == 0 {
+		return false
+	}
+	last := session.turns.pending[len(session.turns.pending)-1]
+	return len(last.audio) >= len(said) && string(last.audio[len(last.audio)-len(said):]) == said
+}
+
+func TestTurnQueue_Serial(t *testing.T) {
+	session, llm, results := queueTurns(t, TurnQueueSerial, "second turn", "third turn")
+	for _, want := range []string{"second turn", "third turn"} {
+		llm.release <- struct{}{}
+		if got := <-llm.started; got != want {
+			t.Fatalf("expected %q next, got %q", want, got)
+		}
+	}
+	llm.release <- struct{}{}
+	for _, r := range results {
+		if res := <-r; res.err != nil {
+			t.Fatalf("turn %q: %v", res.transcript, res.err)
+		}
+	}
+	want := []string{"first", "re: first", "second turn", "re: second turn", "third turn", "re: third turn"}
+	if got := contents(session.GetContextCopy()); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected turns to complete in order, got %q", got)
+	}
+}
+
+func TestTurnQueue_DropOldest(t *testing.T) {
+	session, llm, results := queueTurns(t, TurnQueueDropOldest, "second turn", "third turn")
+	if res := <-results[1]; !errors.Is(res.err, ErrTurnSuperseded) {
+		t.Fatalf("expected the second turn to be superseded, got %v", res.err)
+	}
+	llm.release <- struct{}{}
+	if got := <-llm.started; got != "third turn" {
+		t.Fatalf("expected the newest turn to run, got %q", got)
+	}
+	llm.release <- struct{}{}
+	if res := <-results[2]; res.err != nil {
+		t.Fatal(res.err)
+	}
+	if n := len(session.GetContextCopy()); n != 4 {
+		t.Errorf("expected two answered turns, got %d messages", n)
+	}
+}
+
+func TestTurnQueue_Merge(t *testing.T) {
+	_, llm, results := queueTurns(t, TurnQueueMerge, "book a table", " for two")
+	if res := <-results[1]; !errors.Is(res.err, ErrTurnMerged) {
+		t.Fatalf("expected the second turn to be merged, got %v", res.err)
+	}
+	llm.release <- struct{}{}
+	if got := <-llm.started; got != "book a table for two" {
+		t.Fatalf("expected one turn with both utterances, got %q", got)
+	}
+	llm.release <- struct{}{}
+	if res := <-results[2]; res.err != nil || res.transcript != "book a table for two" {
+		t.Fatalf("merged turn: %q, %v", res.transcript, res.err)
+	}
+}
+
+func TestTurnQueue_CancelWhileWaiting(t *testing.T) {
+	var q turnQueue
+	leave, _, err := q.enter(context.Background(), TurnQueueSerial, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := q.enter(ctx, TurnQueueSerial, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled wait to fail, got %v", err)
+	}
+	leave()
+	if _, _, err := q.enter(context.Background(), TurnQueueSerial, nil); err != nil {
+		t.Fatalf("the session must be free again, got %v", err)
+	}
+}
@@ -25,7 +25,8 @@ func (n *NoOpLogger) Error(msg string, args ...interface{}) {}
 
 type TranscriptionResult struct {
 	Text         string
-	NoSpeechProb float64 // Probability that the audio contains no speech (0.0 to 1.0)
+	NoSpeechProb float64      // Probability that the audio contains no speech (0.0 to 1.0)
+	Words        []WordTiming // Word timings, for providers that report them
 }
 
 type STTProvider interface {
@@ -80,18 +81,39 @@ type VADEvent struct {
 type EventType string
 
 const (
-	UserSpeaking      EventType = "USER_SPEAKING"
-	UserStopped       EventType = "USER_STOPPED"
-	TranscriptPartial EventType = "TRANSCRIPT_PARTIAL"
-	TranscriptFinal   EventType = "TRANSCRIPT_FINAL"
-	BotThinking       EventType = "BOT_THINKING"
-	BotResponse       EventType = "BOT_RESPONSE"
-	BotSpeaking       EventType = "BOT_SPEAKING"
-	Interrupted       EventType = "INTERRUPTED"
-	BotResumed        EventType = "BOT_RESUMED"
-	AudioChunk        EventType = "AUDIO_CHUNK"
-	ToolCall          EventType = "TOOL_CALL"
-	ErrorEvent        EventType = "ERROR"
+	UserSpeaking          EventType = "USER_SPEAKING"
+	UserStopped           EventType = "USER_STOPPED"
+	TranscriptPartial     EventType = "TRANSCRIPT_PARTIAL"
+	TranscriptFinal       EventType = "TRANSCRIPT_FINAL"
+	BotThinking           EventType = "BOT_THINKING"
+	BotResponse           EventType = "BOT_RESPONSE"
+	BotSpeaking           EventType = "BOT_SPEAKING"
+	Interrupted           EventType = "INTERRUPTED"
+	BotResumed            EventType = "BOT_RESUMED"
+	AudioChunk            EventType = "AUDIO_CHUNK"
+	ToolCall              EventType = "TOOL_CALL"
+	ToolAudit             EventType = "TOOL_AUDIT"
+	ToolResult            EventType = "TOOL_RESULT" // Async tool finished
+	CallAnswered          EventType = "CALL_ANSWERED"
+	VoicemailBeep         EventType = "VOICEMAIL_BEEP"
+	AmbientTranscript     EventType = "AMBIENT_TRANSCRIPT" // Overheard, not a turn
+	WakeWordDetected      EventType = "WAKE_WORD_DETECTED"
+	AnalyticsUpdate       EventType = "ANALYTICS_UPDATE"
+	UserAudio             EventType = "USER_AUDIO"              // Observers only
+	SupervisorWhisper     EventType = "SUPERVISOR_WHISPER"      // Observers only
+	HotCommandHeard       EventType = "HOT_COMMAND"             // Data is the HotCommand
+	TurnTimelineReady     EventType = "TURN_TIMELINE"           // Data is a TurnTimeline
+	ConversationComplete  EventType = "CONVERSATION_COMPLETE"   // Data is the user's farewell; the stream closes after the closing turn
+	LanguageChanged       EventType = "LANGUAGE_CHANGED"        // Data is the Language the session switched to
+	LatencyBudgetExceeded EventType = "LATENCY_BUDGET_EXCEEDED" // Data is a BudgetExceeded
+	DoubleTalk            EventType = "DOUBLE_TALK"             // Data is a DoubleTalkEvent
+	Backchannel           EventType = "BACKCHANNEL"             // Data is the transcript of an acknowledgment the bot talked through
+	ProviderFallback      EventType = "PROVIDER_FALLBACK"       // Data is a FallbackUsed
+	AssistTranscript      EventType = "ASSIST_TRANSCRIPT"       // Data is an AssistUtterance
+	SuggestionReady       EventType = "SUGGESTION"              // Data is a Suggestion
+	KnowledgeFound        EventType = "KNOWLEDGE"               // Data is a KnowledgeResult
+	ComplianceAlert       EventType = "COMPLIANCE_ALERT"        // Data is a ComplianceViolation
+	ErrorEvent            EventType = "ERROR"
 )
 
 type ToolCallEventData struct {
@@ -135,12 +157,74 @@ const (
 	LanguageZh Language = "zh"
 )
 
+// Message roles. Role is a plain string so providers can pass through
+// roles of their own; these are the ones the orchestrator understands.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+	RoleFunction  = "function"
+	RoleObserver  = "observer" // Notes from a listener (e.g. a supervisor) that are not part of the dialogue
+)
+
+type PartType string
+
+const (
+	PartText  PartType = "text"
+	PartImage PartType = "image"
+	PartAudio PartType = "audio"
+)
+
+// ContentPart is one piece of a multimodal message. Media is either
+// referenced by URL or carried inline in Data.
+type ContentPart struct {
+	Type     PartType `json:"type"`
+	Text     string   `json:"text,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	MIMEType string   `json:"mime_type,omitempty"`
+	Data     []byte   `json:"data,omitempty"`
+}
+
+func TextPart(text string) ContentPart { return ContentPart{Type: PartText, Text: text} }
+
+func ImageRef(url string) ContentPart { return ContentPart{Type: PartImage, URL: url} }
+
+func AudioRef(url, mimeType string) ContentPart {
+	return ContentPart{Type: PartAudio, URL: url, MIMEType: mimeType}
+}
+
+func ImageData(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: PartImage, Data: data, MIMEType: mimeType}
+}
+
+func AudioData(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: PartAudio, Data: data, MIMEType: mimeType}
+}
+
 type Message struct {
-	Role       string      `json:"role"`
-	Content    string      `json:"content"`
-	Name       string      `json:"name,omitempty"`
-	ToolCallID string      `json:"tool_call_id,omitempty"`
-	ToolCalls  interface{} `json:"tool_calls,omitempty"`
+	Role       string        `json:"role"`
+	Content    string        `json:"content"`
+	Parts      []ContentPart `json:"parts,omitempty"` // Structured content following Content
+	Name       string        `json:"name,omitempty"`
+	ToolCallID string        `json:"tool_call_id,omitempty"`
+	ToolCalls  interface{}   `json:"tool_calls,omitempty"`
+	Usage      *TokenUsage   `json:"usage,omitempty"` // Tokens spent producing an assistant message
+}
+
+// Text returns the message's text: Content followed by any text parts.
+func (m Message) Text() string {
+	text := m.Content
+	for _, p := range m.Parts {
+		if p.Type != PartText || p.Text == "" {
+			continue
+		}
+		if text != "" {
+			text += "\n"
+		}
+		text += p.Text
+	}
+	return text
 }
 
 type Tool struct {
@@ -160,17 +244,65 @@ type Config struct {
 	Channels                 int
 	BytesPerSamp             int
 	MaxContextMessages       int
+	ContextWindow            int // Tokens the LLM accepts, prompt and reply; 0 asks the provider (ContextWindowProvider), if it knows
 	VoiceStyle               Voice
 	MinWordsToInterrupt      int
 	Language                 Language
-	STTTimeout               uint
-	LLMTimeout               uint
-	TTSTimeout               uint
+	STTTimeout               time.Duration // Per STT call, retries each getting their own; 0 leaves calls to the caller's context
+	LLMTimeout               time.Duration // Per LLM call, the whole streamed reply included
+	TTSTimeout               time.Duration // Per synthesis, the whole streamed audio included
 	BargeInVADThreshold      float64
 	BargeInVADTrailWindow    time.Duration
+	BargeInPlaybackRatio     float64              // Mic RMS needed to interrupt, relative to playback RMS; 0 disables
+	EchoGating               bool                 // Hold back barge-in that is quieter than, or correlates with, the bot's own audio
+	InterruptionPolicy       InterruptionPolicy   // What user speech does while the bot talks or thinks; "" is InterruptStopAndAbortLLM
+	MinBargeInDuration       time.Duration        // Speech must last this long to interrupt the bot; 0 interrupts at once
+	ResumeAfterInterruption  int                  // Interjections of up to this many words resume the cut-off answer; 0 always starts afresh
+	DoubleTalk               *DoubleTalkDetection // Reports the user talking over the bot, and picks policies by kind; nil disables
+	Backchannel              *BackchannelFilter   // Talks through short acknowledgments instead of stopping for them; nil disables
+	TailBehavior             TailBehavior         // How interrupted bot audio ends; "" is TailHardStop
+	TailFade                 time.Duration        // Fade-out for TailFade and TailWordBoundary; defaults to 40ms
+	PlaybackLead             time.Duration        // How far synthesis may run ahead of playback a client reports; 0 is unlimited
 	EchoSuppressionThreshold float64
 	FirstSpeaker             FirstSpeaker
 	SilenceTimeout           time.Duration
+	SpeechHangover           time.Duration         // Grace after speech end in which resumed speech continues the same utterance
+	PreRoll                  time.Duration         // Audio before the VAD's speech start sent to STT with the utterance; 0 sends what is buffered, up to 3s
+	PostRoll                 time.Duration         // Audio after the VAD's speech end sent to STT with the utterance
+	Endpointing              *TurnEndpointer       // Closes turns on silence, punctuation and an optional check together; nil closes them on VAD silence
+	EndpointFusion           *EndpointFusion       // Combines streaming STT endpoints with the VAD's; nil ends a turn on either
+	MaxRetries               int                   // Retries for rate-limited or transient provider errors
+	RetryBaseDelay           time.Duration         // Exponential backoff base; Retry-After wins when longer
+	RetryMaxDelay            time.Duration         // Longest wait before giving up on a throttled provider
+	RateLimitCooldown        time.Duration         // Down-weight window when a 429 carries no Retry-After
+	PromptLogSampleRate      float64               // Fraction of turns (0-1) whose redacted LLM prompt is logged
+	PromptLogOnError         bool                  // Always log the redacted prompt of a failed LLM call
+	VADCalibration           time.Duration         // Ambient audio each stream listens to before setting its VAD threshold
+	TrimStrategy             TrimStrategy          // Context compaction for new sessions; nil drops the oldest messages
+	Segmentation             SegmenterConfig       // How streamed LLM text is cut into sentences for TTS
+	EchoTranscriptSimilarity float64               // Drop transcripts this similar (0-1) to the assistant's last speech, of audio captured during or just after its playback; 0 disables
+	EagerSynthesis           EagerSynthesis        // Start TTS on unfinished sentences to cut time-to-first-audio
+	HotCommands              HotCommands           // Phrases handled locally without the LLM; nil disables
+	TimeStretch              bool                  // Apply session speech rates to the audio locally (WSOLA) instead of via the TTS provider
+	Loudness                 LoudnessNormalization // Bring synthesised audio to a common loudness across providers and voices
+	ComfortNoise             ComfortNoise          // Faint noise in silent gaps of bot audio, for telephony
+	TurnQueue                TurnQueuePolicy       // What happens to a turn arriving while its session is busy; "" queues it
+	Greeting                 string                // Said as written when a bot-first stream opens; "" has the LLM open with a hello
+	Closing                  string                // Said by ManagedStream.End before the stream closes
+	MaxSilencePrompts        int                   // Silence reprompts before the stream says its closing and ends; 0 never ends it
+	EndDetection             *EndDetection         // Ends streams when the user says goodbye; nil disables
+	LanguageDetection        LanguageDetection     // Follow the user's language between turns; no Detector disables
+	WrapUp                   WrapUpConfig          // Summary, intents and disposition delivered when a stream closes
+	Archive                  ArchiveConfig         // Transcript, metrics and optionally audio of each stream uploaded to object storage when it closes
+	LatencyBudget            LatencyBudget         // Per-stage time limits, with warnings and a filler phrase on overrun
+	TurnBudget               TurnBudget            // Time from the end of the user's speech to the first reply audio, shared out between the stages
+	Determinism              *Determinism          // Seeded LLM calls and hashed stage inputs/outputs for reproducible runs; nil disables
+	Normalizer               TextNormalizer        // Rewrites reply text for TTS, e.g. NewLocaleNormalizer(); nil speaks it as written
+	CodeSwitching            *CodeSwitching        // Speaks foreign phrases in replies in their own language; nil speaks all of a reply in the session's
+	Markup                   *SpeechMarkup         // SSML tags in replies passed through to TTS; nil removes all markup before synthesis
+	Flags                    FlagEvaluator         // Feature flags evaluated per turn, for gradual rollouts; nil leaves features to this config
+	AudioFraming             AudioFraming          // Chunking of reply audio, e.g. 20ms frames for RTP; connections can set their own with WithAudioFraming
+	ReadinessCacheTTL        time.Duration         // How long Readiness reuses its checks' and provider pings' results; 0 runs them on every probe
 }
 
 func DefaultConfig() Config {
@@ -182,20 +314,34 @@ func DefaultConfig() Config {
 		VoiceStyle:               VoiceF1,
 		MinWordsToInterrupt:      2,
 		Language:                 LanguageEn,
-		STTTimeout:               30,
-		LLMTimeout:               60,
-		TTSTimeout:               30,
+		STTTimeout:               30 * time.Second,
+		LLMTimeout:               60 * time.Second,
+		TTSTimeout:               30 * time.Second,
 		BargeInVADThreshold:      0.007,
 		BargeInVADTrailWindow:    1500 * time.Millisecond,
+		BargeInPlaybackRatio:     0,
 		EchoSuppressionThreshold: 0.35,
 		FirstSpeaker:             FirstSpeakerBot,
 		SilenceTimeout:           0,
+		SpeechHangover:           0,
+		MaxRetries:               2,
+		RetryBaseDelay:           250 * time.Millisecond,
+		RetryMaxDelay:            5 * time.Second,
+		RateLimitCooldown:        30 * time.Second,
+		PromptLogSampleRate:      0,
+		PromptLogOnError:         true,
+		VADCalibration:           0,
+		ReadinessCacheTTL:        10 * time.Second,
 	}
 }
 
 type ConversationSession struct {
 	mu              sync.RWMutex
 	ID              string
+	UserID          string
+	TenantID        string
+	Identity        *Identity // Who opened the session, when they were authenticated
+	Priority        Priority
 	Context         []Message
 	LastUser        string
 	LastAssistant   string
@@ -203,11 +349,38 @@ type ConversationSession struct {
 	CurrentVoice    Voice
 	CurrentLanguage Language
 	Tools           []Tool
+	AllowedTools    []string     // Tool names this session may call; nil allows all
+	TrimStrategy    TrimStrategy // How Context is cut back to MaxMessages; nil drops the oldest
+	// OnPartialTranscript receives interim transcripts from a streaming STT
+	// provider while the user is still speaking, e.g. for live captions. It
+	// is called from the transcription goroutine and must not block.
+	OnPartialTranscript func(text string)
+
+	analytics    sessionAnalytics
+	usage        TokenUsage
+	pendingUsage TokenUsage    // Recorded but not yet attached to an assistant message
+	attachments  []ContentPart // For the next user message
+
+	checkpoints   []checkpoint
+	checkpointSeq int
+
+	speechRate   float64 // 0 leaves the provider's default
+	lastSpeech   spokenResponse
+	playback     replyPlayback
+	botSpeechEnd time.Time // When the assistant's last spoken audio stopped playing, as estimated
+
+	greeting, closing string // Override the config's lifecycle lines
+
+	state          PipelineState
+	stateListeners []func(StateChange)
+
+	turns turnQueue
 }
 
 func NewConversationSession(userID string) *ConversationSession {
 	return &ConversationSession{
 		ID:              userID,
+		UserID:          userID,
 		Context:         []Message{},
 		MaxMessages:     20,
 		CurrentVoice:    VoiceF1,
@@ -222,17 +395,39 @@ func (s *ConversationSession) AddMessage(role, content string) {
 func (s *ConversationSession) AddMessageRaw(msg Message) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if msg.Role == RoleAssistant && msg.Usage == nil && !s.pendingUsage.IsZero() {
+		u := s.pendingUsage
+		msg.Usage = &u
+		s.pendingUsage = TokenUsage{}
+	}
+	if msg.Role == RoleUser {
+		s.settlePlaybackLocked()
+		if len(s.attachments) > 0 {
+			msg.Parts = append(append([]ContentPart(nil), msg.Parts...), s.attachments...)
+			s.attachments = nil
+		}
+	}
 	s.Context = append(s.Context, msg)
-	if len(s.Context) > s.MaxMessages {
-		s.Context = s.Context[len(s.Context)-s.MaxMessages:]
+	if _, slow := s.trimStrategy().(ContextTrimStrategy); !slow && len(s.Context) > s.MaxMessages {
+		s.Context = s.trimStrategy().Trim(s.Context, s.MaxMessages)
 	}
-	if msg.Role == "user" {
-		s.LastUser = msg.Content
-	} else if msg.Role == "assistant" && msg.Content != "" {
-		s.LastAssistant = msg.Content
+	if msg.Role == RoleUser {
+		s.LastUser = msg.Text()
+	} else if msg.Role == RoleAssistant && msg.Text() != "" {
+		s.LastAssistant = msg.Text()
 	}
 }
 
+// Attach adds parts to the next user message, whichever way it arrives: a
+// camera frame attached while the user asks "what am I holding?" reaches the
+// LLM with the transcript of the question. Providers that cannot take the
+// media get a placeholder instead.
+func (s *ConversationSession) Attach(parts ...ContentPart) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attachments = append(s.attachments, parts...)
+}
+
 func (s *ConversationSession) UpdateLastUserMessage(content string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -254,10 +449,21 @@ func (s *ConversationSession) SetTools(tools []Tool) {
 	s.Tools = tools
 }
 
+// GetTools returns the tool definitions offered to the LLM, leaving out any
+// the session's allowlist does not permit.
 func (s *ConversationSession) GetTools() []Tool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.Tools
+	if s.AllowedTools == nil {
+		return s.Tools
+	}
+	var tools []Tool
+	for _, t := range s.Tools {
+		if s.toolAllowedLocked(toolName(t)) {
+			tools = append(tools, t)
+		}
+	}
+	return tools
 }
 
 func (s *ConversationSession) ClearContext() {
@@ -282,6 +488,18 @@ func (s *ConversationSession) GetCurrentVoice() Voice {
 	return s.CurrentVoice
 }
 
+func (s *ConversationSession) GetPriority() Priority {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Priority
+}
+
+func (s *ConversationSession) GetTenantID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.TenantID
+}
+
 func (s *ConversationSession) GetCurrentLanguage() Language {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -14,8 +14,8 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.SampleRate != 44100 {
 		t.Errorf("Expected sample rate 44100, got %d", cfg.SampleRate)
 	}
-	if cfg.MaxContextMessages != 20 {
-		t.Errorf("Expected max messages 20, got %d", cfg.MaxContextMessages)
+	if cfg.MaxContextMessages != 100 {
+		t.Errorf("Expected max messages 100, got %d", cfg.MaxContextMessages)
 	}
 }
 
@@ -48,3 +48,41 @@ func TestClearContext(t *testing.T) {
 		t.Errorf("Expected empty context after clear")
 	}
 }
+
+func TestMessageParts(t *testing.T) {
+	session := NewConversationSession("user_parts")
+	session.AddMessageRaw(Message{
+		Role:    RoleUser,
+		Content: "What is in this picture?",
+		Parts:   []ContentPart{ImageRef("https://example.com/cam.jpg"), TextPart("Be brief.")},
+	})
+	if session.LastUser != "What is in this picture?\nBe brief." {
+		t.Errorf("Expected text parts in LastUser, got %q", session.LastUser)
+	}
+
+	snap := session.Snapshot()
+	if parts := RestoreSession(snap).GetContextCopy()[0].Parts; len(parts) != 2 || parts[0].URL != "https://example.com/cam.jpg" {
+		t.Errorf("Expected parts to survive a snapshot, got %+v", parts)
+	}
+}
+
+func TestSessionAttach(t *testing.T) {
+	session := NewConversationSession("u")
+	session.Attach(ImageData([]byte{1, 2}, "image/jpeg"))
+	session.AddMessage(RoleAssistant, "Go ahead.")
+	session.AddMessage(RoleUser, "What is this?")
+	session.AddMessage(RoleUser, "And now?")
+
+	if len(session.Context[0].Parts) != 0 {
+		t.Error("attachment went to an assistant message")
+	}
+	if parts := session.Context[1].Parts; len(parts) != 1 || parts[0].Type != PartImage {
+		t.Errorf("first user message parts = %v", parts)
+	}
+	if len(session.Context[2].Parts) != 0 {
+		t.Error("attachment repeated on a later message")
+	}
+	if session.LastUser != "And now?" {
+		t.Errorf("LastUser = %q", session.LastUser)
+	}
+}
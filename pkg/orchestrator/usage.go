@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+)
+
+// TokenUsage counts the tokens one or more LLM calls consumed. Estimated is
+// set when any part of the count was guessed from text length because the
+// provider did not report it.
+type TokenUsage struct {
+	PromptTokens     int  `json:"prompt_tokens"`
+	CompletionTokens int  `json:"completion_tokens"`
+	Estimated        bool `json:"estimated,omitempty"`
+}
+
+func (u TokenUsage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+func (u TokenUsage) IsZero() bool {
+	return u.PromptTokens == 0 && u.CompletionTokens == 0
+}
+
+// Add returns the sum of u and v.
+func (u TokenUsage) Add(v TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens + v.PromptTokens,
+		CompletionTokens: u.CompletionTokens + v.CompletionTokens,
+		Estimated:        u.Estimated || v.Estimated,
+	}
+}
+
+func (u TokenUsage) sub(v TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens - v.PromptTokens,
+		CompletionTokens: u.CompletionTokens - v.CompletionTokens,
+		Estimated:        u.Estimated,
+	}
+}
+
+type usageKey struct{}
+
+type usageCollector struct {
+	mu       sync.Mutex
+	usage    TokenUsage
+	reported bool
+}
+
+func withUsageCollector(ctx context.Context) (context.Context, *usageCollector) {
+	c := &usageCollector{}
+	return context.WithValue(ctx, usageKey{}, c), c
+}
+
+// ReportUsage lets an LLMProvider pass the token counts from its API response
+// back to the orchestrator. Calls outside a turn are ignored.
+func ReportUsage(ctx context.Context, u TokenUsage) {
+	c, ok := ctx.Value(usageKey{}).(*usageCollector)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.usage = c.usage.Add(u)
+	c.reported = true
+}
+
+// result returns the reported usage, or an estimate from the prompt and
+// response text if the provider reported nothing.
+func (c *usageCollector) result(messages []Message, response string) TokenUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reported {
+		return c.usage
+	}
+	return TokenUsage{PromptTokens: estimatePrompt(messages), CompletionTokens: EstimateTokens(response), Estimated: true}
+}
+
+// EstimateTokens approximates a tokenizer at four characters per token.
+func EstimateTokens(text string) int {
+	n := len([]rune(text))
+	return (n + 3) / 4
+}
+
+// RecordUsage adds one LLM call's usage to the session total. It is also
+// attached to the next assistant message added to the context.
+func (s *ConversationSession) RecordUsage(u TokenUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage = s.usage.Add(u)
+	s.pendingUsage = s.pendingUsage.Add(u)
+}
+
+// Usage returns the tokens the session has consumed so far.
+func (s *ConversationSession) Usage() TokenUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.usage
+}
+
+// addUsageTotal counts usage already attached to messages, without attaching
+// it again.
+func (s *ConversationSession) addUsageTotal(u TokenUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage = s.usage.Add(u)
+}
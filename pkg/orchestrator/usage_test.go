@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProcessAudio_EstimatesUsage(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "hello there"}, &MockLLMProvider{completeResult: "hi, how can I help?"}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+
+	if _, _, err := o.ProcessAudio(context.Background(), session, []byte{1}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	u := session.Usage()
+	if !u.Estimated {
+		t.Error("usage without a provider report should be marked estimated")
+	}
+	if u.PromptTokens != EstimateTokens("hello there")+4 || u.CompletionTokens != EstimateTokens("hi, how can I help?") {
+		t.Errorf("unexpected estimate %+v", u)
+	}
+
+	ctx := session.GetContextCopy()
+	last := ctx[len(ctx)-1]
+	if last.Role != RoleAssistant || last.Usage == nil || *last.Usage != u {
+		t.Errorf("expected the assistant message to carry the turn's usage, got %+v", last.Usage)
+	}
+	if ctx[0].Usage != nil {
+		t.Error("user messages should not carry usage")
+	}
+}
+
+type reportingLLM struct{}
+
+func (reportingLLM) Complete(ctx context.Context, messages []Message, tools []Tool) (string, error) {
+	ReportUsage(ctx, TokenUsage{PromptTokens: 100, CompletionTokens: 10})
+	return "sure thing", nil
+}
+
+func (reportingLLM) Name() string { return "reporting" }
+
+func TestUsage_AccumulatesAndSurvivesStatelessTurns(t *testing.T) {
+	o := NewWithVAD(&MockSTTProvider{transcribeResult: "hello there"}, reportingLLM{}, &MockTTSProvider{synthesizeResult: []byte{1}}, nil, DefaultConfig())
+	o.SetSessionStore(NewInMemorySessionStore())
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := o.ProcessAudioStateless(context.Background(), "s1", []byte{1}, false, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	session, _, err := o.LoadSession(context.Background(), "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TokenUsage{PromptTokens: 200, CompletionTokens: 20}
+	if got := session.Usage(); got != want {
+		t.Errorf("expected %+v after two turns, got %+v", want, got)
+	}
+	if got := session.Usage().Total(); got != 220 {
+		t.Errorf("expected total 220, got %d", got)
+	}
+}
@@ -21,6 +21,7 @@ type RMSVAD struct {
 	lastRMS           float64
 	localMin          float64
 	lastMinUpdate     time.Time
+	meter             vadMeter
 	mu                sync.Mutex
 }
 
@@ -81,6 +82,22 @@ func (v *RMSVAD) Process(chunk []byte) (*VADEvent, error) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
+	if v.meter.calibRemaining > 0 {
+		rms := v.calculateRMS(chunk)
+		v.lastRMS = rms
+		if floor, threshold, done := v.meter.calibrate(chunk, rms); done {
+			v.noiseFloor = floor
+			v.threshold = threshold
+		}
+		return nil, nil
+	}
+
+	event, err := v.process(chunk)
+	v.meter.observe(v.lastRMS, v.isSpeaking, v.consecutiveFrames, event)
+	return event, err
+}
+
+func (v *RMSVAD) process(chunk []byte) (*VADEvent, error) {
 	rms := v.calculateRMS(chunk)
 	v.lastRMS = rms
 	now := time.Now()
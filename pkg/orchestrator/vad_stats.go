@@ -0,0 +1,173 @@
+package orchestrator
+
+import (
+	"sort"
+	"time"
+)
+
+// VADRMSBuckets are the upper bounds of the RMS histogram in VADStats. The
+// last bucket catches everything above the final bound.
+var VADRMSBuckets = []float64{0.001, 0.002, 0.005, 0.01, 0.02, 0.05, 0.1, 0.2, 0.5}
+
+// VADStats describes what a VAD has heard so far in a session.
+type VADStats struct {
+	Frames         int     `json:"frames"`
+	SpeechFrames   int     `json:"speech_frames"`
+	NoiseFloor     float64 `json:"noise_floor"`
+	Threshold      float64 `json:"threshold"`
+	MeanRMS        float64 `json:"mean_rms"`
+	PeakRMS        float64 `json:"peak_rms"`
+	RMSHistogram   []int   `json:"rms_histogram"` // len(VADRMSBuckets)+1 counts
+	SpeechSegments int     `json:"speech_segments"`
+	FalseStarts    int     `json:"false_starts"` // Onsets that never reached confirmation
+	Calibrated     bool    `json:"calibrated"`
+}
+
+// VADStatsProvider is implemented by VADs that keep per-session statistics.
+type VADStatsProvider interface {
+	Stats() VADStats
+}
+
+// VADCalibrator is implemented by VADs that can derive their threshold from
+// ambient audio. While calibrating the VAD reports no speech.
+type VADCalibrator interface {
+	Calibrate(d time.Duration, sampleRate int)
+	Calibrating() bool
+}
+
+// Calibration places the threshold this far above the loud end of the
+// ambient noise, bounded to the range the VADs already clamp to.
+const (
+	calibrationMargin       = 2.5
+	calibrationMinThreshold = 0.005
+	calibrationMaxThreshold = 0.3
+)
+
+type vadMeter struct {
+	frames       int
+	speechFrames int
+	sum          float64
+	peak         float64
+	hist         []int
+	segments     int
+	falseStarts  int
+	onset        bool
+
+	calibRemaining int // Bytes of ambient audio still to collect
+	calibSamples   []float64
+	calibrated     bool
+}
+
+func (m *vadMeter) observe(rms float64, speaking bool, consecutive int, ev *VADEvent) {
+	if m.hist == nil {
+		m.hist = make([]int, len(VADRMSBuckets)+1)
+	}
+	m.frames++
+	m.sum += rms
+	if rms > m.peak {
+		m.peak = rms
+	}
+	m.hist[sort.SearchFloat64s(VADRMSBuckets, rms)]++
+	if speaking {
+		m.speechFrames++
+	}
+
+	switch {
+	case ev != nil && ev.Type == VADSpeechStart:
+		m.segments++
+		m.onset = false
+	case !speaking && consecutive > 0:
+		m.onset = true
+	case !speaking && consecutive == 0 && m.onset:
+		m.falseStarts++
+		m.onset = false
+	}
+}
+
+func (m *vadMeter) startCalibration(d time.Duration, sampleRate int) {
+	if sampleRate <= 0 {
+		sampleRate = 44100
+	}
+	m.calibRemaining = int(d.Seconds()*float64(sampleRate)) * 2
+	m.calibSamples = m.calibSamples[:0]
+	m.calibrated = false
+}
+
+// calibrate consumes a chunk of ambient audio. When enough has been heard it
+// returns the measured noise floor and the threshold to use.
+func (m *vadMeter) calibrate(chunk []byte, rms float64) (floor, threshold float64, done bool) {
+	m.calibSamples = append(m.calibSamples, rms)
+	m.calibRemaining -= len(chunk)
+	if m.calibRemaining > 0 {
+		return 0, 0, false
+	}
+	m.calibRemaining = 0
+	m.calibrated = true
+
+	sorted := append([]float64(nil), m.calibSamples...)
+	sort.Float64s(sorted)
+	m.calibSamples = nil
+	floor = sorted[len(sorted)/2]
+	p90 := sorted[(len(sorted)*9)/10]
+	threshold = min(max(p90*calibrationMargin, calibrationMinThreshold), calibrationMaxThreshold)
+	return floor, threshold, true
+}
+
+func (m *vadMeter) stats(floor, threshold float64) VADStats {
+	s := VADStats{
+		Frames:         m.frames,
+		SpeechFrames:   m.speechFrames,
+		NoiseFloor:     floor,
+		Threshold:      threshold,
+		PeakRMS:        m.peak,
+		RMSHistogram:   append([]int(nil), m.hist...),
+		SpeechSegments: m.segments,
+		FalseStarts:    m.falseStarts,
+		Calibrated:     m.calibrated,
+	}
+	if s.RMSHistogram == nil {
+		s.RMSHistogram = make([]int, len(VADRMSBuckets)+1)
+	}
+	if m.frames > 0 {
+		s.MeanRMS = m.sum / float64(m.frames)
+	}
+	return s
+}
+
+// Calibrate makes the VAD listen to d of ambient audio (at sampleRate, 16-bit
+// mono) and then set its threshold and noise floor from what it heard.
+func (v *RMSVAD) Calibrate(d time.Duration, sampleRate int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.meter.startCalibration(d, sampleRate)
+}
+
+func (v *RMSVAD) Calibrating() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.meter.calibRemaining > 0
+}
+
+func (v *RMSVAD) Stats() VADStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.meter.stats(v.noiseFloor, v.threshold)
+}
+
+func (v *ImprovedRMSVAD) Calibrate(d time.Duration, sampleRate int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.meter.startCalibration(d, sampleRate)
+}
+
+func (v *ImprovedRMSVAD) Calibrating() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.meter.calibRemaining > 0
+}
+
+func (v *ImprovedRMSVAD) Stats() VADStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.meter.stats(v.noiseFloor, v.threshold)
+}
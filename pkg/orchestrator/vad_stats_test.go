@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRMSVAD_Calibrate(t *testing.T) {
+	v := NewRMSVAD(0.001, 300*time.Millisecond)
+	v.Calibrate(200*time.Millisecond, 16000)
+
+	// 200ms at 16kHz is 6400 bytes; feed 20ms chunks of steady hum.
+	hum := generateSine(60, 20, 16000, 0.02)
+	for i := 0; i < 10; i++ {
+		if !v.Calibrating() {
+			t.Fatalf("calibration ended early after %d chunks", i)
+		}
+		if ev, _ := v.Process(hum); ev != nil {
+			t.Fatalf("expected no events while calibrating, got %v", ev.Type)
+		}
+	}
+	if v.Calibrating() {
+		t.Fatal("expected calibration to finish")
+	}
+
+	rms := v.LastRMS()
+	if th := v.Threshold(); th < rms*2 || th > rms*3 {
+		t.Errorf("threshold %.4f should sit above the hum (rms %.4f)", th, rms)
+	}
+	if s := v.Stats(); !s.Calibrated || s.Frames != 0 {
+		t.Errorf("calibration audio should not count as frames, got %+v", s)
+	}
+
+	// The hum alone must no longer trigger speech.
+	for i := 0; i < 20; i++ {
+		if ev, _ := v.Process(hum); ev != nil && ev.Type == VADSpeechStart {
+			t.Fatal("ambient hum triggered speech after calibration")
+		}
+	}
+}
+
+func TestRMSVAD_Stats(t *testing.T) {
+	v := NewRMSVAD(0.02, 50*time.Millisecond)
+	v.SetAdaptiveMode(false)
+	v.SetMinConfirmed(3)
+
+	quiet := make([]byte, 640)
+	loud := generateSine(440, 20, 16000, 0.3)
+
+	// A two-frame blip is a false start.
+	v.Process(loud)
+	v.Process(loud)
+	v.Process(quiet)
+
+	for i := 0; i < 5; i++ {
+		v.Process(loud)
+	}
+
+	s := v.Stats()
+	if s.FalseStarts != 1 {
+		t.Errorf("expected 1 false start, got %d", s.FalseStarts)
+	}
+	if s.SpeechSegments != 1 {
+		t.Errorf("expected 1 speech segment, got %d", s.SpeechSegments)
+	}
+	if s.Frames != 8 || s.SpeechFrames != 3 {
+		t.Errorf("unexpected frame counts: %+v", s)
+	}
+	if s.PeakRMS < 0.2 || s.MeanRMS <= 0 {
+		t.Errorf("unexpected RMS summary: mean %.3f peak %.3f", s.MeanRMS, s.PeakRMS)
+	}
+	if len(s.RMSHistogram) != len(VADRMSBuckets)+1 || s.RMSHistogram[0] != 1 {
+		t.Errorf("unexpected histogram: %v", s.RMSHistogram)
+	}
+
+	if cloned := v.Clone().(*RMSVAD).Stats(); cloned.Frames != 0 {
+		t.Error("cloned VAD should start with fresh stats")
+	}
+}
+
+func TestManagedStream_VADCalibrationFromConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.VADCalibration = time.Second
+	o := NewWithVAD(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{}, NewRMSVAD(0.02, time.Second), cfg)
+
+	ms := NewManagedStream(context.Background(), o, NewConversationSession("u"))
+	defer ms.Close()
+
+	if !ms.vad.(VADCalibrator).Calibrating() {
+		t.Error("expected the stream VAD to start calibrating")
+	}
+	if _, ok := ms.VADStats(); !ok {
+		t.Error("expected VAD stats to be available")
+	}
+}
@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"sync"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+type voiceEffects struct {
+	mu     sync.RWMutex
+	chains map[Voice][]audio.EffectFactory
+}
+
+// SetVoiceEffects gives a voice a post-processing chain, run on everything
+// it says after loudness normalisation, whichever TTS provider speaks it,
+// so a brand keeps its sound when the vendor changes:
+//
+//	orch.SetVoiceEffects(orchestrator.VoiceF1,
+//		audio.EQ(audio.EQBand{Type: audio.BandLowShelf, Freq: 200, GainDB: 2}),
+//		audio.DeEsser(6000, -30),
+//		audio.Reverb(0.2, 0.1),
+//		audio.Gain(-1),
+//	)
+//
+// No effects removes the chain.
+func (o *Orchestrator) SetVoiceEffects(voice Voice, effects ...audio.EffectFactory) {
+	o.effects.mu.Lock()
+	defer o.effects.mu.Unlock()
+	if len(effects) == 0 {
+		delete(o.effects.chains, voice)
+		return
+	}
+	if o.effects.chains == nil {
+		o.effects.chains = make(map[Voice][]audio.EffectFactory)
+	}
+	o.effects.chains[voice] = effects
+}
+
+// newEffectsStage returns a fresh chain for one synthesis request in voice,
+// or nil when the voice has none.
+func (o *Orchestrator) newEffectsStage(voice Voice) *audio.Chain {
+	o.effects.mu.RLock()
+	effects := o.effects.chains[voice]
+	o.effects.mu.RUnlock()
+	if len(effects) == 0 {
+		return nil
+	}
+	return audio.NewChain(o.ttsSampleRate(), effects...)
+}
@@ -0,0 +1,44 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+)
+
+func TestVoiceEffects(t *testing.T) {
+	clip := make([]byte, 400)
+	for i := 0; i < len(clip); i += 2 {
+		binary.LittleEndian.PutUint16(clip[i:], uint16(int16(8000)))
+	}
+	o := New(&MockSTTProvider{}, &MockLLMProvider{}, &MockTTSProvider{synthesizeResult: clip}, nil, DefaultConfig(), nil)
+	o.SetVoiceEffects(VoiceF1, audio.Gain(-6.0206))
+	sample := func(pcm []byte) int16 { return int16(binary.LittleEndian.Uint16(pcm[100:])) }
+
+	shaped, err := o.Synthesize(context.Background(), "hi", VoiceF1, LanguageEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sample(shaped); got != 4000 {
+		t.Errorf("F1 sample = %d, want 4000", got)
+	}
+	var streamed []byte
+	o.SynthesizeStream(context.Background(), "hi", VoiceF1, LanguageEn, func(c []byte) error {
+		streamed = append(streamed, c...)
+		return nil
+	})
+	if got := sample(streamed); got != 4000 {
+		t.Errorf("streamed F1 sample = %d, want 4000", got)
+	}
+	plain, _ := o.Synthesize(context.Background(), "hi", VoiceM1, LanguageEn)
+	if got := sample(plain); got != 8000 {
+		t.Errorf("M1 has no chain but its sample is %d", got)
+	}
+
+	o.SetVoiceEffects(VoiceF1)
+	if unshaped, _ := o.Synthesize(context.Background(), "hi", VoiceF1, LanguageEn); sample(unshaped) != 8000 {
+		t.Error("removing the chain should leave F1 as synthesized")
+	}
+}
@@ -0,0 +1,179 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WrapUp is the after-call record of a conversation that contact centers
+// file against each call: what it was about and how it ended.
+type WrapUp struct {
+	SessionID   string         `json:"session_id"`
+	UserID      string         `json:"user_id,omitempty"`
+	TenantID    string         `json:"tenant_id,omitempty"`
+	Summary     string         `json:"summary"`
+	Intents     []string       `json:"intents"`
+	Disposition string         `json:"disposition"` // One of WrapUpConfig.Dispositions
+	Metrics     SessionMetrics `json:"metrics"`
+	EndedAt     time.Time      `json:"ended_at"`
+}
+
+// WrapUpSink receives the WrapUp of each finished session.
+type WrapUpSink interface {
+	DeliverWrapUp(ctx context.Context, w WrapUp) error
+}
+
+// WrapUpFunc adapts a function to WrapUpSink.
+type WrapUpFunc func(ctx context.Context, w WrapUp) error
+
+func (f WrapUpFunc) DeliverWrapUp(ctx context.Context, w WrapUp) error { return f(ctx, w) }
+
+// WrapUpConfig has a WrapUp written by the LLM and delivered to Sink when a
+// ManagedStream closes, if the user said anything. With no Sink, wrap-ups
+// are only produced on request by Orchestrator.WrapUp.
+type WrapUpConfig struct {
+	Sink         WrapUpSink
+	Dispositions []string      // Codes the LLM chooses from; nil uses DefaultDispositions
+	Timeout      time.Duration // For writing and delivering one wrap-up; defaults to 30s
+}
+
+// DefaultDispositions returns general-purpose disposition codes.
+func DefaultDispositions() []string {
+	return []string{"resolved", "unresolved", "escalated", "callback_requested", "abandoned"}
+}
+
+// OtherDisposition is recorded when the LLM's choice is not one of the codes.
+const OtherDisposition = "other"
+
+const wrapUpPrompt = `You write the after-call wrap-up for a conversation between a user and an assistant.
+Reply with only a JSON object with these fields:
+"summary": two or three sentences on what the user wanted and what happened,
+"intents": a list of short snake_case labels for what the user wanted, in the order raised,
+"disposition": exactly one of %s.`
+
+// WrapUp writes the wrap-up of a session's conversation so far with the
+// orchestrator's LLM.
+func (o *Orchestrator) WrapUp(ctx context.Context, session *ConversationSession) (WrapUp, error) {
+	dispositions := o.GetConfig().WrapUp.Dispositions
+	if dispositions == nil {
+		dispositions = DefaultDispositions()
+	}
+	var transcript strings.Builder
+	for _, m := range session.GetContextCopy() {
+		if (m.Role == RoleUser || m.Role == RoleAssistant) && m.Content != "" {
+			fmt.Fprintf(&transcript, "%s: %s\n", m.Role, m.Content)
+		}
+	}
+	codes, _ := json.Marshal(dispositions)
+	messages := []Message{
+		{Role: RoleSystem, Content: fmt.Sprintf(wrapUpPrompt, codes)},
+		{Role: RoleUser, Content: transcript.String()},
+	}
+
+	var reply string
+	err := o.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		reply, err = o.llm.Complete(ctx, messages, nil)
+		return err
+	})
+	if err != nil {
+		return WrapUp{}, fmt.Errorf("%w: %v", ErrLLMFailed, err)
+	}
+
+	var parsed struct {
+		Summary     string   `json:"summary"`
+		Intents     []string `json:"intents"`
+		Disposition string   `json:"disposition"`
+	}
+	// Models sometimes wrap the object in prose or a code fence.
+	if start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}"); start >= 0 && end > start {
+		reply = reply[start : end+1]
+	}
+	if err := json.Unmarshal([]byte(reply), &parsed); err != nil {
+		return WrapUp{}, fmt.Errorf("%w: unreadable wrap-up: %v", ErrLLMFailed, err)
+	}
+	if !slices.Contains(dispositions, parsed.Disposition) {
+		parsed.Disposition = OtherDisposition
+	}
+	if parsed.Intents == nil {
+		parsed.Intents = []string{}
+	}
+
+	return WrapUp{
+		SessionID:   session.ID,
+		UserID:      session.UserID,
+		TenantID:    session.GetTenantID(),
+		Summary:     strings.TrimSpace(parsed.Summary),
+		Intents:     parsed.Intents,
+		Disposition: parsed.Disposition,
+		Metrics:     session.Analytics(),
+		EndedAt:     time.Now(),
+	}, nil
+}
+
+// deliverWrapUp writes and delivers the wrap-up of a session that has ended.
+func (o *Orchestrator) deliverWrapUp(session *ConversationSession) {
+	cfg := o.GetConfig().WrapUp
+	if cfg.Sink == nil || !slices.ContainsFunc(session.GetContextCopy(), func(m Message) bool { return m.Role == RoleUser }) {
+		return
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(WithIdempotencyKey(context.Background(), "wrapup-"+session.ID), timeout)
+	defer cancel()
+
+	w, err := o.WrapUp(ctx, session)
+	if err != nil {
+		o.logger.Warn("wrap-up failed", "sessionID", session.ID, "error", err)
+		return
+	}
+	if err := cfg.Sink.DeliverWrapUp(ctx, w); err != nil {
+		o.logger.Warn("wrap-up delivery failed", "sessionID", session.ID, "error", err)
+	}
+}
+
+// WebhookSink posts each WrapUp as JSON to URL, with the session's
+// idempotency key so a receiver can drop redeliveries.
+type WebhookSink struct {
+	URL     string
+	Headers map[string]string // E.g. an Authorization header
+	Client  *http.Client      // nil uses http.DefaultClient
+}
+
+func (s *WebhookSink) DeliverWrapUp(ctx context.Context, w WrapUp) error {
+	body, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	SetIdempotencyHeader(req)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("wrap-up webhook: status %d", resp.StatusCode)
+	}
+	return nil
+}
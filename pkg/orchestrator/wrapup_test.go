@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWrapUp_ParsesLLMReply(t *testing.T) {
+	llm := &MockLLMProvider{completeResult: "Here you go:\n```json\n" +
+		`{"summary": "The user moved their appointment to Friday.", "intents": ["reschedule_appointment"], "disposition": "resolved"}` +
+		"\n```"}
+	o := NewWithVAD(&MockSTTProvider{}, llm, &MockTTSProvider{}, nil, DefaultConfig())
+	session := NewConversationSession("u")
+	session.AddMessage(RoleUser, "Can I move my appointment to Friday?")
+	session.AddMessage(RoleAssistant, "Done, you're booked for Friday at ten.")
+
+	w, err := o.WrapUp(context.Background(), session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Summary != "The user moved their appointment to Friday." || w.Disposition != "resolved" || len(w.Intents) != 1 || w.SessionID != "u" {
+		t.Errorf("unexpected wrap-up: %+v", w)
+	}
+
+	llm.completeResult = `{"summary": "Unclear.", "disposition": "sale"}`
+	if w, err = o.WrapUp(context.Background(), session); err != nil || w.Disposition != OtherDisposition || w.Intents == nil {
+		t.Errorf("an unknown disposition must become %q: %+v, %v", OtherDisposition, w, err)
+	}
+}
+
+func TestManagedStream_CloseDeliversWrapUp(t *testing.T) {
+	type delivery struct {
+		key string
+		w   WrapUp
+	}
+	got := make(chan delivery, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var w WrapUp
+		json.NewDecoder(r.Body).Decode(&w)
+		got <- delivery{key: r.Header.Get(IdempotencyKeyHeader), w: w}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.FirstSpeaker = FirstSpeakerUser
+	cfg.WrapUp = WrapUpConfig{Sink: &WebhookSink{URL: server.URL}, Dispositions: []string{"sold", "not_interested"}}
+	llm := &MockLLMProvider{completeResult: `{"summary": "Declined the upgrade.", "intents": ["decline_offer"], "disposition": "not_interested"}`}
+	o := NewWithVAD(&MockSTTProvider{}, llm, &MockTTSProvider{}, nil, cfg)
+	session := NewConversationSession("caller-7")
+	session.AddMessage(RoleUser, "No thanks, I'm happy with my plan.")
+
+	ms := o.NewManagedStream(context.Background(), session)
+	ms.Close()
+
+	select {
+	case d := <-got:
+		if d.w.SessionID != "caller-7" || d.w.Disposition != "not_interested" || d.key != "wrapup-caller-7" {
+			t.Errorf("unexpected delivery: %+v", d)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no wrap-up delivered")
+	}
+}
@@ -0,0 +1,157 @@
+// Package aws provides Amazon Transcribe streaming STT, Amazon Polly TTS,
+// standard and neural, and an S3 store for session archives. They are
+// configured with the AWS SDK for Go, so credentials are found as every
+// other AWS tool finds them, and the providers run under the same role as
+// the rest of an AWS stack.
+//
+//	p, err := aws.New(aws.Config{Region: "eu-west-1"})
+//	orch := orchestrator.NewWithVAD(p.STT, llm, p.TTS, vad, cfg)
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Config configures the providers. Every field is optional.
+type Config struct {
+	Region        string                           // Defaults to the SDK's, from AWS_REGION or the profile; us-east-1 if neither has one
+	Profile       string                           // Shared config profile; defaults to AWS_PROFILE
+	Credentials   awssdk.CredentialsProvider       // Defaults to the SDK's default credential chain
+	SampleRate    int                              // Of the pipeline's audio, as Config.SampleRate; defaults to 44100
+	Engine        string                           // Polly engine, "neural" by default; "standard" for the older voices
+	LanguageCodes map[orchestrator.Language]string // Replaces DefaultLanguageCodes
+	Voices        map[orchestrator.Voice]string    // Replaces DefaultVoices
+	HTTPClient    *http.Client
+}
+
+// LoadConfig loads the SDK configuration for cfg's region, profile,
+// credentials and HTTP client. Anything cfg leaves unset is resolved by
+// the SDK's default chain: the environment, the shared config and
+// credentials files (including role_arn and source_profile, SSO and
+// credential_process profiles), web identity, the container credentials
+// endpoint and EC2 instance metadata.
+//
+// The SDK's own retries are turned off; the orchestrator retries failed
+// calls by its Config.
+func LoadConfig(ctx context.Context, cfg Config) (awssdk.Config, error) {
+	opts := []func(*config.LoadOptions) error{
+		config.WithRetryer(func() awssdk.Retryer { return awssdk.NopRetryer{} }),
+	}
+	if cfg.Region != "" {
+		opts = append(opts, config.WithRegion(cfg.Region))
+	}
+	if cfg.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(cfg.Profile))
+	}
+	if cfg.Credentials != nil {
+		opts = append(opts, config.WithCredentialsProvider(cfg.Credentials))
+	}
+	if cfg.HTTPClient != nil {
+		opts = append(opts, config.WithHTTPClient(cfg.HTTPClient))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return awssdk.Config{}, fmt.Errorf("aws config: %w", err)
+	}
+	if awsCfg.Region == "" {
+		awsCfg.Region = "us-east-1"
+	}
+	return awsCfg, nil
+}
+
+// Providers is a matching set of AWS providers. STT also streams, and TTS
+// streams audio.
+type Providers struct {
+	STT *TranscribeSTT
+	TTS *PollyTTS
+}
+
+// New returns the providers for cfg.
+func New(cfg Config) (*Providers, error) {
+	awsCfg, err := LoadConfig(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := &Providers{
+		STT: NewTranscribeSTT(awsCfg),
+		TTS: NewPollyTTS(awsCfg),
+	}
+	if cfg.SampleRate > 0 {
+		p.STT.SetSampleRate(cfg.SampleRate)
+		p.TTS.SetSampleRate(cfg.SampleRate)
+	}
+	if cfg.Engine != "" {
+		p.TTS.SetEngine(cfg.Engine)
+	}
+	if cfg.LanguageCodes != nil {
+		p.STT.SetLanguageCodes(cfg.LanguageCodes)
+	}
+	if cfg.Voices != nil {
+		p.TTS.SetVoices(cfg.Voices)
+	}
+	return p, nil
+}
+
+// DefaultLanguageCodes maps the package's languages to the language codes
+// Transcribe streams in.
+var DefaultLanguageCodes = map[orchestrator.Language]string{
+	orchestrator.LanguageEn: "en-US",
+	orchestrator.LanguageEs: "es-US",
+	orchestrator.LanguageFr: "fr-FR",
+	orchestrator.LanguageDe: "de-DE",
+	orchestrator.LanguageIt: "it-IT",
+	orchestrator.LanguagePt: "pt-BR",
+	orchestrator.LanguageJa: "ja-JP",
+	orchestrator.LanguageZh: "zh-CN",
+}
+
+// reportResponses adds every raw response an SDK client receives to the
+// request's orchestrator audit trail.
+func reportResponses(provider string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("ReportResponse", func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+			out, md, err := next.HandleDeserialize(ctx, in)
+			if resp, ok := out.RawResponse.(*smithyhttp.Response); ok {
+				orchestrator.ReportResponse(ctx, provider, resp.Response)
+			}
+			return out, md, err
+		}), middleware.After)
+	}
+}
+
+// idempotencyHeader sends the turn's idempotency key with each request.
+func idempotencyHeader(stack *middleware.Stack) error {
+	return stack.Build.Add(middleware.BuildMiddlewareFunc("IdempotencyKey", func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+		if req, ok := in.Request.(*smithyhttp.Request); ok {
+			if key := orchestrator.IdempotencyKeyFromContext(ctx); key != "" {
+				req.Header.Set(orchestrator.IdempotencyKeyHeader, key)
+			}
+		}
+		return next.HandleBuild(ctx, in)
+	}), middleware.After)
+}
+
+// apiError reports throttling as an orchestrator.RateLimitError, so the
+// orchestrator backs off as it does for other vendors.
+func apiError(provider string, err error) error {
+	var re *smithyhttp.ResponseError
+	if !errors.As(err, &re) || re.Response == nil {
+		return err
+	}
+	var ae smithy.APIError
+	throttled := errors.As(err, &ae) && (ae.ErrorCode() == "ThrottlingException" || ae.ErrorCode() == "TooManyRequestsException")
+	if re.HTTPStatusCode() == http.StatusTooManyRequests || throttled {
+		return orchestrator.NewRateLimitError(provider, re.Response.Response)
+	}
+	return err
+}
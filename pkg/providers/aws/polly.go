@@ -0,0 +1,243 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/polly"
+	"github.com/aws/aws-sdk-go-v2/service/polly/types"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// DefaultVoices maps the package's voices to Polly's US English voices,
+// all of which have neural versions.
+var DefaultVoices = map[orchestrator.Voice]string{
+	orchestrator.VoiceF1: "Joanna",
+	orchestrator.VoiceF2: "Salli",
+	orchestrator.VoiceF3: "Kimberly",
+	orchestrator.VoiceF4: "Kendra",
+	orchestrator.VoiceF5: "Ruth",
+	orchestrator.VoiceM1: "Matthew",
+	orchestrator.VoiceM2: "Joey",
+	orchestrator.VoiceM3: "Stephen",
+	orchestrator.VoiceM4: "Gregory",
+	orchestrator.VoiceM5: "Brian",
+}
+
+// LanguageVoices are the voices the package's female and male voices speak
+// other languages with, as Polly voices each speak one language.
+var LanguageVoices = map[orchestrator.Language]struct{ Female, Male string }{
+	orchestrator.LanguageEs: {"Lucia", "Sergio"},
+	orchestrator.LanguageFr: {"Lea", "Remi"},
+	orchestrator.LanguageDe: {"Vicki", "Daniel"},
+	orchestrator.LanguageIt: {"Bianca", "Adriano"},
+	orchestrator.LanguagePt: {"Camila", "Thiago"},
+	orchestrator.LanguageJa: {"Kazuha", "Takumi"},
+	orchestrator.LanguageZh: {"Zhiyu", "Zhiyu"},
+}
+
+// PollyTTS synthesizes speech with Amazon Polly. It asks for the neural
+// engine unless told otherwise and falls back to the standard engine for
+// voices that have no neural version.
+type PollyTTS struct {
+	cfg        awssdk.Config
+	api        *polly.Client
+	base       string
+	sampleRate int
+	engine     string
+	voices     map[orchestrator.Voice]string
+
+	mu      sync.Mutex
+	cancels map[*context.CancelFunc]struct{}
+}
+
+// NewPollyTTS returns a provider for cfg's region and credentials.
+func NewPollyTTS(cfg awssdk.Config) *PollyTTS {
+	t := &PollyTTS{
+		cfg:        cfg,
+		sampleRate: 44100,
+		engine:     "neural",
+		voices:     DefaultVoices,
+		cancels:    make(map[*context.CancelFunc]struct{}),
+	}
+	t.api = t.newClient()
+	return t
+}
+
+func (t *PollyTTS) newClient() *polly.Client {
+	return polly.NewFromConfig(t.cfg, func(o *polly.Options) {
+		if t.base != "" {
+			o.BaseEndpoint = awssdk.String(t.base)
+		}
+		o.APIOptions = append(o.APIOptions, reportResponses(t.Name()), idempotencyHeader)
+	})
+}
+
+// SetSampleRate sets the rate audio is delivered at; it should match
+// Config.SampleRate.
+func (t *PollyTTS) SetSampleRate(rate int) {
+	t.sampleRate = rate
+}
+
+// SetEngine sets the Polly engine, e.g. "standard", "neural" or
+// "generative".
+func (t *PollyTTS) SetEngine(engine string) {
+	t.engine = engine
+}
+
+// SetBaseURL replaces the regional endpoint, e.g. with a VPC endpoint.
+func (t *PollyTTS) SetBaseURL(base string) {
+	t.base = strings.TrimSuffix(base, "/")
+	t.api = t.newClient()
+}
+
+// SetHTTPClient sets the client requests are made with.
+func (t *PollyTTS) SetHTTPClient(client *http.Client) {
+	t.cfg.HTTPClient = client
+	t.api = t.newClient()
+}
+
+// SetVoices replaces the mapping from the package's voices to the Polly
+// voices that speak English. Voices missing from it are taken to be Polly
+// voice IDs themselves, so a session can use any voice, e.g. "Olivia",
+// directly, in whatever language it speaks.
+func (t *PollyTTS) SetVoices(voices map[orchestrator.Voice]string) {
+	t.voices = voices
+}
+
+func (t *PollyTTS) voiceID(voice orchestrator.Voice, lang orchestrator.Language) string {
+	if voice == "" {
+		voice = orchestrator.VoiceF1
+	}
+	name, ok := t.voices[voice]
+	if !ok {
+		return string(voice)
+	}
+	if lv, ok := LanguageVoices[lang]; ok {
+		if strings.HasPrefix(string(voice), "M") {
+			return lv.Male
+		}
+		return lv.Female
+	}
+	return name
+}
+
+// outputRate picks the PCM rate Polly offers closest above the pipeline's.
+func (t *PollyTTS) outputRate() int {
+	if t.sampleRate <= 8000 {
+		return 8000
+	}
+	return 16000
+}
+
+// input returns a SynthesizeSpeech request. Text that is already SSML is
+// sent as it is; plain text is wrapped in SSML only to change the speech
+// rate.
+func (t *PollyTTS) input(ctx context.Context, text, voiceID string, engine types.Engine) *polly.SynthesizeSpeechInput {
+	textType := types.TextTypeText
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "<speak") {
+		textType = types.TextTypeSsml
+	}
+	if speed := orchestrator.SpeechRateFromContext(ctx); speed != 1 {
+		content := text
+		if textType == types.TextTypeSsml {
+			if i := strings.Index(content, ">"); i >= 0 {
+				content = strings.TrimSuffix(content[i+1:], "</speak>")
+			}
+		} else {
+			content = xmlEscaper.Replace(content)
+		}
+		text = fmt.Sprintf(`<speak><prosody rate="%d%%">%s</prosody></speak>`, int(math.Round(speed*100)), content)
+		textType = types.TextTypeSsml
+	}
+	return &polly.SynthesizeSpeechInput{
+		Engine:       engine,
+		OutputFormat: types.OutputFormatPcm,
+		SampleRate:   awssdk.String(fmt.Sprint(t.outputRate())),
+		Text:         awssdk.String(text),
+		TextType:     textType,
+		VoiceId:      types.VoiceId(voiceID),
+	}
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+func (t *PollyTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	var out []byte
+	err := t.StreamSynthesize(ctx, text, voice, lang, func(chunk []byte) error {
+		out = append(out, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamSynthesize speaks text in voice, streaming audio as Polly sends it.
+func (t *PollyTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	t.mu.Lock()
+	t.cancels[&cancel] = struct{}{}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.cancels, &cancel)
+		t.mu.Unlock()
+		cancel()
+	}()
+
+	voiceID := t.voiceID(voice, lang)
+	out, err := t.api.SynthesizeSpeech(ctx, t.input(ctx, text, voiceID, types.Engine(t.engine)))
+	var unsupported *types.EngineNotSupportedException
+	if errors.As(err, &unsupported) && t.engine != string(types.EngineStandard) {
+		// The voice has no version for the engine asked for.
+		out, err = t.api.SynthesizeSpeech(ctx, t.input(ctx, text, voiceID, types.EngineStandard))
+	}
+	if err != nil {
+		return fmt.Errorf("polly: %w", apiError(t.Name(), err))
+	}
+	defer out.AudioStream.Close()
+
+	resampler := audio.NewResampler(t.outputRate(), t.sampleRate)
+	buf := make([]byte, 4096)
+	for {
+		n, err := out.AudioStream.Read(buf)
+		if n > 0 {
+			if chunk := resampler.Write(append([]byte(nil), buf[:n]...)); len(chunk) > 0 {
+				if err := onChunk(chunk); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Abort cancels every synthesis in progress.
+func (t *PollyTTS) Abort() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for cancel := range t.cancels {
+		(*cancel)()
+	}
+	return nil
+}
+
+func (t *PollyTTS) Name() string {
+	return "aws-polly"
+}
@@ -0,0 +1,94 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+var testCredentials = credentials.NewStaticCredentialsProvider("AKID", "secret", "")
+
+func TestPollyTTS(t *testing.T) {
+	var requests []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/speech" || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/polly/aws4_request") {
+			http.Error(w, "bad request", http.StatusForbidden)
+			return
+		}
+		var body map[string]string
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		requests = append(requests, body)
+		if body["VoiceId"] == "Brian" && body["Engine"] == "neural" {
+			w.Header().Set("X-Amzn-Errortype", "EngineNotSupportedException")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"This voice does not support the selected engine: neural"}`))
+			return
+		}
+		w.Header().Set("X-Amzn-Requestid", "req-1")
+		w.Write(make([]byte, 3200)) // 100ms at 16kHz
+	}))
+	defer server.Close()
+
+	p, err := New(Config{Region: "eu-west-1", Credentials: testCredentials, SampleRate: 16000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.TTS.SetBaseURL(server.URL)
+	ctx, trail := orchestrator.WithAuditTrail(context.Background())
+
+	audio, err := p.TTS.Synthesize(orchestrator.WithSpeechRate(ctx, 1.2), "Fish & chips", orchestrator.VoiceF1, orchestrator.LanguageEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audio) != 3200 {
+		t.Errorf("got %d bytes, want 3200", len(audio))
+	}
+	want := map[string]string{"Engine": "neural", "OutputFormat": "pcm", "SampleRate": "16000", "Text": `<speak><prosody rate="120%">Fish &amp; chips</prosody></speak>`, "TextType": "ssml", "VoiceId": "Joanna"}
+	for k, v := range want {
+		if requests[0][k] != v {
+			t.Errorf("%s = %q, want %q", k, requests[0][k], v)
+		}
+	}
+	if calls := trail.Calls(); len(calls) != 1 || calls[0].RequestID != "req-1" || calls[0].Provider != "aws-polly" {
+		t.Errorf("audit trail = %+v", calls)
+	}
+
+	// Other languages get that language's voice.
+	if _, err := p.TTS.Synthesize(ctx, "Hola", orchestrator.VoiceM2, orchestrator.LanguageEs); err != nil {
+		t.Fatal(err)
+	}
+	if requests[1]["VoiceId"] != "Sergio" || requests[1]["TextType"] != "text" {
+		t.Errorf("request = %v", requests[1])
+	}
+
+	// A voice with no neural version falls back to the standard engine.
+	if _, err := p.TTS.Synthesize(ctx, "Hello", orchestrator.VoiceM5, orchestrator.LanguageEn); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 4 || requests[3]["Engine"] != "standard" {
+		t.Errorf("requests = %v, want a retry with the standard engine", requests[2:])
+	}
+	if p.TTS.Name() != "aws-polly" || p.STT.Name() != "aws-transcribe" {
+		t.Errorf("unexpected names %q, %q", p.TTS.Name(), p.STT.Name())
+	}
+}
+
+// testConfig returns an SDK configuration for region that signs with
+// testCredentials.
+func testConfig(t *testing.T, region string) awssdk.Config {
+	t.Helper()
+	cfg, err := LoadConfig(context.Background(), Config{Region: region, Credentials: testCredentials})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
@@ -0,0 +1,27 @@
+package aws
+
+import (
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// "aws" is registered for both STT and TTS with
+// orchestrator.DefaultRegistry. Credentials come from the default chain;
+// region and base_url are honoured, and TTS takes an "engine" option.
+func init() {
+	orchestrator.RegisterSTT("aws", func(o orchestrator.ProviderOptions) (orchestrator.STTProvider, error) {
+		p, err := New(Config{Region: o.Region, SampleRate: o.SampleRate})
+		if err != nil {
+			return nil, err
+		}
+		orchestrator.ApplyProviderOptions(p.STT, o)
+		return p.STT, nil
+	})
+	orchestrator.RegisterTTS("aws", func(o orchestrator.ProviderOptions) (orchestrator.TTSProvider, error) {
+		p, err := New(Config{Region: o.Region, SampleRate: o.SampleRate, Engine: o.Option("engine", "")})
+		if err != nil {
+			return nil, err
+		}
+		orchestrator.ApplyProviderOptions(p.TTS, o)
+		return p.TTS, nil
+	})
+}
@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// S3Store uploads session archives to an S3 bucket, as an
+// orchestrator.PurgeableBlobStore. Labels become object tags, which bucket
+// lifecycle rules can filter on to expire or transition archives.
+//
+//	awsCfg, err := aws.LoadConfig(ctx, aws.Config{Region: "eu-west-1"})
+//	cfg.Archive = orchestrator.ArchiveConfig{Store: aws.NewS3Store(awsCfg, "call-archive")}
+type S3Store struct {
+	cfg          awssdk.Config
+	api          *s3.Client
+	bucket       string
+	base         string
+	storageClass string
+}
+
+// NewS3Store returns a store for bucket, in cfg's region and with its
+// credentials.
+func NewS3Store(cfg awssdk.Config, bucket string) *S3Store {
+	s := &S3Store{cfg: cfg, bucket: bucket}
+	s.api = s.newClient()
+	return s
+}
+
+func (s *S3Store) newClient() *s3.Client {
+	return s3.NewFromConfig(s.cfg, func(o *s3.Options) {
+		if s.base != "" {
+			o.BaseEndpoint = awssdk.String(s.base)
+			o.UsePathStyle = true
+		}
+		o.APIOptions = append(o.APIOptions, reportResponses("aws-s3"))
+	})
+}
+
+// SetBaseURL sends requests to another S3-compatible endpoint, such as
+// MinIO, addressing the bucket in the path.
+func (s *S3Store) SetBaseURL(base string) {
+	s.base = strings.TrimSuffix(base, "/")
+	s.api = s.newClient()
+}
+
+// SetHTTPClient sets the client requests are made with.
+func (s *S3Store) SetHTTPClient(client *http.Client) {
+	s.cfg.HTTPClient = client
+	s.api = s.newClient()
+}
+
+// SetStorageClass sets the class objects are stored in, e.g. STANDARD_IA.
+func (s *S3Store) SetStorageClass(class string) {
+	s.storageClass = class
+}
+
+func (s *S3Store) PutBlob(ctx context.Context, key string, data []byte, meta orchestrator.BlobMeta) error {
+	in := &s3.PutObjectInput{
+		Bucket: awssdk.String(s.bucket),
+		Key:    awssdk.String(key),
+		Body:   bytes.NewReader(data),
+	}
+	if meta.ContentType != "" {
+		in.ContentType = awssdk.String(meta.ContentType)
+	}
+	if len(meta.Labels) > 0 {
+		tags := url.Values{}
+		for k, v := range meta.Labels {
+			tags.Set(k, v)
+		}
+		in.Tagging = awssdk.String(tags.Encode())
+	}
+	if s.storageClass != "" {
+		in.StorageClass = s3types.StorageClass(s.storageClass)
+	}
+	if _, err := s.api.PutObject(ctx, in); err != nil {
+		return fmt.Errorf("s3 put %s: %w", key, apiError("aws-s3", err))
+	}
+	return nil
+}
+
+// ListBlobs returns the keys of the objects under prefix.
+func (s *S3Store) ListBlobs(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	pages := s3.NewListObjectsV2Paginator(s.api, &s3.ListObjectsV2Input{
+		Bucket: awssdk.String(s.bucket),
+		Prefix: awssdk.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return keys, fmt.Errorf("s3 list %s: %w", prefix, apiError("aws-s3", err))
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, awssdk.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
+
+func (s *S3Store) DeleteBlob(ctx context.Context, key string) error {
+	_, err := s.api.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: awssdk.String(s.bucket),
+		Key:    awssdk.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("s3 delete %s: %w", key, apiError("aws-s3", err))
+	}
+	return nil
+}
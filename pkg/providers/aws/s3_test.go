@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestS3Store(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	s := NewS3Store(testConfig(t, "eu-west-1"), "archive")
+	s.SetBaseURL(server.URL)
+	s.SetStorageClass("STANDARD_IA")
+	data := []byte("bundle")
+	err := s.PutBlob(context.Background(), "calls/2024/01/02/s 1.tar.gz", data, orchestrator.BlobMeta{ContentType: "application/gzip", Labels: map[string]string{"retention": "90d"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Method != "PUT" || got.URL.EscapedPath() != "/archive/calls/2024/01/02/s%201.tar.gz" || string(body) != "bundle" {
+		t.Errorf("request = %s %s", got.Method, got.URL.EscapedPath())
+	}
+	if got.Header.Get("X-Amz-Tagging") != "retention=90d" || got.Header.Get("X-Amz-Storage-Class") != "STANDARD_IA" || got.Header.Get("Content-Type") != "application/gzip" {
+		t.Errorf("headers = %v", got.Header)
+	}
+	auth := got.Header.Get("Authorization")
+	if !strings.Contains(auth, "/eu-west-1/s3/aws4_request") || !strings.Contains(auth, "x-amz-tagging") {
+		t.Errorf("Authorization = %s, want the tags signed", auth)
+	}
+}
+
+func TestS3Store_ListAndDelete(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			if r.URL.Path != "/archive" || r.URL.Query().Get("prefix") != "calls/users/alice/" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/xml")
+			if r.URL.Query().Get("continuation-token") == "" {
+				w.Write([]byte(`<ListBucketResult><IsTruncated>true</IsTruncated><NextContinuationToken>t2</NextContinuationToken><Contents><Key>calls/users/alice/2024/01/02/s1.tar.gz</Key></Contents></ListBucketResult>`))
+				return
+			}
+			w.Write([]byte(`<ListBucketResult><IsTruncated>false</IsTruncated><Contents><Key>calls/users/alice/2024/01/03/s2.tar.gz</Key></Contents></ListBucketResult>`))
+		case "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	s := NewS3Store(testConfig(t, "eu-west-1"), "archive")
+	s.SetBaseURL(server.URL)
+	var _ orchestrator.PurgeableBlobStore = s
+	keys, err := s.ListBlobs(context.Background(), "calls/users/alice/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[1] != "calls/users/alice/2024/01/03/s2.tar.gz" {
+		t.Errorf("keys = %v", keys)
+	}
+	if err := s.DeleteBlob(context.Background(), keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0] != "/archive/calls/users/alice/2024/01/02/s1.tar.gz" {
+		t.Errorf("deleted %v", deleted)
+	}
+}
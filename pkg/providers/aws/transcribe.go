@@ -0,0 +1,342 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// transcribeRate is the rate audio is streamed to Transcribe at.
+const transcribeRate = 16000
+
+// audioChunk is how much audio, 100ms at transcribeRate, goes in one
+// AudioEvent; Transcribe takes between 50 and 200ms per event.
+const audioChunk = transcribeRate * 2 / 10
+
+// streamEndTimeout is how long a stream waits, once its audio has ended,
+// for Transcribe to send the last results.
+const streamEndTimeout = 5 * time.Second
+
+// TranscribeSTT transcribes speech with Amazon Transcribe streaming over a
+// WebSocket. Transcribe has no request for a single utterance, so
+// Transcribe streams the utterance and waits for its final results;
+// StreamTranscribe keeps a stream open, reporting partial results as the
+// user speaks.
+//
+// The SDK streams Transcribe over HTTP/2; the provider uses Transcribe's
+// WebSocket API instead, presigned with the SDK's signer, so it works
+// through proxies and load balancers that only pass HTTP/1.1.
+type TranscribeSTT struct {
+	cfg        awssdk.Config
+	base       string // Replaces the regional endpoint when set
+	client     *http.Client
+	sampleRate int
+	languages  map[orchestrator.Language]string
+}
+
+// NewTranscribeSTT returns a provider for cfg's region and credentials.
+func NewTranscribeSTT(cfg awssdk.Config) *TranscribeSTT {
+	s := &TranscribeSTT{
+		cfg:        cfg,
+		sampleRate: 44100,
+		languages:  DefaultLanguageCodes,
+	}
+	s.client, _ = cfg.HTTPClient.(*http.Client)
+	return s
+}
+
+// SetSampleRate sets the rate of the audio to transcribe; it should match
+// Config.SampleRate.
+func (s *TranscribeSTT) SetSampleRate(rate int) {
+	s.sampleRate = rate
+}
+
+// SetBaseURL replaces the regional endpoint, e.g. with a VPC endpoint's
+// wss:// address.
+func (s *TranscribeSTT) SetBaseURL(base string) {
+	s.base = strings.TrimSuffix(base, "/")
+}
+
+// SetHTTPClient sets the client the WebSocket handshake is made with.
+func (s *TranscribeSTT) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// SetLanguageCodes replaces the mapping from languages to Transcribe
+// language codes. Languages missing from it are taken to be codes
+// themselves, so "en-GB" works as it is.
+func (s *TranscribeSTT) SetLanguageCodes(codes map[orchestrator.Language]string) {
+	s.languages = codes
+}
+
+func (s *TranscribeSTT) Name() string {
+	return "aws-transcribe"
+}
+
+func (s *TranscribeSTT) languageCode(lang orchestrator.Language) string {
+	if lang == "" {
+		lang = orchestrator.LanguageEn
+	}
+	if code, ok := s.languages[lang]; ok {
+		return code
+	}
+	return string(lang)
+}
+
+// dial opens a presigned stream for lang.
+func (s *TranscribeSTT) dial(ctx context.Context, lang orchestrator.Language) (*websocket.Conn, error) {
+	if s.cfg.Credentials == nil {
+		return nil, errors.New("aws transcribe: no credentials")
+	}
+	creds, err := s.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base := s.base
+	if base == "" {
+		base = "wss://transcribestreaming." + s.cfg.Region + ".amazonaws.com:8443"
+	}
+	u, err := url.Parse(base + "/stream-transcription-websocket")
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("language-code", s.languageCode(lang))
+	q.Set("media-encoding", "pcm")
+	q.Set("sample-rate", fmt.Sprint(transcribeRate))
+	q.Set("X-Amz-Expires", "300")
+	u.RawQuery = q.Encode()
+	req := &http.Request{Method: "GET", URL: u, Host: u.Host, Header: http.Header{}}
+	signed, _, err := v4.NewSigner().PresignHTTP(ctx, creds, req, emptyPayloadHash, "transcribe", s.cfg.Region, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("aws transcribe: %w", err)
+	}
+
+	conn, resp, err := websocket.Dial(ctx, signed, &websocket.DialOptions{HTTPClient: s.client})
+	orchestrator.ReportResponse(ctx, s.Name(), resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to aws transcribe: %w", err)
+	}
+	conn.SetReadLimit(1024 * 1024)
+	return conn, nil
+}
+
+func (s *TranscribeSTT) Transcribe(ctx context.Context, audioPCM []byte, lang orchestrator.Language) (orchestrator.TranscriptionResult, error) {
+	conn, err := s.dial(ctx, lang)
+	if err != nil {
+		return orchestrator.TranscriptionResult{}, err
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	sent := make(chan error, 1)
+	go func() {
+		pcm := audio.Resample(audioPCM, s.sampleRate, transcribeRate)
+		for len(pcm) > 0 {
+			n := min(audioChunk, len(pcm))
+			if err := sendAudio(ctx, conn, pcm[:n]); err != nil {
+				sent <- err
+				return
+			}
+			pcm = pcm[n:]
+		}
+		sent <- sendAudio(ctx, conn, nil)
+	}()
+
+	var texts []string
+	var words []orchestrator.WordTiming
+	var confidence float64
+	var scored int
+	err = receiveResults(ctx, conn, func(r transcriptResult) error {
+		if r.IsPartial || len(r.Alternatives) == 0 {
+			return nil
+		}
+		alt := r.Alternatives[0]
+		if t := strings.TrimSpace(alt.Transcript); t != "" {
+			texts = append(texts, t)
+		}
+		for _, item := range alt.Items {
+			if item.Type != "pronunciation" {
+				continue
+			}
+			words = append(words, orchestrator.WordTiming{
+				Word:  item.Content,
+				Start: seconds(item.StartTime),
+				End:   seconds(item.EndTime),
+			})
+			confidence += item.Confidence
+			scored++
+		}
+		return nil
+	})
+	if err != nil {
+		// Unblocks the sender if Transcribe stopped reading.
+		conn.Close(websocket.StatusInternalError, "")
+		<-sent
+		return orchestrator.TranscriptionResult{}, err
+	}
+	if err := <-sent; err != nil {
+		return orchestrator.TranscriptionResult{}, err
+	}
+
+	if len(texts) == 0 {
+		return orchestrator.TranscriptionResult{NoSpeechProb: 1}, nil
+	}
+	result := orchestrator.TranscriptionResult{Text: strings.Join(texts, " "), Words: words}
+	if scored > 0 {
+		result.NoSpeechProb = 1 - confidence/float64(scored)
+	}
+	return result, nil
+}
+
+// StreamTranscribe streams audio until the returned channel is closed or
+// ctx is done. Transcribe ends results on its own pauses; each is reported
+// final once, with its partial results before it.
+func (s *TranscribeSTT) StreamTranscribe(ctx context.Context, lang orchestrator.Language, onTranscript func(transcript string, isFinal bool) error) (chan<- []byte, error) {
+	conn, err := s.dial(ctx, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	in := make(chan []byte, 256)
+	go func() {
+		resampler := audio.NewResampler(s.sampleRate, transcribeRate)
+		var pending []byte
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case chunk, ok := <-in:
+				if !ok {
+					if len(pending) > 0 {
+						sendAudio(ctx, conn, pending)
+					}
+					// An empty event ends the stream; Transcribe sends what
+					// is left of the results and closes.
+					sendAudio(ctx, conn, nil)
+					time.AfterFunc(streamEndTimeout, func() { conn.Close(websocket.StatusNormalClosure, "") })
+					return
+				}
+				pending = append(pending, resampler.Write(chunk)...)
+				if len(pending) < audioChunk {
+					continue
+				}
+				if err := sendAudio(ctx, conn, pending); err != nil {
+					return
+				}
+				pending = nil
+			}
+		}
+	}()
+	go func() {
+		defer orchestrator.ReportTranscriptionDone(ctx)
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		receiveResults(ctx, conn, func(r transcriptResult) error {
+			if len(r.Alternatives) == 0 || r.Alternatives[0].Transcript == "" {
+				return nil
+			}
+			return onTranscript(r.Alternatives[0].Transcript, !r.IsPartial)
+		})
+	}()
+	return in, nil
+}
+
+// emptyPayloadHash is the SHA-256 of the empty body a presigned URL is
+// signed with.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// sendAudio sends pcm as an AudioEvent; empty pcm ends the stream.
+func sendAudio(ctx context.Context, conn *websocket.Conn, pcm []byte) error {
+	var headers eventstream.Headers
+	headers.Set(":content-type", eventstream.StringValue("application/octet-stream"))
+	headers.Set(":event-type", eventstream.StringValue("AudioEvent"))
+	headers.Set(":message-type", eventstream.StringValue("event"))
+	var msg bytes.Buffer
+	if err := eventstream.NewEncoder().Encode(&msg, eventstream.Message{Headers: headers, Payload: pcm}); err != nil {
+		return err
+	}
+	return conn.Write(ctx, websocket.MessageBinary, msg.Bytes())
+}
+
+// header returns a message's string header, or "" if it has none.
+func header(msg eventstream.Message, name string) string {
+	if v := msg.Headers.Get(name); v != nil {
+		return v.String()
+	}
+	return ""
+}
+
+// transcriptResult is one result of a TranscriptEvent. Times are in
+// seconds from the start of the stream.
+type transcriptResult struct {
+	IsPartial    bool `json:"IsPartial"`
+	Alternatives []struct {
+		Transcript string `json:"Transcript"`
+		Items      []struct {
+			Content    string  `json:"Content"`
+			Type       string  `json:"Type"`
+			StartTime  float64 `json:"StartTime"`
+			EndTime    float64 `json:"EndTime"`
+			Confidence float64 `json:"Confidence"`
+		} `json:"Items"`
+	} `json:"Alternatives"`
+}
+
+// receiveResults passes each result to onResult until the stream ends. An
+// exception from Transcribe is returned as an error; the stream closing, as
+// Transcribe does once the audio has ended, is not.
+func receiveResults(ctx context.Context, conn *websocket.Conn, onResult func(transcriptResult) error) error {
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			if websocket.CloseStatus(err) == websocket.StatusNormalClosure || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		msg, err := eventstream.NewDecoder().Decode(bytes.NewReader(data), nil)
+		if err != nil {
+			return err
+		}
+		if header(msg, ":message-type") == "exception" {
+			var e struct {
+				Message string `json:"Message"`
+			}
+			json.Unmarshal(msg.Payload, &e)
+			return fmt.Errorf("aws transcribe error: %s: %s", header(msg, ":exception-type"), e.Message)
+		}
+		if header(msg, ":event-type") != "TranscriptEvent" {
+			continue
+		}
+		var event struct {
+			Transcript struct {
+				Results []transcriptResult `json:"Results"`
+			} `json:"Transcript"`
+		}
+		if err := json.Unmarshal(msg.Payload, &event); err != nil {
+			return err
+		}
+		for _, r := range event.Transcript.Results {
+			if err := onResult(r); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
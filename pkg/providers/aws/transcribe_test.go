@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// transcribeServer accepts presigned streams and answers each AudioEvent
+// with a partial result and the end of the audio with the final one.
+func transcribeServer(t *testing.T, audioBytes *int, mu *sync.Mutex) *httptest.Server {
+	event := func(partial bool, text string) []byte {
+		payload := fmt.Sprintf(`{"Transcript":{"Results":[{"IsPartial":%t,"Alternatives":[{"Transcript":%q,"Items":[{"Content":"Book","Type":"pronunciation","StartTime":0.1,"EndTime":0.4,"Confidence":0.9},{"Content":".","Type":"punctuation"}]}]}]}}`, partial, text)
+		var headers eventstream.Headers
+		headers.Set(":event-type", eventstream.StringValue("TranscriptEvent"))
+		headers.Set(":message-type", eventstream.StringValue("event"))
+		headers.Set(":content-type", eventstream.StringValue("application/json"))
+		var buf bytes.Buffer
+		eventstream.NewEncoder().Encode(&buf, eventstream.Message{Headers: headers, Payload: []byte(payload)})
+		return buf.Bytes()
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/stream-transcription-websocket" || q.Get("language-code") != "es-US" || q.Get("sample-rate") != "16000" || !strings.HasPrefix(q.Get("X-Amz-Credential"), "AKID/") || q.Get("X-Amz-Signature") == "" {
+			http.Error(w, "bad request", http.StatusForbidden)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		for {
+			_, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			msg, err := eventstream.NewDecoder().Decode(bytes.NewReader(data), nil)
+			if err != nil || header(msg, ":event-type") != "AudioEvent" {
+				t.Errorf("unexpected message %+v, %v", msg, err)
+				return
+			}
+			if len(msg.Payload) == 0 {
+				conn.Write(r.Context(), websocket.MessageBinary, event(false, "Book."))
+				return
+			}
+			mu.Lock()
+			*audioBytes += len(msg.Payload)
+			mu.Unlock()
+			conn.Write(r.Context(), websocket.MessageBinary, event(true, "book"))
+		}
+	}))
+}
+
+func TestTranscribeSTT_Transcribe(t *testing.T) {
+	var mu sync.Mutex
+	var audioBytes int
+	server := transcribeServer(t, &audioBytes, &mu)
+	defer server.Close()
+
+	s := NewTranscribeSTT(testConfig(t, "us-east-1"))
+	s.SetBaseURL("ws" + strings.TrimPrefix(server.URL, "http"))
+	s.SetSampleRate(16000)
+	res, err := s.Transcribe(context.Background(), make([]byte, 16000), orchestrator.LanguageEs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Text != "Book." || len(res.Words) != 1 || res.Words[0].Start != 100*time.Millisecond || res.Words[0].End != 400*time.Millisecond {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if res.NoSpeechProb < 0.09 || res.NoSpeechProb > 0.11 {
+		t.Errorf("NoSpeechProb = %v, want 0.1", res.NoSpeechProb)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if audioBytes != 16000 {
+		t.Errorf("sent %d bytes of audio, want 16000", audioBytes)
+	}
+}
+
+func TestTranscribeSTT_StreamTranscribe(t *testing.T) {
+	var mu sync.Mutex
+	var audioBytes int
+	server := transcribeServer(t, &audioBytes, &mu)
+	defer server.Close()
+
+	s := NewTranscribeSTT(testConfig(t, "us-east-1"))
+	s.SetBaseURL("ws" + strings.TrimPrefix(server.URL, "http"))
+	s.SetSampleRate(16000)
+	type transcript struct {
+		text  string
+		final bool
+	}
+	got := make(chan transcript, 10)
+	in, err := s.StreamTranscribe(context.Background(), orchestrator.LanguageEs, func(text string, isFinal bool) error {
+		got <- transcript{text, isFinal}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in <- make([]byte, 1600) // Held until a whole event's worth has arrived
+	in <- make([]byte, 1600)
+	if tr := <-got; tr.text != "book" || tr.final {
+		t.Errorf("first transcript = %+v, want the partial result", tr)
+	}
+	close(in)
+	select {
+	case tr := <-got:
+		if tr.text != "Book." || !tr.final {
+			t.Errorf("final transcript = %+v", tr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no final transcript after the audio ended")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if audioBytes != 3200 {
+		t.Errorf("sent %d bytes of audio, want 3200", audioBytes)
+	}
+}
@@ -0,0 +1,125 @@
+// Package azurespeech provides Azure AI Speech providers: STT, one-shot
+// and continuous, and neural TTS with SSML. Both authenticate with the key
+// and region of a Speech resource.
+//
+//	p := azurespeech.New(azurespeech.Config{Key: os.Getenv("AZURE_SPEECH_KEY"), Region: "westeurope"})
+//	orch := orchestrator.NewWithVAD(p.STT, llm, p.TTS, vad, cfg)
+package azurespeech
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// Config configures the providers. Key and Region are required, unless
+// BaseURL points at a resource that needs neither, such as a container.
+type Config struct {
+	Key        string
+	Region     string                           // The resource's region, e.g. westeurope
+	BaseURL    string                           // Replaces the region's STT and TTS hosts, e.g. http://localhost:5000
+	SampleRate int                              // Of the pipeline's audio, as Config.SampleRate; defaults to 44100
+	Locales    map[orchestrator.Language]string // Replaces DefaultLocales
+	Voices     map[orchestrator.Voice]string    // Replaces DefaultVoices
+	HTTPClient *http.Client
+}
+
+// Providers is a matching set of Azure providers. STT also streams, with
+// continuous recognition, and TTS streams audio.
+type Providers struct {
+	STT *STT
+	TTS *TTS
+}
+
+// New returns the providers for cfg.
+func New(cfg Config) *Providers {
+	p := &Providers{
+		STT: NewSTT(cfg.Key, cfg.Region),
+		TTS: NewTTS(cfg.Key, cfg.Region),
+	}
+	if cfg.BaseURL != "" {
+		p.STT.SetBaseURL(cfg.BaseURL)
+		p.TTS.SetBaseURL(cfg.BaseURL)
+	}
+	if cfg.SampleRate > 0 {
+		p.STT.SetSampleRate(cfg.SampleRate)
+		p.TTS.SetSampleRate(cfg.SampleRate)
+	}
+	if cfg.Locales != nil {
+		p.STT.SetLocales(cfg.Locales)
+		p.TTS.SetLocales(cfg.Locales)
+	}
+	if cfg.Voices != nil {
+		p.TTS.SetVoices(cfg.Voices)
+	}
+	if cfg.HTTPClient != nil {
+		p.STT.SetHTTPClient(cfg.HTTPClient)
+		p.TTS.SetHTTPClient(cfg.HTTPClient)
+	}
+	return p
+}
+
+// DefaultLocales maps the package's languages to the locales Azure
+// recognizes and speaks them in.
+var DefaultLocales = map[orchestrator.Language]string{
+	orchestrator.LanguageEn: "en-US",
+	orchestrator.LanguageEs: "es-ES",
+	orchestrator.LanguageFr: "fr-FR",
+	orchestrator.LanguageDe: "de-DE",
+	orchestrator.LanguageIt: "it-IT",
+	orchestrator.LanguagePt: "pt-BR",
+	orchestrator.LanguageJa: "ja-JP",
+	orchestrator.LanguageZh: "zh-CN",
+}
+
+// locale returns the locale lang is spoken in. Languages missing from
+// locales are taken to be locales themselves, so "en-GB" works as it is.
+func locale(locales map[orchestrator.Language]string, lang orchestrator.Language) string {
+	if lang == "" {
+		lang = orchestrator.LanguageEn
+	}
+	if l, ok := locales[lang]; ok {
+		return l
+	}
+	return string(lang)
+}
+
+// account is the resource requests go to.
+type account struct {
+	key    string
+	region string
+	base   string // Replaces the region's host when set
+	client *http.Client
+}
+
+// url returns the address of path on the resource's service, e.g. "stt".
+func (a *account) url(service, path string) string {
+	if a.base != "" {
+		return a.base + path
+	}
+	return "https://" + a.region + "." + service + ".speech.microsoft.com" + path
+}
+
+func (a *account) authorize(h http.Header) {
+	if a.key != "" {
+		h.Set("Ocp-Apim-Subscription-Key", a.key)
+	}
+}
+
+func (a *account) do(req *http.Request) (*http.Response, error) {
+	a.authorize(req.Header)
+	client := a.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// requestID returns an ID in the form the service expects: 32 hex digits.
+func requestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
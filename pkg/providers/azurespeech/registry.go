@@ -0,0 +1,33 @@
+package azurespeech
+
+import (
+	"fmt"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// "azure" is registered for both STT and TTS with
+// orchestrator.DefaultRegistry, configured by api_key, region and base_url.
+func init() {
+	orchestrator.RegisterSTT("azure", func(o orchestrator.ProviderOptions) (orchestrator.STTProvider, error) {
+		p, err := fromOptions(o)
+		if err != nil {
+			return nil, err
+		}
+		return p.STT, nil
+	})
+	orchestrator.RegisterTTS("azure", func(o orchestrator.ProviderOptions) (orchestrator.TTSProvider, error) {
+		p, err := fromOptions(o)
+		if err != nil {
+			return nil, err
+		}
+		return p.TTS, nil
+	})
+}
+
+func fromOptions(o orchestrator.ProviderOptions) (*Providers, error) {
+	if o.BaseURL == "" && (o.APIKey == "" || o.Region == "") {
+		return nil, fmt.Errorf("azure: api_key and region are required")
+	}
+	return New(Config{Key: o.APIKey, Region: o.Region, BaseURL: o.BaseURL, SampleRate: o.SampleRate}), nil
+}
@@ -0,0 +1,289 @@
+package azurespeech
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// recognitionRate is the rate audio is sent to the recognizer at.
+const recognitionRate = 16000
+
+const recognitionPath = "/speech/recognition/conversation/cognitiveservices/v1"
+
+// turnEndTimeout is how long a recognition waits, once its audio has
+// ended, for the service to finish the last phrase.
+const turnEndTimeout = 5 * time.Second
+
+// STT transcribes speech with Azure. Transcribe sends an utterance to the
+// REST API for short audio; StreamTranscribe keeps a recognition running
+// over a WebSocket, reporting hypotheses as the user speaks and a final
+// transcript for each phrase.
+type STT struct {
+	account
+	sampleRate int
+	locales    map[orchestrator.Language]string
+}
+
+// NewSTT returns a provider for the Speech resource with key in region.
+func NewSTT(key, region string) *STT {
+	return &STT{
+		account:    account{key: key, region: region},
+		sampleRate: 44100,
+		locales:    DefaultLocales,
+	}
+}
+
+// SetSampleRate sets the rate of the audio to transcribe; it should match
+// Config.SampleRate.
+func (s *STT) SetSampleRate(rate int) {
+	s.sampleRate = rate
+}
+
+// SetBaseURL points the provider at another host, such as a Speech
+// container, given its base URL.
+func (s *STT) SetBaseURL(base string) {
+	s.base = strings.TrimSuffix(base, "/")
+}
+
+// SetHTTPClient sets the client requests are made with.
+func (s *STT) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// SetLocales replaces the mapping from languages to recognition locales.
+func (s *STT) SetLocales(locales map[orchestrator.Language]string) {
+	s.locales = locales
+}
+
+func (s *STT) Name() string {
+	return "azure-stt"
+}
+
+func (s *STT) query(lang orchestrator.Language) string {
+	q := url.Values{}
+	q.Set("language", locale(s.locales, lang))
+	q.Set("format", "detailed")
+	q.Set("wordLevelTimestamps", "true")
+	return q.Encode()
+}
+
+func (s *STT) Transcribe(ctx context.Context, audioPCM []byte, lang orchestrator.Language) (orchestrator.TranscriptionResult, error) {
+	wav := audio.NewWavBuffer(audio.Resample(audioPCM, s.sampleRate, recognitionRate), recognitionRate)
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url("stt", recognitionPath)+"?"+s.query(lang), bytes.NewReader(wav))
+	if err != nil {
+		return orchestrator.TranscriptionResult{}, err
+	}
+	req.Header.Set("Content-Type", fmt.Sprintf("audio/wav; codecs=audio/pcm; samplerate=%d", recognitionRate))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.do(req)
+	if err != nil {
+		return orchestrator.TranscriptionResult{}, err
+	}
+	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, s.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return orchestrator.TranscriptionResult{}, orchestrator.NewRateLimitError(s.Name(), resp)
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return orchestrator.TranscriptionResult{}, fmt.Errorf("azure stt error: %s (status %d)", string(respBody), resp.StatusCode)
+	}
+
+	var result phrase
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return orchestrator.TranscriptionResult{}, err
+	}
+	return result.transcription()
+}
+
+// phrase is a recognition result, from the REST API or a speech.phrase
+// message. Offsets and durations are in ticks of 100ns.
+type phrase struct {
+	RecognitionStatus string `json:"RecognitionStatus"`
+	DisplayText       string `json:"DisplayText"`
+	NBest             []struct {
+		Confidence float64 `json:"Confidence"`
+		Display    string  `json:"Display"`
+		Words      []struct {
+			Word     string `json:"Word"`
+			Offset   int64  `json:"Offset"`
+			Duration int64  `json:"Duration"`
+		} `json:"Words"`
+	} `json:"NBest"`
+}
+
+func (p phrase) transcription() (orchestrator.TranscriptionResult, error) {
+	switch p.RecognitionStatus {
+	case "Success":
+	case "NoMatch", "InitialSilenceTimeout", "BabbleTimeout":
+		return orchestrator.TranscriptionResult{NoSpeechProb: 1}, nil
+	default:
+		return orchestrator.TranscriptionResult{}, fmt.Errorf("azure stt error: recognition status %s", p.RecognitionStatus)
+	}
+	if len(p.NBest) == 0 {
+		return orchestrator.TranscriptionResult{Text: p.DisplayText}, nil
+	}
+	best := p.NBest[0]
+	var words []orchestrator.WordTiming
+	for _, w := range best.Words {
+		words = append(words, orchestrator.WordTiming{
+			Word:  w.Word,
+			Start: time.Duration(w.Offset) * 100,
+			End:   time.Duration(w.Offset+w.Duration) * 100,
+		})
+	}
+	return orchestrator.TranscriptionResult{
+		Text:         best.Display,
+		NoSpeechProb: 1.0 - best.Confidence,
+		Words:        words,
+	}, nil
+}
+
+// StreamTranscribe runs continuous recognition until the returned channel
+// is closed or ctx is done. Azure ends phrases on its own silence
+// detection; each is reported final once, with its hypotheses before it.
+func (s *STT) StreamTranscribe(ctx context.Context, lang orchestrator.Language, onTranscript func(transcript string, isFinal bool) error) (chan<- []byte, error) {
+	u := s.url("stt", recognitionPath) + "?" + s.query(lang)
+	u = "ws" + strings.TrimPrefix(u, "http")
+	header := http.Header{}
+	s.authorize(header)
+	header.Set("X-ConnectionId", requestID())
+	conn, resp, err := websocket.Dial(ctx, u, &websocket.DialOptions{HTTPHeader: header, HTTPClient: s.client})
+	orchestrator.ReportResponse(ctx, s.Name(), resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to azure stt: %w", err)
+	}
+	conn.SetReadLimit(1024 * 1024)
+
+	r := &recognition{conn: conn, id: requestID()}
+	config := `{"context":{"system":{"name":"lokutor-orchestrator","version":"1.0.0"},"os":{"platform":"go"},"audio":{"source":{"samplerate":16000,"bitspersample":16,"channelcount":1}}}}`
+	if err := r.send(ctx, "speech.config", "application/json", config); err != nil {
+		conn.Close(websocket.StatusAbnormalClosure, "failed to configure")
+		return nil, fmt.Errorf("failed to configure azure stt: %w", err)
+	}
+	// The stream's audio starts with a WAV header of unknown length.
+	if err := r.audio(ctx, audio.NewWavBuffer(nil, recognitionRate)); err != nil {
+		conn.Close(websocket.StatusAbnormalClosure, "failed to send audio")
+		return nil, fmt.Errorf("failed to start azure stt: %w", err)
+	}
+
+	in := make(chan []byte, 256)
+	go r.stream(ctx, in, s.sampleRate)
+	go r.receive(ctx, onTranscript)
+	return in, nil
+}
+
+// recognition is one continuous recognition over a WebSocket, in the
+// protocol the Speech SDK speaks: text messages are headers and a body,
+// audio messages a length-prefixed header block and the audio.
+type recognition struct {
+	conn *websocket.Conn
+	id   string
+}
+
+func (r *recognition) headers(path, contentType string) string {
+	return "Path: " + path + "\r\nX-RequestId: " + r.id + "\r\nX-Timestamp: " + time.Now().UTC().Format("2006-01-02T15:04:05.000Z") + "\r\nContent-Type: " + contentType + "\r\n"
+}
+
+func (r *recognition) send(ctx context.Context, path, contentType, body string) error {
+	return r.conn.Write(ctx, websocket.MessageText, []byte(r.headers(path, contentType)+"\r\n"+body))
+}
+
+func (r *recognition) audio(ctx context.Context, pcm []byte) error {
+	h := r.headers("audio", "audio/x-wav")
+	msg := make([]byte, 2, 2+len(h)+len(pcm))
+	binary.BigEndian.PutUint16(msg, uint16(len(h)))
+	msg = append(msg, h...)
+	msg = append(msg, pcm...)
+	return r.conn.Write(ctx, websocket.MessageBinary, msg)
+}
+
+// stream sends the audio from in, at rate, until in is closed or ctx is
+// done.
+func (r *recognition) stream(ctx context.Context, in <-chan []byte, rate int) {
+	resampler := audio.NewResampler(rate, recognitionRate)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case chunk, ok := <-in:
+			if !ok {
+				// An empty audio message ends the stream: the service
+				// finishes the phrase in progress and ends the turn.
+				r.audio(ctx, nil)
+				time.AfterFunc(turnEndTimeout, func() { r.conn.Close(websocket.StatusNormalClosure, "") })
+				return
+			}
+			if pcm := resampler.Write(chunk); len(pcm) > 0 {
+				if err := r.audio(ctx, pcm); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// receive reports results until the turn ends, the connection fails or ctx
+// is done.
+func (r *recognition) receive(ctx context.Context, onTranscript func(string, bool) error) {
+	defer orchestrator.ReportTranscriptionDone(ctx)
+	defer r.conn.Close(websocket.StatusNormalClosure, "")
+	for {
+		typ, data, err := r.conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		if typ != websocket.MessageText {
+			continue
+		}
+		path, body := parseMessage(string(data))
+		switch path {
+		case "speech.hypothesis":
+			var h struct {
+				Text string `json:"Text"`
+			}
+			if json.Unmarshal([]byte(body), &h) == nil && h.Text != "" {
+				if onTranscript(h.Text, false) != nil {
+					return
+				}
+			}
+		case "speech.phrase":
+			var p phrase
+			if json.Unmarshal([]byte(body), &p) != nil {
+				continue
+			}
+			if res, err := p.transcription(); err == nil && res.Text != "" {
+				if onTranscript(res.Text, true) != nil {
+					return
+				}
+			}
+		case "turn.end":
+			return
+		}
+	}
+}
+
+// parseMessage splits a text message into its Path header and its body.
+func parseMessage(msg string) (path, body string) {
+	head, body, _ := strings.Cut(msg, "\r\n\r\n")
+	for _, line := range strings.Split(head, "\r\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "path") {
+			path = strings.TrimSpace(value)
+		}
+	}
+	return path, body
+}
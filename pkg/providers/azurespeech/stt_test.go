@@ -0,0 +1,128 @@
+package azurespeech
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestSTT_Transcribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "test-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != recognitionPath || r.URL.Query().Get("language") != "es-ES" || !strings.Contains(r.Header.Get("Content-Type"), "samplerate=16000") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"RecognitionStatus":"Success","DisplayText":"Hola.","NBest":[{"Confidence":0.9,"Display":"Hola.","Words":[{"Word":"hola","Offset":1000000,"Duration":4000000}]}]}`))
+	}))
+	defer server.Close()
+
+	s := NewSTT("test-key", "westeurope")
+	s.SetBaseURL(server.URL)
+	res, err := s.Transcribe(context.Background(), make([]byte, 44100), orchestrator.LanguageEs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Text != "Hola." || len(res.Words) != 1 || res.Words[0].Start != 100*time.Millisecond || res.Words[0].End != 500*time.Millisecond {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if res.NoSpeechProb < 0.09 || res.NoSpeechProb > 0.11 {
+		t.Errorf("NoSpeechProb = %v, want 0.1", res.NoSpeechProb)
+	}
+}
+
+func TestSTT_StreamTranscribe(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	var audioBytes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "test-key" || r.URL.Query().Get("language") != "en-US" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		send := func(path, body string) {
+			conn.Write(r.Context(), websocket.MessageText, []byte("X-RequestId: 1\r\nPath: "+path+"\r\nContent-Type: application/json\r\n\r\n"+body))
+		}
+		for {
+			typ, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			var path string
+			if typ == websocket.MessageText {
+				path, _ = parseMessage(string(data))
+			} else {
+				n := int(binary.BigEndian.Uint16(data))
+				path, _ = parseMessage(string(data[2:2+n]) + "\r\n")
+				mu.Lock()
+				audioBytes += len(data) - 2 - n
+				mu.Unlock()
+				if len(data) == 2+n {
+					// End of audio.
+					send("speech.phrase", `{"RecognitionStatus":"Success","DisplayText":"Book a table.","NBest":[{"Confidence":0.95,"Display":"Book a table."}]}`)
+					send("turn.end", `{}`)
+				} else if audioBytes > 44 {
+					send("speech.hypothesis", `{"Text":"book a"}`)
+				}
+			}
+			mu.Lock()
+			paths = append(paths, path)
+			mu.Unlock()
+		}
+	}))
+	defer server.Close()
+
+	s := NewSTT("test-key", "westeurope")
+	s.SetBaseURL(server.URL)
+	s.SetSampleRate(16000)
+	type transcript struct {
+		text  string
+		final bool
+	}
+	got := make(chan transcript, 10)
+	in, err := s.StreamTranscribe(context.Background(), orchestrator.LanguageEn, func(text string, isFinal bool) error {
+		got <- transcript{text, isFinal}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in <- make([]byte, 3200)
+	if tr := <-got; tr.text != "book a" || tr.final {
+		t.Errorf("first transcript = %+v, want the hypothesis", tr)
+	}
+	close(in)
+	select {
+	case tr := <-got:
+		if tr.text != "Book a table." || !tr.final {
+			t.Errorf("final transcript = %+v", tr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no final transcript after the audio ended")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) < 3 || paths[0] != "speech.config" || paths[1] != "audio" {
+		t.Errorf("messages = %v, want speech.config then audio", paths)
+	}
+	if audioBytes != 44+3200 {
+		t.Errorf("got %d bytes of audio, want a WAV header and the chunk", audioBytes)
+	}
+}
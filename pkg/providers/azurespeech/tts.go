@@ -0,0 +1,229 @@
+package azurespeech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// DefaultVoices maps the package's voices to Azure multilingual neural
+// voices, which speak every language in DefaultLocales.
+var DefaultVoices = map[orchestrator.Voice]string{
+	orchestrator.VoiceF1: "en-US-AvaMultilingualNeural",
+	orchestrator.VoiceF2: "en-US-EmmaMultilingualNeural",
+	orchestrator.VoiceF3: "en-US-JennyMultilingualNeural",
+	orchestrator.VoiceF4: "de-DE-SeraphinaMultilingualNeural",
+	orchestrator.VoiceF5: "fr-FR-VivienneMultilingualNeural",
+	orchestrator.VoiceM1: "en-US-AndrewMultilingualNeural",
+	orchestrator.VoiceM2: "en-US-BrianMultilingualNeural",
+	orchestrator.VoiceM3: "en-US-RyanMultilingualNeural",
+	orchestrator.VoiceM4: "de-DE-FlorianMultilingualNeural",
+	orchestrator.VoiceM5: "fr-FR-RemyMultilingualNeural",
+}
+
+// outputFormats are the raw PCM formats the service offers, by rate.
+var outputFormats = []struct {
+	rate   int
+	format string
+}{
+	{8000, "raw-8khz-16bit-mono-pcm"},
+	{16000, "raw-16khz-16bit-mono-pcm"},
+	{22050, "raw-22050hz-16bit-mono-pcm"},
+	{24000, "raw-24khz-16bit-mono-pcm"},
+	{44100, "raw-44100hz-16bit-mono-pcm"},
+	{48000, "raw-48khz-16bit-mono-pcm"},
+}
+
+// TTS synthesizes speech with Azure neural voices. Each request is an SSML
+// document naming the voice and the locale; text that is already SSML, as
+// written under CodeSwitching.SSML, is spoken with its markup.
+type TTS struct {
+	account
+	sampleRate int
+	locales    map[orchestrator.Language]string
+	voices     map[orchestrator.Voice]string
+
+	mu      sync.Mutex
+	cancels map[*context.CancelFunc]struct{}
+}
+
+// NewTTS returns a provider for the Speech resource with key in region.
+func NewTTS(key, region string) *TTS {
+	return &TTS{
+		account:    account{key: key, region: region},
+		sampleRate: 44100,
+		locales:    DefaultLocales,
+		voices:     DefaultVoices,
+		cancels:    make(map[*context.CancelFunc]struct{}),
+	}
+}
+
+// SetSampleRate sets the rate audio is delivered at; it should match
+// Config.SampleRate.
+func (t *TTS) SetSampleRate(rate int) {
+	t.sampleRate = rate
+}
+
+// SetBaseURL points the provider at another host, such as a Speech
+// container, given its base URL.
+func (t *TTS) SetBaseURL(base string) {
+	t.base = strings.TrimSuffix(base, "/")
+}
+
+// SetHTTPClient sets the client requests are made with.
+func (t *TTS) SetHTTPClient(client *http.Client) {
+	t.client = client
+}
+
+// SetLocales replaces the mapping from languages to the locales text is
+// read in.
+func (t *TTS) SetLocales(locales map[orchestrator.Language]string) {
+	t.locales = locales
+}
+
+// SetVoices replaces the mapping from the package's voices to Azure voice
+// names. Voices missing from it are taken to be voice names themselves, so
+// a session can use any voice, e.g. "es-MX-DaliaNeural", directly.
+func (t *TTS) SetVoices(voices map[orchestrator.Voice]string) {
+	t.voices = voices
+}
+
+func (t *TTS) voiceName(voice orchestrator.Voice) string {
+	if voice == "" {
+		voice = orchestrator.VoiceF1
+	}
+	if name, ok := t.voices[voice]; ok {
+		return name
+	}
+	return string(voice)
+}
+
+// outputFormat picks the pipeline's rate if offered, else the lowest above
+// it, else the highest.
+func (t *TTS) outputFormat() (int, string) {
+	for _, f := range outputFormats {
+		if f.rate >= t.sampleRate {
+			return f.rate, f.format
+		}
+	}
+	last := outputFormats[len(outputFormats)-1]
+	return last.rate, last.format
+}
+
+// ssml wraps text in the document the service takes. Plain text is
+// escaped and, when a multilingual voice is to read it in a locale other
+// than its own, marked with that locale. A <speak> document's content is
+// kept as it is, markup included.
+func (t *TTS) ssml(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) string {
+	name := t.voiceName(voice)
+	loc := locale(t.locales, lang)
+	content := strings.TrimSpace(text)
+	if strings.HasPrefix(content, "<speak") {
+		if i := strings.Index(content, ">"); i >= 0 {
+			content = strings.TrimSuffix(content[i+1:], "</speak>")
+		}
+	} else {
+		content = xmlEscaper.Replace(content)
+		if strings.Contains(name, "Multilingual") && !strings.HasPrefix(name, loc+"-") {
+			content = `<lang xml:lang="` + loc + `">` + content + `</lang>`
+		}
+	}
+	if speed := orchestrator.SpeechRateFromContext(ctx); speed != 1 {
+		content = fmt.Sprintf(`<prosody rate="%+d%%">%s</prosody>`, int(math.Round((speed-1)*100)), content)
+	}
+	return `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="` + loc + `"><voice name="` + xmlEscaper.Replace(name) + `">` + content + `</voice></speak>`
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+func (t *TTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	var out []byte
+	err := t.StreamSynthesize(ctx, text, voice, lang, func(chunk []byte) error {
+		out = append(out, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamSynthesize speaks text in voice, streaming audio as it is
+// generated.
+func (t *TTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	t.mu.Lock()
+	t.cancels[&cancel] = struct{}{}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.cancels, &cancel)
+		t.mu.Unlock()
+		cancel()
+	}()
+
+	rate, format := t.outputFormat()
+	req, err := http.NewRequestWithContext(ctx, "POST", t.url("tts", "/cognitiveservices/v1"), bytes.NewReader([]byte(t.ssml(ctx, text, voice, lang))))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/ssml+xml")
+	req.Header.Set("X-Microsoft-OutputFormat", format)
+	req.Header.Set("User-Agent", "lokutor-orchestrator")
+
+	resp, err := t.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, t.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return orchestrator.NewRateLimitError(t.Name(), resp)
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("azure tts error: %s (status %d)", string(respBody), resp.StatusCode)
+	}
+
+	resampler := audio.NewResampler(rate, t.sampleRate)
+	buf := make([]byte, 4096)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if chunk := resampler.Write(append([]byte(nil), buf[:n]...)); len(chunk) > 0 {
+				if err := onChunk(chunk); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Abort cancels every synthesis in progress.
+func (t *TTS) Abort() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for cancel := range t.cancels {
+		(*cancel)()
+	}
+	return nil
+}
+
+func (t *TTS) Name() string {
+	return "azure-tts"
+}
@@ -0,0 +1,56 @@
+package azurespeech
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestTTS(t *testing.T) {
+	var bodies, formats []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "test-key" || r.URL.Path != "/cognitiveservices/v1" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		formats = append(formats, r.Header.Get("X-Microsoft-OutputFormat"))
+		w.Write(make([]byte, 4800)) // 100ms at 24kHz
+	}))
+	defer server.Close()
+
+	p := New(Config{Key: "test-key", Region: "westeurope", BaseURL: server.URL, SampleRate: 24000})
+	audio, err := p.TTS.Synthesize(orchestrator.WithSpeechRate(context.Background(), 1.2), "Fish & chips", orchestrator.VoiceM1, orchestrator.LanguageEs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audio) != 4800 {
+		t.Errorf("got %d bytes, want 4800", len(audio))
+	}
+	want := `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="es-ES"><voice name="en-US-AndrewMultilingualNeural"><prosody rate="+20%"><lang xml:lang="es-ES">Fish &amp; chips</lang></prosody></voice></speak>`
+	if bodies[0] != want {
+		t.Errorf("SSML = %s\nwant %s", bodies[0], want)
+	}
+	if formats[0] != "raw-24khz-16bit-mono-pcm" {
+		t.Errorf("format = %q", formats[0])
+	}
+
+	// SSML from CodeSwitching keeps its markup; voices outside the map are
+	// Azure voice names.
+	ssml := `<speak>Try <lang xml:lang="fr">Chez Marie</lang></speak>`
+	if _, err := p.TTS.Synthesize(context.Background(), ssml, "en-GB-SoniaNeural", orchestrator.LanguageEn); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bodies[1], `<voice name="en-GB-SoniaNeural">Try <lang xml:lang="fr">Chez Marie</lang></voice>`) {
+		t.Errorf("SSML = %s", bodies[1])
+	}
+	if p.TTS.Name() != "azure-tts" || p.STT.Name() != "azure-stt" {
+		t.Errorf("unexpected names %q, %q", p.TTS.Name(), p.STT.Name())
+	}
+}
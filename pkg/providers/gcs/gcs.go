@@ -0,0 +1,198 @@
+// Package gcs uploads session archives to Google Cloud Storage, as an
+// orchestrator.PurgeableBlobStore.
+//
+//	cfg.Archive = orchestrator.ArchiveConfig{Store: gcs.New("call-archive", nil)}
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// TokenSource returns an OAuth access token with a storage scope.
+type TokenSource func(ctx context.Context) (string, error)
+
+// Store uploads objects to a bucket with the JSON API. Labels become
+// custom metadata on the object. GCS lifecycle rules match on prefixes, not
+// metadata, so retention is best set with ArchiveConfig.Prefix.
+type Store struct {
+	bucket string
+	token  TokenSource
+	base   string
+	client *http.Client
+}
+
+// New returns a store for bucket. A nil token uses the service account of
+// the instance, Cloud Run service or GKE workload the process runs as.
+func New(bucket string, token TokenSource) *Store {
+	if token == nil {
+		token = (&metadataToken{url: metadataTokenURL}).token
+	}
+	return &Store{bucket: bucket, token: token, base: "https://storage.googleapis.com"}
+}
+
+// SetBaseURL sends requests to another endpoint, such as an emulator.
+func (s *Store) SetBaseURL(base string) {
+	s.base = strings.TrimSuffix(base, "/")
+}
+
+// SetHTTPClient sets the client requests are made with.
+func (s *Store) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+func (s *Store) PutBlob(ctx context.Context, key string, data []byte, meta orchestrator.BlobMeta) error {
+	object, err := json.Marshal(map[string]any{
+		"name":        key,
+		"contentType": meta.ContentType,
+		"metadata":    meta.Labels,
+	})
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=UTF-8"}})
+	part.Write(object)
+	contentType := meta.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	part, _ = mw.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+	part.Write(data)
+	mw.Close()
+
+	endpoint := s.base + "/upload/storage/v1/b/" + url.PathEscape(s.bucket) + "/o?uploadType=multipart"
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "multipart/related; boundary="+mw.Boundary())
+	return s.do(req, nil)
+}
+
+// ListBlobs returns the names of the objects under prefix.
+func (s *Store) ListBlobs(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	pageToken := ""
+	for {
+		q := url.Values{"prefix": {prefix}, "fields": {"items(name),nextPageToken"}}
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", s.base+"/storage/v1/b/"+url.PathEscape(s.bucket)+"/o?"+q.Encode(), nil)
+		if err != nil {
+			return names, err
+		}
+		var page struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := s.do(req, &page); err != nil {
+			return names, err
+		}
+		for _, item := range page.Items {
+			names = append(names, item.Name)
+		}
+		if page.NextPageToken == "" {
+			return names, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+func (s *Store) DeleteBlob(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", s.base+"/storage/v1/b/"+url.PathEscape(s.bucket)+"/o/"+url.PathEscape(key), nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, nil)
+}
+
+// do sends req with a token and decodes the response into out, if it is
+// non-nil.
+func (s *Store) do(req *http.Request, out any) error {
+	token, err := s.token(req.Context())
+	if err != nil {
+		return fmt.Errorf("gcs token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	orchestrator.ReportResponse(req.Context(), "gcs", resp)
+	if resp.StatusCode/100 != 2 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("gcs error: %s (status %d)", string(respBody), resp.StatusCode)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+const metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// metadataToken fetches tokens from the metadata server, reusing each until
+// a minute before it expires.
+type metadataToken struct {
+	url string
+
+	mu      sync.Mutex
+	current string
+	expires time.Time
+}
+
+func (m *metadataToken) token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current != "" && time.Until(m.expires) > time.Minute {
+		return m.current, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", m.url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := (&http.Client{Timeout: 5 * time.Second}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("metadata server: %s (status %d)", string(respBody), resp.StatusCode)
+	}
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	m.current = out.AccessToken
+	m.expires = time.Now().Add(time.Duration(out.ExpiresIn) * time.Second)
+	return m.current, nil
+}
@@ -0,0 +1,99 @@
+package gcs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestStore(t *testing.T) {
+	var object map[string]any
+	var data string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				http.Error(w, "missing flavor", http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"access_token":"ya29.test","expires_in":3600}`))
+		case "/upload/storage/v1/b/archive/o":
+			if r.Header.Get("Authorization") != "Bearer ya29.test" || r.URL.Query().Get("uploadType") != "multipart" {
+				http.Error(w, "bad request", http.StatusUnauthorized)
+				return
+			}
+			_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			mr := multipart.NewReader(r.Body, params["boundary"])
+			part, _ := mr.NextPart()
+			json.NewDecoder(part).Decode(&object)
+			part, _ = mr.NextPart()
+			b, _ := io.ReadAll(part)
+			data = string(b)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	s := New("archive", (&metadataToken{url: server.URL + "/token"}).token)
+	s.SetBaseURL(server.URL)
+	err := s.PutBlob(context.Background(), "calls/s1.tar.gz", []byte("bundle"), orchestrator.BlobMeta{ContentType: "application/gzip", Labels: map[string]string{"tenant": "acme"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object["name"] != "calls/s1.tar.gz" || object["contentType"] != "application/gzip" || object["metadata"].(map[string]any)["tenant"] != "acme" {
+		t.Errorf("object = %v", object)
+	}
+	if data != "bundle" {
+		t.Errorf("data = %q", data)
+	}
+}
+
+func TestStore_ListAndDelete(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ya29.test" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case "GET":
+			if r.URL.Path != "/storage/v1/b/archive/o" || r.URL.Query().Get("prefix") != "calls/users/alice/" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			if r.URL.Query().Get("pageToken") == "" {
+				w.Write([]byte(`{"items":[{"name":"calls/users/alice/2024/01/02/s1.tar.gz"}],"nextPageToken":"p2"}`))
+				return
+			}
+			w.Write([]byte(`{"items":[{"name":"calls/users/alice/2024/01/03/s2.tar.gz"}]}`))
+		case "DELETE":
+			deleted = append(deleted, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	s := New("archive", func(ctx context.Context) (string, error) { return "ya29.test", nil })
+	s.SetBaseURL(server.URL)
+	var _ orchestrator.PurgeableBlobStore = s
+	names, err := s.ListBlobs(context.Background(), "calls/users/alice/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[1] != "calls/users/alice/2024/01/03/s2.tar.gz" {
+		t.Errorf("names = %v", names)
+	}
+	if err := s.DeleteBlob(context.Background(), names[0]); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0] != "/storage/v1/b/archive/o/calls%2Fusers%2Falice%2F2024%2F01%2F02%2Fs1.tar.gz" {
+		t.Errorf("deleted %v", deleted)
+	}
+}
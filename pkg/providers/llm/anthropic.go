@@ -3,6 +3,7 @@ package llm
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -30,17 +31,26 @@ func NewAnthropicLLM(apiKey string, model string) *AnthropicLLM {
 func (l *AnthropicLLM) Complete(ctx context.Context, messages []orchestrator.Message, tools []orchestrator.Tool) (string, error) {
 	
 	var system string
-	var anthropicMessages []map[string]string
+	var anthropicMessages []map[string]interface{}
 
 	for _, msg := range messages {
-		if msg.Role == "system" {
-			system = msg.Content
-		} else {
-			anthropicMessages = append(anthropicMessages, map[string]string{
-				"role":    msg.Role,
-				"content": msg.Content,
-			})
+		if msg.Role == orchestrator.RoleSystem || msg.Role == orchestrator.RoleObserver {
+			// Anthropic takes a single system prompt; keep later system
+			// messages (e.g. supervisor whispers) instead of overwriting.
+			if system != "" {
+				system += "\n\n"
+			}
+			system += msg.Text()
+			continue
+		}
+		var content interface{} = msg.Content
+		if len(msg.Parts) > 0 {
+			content = anthropicBlocks(msg)
 		}
+		anthropicMessages = append(anthropicMessages, map[string]interface{}{
+			"role":    msg.Role,
+			"content": content,
+		})
 	}
 
 	payload := map[string]interface{}{
@@ -51,6 +61,10 @@ func (l *AnthropicLLM) Complete(ctx context.Context, messages []orchestrator.Mes
 	if system != "" {
 		payload["system"] = system
 	}
+	if _, ok := orchestrator.SeedFromContext(ctx); ok {
+		payload["temperature"] = 0 // The API takes no seed; this is as close as it gets
+	}
+	applyMaxTokens(ctx, payload, "max_tokens")
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -65,12 +79,18 @@ func (l *AnthropicLLM) Complete(ctx context.Context, messages []orchestrator.Mes
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-api-key", l.apiKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
+	orchestrator.SetIdempotencyHeader(req)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, l.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", orchestrator.NewRateLimitError(l.Name(), resp)
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp interface{}
@@ -82,10 +102,17 @@ func (l *AnthropicLLM) Complete(ctx context.Context, messages []orchestrator.Mes
 		Content []struct {
 			Text string `json:"text"`
 		} `json:"content"`
+		Usage *struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return "", err
 	}
+	if u := result.Usage; u != nil {
+		orchestrator.ReportUsage(ctx, orchestrator.TokenUsage{PromptTokens: u.InputTokens, CompletionTokens: u.OutputTokens})
+	}
 
 	if len(result.Content) == 0 {
 		return "", fmt.Errorf("no content returned from anthropic")
@@ -94,6 +121,41 @@ func (l *AnthropicLLM) Complete(ctx context.Context, messages []orchestrator.Mes
 	return result.Content[0].Text, nil
 }
 
+func anthropicBlocks(m orchestrator.Message) []map[string]interface{} {
+	var blocks []map[string]interface{}
+	if m.Content != "" {
+		blocks = append(blocks, map[string]interface{}{"type": "text", "text": m.Content})
+	}
+	for _, p := range m.Parts {
+		switch {
+		case p.Type == orchestrator.PartImage && len(p.Data) > 0:
+			blocks = append(blocks, map[string]interface{}{
+				"type": "image",
+				"source": map[string]string{
+					"type":       "base64",
+					"media_type": p.MIMEType,
+					"data":       base64.StdEncoding.EncodeToString(p.Data),
+				},
+			})
+		case p.Type == orchestrator.PartImage:
+			blocks = append(blocks, map[string]interface{}{
+				"type":   "image",
+				"source": map[string]string{"type": "url", "url": p.URL},
+			})
+		case p.Type == orchestrator.PartText:
+			blocks = append(blocks, map[string]interface{}{"type": "text", "text": p.Text})
+		default:
+			blocks = append(blocks, map[string]interface{}{"type": "text", "text": mediaPlaceholder(p)})
+		}
+	}
+	return blocks
+}
+
 func (l *AnthropicLLM) Name() string {
 	return "anthropic-llm"
 }
+
+// Model returns the model requests are made with.
+func (l *AnthropicLLM) Model() string {
+	return l.model
+}
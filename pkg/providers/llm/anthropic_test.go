@@ -67,3 +67,60 @@ func TestAnthropicLLM(t *testing.T) {
 		t.Errorf("expected 'hello from anthropic', got '%s'", resp)
 	}
 }
+
+func TestAnthropicLLM_MultipleSystemMessages(t *testing.T) {
+	var system string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			System string `json:"system"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		system = req.System
+		w.Write([]byte(`{"content":[{"text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	l := &AnthropicLLM{apiKey: "test-key", url: server.URL, model: "claude-3"}
+
+	messages := []orchestrator.Message{
+		{Role: "system", Content: "persona"},
+		{Role: "user", Content: "hi"},
+		{Role: "system", Name: orchestrator.SupervisorName, Content: "offer a discount"},
+	}
+	if _, err := l.Complete(context.Background(), messages, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if system != "persona\n\noffer a discount" {
+		t.Errorf("expected both system messages to be kept, got %q", system)
+	}
+}
+
+func TestAnthropicLLM_ImageBlocks(t *testing.T) {
+	var req struct {
+		Messages []struct {
+			Content []map[string]interface{} `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(`{"content":[{"text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	l := &AnthropicLLM{apiKey: "test-key", url: server.URL, model: "claude-3"}
+	messages := []orchestrator.Message{{
+		Role:    orchestrator.RoleUser,
+		Content: "describe",
+		Parts:   []orchestrator.ContentPart{{Type: orchestrator.PartImage, MIMEType: "image/png", Data: []byte{0x89}}},
+	}}
+	if _, err := l.Complete(context.Background(), messages, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	blocks := req.Messages[0].Content
+	if len(blocks) != 2 || blocks[1]["type"] != "image" {
+		t.Fatalf("expected text and image blocks, got %v", blocks)
+	}
+	if src := blocks[1]["source"].(map[string]interface{}); src["type"] != "base64" || src["media_type"] != "image/png" {
+		t.Errorf("unexpected image source: %v", src)
+	}
+}
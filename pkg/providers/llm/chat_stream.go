@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// chatEndpoint is an OpenAI-compatible chat completions API.
+type chatEndpoint struct {
+	url    string
+	apiKey string
+	name   string // Provider name, for rate-limit errors
+	vendor string // Named in other errors
+	client *http.Client
+}
+
+func (e chatEndpoint) httpClient() *http.Client {
+	if e.client != nil {
+		return e.client
+	}
+	return http.DefaultClient
+}
+
+// stream sends payload with streaming on, passing content to onChunk as it
+// arrives and tool calls to onToolCall once the response is complete.
+func (e chatEndpoint) stream(ctx context.Context, payload map[string]interface{}, onChunk func(string) error, onToolCall func(orchestrator.ToolCallEventData) error) (string, error) {
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	orchestrator.SetIdempotencyHeader(req)
+
+	resp, err := e.httpClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, e.name, resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", orchestrator.NewRateLimitError(e.name, resp)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp interface{}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return "", fmt.Errorf("%s api error: %v", e.vendor, errResp)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var fullContent strings.Builder
+
+	type toolCallState struct {
+		id        string
+		name      string
+		arguments strings.Builder
+	}
+	toolCalls := make(map[int]*toolCallState)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		data := strings.TrimPrefix(line, "data: ")
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content   string `json:"content"`
+					ToolCalls []struct {
+						Index    int    `json:"index"`
+						ID       string `json:"id"`
+						Function struct {
+							Name      string `json:"name"`
+							Arguments string `json:"arguments"`
+						} `json:"function"`
+					} `json:"tool_calls"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *openAIUsage `json:"usage"` // In the last chunk, when stream_options asks for it
+		}
+
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+
+		chunk.Usage.report(ctx)
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta
+		if delta.Content != "" {
+			fullContent.WriteString(delta.Content)
+			if onChunk != nil {
+				if err := onChunk(delta.Content); err != nil {
+					return "", err
+				}
+			}
+		}
+
+		for _, tc := range delta.ToolCalls {
+			state, ok := toolCalls[tc.Index]
+			if !ok {
+				state = &toolCallState{}
+				toolCalls[tc.Index] = state
+			}
+			if tc.ID != "" {
+				state.id = tc.ID
+			}
+			if tc.Function.Name != "" {
+				state.name = tc.Function.Name
+			}
+			if tc.Function.Arguments != "" {
+				state.arguments.WriteString(tc.Function.Arguments)
+			}
+		}
+	}
+
+	// Emit tool calls if any - iterating safely over max observed index
+	maxIdx := -1
+	for idx := range toolCalls {
+		if idx > maxIdx {
+			maxIdx = idx
+		}
+	}
+
+	for i := 0; i <= maxIdx; i++ {
+		state, ok := toolCalls[i]
+		if ok && state != nil && onToolCall != nil {
+			err := onToolCall(orchestrator.ToolCallEventData{
+				Name:      state.name,
+				Arguments: state.arguments.String(),
+				CallID:    state.id,
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return fullContent.String(), nil
+}
@@ -0,0 +1,49 @@
+package llm
+
+import "strings"
+
+// contextWindows lists the context window of known models by name prefix,
+// most specific first.
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-4.1", 1047576},
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"claude-", 200000},
+	{"gemini-1.5-pro", 2097152},
+	{"gemini-", 1048576},
+	{"meta-llama/llama-4", 131072},
+	{"llama-3", 131072},
+	{"mixtral-8x7b", 32768},
+	{"gemma2", 8192},
+}
+
+// contextWindow returns the window of model, or fallback for models not
+// listed.
+func contextWindow(model string, fallback int) int {
+	for _, w := range contextWindows {
+		if strings.HasPrefix(model, w.prefix) {
+			return w.tokens
+		}
+	}
+	return fallback
+}
+
+// ContextWindow implements orchestrator.ContextWindowProvider.
+func (l *OpenAILLM) ContextWindow() int { return contextWindow(l.model, 128000) }
+
+// ContextWindow implements orchestrator.ContextWindowProvider.
+func (l *GroqLLM) ContextWindow() int { return contextWindow(l.model, 131072) }
+
+// ContextWindow implements orchestrator.ContextWindowProvider.
+func (l *AnthropicLLM) ContextWindow() int { return contextWindow(l.model, 200000) }
+
+// ContextWindow implements orchestrator.ContextWindowProvider.
+func (l *GoogleLLM) ContextWindow() int { return contextWindow(l.model, 1048576) }
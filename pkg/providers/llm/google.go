@@ -3,6 +3,7 @@ package llm
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -29,31 +30,54 @@ func NewGoogleLLM(apiKey string, model string) *GoogleLLM {
 
 func (l *GoogleLLM) Complete(ctx context.Context, messages []orchestrator.Message, tools []orchestrator.Tool) (string, error) {
 	type GoogleMessage struct {
-		Role  string `json:"role"`
-		Parts []struct {
-			Text string `json:"text"`
-		} `json:"parts"`
+		Role  string       `json:"role"`
+		Parts []googlePart `json:"parts"`
 	}
 
 	var googleMessages []GoogleMessage
+	var system []googlePart
 	for _, m := range messages {
 		role := m.Role
-		if role == "system" {
-			role = "user" 
-		}
-		if role == "assistant" {
+		switch role {
+		case orchestrator.RoleSystem:
+			// Gemini takes instructions apart from the turns.
+			system = append(system, googlePart{Text: m.Text()})
+			continue
+		case orchestrator.RoleAssistant:
 			role = "model"
+		case orchestrator.RoleUser:
+		default:
+			role = "user"
 		}
 		msg := GoogleMessage{Role: role}
-		msg.Parts = append(msg.Parts, struct {
-			Text string `json:"text"`
-		}{Text: m.Content})
+		if m.Content != "" || len(m.Parts) == 0 {
+			msg.Parts = append(msg.Parts, googlePart{Text: m.Content})
+		}
+		for _, p := range m.Parts {
+			msg.Parts = append(msg.Parts, toGooglePart(p))
+		}
 		googleMessages = append(googleMessages, msg)
 	}
 
 	payload := map[string]interface{}{
 		"contents": googleMessages,
 	}
+	if len(system) > 0 {
+		if len(googleMessages) == 0 {
+			// Gemini wants at least one turn.
+			payload["contents"] = []GoogleMessage{{Role: "user", Parts: system}}
+		} else {
+			payload["system_instruction"] = map[string]interface{}{"parts": system}
+		}
+	}
+	generation := map[string]interface{}{}
+	if seed, ok := orchestrator.SeedFromContext(ctx); ok {
+		generation["seed"], generation["temperature"] = seed, 0
+	}
+	applyMaxTokens(ctx, generation, "maxOutputTokens")
+	if len(generation) > 0 {
+		payload["generationConfig"] = generation
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -71,6 +95,11 @@ func (l *GoogleLLM) Complete(ctx context.Context, messages []orchestrator.Messag
 		return "", err
 	}
 	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, l.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", orchestrator.NewRateLimitError(l.Name(), resp)
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp interface{}
@@ -86,10 +115,17 @@ func (l *GoogleLLM) Complete(ctx context.Context, messages []orchestrator.Messag
 				} `json:"parts"`
 			} `json:"content"`
 		} `json:"candidates"`
+		UsageMetadata *struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return "", err
 	}
+	if u := result.UsageMetadata; u != nil {
+		orchestrator.ReportUsage(ctx, orchestrator.TokenUsage{PromptTokens: u.PromptTokenCount, CompletionTokens: u.CandidatesTokenCount})
+	}
 
 	if len(result.Candidates) == 0 || len(result.Candidates[0].Content.Parts) == 0 {
 		return "", fmt.Errorf("no response from google llm")
@@ -98,6 +134,42 @@ func (l *GoogleLLM) Complete(ctx context.Context, messages []orchestrator.Messag
 	return result.Candidates[0].Content.Parts[0].Text, nil
 }
 
+type googlePart struct {
+	Text       string          `json:"text,omitempty"`
+	InlineData *googleBlob     `json:"inline_data,omitempty"`
+	FileData   *googleFileData `json:"file_data,omitempty"`
+}
+
+type googleBlob struct {
+	MIMEType string `json:"mime_type"`
+	Data     string `json:"data"`
+}
+
+type googleFileData struct {
+	MIMEType string `json:"mime_type,omitempty"`
+	FileURI  string `json:"file_uri"`
+}
+
+func toGooglePart(p orchestrator.ContentPart) googlePart {
+	switch {
+	case p.Type == orchestrator.PartText:
+		return googlePart{Text: p.Text}
+	case len(p.Data) > 0:
+		mime := p.MIMEType
+		if mime == "" {
+			mime = http.DetectContentType(p.Data)
+		}
+		return googlePart{InlineData: &googleBlob{MIMEType: mime, Data: base64.StdEncoding.EncodeToString(p.Data)}}
+	default:
+		return googlePart{FileData: &googleFileData{MIMEType: p.MIMEType, FileURI: p.URL}}
+	}
+}
+
 func (l *GoogleLLM) Name() string {
 	return "google-llm"
 }
+
+// Model returns the model requests are made with.
+func (l *GoogleLLM) Model() string {
+	return l.model
+}
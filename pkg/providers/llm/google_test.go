@@ -72,3 +72,71 @@ func TestGoogleLLM(t *testing.T) {
 		t.Errorf("expected 'hello from google', got '%s'", resp)
 	}
 }
+
+func TestGoogleLLM_InlineParts(t *testing.T) {
+	var req struct {
+		Contents []struct {
+			Role  string                   `json:"role"`
+			Parts []map[string]interface{} `json:"parts"`
+		} `json:"contents"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}`))
+	}))
+	defer server.Close()
+
+	l := &GoogleLLM{apiKey: "test-key", url: server.URL, model: "gemini"}
+	messages := []orchestrator.Message{{
+		Role:  orchestrator.RoleUser,
+		Parts: []orchestrator.ContentPart{orchestrator.AudioRef("gs://bucket/clip.wav", "audio/wav")},
+	}}
+	if _, err := l.Complete(context.Background(), messages, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parts := req.Contents[0].Parts
+	if len(parts) != 1 || parts[0]["file_data"] == nil {
+		t.Errorf("expected a single file_data part, got %v", parts)
+	}
+}
+
+func TestGoogleLLM_SystemInstructionAndAttachments(t *testing.T) {
+	var req struct {
+		SystemInstruction struct {
+			Parts []map[string]interface{} `json:"parts"`
+		} `json:"system_instruction"`
+		Contents []struct {
+			Role  string                   `json:"role"`
+			Parts []map[string]interface{} `json:"parts"`
+		} `json:"contents"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"A red mug."}]}}]}`))
+	}))
+	defer server.Close()
+
+	session := orchestrator.NewConversationSession("u")
+	session.AddMessage(orchestrator.RoleSystem, "You can see through the user's camera.")
+	// A PNG frame, attached before the question is transcribed.
+	session.Attach(orchestrator.ImageData([]byte("\x89PNG\r\n\x1a\nframe"), ""))
+	session.AddMessage(orchestrator.RoleUser, "What am I holding?")
+
+	l := &GoogleLLM{apiKey: "test-key", url: server.URL, model: "gemini"}
+	if _, err := l.Complete(context.Background(), session.GetContextCopy(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.SystemInstruction.Parts) != 1 || req.SystemInstruction.Parts[0]["text"] != "You can see through the user's camera." {
+		t.Errorf("system_instruction = %v", req.SystemInstruction)
+	}
+	if len(req.Contents) != 1 || req.Contents[0].Role != "user" {
+		t.Fatalf("contents = %v", req.Contents)
+	}
+	parts := req.Contents[0].Parts
+	if len(parts) != 2 || parts[0]["text"] != "What am I holding?" {
+		t.Fatalf("parts = %v", parts)
+	}
+	if blob, _ := parts[1]["inline_data"].(map[string]interface{}); blob["mime_type"] != "image/png" {
+		t.Errorf("image part = %v", parts[1])
+	}
+}
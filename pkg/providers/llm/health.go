@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// The LLM providers implement orchestrator.HealthChecker by looking up
+// their model, which checks the key and the model without generating
+// anything.
+
+func (l *OpenAILLM) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(l.url, "/chat/completions")+"/models/"+l.model, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+l.apiKey)
+	return orchestrator.PingHTTP(l.endpoint().httpClient(), l.Name(), req)
+}
+
+func (l *GroqLLM) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(l.url, "/chat/completions")+"/models/"+l.model, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+l.apiKey)
+	return orchestrator.PingHTTP(nil, l.Name(), req)
+}
+
+func (l *AnthropicLLM) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(l.url, "/messages")+"/models/"+l.model, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-api-key", l.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	return orchestrator.PingHTTP(nil, l.Name(), req)
+}
+
+func (l *GoogleLLM) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(l.url, ":generateContent")+"?key="+url.QueryEscape(l.apiKey), nil)
+	if err != nil {
+		return err
+	}
+	return orchestrator.PingHTTP(nil, l.Name(), req)
+}
@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestOpenAILLM_Ping(t *testing.T) {
+	var limited bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case limited:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.Method != "GET" || r.URL.Path != "/v1/models/gpt-4o":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Header.Get("Authorization") != "Bearer test-key":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.Write([]byte(`{"id": "gpt-4o"}`))
+		}
+	}))
+	defer server.Close()
+
+	l := NewOpenAILLM("test-key", "gpt-4o")
+	l.SetBaseURL(server.URL + "/v1")
+	if err := l.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	bad := NewOpenAILLM("wrong-key", "gpt-4o")
+	bad.SetBaseURL(server.URL + "/v1")
+	if err := bad.Ping(context.Background()); err == nil {
+		t.Error("Ping with a bad key should fail")
+	}
+
+	limited = true
+	var rl *orchestrator.RateLimitError
+	if err := l.Ping(context.Background()); !errors.As(err, &rl) {
+		t.Errorf("expected RateLimitError, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// openAIMessage is the chat-completions wire form shared by OpenAI and Groq.
+// Content is a string, or a list of typed parts for multimodal messages.
+type openAIMessage struct {
+	Role       string      `json:"role"`
+	Content    interface{} `json:"content"`
+	Name       string      `json:"name,omitempty"`
+	ToolCallID string      `json:"tool_call_id,omitempty"`
+	ToolCalls  interface{} `json:"tool_calls,omitempty"`
+}
+
+func toOpenAIMessages(messages []orchestrator.Message) []openAIMessage {
+	out := make([]openAIMessage, 0, len(messages))
+	for _, m := range messages {
+		msg := openAIMessage{
+			Role:       m.Role,
+			Content:    m.Content,
+			Name:       m.Name,
+			ToolCallID: m.ToolCallID,
+			ToolCalls:  m.ToolCalls,
+		}
+		if m.Role == orchestrator.RoleObserver {
+			msg.Role = orchestrator.RoleSystem
+			if msg.Name == "" {
+				msg.Name = orchestrator.RoleObserver
+			}
+		}
+		if len(m.Parts) > 0 {
+			msg.Content = openAIParts(m)
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+func openAIParts(m orchestrator.Message) []map[string]interface{} {
+	var parts []map[string]interface{}
+	if m.Content != "" {
+		parts = append(parts, map[string]interface{}{"type": "text", "text": m.Content})
+	}
+	for _, p := range m.Parts {
+		switch p.Type {
+		case orchestrator.PartImage:
+			parts = append(parts, map[string]interface{}{
+				"type":      "image_url",
+				"image_url": map[string]string{"url": mediaURL(p)},
+			})
+		case orchestrator.PartAudio:
+			if len(p.Data) == 0 {
+				// input_audio only takes inline data.
+				parts = append(parts, map[string]interface{}{"type": "text", "text": mediaPlaceholder(p)})
+				continue
+			}
+			parts = append(parts, map[string]interface{}{
+				"type": "input_audio",
+				"input_audio": map[string]string{
+					"data":   base64.StdEncoding.EncodeToString(p.Data),
+					"format": audioFormat(p.MIMEType),
+				},
+			})
+		default:
+			parts = append(parts, map[string]interface{}{"type": "text", "text": p.Text})
+		}
+	}
+	return parts
+}
+
+// mediaURL returns a part's URL, or a data: URL for inline media.
+func mediaURL(p orchestrator.ContentPart) string {
+	if p.URL != "" || len(p.Data) == 0 {
+		return p.URL
+	}
+	mime := p.MIMEType
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(p.Data)
+}
+
+// mediaPlaceholder describes media a provider cannot accept, so the model
+// at least knows it was there.
+func mediaPlaceholder(p orchestrator.ContentPart) string {
+	if p.URL != "" {
+		return "[" + string(p.Type) + ": " + p.URL + "]"
+	}
+	return "[" + string(p.Type) + " attachment]"
+}
+
+func audioFormat(mimeType string) string {
+	switch {
+	case strings.Contains(mimeType, "mpeg"), strings.Contains(mimeType, "mp3"):
+		return "mp3"
+	default:
+		return "wav"
+	}
+}
+
+// applySeed asks an OpenAI-compatible API for reproducible output when the
+// orchestrator runs deterministically.
+func applySeed(ctx context.Context, payload map[string]interface{}) {
+	if seed, ok := orchestrator.SeedFromContext(ctx); ok {
+		payload["seed"] = seed
+		payload["temperature"] = 0
+	}
+}
+
+// openAIUsage is the usage block of an OpenAI-compatible completion.
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+func (u *openAIUsage) report(ctx context.Context) {
+	if u == nil {
+		return
+	}
+	orchestrator.ReportUsage(ctx, orchestrator.TokenUsage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens})
+}
+
+// applyMaxTokens caps the reply at the orchestrator's token limit, under
+// key, which differs between APIs.
+func applyMaxTokens(ctx context.Context, payload map[string]interface{}, key string) {
+	if n := orchestrator.MaxTokensFromContext(ctx); n > 0 {
+		payload[key] = n
+	}
+}
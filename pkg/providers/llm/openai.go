@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
 )
@@ -14,6 +15,7 @@ type OpenAILLM struct {
 	apiKey string
 	url    string
 	model  string
+	client *http.Client
 }
 
 func NewOpenAILLM(apiKey string, model string) *OpenAILLM {
@@ -27,11 +29,33 @@ func NewOpenAILLM(apiKey string, model string) *OpenAILLM {
 	}
 }
 
+// SetBaseURL points the provider at another OpenAI-compatible API, such as
+// a proxy or Azure gateway, given its base URL ending in /v1.
+func (l *OpenAILLM) SetBaseURL(base string) {
+	l.url = strings.TrimSuffix(base, "/") + "/chat/completions"
+}
+
+// SetHTTPClient sets the client requests are made with, e.g. one whose
+// transport retries transient failures.
+func (l *OpenAILLM) SetHTTPClient(client *http.Client) {
+	l.client = client
+}
+
+func (l *OpenAILLM) endpoint() chatEndpoint {
+	return chatEndpoint{url: l.url, apiKey: l.apiKey, name: l.Name(), vendor: "openai", client: l.client}
+}
+
 func (l *OpenAILLM) Complete(ctx context.Context, messages []orchestrator.Message, tools []orchestrator.Tool) (string, error) {
 	payload := map[string]interface{}{
 		"model":    l.model,
-		"messages": messages,
+		"messages": toOpenAIMessages(messages),
 	}
+	if len(tools) > 0 {
+		payload["tools"] = tools
+		payload["tool_choice"] = "auto"
+	}
+	applySeed(ctx, payload)
+	applyMaxTokens(ctx, payload, "max_completion_tokens")
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -45,12 +69,18 @@ func (l *OpenAILLM) Complete(ctx context.Context, messages []orchestrator.Messag
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+l.apiKey)
+	orchestrator.SetIdempotencyHeader(req)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := l.endpoint().httpClient().Do(req)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, l.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", orchestrator.NewRateLimitError(l.Name(), resp)
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp interface{}
@@ -64,10 +94,12 @@ func (l *OpenAILLM) Complete(ctx context.Context, messages []orchestrator.Messag
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage *openAIUsage `json:"usage"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return "", err
 	}
+	result.Usage.report(ctx)
 
 	if len(result.Choices) == 0 {
 		return "", fmt.Errorf("no choices returned from openai")
@@ -76,6 +108,27 @@ func (l *OpenAILLM) Complete(ctx context.Context, messages []orchestrator.Messag
 	return result.Choices[0].Message.Content, nil
 }
 
+func (l *OpenAILLM) StreamComplete(ctx context.Context, messages []orchestrator.Message, tools []orchestrator.Tool, onChunk func(string) error, onToolCall func(orchestrator.ToolCallEventData) error) (string, error) {
+	payload := map[string]interface{}{
+		"model":          l.model,
+		"messages":       toOpenAIMessages(messages),
+		"stream":         true,
+		"stream_options": map[string]bool{"include_usage": true},
+	}
+	if len(tools) > 0 {
+		payload["tools"] = tools
+		payload["tool_choice"] = "auto"
+	}
+	applySeed(ctx, payload)
+	applyMaxTokens(ctx, payload, "max_completion_tokens")
+	return l.endpoint().stream(ctx, payload, onChunk, onToolCall)
+}
+
 func (l *OpenAILLM) Name() string {
 	return "openai-llm"
 }
+
+// Model returns the model requests are made with.
+func (l *OpenAILLM) Model() string {
+	return l.model
+}
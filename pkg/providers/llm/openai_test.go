@@ -3,9 +3,11 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
 )
@@ -72,3 +74,188 @@ func TestOpenAILLM(t *testing.T) {
 		t.Errorf("expected openai-llm, got %s", l.Name())
 	}
 }
+
+func TestOpenAILLM_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	l := &OpenAILLM{apiKey: "test-key", url: server.URL, model: "gpt-4o"}
+
+	_, err := l.Complete(context.Background(), []orchestrator.Message{{Role: "user", Content: "hi"}}, nil)
+
+	var rl *orchestrator.RateLimitError
+	if !errors.As(err, &rl) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if rl.RetryAfter != 3*time.Second {
+		t.Errorf("expected Retry-After of 3s, got %v", rl.RetryAfter)
+	}
+	if rl.Provider != "openai-llm" {
+		t.Errorf("expected provider openai-llm, got %s", rl.Provider)
+	}
+}
+
+func TestOpenAILLM_IdempotencyKey(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(orchestrator.IdempotencyKeyHeader)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	l := &OpenAILLM{apiKey: "test-key", url: server.URL, model: "gpt-4o"}
+
+	ctx := orchestrator.WithIdempotencyKey(context.Background(), "turn-123")
+	if _, err := l.Complete(ctx, []orchestrator.Message{{Role: "user", Content: "hi"}}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "turn-123" {
+		t.Errorf("expected Idempotency-Key turn-123, got %q", got)
+	}
+}
+
+func TestOpenAILLM_ReportsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}],"usage":{"prompt_tokens":42,"completion_tokens":7}}`))
+	}))
+	defer server.Close()
+
+	l := &OpenAILLM{apiKey: "test-key", url: server.URL, model: "gpt-4o"}
+	o := orchestrator.New(nil, l, nil, nil, orchestrator.DefaultConfig(), nil)
+	session := orchestrator.NewConversationSession("u")
+	session.AddMessage(orchestrator.RoleUser, "hi")
+
+	if _, err := o.GenerateResponse(context.Background(), session); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := orchestrator.TokenUsage{PromptTokens: 42, CompletionTokens: 7}
+	if got := session.Usage(); got != want {
+		t.Errorf("expected reported usage %+v, got %+v", want, got)
+	}
+}
+
+func TestOpenAILLM_MultimodalAndRoles(t *testing.T) {
+	var req struct {
+		Messages []map[string]interface{} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(`{"choices":[{"message":{"content":"a cat"}}]}`))
+	}))
+	defer server.Close()
+
+	l := &OpenAILLM{apiKey: "test-key", url: server.URL, model: "gpt-4o"}
+	messages := []orchestrator.Message{
+		{Role: orchestrator.RoleObserver, Content: "caller sounds upset"},
+		{Role: orchestrator.RoleUser, Content: "what is this?", Parts: []orchestrator.ContentPart{
+			orchestrator.ImageRef("https://example.com/cat.jpg"),
+			{Type: orchestrator.PartAudio, MIMEType: "audio/wav", Data: []byte("RIFF")},
+		}},
+	}
+	if _, err := l.Complete(context.Background(), messages, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if req.Messages[0]["role"] != "system" || req.Messages[0]["name"] != "observer" {
+		t.Errorf("observer should become a named system message, got %v", req.Messages[0])
+	}
+	parts, ok := req.Messages[1]["content"].([]interface{})
+	if !ok || len(parts) != 3 {
+		t.Fatalf("expected three content parts, got %v", req.Messages[1]["content"])
+	}
+	image := parts[1].(map[string]interface{})
+	if image["type"] != "image_url" || image["image_url"].(map[string]interface{})["url"] != "https://example.com/cat.jpg" {
+		t.Errorf("unexpected image part: %v", image)
+	}
+	audio := parts[2].(map[string]interface{})
+	if audio["type"] != "input_audio" || audio["input_audio"].(map[string]interface{})["format"] != "wav" {
+		t.Errorf("unexpected audio part: %v", audio)
+	}
+}
+
+func TestOpenAILLM_SendsSeed(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices": [{"message": {"content": "ok"}}]}`))
+	}))
+	defer server.Close()
+	l := &OpenAILLM{apiKey: "k", url: server.URL, model: "gpt-4o"}
+	messages := []orchestrator.Message{{Role: "user", Content: "hi"}}
+
+	if _, err := l.Complete(context.Background(), messages, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["seed"]; ok {
+		t.Errorf("unexpected seed without determinism: %v", got)
+	}
+
+	if _, err := l.Complete(orchestrator.WithSeed(context.Background(), 42), messages, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got["seed"] != float64(42) || got["temperature"] != float64(0) {
+		t.Errorf("expected seed 42 at temperature 0, got %v", got)
+	}
+}
+
+func TestOpenAILLM_SendsMaxTokens(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices": [{"message": {"content": "ok"}}]}`))
+	}))
+	defer server.Close()
+	l := &OpenAILLM{apiKey: "k", url: server.URL, model: "gpt-4o"}
+	messages := []orchestrator.Message{{Role: "user", Content: "hi"}}
+
+	if _, err := l.Complete(context.Background(), messages, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["max_completion_tokens"]; ok {
+		t.Errorf("unexpected token limit: %v", got)
+	}
+	if _, err := l.Complete(orchestrator.WithMaxTokens(context.Background(), 60), messages, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got["max_completion_tokens"] != float64(60) {
+		t.Errorf("expected a limit of 60 tokens, got %v", got)
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	var _ orchestrator.ContextWindowProvider = (*GroqLLM)(nil)
+	cases := map[orchestrator.LLMProvider]int{
+		NewOpenAILLM("k", "gpt-4o-mini"):           128000,
+		NewOpenAILLM("k", "gpt-4"):                 8192,
+		NewOpenAILLM("k", "my-finetune"):           128000,
+		NewGroqLLM("k", ""):                        131072,
+		NewAnthropicLLM("k", ""):                   200000,
+		NewGoogleLLM("k", "gemini-1.5-pro-latest"): 2097152,
+	}
+	for p, want := range cases {
+		if got := p.(orchestrator.ContextWindowProvider).ContextWindow(); got != want {
+			t.Errorf("%s: got %d, want %d", p.Name(), got, want)
+		}
+	}
+}
+
+func TestOpenAILLM_ReportsRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req_abc")
+		w.Write([]byte(`{"choices":[{"message":{"content":"hi"}}]}`))
+	}))
+	defer server.Close()
+
+	l := &OpenAILLM{apiKey: "test-key", url: server.URL, model: "gpt-4o"}
+	ctx, trail := orchestrator.WithAuditTrail(context.Background())
+	if _, err := l.Complete(ctx, []orchestrator.Message{{Role: "user", Content: "hi"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	calls := trail.Calls()
+	if len(calls) != 1 || calls[0].RequestID != "req_abc" || calls[0].Provider != "openai-llm" || calls[0].Status != http.StatusOK {
+		t.Errorf("unexpected calls: %+v", calls)
+	}
+}
@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"cmp"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// The package's providers register with orchestrator.DefaultRegistry, for
+// pipelines chosen by name with orchestrator.BuildFromConfig.
+func init() {
+	orchestrator.RegisterLLM("groq", func(o orchestrator.ProviderOptions) (orchestrator.LLMProvider, error) {
+		if err := o.RequireAPIKey("groq"); err != nil {
+			return nil, err
+		}
+		l := NewGroqLLM(o.APIKey, cmp.Or(o.Model, "meta-llama/llama-4-scout-17b-16e-instruct"))
+		orchestrator.ApplyProviderOptions(l, o)
+		return l, nil
+	})
+	orchestrator.RegisterLLM("openai", func(o orchestrator.ProviderOptions) (orchestrator.LLMProvider, error) {
+		if err := o.RequireAPIKey("openai"); err != nil {
+			return nil, err
+		}
+		l := NewOpenAILLM(o.APIKey, cmp.Or(o.Model, "gpt-4o"))
+		orchestrator.ApplyProviderOptions(l, o)
+		return l, nil
+	})
+	orchestrator.RegisterLLM("anthropic", func(o orchestrator.ProviderOptions) (orchestrator.LLMProvider, error) {
+		if err := o.RequireAPIKey("anthropic"); err != nil {
+			return nil, err
+		}
+		l := NewAnthropicLLM(o.APIKey, cmp.Or(o.Model, "claude-3-5-sonnet-20241022"))
+		orchestrator.ApplyProviderOptions(l, o)
+		return l, nil
+	})
+	orchestrator.RegisterLLM("google", func(o orchestrator.ProviderOptions) (orchestrator.LLMProvider, error) {
+		if err := o.RequireAPIKey("google"); err != nil {
+			return nil, err
+		}
+		l := NewGoogleLLM(o.APIKey, cmp.Or(o.Model, "gemini-1.5-flash"))
+		orchestrator.ApplyProviderOptions(l, o)
+		return l, nil
+	})
+}
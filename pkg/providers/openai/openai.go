@@ -0,0 +1,82 @@
+// Package openai assembles a complete voice pipeline on the OpenAI API:
+// Whisper (or gpt-4o-transcribe) for STT, a GPT model for the LLM, and the
+// speech API for TTS, sharing one API key and one retrying HTTP client.
+//
+//	p := openai.New(openai.Config{APIKey: os.Getenv("OPENAI_API_KEY")})
+//	orch := orchestrator.NewWithVAD(p.STT, p.LLM, p.TTS, vad, cfg)
+package openai
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/llm"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/stt"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/tts"
+)
+
+// Config configures the providers. Only APIKey is required.
+type Config struct {
+	APIKey       string
+	BaseURL      string        // Defaults to https://api.openai.com/v1
+	Organization string        // Sent as OpenAI-Organization when set
+	STTModel     string        // Defaults to whisper-1
+	LLMModel     string        // Defaults to gpt-4o
+	TTSModel     string        // Defaults to gpt-4o-mini-tts
+	SampleRate   int           // Of the pipeline's audio, as Config.SampleRate; defaults to 44100
+	MaxRetries   int           // Retries of server errors and dropped connections; 0 defaults to 2, negative disables
+	RetryDelay   time.Duration // Before the first retry, doubling after; defaults to 250ms
+	HTTPClient   *http.Client  // Base client; its transport is wrapped for retries
+}
+
+// Providers is a matching set of OpenAI providers. LLM streams tokens and TTS
+// streams audio.
+type Providers struct {
+	STT *stt.OpenAISTT
+	LLM *llm.OpenAILLM
+	TTS *tts.OpenAITTS
+}
+
+// New returns the providers for cfg.
+func New(cfg Config) *Providers {
+	client := newClient(cfg)
+	p := &Providers{
+		STT: stt.NewOpenAISTT(cfg.APIKey, cfg.STTModel),
+		LLM: llm.NewOpenAILLM(cfg.APIKey, cfg.LLMModel),
+		TTS: tts.NewOpenAITTS(cfg.APIKey, cfg.TTSModel),
+	}
+	p.STT.SetHTTPClient(client)
+	p.LLM.SetHTTPClient(client)
+	p.TTS.SetHTTPClient(client)
+	if cfg.BaseURL != "" {
+		p.STT.SetBaseURL(cfg.BaseURL)
+		p.LLM.SetBaseURL(cfg.BaseURL)
+		p.TTS.SetBaseURL(cfg.BaseURL)
+	}
+	if cfg.SampleRate > 0 {
+		p.STT.SetSampleRate(cfg.SampleRate)
+		p.TTS.SetSampleRate(cfg.SampleRate)
+	}
+	return p
+}
+
+func newClient(cfg Config) *http.Client {
+	base := http.DefaultClient
+	if cfg.HTTPClient != nil {
+		base = cfg.HTTPClient
+	}
+	client := *base
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	rt := &retryTransport{next: next, retries: cfg.MaxRetries, delay: cfg.RetryDelay, org: cfg.Organization}
+	if rt.retries == 0 {
+		rt.retries = 2
+	}
+	if rt.delay <= 0 {
+		rt.delay = 250 * time.Millisecond
+	}
+	client.Transport = rt
+	return &client
+}
@@ -0,0 +1,147 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/providers/tts"
+)
+
+// apiVoices are the voices the speech API accepts.
+var apiVoices = map[string]bool{
+	"alloy": true, "ash": true, "ballad": true, "coral": true, "echo": true, "fable": true,
+	"nova": true, "onyx": true, "sage": true, "shimmer": true, "verse": true,
+}
+
+// fakeAPI serves the three endpoints the providers use. The first chat
+// request fails with a 503, to be retried.
+func fakeAPI(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var chats atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil || r.FormValue("model") != "whisper-1" {
+			http.Error(w, "bad form", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"text":"what's the weather","segments":[{"no_speech_prob":0.1}]}`)
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		if chats.Add(1) == 1 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["stream"] != true {
+			fmt.Fprint(w, `{"choices":[{"message":{"content":"Sunny."}}]}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintln(w, `data: {"choices":[{"delta":{"content":"Sun"}}]}`)
+		fmt.Fprintln(w, `data: {"choices":[{"delta":{"content":"ny."}}]}`)
+		fmt.Fprintln(w, `data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":2}}`)
+		fmt.Fprintln(w, `data: [DONE]`)
+	})
+	mux.HandleFunc("/v1/audio/speech", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["response_format"] != "pcm" || !apiVoices[fmt.Sprint(req["voice"])] {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write(make([]byte, 2*24000/10)) // 100ms at 24kHz
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-test" || r.Header.Get("OpenAI-Organization") != "org-1" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &chats
+}
+
+func TestProviders(t *testing.T) {
+	srv, chats := fakeAPI(t)
+	p := New(Config{
+		APIKey:       "sk-test",
+		BaseURL:      srv.URL + "/v1",
+		Organization: "org-1",
+		SampleRate:   16000,
+		RetryDelay:   time.Millisecond,
+	})
+	ctx := context.Background()
+
+	res, err := p.STT.Transcribe(ctx, make([]byte, 3200), orchestrator.LanguageEn)
+	if err != nil || res.Text != "what's the weather" {
+		t.Fatalf("Transcribe: %+v, %v", res, err)
+	}
+
+	var streamed []string
+	reply, err := p.LLM.StreamComplete(ctx, []orchestrator.Message{{Role: "user", Content: res.Text}}, nil, func(s string) error {
+		streamed = append(streamed, s)
+		return nil
+	}, nil)
+	if err != nil || reply != "Sunny." || strings.Join(streamed, "|") != "Sun|ny." {
+		t.Fatalf("StreamComplete: %q %v, %v", reply, streamed, err)
+	}
+	if n := chats.Load(); n != 2 {
+		t.Errorf("expected the 503 to be retried once, got %d requests", n)
+	}
+
+	pcm, err := p.TTS.Synthesize(ctx, reply, "nova", orchestrator.LanguageEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2 * 16000 / 10; len(pcm) < want-4 || len(pcm) > want+4 {
+		t.Errorf("got %d bytes of audio, want 100ms at 16kHz (%d)", len(pcm), want)
+	}
+}
+
+func TestProviders_DefaultVoice(t *testing.T) {
+	srv, _ := fakeAPI(t)
+	p := New(Config{APIKey: "sk-test", BaseURL: srv.URL + "/v1", Organization: "org-1"})
+
+	voice := orchestrator.DefaultConfig().VoiceStyle
+	if _, err := p.TTS.Synthesize(context.Background(), "hello", voice, orchestrator.LanguageEn); err != nil {
+		t.Fatalf("default voice %s: %v", voice, err)
+	}
+	for v, name := range tts.DefaultOpenAIVoices {
+		if !apiVoices[name] {
+			t.Errorf("%s maps to %q, which the API rejects", v, name)
+		}
+	}
+}
+
+func TestRetryTransport_GivesUp(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	p := New(Config{APIKey: "k", BaseURL: srv.URL, MaxRetries: 1, RetryDelay: time.Millisecond})
+	if _, err := p.LLM.Complete(context.Background(), nil, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("got %d attempts, want 2", n)
+	}
+
+	calls.Store(0)
+	p = New(Config{APIKey: "k", BaseURL: srv.URL, MaxRetries: -1})
+	p.LLM.Complete(context.Background(), nil, nil)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("with retries disabled got %d attempts", n)
+	}
+}
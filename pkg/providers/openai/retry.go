@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// retryTransport retries requests the API failed with a server error, or
+// that lost their connection, after a doubling delay. Rate limits are left
+// to the orchestrator, which knows the Retry-After and can route elsewhere.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	delay   time.Duration
+	org     string
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.org != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("OpenAI-Organization", t.org)
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.retries || !retryable(req, resp, err) || req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(t.delay << attempt)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -12,15 +12,31 @@ import (
 )
 
 type AssemblyAISTT struct {
-	apiKey string
+	apiKey     string
+	sampleRate int
+	streamURL  string
 }
 
 func NewAssemblyAISTT(apiKey string) *AssemblyAISTT {
 	return &AssemblyAISTT{
-		apiKey: apiKey,
+		apiKey:     apiKey,
+		sampleRate: 44100,
+		streamURL:  "wss://streaming.assemblyai.com/v3/ws",
 	}
 }
 
+// SetSampleRate sets the rate of the audio StreamTranscribe is given; it
+// should match Config.SampleRate.
+func (s *AssemblyAISTT) SetSampleRate(rate int) {
+	s.sampleRate = rate
+}
+
+// SetStreamingURL points StreamTranscribe at another realtime endpoint,
+// such as the EU one.
+func (s *AssemblyAISTT) SetStreamingURL(url string) {
+	s.streamURL = url
+}
+
 func (s *AssemblyAISTT) Name() string {
 	return "assemblyai-stt"
 }
@@ -70,6 +86,11 @@ func (s *AssemblyAISTT) upload(ctx context.Context, audioPCM []byte) (string, er
 		return "", err
 	}
 	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, s.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", orchestrator.NewRateLimitError(s.Name(), resp)
+	}
 
 	var result struct {
 		UploadURL string `json:"upload_url"`
@@ -96,6 +117,11 @@ func (s *AssemblyAISTT) submit(ctx context.Context, uploadURL string, lang orche
 		return "", err
 	}
 	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, s.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", orchestrator.NewRateLimitError(s.Name(), resp)
+	}
 
 	var result struct {
 		ID string `json:"id"`
@@ -114,14 +140,32 @@ func (s *AssemblyAISTT) getTranscript(ctx context.Context, id string) (orchestra
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return orchestrator.TranscriptionResult{}, "", orchestrator.NewRateLimitError(s.Name(), resp)
+	}
+
 	var result struct {
 		Status     string  `json:"status"`
 		Text       string  `json:"text"`
 		Confidence float64 `json:"confidence"`
+		Words      []struct {
+			Text  string `json:"text"`
+			Start int64  `json:"start"` // Milliseconds
+			End   int64  `json:"end"`
+		} `json:"words"`
 	}
 	json.NewDecoder(resp.Body).Decode(&result)
+	var words []orchestrator.WordTiming
+	for _, w := range result.Words {
+		words = append(words, orchestrator.WordTiming{
+			Word:  w.Text,
+			Start: time.Duration(w.Start) * time.Millisecond,
+			End:   time.Duration(w.End) * time.Millisecond,
+		})
+	}
 	return orchestrator.TranscriptionResult{
 		Text:         result.Text,
 		NoSpeechProb: 1.0 - result.Confidence,
+		Words:        words,
 	}, result.Status, nil
 }
@@ -0,0 +1,135 @@
+package stt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+const (
+	assemblyAIRate = 16000
+	// assemblyAIChunk is 100ms of audio; the service takes 50ms to 1s at a
+	// time.
+	assemblyAIChunk = assemblyAIRate * 2 / 10
+	// assemblyAIEndTimeout bounds the wait for the last turn once the audio
+	// has ended.
+	assemblyAIEndTimeout = 5 * time.Second
+)
+
+// assemblyAIMultilingual are the languages of the multilingual streaming
+// model; the English model is used otherwise.
+var assemblyAIMultilingual = map[orchestrator.Language]bool{
+	orchestrator.LanguageEs: true, orchestrator.LanguageFr: true, orchestrator.LanguageDe: true,
+	orchestrator.LanguageIt: true, orchestrator.LanguagePt: true,
+}
+
+// StreamTranscribe transcribes over AssemblyAI's realtime WebSocket until the
+// returned channel is closed or ctx is done. Words of the turn in progress
+// come as interim transcripts. When the service decides the user has
+// finished speaking, the formatted turn is reported as final, and the
+// orchestrator takes that as the end of the turn.
+func (s *AssemblyAISTT) StreamTranscribe(ctx context.Context, lang orchestrator.Language, onTranscript func(transcript string, isFinal bool) error) (chan<- []byte, error) {
+	q := url.Values{}
+	q.Set("sample_rate", strconv.Itoa(assemblyAIRate))
+	q.Set("encoding", "pcm_s16le")
+	q.Set("format_turns", "true")
+	if assemblyAIMultilingual[lang] {
+		q.Set("speech_model", "universal-streaming-multilingual")
+	}
+	header := http.Header{}
+	header.Set("Authorization", s.apiKey)
+	conn, resp, err := websocket.Dial(ctx, s.streamURL+"?"+q.Encode(), &websocket.DialOptions{HTTPHeader: header})
+	orchestrator.ReportResponse(ctx, s.Name(), resp)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			return nil, orchestrator.NewRateLimitError(s.Name(), resp)
+		}
+		return nil, fmt.Errorf("failed to connect to assemblyai: %w", err)
+	}
+
+	in := make(chan []byte, 256)
+	go s.sendStream(ctx, conn, in)
+	go s.receiveStream(ctx, conn, onTranscript)
+	return in, nil
+}
+
+// sendStream sends the audio from in in 100ms chunks until in is closed,
+// then asks the service to finish.
+func (s *AssemblyAISTT) sendStream(ctx context.Context, conn *websocket.Conn, in <-chan []byte) {
+	resampler := audio.NewResampler(s.sampleRate, assemblyAIRate)
+	var pending []byte
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case chunk, ok := <-in:
+			if !ok {
+				if len(pending) > 0 {
+					conn.Write(ctx, websocket.MessageBinary, pending)
+				}
+				wsjson.Write(ctx, conn, map[string]string{"type": "Terminate"})
+				time.AfterFunc(assemblyAIEndTimeout, func() { conn.Close(websocket.StatusNormalClosure, "") })
+				return
+			}
+			pending = append(pending, resampler.Write(chunk)...)
+			for len(pending) >= assemblyAIChunk {
+				if err := conn.Write(ctx, websocket.MessageBinary, pending[:assemblyAIChunk]); err != nil {
+					return
+				}
+				pending = pending[assemblyAIChunk:]
+			}
+		}
+	}
+}
+
+type assemblyAIMessage struct {
+	Type            string `json:"type"`
+	Transcript      string `json:"transcript"`
+	EndOfTurn       bool   `json:"end_of_turn"`
+	TurnIsFormatted bool   `json:"turn_is_formatted"`
+	Error           string `json:"error"`
+}
+
+// receiveStream reports turns until the session terminates, the connection
+// fails or ctx is done.
+func (s *AssemblyAISTT) receiveStream(ctx context.Context, conn *websocket.Conn, onTranscript func(string, bool) error) {
+	defer orchestrator.ReportTranscriptionDone(ctx)
+	defer conn.Close(websocket.StatusNormalClosure, "")
+	for {
+		var msg assemblyAIMessage
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		if typ != websocket.MessageText || json.Unmarshal(data, &msg) != nil {
+			continue
+		}
+		switch msg.Type {
+		case "Turn":
+			if msg.Transcript == "" {
+				continue
+			}
+			// An ended turn comes twice with format_turns: as spoken, then
+			// punctuated and cased. Only the second is final.
+			final := msg.EndOfTurn && msg.TurnIsFormatted
+			if onTranscript(msg.Transcript, final) != nil {
+				return
+			}
+		case "Termination":
+			return
+		default:
+			if msg.Error != "" {
+				return
+			}
+		}
+	}
+}
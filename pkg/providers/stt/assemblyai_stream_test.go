@@ -0,0 +1,99 @@
+package stt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestAssemblyAISTT_StreamTranscribe(t *testing.T) {
+	var query string
+	var chunks []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "test-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		query = r.URL.RawQuery
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		ctx := r.Context()
+		wsjson.Write(ctx, conn, map[string]any{"type": "Begin", "id": "s1"})
+		for {
+			typ, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			if typ == websocket.MessageBinary {
+				chunks = append(chunks, len(data))
+				if len(chunks) == 1 {
+					wsjson.Write(ctx, conn, map[string]any{"type": "Turn", "transcript": "hola que", "end_of_turn": false})
+				}
+				continue
+			}
+			if !strings.Contains(string(data), "Terminate") {
+				continue
+			}
+			wsjson.Write(ctx, conn, map[string]any{"type": "Turn", "transcript": "hola que tal", "end_of_turn": true})
+			wsjson.Write(ctx, conn, map[string]any{"type": "Turn", "transcript": "Hola, ¿qué tal?", "end_of_turn": true, "turn_is_formatted": true})
+			wsjson.Write(ctx, conn, map[string]any{"type": "Termination"})
+			conn.Close(websocket.StatusNormalClosure, "")
+			return
+		}
+	}))
+	defer server.Close()
+
+	s := NewAssemblyAISTT("test-key")
+	s.SetSampleRate(16000)
+	s.SetStreamingURL("ws" + strings.TrimPrefix(server.URL, "http"))
+
+	var mu sync.Mutex
+	var got []string
+	done := make(chan struct{})
+	in, err := s.StreamTranscribe(context.Background(), orchestrator.LanguageEs, func(transcript string, isFinal bool) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if isFinal {
+			transcript = "final: " + transcript
+			close(done)
+		}
+		got = append(got, transcript)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 250ms in 10ms chunks goes out as two 100ms chunks and the rest.
+	for range 25 {
+		in <- make([]byte, 320)
+	}
+	close(in)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no final transcript")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(got, "|") != "hola que|hola que tal|final: Hola, ¿qué tal?" {
+		t.Errorf("transcripts = %q", got)
+	}
+	if len(chunks) != 3 || chunks[0] != 3200 || chunks[2] != 1600 {
+		t.Errorf("audio chunks = %v", chunks)
+	}
+	if !strings.Contains(query, "sample_rate=16000") || !strings.Contains(query, "speech_model=universal-streaming-multilingual") {
+		t.Errorf("query = %s", query)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
 )
@@ -55,6 +56,11 @@ func (s *DeepgramSTT) Transcribe(ctx context.Context, audioPCM []byte, lang orch
 		return orchestrator.TranscriptionResult{}, err
 	}
 	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, s.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return orchestrator.TranscriptionResult{}, orchestrator.NewRateLimitError(s.Name(), resp)
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
@@ -65,8 +71,13 @@ func (s *DeepgramSTT) Transcribe(ctx context.Context, audioPCM []byte, lang orch
 		Results struct {
 			Channels []struct {
 				Alternatives []struct {
-					Transcript string `json:"transcript"`
+					Transcript string  `json:"transcript"`
 					Confidence float64 `json:"confidence"`
+					Words      []struct {
+						Word  string  `json:"word"`
+						Start float64 `json:"start"`
+						End   float64 `json:"end"`
+					} `json:"words"`
 				} `json:"alternatives"`
 			} `json:"channels"`
 		} `json:"results"`
@@ -81,8 +92,17 @@ func (s *DeepgramSTT) Transcribe(ctx context.Context, audioPCM []byte, lang orch
 	}
 
 	alt := result.Results.Channels[0].Alternatives[0]
+	var words []orchestrator.WordTiming
+	for _, w := range alt.Words {
+		words = append(words, orchestrator.WordTiming{
+			Word:  w.Word,
+			Start: time.Duration(w.Start * float64(time.Second)),
+			End:   time.Duration(w.End * float64(time.Second)),
+		})
+	}
 	return orchestrator.TranscriptionResult{
 		Text:         alt.Transcript,
 		NoSpeechProb: 1.0 - alt.Confidence,
+		Words:        words,
 	}, nil
 }
@@ -76,12 +76,18 @@ func (s *GroqSTT) Transcribe(ctx context.Context, audioPCM []byte, lang orchestr
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	orchestrator.SetIdempotencyHeader(req)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return orchestrator.TranscriptionResult{}, err
 	}
 	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, s.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return orchestrator.TranscriptionResult{}, orchestrator.NewRateLimitError(s.Name(), resp)
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp interface{}
@@ -120,3 +126,8 @@ func (s *GroqSTT) Transcribe(ctx context.Context, audioPCM []byte, lang orchestr
 func (s *GroqSTT) Name() string {
 	return "groq-stt"
 }
+
+// Model returns the model requests are made with.
+func (s *GroqSTT) Model() string {
+	return s.model
+}
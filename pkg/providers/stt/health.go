@@ -0,0 +1,48 @@
+package stt
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// The STT providers implement orchestrator.HealthChecker with a read-only
+// request that needs a valid key, so nothing is transcribed or billed.
+
+func (s *OpenAISTT) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(s.url, "/audio/transcriptions")+"/models/"+s.model, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	return orchestrator.PingHTTP(s.client, s.Name(), req)
+}
+
+func (s *GroqSTT) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(s.url, "/audio/transcriptions")+"/models/"+s.model, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	return orchestrator.PingHTTP(nil, s.Name(), req)
+}
+
+func (s *DeepgramSTT) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(s.url, "/listen")+"/projects", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+s.apiKey)
+	return orchestrator.PingHTTP(nil, s.Name(), req)
+}
+
+func (s *AssemblyAISTT) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.assemblyai.com/v2/transcript?limit=1", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", s.apiKey)
+	return orchestrator.PingHTTP(nil, s.Name(), req)
+}
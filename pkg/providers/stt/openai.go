@@ -8,6 +8,7 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"strings"
 
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
 	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
@@ -18,6 +19,7 @@ type OpenAISTT struct {
 	url        string
 	model      string
 	sampleRate int
+	client     *http.Client
 }
 
 func NewOpenAISTT(apiKey string, model string) *OpenAISTT {
@@ -36,10 +38,26 @@ func (s *OpenAISTT) SetSampleRate(rate int) {
 	s.sampleRate = rate
 }
 
+// SetBaseURL points the provider at another OpenAI-compatible API, given
+// its base URL ending in /v1.
+func (s *OpenAISTT) SetBaseURL(base string) {
+	s.url = strings.TrimSuffix(base, "/") + "/audio/transcriptions"
+}
+
+// SetHTTPClient sets the client requests are made with.
+func (s *OpenAISTT) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
 func (s *OpenAISTT) Name() string {
 	return "openai_stt"
 }
 
+// Model returns the model requests are made with.
+func (s *OpenAISTT) Model() string {
+	return s.model
+}
+
 func (s *OpenAISTT) Transcribe(ctx context.Context, audioPCM []byte, lang orchestrator.Language) (orchestrator.TranscriptionResult, error) {
 	wavData := audio.NewWavBuffer(audioPCM, s.sampleRate)
 
@@ -76,12 +94,22 @@ func (s *OpenAISTT) Transcribe(ctx context.Context, audioPCM []byte, lang orches
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	orchestrator.SetIdempotencyHeader(req)
 
-	resp, err := http.DefaultClient.Do(req)
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return orchestrator.TranscriptionResult{}, err
 	}
 	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, s.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return orchestrator.TranscriptionResult{}, orchestrator.NewRateLimitError(s.Name(), resp)
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
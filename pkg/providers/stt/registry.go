@@ -0,0 +1,47 @@
+package stt
+
+import (
+	"cmp"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// The package's providers register with orchestrator.DefaultRegistry, for
+// pipelines chosen by name with orchestrator.BuildFromConfig.
+func init() {
+	orchestrator.RegisterSTT("groq", func(o orchestrator.ProviderOptions) (orchestrator.STTProvider, error) {
+		if err := o.RequireAPIKey("groq"); err != nil {
+			return nil, err
+		}
+		s := NewGroqSTT(o.APIKey, cmp.Or(o.Model, "whisper-large-v3-turbo"))
+		orchestrator.ApplyProviderOptions(s, o)
+		return s, nil
+	})
+	orchestrator.RegisterSTT("openai", func(o orchestrator.ProviderOptions) (orchestrator.STTProvider, error) {
+		if err := o.RequireAPIKey("openai"); err != nil {
+			return nil, err
+		}
+		s := NewOpenAISTT(o.APIKey, cmp.Or(o.Model, "whisper-1"))
+		orchestrator.ApplyProviderOptions(s, o)
+		return s, nil
+	})
+	orchestrator.RegisterSTT("deepgram", func(o orchestrator.ProviderOptions) (orchestrator.STTProvider, error) {
+		if err := o.RequireAPIKey("deepgram"); err != nil {
+			return nil, err
+		}
+		s := NewDeepgramSTT(o.APIKey)
+		orchestrator.ApplyProviderOptions(s, o)
+		return s, nil
+	})
+	orchestrator.RegisterSTT("assemblyai", func(o orchestrator.ProviderOptions) (orchestrator.STTProvider, error) {
+		if err := o.RequireAPIKey("assemblyai"); err != nil {
+			return nil, err
+		}
+		s := NewAssemblyAISTT(o.APIKey)
+		orchestrator.ApplyProviderOptions(s, o)
+		if url := o.Option("streaming_url", ""); url != "" {
+			s.SetStreamingURL(url)
+		}
+		return s, nil
+	})
+}
@@ -0,0 +1,23 @@
+package stt
+
+import (
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestRegistered(t *testing.T) {
+	p, err := orchestrator.DefaultRegistry.NewSTT(orchestrator.ProviderSpec{
+		Name:            "groq",
+		ProviderOptions: orchestrator.ProviderOptions{APIKey: "k", SampleRate: 16000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := p.(*GroqSTT); s.Model() != "whisper-large-v3-turbo" || s.sampleRate != 16000 {
+		t.Errorf("groq = %s at %d Hz", s.Model(), s.sampleRate)
+	}
+	if _, err := orchestrator.DefaultRegistry.NewSTT(orchestrator.ProviderSpec{Name: "deepgram"}); err == nil {
+		t.Error("deepgram without a key should fail")
+	}
+}
@@ -0,0 +1,249 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// DefaultElevenLabsVoices maps the package's voices to ElevenLabs premade
+// voices available on every account.
+var DefaultElevenLabsVoices = map[orchestrator.Voice]string{
+	orchestrator.VoiceF1: "21m00Tcm4TlvDq8ikWAM", // Rachel
+	orchestrator.VoiceF2: "EXAVITQu4vr4xnSDxMaL", // Sarah
+	orchestrator.VoiceF3: "MF3mGyEYCl7XYWbV9V6O", // Elli
+	orchestrator.VoiceF4: "AZnzlk1XvdvUeBnXmlld", // Domi
+	orchestrator.VoiceF5: "ThT5KcBeYPX3keUQqHPh", // Dorothy
+	orchestrator.VoiceM1: "pNInz6obpgDQGcFmaJgB", // Adam
+	orchestrator.VoiceM2: "ErXwobaYiN019PkySvjV", // Antoni
+	orchestrator.VoiceM3: "TxGEqnHWrfWFTfGW9XjX", // Josh
+	orchestrator.VoiceM4: "VR6AewLTigWG4xSOukaG", // Arnold
+	orchestrator.VoiceM5: "yoZ06aMxZJJ28mfd3POQ", // Sam
+}
+
+// elevenLabsRates are the raw PCM output formats the API offers, pcm_<rate>.
+// The highest need a paid plan.
+var elevenLabsRates = []int{8000, 16000, 22050, 24000, 44100, 48000}
+
+// ElevenLabsTTS synthesizes speech with the ElevenLabs streaming API. It
+// asks for raw PCM at the pipeline's rate, or the nearest rate the account
+// may use, resampling what arrives.
+type ElevenLabsTTS struct {
+	apiKey     string
+	url        string
+	model      string
+	sampleRate int
+	client     *http.Client
+	voices     map[orchestrator.Voice]string
+
+	mu      sync.Mutex
+	cancels map[*context.CancelFunc]struct{}
+	refused map[int]bool // Output rates the account is not allowed
+}
+
+// NewElevenLabsTTS returns a provider using model, e.g. eleven_flash_v2_5;
+// "" uses eleven_flash_v2_5.
+func NewElevenLabsTTS(apiKey string, model string) *ElevenLabsTTS {
+	if model == "" {
+		model = "eleven_flash_v2_5"
+	}
+	return &ElevenLabsTTS{
+		apiKey:     apiKey,
+		url:        "https://api.elevenlabs.io/v1",
+		model:      model,
+		sampleRate: 44100,
+		voices:     DefaultElevenLabsVoices,
+		cancels:    make(map[*context.CancelFunc]struct{}),
+		refused:    make(map[int]bool),
+	}
+}
+
+// SetSampleRate sets the rate audio is delivered at; it should match
+// Config.SampleRate.
+func (t *ElevenLabsTTS) SetSampleRate(rate int) {
+	t.sampleRate = rate
+}
+
+// SetVoices replaces the mapping from the package's voices to ElevenLabs
+// voice IDs. Voices missing from it are taken to be voice IDs themselves,
+// so a session can also speak with any voice of the account directly.
+func (t *ElevenLabsTTS) SetVoices(voices map[orchestrator.Voice]string) {
+	t.voices = voices
+}
+
+// SetBaseURL points the provider at another host, given its base URL
+// ending in /v1.
+func (t *ElevenLabsTTS) SetBaseURL(base string) {
+	t.url = strings.TrimSuffix(base, "/")
+}
+
+// SetHTTPClient sets the client requests are made with.
+func (t *ElevenLabsTTS) SetHTTPClient(client *http.Client) {
+	t.client = client
+}
+
+func (t *ElevenLabsTTS) voiceID(voice orchestrator.Voice) string {
+	if voice == "" {
+		voice = orchestrator.VoiceF1
+	}
+	if id, ok := t.voices[voice]; ok {
+		return id
+	}
+	return string(voice)
+}
+
+// outputRate picks the format to ask for: the pipeline's rate if offered,
+// else the lowest above it, else the highest the account may use.
+func (t *ElevenLabsTTS) outputRate() (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	best := 0
+	for _, rate := range elevenLabsRates {
+		if t.refused[rate] {
+			continue
+		}
+		if rate >= t.sampleRate {
+			return rate, true
+		}
+		best = rate
+	}
+	return best, best > 0
+}
+
+func (t *ElevenLabsTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	var out []byte
+	err := t.StreamSynthesize(ctx, text, voice, lang, func(chunk []byte) error {
+		out = append(out, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamSynthesize speaks text in voice, streaming audio as it is
+// generated. Multilingual v2.5 models are told the language; the others
+// detect it from the text.
+func (t *ElevenLabsTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	payload := map[string]interface{}{
+		"text":     text,
+		"model_id": t.model,
+	}
+	if lang != "" && strings.HasSuffix(t.model, "_v2_5") {
+		payload["language_code"] = string(lang)
+	}
+	if speed := orchestrator.SpeechRateFromContext(ctx); speed != 1 {
+		payload["voice_settings"] = map[string]interface{}{"speed": min(max(speed, 0.7), 1.2)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.mu.Lock()
+	t.cancels[&cancel] = struct{}{}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.cancels, &cancel)
+		t.mu.Unlock()
+		cancel()
+	}()
+
+	for {
+		rate, ok := t.outputRate()
+		if !ok {
+			return fmt.Errorf("elevenlabs tts error: no PCM output format allowed")
+		}
+		resp, err := t.request(ctx, voice, rate, body)
+		if err != nil {
+			return err
+		}
+		orchestrator.ReportResponse(ctx, t.Name(), resp)
+		if resp.StatusCode == http.StatusOK {
+			defer resp.Body.Close()
+			return t.stream(resp.Body, rate, onChunk)
+		}
+
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return orchestrator.NewRateLimitError(t.Name(), resp)
+		}
+		// Plans without high-quality output refuse the format; step down.
+		if resp.StatusCode < 500 && bytes.Contains(respBody, []byte("output_format")) {
+			t.mu.Lock()
+			t.refused[rate] = true
+			t.mu.Unlock()
+			continue
+		}
+		return fmt.Errorf("elevenlabs tts error: %s (status %d)", string(respBody), resp.StatusCode)
+	}
+}
+
+func (t *ElevenLabsTTS) request(ctx context.Context, voice orchestrator.Voice, rate int, body []byte) (*http.Response, error) {
+	url := fmt.Sprintf("%s/text-to-speech/%s/stream?output_format=pcm_%d", t.url, t.voiceID(voice), rate)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("xi-api-key", t.apiKey)
+	orchestrator.SetIdempotencyHeader(req)
+
+	client := t.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+func (t *ElevenLabsTTS) stream(body io.Reader, rate int, onChunk func([]byte) error) error {
+	resampler := audio.NewResampler(rate, t.sampleRate)
+	buf := make([]byte, 4096)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if chunk := resampler.Write(append([]byte(nil), buf[:n]...)); len(chunk) > 0 {
+				if err := onChunk(chunk); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Abort cancels every synthesis in progress.
+func (t *ElevenLabsTTS) Abort() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for cancel := range t.cancels {
+		(*cancel)()
+	}
+	return nil
+}
+
+func (t *ElevenLabsTTS) Name() string {
+	return "elevenlabs"
+}
+
+// Model returns the model requests are made with.
+func (t *ElevenLabsTTS) Model() string {
+	return t.model
+}
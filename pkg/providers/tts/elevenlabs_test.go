@@ -0,0 +1,75 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+func TestElevenLabsTTS(t *testing.T) {
+	var formats, paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("xi-api-key") != "test-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		format := r.URL.Query().Get("output_format")
+		formats = append(formats, format)
+		paths = append(paths, r.URL.Path)
+		// The account's plan stops at 24kHz.
+		rate, _ := strconv.Atoi(strings.TrimPrefix(format, "pcm_"))
+		if rate > 24000 {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"detail":{"status":"output_format_not_allowed","message":"upgrade for pcm_44100"}}`))
+			return
+		}
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["model_id"] != "eleven_flash_v2_5" || req["language_code"] != "es" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write(make([]byte, 2*rate/10)) // 100ms
+	}))
+	defer server.Close()
+
+	tts := NewElevenLabsTTS("test-key", "")
+	tts.SetBaseURL(server.URL + "/v1")
+	ctx := context.Background()
+
+	audio, err := tts.Synthesize(ctx, "hola", orchestrator.VoiceM1, orchestrator.LanguageEs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2 * 44100 / 10; len(audio) < want-8 || len(audio) > want+8 {
+		t.Errorf("got %d bytes, want 100ms at 44.1kHz (%d)", len(audio), want)
+	}
+	if strings.Join(formats, ",") != "pcm_44100,pcm_48000,pcm_24000" {
+		t.Errorf("negotiated with %v", formats)
+	}
+	if paths[0] != "/v1/text-to-speech/pNInz6obpgDQGcFmaJgB/stream" {
+		t.Errorf("M1 went to %s", paths[0])
+	}
+
+	// The refused formats are remembered, and voice IDs pass through.
+	formats, paths = nil, nil
+	if _, err := tts.Synthesize(ctx, "hola", "myClonedVoice", orchestrator.LanguageEs); err != nil {
+		t.Fatal(err)
+	}
+	if len(formats) != 1 || formats[0] != "pcm_24000" || paths[0] != "/v1/text-to-speech/myClonedVoice/stream" {
+		t.Errorf("second request: %v %v", formats, paths)
+	}
+
+	// At a rate the API offers, audio is not resampled.
+	tts.SetSampleRate(16000)
+	audio, err = tts.Synthesize(ctx, "hola", orchestrator.VoiceF1, orchestrator.LanguageEs)
+	if err != nil || len(audio) != 3200 || formats[len(formats)-1] != "pcm_16000" {
+		t.Errorf("at 16kHz got %d bytes via %v, %v", len(audio), formats, err)
+	}
+}
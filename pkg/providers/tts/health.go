@@ -0,0 +1,54 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// The TTS providers implement orchestrator.HealthChecker without
+// synthesizing anything.
+
+func (t *OpenAITTS) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(t.url, "/audio/speech")+"/models/"+t.model, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	return orchestrator.PingHTTP(t.client, t.Name(), req)
+}
+
+func (t *ElevenLabsTTS) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.url+"/models", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("xi-api-key", t.apiKey)
+	return orchestrator.PingHTTP(t.client, t.Name(), req)
+}
+
+// Ping checks that the piper binary is installed and its voice models are
+// still on disk.
+func (t *PiperTTS) Ping(ctx context.Context) error {
+	if _, err := exec.LookPath(t.binary); err != nil {
+		return err
+	}
+	for _, v := range t.voices {
+		if _, err := os.Stat(v.Model); err != nil {
+			return fmt.Errorf("piper voice %s: %w", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// Ping opens the websocket if it isn't already, so a probe also warms the
+// connection.
+func (t *LokutorTTS) Ping(ctx context.Context) error {
+	_, err := t.getConn(ctx)
+	return err
+}
@@ -36,7 +36,8 @@ func (t *LokutorTTS) getConn(ctx context.Context) (*websocket.Conn, error) {
 	}
 
 	u := url.URL{Scheme: t.scheme, Host: t.host, Path: "/ws", RawQuery: "api_key=" + t.apiKey}
-	conn, _, err := websocket.Dial(ctx, u.String(), nil)
+	conn, resp, err := websocket.Dial(ctx, u.String(), nil)
+	orchestrator.ReportResponse(ctx, t.Name(), resp)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to lokutor: %w", err)
 	}
@@ -70,7 +71,7 @@ func (t *LokutorTTS) StreamSynthesize(ctx context.Context, text string, voice or
 		"text":    text,
 		"voice":   string(voice),
 		"lang":    string(lang),
-		"speed":   1.0,
+		"speed":   orchestrator.SpeechRateFromContext(ctx),
 		"steps":   6,
 		"visemes": false,
 	}
@@ -0,0 +1,202 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// openAIRate is the sample rate of the raw PCM the speech API returns.
+const openAIRate = 24000
+
+// DefaultOpenAIVoices maps the package's voices to the speech API's
+// built-in voices.
+var DefaultOpenAIVoices = map[orchestrator.Voice]string{
+	orchestrator.VoiceF1: "nova",
+	orchestrator.VoiceF2: "shimmer",
+	orchestrator.VoiceF3: "coral",
+	orchestrator.VoiceF4: "sage",
+	orchestrator.VoiceF5: "alloy",
+	orchestrator.VoiceM1: "onyx",
+	orchestrator.VoiceM2: "echo",
+	orchestrator.VoiceM3: "ash",
+	orchestrator.VoiceM4: "fable",
+	orchestrator.VoiceM5: "verse",
+}
+
+// OpenAITTS synthesizes speech with the OpenAI speech API, streaming the
+// audio as it is generated and resampling it to the pipeline's rate.
+type OpenAITTS struct {
+	apiKey     string
+	url        string
+	model      string
+	sampleRate int
+	voices     map[orchestrator.Voice]string
+	client     *http.Client
+
+	mu      sync.Mutex
+	cancels map[*context.CancelFunc]struct{}
+}
+
+func NewOpenAITTS(apiKey string, model string) *OpenAITTS {
+	if model == "" {
+		model = "gpt-4o-mini-tts"
+	}
+	return &OpenAITTS{
+		apiKey:     apiKey,
+		url:        "https://api.openai.com/v1/audio/speech",
+		model:      model,
+		sampleRate: 44100,
+		voices:     DefaultOpenAIVoices,
+		cancels:    make(map[*context.CancelFunc]struct{}),
+	}
+}
+
+// SetSampleRate sets the rate audio is delivered at; it should match
+// Config.SampleRate.
+func (t *OpenAITTS) SetSampleRate(rate int) {
+	t.sampleRate = rate
+}
+
+// SetBaseURL points the provider at another OpenAI-compatible API, given
+// its base URL ending in /v1.
+func (t *OpenAITTS) SetBaseURL(base string) {
+	t.url = strings.TrimSuffix(base, "/") + "/audio/speech"
+}
+
+// SetVoices replaces the mapping from the package's voices to OpenAI voice
+// names. Voices missing from it are taken to be voice names themselves, so
+// a session can also ask for "alloy" directly.
+func (t *OpenAITTS) SetVoices(voices map[orchestrator.Voice]string) {
+	t.voices = voices
+}
+
+func (t *OpenAITTS) voiceName(voice orchestrator.Voice) string {
+	if voice == "" {
+		voice = orchestrator.VoiceF1
+	}
+	if name, ok := t.voices[voice]; ok {
+		return name
+	}
+	return string(voice)
+}
+
+// SetHTTPClient sets the client requests are made with.
+func (t *OpenAITTS) SetHTTPClient(client *http.Client) {
+	t.client = client
+}
+
+func (t *OpenAITTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	var out []byte
+	err := t.StreamSynthesize(ctx, text, voice, lang, func(chunk []byte) error {
+		out = append(out, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamSynthesize speaks text in voice, mapped through SetVoices to one of
+// the API's voices; "" speaks as VoiceF1. The API detects the language from
+// the text.
+func (t *OpenAITTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	payload := map[string]interface{}{
+		"model":           t.model,
+		"input":           text,
+		"voice":           t.voiceName(voice),
+		"response_format": "pcm",
+	}
+	if speed := orchestrator.SpeechRateFromContext(ctx); speed != 1 {
+		payload["speed"] = speed
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.mu.Lock()
+	t.cancels[&cancel] = struct{}{}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.cancels, &cancel)
+		t.mu.Unlock()
+		cancel()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	orchestrator.SetIdempotencyHeader(req)
+
+	client := t.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	orchestrator.ReportResponse(ctx, t.Name(), resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return orchestrator.NewRateLimitError(t.Name(), resp)
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("openai tts error: %s (status %d)", string(respBody), resp.StatusCode)
+	}
+
+	resampler := audio.NewResampler(openAIRate, t.sampleRate)
+	buf := make([]byte, 4800) // 100ms
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if chunk := resampler.Write(append([]byte(nil), buf[:n]...)); len(chunk) > 0 {
+				if err := onChunk(chunk); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Abort cancels every synthesis in progress.
+func (t *OpenAITTS) Abort() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for cancel := range t.cancels {
+		(*cancel)()
+	}
+	return nil
+}
+
+func (t *OpenAITTS) Name() string {
+	return "openai_tts"
+}
+
+// Model returns the model requests are made with.
+func (t *OpenAITTS) Model() string {
+	return t.model
+}
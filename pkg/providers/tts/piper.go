@@ -0,0 +1,282 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/audio"
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// PiperVoice is a Piper voice model found on disk.
+type PiperVoice struct {
+	Name       string                // The model's file name without .onnx, e.g. "en_US-lessac-medium"
+	Model      string                // Path to the .onnx file
+	Language   orchestrator.Language // e.g. "en"
+	Locale     string                // e.g. "en_US"
+	SampleRate int
+	Speakers   map[string]int // Of multi-speaker models, by name
+}
+
+// DiscoverPiperVoices finds the voice models under dir: each .onnx file
+// with its .onnx.json config next to it. Models without a config are
+// skipped, since their rate and language are unknown.
+func DiscoverPiperVoices(dir string) ([]PiperVoice, error) {
+	var voices []PiperVoice
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".onnx") {
+			return nil
+		}
+		data, err := os.ReadFile(path + ".json")
+		if err != nil {
+			return nil
+		}
+		var cfg struct {
+			Audio struct {
+				SampleRate int `json:"sample_rate"`
+			} `json:"audio"`
+			Language struct {
+				Code   string `json:"code"`
+				Family string `json:"family"`
+			} `json:"language"`
+			SpeakerIDMap map[string]int `json:"speaker_id_map"`
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("piper voice %s: %w", path, err)
+		}
+		v := PiperVoice{
+			Name:       strings.TrimSuffix(filepath.Base(path), ".onnx"),
+			Model:      path,
+			Locale:     cfg.Language.Code,
+			Language:   orchestrator.Language(cfg.Language.Family),
+			SampleRate: cfg.Audio.SampleRate,
+		}
+		if v.Language == "" {
+			v.Language = orchestrator.Language(strings.SplitN(v.Locale, "_", 2)[0])
+		}
+		if v.SampleRate == 0 {
+			v.SampleRate = 22050
+		}
+		if len(cfg.SpeakerIDMap) > 1 {
+			v.Speakers = cfg.SpeakerIDMap
+		}
+		voices = append(voices, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(voices, func(i, j int) bool { return voices[i].Name < voices[j].Name })
+	return voices, nil
+}
+
+// piperVoiceOrder spreads the package's voices over a language's models,
+// alternating so that F1 and M1 differ whenever there are two.
+var piperVoiceOrder = []orchestrator.Voice{
+	orchestrator.VoiceF1, orchestrator.VoiceM1,
+	orchestrator.VoiceF2, orchestrator.VoiceM2,
+	orchestrator.VoiceF3, orchestrator.VoiceM3,
+	orchestrator.VoiceF4, orchestrator.VoiceM4,
+	orchestrator.VoiceF5, orchestrator.VoiceM5,
+}
+
+// PiperTTS synthesizes speech offline with Piper, running the piper binary
+// for each request and reading raw PCM from it, so a deployment needs no
+// network access for speech. Voices come from a directory of models.
+type PiperTTS struct {
+	binary     string
+	voices     []PiperVoice
+	mapping    map[orchestrator.Voice]string
+	sampleRate int
+
+	mu      sync.Mutex
+	cancels map[*context.CancelFunc]struct{}
+}
+
+// NewPiperTTS returns a provider speaking with the models under modelDir.
+func NewPiperTTS(modelDir string) (*PiperTTS, error) {
+	voices, err := DiscoverPiperVoices(modelDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(voices) == 0 {
+		return nil, fmt.Errorf("no piper voices in %s", modelDir)
+	}
+	return &PiperTTS{
+		binary:     "piper",
+		voices:     voices,
+		sampleRate: 44100,
+		cancels:    make(map[*context.CancelFunc]struct{}),
+	}, nil
+}
+
+// SetBinary sets the piper executable, by path or name on the PATH.
+func (t *PiperTTS) SetBinary(path string) {
+	t.binary = path
+}
+
+// SetSampleRate sets the rate audio is delivered at; it should match
+// Config.SampleRate.
+func (t *PiperTTS) SetSampleRate(rate int) {
+	t.sampleRate = rate
+}
+
+// SetVoices maps the package's voices to models by name. A name may end in
+// "#speaker" to pick a speaker of a multi-speaker model, by name or ID.
+// Voices missing from it are spread over the models for the session's
+// language in name order, F1 and M1 first, then F2 and M2, and so on.
+func (t *PiperTTS) SetVoices(voices map[orchestrator.Voice]string) {
+	t.mapping = voices
+}
+
+// Voices returns the models found, by name.
+func (t *PiperTTS) Voices() []PiperVoice {
+	return append([]PiperVoice(nil), t.voices...)
+}
+
+// resolve picks the model and speaker for voice in lang. A voice that is
+// a model name, with or without a speaker, is used as it is.
+func (t *PiperTTS) resolve(voice orchestrator.Voice, lang orchestrator.Language) (PiperVoice, string, error) {
+	name := string(voice)
+	if mapped, ok := t.mapping[voice]; ok {
+		name = mapped
+	}
+	name, speaker, _ := strings.Cut(name, "#")
+	for _, v := range t.voices {
+		if v.Name == name {
+			return v, speaker, nil
+		}
+	}
+
+	if lang == "" {
+		lang = orchestrator.LanguageEn
+	}
+	var matches []PiperVoice
+	for _, v := range t.voices {
+		if v.Language == lang {
+			matches = append(matches, v)
+		}
+	}
+	if len(matches) == 0 {
+		return PiperVoice{}, "", fmt.Errorf("no piper voice for language %s", lang)
+	}
+	i := 0
+	for j, pv := range piperVoiceOrder {
+		if pv == voice {
+			i = j
+		}
+	}
+	return matches[i%len(matches)], "", nil
+}
+
+func (t *PiperTTS) Synthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language) ([]byte, error) {
+	var out []byte
+	err := t.StreamSynthesize(ctx, text, voice, lang, func(chunk []byte) error {
+		out = append(out, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamSynthesize speaks text, streaming audio as piper writes it.
+func (t *PiperTTS) StreamSynthesize(ctx context.Context, text string, voice orchestrator.Voice, lang orchestrator.Language, onChunk func([]byte) error) error {
+	v, speaker, err := t.resolve(voice, lang)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.mu.Lock()
+	t.cancels[&cancel] = struct{}{}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.cancels, &cancel)
+		t.mu.Unlock()
+		cancel()
+	}()
+
+	args := []string{"--model", v.Model, "--output_raw"}
+	if speaker != "" {
+		if id, ok := v.Speakers[speaker]; ok {
+			speaker = strconv.Itoa(id)
+		}
+		args = append(args, "--speaker", speaker)
+	}
+	if speed := orchestrator.SpeechRateFromContext(ctx); speed != 1 && speed > 0 {
+		args = append(args, "--length_scale", strconv.FormatFloat(1/speed, 'f', 3, 64))
+	}
+	cmd := exec.CommandContext(ctx, t.binary, args...)
+	// Piper reads one utterance per line.
+	cmd.Stdin = strings.NewReader(strings.Join(strings.Fields(text), " ") + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start piper: %w", err)
+	}
+
+	resampler := audio.NewResampler(v.SampleRate, t.sampleRate)
+	buf := make([]byte, 4096)
+	for {
+		n, readErr := stdout.Read(buf)
+		if n > 0 {
+			if chunk := resampler.Write(append([]byte(nil), buf[:n]...)); len(chunk) > 0 {
+				if err := onChunk(chunk); err != nil {
+					cancel()
+					cmd.Wait()
+					return err
+				}
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			cancel()
+			cmd.Wait()
+			return readErr
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("piper error: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return nil
+}
+
+// Abort stops every synthesis in progress.
+func (t *PiperTTS) Abort() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for cancel := range t.cancels {
+		(*cancel)()
+	}
+	return nil
+}
+
+func (t *PiperTTS) Name() string {
+	return "piper"
+}
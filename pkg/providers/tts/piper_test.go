@@ -0,0 +1,86 @@
+package tts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// fakePiper writes a script standing in for piper: it logs its arguments
+// and stdin and writes 100ms of silence at 22.05kHz.
+func fakePiper(t *testing.T, dir string) string {
+	t.Helper()
+	script := filepath.Join(dir, "piper")
+	body := "#!/bin/sh\necho \"$@\" >> " + filepath.Join(dir, "args") + "\ncat >> " + filepath.Join(dir, "stdin") + "\nhead -c 4410 /dev/zero\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func writeVoice(t *testing.T, dir, name, config string) {
+	t.Helper()
+	os.MkdirAll(dir, 0o755)
+	os.WriteFile(filepath.Join(dir, name+".onnx"), nil, 0o644)
+	if config != "" {
+		os.WriteFile(filepath.Join(dir, name+".onnx.json"), []byte(config), 0o644)
+	}
+}
+
+func TestPiperTTS(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("needs /bin/sh")
+	}
+	models := t.TempDir()
+	writeVoice(t, filepath.Join(models, "en"), "en_US-lessac-medium", `{"audio":{"sample_rate":22050},"language":{"code":"en_US","family":"en"}}`)
+	writeVoice(t, filepath.Join(models, "en"), "en_US-ryan-high", `{"audio":{"sample_rate":22050},"language":{"code":"en_US","family":"en"}}`)
+	writeVoice(t, filepath.Join(models, "es"), "es_ES-multi-medium", `{"audio":{"sample_rate":22050},"language":{"code":"es_ES"},"speaker_id_map":{"ana":0,"luis":1}}`)
+	writeVoice(t, models, "unconfigured", "")
+
+	tts, err := NewPiperTTS(models)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voices := tts.Voices()
+	if len(voices) != 3 || voices[2].Language != orchestrator.LanguageEs || voices[2].Speakers["luis"] != 1 {
+		t.Fatalf("voices = %+v", voices)
+	}
+	bin := t.TempDir()
+	tts.SetBinary(fakePiper(t, bin))
+	tts.SetVoices(map[orchestrator.Voice]string{orchestrator.VoiceM2: "es_ES-multi-medium#luis"})
+
+	ctx := orchestrator.WithSpeechRate(context.Background(), 1.25)
+	audio, err := tts.Synthesize(ctx, "Hello\nthere.", orchestrator.VoiceM1, orchestrator.LanguageEn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2 * 44100 / 10; len(audio) < want-8 || len(audio) > want+8 {
+		t.Errorf("got %d bytes, want 100ms at 44.1kHz (%d)", len(audio), want)
+	}
+	if _, err := tts.Synthesize(context.Background(), "hola", orchestrator.VoiceM2, orchestrator.LanguageEs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tts.Synthesize(context.Background(), "bonjour", orchestrator.VoiceF1, orchestrator.LanguageFr); err == nil {
+		t.Error("expected an error with no French voice")
+	}
+
+	args, _ := os.ReadFile(filepath.Join(bin, "args"))
+	lines := strings.Split(strings.TrimSpace(string(args)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("piper ran %d times", len(lines))
+	}
+	// M1 is the second English model, and speech rate becomes length scale.
+	if want := "--model " + filepath.Join(models, "en", "en_US-ryan-high.onnx") + " --output_raw --length_scale 0.800"; lines[0] != want {
+		t.Errorf("args = %q, want %q", lines[0], want)
+	}
+	if !strings.HasSuffix(lines[1], "es_ES-multi-medium.onnx --output_raw --speaker 1") {
+		t.Errorf("args = %q", lines[1])
+	}
+	if stdin, _ := os.ReadFile(filepath.Join(bin, "stdin")); string(stdin) != "Hello there.\nhola\n" {
+		t.Errorf("stdin = %q", stdin)
+	}
+}
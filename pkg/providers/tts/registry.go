@@ -0,0 +1,47 @@
+package tts
+
+import (
+	"github.com/lokutor-ai/lokutor-orchestrator/pkg/orchestrator"
+)
+
+// The package's providers register with orchestrator.DefaultRegistry, for
+// pipelines chosen by name with orchestrator.BuildFromConfig.
+func init() {
+	orchestrator.RegisterTTS("lokutor", func(o orchestrator.ProviderOptions) (orchestrator.TTSProvider, error) {
+		if err := o.RequireAPIKey("lokutor"); err != nil {
+			return nil, err
+		}
+		t := NewLokutorTTS(o.APIKey)
+		orchestrator.ApplyProviderOptions(t, o)
+		return t, nil
+	})
+	orchestrator.RegisterTTS("openai", func(o orchestrator.ProviderOptions) (orchestrator.TTSProvider, error) {
+		if err := o.RequireAPIKey("openai"); err != nil {
+			return nil, err
+		}
+		t := NewOpenAITTS(o.APIKey, o.Model)
+		orchestrator.ApplyProviderOptions(t, o)
+		return t, nil
+	})
+	orchestrator.RegisterTTS("elevenlabs", func(o orchestrator.ProviderOptions) (orchestrator.TTSProvider, error) {
+		if err := o.RequireAPIKey("elevenlabs"); err != nil {
+			return nil, err
+		}
+		t := NewElevenLabsTTS(o.APIKey, o.Model)
+		orchestrator.ApplyProviderOptions(t, o)
+		return t, nil
+	})
+	// Piper takes its models from the "voices_dir" option and, optionally,
+	// the executable from "binary".
+	orchestrator.RegisterTTS("piper", func(o orchestrator.ProviderOptions) (orchestrator.TTSProvider, error) {
+		t, err := NewPiperTTS(o.Option("voices_dir", "."))
+		if err != nil {
+			return nil, err
+		}
+		if bin := o.Option("binary", ""); bin != "" {
+			t.SetBinary(bin)
+		}
+		orchestrator.ApplyProviderOptions(t, o)
+		return t, nil
+	})
+}